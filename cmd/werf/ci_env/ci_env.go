@@ -34,6 +34,7 @@ var cmdData struct {
 	Shell               string
 	AllowRegistryLogin  bool
 	UseDockerAuthConfig bool
+	GenericMappingFile  string
 }
 
 var commonCmdData common.CmdData
@@ -46,10 +47,34 @@ func NewCmd(ctx context.Context) *cobra.Command {
 		Short:                 "Generate werf environment variables for specified CI system",
 		Long: `Generate werf environment variables for specified CI system.
 
-Currently supported only GitLab (gitlab) and GitHub (github) CI systems`,
+Supported CI systems: GitLab (gitlab), GitHub (github), Bitbucket Pipelines (bitbucket), Jenkins (jenkins),
+Azure DevOps (azure-devops), Woodpecker CI and Drone (woodpecker, drone).
+
+Other CI systems are supported with the generic mode (generic), which is driven by the mapping file
+(--generic-mapping-file) with the werf values templates, e.g.:
+
+  repo: registry.example.com/$CI_PROJECT_PATH
+  registryUsername: $CI_REGISTRY_USER
+  registryPassword: $CI_REGISTRY_PASSWORD
+  env: $CI_ENVIRONMENT
+  projectGit: $CI_PROJECT_URL
+  commit: $CI_COMMIT
+  tag: $CI_TAG
+  branch: $CI_BRANCH
+  annotations:
+    ci.example.com/build-url: $CI_BUILD_URL
+
+For the CI systems without the built-in container registry werf logs in to the registry of WERF_REPO
+with WERF_USERNAME and WERF_PASSWORD, if they are set`,
 		Example: `  # Load generated werf environment variables on GitLab job runner
   $ . $(werf ci-env gitlab --as-file)
 
+  # Load generated werf environment variables in Bitbucket Pipelines step
+  $ . $(werf ci-env bitbucket --as-file)
+
+  # Load generated werf environment variables using the mapping file for the unsupported CI system
+  $ . $(werf ci-env generic --generic-mapping-file .werf-ci-env.yaml --as-file)
+
   # Load generated werf environment variables on GitLab job runner using powershell
   $ Invoke-Expression -Command "werf ci-env gitlab --as-file --shell powershell" | Out-String -OutVariable WERF_CI_ENV_SCRIPT_PATH
   $ . $WERF_CI_ENV_SCRIPT_PATH.Trim()
@@ -85,6 +110,7 @@ Currently supported only GitLab (gitlab) and GitHub (github) CI systems`,
 	cmd.Flags().BoolVarP(&cmdData.AsFile, "as-file", "", util.GetBoolEnvironmentDefaultFalse("WERF_AS_FILE"), "Create the script and print the path for sourcing (default $WERF_AS_FILE).")
 	cmd.Flags().BoolVarP(&cmdData.AsEnvFile, "as-env-file", "", util.GetBoolEnvironmentDefaultFalse("WERF_AS_ENV_FILE"), "Create the .env file and print the path for sourcing (default $WERF_AS_ENV_FILE).")
 	cmd.Flags().StringVarP(&cmdData.OutputFilePath, "output-file-path", "o", os.Getenv("WERF_OUTPUT_FILE_PATH"), "Write to custom file (default $WERF_OUTPUT_FILE_PATH).")
+	cmd.Flags().StringVarP(&cmdData.GenericMappingFile, "generic-mapping-file", "", os.Getenv("WERF_GENERIC_MAPPING_FILE"), "Use the mapping file to generate variables for the generic CI system (default $WERF_GENERIC_MAPPING_FILE).")
 	cmd.Flags().StringVarP(&cmdData.Shell, "shell", "", os.Getenv("WERF_SHELL"), "Set to cmdexe, powershell or use the default behavior that is compatible with any unix shell (default $WERF_SHELL).")
 	cmd.Flags().StringVarP(&cmdData.TaggingStrategyStub, "tagging-strategy", "", "", `stub`)
 	cmd.Flag("tagging-strategy").Hidden = true
@@ -160,29 +186,53 @@ func runCIEnv(cmd *cobra.Command, args []string) error {
 		w = os.Stdout
 	}
 
+	var generateFunc func() error
 	ciSystem := args[0]
 	switch ciSystem {
 	case "github":
-		err := generateGithubEnvs(ctx, w, dockerConfig)
-		if err != nil {
-			if !cmdData.AsFile && !cmdData.AsEnvFile {
-				writeError(w, err.Error())
-			}
-			return err
+		generateFunc = func() error {
+			return generateGithubEnvs(ctx, w, dockerConfig)
 		}
 	case "gitlab":
-		err := generateGitlabEnvs(ctx, w, dockerConfig)
-		if err != nil {
-			if !cmdData.AsFile && !cmdData.AsEnvFile {
-				writeError(w, err.Error())
+		generateFunc = func() error {
+			return generateGitlabEnvs(ctx, w, dockerConfig)
+		}
+	case "bitbucket":
+		generateFunc = func() error {
+			return generateCIEnvs(ctx, w, dockerConfig, bitbucketEnvs(os.Getenv))
+		}
+	case "jenkins":
+		generateFunc = func() error {
+			return generateCIEnvs(ctx, w, dockerConfig, jenkinsEnvs(os.Getenv))
+		}
+	case "azure-devops":
+		generateFunc = func() error {
+			return generateCIEnvs(ctx, w, dockerConfig, azureDevOpsEnvs(os.Getenv))
+		}
+	case "woodpecker", "drone":
+		generateFunc = func() error {
+			return generateCIEnvs(ctx, w, dockerConfig, woodpeckerEnvs(os.Getenv))
+		}
+	case "generic":
+		generateFunc = func() error {
+			mapping, err := readGenericMapping(cmdData.GenericMappingFile)
+			if err != nil {
+				return err
 			}
-			return err
+			return generateCIEnvs(ctx, w, dockerConfig, genericEnvs(os.Getenv, mapping))
 		}
 	default:
 		common.PrintHelp(cmd)
 		return fmt.Errorf("provided ci system %q not supported", ciSystem)
 	}
 
+	if err := generateFunc(); err != nil {
+		if !cmdData.AsFile && !cmdData.AsEnvFile {
+			writeError(w, err.Error())
+		}
+		return err
+	}
+
 	if cmdData.AsFile || cmdData.AsEnvFile {
 		sourceFilePath, err := createSourceFile(w.(*bytes.Buffer).Bytes())
 		if err != nil {
@@ -0,0 +1,179 @@
+package ci_env
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/werf/werf/v2/pkg/docker"
+)
+
+// ciEnvs are the values of werf environment variables collected from the CI system variables.
+type ciEnvs struct {
+	Repo string
+	// RegistryUsername and RegistryPassword are used to log in to the registry of the Repo.
+	RegistryUsername string
+	RegistryPassword string
+
+	Env string
+
+	ProjectGit string
+	Commit     string
+	Tag        string
+	Branch     string
+
+	// Annotations are the CI system specific annotations, e.g. the pipeline url.
+	Annotations []ciAnnotation
+}
+
+type ciAnnotation struct {
+	// EnvSuffix is used in the WERF_ADD_ANNOTATION_<EnvSuffix> variable name.
+	EnvSuffix string
+	Key       string
+	Value     string
+}
+
+// newCIEnvs returns the values, which are common for the CI systems without the built-in container registry.
+// The registry credentials are taken from the same variables as werf cr login uses.
+func newCIEnvs(getenv func(string) string) *ciEnvs {
+	return &ciEnvs{
+		RegistryUsername: getenv("WERF_USERNAME"),
+		RegistryPassword: getenv("WERF_PASSWORD"),
+	}
+}
+
+func bitbucketEnvs(getenv func(string) string) *ciEnvs {
+	envs := newCIEnvs(getenv)
+	envs.Env = getenv("BITBUCKET_DEPLOYMENT_ENVIRONMENT")
+	envs.ProjectGit = getenv("BITBUCKET_GIT_HTTP_ORIGIN")
+	envs.Commit = getenv("BITBUCKET_COMMIT")
+	envs.Tag = getenv("BITBUCKET_TAG")
+	envs.Branch = getenv("BITBUCKET_BRANCH")
+
+	var pipelineUrl string
+	if repoFullName, buildNumber := getenv("BITBUCKET_REPO_FULL_NAME"), getenv("BITBUCKET_BUILD_NUMBER"); repoFullName != "" && buildNumber != "" {
+		pipelineUrl = fmt.Sprintf("https://bitbucket.org/%s/pipelines/results/%s", repoFullName, buildNumber)
+	}
+	envs.Annotations = append(envs.Annotations, ciAnnotation{EnvSuffix: "BITBUCKET_PIPELINE_URL", Key: "bitbucket.ci.werf.io/pipeline-url", Value: pipelineUrl})
+
+	return envs
+}
+
+func jenkinsEnvs(getenv func(string) string) *ciEnvs {
+	envs := newCIEnvs(getenv)
+	envs.ProjectGit = getenv("GIT_URL")
+	envs.Commit = getenv("GIT_COMMIT")
+	envs.Tag = getenv("TAG_NAME")
+
+	// BRANCH_NAME is set by the multibranch pipelines, GIT_BRANCH is set by the git plugin and contains the remote name.
+	envs.Branch = getenv("BRANCH_NAME")
+	if envs.Branch == "" {
+		envs.Branch = strings.TrimPrefix(getenv("GIT_BRANCH"), "origin/")
+	}
+
+	envs.Annotations = append(envs.Annotations, ciAnnotation{EnvSuffix: "JENKINS_BUILD_URL", Key: "jenkins.ci.werf.io/build-url", Value: getenv("BUILD_URL")})
+
+	return envs
+}
+
+func azureDevOpsEnvs(getenv func(string) string) *ciEnvs {
+	envs := newCIEnvs(getenv)
+	envs.Env = getenv("ENVIRONMENT_NAME")
+	envs.ProjectGit = getenv("BUILD_REPOSITORY_URI")
+	envs.Commit = getenv("BUILD_SOURCEVERSION")
+
+	sourceBranch := getenv("BUILD_SOURCEBRANCH")
+	switch {
+	case strings.HasPrefix(sourceBranch, "refs/tags/"):
+		envs.Tag = strings.TrimPrefix(sourceBranch, "refs/tags/")
+	case strings.HasPrefix(sourceBranch, "refs/heads/"):
+		envs.Branch = strings.TrimPrefix(sourceBranch, "refs/heads/")
+	}
+
+	var buildUrl string
+	if collectionUri, teamProject, buildId := getenv("SYSTEM_COLLECTIONURI"), getenv("SYSTEM_TEAMPROJECT"), getenv("BUILD_BUILDID"); collectionUri != "" && teamProject != "" && buildId != "" {
+		buildUrl = fmt.Sprintf("%s%s/_build/results?buildId=%s", ensureTrailingSlash(collectionUri), teamProject, buildId)
+	}
+	envs.Annotations = append(envs.Annotations, ciAnnotation{EnvSuffix: "AZURE_DEVOPS_BUILD_URL", Key: "azure-devops.ci.werf.io/build-url", Value: buildUrl})
+
+	return envs
+}
+
+// woodpeckerEnvs supports both Woodpecker CI and Drone, the Woodpecker variables take precedence.
+func woodpeckerEnvs(getenv func(string) string) *ciEnvs {
+	firstNonEmpty := func(names ...string) string {
+		for _, name := range names {
+			if value := getenv(name); value != "" {
+				return value
+			}
+		}
+		return ""
+	}
+
+	envs := newCIEnvs(getenv)
+	envs.Env = firstNonEmpty("CI_PIPELINE_DEPLOY_TARGET", "DRONE_DEPLOY_TO")
+	envs.ProjectGit = firstNonEmpty("CI_REPO_URL", "DRONE_REPO_LINK")
+	envs.Commit = firstNonEmpty("CI_COMMIT_SHA", "DRONE_COMMIT_SHA")
+	envs.Tag = firstNonEmpty("CI_COMMIT_TAG", "DRONE_TAG")
+	envs.Branch = firstNonEmpty("CI_COMMIT_BRANCH", "DRONE_BRANCH")
+	envs.Annotations = append(envs.Annotations, ciAnnotation{EnvSuffix: "WOODPECKER_PIPELINE_URL", Key: "woodpecker.ci.werf.io/pipeline-url", Value: firstNonEmpty("CI_PIPELINE_URL", "DRONE_BUILD_LINK")})
+
+	return envs
+}
+
+func generateCIEnvs(ctx context.Context, w io.Writer, dockerConfig string, envs *ciEnvs) error {
+	repo := os.Getenv("WERF_REPO")
+	if repo == "" {
+		repo = envs.Repo
+	}
+
+	if repo != "" && envs.RegistryUsername != "" && envs.RegistryPassword != "" && cmdData.AllowRegistryLogin {
+		if err := docker.Login(ctx, envs.RegistryUsername, envs.RegistryPassword, repo); err != nil {
+			return fmt.Errorf("unable to login into docker repo %s: %w", repo, err)
+		}
+	}
+
+	writeHeader(w, "DOCKER CONFIG", false)
+	writeEnv(w, "DOCKER_CONFIG", dockerConfig, true)
+	writeEnv(w, "WERF_DOCKER_CONFIG", dockerConfig, true)
+
+	writeHeader(w, "REPO", true)
+	writeEnv(w, "WERF_REPO", envs.Repo, false)
+
+	writeHeader(w, "DEPLOY", true)
+	writeEnv(w, "WERF_ENV", envs.Env, false)
+
+	var releaseChannel string
+	trdlUseWerfGroupChannel := os.Getenv("TRDL_USE_WERF_GROUP_CHANNEL")
+	if trdlUseWerfGroupChannel != "" {
+		releaseChannel = fmt.Sprintf("werf.io/release-channel=%s", trdlUseWerfGroupChannel)
+	}
+	writeEnv(w, "WERF_ADD_ANNOTATION_WERF_RELEASE_CHANNEL", releaseChannel, true)
+
+	writeAnnotationEnv(w, ciAnnotation{EnvSuffix: "PROJECT_GIT", Key: "project.werf.io/git", Value: envs.ProjectGit})
+	writeAnnotationEnv(w, ciAnnotation{EnvSuffix: "CI_COMMIT", Key: "ci.werf.io/commit", Value: envs.Commit})
+	writeAnnotationEnv(w, ciAnnotation{EnvSuffix: "CI_GIT_TAG", Key: "ci.werf.io/tag", Value: envs.Tag})
+	writeAnnotationEnv(w, ciAnnotation{EnvSuffix: "CI_GIT_BRANCH", Key: "ci.werf.io/branch", Value: envs.Branch})
+	for _, annotation := range envs.Annotations {
+		writeAnnotationEnv(w, annotation)
+	}
+
+	return generateOther(w)
+}
+
+func writeAnnotationEnv(w io.Writer, annotation ciAnnotation) {
+	var value string
+	if annotation.Value != "" {
+		value = fmt.Sprintf("%s=%s", annotation.Key, annotation.Value)
+	}
+	writeEnv(w, "WERF_ADD_ANNOTATION_"+annotation.EnvSuffix, value, true)
+}
+
+func ensureTrailingSlash(s string) string {
+	if strings.HasSuffix(s, "/") {
+		return s
+	}
+	return s + "/"
+}
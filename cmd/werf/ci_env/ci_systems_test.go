@@ -0,0 +1,136 @@
+package ci_env
+
+import (
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func mapGetenv(vars map[string]string) func(string) string {
+	return func(name string) string {
+		return vars[name]
+	}
+}
+
+var _ = Describe("CI systems", func() {
+	It("bitbucket", func() {
+		envs := bitbucketEnvs(mapGetenv(map[string]string{
+			"BITBUCKET_DEPLOYMENT_ENVIRONMENT": "production",
+			"BITBUCKET_GIT_HTTP_ORIGIN":        "http://bitbucket.org/workspace/repo",
+			"BITBUCKET_COMMIT":                 "abc",
+			"BITBUCKET_TAG":                    "v1.0.0",
+			"BITBUCKET_REPO_FULL_NAME":         "workspace/repo",
+			"BITBUCKET_BUILD_NUMBER":           "42",
+			"WERF_USERNAME":                    "user",
+			"WERF_PASSWORD":                    "password",
+		}))
+
+		Expect(envs).To(Equal(&ciEnvs{
+			RegistryUsername: "user",
+			RegistryPassword: "password",
+			Env:              "production",
+			ProjectGit:       "http://bitbucket.org/workspace/repo",
+			Commit:           "abc",
+			Tag:              "v1.0.0",
+			Annotations: []ciAnnotation{
+				{EnvSuffix: "BITBUCKET_PIPELINE_URL", Key: "bitbucket.ci.werf.io/pipeline-url", Value: "https://bitbucket.org/workspace/repo/pipelines/results/42"},
+			},
+		}))
+	})
+
+	DescribeTable("jenkins branch",
+		func(vars map[string]string, expectedBranch string) {
+			Expect(jenkinsEnvs(mapGetenv(vars)).Branch).To(Equal(expectedBranch))
+		},
+		Entry("multibranch pipeline", map[string]string{"BRANCH_NAME": "main", "GIT_BRANCH": "origin/other"}, "main"),
+		Entry("git plugin", map[string]string{"GIT_BRANCH": "origin/main"}, "main"),
+		Entry("no branch", map[string]string{}, ""),
+	)
+
+	DescribeTable("azure-devops",
+		func(sourceBranch, expectedTag, expectedBranch string) {
+			envs := azureDevOpsEnvs(mapGetenv(map[string]string{
+				"BUILD_SOURCEBRANCH":   sourceBranch,
+				"SYSTEM_COLLECTIONURI": "https://dev.azure.com/org",
+				"SYSTEM_TEAMPROJECT":   "project",
+				"BUILD_BUILDID":        "7",
+			}))
+
+			Expect(envs.Tag).To(Equal(expectedTag))
+			Expect(envs.Branch).To(Equal(expectedBranch))
+			Expect(envs.Annotations).To(ConsistOf(ciAnnotation{
+				EnvSuffix: "AZURE_DEVOPS_BUILD_URL",
+				Key:       "azure-devops.ci.werf.io/build-url",
+				Value:     "https://dev.azure.com/org/project/_build/results?buildId=7",
+			}))
+		},
+		Entry("tag", "refs/tags/v1.0.0", "v1.0.0", ""),
+		Entry("branch", "refs/heads/feature/x", "", "feature/x"),
+		Entry("pull request", "refs/pull/1/merge", "", ""),
+	)
+
+	DescribeTable("woodpecker",
+		func(vars map[string]string, expectedCommit, expectedPipelineUrl string) {
+			envs := woodpeckerEnvs(mapGetenv(vars))
+			Expect(envs.Commit).To(Equal(expectedCommit))
+			Expect(envs.Annotations).To(HaveLen(1))
+			Expect(envs.Annotations[0].Value).To(Equal(expectedPipelineUrl))
+		},
+		Entry("woodpecker", map[string]string{"CI_COMMIT_SHA": "abc", "CI_PIPELINE_URL": "https://ci.example.com/1", "DRONE_COMMIT_SHA": "def"}, "abc", "https://ci.example.com/1"),
+		Entry("drone", map[string]string{"DRONE_COMMIT_SHA": "def", "DRONE_BUILD_LINK": "https://drone.example.com/1"}, "def", "https://drone.example.com/1"),
+	)
+
+	Describe("generic", func() {
+		It("expands the mapping file values", func() {
+			mappingPath := filepath.Join(GinkgoT().TempDir(), "mapping.yaml")
+			Expect(os.WriteFile(mappingPath, []byte(`
+repo: registry.example.com/$PROJECT
+registryUsername: $REGISTRY_USER
+registryPassword: ${REGISTRY_PASSWORD}
+env: $DEPLOY_ENV
+commit: $COMMIT
+annotations:
+  ci.example.com/build-url: https://ci.example.com/builds/$BUILD_ID
+`), 0o644)).To(Succeed())
+
+			mapping, err := readGenericMapping(mappingPath)
+			Expect(err).ToNot(HaveOccurred())
+
+			envs := genericEnvs(mapGetenv(map[string]string{
+				"PROJECT":           "group/app",
+				"REGISTRY_USER":     "user",
+				"REGISTRY_PASSWORD": "password",
+				"DEPLOY_ENV":        "staging",
+				"COMMIT":            "abc",
+				"BUILD_ID":          "5",
+				"WERF_USERNAME":     "werf-user",
+			}), mapping)
+
+			Expect(envs).To(Equal(&ciEnvs{
+				Repo:             "registry.example.com/group/app",
+				RegistryUsername: "user",
+				RegistryPassword: "password",
+				Env:              "staging",
+				Commit:           "abc",
+				Annotations: []ciAnnotation{
+					{EnvSuffix: "CI_EXAMPLE_COM_BUILD_URL", Key: "ci.example.com/build-url", Value: "https://ci.example.com/builds/5"},
+				},
+			}))
+		})
+
+		It("fails on unknown mapping fields", func() {
+			mappingPath := filepath.Join(GinkgoT().TempDir(), "mapping.yaml")
+			Expect(os.WriteFile(mappingPath, []byte("repository: registry.example.com/app\n"), 0o644)).To(Succeed())
+
+			_, err := readGenericMapping(mappingPath)
+			Expect(err).To(HaveOccurred())
+		})
+
+		It("requires the mapping file", func() {
+			_, err := readGenericMapping("")
+			Expect(err).To(HaveOccurred())
+		})
+	})
+})
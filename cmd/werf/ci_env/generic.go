@@ -0,0 +1,94 @@
+package ci_env
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"sigs.k8s.io/yaml"
+)
+
+// genericMapping describes how to get werf values from the variables of an arbitrary CI system.
+// Each value is expanded with the environment variables, e.g. "registry.example.com/$CI_PROJECT_PATH".
+type genericMapping struct {
+	Repo             string `json:"repo,omitempty"`
+	RegistryUsername string `json:"registryUsername,omitempty"`
+	RegistryPassword string `json:"registryPassword,omitempty"`
+
+	Env string `json:"env,omitempty"`
+
+	ProjectGit string `json:"projectGit,omitempty"`
+	Commit     string `json:"commit,omitempty"`
+	Tag        string `json:"tag,omitempty"`
+	Branch     string `json:"branch,omitempty"`
+
+	// Annotations are the annotation keys with the value templates, e.g. "ci.example.com/build-url: $BUILD_URL".
+	Annotations map[string]string `json:"annotations,omitempty"`
+}
+
+func readGenericMapping(path string) (*genericMapping, error) {
+	if path == "" {
+		return nil, fmt.Errorf("mapping file should be specified with --generic-mapping-file for the generic CI system")
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read mapping file %q: %w", path, err)
+	}
+
+	mapping := &genericMapping{}
+	if err := yaml.UnmarshalStrict(data, mapping); err != nil {
+		return nil, fmt.Errorf("unable to parse mapping file %q: %w", path, err)
+	}
+
+	return mapping, nil
+}
+
+func genericEnvs(getenv func(string) string, mapping *genericMapping) *ciEnvs {
+	expand := func(s string) string {
+		return os.Expand(s, getenv)
+	}
+
+	envs := newCIEnvs(getenv)
+	envs.Repo = expand(mapping.Repo)
+	if mapping.RegistryUsername != "" || mapping.RegistryPassword != "" {
+		envs.RegistryUsername = expand(mapping.RegistryUsername)
+		envs.RegistryPassword = expand(mapping.RegistryPassword)
+	}
+	envs.Env = expand(mapping.Env)
+	envs.ProjectGit = expand(mapping.ProjectGit)
+	envs.Commit = expand(mapping.Commit)
+	envs.Tag = expand(mapping.Tag)
+	envs.Branch = expand(mapping.Branch)
+
+	keys := make([]string, 0, len(mapping.Annotations))
+	for key := range mapping.Annotations {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		envs.Annotations = append(envs.Annotations, ciAnnotation{
+			EnvSuffix: annotationEnvSuffix(key),
+			Key:       key,
+			Value:     expand(mapping.Annotations[key]),
+		})
+	}
+
+	return envs
+}
+
+// annotationEnvSuffix makes the env name suffix from the annotation key, e.g. ci.example.com/build-url -> CI_EXAMPLE_COM_BUILD_URL.
+func annotationEnvSuffix(key string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z':
+			return r - 'a' + 'A'
+		case r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+			return r
+		default:
+			return '_'
+		}
+	}, key)
+}
@@ -0,0 +1,13 @@
+package ci_env
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestCmdCIEnv(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Cmd CI Env Suite")
+}
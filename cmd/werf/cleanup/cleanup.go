@@ -13,7 +13,12 @@ import (
 	"github.com/werf/logboek"
 	"github.com/werf/werf/v2/cmd/werf/common"
 	"github.com/werf/werf/v2/pkg/cleaning"
+	"github.com/werf/werf/v2/pkg/cleanup_report"
+	"github.com/werf/werf/v2/pkg/config"
+	"github.com/werf/werf/v2/pkg/container_backend"
 	"github.com/werf/werf/v2/pkg/git_repo"
+	"github.com/werf/werf/v2/pkg/giterminism_manager"
+	"github.com/werf/werf/v2/pkg/storage/manager"
 	"github.com/werf/werf/v2/pkg/tmp_manager"
 	"github.com/werf/werf/v2/pkg/true_git"
 	"github.com/werf/werf/v2/pkg/werf/global_warnings"
@@ -24,6 +29,7 @@ var commonCmdData common.CmdData
 type cmdDataType struct {
 	ScanContextOnly string
 	KeepList        string
+	ProjectsConfig  string
 }
 
 var cmdData cmdDataType
@@ -80,6 +86,7 @@ func NewCmd(ctx context.Context) *cobra.Command {
 	common.StubSetupInsecureHelmDependencies(&commonCmdData, cmd)
 	common.SetupSkipTlsVerifyRegistry(&commonCmdData, cmd)
 	common.SetupContainerRegistryMirror(&commonCmdData, cmd)
+	common.SetupRegistryAuthConfig(&commonCmdData, cmd)
 
 	common.SetupScanContextNamespaceOnly(&commonCmdData, cmd)
 	common.SetupKubeScanNamespaces(&commonCmdData, cmd)
@@ -117,6 +124,7 @@ func NewCmd(ctx context.Context) *cobra.Command {
 	cmd.Flags().StringVarP(&commonCmdData.KubeTLSCAData, "kube-ca-data", "", os.Getenv("WERF_KUBE_CA_DATA"), "Pass Kubernetes API server TLS CA data (default $WERF_KUBE_CA_DATA)")
 
 	setupKeeplist(&cmdData, cmd)
+	setupProjectsConfig(&cmdData, cmd)
 
 	common.SetupLegacyKubeConfigPath(&commonCmdData, cmd)
 	common.SetupKubeConfigBase64(&commonCmdData, cmd)
@@ -157,21 +165,70 @@ func runCleanup(ctx context.Context, cmd *cobra.Command) error {
 		return err
 	}
 
-	giterminismManager, err := common.GetGiterminismManager(ctx, &commonCmdData)
+	_, err = tmp_manager.CreateProjectDir(ctx)
+	if err != nil {
+		return fmt.Errorf("getting project tmp dir failed: %w", err)
+	}
+
+	if cmdData.ProjectsConfig != "" {
+		return runMultiProjectCleanup(ctx, cmd, containerBackend)
+	}
+
+	project, err := prepareProject(ctx, &commonCmdData, containerBackend)
 	if err != nil {
 		return err
 	}
 
-	common.ProcessLogProjectDir(&commonCmdData, giterminismManager.ProjectDir())
+	var kubernetesContextClients []*kube.ContextClient
+	if !(*commonCmdData.WithoutKube || project.WerfConfig.Meta.Cleanup.DisableKubernetesBasedPolicy) {
+		kubernetesContextClients, err = getKubernetesContextClients()
+		if err != nil {
+			return err
+		}
+	}
 
-	_, err = tmp_manager.CreateProjectDir(ctx)
+	kubernetesNamespacesByContext := common.GetKubernetesNamespacesByContext(&commonCmdData, kubernetesContextClients)
+
+	keepList, err := getKeepList()
 	if err != nil {
-		return fmt.Errorf("getting project tmp dir failed: %w", err)
+		return err
 	}
 
-	_, werfConfig, err := common.GetRequiredWerfConfig(ctx, &commonCmdData, giterminismManager, common.GetWerfConfigOptions(&commonCmdData, true))
+	report, reportPath, err := common.NewCleanupReport(ctx, &commonCmdData, "cleanup", *commonCmdData.DryRun, project.StorageManager)
 	if err != nil {
-		return fmt.Errorf("unable to load werf config: %w", err)
+		return err
+	}
+
+	cleanupOptions := newCleanupOptions(cmd, project, keepList, report)
+	cleanupOptions.KubernetesContextClients = kubernetesContextClients
+	cleanupOptions.KubernetesNamespacesByContext = kubernetesNamespacesByContext
+
+	logboek.LogOptionalLn()
+	runErr := cleaning.Cleanup(ctx, project.Name, project.StorageManager, cleanupOptions)
+
+	return errors.Join(runErr, report.Save(ctx, reportPath))
+}
+
+type project struct {
+	Name               string
+	GiterminismManager *giterminism_manager.Manager
+	WerfConfig         *config.WerfConfig
+	StorageManager     *manager.StorageManager
+	ImagesNames        []string
+}
+
+// prepareProject loads werf.yaml, synchronizes the git repository with origin and initializes the storage manager of the project.
+func prepareProject(ctx context.Context, projectCmdData *common.CmdData, containerBackend container_backend.ContainerBackend) (*project, error) {
+	giterminismManager, err := common.GetGiterminismManager(ctx, projectCmdData)
+	if err != nil {
+		return nil, err
+	}
+
+	common.ProcessLogProjectDir(projectCmdData, giterminismManager.ProjectDir())
+
+	_, werfConfig, err := common.GetRequiredWerfConfig(ctx, projectCmdData, giterminismManager, common.GetWerfConfigOptions(projectCmdData, true))
+	if err != nil {
+		return nil, fmt.Errorf("unable to load werf config: %w", err)
 	}
 
 	logboek.Context(ctx).LogOptionalLn()
@@ -180,21 +237,21 @@ func runCleanup(ctx context.Context, cmd *cobra.Command) error {
 		if !werfConfig.Meta.GitWorktree.GetForceShallowClone() && !werfConfig.Meta.GitWorktree.GetAllowFetchingOriginBranchesAndTags() {
 			isShallow, err := giterminismManager.LocalGitRepo().IsShallowClone(ctx)
 			if err != nil {
-				return fmt.Errorf("check shallow clone failed: %w", err)
+				return nil, fmt.Errorf("check shallow clone failed: %w", err)
 			}
 
 			if isShallow {
-				logboek.Warn().LogLn("Git shallow clone should not be used with images cleanup commands due to incompleteness of the repository history that is extremely essential for proper work.")
-				logboek.Warn().LogLn("It is recommended to enable automatic fetch of origin git branches and tags during cleanup process with the gitWorktree.allowFetchOriginBranchesAndTags=true werf.yaml directive (which is enabled by default.")
-				logboek.Warn().LogLn("If you still want to use shallow clone, add gitWorktree.forceShallowClone=true directive into werf.yaml.")
+				logboek.Context(ctx).Warn().LogLn("Git shallow clone should not be used with images cleanup commands due to incompleteness of the repository history that is extremely essential for proper work.")
+				logboek.Context(ctx).Warn().LogLn("It is recommended to enable automatic fetch of origin git branches and tags during cleanup process with the gitWorktree.allowFetchOriginBranchesAndTags=true werf.yaml directive (which is enabled by default.")
+				logboek.Context(ctx).Warn().LogLn("If you still want to use shallow clone, add gitWorktree.forceShallowClone=true directive into werf.yaml.")
 
-				return fmt.Errorf("git shallow clone is not allowed")
+				return nil, fmt.Errorf("git shallow clone is not allowed")
 			}
 		}
 
 		if werfConfig.Meta.GitWorktree.GetAllowFetchingOriginBranchesAndTags() {
 			if err := giterminismManager.LocalGitRepo().SyncWithOrigin(ctx); err != nil {
-				return fmt.Errorf("synchronization failed: %w", err)
+				return nil, fmt.Errorf("synchronization failed: %w", err)
 			}
 		}
 	}
@@ -204,66 +261,40 @@ func runCleanup(ctx context.Context, cmd *cobra.Command) error {
 	storageManager, err := common.NewStorageManager(ctx, &common.NewStorageManagerConfig{
 		ProjectName:                    projectName,
 		ContainerBackend:               containerBackend,
-		CmdData:                        &commonCmdData,
+		CmdData:                        projectCmdData,
 		CleanupDisabled:                werfConfig.Meta.Cleanup.DisableCleanup,
 		GitHistoryBasedCleanupDisabled: werfConfig.Meta.Cleanup.DisableGitHistoryBasedPolicy,
 		SkipMetaCheck:                  true,
 	})
 	if err != nil {
-		return fmt.Errorf("unable to init storage manager: %w", err)
+		return nil, fmt.Errorf("unable to init storage manager: %w", err)
 	}
 
-	if *commonCmdData.Parallel {
-		storageManager.EnableParallel(int(common.GetParallelTasksLimit(&commonCmdData)))
+	if *projectCmdData.Parallel {
+		storageManager.EnableParallel(int(common.GetParallelTasksLimit(projectCmdData)))
 	}
 
 	imagesNames, err := common.GetManagedImagesNames(ctx, projectName, storageManager.StagesStorage, werfConfig)
 	if err != nil {
-		return err
-	}
-	logboek.Debug().LogF("Managed images names: %v\n", imagesNames)
-
-	var kubernetesContextClients []*kube.ContextClient
-	var kubernetesNamespacesByContext map[string][]string
-	if !(*commonCmdData.WithoutKube || werfConfig.Meta.Cleanup.DisableKubernetesBasedPolicy) {
-		kubernetesContextClients, err = common.GetKubernetesContextClients(
-			commonCmdData.LegacyKubeConfigPath,
-			commonCmdData.KubeConfigBase64,
-			commonCmdData.LegacyKubeConfigPathsMergeList,
-			cmdData.ScanContextOnly,
-			commonCmdData.KubeBearerTokenData,
-			commonCmdData.KubeBearerTokenPath,
-			commonCmdData.KubeAPIServerAddress,
-			commonCmdData.KubeTLSCAData,
-			commonCmdData.KubeSkipTLSVerify,
-		)
-		if err != nil {
-			return fmt.Errorf("unable to get Kubernetes clusters connections: %w", err)
-		}
-	}
-
-	kubernetesNamespacesByContext = common.GetKubernetesNamespacesByContext(&commonCmdData, kubernetesContextClients)
-
-	keepList := cleaning.NewKeepListWithSize(0)
-
-	if cmdData.KeepList != "" {
-		if keepList, err = parseKeepList(cmdData.KeepList); err != nil {
-			return fmt.Errorf("unable to parse keepList: %w", err)
-		}
-	}
-
-	report, reportPath, err := common.NewCleanupReport(ctx, &commonCmdData, "cleanup", *commonCmdData.DryRun, storageManager)
-	if err != nil {
-		return err
+		return nil, err
 	}
+	logboek.Context(ctx).Debug().LogF("Managed images names: %v\n", imagesNames)
+
+	return &project{
+		Name:               projectName,
+		GiterminismManager: giterminismManager,
+		WerfConfig:         werfConfig,
+		StorageManager:     storageManager,
+		ImagesNames:        imagesNames,
+	}, nil
+}
 
-	cleanupOptions := cleaning.CleanupOptions{
-		ImageNameList:                   imagesNames,
-		LocalGit:                        giterminismManager.LocalGitRepo().(*git_repo.Local),
-		KubernetesContextClients:        kubernetesContextClients,
-		KubernetesNamespacesByContext:   kubernetesNamespacesByContext,
+func newCleanupOptions(cmd *cobra.Command, project *project, keepList cleaning.KeepList, report *cleanup_report.Report) cleaning.CleanupOptions {
+	return cleaning.CleanupOptions{
+		ImageNameList:                   project.ImagesNames,
+		LocalGit:                        project.GiterminismManager.LocalGitRepo().(*git_repo.Local),
 		WithoutKube:                     *commonCmdData.WithoutKube,
-		ConfigMetaCleanup:               werfConfig.Meta.Cleanup,
+		ConfigMetaCleanup:               project.WerfConfig.Meta.Cleanup,
 		KeepStagesBuiltWithinLastNHours: common.GetKeepStagesBuiltWithinLastNHours(&commonCmdData, cmd),
 		DryRun:                          *commonCmdData.DryRun,
 		Parallel:                        common.GetParallel(&commonCmdData),
@@ -271,9 +302,36 @@ func runCleanup(ctx context.Context, cmd *cobra.Command) error {
 		KeepList:                        keepList,
 		Report:                          report,
 	}
+}
 
-	logboek.LogOptionalLn()
-	runErr := cleaning.Cleanup(ctx, projectName, storageManager, cleanupOptions)
+func getKubernetesContextClients() ([]*kube.ContextClient, error) {
+	kubernetesContextClients, err := common.GetKubernetesContextClients(
+		commonCmdData.LegacyKubeConfigPath,
+		commonCmdData.KubeConfigBase64,
+		commonCmdData.LegacyKubeConfigPathsMergeList,
+		cmdData.ScanContextOnly,
+		commonCmdData.KubeBearerTokenData,
+		commonCmdData.KubeBearerTokenPath,
+		commonCmdData.KubeAPIServerAddress,
+		commonCmdData.KubeTLSCAData,
+		commonCmdData.KubeSkipTLSVerify,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("unable to get Kubernetes clusters connections: %w", err)
+	}
 
-	return errors.Join(runErr, report.Save(ctx, reportPath))
+	return kubernetesContextClients, nil
+}
+
+func getKeepList() (cleaning.KeepList, error) {
+	if cmdData.KeepList == "" {
+		return cleaning.NewKeepListWithSize(0), nil
+	}
+
+	keepList, err := parseKeepList(cmdData.KeepList)
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse keepList: %w", err)
+	}
+
+	return keepList, nil
 }
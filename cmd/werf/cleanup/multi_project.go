@@ -0,0 +1,110 @@
+package cleanup
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/werf/logboek"
+	"github.com/werf/werf/v2/cmd/werf/common"
+	"github.com/werf/werf/v2/pkg/cleaning"
+	"github.com/werf/werf/v2/pkg/cleanup_report"
+	"github.com/werf/werf/v2/pkg/container_backend"
+	"github.com/werf/werf/v2/pkg/util/parallel"
+)
+
+// runMultiProjectCleanup cleans up the projects from the projects config. Kubernetes is scanned once and the deployed images
+// are shared by the project cleanups. The failed project does not stop the cleanup of the others.
+func runMultiProjectCleanup(ctx context.Context, cmd *cobra.Command, containerBackend container_backend.ContainerBackend) error {
+	cfg, err := parseProjectsConfig(cmdData.ProjectsConfig)
+	if err != nil {
+		return err
+	}
+
+	keepList, err := getKeepList()
+	if err != nil {
+		return err
+	}
+
+	var deployedDockerImages []*cleaning.DeployedDockerImage
+	if !*commonCmdData.WithoutKube {
+		kubernetesContextClients, err := getKubernetesContextClients()
+		if err != nil {
+			return err
+		}
+
+		if len(kubernetesContextClients) == 0 {
+			return fmt.Errorf("cleanup requires Kubernetes access (token or kubeconfig), pass --without-kube to skip Kubernetes cleanup")
+		}
+
+		kubernetesNamespacesByContext := common.GetKubernetesNamespacesByContext(&commonCmdData, kubernetesContextClients)
+
+		if err := logboek.Context(ctx).LogProcess("Getting deployed docker images from Kubernetes").DoError(func() error {
+			deployedDockerImages, err = cleaning.ScanDeployedDockerImages(ctx, kubernetesContextClients, kubernetesNamespacesByContext)
+			return err
+		}); err != nil {
+			return fmt.Errorf("error getting deployed docker images names from Kubernetes: %w", err)
+		}
+	}
+
+	report, reportPath, err := common.NewCombinedCleanupReport(ctx, &commonCmdData, "cleanup", *commonCmdData.DryRun)
+	if err != nil {
+		return err
+	}
+
+	numberOfWorkers := 1
+	if common.GetParallel(&commonCmdData) {
+		numberOfWorkers = len(cfg.Projects)
+		if limit := int(common.GetParallelTasksLimit(&commonCmdData)); limit > 0 && limit < numberOfWorkers {
+			numberOfWorkers = limit
+		}
+	}
+
+	projectErrs := make([]error, len(cfg.Projects))
+	runErr := parallel.DoTasks(ctx, len(cfg.Projects), parallel.DoTasksOptions{
+		MaxNumberOfWorkers: numberOfWorkers,
+	}, func(ctx context.Context, taskId int) error {
+		entry := cfg.Projects[taskId]
+
+		return logboek.Context(ctx).LogProcess("Cleanup project %s", entry.Name()).DoError(func() error {
+			if err := cleanupProject(ctx, cmd, containerBackend, entry, keepList, deployedDockerImages, report); err != nil {
+				projectErrs[taskId] = fmt.Errorf("project %s cleanup failed: %w", entry.Name(), err)
+				logboek.Context(ctx).Error().LogF("Project %s cleanup failed: %s\n", entry.Name(), err)
+			}
+
+			return nil
+		})
+	})
+
+	return errors.Join(runErr, errors.Join(projectErrs...), report.Save(ctx, reportPath))
+}
+
+func cleanupProject(ctx context.Context, cmd *cobra.Command, containerBackend container_backend.ContainerBackend, entry *projectsConfigEntry, keepList cleaning.KeepList, deployedDockerImages []*cleaning.DeployedDockerImage, report *cleanup_report.CombinedReport) error {
+	project, err := prepareProject(ctx, newProjectCmdData(&commonCmdData, entry), containerBackend)
+	if err != nil {
+		report.SetError(ctx, report.AddProject(ctx, entry.Name(), nil), err)
+		return err
+	}
+
+	if entry.Project != "" && entry.Project != project.Name {
+		err := fmt.Errorf("project %q expected in %s, got %q", entry.Project, entry.GitDir, project.Name)
+		report.SetError(ctx, report.AddProject(ctx, entry.Name(), nil), err)
+		return err
+	}
+
+	var projectReport *cleanup_report.Report
+	if report != nil {
+		projectReport = common.NewProjectCleanupReport(ctx, "cleanup", *commonCmdData.DryRun, project.StorageManager)
+	}
+	projectReportItem := report.AddProject(ctx, project.Name, projectReport)
+
+	cleanupOptions := newCleanupOptions(cmd, project, keepList, projectReport)
+	cleanupOptions.DeployedDockerImages = deployedDockerImages
+
+	err = cleaning.Cleanup(ctx, project.Name, project.StorageManager, cleanupOptions)
+	report.SetError(ctx, projectReportItem, err)
+
+	return err
+}
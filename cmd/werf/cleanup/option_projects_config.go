@@ -0,0 +1,96 @@
+package cleanup
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+	"sigs.k8s.io/yaml"
+
+	"github.com/werf/werf/v2/cmd/werf/common"
+)
+
+type projectsConfig struct {
+	Projects []*projectsConfigEntry `json:"projects"`
+}
+
+type projectsConfigEntry struct {
+	// Project is the expected project name from werf.yaml, it guards against the wrong git directory.
+	Project string `json:"project,omitempty"`
+	// GitDir is the project directory in the git work tree, relative paths are resolved against the projects config directory.
+	GitDir    string `json:"gitDir"`
+	Config    string `json:"config,omitempty"`
+	Repo      string `json:"repo"`
+	FinalRepo string `json:"finalRepo,omitempty"`
+}
+
+// Name returns the project name for logs and reports.
+func (e *projectsConfigEntry) Name() string {
+	if e.Project != "" {
+		return e.Project
+	}
+	return e.GitDir
+}
+
+func setupProjectsConfig(cmdData *cmdDataType, cmd *cobra.Command) {
+	const name = "projects-config"
+
+	cmd.Flags().StringVarP(&cmdData.ProjectsConfig, name, "", os.Getenv("WERF_PROJECTS_CONFIG"), "Cleanup multiple projects listed in the specified config file in a single run. Kubernetes is scanned once for all projects, the projects are cleaned up in parallel up to --parallel-tasks-limit and a single cleanup report is saved. The --dir, --config, --repo and --final-repo options are taken from the config for each project (default $WERF_PROJECTS_CONFIG)")
+}
+
+func parseProjectsConfig(filename string) (*projectsConfig, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read projects config file: %w", err)
+	}
+
+	cfg := &projectsConfig{}
+	if err := yaml.UnmarshalStrict(data, cfg); err != nil {
+		return nil, fmt.Errorf("unable to parse projects config file %q: %w", filename, err)
+	}
+
+	if len(cfg.Projects) == 0 {
+		return nil, fmt.Errorf("no projects specified in projects config file %q", filename)
+	}
+
+	baseDir := filepath.Dir(filename)
+	for i, entry := range cfg.Projects {
+		if entry.GitDir == "" {
+			return nil, fmt.Errorf("projects config file %q: projects[%d].gitDir required", filename, i)
+		}
+
+		if entry.Repo == "" {
+			return nil, fmt.Errorf("projects config file %q: projects[%d].repo required", filename, i)
+		}
+
+		if !filepath.IsAbs(entry.GitDir) {
+			entry.GitDir = filepath.Join(baseDir, entry.GitDir)
+		}
+	}
+
+	return cfg, nil
+}
+
+// newProjectCmdData returns the copy of the command data with the project-specific options.
+// The --secondary-repo and --cache-repo options are not applied to the projects.
+func newProjectCmdData(cmdData *common.CmdData, entry *projectsConfigEntry) *common.CmdData {
+	projectCmdData := *cmdData
+
+	projectCmdData.Dir = &entry.GitDir
+	projectCmdData.GitWorkTree = new(string)
+	projectCmdData.ConfigPath = &entry.Config
+
+	repo := *cmdData.Repo
+	repo.Address = &entry.Repo
+	projectCmdData.Repo = &repo
+
+	finalRepo := *cmdData.FinalRepo
+	finalRepo.Address = &entry.FinalRepo
+	projectCmdData.FinalRepo = &finalRepo
+
+	projectCmdData.SecondaryStagesStorage = new([]string)
+	projectCmdData.CacheStagesStorage = new([]string)
+
+	return &projectCmdData
+}
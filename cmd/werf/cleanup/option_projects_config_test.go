@@ -0,0 +1,76 @@
+package cleanup
+
+import (
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/werf/werf/v2/cmd/werf/common"
+)
+
+var _ = Describe("projects config", func() {
+	writeConfig := func(content string) string {
+		path := filepath.Join(GinkgoT().TempDir(), "cleanup.yaml")
+		Expect(os.WriteFile(path, []byte(content), 0o644)).To(Succeed())
+		return path
+	}
+
+	It("parses the projects and resolves the relative git dirs against the config dir", func() {
+		path := writeConfig(`
+projects:
+- project: backend
+  gitDir: backend
+  repo: registry.example.com/backend
+- gitDir: /srv/git/frontend
+  config: werf-frontend.yaml
+  repo: registry.example.com/frontend
+  finalRepo: registry.example.com/frontend-final
+`)
+
+		cfg, err := parseProjectsConfig(path)
+		Expect(err).To(Succeed())
+		Expect(cfg.Projects).To(Equal([]*projectsConfigEntry{
+			{Project: "backend", GitDir: filepath.Join(filepath.Dir(path), "backend"), Repo: "registry.example.com/backend"},
+			{GitDir: "/srv/git/frontend", Config: "werf-frontend.yaml", Repo: "registry.example.com/frontend", FinalRepo: "registry.example.com/frontend-final"},
+		}))
+		Expect(cfg.Projects[1].Name()).To(Equal("/srv/git/frontend"))
+	})
+
+	DescribeTable("fails on the bad config",
+		func(content string) {
+			_, err := parseProjectsConfig(writeConfig(content))
+			Expect(err).To(HaveOccurred())
+		},
+		Entry("no projects", "projects: []\n"),
+		Entry("no git dir", "projects:\n- repo: registry.example.com/app\n"),
+		Entry("no repo", "projects:\n- gitDir: app\n"),
+		Entry("unknown field", "projects:\n- gitDir: app\n  repo: registry.example.com/app\n  dir: app\n"),
+	)
+
+	It("overrides the project options without changing the command data", func() {
+		dir, configPath, repoAddress, finalRepoAddress := "/common", "werf.yaml", "registry.example.com/common", ""
+		secondaryRepos := []string{"registry.example.com/secondary"}
+		cmdData := &common.CmdData{
+			Dir:                    &dir,
+			GitWorkTree:            new(string),
+			ConfigPath:             &configPath,
+			Repo:                   &common.RepoData{Name: "repo", Address: &repoAddress},
+			FinalRepo:              &common.RepoData{Name: "final-repo", Address: &finalRepoAddress},
+			SecondaryStagesStorage: &secondaryRepos,
+			CacheStagesStorage:     new([]string),
+		}
+
+		projectCmdData := newProjectCmdData(cmdData, &projectsConfigEntry{GitDir: "/srv/git/app", Repo: "registry.example.com/app"})
+		Expect(*projectCmdData.Dir).To(Equal("/srv/git/app"))
+		Expect(*projectCmdData.ConfigPath).To(BeEmpty())
+		Expect(*projectCmdData.Repo.Address).To(Equal("registry.example.com/app"))
+		Expect(projectCmdData.Repo.Name).To(Equal("repo"))
+		Expect(*projectCmdData.SecondaryStagesStorage).To(BeEmpty())
+
+		Expect(*cmdData.Dir).To(Equal("/common"))
+		Expect(*cmdData.Repo.Address).To(Equal("registry.example.com/common"))
+		Expect(*cmdData.SecondaryStagesStorage).To(HaveLen(1))
+	})
+})
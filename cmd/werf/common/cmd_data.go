@@ -12,6 +12,7 @@ import (
 	"github.com/werf/nelm/pkg/common"
 	"github.com/werf/werf/v2/pkg/host_cleaning/units"
 	"github.com/werf/werf/v2/pkg/util/option"
+	"github.com/werf/werf/v2/pkg/vulnerability_scanner"
 )
 
 type CmdData struct {
@@ -65,6 +66,7 @@ type CmdData struct {
 	keepStagesBuiltWithinLastNHours *uint64
 	WithoutKube                     *bool
 	ContainerRegistryMirror         *[]string
+	RegistryAuthConfig              *string
 
 	LooseGiterminism *bool
 	Dev              *bool
@@ -115,6 +117,12 @@ type CmdData struct {
 
 	CreateIncludesLockFile bool
 	AllowIncludesUpdate    bool
+	SkipIncludesInit       bool
+
+	VulnerabilityScanner           string
+	VulnerabilitySeverityThreshold string
+	VulnerabilityScanReportFormat  string
+	VulnerabilityScanCacheTTL      time.Duration
 
 	ChartProvenanceKeyring           string
 	ChartProvenanceStrategy          string
@@ -236,6 +244,28 @@ func (cmdData *CmdData) SetupAllowIncludesUpdate(cmd *cobra.Command) {
 	cmd.Flags().BoolVarP(&cmdData.AllowIncludesUpdate, "allow-includes-update", "", util.GetBoolEnvironmentDefaultFalse("WERF_ALLOW_INCLUDES_UPDATE"), `Allow use includes latest versions (default $WERF_ALLOW_INCLUDES_UPDATE or false)`)
 }
 
+const DefaultVulnerabilityScanCacheTTL = 24 * time.Hour
+
+func (cmdData *CmdData) SetupVulnerabilityScan(cmd *cobra.Command) error {
+	cmd.Flags().StringVarP(&cmdData.VulnerabilityScanner, "vulnerability-scanner", "", os.Getenv("WERF_VULNERABILITY_SCANNER"), fmt.Sprintf("Scan final images for vulnerabilities with the specified scanner after they are published to the repo and add the results to the build report, the scanner binary must be available in PATH (one of %v, default $WERF_VULNERABILITY_SCANNER or no scan)", vulnerability_scanner.ScannerTypes))
+	cmd.Flags().StringVarP(&cmdData.VulnerabilitySeverityThreshold, "vulnerability-severity-threshold", "", os.Getenv("WERF_VULNERABILITY_SEVERITY_THRESHOLD"), fmt.Sprintf("Fail the build if vulnerabilities of the specified or higher severity are found in final images (one of %v, default $WERF_VULNERABILITY_SEVERITY_THRESHOLD or never fail)", vulnerability_scanner.Severities))
+	cmd.Flags().StringVarP(&cmdData.VulnerabilityScanReportFormat, "vulnerability-scan-report-format", "", os.Getenv("WERF_VULNERABILITY_SCAN_REPORT_FORMAT"), fmt.Sprintf("Format the scanner report is requested and parsed in (one of %v, default $WERF_VULNERABILITY_SCAN_REPORT_FORMAT or %s)", vulnerability_scanner.ReportFormats, vulnerability_scanner.ReportFormatJSON))
+
+	defaultCacheTTL := DefaultVulnerabilityScanCacheTTL
+	if os.Getenv("WERF_VULNERABILITY_SCAN_CACHE_TTL") != "" {
+		var err error
+
+		defaultCacheTTL, err = util.GetDurationEnvVar("WERF_VULNERABILITY_SCAN_CACHE_TTL")
+		if err != nil {
+			return fmt.Errorf("bad WERF_VULNERABILITY_SCAN_CACHE_TTL value: %w", err)
+		}
+	}
+
+	cmd.Flags().DurationVarP(&cmdData.VulnerabilityScanCacheTTL, "vulnerability-scan-cache-ttl", "", defaultCacheTTL, "How long the stored scan result is reused for the final image that is not rebuilt, the image is scanned again with the updated scanner database after that (default $WERF_VULNERABILITY_SCAN_CACHE_TTL or 24h)")
+
+	return nil
+}
+
 func (cmdData *CmdData) SetupBackendNetwork(cmd *cobra.Command) {
 	cmdData.BackendNetwork = new(string)
 	cmd.Flags().StringVarP(cmdData.BackendNetwork, "backend-network", "", os.Getenv("WERF_BACKEND_NETWORK"), "Network mode for the build containers ($WERF_BACKEND_NETWORK or nothing by default)")
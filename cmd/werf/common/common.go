@@ -30,11 +30,14 @@ import (
 	"github.com/werf/werf/v2/pkg/container_backend"
 	"github.com/werf/werf/v2/pkg/docker"
 	"github.com/werf/werf/v2/pkg/docker_registry"
+	"github.com/werf/werf/v2/pkg/docker_registry/credhelper"
+	"github.com/werf/werf/v2/pkg/docker_registry/mirror"
 	"github.com/werf/werf/v2/pkg/git_repo"
 	"github.com/werf/werf/v2/pkg/giterminism_manager"
 	"github.com/werf/werf/v2/pkg/logging"
 	"github.com/werf/werf/v2/pkg/storage"
 	"github.com/werf/werf/v2/pkg/storage/manager"
+	"github.com/werf/werf/v2/pkg/telemetry"
 	"github.com/werf/werf/v2/pkg/true_git"
 	"github.com/werf/werf/v2/pkg/util/option"
 	"github.com/werf/werf/v2/pkg/werf"
@@ -265,6 +268,11 @@ func NewCleanupReport(ctx context.Context, cmdData *CmdData, command string, dry
 		return nil, "", err
 	}
 
+	return NewProjectCleanupReport(ctx, command, dryRun, storageManager), reportPath, nil
+}
+
+// NewProjectCleanupReport returns the cleanup report of the project repos.
+func NewProjectCleanupReport(ctx context.Context, command string, dryRun bool, storageManager *manager.StorageManager) *cleanup_report.Report {
 	var finalRepo string
 	if finalStagesStorage := storageManager.GetFinalStagesStorage(); finalStagesStorage != nil {
 		finalRepo = finalStagesStorage.Address()
@@ -272,7 +280,43 @@ func NewCleanupReport(ctx context.Context, cmdData *CmdData, command string, dry
 
 	return cleanup_report.NewReport(ctx, command, dryRun, storageManager.GetStagesStorage().Address(), cleanup_report.NewReportOptions{
 		FinalRepo: finalRepo,
-	}), reportPath, nil
+	})
+}
+
+// NewCombinedCleanupReport returns the report of the cleanup of multiple projects, the project reports are added by the project cleanups.
+func NewCombinedCleanupReport(ctx context.Context, cmdData *CmdData, command string, dryRun bool) (*cleanup_report.CombinedReport, string, error) {
+	if !GetSaveCleanupReport(cmdData) {
+		return nil, "", nil
+	}
+
+	reportPath, err := GetCleanupReportPath(cmdData)
+	if err != nil {
+		return nil, "", err
+	}
+
+	if err := cleanup_report.CheckWritable(ctx, reportPath); err != nil {
+		return nil, "", err
+	}
+
+	return cleanup_report.NewCombinedReport(ctx, command, dryRun), reportPath, nil
+}
+
+// NewHostCleanupReport returns the report of the local container backend cleanup by the host cleanup.
+func NewHostCleanupReport(ctx context.Context, cmdData *CmdData, command string, dryRun bool) (*cleanup_report.HostReport, string, error) {
+	if !GetSaveCleanupReport(cmdData) {
+		return nil, "", nil
+	}
+
+	reportPath, err := GetCleanupReportPath(cmdData)
+	if err != nil {
+		return nil, "", err
+	}
+
+	if err := cleanup_report.CheckWritable(ctx, reportPath); err != nil {
+		return nil, "", err
+	}
+
+	return cleanup_report.NewHostReport(ctx, command, dryRun), reportPath, nil
 }
 
 func SetupSaveDeployReport(cmdData *CmdData, cmd *cobra.Command) {
@@ -570,7 +614,15 @@ func SetupInsecureRegistry(cmdData *CmdData, cmd *cobra.Command) {
 
 func SetupContainerRegistryMirror(cmdData *CmdData, cmd *cobra.Command) {
 	cmdData.ContainerRegistryMirror = new([]string)
-	cmd.Flags().StringArrayVarP(cmdData.ContainerRegistryMirror, "container-registry-mirror", "", []string{}, "(Buildah-only) Use specified mirrors for docker.io")
+	cmd.Flags().StringArrayVarP(cmdData.ContainerRegistryMirror, "container-registry-mirror", "", []string{}, `Use specified mirrors for the container registry in the [REGISTRY=]URL format, e.g. ghcr.io=https://ghcr-mirror.example.com (docker.io is used if REGISTRY is not specified).
+The unavailable mirror is skipped for a while and the next mirror or the origin registry is used instead.
+Also, can be specified with $WERF_CONTAINER_REGISTRY_MIRROR_* (e.g. $WERF_CONTAINER_REGISTRY_MIRROR_1=https://mirror.gcr.io)`)
+}
+
+func SetupRegistryAuthConfig(cmdData *CmdData, cmd *cobra.Command) {
+	cmdData.RegistryAuthConfig = new(string)
+	cmd.Flags().StringVarP(cmdData.RegistryAuthConfig, "registry-auth-config", "", os.Getenv("WERF_REGISTRY_AUTH_CONFIG"), `Path to the werf registry auth config with the docker credential helpers per registry. The credentials are requested from the helpers directly and refreshed during the long operations.
+Default $WERF_REGISTRY_AUTH_CONFIG or ~/.werf/registry_auth.json`)
 }
 
 func SetupSkipTlsVerifyRegistry(cmdData *CmdData, cmd *cobra.Command) {
@@ -847,7 +899,8 @@ func SetupFollow(cmdData *CmdData, cmd *cobra.Command) {
 	cmdData.Follow = new(bool)
 	cmd.Flags().BoolVarP(cmdData.Follow, "follow", "", util.GetBoolEnvironmentDefaultFalse("WERF_FOLLOW"), `Enable follow mode (default $WERF_FOLLOW).
 The mode allows restarting the command on a new commit.
-In development mode (--dev), werf restarts the command on any changes (including untracked files) in the git repository worktree`)
+In development mode (--dev), werf restarts the command on any changes (including untracked files) in the git repository worktree.
+The changed files, which are mapped only into the gitLatestPatch stage, are synced into the running containers without restarting: for "werf kube-run" at any time, for "werf compose up" only after the detached "docker compose up" has finished (the changes made while the images are being built cause the restart)`)
 }
 
 func SetupKubeVersion(cmdData *CmdData, cmd *cobra.Command) {
@@ -999,6 +1052,10 @@ func GetSecondaryStagesStorageList(ctx context.Context, stagesStorage storage.St
 }
 
 func DockerRegistryInit(ctx context.Context, cmdData *CmdData, registryMirrors []string, buildahMode buildah.Mode) error {
+	if _, err := InitRegistryAuth(cmdData); err != nil {
+		return err
+	}
+
 	insecureHosts, err := GetInsecureRegistryHosts(ctx, cmdData, buildahMode)
 	if err != nil {
 		return fmt.Errorf("get insecure registry hosts: %w", err)
@@ -1006,6 +1063,29 @@ func DockerRegistryInit(ctx context.Context, cmdData *CmdData, registryMirrors [
 	return docker_registry.Init(ctx, *cmdData.InsecureRegistry, *cmdData.SkipTlsVerifyRegistry, registryMirrors, insecureHosts)
 }
 
+// InitRegistryAuth loads the werf registry auth config and initializes the credential helpers keychain,
+// which is used by the container registry client and the container backends.
+func InitRegistryAuth(cmdData *CmdData) (*credhelper.Keychain, error) {
+	var path string
+	if cmdData.RegistryAuthConfig != nil {
+		path = *cmdData.RegistryAuthConfig
+	}
+	if path == "" {
+		path = filepath.Join(werf.GetHomeDir(), "registry_auth.json")
+	}
+
+	cfg, err := credhelper.LoadConfig(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := credhelper.Init(cfg); err != nil {
+		return nil, fmt.Errorf("unable to init registry auth: %w", err)
+	}
+
+	return credhelper.DefaultKeychain(), nil
+}
+
 func CreateDockerRegistryWithInsecureHosts(ctx context.Context, cmdData *CmdData, addr string, buildahMode buildah.Mode) (docker_registry.Interface, error) {
 	insecureHosts, err := GetInsecureRegistryHosts(ctx, cmdData, buildahMode)
 	if err != nil {
@@ -1354,18 +1434,19 @@ func GetContainerRegistryMirror(ctx context.Context, cmdData *CmdData, buildahMo
 	var result []string
 	seen := make(map[string]bool)
 
-	for _, mirror := range cmdMirrors {
-		if strings.HasPrefix(mirror, "http://") {
-			return nil, fmt.Errorf("invalid container registry mirror %q: only https schema allowed", mirror)
+	for _, spec := range cmdMirrors {
+		m, err := mirror.Parse(spec)
+		if err != nil {
+			return nil, fmt.Errorf("invalid container registry mirror %q: %w", spec, err)
 		}
 
-		if !strings.HasPrefix(mirror, "https://") {
-			mirror = "https://" + mirror
+		if m.Insecure {
+			return nil, fmt.Errorf("invalid container registry mirror %q: only https schema allowed", spec)
 		}
 
-		if !seen[mirror] {
-			seen[mirror] = true
-			result = append(result, mirror)
+		if normalized := m.String(); !seen[normalized] {
+			seen[normalized] = true
+			result = append(result, normalized)
 		}
 	}
 
@@ -1476,6 +1557,7 @@ func GetGiterminismManager(ctx context.Context, cmdData *CmdData) (*giterminism_
 				Dev:                    *cmdData.Dev,
 				CreateIncludesLockFile: cmdData.CreateIncludesLockFile,
 				AllowIncludesUpdate:    cmdData.AllowIncludesUpdate,
+				SkipIncludesInit:       cmdData.SkipIncludesInit,
 			})
 			if err != nil {
 				return err
@@ -1693,6 +1775,8 @@ func GetOptionalWerfConfig(ctx context.Context, cmdData *CmdData, giterminismMan
 			return "", nil, err
 		}
 
+		telemetry.GetTelemetryWerfIO().SetProjectName(ctx, c.Meta.Project)
+
 		return configPath, c, nil
 	}
 
@@ -1720,6 +1804,8 @@ func GetRequiredWerfConfig(ctx context.Context, cmdData *CmdData, giterminismMan
 		return "", nil, err
 	}
 
+	telemetry.GetTelemetryWerfIO().SetProjectName(ctx, c.Meta.Project)
+
 	return configPath, c, nil
 }
 
@@ -89,6 +89,10 @@ func InitCommonComponents(ctx context.Context, opts InitCommonComponentsOptions)
 		if err := docker.InitDockerConfig(docker.InitOptions{DockerConfigDir: *opts.Cmd.DockerConfig}); err != nil {
 			return nil, ctx, fmt.Errorf("init docker config: %w", err)
 		}
+
+		if _, err := InitRegistryAuth(opts.Cmd); err != nil {
+			return nil, ctx, fmt.Errorf("init registry auth: %w", err)
+		}
 	}
 
 	if opts.InitProcessContainerBackend && resolvedBuildahMode == buildah.ModeDisabled {
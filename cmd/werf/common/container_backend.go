@@ -12,6 +12,8 @@ import (
 	"github.com/werf/werf/v2/pkg/buildah/thirdparty"
 	"github.com/werf/werf/v2/pkg/container_backend"
 	"github.com/werf/werf/v2/pkg/docker"
+	"github.com/werf/werf/v2/pkg/docker_registry/credhelper"
+	"github.com/werf/werf/v2/pkg/docker_registry/mirror"
 	"github.com/werf/werf/v2/pkg/werf"
 )
 
@@ -99,6 +101,11 @@ func InitProcessContainerBackend(ctx context.Context, cmdData *CmdData, registry
 		return nil, ctx, fmt.Errorf("unable to determine buildah mode: %w", err)
 	}
 
+	mirrors, err := mirror.ParseList(registryMirrors)
+	if err != nil {
+		return nil, ctx, fmt.Errorf("unable to parse container registry mirrors: %w", err)
+	}
+
 	if *buildahMode != buildah.ModeDisabled {
 		storageDriver, err := GetBuildahStorageDriver()
 		if err != nil {
@@ -129,6 +136,7 @@ func InitProcessContainerBackend(ctx context.Context, cmdData *CmdData, registry
 				RegistryMirrors:              registryMirrors,
 				InsecureRegistries:           insecureHosts,
 				StandaloneInsecureRegistries: standaloneInsecureHosts,
+				CredentialsKeychain:          credhelper.DefaultKeychain(),
 			},
 			NativeModeOpts: buildah.NativeModeOpts{},
 		})
@@ -136,7 +144,11 @@ func InitProcessContainerBackend(ctx context.Context, cmdData *CmdData, registry
 			return nil, ctx, fmt.Errorf("unable to get buildah client: %w", err)
 		}
 
-		return wrapContainerBackend(container_backend.NewBuildahBackend(b, container_backend.BuildahBackendOptions{TmpDir: filepath.Join(werf.GetServiceDir(), "tmp", "buildah")})), ctx, nil
+		return wrapContainerBackend(container_backend.NewBuildahBackend(b, container_backend.BuildahBackendOptions{
+			TmpDir:          filepath.Join(werf.GetServiceDir(), "tmp", "buildah"),
+			RegistryMirrors: mirrors,
+			HostLocker:      werf.HostLocker(),
+		})), ctx, nil
 	}
 
 	newCtx, err := InitProcessDocker(ctx, cmdData)
@@ -145,7 +157,7 @@ func InitProcessContainerBackend(ctx context.Context, cmdData *CmdData, registry
 	}
 	ctx = newCtx
 
-	return wrapContainerBackend(container_backend.NewDockerServerBackend(werf.HostLocker().Locker())), ctx, nil
+	return wrapContainerBackend(container_backend.NewDockerServerBackend(werf.HostLocker().Locker(), container_backend.DockerServerBackendOptions{RegistryMirrors: mirrors})), ctx, nil
 }
 
 func InitProcessDocker(ctx context.Context, cmdData *CmdData) (context.Context, error) {
@@ -154,9 +166,10 @@ func InitProcessDocker(ctx context.Context, cmdData *CmdData) (context.Context,
 	}
 
 	opts := docker.InitOptions{
-		DockerConfigDir: *cmdData.DockerConfig,
-		Verbose:         *cmdData.LogVerbose,
-		Debug:           *cmdData.LogDebug,
+		DockerConfigDir:   *cmdData.DockerConfig,
+		Verbose:           *cmdData.LogVerbose,
+		Debug:             *cmdData.LogDebug,
+		CredentialHelpers: credhelper.DefaultKeychain().CredentialHelpers(),
 	}
 
 	if err := docker.Init(ctx, opts); err != nil {
@@ -18,6 +18,7 @@ import (
 	"github.com/werf/werf/v2/pkg/image"
 	"github.com/werf/werf/v2/pkg/slug"
 	"github.com/werf/werf/v2/pkg/storage"
+	"github.com/werf/werf/v2/pkg/vulnerability_scanner"
 )
 
 func GetConveyorOptions(ctx context.Context, commonCmdData *CmdData, imagesToProcess config.ImagesToProcess) (build.ConveyorOptions, error) {
@@ -124,6 +125,11 @@ func GetBuildOptions(ctx context.Context, commonCmdData *CmdData, werfConfig *co
 		IntrospectOptions: introspectOptions,
 	}
 
+	buildOptions.VulnerabilityScanOptions, err = getVulnerabilityScanOptions(commonCmdData)
+	if err != nil {
+		return buildOptions, err
+	}
+
 	if GetSaveBuildReport(commonCmdData) {
 		buildOptions.ReportPath, buildOptions.ReportFormat, err = GetBuildReportPathAndFormat(commonCmdData)
 		if err != nil {
@@ -134,6 +140,49 @@ func GetBuildOptions(ctx context.Context, commonCmdData *CmdData, werfConfig *co
 	return buildOptions, nil
 }
 
+func getVulnerabilityScanOptions(commonCmdData *CmdData) (build.VulnerabilityScanOptions, error) {
+	var opts build.VulnerabilityScanOptions
+	if commonCmdData.VulnerabilityScanner == "" {
+		if commonCmdData.VulnerabilitySeverityThreshold != "" {
+			return opts, fmt.Errorf("--vulnerability-severity-threshold requires --vulnerability-scanner")
+		}
+		return opts, nil
+	}
+
+	if *commonCmdData.Repo.Address == "" || *commonCmdData.Repo.Address == storage.LocalStorageAddress {
+		return opts, fmt.Errorf("vulnerability scan can only be used with remote storage: --repo=ADDRESS param required")
+	}
+
+	scanner, err := vulnerability_scanner.ParseScannerType(commonCmdData.VulnerabilityScanner)
+	if err != nil {
+		return opts, fmt.Errorf("invalid --vulnerability-scanner: %w", err)
+	}
+	opts.Scanner = scanner
+
+	if commonCmdData.VulnerabilitySeverityThreshold != "" {
+		threshold, err := vulnerability_scanner.ParseSeverity(commonCmdData.VulnerabilitySeverityThreshold)
+		if err != nil {
+			return opts, fmt.Errorf("invalid --vulnerability-severity-threshold: %w", err)
+		}
+		opts.SeverityThreshold = threshold
+	}
+
+	if commonCmdData.VulnerabilityScanReportFormat != "" {
+		reportFormat, err := vulnerability_scanner.ParseReportFormat(commonCmdData.VulnerabilityScanReportFormat)
+		if err != nil {
+			return opts, fmt.Errorf("invalid --vulnerability-scan-report-format: %w", err)
+		}
+		opts.ReportFormat = reportFormat
+	}
+
+	if commonCmdData.VulnerabilityScanCacheTTL < 0 {
+		return opts, fmt.Errorf("invalid --vulnerability-scan-cache-ttl: must not be negative")
+	}
+	opts.CacheTTL = commonCmdData.VulnerabilityScanCacheTTL
+
+	return opts, nil
+}
+
 func getCustomTagFuncList(tagOptionValues []string, commonCmdData *CmdData, imagesToProcess config.ImagesToProcess) ([]image.CustomTagFunc, error) {
 	if len(tagOptionValues) == 0 {
 		return nil, nil
@@ -3,16 +3,40 @@ package common
 import (
 	"context"
 	"fmt"
+	"path/filepath"
+	"strings"
 	"time"
 
 	"github.com/werf/common-go/pkg/graceful"
 	"github.com/werf/logboek"
 	"github.com/werf/logboek/pkg/style"
 	"github.com/werf/logboek/pkg/types"
+	"github.com/werf/werf/v2/pkg/config"
+	"github.com/werf/werf/v2/pkg/follow"
 	"github.com/werf/werf/v2/pkg/giterminism_manager"
+	"github.com/werf/werf/v2/pkg/true_git"
 )
 
+// followFallbackPollInterval is used to catch the changes that cannot be noticed by the watcher,
+// e.g. refs updates in the common dir of the linked git worktree.
+const followFallbackPollInterval = 10 * time.Second
+
+type FollowGitHeadOptions struct {
+	// SyncFilesFunc syncs the changed files into the running containers instead of rerunning the task.
+	// It is used only in the dev mode when all changed files are mapped into the gitLatestPatch stage of the images.
+	SyncFilesFunc func(ctx context.Context, files []*follow.SyncFile) error
+	// SyncWhileTaskRunning allows syncing while the task is still running, e.g. into the pod of the running kube-run command.
+	// Otherwise, the changes made during the task run are followed by the rerun.
+	SyncWhileTaskRunning bool
+}
+
 func FollowGitHead(ctx context.Context, cmdData *CmdData, taskFunc func(ctx context.Context, iterGiterminismManager *giterminism_manager.Manager) error) error {
+	return FollowGitHeadWithOptions(ctx, cmdData, FollowGitHeadOptions{}, taskFunc)
+}
+
+// FollowGitHeadWithOptions runs the task and reruns it on the new commit (or on the new changes in the dev mode).
+// The changes are noticed by the file system watcher.
+func FollowGitHeadWithOptions(ctx context.Context, cmdData *CmdData, opts FollowGitHeadOptions, taskFunc func(ctx context.Context, iterGiterminismManager *giterminism_manager.Manager) error) error {
 	var waitMessage string
 	if *cmdData.Dev {
 		waitMessage = "Waiting for new changes ..."
@@ -20,49 +44,181 @@ func FollowGitHead(ctx context.Context, cmdData *CmdData, taskFunc func(ctx cont
 		waitMessage = "Waiting for the new commit ..."
 	}
 
+	giterminismManager, err := GetGiterminismManager(ctx, cmdData)
+	if err != nil {
+		return fmt.Errorf("unable to get giterminism manager: %w", err)
+	}
+
+	watcher, err := newFollowWatcher(ctx, giterminismManager)
+	if err != nil {
+		return err
+	}
+	defer watcher.Close()
+
+	relativeToGitProjectDir := giterminismManager.RelativeToGitProjectDir()
+
 	var savedHeadCommit string
-	iterFunc := func() error {
-		giterminismManager, err := GetGiterminismManager(ctx, cmdData)
-		if err != nil {
-			return fmt.Errorf("unable to get giterminism manager: %w", err)
+	var werfConfig *config.WerfConfig
+	var taskRunning, taskSucceeded bool
+	taskDone := make(chan error, 1)
+
+	rerunPending := true
+	for {
+		if rerunPending && !taskRunning {
+			rerunPending = false
+
+			iterGiterminismManager := giterminismManager
+			giterminismManager = nil
+
+			if iterGiterminismManager == nil {
+				iterGiterminismManager, err = GetGiterminismManager(ctx, cmdData)
+				if err != nil {
+					if graceful.IsTerminating(ctx) {
+						return context.Cause(ctx)
+					}
+
+					logboek.Context(ctx).Warn().LogF("unable to get giterminism manager: %s\n", err)
+					logboek.Context(ctx).LogLn(waitMessage)
+					logboek.Context(ctx).LogOptionalLn()
+					continue
+				}
+			}
+
+			if savedHeadCommit != iterGiterminismManager.HeadCommit(ctx) {
+				savedHeadCommit = iterGiterminismManager.HeadCommit(ctx)
+
+				werfConfig = nil
+				if opts.SyncFilesFunc != nil && *cmdData.Dev {
+					if _, werfConfig, err = GetOptionalWerfConfig(ctx, cmdData, iterGiterminismManager, GetWerfConfigOptions(cmdData, false)); err != nil {
+						logboek.Context(ctx).Warn().LogF("WARNING: unable to load werf config to sync changed files, the images will be rebuilt on each change: %s\n", err)
+					}
+				}
+
+				taskRunning = true
+				go func(headCommit string) {
+					taskDone <- logboek.Context(ctx).LogProcess("Commit %q", headCommit).
+						Options(func(options types.LogProcessOptionsInterface) {
+							options.Style(style.Highlight())
+						}).
+						DoError(func() error {
+							return taskFunc(ctx, iterGiterminismManager)
+						})
+				}(savedHeadCommit)
+			}
 		}
 
-		currentHeadCommit := giterminismManager.HeadCommit(ctx)
-		if savedHeadCommit != currentHeadCommit {
-			savedHeadCommit = currentHeadCommit
-
-			if err := logboek.Context(ctx).LogProcess("Commit %q", savedHeadCommit).
-				Options(func(options types.LogProcessOptionsInterface) {
-					options.Style(style.Highlight())
-				}).
-				DoError(func() error {
-					return taskFunc(ctx, giterminismManager)
-				}); err != nil {
-				return err
+		// The running task is expected to be stopped by the canceled context on its own.
+		var ctxDone <-chan struct{}
+		var fallbackPoll <-chan time.Time
+		if !taskRunning {
+			ctxDone = ctx.Done()
+			fallbackPoll = time.After(followFallbackPollInterval)
+		}
+
+		select {
+		case taskErr := <-taskDone:
+			taskRunning = false
+			taskSucceeded = taskErr == nil
+
+			if taskErr != nil {
+				if graceful.IsTerminating(ctx) {
+					return context.Cause(ctx)
+				}
+
+				logboek.Context(ctx).Warn().LogLn(taskErr)
 			}
 
 			logboek.Context(ctx).LogLn(waitMessage)
 			logboek.Context(ctx).LogOptionalLn()
-		} else {
-			time.Sleep(1 * time.Second)
+		case changes, ok := <-watcher.Changes():
+			if !ok {
+				return context.Cause(ctx)
+			}
+
+			// Without the dev mode only the new commits matter.
+			if changes.GitChanged || !*cmdData.Dev {
+				rerunPending = rerunPending || changes.GitChanged
+				continue
+			}
+
+			canSync := taskSucceeded && !taskRunning || taskRunning && opts.SyncWhileTaskRunning
+			if canSync && syncChangedFiles(ctx, werfConfig, changes.Paths, relativeToGitProjectDir, opts) {
+				// The synced changes are already in the running containers, so only the newer changes should cause the rerun.
+				if syncedGiterminismManager, err := GetGiterminismManager(ctx, cmdData); err != nil {
+					logboek.Context(ctx).Warn().LogF("WARNING: unable to get giterminism manager after sync: %s\n", err)
+				} else {
+					savedHeadCommit = syncedGiterminismManager.HeadCommit(ctx)
+				}
+
+				continue
+			}
+
+			rerunPending = true
+		case <-fallbackPoll:
+			rerunPending = true
+		case <-ctxDone:
+			return context.Cause(ctx)
 		}
+	}
+}
 
-		return nil
+func newFollowWatcher(ctx context.Context, giterminismManager giterminism_manager.Interface) (*follow.Watcher, error) {
+	workTreeDir := giterminismManager.LocalGitRepo().GetWorkTreeDir()
+
+	gitDir, err := true_git.ResolveRepoDir(ctx, filepath.Join(workTreeDir, ".git"))
+	if err != nil {
+		return nil, fmt.Errorf("unable to resolve git dir: %w", err)
 	}
 
-	if err := iterFunc(); err != nil {
-		return err
+	if !filepath.IsAbs(gitDir) {
+		gitDir = filepath.Join(workTreeDir, gitDir)
 	}
 
-	for {
-		if err := iterFunc(); err != nil {
-			if graceful.IsTerminating(ctx) {
-				return context.Cause(ctx)
-			}
+	watcher, err := follow.NewWatcher(ctx, follow.NewWatcherOptions{
+		WorkTreeDir: workTreeDir,
+		GitDir:      gitDir,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("unable to watch project changes: %w", err)
+	}
 
-			logboek.Context(ctx).Warn().LogLn(err)
-			logboek.Context(ctx).LogLn(waitMessage)
-			logboek.Context(ctx).LogOptionalLn()
+	return watcher, nil
+}
+
+// syncChangedFiles syncs the changed files into the running containers and returns true,
+// if all changes are mapped into the gitLatestPatch stage only and the sync succeeded.
+func syncChangedFiles(ctx context.Context, werfConfig *config.WerfConfig, paths []string, relativeToGitProjectDir string, opts FollowGitHeadOptions) bool {
+	if opts.SyncFilesFunc == nil || werfConfig == nil {
+		return false
+	}
+
+	analysis, err := follow.AnalyzeChanges(werfConfig, paths, follow.AnalyzeChangesOptions{
+		RelativeToGitProjectDir: relativeToGitProjectDir,
+	})
+	if err != nil {
+		logboek.Context(ctx).Warn().LogF("WARNING: unable to analyze changes: %s\n", err)
+		return false
+	}
+
+	for _, image := range analysis.Images {
+		var stages []string
+		for _, stageName := range image.Stages {
+			stages = append(stages, string(stageName))
 		}
+
+		logboek.Context(ctx).Info().LogF("Image %q is affected by %d changed file(s): %s\n", image.ImageName, len(image.Paths), strings.Join(stages, ", "))
+	}
+
+	if !analysis.SyncOnly() {
+		return false
 	}
+
+	if err := logboek.Context(ctx).LogProcess("Syncing %d changed file(s) into running containers", len(analysis.SyncFiles)).DoError(func() error {
+		return opts.SyncFilesFunc(ctx, analysis.SyncFiles)
+	}); err != nil {
+		logboek.Context(ctx).Warn().LogF("WARNING: unable to sync changed files, falling back to rebuild: %s\n", err)
+		return false
+	}
+
+	return true
 }
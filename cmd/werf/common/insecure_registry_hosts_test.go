@@ -121,6 +121,30 @@ location = "config-mirror.example.com"
 	}
 }
 
+func TestGetContainerRegistryMirror_PerRegistryMirrors(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("CONTAINERS_REGISTRIES_CONF", filepath.Join(t.TempDir(), "absent-registries.conf"))
+
+	cmdData := &CmdData{
+		ContainerRegistryMirror: &[]string{"ghcr.io=nexus.example.com/ghcr", "https://mirror.example.com/", "ghcr.io=https://nexus.example.com/ghcr"},
+	}
+	mirrors, err := GetContainerRegistryMirror(context.Background(), cmdData, buildah.ModeNative)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	expected := []string{"ghcr.io=https://nexus.example.com/ghcr", "https://mirror.example.com"}
+	if len(mirrors) != len(expected) || mirrors[0] != expected[0] || mirrors[1] != expected[1] {
+		t.Fatalf("expected %v, got: %v", expected, mirrors)
+	}
+
+	for _, spec := range []string{"ghcr.io=http://nexus.example.com", "ghcr.io/org=https://nexus.example.com"} {
+		cmdData.ContainerRegistryMirror = &[]string{spec}
+		if _, err := GetContainerRegistryMirror(context.Background(), cmdData, buildah.ModeNative); err == nil {
+			t.Fatalf("expected error for mirror %q", spec)
+		}
+	}
+}
+
 func boolPtr(v bool) *bool {
 	return &v
 }
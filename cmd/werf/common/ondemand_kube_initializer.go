@@ -42,12 +42,10 @@ func GetOndemandKubeInitializer() *OndemandKubeInitializer {
 	return ondemandKubeInitializer
 }
 
-func (initializer *OndemandKubeInitializer) Init(ctx context.Context) error {
-	if initializer.initialized {
-		return nil
-	}
-
-	kubeOpts := kube.KubeConfigOptions{
+// KubeConfigOptions returns the options used to load kube config, so that commands needing
+// a rest config of their own connect to the same cluster as the initialized clients.
+func (initializer *OndemandKubeInitializer) KubeConfigOptions() kube.KubeConfigOptions {
+	return kube.KubeConfigOptions{
 		Context:             initializer.KubeContext,
 		ConfigPath:          initializer.KubeConfig,
 		ConfigDataBase64:    initializer.KubeConfigBase64,
@@ -55,8 +53,14 @@ func (initializer *OndemandKubeInitializer) Init(ctx context.Context) error {
 		BearerToken:         initializer.BearerToken,
 		BearerTokenFile:     initializer.BearerTokenFile,
 	}
+}
+
+func (initializer *OndemandKubeInitializer) Init(ctx context.Context) error {
+	if initializer.initialized {
+		return nil
+	}
 
-	if err := kube.Init(kube.InitOptions{KubeConfigOptions: kubeOpts}); err != nil {
+	if err := kube.Init(kube.InitOptions{KubeConfigOptions: initializer.KubeConfigOptions()}); err != nil {
 		return fmt.Errorf("cannot initialize kube: %w", err)
 	}
 
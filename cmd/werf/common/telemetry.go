@@ -5,6 +5,7 @@ import (
 	"context"
 	"fmt"
 	"net"
+	"os"
 	"strings"
 
 	"github.com/go-git/go-git/v5/plumbing/transport"
@@ -16,13 +17,24 @@ import (
 	"github.com/werf/werf/v2/pkg/telemetry"
 )
 
+const TelemetryOtlpEndpointFlag = "telemetry-otlp-endpoint"
+
 var telemetryIgnoreCommands = []string{
 	"werf version",
 	"werf synchronization",
 	"werf completion",
 }
 
-func InitTelemetry(ctx context.Context) {
+// SetupTelemetryOtlpEndpoint adds the option to send the telemetry events to the own OTLP/HTTP collector.
+func SetupTelemetryOtlpEndpoint(cmd *cobra.Command) {
+	if cmd.Flags().Lookup(TelemetryOtlpEndpointFlag) != nil {
+		return
+	}
+
+	cmd.Flags().String(TelemetryOtlpEndpointFlag, os.Getenv(telemetry.EndpointEnv), fmt.Sprintf("Send telemetry events to the specified OTLP/HTTP collector endpoint, e.g. http://otel-collector:4318 (default $%s)", telemetry.EndpointEnv))
+}
+
+func InitTelemetry(ctx context.Context, endpoint string) {
 	if err := telemetry.Init(ctx, telemetry.TelemetryOptions{
 		ErrorHandlerFunc: func(err error) {
 			if err == nil {
@@ -31,6 +43,7 @@ func InitTelemetry(ctx context.Context) {
 
 			telemetry.LogF("error: %s", err)
 		},
+		Endpoint: endpoint,
 	}); err != nil {
 		telemetry.LogF("error: %s", err)
 	}
@@ -55,7 +68,12 @@ func TelemetryPreRun(cmd *cobra.Command, args []string) error {
 		}
 	}
 
-	InitTelemetry(ctx)
+	endpoint := os.Getenv(telemetry.EndpointEnv)
+	if f := cmd.Flags().Lookup(TelemetryOtlpEndpointFlag); f != nil {
+		endpoint = f.Value.String()
+	}
+
+	InitTelemetry(ctx, endpoint)
 	telemetry.GetTelemetryWerfIO().SetCommand(ctx, command)
 
 	var commandOptions []telemetry.CommandOption
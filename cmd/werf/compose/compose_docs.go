@@ -33,6 +33,7 @@ services:
     image: $WERF_FRONTEND_DOCKER_IMAGE_NAME
   backend:
     image: $WERF_GEODATA_BACKEND_DOCKER_IMAGE_NAME
+With --resolved option werf resolves the compose configuration itself: the werf images are substituted, the images are pinned by digest and the build sections are removed. The resolved configuration can be written with "werf compose config --resolved -o FILE" or run with any compose implementation specified by --compose-runner option (e.g. "podman compose" or "nerdctl compose").
 `
 
 	docs.LongMD = short + "\n\n" +
@@ -63,7 +64,8 @@ services:
 		"    image: $WERF_FRONTEND_DOCKER_IMAGE_NAME\n" +
 		"  backend:\n" +
 		"    image: $WERF_GEODATA_BACKEND_DOCKER_IMAGE_NAME\n" +
-		"```\n"
+		"```\n\n" +
+		"With `--resolved` option werf resolves the compose configuration itself: the werf images are substituted, the images are pinned by digest and the build sections are removed. The resolved configuration can be written with `werf compose config --resolved -o FILE` or run with any compose implementation specified by `--compose-runner` option (e.g. `podman compose` or `nerdctl compose`).\n"
 
 	return docs
 }
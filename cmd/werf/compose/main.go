@@ -8,18 +8,22 @@ import (
 	"fmt"
 	"os"
 	"os/exec"
-	"regexp"
+	"path/filepath"
 	"strings"
 
 	"github.com/samber/lo"
 	"github.com/spf13/cobra"
 
+	"github.com/werf/common-go/pkg/util"
 	"github.com/werf/logboek"
 	"github.com/werf/werf/v2/cmd/werf/common"
 	"github.com/werf/werf/v2/pkg/build"
 	"github.com/werf/werf/v2/pkg/config"
 	"github.com/werf/werf/v2/pkg/container_backend"
+	"github.com/werf/werf/v2/pkg/docker_registry"
+	"github.com/werf/werf/v2/pkg/follow"
 	"github.com/werf/werf/v2/pkg/giterminism_manager"
+	"github.com/werf/werf/v2/pkg/image"
 	"github.com/werf/werf/v2/pkg/tmp_manager"
 	"github.com/werf/werf/v2/pkg/true_git"
 	werfExec "github.com/werf/werf/v2/pkg/werf/exec"
@@ -32,6 +36,7 @@ type newCmdOptions struct {
 	FollowSupport bool
 	ArgsSupport   bool
 	ArgsRequired  bool
+	OutputSupport bool
 }
 
 type composeCmdData struct {
@@ -43,6 +48,12 @@ type composeCmdData struct {
 	ComposeCommandOptions []string
 	ComposeCommandArgs    []string
 
+	// Runner is the compose implementation command, e.g. "docker compose" or "podman compose".
+	Runner string
+	// Resolved enables the compose-spec native mode: werf renders the resolved compose configuration itself.
+	Resolved   bool
+	OutputPath string
+
 	ImageNameListFromArgs []string
 }
 
@@ -55,9 +66,19 @@ func (d *composeCmdData) extractImageNameListFromComposeConfig(ctx context.Conte
 		return s
 	}
 
-	extractedImageNameList, err := extractImageNamesFromComposeConfig(ctx, d.getComposeFileCustomPathList())
-	if err != nil {
-		return nil, fmt.Errorf("unable to extract image names from docker-compose file: %w", err)
+	var extractedImageNameList []string
+	if d.Resolved {
+		project, err := d.loadComposeProject()
+		if err != nil {
+			return nil, fmt.Errorf("unable to extract image names from docker-compose file: %w", err)
+		}
+		extractedImageNameList = extractImageNamesFromComposeProject(project)
+	} else {
+		var err error
+		extractedImageNameList, err = d.extractImageNamesFromComposeConfig(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("unable to extract image names from docker-compose file: %w", err)
+		}
 	}
 
 	configImageNameList := werfConfig.GetImageNameList(false)
@@ -96,14 +117,21 @@ func (d *composeCmdData) getComposeFileCustomPathList() []string {
 	return result
 }
 
-func extractImageNamesFromComposeConfig(ctx context.Context, customConfigPathList []string) ([]string, error) {
-	composeArgs := []string{"compose"}
-	for _, p := range customConfigPathList {
+// extractImageNamesFromComposeConfig returns the werf image names used in the compose configuration printed by the compose runner
+// for the enabled profiles.
+func (d *composeCmdData) extractImageNamesFromComposeConfig(ctx context.Context) ([]string, error) {
+	runner := strings.Fields(d.Runner)
+
+	composeArgs := append([]string{}, runner[1:]...)
+	for _, p := range d.getComposeFileCustomPathList() {
 		composeArgs = append(composeArgs, "--file", p)
 	}
+	for _, profile := range d.getComposeProfiles() {
+		composeArgs = append(composeArgs, "--profile", profile)
+	}
 	composeArgs = append(composeArgs, "config", "--no-interpolate")
 
-	cmd := werfExec.CommandContextCancellation(ctx, "docker", composeArgs...)
+	cmd := werfExec.CommandContextCancellation(ctx, runner[0], composeArgs...)
 
 	var stdout, stderr bytes.Buffer
 	cmd.Stdout = &stdout
@@ -121,9 +149,6 @@ func extractImageNamesFromComposeConfig(ctx context.Context, customConfigPathLis
 
 	output := stdout.Bytes()
 
-	// Matches $WERF_<IMAGE_NAME>_DOCKER_IMAGE_NAME and ${WERF_<IMAGE_NAME>_DOCKER_IMAGE_NAME}.
-	re := regexp.MustCompile(`\${?WERF_(.*)_DOCKER_IMAGE_NAME}?`)
-
 	var imageNames []string
 	scanner := bufio.NewScanner(strings.NewReader(string(output)))
 	for scanner.Scan() {
@@ -134,7 +159,7 @@ func extractImageNamesFromComposeConfig(ctx context.Context, customConfigPathLis
 			continue
 		}
 
-		matches := re.FindAllStringSubmatch(line, -1)
+		matches := imageEnvRegexp.FindAllStringSubmatch(line, -1)
 		for _, match := range matches {
 			if len(match) > 1 {
 				imageName := strings.ToLower(match[1])
@@ -150,6 +175,50 @@ func extractImageNamesFromComposeConfig(ctx context.Context, customConfigPathLis
 	return imageNames, nil
 }
 
+func (d *composeCmdData) loadComposeProject() (*composeProject, error) {
+	return loadComposeProject(loadComposeProjectOptions{
+		Files:    d.getComposeFileCustomPathList(),
+		Profiles: d.getComposeProfiles(),
+	})
+}
+
+// getComposeProfiles returns the profiles enabled with --profile compose option and $COMPOSE_PROFILES.
+func (d *composeCmdData) getComposeProfiles() []string {
+	var result []string
+	for _, profile := range strings.Split(os.Getenv("COMPOSE_PROFILES"), ",") {
+		if profile = strings.TrimSpace(profile); profile != "" {
+			result = append(result, profile)
+		}
+	}
+
+	for ind, value := range d.ComposeOptions {
+		if value == "--profile" && len(d.ComposeOptions) > ind+1 {
+			result = append(result, d.ComposeOptions[ind+1])
+		} else if strings.HasPrefix(value, "--profile=") {
+			result = append(result, strings.TrimPrefix(value, "--profile="))
+		}
+	}
+
+	return result
+}
+
+// getComposeOptionsWithoutFiles returns the compose options without the compose files.
+func (d *composeCmdData) getComposeOptionsWithoutFiles() []string {
+	var result []string
+	for ind := 0; ind < len(d.ComposeOptions); ind++ {
+		value := d.ComposeOptions[ind]
+		switch {
+		case value == "-f" || value == "--file":
+			ind++
+		case strings.HasPrefix(value, "-f") || strings.HasPrefix(value, "--file="):
+		default:
+			result = append(result, value)
+		}
+	}
+
+	return result
+}
+
 func NewConfigCmd(ctx context.Context) *cobra.Command {
 	return newCmd(ctx, "config", &newCmdOptions{
 		Use: "config [IMAGE_NAME...] [options] [--docker-compose-options=\"OPTIONS\"] [--docker-compose-command-options=\"OPTIONS\"]",
@@ -166,9 +235,14 @@ func NewConfigCmd(ctx context.Context) *cobra.Command {
   # Print docker-compose command without executing
   $ werf compose config --docker-compose-options="-f docker-compose-test.yml" --docker-compose-command-options="--resolve-image-digests" --dry-run --quiet
   export WERF_APP_DOCKER_IMAGE_NAME=project:570c59946a7f77873d361efd25a637c4ccde86abf3d3186add19bded-1604928781528
-  docker-compose -f docker-compose-test.yml config --resolve-image-digests`,
+  docker-compose -f docker-compose-test.yml config --resolve-image-digests
+
+  # Write the resolved compose file with werf images pinned by digest and run it with Podman
+  $ werf compose config --repo registry.example.com/project --resolved -o compose.resolved.yaml
+  $ podman compose -f compose.resolved.yaml up`,
 		FollowSupport: false,
 		ArgsSupport:   false,
+		OutputSupport: true,
 	})
 }
 
@@ -194,6 +268,9 @@ func NewUpCmd(ctx context.Context) *cobra.Command {
   # Follow git HEAD and run docker-compose up for each new commit
   $ werf compose up --follow --docker-compose-command-options="-d"
 
+  # Run podman compose up with the resolved compose file
+  $ werf compose up --compose-runner="podman compose" --resolved
+
   # Print docker-compose command without executing
   $ werf compose up --docker-compose-options="-f docker-compose-test.yml" --docker-compose-command-options="--abort-on-container-exit -t 20" --dry-run --quiet
   export WERF_APP_DOCKER_IMAGE_NAME=localhost:5000/project:570c59946a7f77873d361efd25a637c4ccde86abf3d3186add19bded-1604928781528
@@ -275,6 +352,15 @@ func newCmd(ctx context.Context, composeCmdName string, options *newCmdOptions)
 				cmdData.ComposeCommandOptions = strings.Fields(cmdData.RawComposeCommandOptions)
 			}
 
+			if cmdData.Runner == "" {
+				cmdData.Runner = "docker compose"
+			}
+
+			if cmdData.OutputPath != "" && !cmdData.Resolved {
+				common.PrintHelp(cmd)
+				return fmt.Errorf("--output option requires --resolved option")
+			}
+
 			return runMain(ctx, composeCmdName, cmdData, commonCmdData, options.FollowSupport)
 		},
 	})
@@ -316,6 +402,7 @@ func newCmd(ctx context.Context, composeCmdName string, options *newCmdOptions)
 	common.StubSetupInsecureHelmDependencies(&commonCmdData, cmd)
 	common.SetupSkipTlsVerifyRegistry(&commonCmdData, cmd)
 	common.SetupContainerRegistryMirror(&commonCmdData, cmd)
+	common.SetupRegistryAuthConfig(&commonCmdData, cmd)
 
 	common.SetupLogOptions(&commonCmdData, cmd)
 	common.SetupLogProjectDir(&commonCmdData, cmd)
@@ -338,6 +425,11 @@ func newCmd(ctx context.Context, composeCmdName string, options *newCmdOptions)
 	commonCmdData.SetupBackendNetwork(cmd)
 	commonCmdData.SetupDebugTemplates(cmd)
 
+	cmd.Flags().StringVarP(&cmdData.Runner, "compose-runner", "", os.Getenv("WERF_COMPOSE_RUNNER"), `Compose implementation command, e.g. "podman compose" or "nerdctl compose" (default $WERF_COMPOSE_RUNNER or "docker compose")`)
+	cmd.Flags().BoolVarP(&cmdData.Resolved, "resolved", "", util.GetBoolEnvironmentDefaultFalse("WERF_COMPOSE_RESOLVED"), `Resolve the compose configuration natively instead of passing the image names via environment variables: werf images are substituted, images are pinned by digest, build sections are removed (default $WERF_COMPOSE_RESOLVED or false)`)
+	if options.OutputSupport {
+		cmd.Flags().StringVarP(&cmdData.OutputPath, "output", "o", os.Getenv("WERF_COMPOSE_OUTPUT"), "Write the resolved compose configuration to the file instead of stdout, requires --resolved (default $WERF_COMPOSE_OUTPUT)")
+	}
 	cmd.Flags().StringVarP(&cmdData.RawComposeOptions, "docker-compose-options", "", os.Getenv("WERF_DOCKER_COMPOSE_OPTIONS"), "Define docker-compose options (default $WERF_DOCKER_COMPOSE_OPTIONS)")
 	cmd.Flags().StringVarP(&cmdData.RawComposeCommandOptions, "docker-compose-command-options", "", os.Getenv("WERF_DOCKER_COMPOSE_COMMAND_OPTIONS"), "Define docker-compose command options (default $WERF_DOCKER_COMPOSE_COMMAND_OPTIONS)")
 
@@ -419,18 +511,26 @@ func runMain(ctx context.Context, dockerComposeCmdName string, cmdData composeCm
 			return err
 		}
 
-		return common.FollowGitHead(ctx, &commonCmdData, func(ctx context.Context, headCommitGiterminismManager *giterminism_manager.Manager) error {
-			return run(ctx, containerBackend, headCommitGiterminismManager, commonCmdData, cmdData, dockerComposeCmdName)
+		var envArray []string
+		return common.FollowGitHeadWithOptions(ctx, &commonCmdData, common.FollowGitHeadOptions{
+			SyncFilesFunc: func(ctx context.Context, files []*follow.SyncFile) error {
+				return syncFilesIntoContainers(ctx, giterminismManager.LocalGitRepo().GetWorkTreeDir(), envArray, files)
+			},
+		}, func(ctx context.Context, headCommitGiterminismManager *giterminism_manager.Manager) error {
+			var err error
+			envArray, err = run(ctx, containerBackend, headCommitGiterminismManager, commonCmdData, cmdData, dockerComposeCmdName)
+			return err
 		})
 	} else {
-		return run(ctx, containerBackend, giterminismManager, commonCmdData, cmdData, dockerComposeCmdName)
+		_, err := run(ctx, containerBackend, giterminismManager, commonCmdData, cmdData, dockerComposeCmdName)
+		return err
 	}
 }
 
-func run(ctx context.Context, containerBackend container_backend.ContainerBackend, giterminismManager giterminism_manager.Interface, commonCmdData common.CmdData, cmdData composeCmdData, dockerComposeCmdName string) error {
+func run(ctx context.Context, containerBackend container_backend.ContainerBackend, giterminismManager giterminism_manager.Interface, commonCmdData common.CmdData, cmdData composeCmdData, dockerComposeCmdName string) ([]string, error) {
 	_, werfConfig, err := common.GetRequiredWerfConfig(ctx, &commonCmdData, giterminismManager, common.GetWerfConfigOptions(&commonCmdData, true))
 	if err != nil {
-		return fmt.Errorf("unable to load werf config: %w", err)
+		return nil, fmt.Errorf("unable to load werf config: %w", err)
 	}
 
 	var imageNameList []string
@@ -439,30 +539,32 @@ func run(ctx context.Context, containerBackend container_backend.ContainerBacken
 	} else {
 		imageNameListFromComposeConfig, err := cmdData.extractImageNameListFromComposeConfig(ctx, werfConfig)
 		if err != nil {
-			return err
+			return nil, err
 		}
 		imageNameList = imageNameListFromComposeConfig
 	}
 
 	imagesToProcess, err := config.NewImagesToProcess(werfConfig, imageNameList, *commonCmdData.FinalImagesOnly, *commonCmdData.WithoutImages)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	shouldBeBuilt := !*commonCmdData.StubTags
 
 	var envArray []string
+	// werfImageDigests are the digests of the werf images by their names (empty if the digest is unknown).
+	werfImageDigests := map[string]string{}
 	if !imagesToProcess.WithoutImages && shouldBeBuilt {
 		common.SetupOndemandKubeInitializer(commonCmdData.KubeContextCurrent, commonCmdData.LegacyKubeConfigPath, commonCmdData.KubeConfigBase64, commonCmdData.LegacyKubeConfigPathsMergeList, commonCmdData.KubeBearerTokenData, commonCmdData.KubeBearerTokenPath)
 		if err := common.GetOndemandKubeInitializer().Init(ctx); err != nil {
-			return err
+			return nil, err
 		}
 
 		projectName := werfConfig.Meta.Project
 
 		projectTmpDir, err := tmp_manager.CreateProjectDir(ctx)
 		if err != nil {
-			return fmt.Errorf("getting project tmp dir failed: %w", err)
+			return nil, fmt.Errorf("getting project tmp dir failed: %w", err)
 		}
 
 		storageManager, err := common.NewStorageManager(ctx, &common.NewStorageManagerConfig{
@@ -473,25 +575,33 @@ func run(ctx context.Context, containerBackend container_backend.ContainerBacken
 			GitHistoryBasedCleanupDisabled: werfConfig.Meta.Cleanup.DisableGitHistoryBasedPolicy,
 		})
 		if err != nil {
-			return fmt.Errorf("unable to init storage manager: %w", err)
+			return nil, fmt.Errorf("unable to init storage manager: %w", err)
 		}
 
 		logboek.Context(ctx).Default().LogOptionalLn()
 
 		conveyorOptions, err := common.GetConveyorOptions(ctx, &commonCmdData, imagesToProcess)
 		if err != nil {
-			return err
+			return nil, err
 		}
 
 		conveyorWithRetry := build.NewConveyorWithRetryWrapper(werfConfig, giterminismManager, giterminismManager.ProjectDir(), projectTmpDir, containerBackend, storageManager, storageManager.StorageLockManager, conveyorOptions)
 		defer conveyorWithRetry.Terminate()
 
 		if err := conveyorWithRetry.WithRetryBlock(ctx, func(c *build.Conveyor) error {
+			var infoGetters []*image.InfoGetter
 			if c.UseBuildReport {
 				envArray, err = c.GetImagesEnvArrayFromReport(ctx)
 				if err != nil {
 					return fmt.Errorf("unable to get images env array from build report: %w", err)
 				}
+
+				if cmdData.Resolved {
+					infoGetters, err = c.GetImageInfoGettersFromReport(ctx, image.InfoGetterOptions{})
+					if err != nil {
+						return err
+					}
+				}
 			} else {
 				if common.GetRequireBuiltImages(&commonCmdData) {
 					if _, err := c.ShouldBeBuilt(ctx, build.ShouldBeBuiltOptions{}); err != nil {
@@ -515,11 +625,22 @@ func run(ctx context.Context, containerBackend container_backend.ContainerBacken
 				}
 
 				envArray = c.GetImagesEnvArray()
+
+				if cmdData.Resolved {
+					infoGetters, err = c.GetImageInfoGetters(image.InfoGetterOptions{})
+					if err != nil {
+						return err
+					}
+				}
+			}
+
+			for _, getter := range infoGetters {
+				werfImageDigests[getter.GetName()] = getter.Digest
 			}
 
 			return nil
 		}); err != nil {
-			return err
+			return nil, err
 		}
 	} else {
 		for _, imageName := range imagesToProcess.ImageNameList {
@@ -527,8 +648,43 @@ func run(ctx context.Context, containerBackend container_backend.ContainerBacken
 		}
 	}
 
+	composeOptions := cmdData.ComposeOptions
+	if cmdData.Resolved {
+		project, err := cmdData.loadComposeProject()
+		if err != nil {
+			return nil, fmt.Errorf("unable to load compose configuration: %w", err)
+		}
+
+		for _, env := range envArray {
+			if _, ref, found := strings.Cut(env, "="); found {
+				if _, ok := werfImageDigests[ref]; !ok {
+					werfImageDigests[ref] = ""
+				}
+			}
+		}
+
+		data, err := resolveComposeProject(ctx, project, resolveComposeProjectOptions{
+			ImageEnvs:    envArray,
+			PinImageFunc: newPinImageFunc(werfImageDigests),
+		})
+		if err != nil {
+			return nil, fmt.Errorf("unable to resolve compose configuration: %w", err)
+		}
+
+		if dockerComposeCmdName == "config" {
+			return envArray, writeResolvedComposeConfig(cmdData.OutputPath, data)
+		}
+
+		resolvedConfigPath, err := createResolvedComposeConfigFile(ctx, data)
+		if err != nil {
+			return nil, err
+		}
+
+		composeOptions = append([]string{"--file", resolvedConfigPath}, cmdData.getComposeOptionsWithoutFiles()...)
+	}
+
 	var dockerComposeArgs []string
-	dockerComposeArgs = append(dockerComposeArgs, cmdData.ComposeOptions...)
+	dockerComposeArgs = append(dockerComposeArgs, composeOptions...)
 	dockerComposeArgs = append(dockerComposeArgs, dockerComposeCmdName)
 	dockerComposeArgs = append(dockerComposeArgs, cmdData.ComposeCommandOptions...)
 
@@ -537,17 +693,16 @@ func run(ctx context.Context, containerBackend container_backend.ContainerBacken
 		dockerComposeArgs = append(dockerComposeArgs, cmdData.ComposeCommandArgs...)
 	}
 
-	// TODO: use docker SDK instead of host docker binary
+	runner := strings.Fields(cmdData.Runner)
+
 	if *commonCmdData.DryRun {
 		for _, env := range envArray {
 			fmt.Println("export", env)
 		}
-		fmt.Printf("docker compose %s\n", strings.Join(dockerComposeArgs, " "))
-		return nil
+		fmt.Printf("%s %s\n", strings.Join(runner, " "), strings.Join(dockerComposeArgs, " "))
+		return envArray, nil
 	} else {
-		dockerComposeArgs = append([]string{"compose"}, dockerComposeArgs...)
-
-		cmd := werfExec.CommandContextCancellation(ctx, "docker", dockerComposeArgs...)
+		cmd := werfExec.CommandContextCancellation(ctx, runner[0], append(runner[1:], dockerComposeArgs...)...)
 
 		cmd.Stdin = os.Stdin
 		cmd.Stdout = os.Stdout
@@ -556,9 +711,63 @@ func run(ctx context.Context, containerBackend container_backend.ContainerBacken
 
 		if err := cmd.Run(); err != nil {
 			werfExec.TerminateIfCanceled(ctx)
-			return fmt.Errorf("error running command %q: %s", cmd, err)
+			return nil, fmt.Errorf("error running command %q: %s", cmd, err)
+		}
+	}
+
+	return envArray, nil
+}
+
+// newPinImageFunc returns the function, which pins the werf images by the known digests
+// and the other images by the digests from the container registry.
+func newPinImageFunc(werfImageDigests map[string]string) func(ctx context.Context, ref string) (string, error) {
+	return func(ctx context.Context, ref string) (string, error) {
+		if digest, ok := werfImageDigests[ref]; ok {
+			if digest == "" {
+				return "", nil
+			}
+			return ref + "@" + digest, nil
 		}
+
+		// The image may be available only locally, so it is used as is.
+		info, err := docker_registry.API().GetRepoImage(ctx, ref)
+		if err != nil {
+			logboek.Context(ctx).Warn().LogF("WARNING: Unable to get digest of image %q, the image is not pinned: %s\n", ref, err)
+			return "", nil
+		}
+
+		if info.GetDigest() == "" {
+			logboek.Context(ctx).Warn().LogF("WARNING: Unable to get digest of image %q, the image is not pinned\n", ref)
+			return "", nil
+		}
+
+		return ref + "@" + info.GetDigest(), nil
+	}
+}
+
+func writeResolvedComposeConfig(path string, data []byte) error {
+	if path == "" {
+		_, err := os.Stdout.Write(data)
+		return err
+	}
+
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		return fmt.Errorf("unable to write resolved compose configuration: %w", err)
 	}
 
 	return nil
 }
+
+func createResolvedComposeConfigFile(ctx context.Context, data []byte) (string, error) {
+	dir, err := tmp_manager.CreateProjectDir(ctx)
+	if err != nil {
+		return "", fmt.Errorf("unable to create tmp dir for resolved compose configuration: %w", err)
+	}
+
+	path := filepath.Join(dir, "compose.yaml")
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		return "", fmt.Errorf("unable to write resolved compose configuration: %w", err)
+	}
+
+	return path, nil
+}
@@ -0,0 +1,47 @@
+package compose
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("extractImageNamesFromComposeConfig", func() {
+	var runner string
+
+	BeforeEach(func() {
+		// The fake runner prints the compose config, the debug service is printed only with the debug profile.
+		runner = filepath.Join(GinkgoT().TempDir(), "compose")
+		Expect(os.WriteFile(runner, []byte(`#!/bin/sh
+echo 'services:'
+echo '  app:'
+echo '    image: $WERF_APP_DOCKER_IMAGE_NAME'
+echo '    command: echo ${WERF_MIGRATE_DOCKER_IMAGE_NAME} ${WERF_WORKER_DOCKER_IMAGE_NAME}'
+case " $* " in
+  *" --profile debug "*)
+    echo '  debug:'
+    echo '    image: ${WERF_DEBUG_DOCKER_IMAGE_NAME}'
+    ;;
+esac
+`), 0o755)).To(Succeed())
+	})
+
+	It("extracts every image used in the line", func() {
+		d := &composeCmdData{Runner: runner}
+
+		imageNames, err := d.extractImageNamesFromComposeConfig(context.Background())
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(imageNames).To(Equal([]string{"app", "migrate", "worker"}))
+	})
+
+	It("extracts the images of the services of the enabled profiles", func() {
+		d := &composeCmdData{Runner: runner, ComposeOptions: []string{"--profile", "debug"}}
+
+		imageNames, err := d.extractImageNamesFromComposeConfig(context.Background())
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(imageNames).To(Equal([]string{"app", "migrate", "worker", "debug"}))
+	})
+})
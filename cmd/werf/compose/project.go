@@ -0,0 +1,470 @@
+package compose
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+var (
+	// composeDefaultFileNames are looked up in the working directory if no compose files specified (in the order of priority).
+	composeDefaultFileNames         = []string{"compose.yaml", "compose.yml", "docker-compose.yaml", "docker-compose.yml"}
+	composeDefaultOverrideFileNames = []string{"compose.override.yaml", "compose.override.yml", "docker-compose.override.yaml", "docker-compose.override.yml"}
+
+	// composeServiceAppendKeys are the service sequences, which are concatenated when merging compose files (other sequences are replaced).
+	composeServiceAppendKeys = []string{"cap_add", "cap_drop", "devices", "dns", "dns_search", "env_file", "expose", "external_links", "ports", "security_opt", "tmpfs", "volumes"}
+	// composeServiceMappingKeys can be defined either as mappings or as sequences of KEY=VALUE.
+	composeServiceMappingKeys = []string{"annotations", "environment", "labels"}
+
+	composeProjectNameInvalidCharsRegexp = regexp.MustCompile(`[^a-z0-9_-]`)
+)
+
+type loadComposeProjectOptions struct {
+	// Files are merged in the given order. The default compose files from the WorkingDir are used if empty.
+	Files []string
+	// Profiles enable services with the matching profiles. The "*" profile enables all services.
+	Profiles []string
+	// WorkingDir is the current directory by default.
+	WorkingDir string
+}
+
+// composeProject is the compose configuration merged from the compose files with the extends resolved
+// and without the services disabled by the profiles. The relative host paths are made absolute,
+// so that the configuration can be written to any location.
+type composeProject struct {
+	Dir    string
+	Config map[string]interface{}
+}
+
+func (p *composeProject) Services() map[string]interface{} {
+	services, _ := p.Config["services"].(map[string]interface{})
+	return services
+}
+
+// Name returns the project name from the configuration or the one derived from the project directory like compose does.
+func (p *composeProject) Name() string {
+	if name, ok := p.Config["name"].(string); ok && name != "" {
+		return name
+	}
+
+	name := composeProjectNameInvalidCharsRegexp.ReplaceAllString(strings.ToLower(filepath.Base(p.Dir)), "")
+	return strings.TrimLeft(name, "_-")
+}
+
+func loadComposeProject(opts loadComposeProjectOptions) (*composeProject, error) {
+	workingDir := opts.WorkingDir
+	if workingDir == "" {
+		wd, err := os.Getwd()
+		if err != nil {
+			return nil, fmt.Errorf("unable to get working directory: %w", err)
+		}
+		workingDir = wd
+	}
+
+	files, err := getComposeFiles(workingDir, opts.Files)
+	if err != nil {
+		return nil, err
+	}
+
+	project := &composeProject{
+		Dir:    filepath.Dir(files[0]),
+		Config: map[string]interface{}{},
+	}
+
+	for _, file := range files {
+		config, err := readComposeFile(file, project.Dir)
+		if err != nil {
+			return nil, err
+		}
+
+		mergeComposeConfig(project.Config, config)
+	}
+
+	services := project.Services()
+
+	serviceNames := make([]string, 0, len(services))
+	for name := range services {
+		serviceNames = append(serviceNames, name)
+	}
+	sort.Strings(serviceNames)
+
+	for _, name := range serviceNames {
+		if _, err := resolveComposeServiceExtends(services, "", name, []string{":" + name}); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, name := range serviceNames {
+		service, _ := services[name].(map[string]interface{})
+		if !isComposeServiceEnabled(service, opts.Profiles) {
+			delete(services, name)
+			continue
+		}
+		delete(service, "profiles")
+	}
+
+	return project, nil
+}
+
+func getComposeFiles(workingDir string, files []string) ([]string, error) {
+	var result []string
+	for _, file := range files {
+		if !filepath.IsAbs(file) {
+			file = filepath.Join(workingDir, file)
+		}
+		result = append(result, file)
+	}
+
+	if len(result) > 0 {
+		return result, nil
+	}
+
+	for _, names := range [][]string{composeDefaultFileNames, composeDefaultOverrideFileNames} {
+		for _, name := range names {
+			file := filepath.Join(workingDir, name)
+			if _, err := os.Stat(file); err == nil {
+				result = append(result, file)
+				break
+			} else if !os.IsNotExist(err) {
+				return nil, fmt.Errorf("unable to stat %q: %w", file, err)
+			}
+		}
+
+		if len(result) == 0 {
+			return nil, fmt.Errorf("no compose file found in %q: expected one of %s", workingDir, strings.Join(composeDefaultFileNames, ", "))
+		}
+	}
+
+	return result, nil
+}
+
+// readComposeFile reads the compose file and normalizes its services. The relative host paths are resolved against baseDir,
+// the extended files are resolved against the directory of the file.
+func readComposeFile(file, baseDir string) (map[string]interface{}, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read compose file: %w", err)
+	}
+
+	config := map[string]interface{}{}
+	if err := yaml.Unmarshal(data, &config); err != nil {
+		return nil, fmt.Errorf("unable to parse compose file %q: %w", file, err)
+	}
+
+	if services, ok := config["services"].(map[string]interface{}); ok {
+		for name, value := range services {
+			service, ok := value.(map[string]interface{})
+			if !ok {
+				if value != nil {
+					return nil, fmt.Errorf("service %q in %q must be a mapping", name, file)
+				}
+				service = map[string]interface{}{}
+				services[name] = service
+			}
+
+			if err := normalizeComposeService(service, baseDir, filepath.Dir(file)); err != nil {
+				return nil, fmt.Errorf("service %q in %q: %w", name, file, err)
+			}
+		}
+	}
+
+	for _, key := range []string{"configs", "secrets"} {
+		if items, ok := config[key].(map[string]interface{}); ok {
+			for _, item := range items {
+				if m, ok := item.(map[string]interface{}); ok {
+					if path, ok := m["file"].(string); ok {
+						m["file"] = absComposeHostPath(path, baseDir)
+					}
+				}
+			}
+		}
+	}
+
+	return config, nil
+}
+
+func normalizeComposeService(service map[string]interface{}, baseDir, fileDir string) error {
+	for _, key := range composeServiceMappingKeys {
+		if value, ok := service[key]; ok {
+			m, err := composeSequenceToMapping(value)
+			if err != nil {
+				return fmt.Errorf("invalid %s: %w", key, err)
+			}
+			service[key] = m
+		}
+	}
+
+	if volumes, ok := service["volumes"].([]interface{}); ok {
+		for i, volume := range volumes {
+			switch v := volume.(type) {
+			case string:
+				parts := strings.SplitN(v, ":", 2)
+				if len(parts) == 2 && isRelativeComposeHostPath(parts[0]) {
+					volumes[i] = absComposeHostPath(parts[0], baseDir) + ":" + parts[1]
+				}
+			case map[string]interface{}:
+				if source, ok := v["source"].(string); ok && v["type"] == "bind" {
+					v["source"] = absComposeHostPath(source, baseDir)
+				}
+			}
+		}
+	}
+
+	switch envFile := service["env_file"].(type) {
+	case string:
+		service["env_file"] = []interface{}{absComposeHostPath(envFile, baseDir)}
+	case []interface{}:
+		for i, item := range envFile {
+			switch v := item.(type) {
+			case string:
+				envFile[i] = absComposeHostPath(v, baseDir)
+			case map[string]interface{}:
+				if path, ok := v["path"].(string); ok {
+					v["path"] = absComposeHostPath(path, baseDir)
+				}
+			}
+		}
+	}
+
+	if extends, ok := service["extends"].(map[string]interface{}); ok {
+		if file, ok := extends["file"].(string); ok {
+			extends["file"] = absComposeHostPath(file, fileDir)
+		}
+	}
+
+	return nil
+}
+
+// resolveComposeServiceExtends merges the service with the services it extends and replaces it in the services.
+// The file is the compose file of the services (empty for the project services), it is used to detect circular extends.
+func resolveComposeServiceExtends(services map[string]interface{}, file, name string, stack []string) (map[string]interface{}, error) {
+	service, ok := services[name].(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("service %q not found", name)
+	}
+
+	extends, ok := service["extends"]
+	if !ok {
+		return service, nil
+	}
+
+	var baseName, baseFile string
+	switch v := extends.(type) {
+	case string:
+		baseName = v
+	case map[string]interface{}:
+		baseName, _ = v["service"].(string)
+		baseFile, _ = v["file"].(string)
+	}
+
+	if baseName == "" {
+		return nil, fmt.Errorf("service %q: extends must specify the service", name)
+	}
+
+	if baseFile == "" {
+		baseFile = file
+	}
+
+	key := baseFile + ":" + baseName
+	for _, k := range stack {
+		if k == key {
+			return nil, fmt.Errorf("service %q: circular extends of %s", name, strings.Join(append(stack, key), " -> "))
+		}
+	}
+
+	baseServices := services
+	if baseFile != file {
+		config, err := readComposeFile(baseFile, filepath.Dir(baseFile))
+		if err != nil {
+			return nil, fmt.Errorf("service %q: %w", name, err)
+		}
+
+		baseServices, _ = config["services"].(map[string]interface{})
+		if baseServices == nil {
+			return nil, fmt.Errorf("service %q: no services in %q", name, baseFile)
+		}
+	}
+
+	base, err := resolveComposeServiceExtends(baseServices, baseFile, baseName, append(stack, key))
+	if err != nil {
+		return nil, fmt.Errorf("service %q: %w", name, err)
+	}
+
+	result := deepCopyComposeValue(base).(map[string]interface{})
+	delete(service, "extends")
+	mergeComposeService(result, service)
+	services[name] = result
+
+	return result, nil
+}
+
+func isComposeServiceEnabled(service map[string]interface{}, activeProfiles []string) bool {
+	profiles, ok := service["profiles"].([]interface{})
+	if !ok || len(profiles) == 0 {
+		return true
+	}
+
+	for _, active := range activeProfiles {
+		if active == "*" {
+			return true
+		}
+
+		for _, profile := range profiles {
+			if profile == active {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+func mergeComposeConfig(dst, src map[string]interface{}) {
+	for key, value := range src {
+		if key != "services" {
+			dst[key] = mergeComposeValue(dst[key], value)
+			continue
+		}
+
+		srcServices, _ := value.(map[string]interface{})
+		dstServices, ok := dst[key].(map[string]interface{})
+		if !ok {
+			dstServices = map[string]interface{}{}
+			dst[key] = dstServices
+		}
+
+		for name, service := range srcServices {
+			dstService, ok := dstServices[name].(map[string]interface{})
+			if !ok {
+				dstServices[name] = service
+				continue
+			}
+			mergeComposeService(dstService, service.(map[string]interface{}))
+		}
+	}
+}
+
+func mergeComposeService(dst, src map[string]interface{}) {
+	for key, value := range src {
+		dstList, dstIsList := dst[key].([]interface{})
+		srcList, srcIsList := value.([]interface{})
+
+		if dstIsList && srcIsList && isComposeServiceAppendKey(key) {
+			dst[key] = appendUniqueComposeValues(dstList, srcList)
+			continue
+		}
+
+		dst[key] = mergeComposeValue(dst[key], value)
+	}
+}
+
+// mergeComposeValue merges the mappings recursively, other values are overridden.
+func mergeComposeValue(dst, src interface{}) interface{} {
+	dstMap, dstIsMap := dst.(map[string]interface{})
+	srcMap, srcIsMap := src.(map[string]interface{})
+	if !dstIsMap || !srcIsMap {
+		return src
+	}
+
+	for key, value := range srcMap {
+		dstMap[key] = mergeComposeValue(dstMap[key], value)
+	}
+
+	return dstMap
+}
+
+func isComposeServiceAppendKey(key string) bool {
+	for _, k := range composeServiceAppendKeys {
+		if k == key {
+			return true
+		}
+	}
+	return false
+}
+
+func appendUniqueComposeValues(dst, src []interface{}) []interface{} {
+	seen := map[string]struct{}{}
+	for _, value := range dst {
+		seen[fmt.Sprintf("%v", value)] = struct{}{}
+	}
+
+	for _, value := range src {
+		key := fmt.Sprintf("%v", value)
+		if _, ok := seen[key]; ok {
+			continue
+		}
+		seen[key] = struct{}{}
+		dst = append(dst, value)
+	}
+
+	return dst
+}
+
+// composeSequenceToMapping converts ["KEY=VALUE", "KEY"] to {KEY: VALUE, KEY: null}.
+func composeSequenceToMapping(value interface{}) (map[string]interface{}, error) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		return v, nil
+	case nil:
+		return map[string]interface{}{}, nil
+	case []interface{}:
+		result := map[string]interface{}{}
+		for _, item := range v {
+			s, ok := item.(string)
+			if !ok {
+				return nil, fmt.Errorf("expected KEY=VALUE string, got %v", item)
+			}
+
+			if key, val, found := strings.Cut(s, "="); found {
+				result[key] = val
+			} else {
+				result[key] = nil
+			}
+		}
+		return result, nil
+	default:
+		return nil, fmt.Errorf("expected mapping or sequence, got %v", value)
+	}
+}
+
+func isRelativeComposeHostPath(path string) bool {
+	return path == "." || path == ".." || strings.HasPrefix(path, "./") || strings.HasPrefix(path, "../") || path == "~" || strings.HasPrefix(path, "~/")
+}
+
+func absComposeHostPath(path, baseDir string) string {
+	switch {
+	case path == "~" || strings.HasPrefix(path, "~/"):
+		if home, err := os.UserHomeDir(); err == nil {
+			return filepath.Join(home, strings.TrimPrefix(path, "~"))
+		}
+		return path
+	case filepath.IsAbs(path) || strings.HasPrefix(path, "$"):
+		return path
+	default:
+		return filepath.Join(baseDir, path)
+	}
+}
+
+func deepCopyComposeValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		result := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			result[key] = deepCopyComposeValue(item)
+		}
+		return result
+	case []interface{}:
+		result := make([]interface{}, len(v))
+		for i, item := range v {
+			result[i] = deepCopyComposeValue(item)
+		}
+		return result
+	default:
+		return v
+	}
+}
@@ -0,0 +1,151 @@
+package compose
+
+import (
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("compose project", func() {
+	var dir string
+
+	writeFile := func(name, content string) {
+		path := filepath.Join(dir, name)
+		Expect(os.MkdirAll(filepath.Dir(path), 0o755)).To(Succeed())
+		Expect(os.WriteFile(path, []byte(content), 0o644)).To(Succeed())
+	}
+
+	BeforeEach(func() {
+		dir = GinkgoT().TempDir()
+	})
+
+	It("uses the default compose file with the override file", func() {
+		writeFile("compose.yaml", `
+services:
+  app:
+    image: $WERF_APP_DOCKER_IMAGE_NAME
+    ports: ["80:80"]
+    environment: [A=1, B=2]
+`)
+		writeFile("compose.override.yaml", `
+services:
+  app:
+    ports: ["443:443"]
+    environment:
+      B: "3"
+`)
+
+		project, err := loadComposeProject(loadComposeProjectOptions{WorkingDir: dir})
+		Expect(err).NotTo(HaveOccurred())
+
+		app := project.Services()["app"].(map[string]interface{})
+		Expect(app["ports"]).To(Equal([]interface{}{"80:80", "443:443"}))
+		Expect(app["environment"]).To(Equal(map[string]interface{}{"A": "1", "B": "3"}))
+	})
+
+	It("fails if there is no compose file", func() {
+		_, err := loadComposeProject(loadComposeProjectOptions{WorkingDir: dir})
+		Expect(err).To(MatchError(ContainSubstring("no compose file found")))
+	})
+
+	It("resolves extends from the same and from another file", func() {
+		writeFile("common/base.yaml", `
+services:
+  base:
+    image: $WERF_BASE_DOCKER_IMAGE_NAME
+    env_file: base.env
+    environment:
+      FROM_BASE: "1"
+`)
+		writeFile("compose.yaml", `
+services:
+  worker:
+    extends:
+      service: app
+    command: [worker]
+  app:
+    extends:
+      file: common/base.yaml
+      service: base
+    environment:
+      FROM_APP: "1"
+    volumes: [./data:/data, cache:/cache]
+`)
+
+		project, err := loadComposeProject(loadComposeProjectOptions{WorkingDir: dir})
+		Expect(err).NotTo(HaveOccurred())
+
+		worker := project.Services()["worker"].(map[string]interface{})
+		Expect(worker).NotTo(HaveKey("extends"))
+		Expect(worker["image"]).To(Equal("$WERF_BASE_DOCKER_IMAGE_NAME"))
+		Expect(worker["command"]).To(Equal([]interface{}{"worker"}))
+		Expect(worker["environment"]).To(Equal(map[string]interface{}{"FROM_BASE": "1", "FROM_APP": "1"}))
+		Expect(worker["env_file"]).To(Equal([]interface{}{filepath.Join(dir, "common", "base.env")}))
+		Expect(worker["volumes"]).To(Equal([]interface{}{filepath.Join(dir, "data") + ":/data", "cache:/cache"}))
+	})
+
+	It("detects circular extends", func() {
+		writeFile("compose.yaml", `
+services:
+  a:
+    extends: b
+  b:
+    extends: a
+`)
+
+		_, err := loadComposeProject(loadComposeProjectOptions{WorkingDir: dir})
+		Expect(err).To(MatchError(ContainSubstring("circular extends")))
+	})
+
+	DescribeTable("enables services by profiles",
+		func(profiles, expectedServices []string) {
+			writeFile("docker-compose.yml", `
+services:
+  app:
+    image: app
+  debug:
+    image: debug
+    profiles: [debug]
+  tools:
+    image: tools
+    profiles: [tools, debug]
+`)
+
+			project, err := loadComposeProject(loadComposeProjectOptions{WorkingDir: dir, Profiles: profiles})
+			Expect(err).NotTo(HaveOccurred())
+
+			var services []string
+			for name, service := range project.Services() {
+				Expect(service).NotTo(HaveKey("profiles"))
+				services = append(services, name)
+			}
+			Expect(services).To(ConsistOf(expectedServices))
+		},
+		Entry("without profiles", nil, []string{"app"}),
+		Entry("with profile", []string{"tools"}, []string{"app", "tools"}),
+		Entry("with shared profile", []string{"debug"}, []string{"app", "debug", "tools"}),
+		Entry("with all profiles", []string{"*"}, []string{"app", "debug", "tools"}),
+	)
+
+	It("derives the project name from the directory", func() {
+		project := &composeProject{Dir: "/work/My.Project_1", Config: map[string]interface{}{}}
+		Expect(project.Name()).To(Equal("myproject_1"))
+
+		project.Config["name"] = "custom"
+		Expect(project.Name()).To(Equal("custom"))
+	})
+})
+
+var _ = Describe("composeCmdData", func() {
+	It("returns the profiles from the compose options", func() {
+		d := &composeCmdData{ComposeOptions: []string{"--profile", "a", "-f", "compose.yaml", "--profile=b"}}
+		Expect(d.getComposeProfiles()).To(Equal([]string{"a", "b"}))
+	})
+
+	It("removes the compose files from the compose options", func() {
+		d := &composeCmdData{ComposeOptions: []string{"-f", "a.yaml", "--file=b.yaml", "-fc.yaml", "--file", "d.yaml", "-p", "project"}}
+		Expect(d.getComposeOptionsWithoutFiles()).To(Equal([]string{"-p", "project"}))
+	})
+})
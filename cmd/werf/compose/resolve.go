@@ -0,0 +1,138 @@
+package compose
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/samber/lo"
+	"gopkg.in/yaml.v3"
+)
+
+// Matches $WERF_<IMAGE_NAME>_DOCKER_IMAGE_NAME and ${WERF_<IMAGE_NAME>_DOCKER_IMAGE_NAME}.
+var imageEnvRegexp = regexp.MustCompile(`\${?WERF_(.*?)_DOCKER_IMAGE_NAME}?`)
+
+type resolveComposeProjectOptions struct {
+	// ImageEnvs are the werf image variables in the KEY=VALUE format, which are substituted in the service images.
+	ImageEnvs []string
+	// PinImageFunc returns the image reference pinned by digest or empty string if the digest is unknown.
+	PinImageFunc func(ctx context.Context, ref string) (string, error)
+}
+
+// resolveComposeProject makes the compose configuration self-contained, so that any compose implementation can run it
+// without werf variables: the werf images are substituted, the images are pinned by digest, the build sections are removed
+// and the project name is set explicitly.
+func resolveComposeProject(ctx context.Context, project *composeProject, opts resolveComposeProjectOptions) ([]byte, error) {
+	imageEnvs := map[string]string{}
+	for _, env := range opts.ImageEnvs {
+		if key, value, found := strings.Cut(env, "="); found {
+			imageEnvs[key] = value
+		}
+	}
+
+	services := project.Services()
+
+	serviceNames := make([]string, 0, len(services))
+	for name := range services {
+		serviceNames = append(serviceNames, name)
+	}
+	sort.Strings(serviceNames)
+
+	for _, name := range serviceNames {
+		service := services[name].(map[string]interface{})
+
+		_, hasBuild := service["build"]
+		delete(service, "build")
+		if service["pull_policy"] == "build" {
+			delete(service, "pull_policy")
+		}
+
+		image, _ := service["image"].(string)
+		if image == "" {
+			if hasBuild {
+				return nil, fmt.Errorf("service %q: the build section is not supported in the resolved mode, use werf image instead", name)
+			}
+			return nil, fmt.Errorf("service %q: image is not specified", name)
+		}
+
+		image, err := resolveComposeImage(ctx, image, imageEnvs, opts.PinImageFunc)
+		if err != nil {
+			return nil, fmt.Errorf("service %q: %w", name, err)
+		}
+		service["image"] = image
+	}
+
+	project.Config["name"] = project.Name()
+
+	data, err := yaml.Marshal(project.Config)
+	if err != nil {
+		return nil, fmt.Errorf("unable to marshal compose configuration: %w", err)
+	}
+
+	return data, nil
+}
+
+func resolveComposeImage(ctx context.Context, image string, imageEnvs map[string]string, pinImageFunc func(ctx context.Context, ref string) (string, error)) (string, error) {
+	var unknownEnvs []string
+	image = imageEnvRegexp.ReplaceAllStringFunc(image, func(match string) string {
+		key := strings.Trim(match, "${}")
+		if value, ok := imageEnvs[key]; ok {
+			return value
+		}
+
+		unknownEnvs = append(unknownEnvs, key)
+		return match
+	})
+
+	if len(unknownEnvs) > 0 {
+		return "", fmt.Errorf("unable to substitute %s: no such werf image built", strings.Join(unknownEnvs, ", "))
+	}
+
+	// Images with the other variables are interpolated by the compose implementation.
+	if pinImageFunc == nil || strings.Contains(image, "$") || strings.Contains(image, "@") {
+		return image, nil
+	}
+
+	pinned, err := pinImageFunc(ctx, image)
+	if err != nil {
+		return "", fmt.Errorf("unable to pin image %q by digest: %w", image, err)
+	}
+
+	if pinned == "" {
+		return image, nil
+	}
+
+	return pinned, nil
+}
+
+// extractImageNamesFromComposeProject returns the werf image names used by the enabled services of the project.
+func extractImageNamesFromComposeProject(project *composeProject) []string {
+	var imageNames []string
+	walkComposeStrings(project.Services(), func(s string) {
+		for _, match := range imageEnvRegexp.FindAllStringSubmatch(s, -1) {
+			imageNames = append(imageNames, strings.ToLower(match[1]))
+		}
+	})
+
+	sort.Strings(imageNames)
+
+	return lo.Uniq(imageNames)
+}
+
+func walkComposeStrings(value interface{}, f func(s string)) {
+	switch v := value.(type) {
+	case string:
+		f(v)
+	case map[string]interface{}:
+		for key, item := range v {
+			f(key)
+			walkComposeStrings(item, f)
+		}
+	case []interface{}:
+		for _, item := range v {
+			walkComposeStrings(item, f)
+		}
+	}
+}
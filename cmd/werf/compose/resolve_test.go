@@ -0,0 +1,89 @@
+package compose
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"gopkg.in/yaml.v3"
+)
+
+var _ = Describe("resolveComposeProject", func() {
+	newProject := func() *composeProject {
+		return &composeProject{
+			Dir: "/work/app",
+			Config: map[string]interface{}{
+				"services": map[string]interface{}{
+					"app": map[string]interface{}{
+						"image":       "${WERF_APP_DOCKER_IMAGE_NAME}",
+						"build":       ".",
+						"pull_policy": "build",
+					},
+					"db": map[string]interface{}{
+						"image": "postgres:16",
+					},
+					"cache": map[string]interface{}{
+						"image": "redis@sha256:0000",
+					},
+				},
+			},
+		}
+	}
+
+	pinImageFunc := func(_ context.Context, ref string) (string, error) {
+		switch ref {
+		case "registry.example.com/app:tag":
+			return ref + "@sha256:1111", nil
+		case "postgres:16":
+			return ref + "@sha256:2222", nil
+		}
+		return "", nil
+	}
+
+	It("substitutes werf images, pins images by digest and removes build sections", func() {
+		data, err := resolveComposeProject(context.Background(), newProject(), resolveComposeProjectOptions{
+			ImageEnvs:    []string{"WERF_APP_DOCKER_IMAGE_NAME=registry.example.com/app:tag"},
+			PinImageFunc: pinImageFunc,
+		})
+		Expect(err).NotTo(HaveOccurred())
+
+		var config map[string]interface{}
+		Expect(yaml.Unmarshal(data, &config)).To(Succeed())
+		Expect(config["name"]).To(Equal("app"))
+		Expect(config["services"]).To(Equal(map[string]interface{}{
+			"app":   map[string]interface{}{"image": "registry.example.com/app:tag@sha256:1111"},
+			"db":    map[string]interface{}{"image": "postgres:16@sha256:2222"},
+			"cache": map[string]interface{}{"image": "redis@sha256:0000"},
+		}))
+	})
+
+	It("fails if the werf image is not built", func() {
+		_, err := resolveComposeProject(context.Background(), newProject(), resolveComposeProjectOptions{})
+		Expect(err).To(MatchError(ContainSubstring("unable to substitute WERF_APP_DOCKER_IMAGE_NAME")))
+	})
+
+	It("fails if the service has only the build section", func() {
+		project := newProject()
+		delete(project.Services()["app"].(map[string]interface{}), "image")
+
+		_, err := resolveComposeProject(context.Background(), project, resolveComposeProjectOptions{})
+		Expect(err).To(MatchError(ContainSubstring(`service "app": the build section is not supported`)))
+	})
+})
+
+var _ = Describe("extractImageNamesFromComposeProject", func() {
+	It("returns the werf image names used by the services", func() {
+		project := &composeProject{Config: map[string]interface{}{
+			"services": map[string]interface{}{
+				"a": map[string]interface{}{"image": "$WERF_FRONTEND_DOCKER_IMAGE_NAME"},
+				"b": map[string]interface{}{"image": "${WERF_GEODATA_BACKEND_DOCKER_IMAGE_NAME}"},
+				"c": map[string]interface{}{
+					"image":       "alpine",
+					"environment": map[string]interface{}{"IMAGE": "${WERF_FRONTEND_DOCKER_IMAGE_NAME}"},
+				},
+			},
+		}}
+
+		Expect(extractImageNamesFromComposeProject(project)).To(Equal([]string{"frontend", "geodata_backend"}))
+	})
+})
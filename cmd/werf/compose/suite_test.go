@@ -0,0 +1,13 @@
+package compose
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestCmdCompose(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Cmd Compose Suite")
+}
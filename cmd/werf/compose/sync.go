@@ -0,0 +1,78 @@
+package compose
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/filters"
+
+	"github.com/werf/logboek"
+	"github.com/werf/werf/v2/pkg/build"
+	"github.com/werf/werf/v2/pkg/docker"
+	"github.com/werf/werf/v2/pkg/follow"
+)
+
+// syncFilesIntoContainers copies the changed files into the running containers of the compose services, which use the werf images.
+func syncFilesIntoContainers(ctx context.Context, workTreeDir string, envArray []string, files []*follow.SyncFile) error {
+	for imageName, imageFiles := range follow.GroupSyncFilesByImage(files) {
+		dockerImageName, ok := imageDockerNameFromEnvArray(envArray, imageName)
+		if !ok {
+			return fmt.Errorf("image %q is not used by the compose services", imageName)
+		}
+
+		containers, err := docker.Containers(ctx, types.ContainerListOptions{
+			Filters: filters.NewArgs(filters.Arg("ancestor", dockerImageName)),
+		})
+		if err != nil {
+			return fmt.Errorf("unable to list containers of image %q: %w", imageName, err)
+		}
+
+		if len(containers) == 0 {
+			return fmt.Errorf("no running containers of image %q", imageName)
+		}
+
+		for _, container := range containers {
+			if err := syncFilesIntoContainer(ctx, workTreeDir, container.ID, imageFiles); err != nil {
+				return fmt.Errorf("unable to sync files into container %s of image %q: %w", container.ID, imageName, err)
+			}
+
+			logboek.Context(ctx).Default().LogF("Synced %d file(s) into container %s of image %q\n", len(imageFiles), container.ID[:12], imageName)
+		}
+	}
+
+	return nil
+}
+
+func syncFilesIntoContainer(ctx context.Context, workTreeDir, containerID string, files []*follow.SyncFile) error {
+	archive := bytes.NewBuffer(nil)
+	commands, err := follow.WriteSyncFilesArchive(archive, workTreeDir, files)
+	if err != nil {
+		return fmt.Errorf("unable to prepare files archive: %w", err)
+	}
+
+	if err := docker.ContainerCopyTo(ctx, containerID, "/", archive); err != nil {
+		return fmt.Errorf("unable to copy files: %w", err)
+	}
+
+	for _, command := range commands {
+		if output, err := docker.CliExec_RecordedOutput(ctx, append([]string{containerID}, command...)...); err != nil {
+			return fmt.Errorf("unable to run %q: %w\n%s", strings.Join(command, " "), err, output)
+		}
+	}
+
+	return nil
+}
+
+func imageDockerNameFromEnvArray(envArray []string, imageName string) (string, bool) {
+	envName := strings.TrimSuffix(build.GenerateImageEnv(imageName, ""), "=")
+	for _, env := range envArray {
+		if name, value, ok := strings.Cut(env, "="); ok && name == envName {
+			return value, true
+		}
+	}
+
+	return "", false
+}
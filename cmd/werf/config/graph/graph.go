@@ -3,11 +3,13 @@ package graph
 import (
 	"context"
 	"fmt"
+	"os"
 	"strings"
 
 	"github.com/spf13/cobra"
 	"gopkg.in/yaml.v2"
 
+	"github.com/werf/common-go/pkg/util"
 	"github.com/werf/logboek"
 	"github.com/werf/werf/v2/cmd/werf/common"
 	"github.com/werf/werf/v2/pkg/config"
@@ -16,6 +18,11 @@ import (
 	"github.com/werf/werf/v2/pkg/werf/global_warnings"
 )
 
+var cmdData struct {
+	Output string
+	Stages bool
+}
+
 var commonCmdData common.CmdData
 
 func NewCmd(ctx context.Context) *cobra.Command {
@@ -46,6 +53,19 @@ func NewCmd(ctx context.Context) *cobra.Command {
       from: baseImage
       import:
       - app1
+
+  # Print dependency graph in DOT format with stages and render it with graphviz
+  $ werf config graph --output dot --stages | dot -Tsvg > graph.svg
+
+  # Print dependency graph in Mermaid format
+  $ werf config graph --output mermaid
+  flowchart LR
+    n0["app1"]
+    n1["app2"]
+    n2["baseImage"]
+    n2 ==>|fromImage| n0
+    n2 ==>|fromImage| n1
+    n0 -.->|import| n1
 `,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			ctx := cmd.Context()
@@ -57,6 +77,19 @@ func NewCmd(ctx context.Context) *cobra.Command {
 				return err
 			}
 
+			output := outputFormat(cmdData.Output)
+			switch output {
+			case outputFormatYaml, outputFormatJson, outputFormatDot, outputFormatMermaid:
+			default:
+				common.PrintHelp(cmd)
+				return fmt.Errorf("unsupported output format %q: expected one of yaml, json, dot or mermaid", cmdData.Output)
+			}
+
+			if cmdData.Stages && output == outputFormatYaml {
+				common.PrintHelp(cmd)
+				return fmt.Errorf("--stages option can be used only with json, dot or mermaid output format")
+			}
+
 			_, ctx, err := common.InitCommonComponents(ctx, common.InitCommonComponentsOptions{
 				Cmd: &commonCmdData,
 				InitTrueGitWithOptions: &common.InitTrueGitOptions{
@@ -107,17 +140,40 @@ func NewCmd(ctx context.Context) *cobra.Command {
 				return err
 			}
 
-			graphList, err := werfConfig.GetImageGraphList(imagesToProcess)
-			if err != nil {
-				return err
+			if output == outputFormatYaml {
+				graphList, err := werfConfig.GetImageGraphList(imagesToProcess)
+				if err != nil {
+					return err
+				}
+
+				data, err := yaml.Marshal(graphList)
+				if err != nil {
+					return err
+				}
+
+				fmt.Println(strings.TrimSpace(string(data)))
+				return nil
 			}
 
-			data, err := yaml.Marshal(graphList)
+			g, err := buildImagesGraph(ctx, werfConfig, imagesToProcess, giterminismManager, buildImagesGraphOptions{WithStages: cmdData.Stages})
 			if err != nil {
-				return err
+				return fmt.Errorf("unable to build images graph: %w", err)
 			}
 
-			fmt.Println(strings.TrimSpace(string(data)))
+			var data string
+			switch output {
+			case outputFormatJson:
+				data, err = renderJson(g)
+				if err != nil {
+					return err
+				}
+			case outputFormatDot:
+				data = renderDot(g)
+			case outputFormatMermaid:
+				data = renderMermaid(g)
+			}
+
+			fmt.Println(data)
 			return nil
 		},
 	})
@@ -142,5 +198,12 @@ func NewCmd(ctx context.Context) *cobra.Command {
 
 	commonCmdData.SetupAllowIncludesUpdate(cmd)
 
+	defaultOutput := os.Getenv("WERF_CONFIG_GRAPH_OUTPUT")
+	if defaultOutput == "" {
+		defaultOutput = string(outputFormatYaml)
+	}
+	cmd.Flags().StringVarP(&cmdData.Output, "output", "o", defaultOutput, "Output format: yaml, json, dot or mermaid (default $WERF_CONFIG_GRAPH_OUTPUT or yaml)")
+	cmd.Flags().BoolVarP(&cmdData.Stages, "stages", "", util.GetBoolEnvironmentDefaultFalse("WERF_CONFIG_GRAPH_STAGES"), "Show image stages and connect dependencies to the stages they affect. Can be used only with json, dot or mermaid output format (default $WERF_CONFIG_GRAPH_STAGES or false)")
+
 	return cmd
 }
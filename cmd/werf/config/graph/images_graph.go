@@ -0,0 +1,295 @@
+package graph
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"regexp"
+
+	"github.com/werf/common-go/pkg/util"
+	"github.com/werf/werf/v2/pkg/build/image"
+	"github.com/werf/werf/v2/pkg/build/stage"
+	"github.com/werf/werf/v2/pkg/config"
+	"github.com/werf/werf/v2/pkg/dockerfile"
+	"github.com/werf/werf/v2/pkg/dockerfile/frontend"
+	"github.com/werf/werf/v2/pkg/giterminism_manager"
+)
+
+type imageNodeType string
+
+const (
+	imageNodeTypeStapel     imageNodeType = "stapel"
+	imageNodeTypeDockerfile imageNodeType = "dockerfile"
+	imageNodeTypeExternal   imageNodeType = "external"
+)
+
+type edgeType string
+
+const (
+	edgeTypeFromImage      edgeType = "fromImage"
+	edgeTypeImport         edgeType = "import"
+	edgeTypeDependency     edgeType = "dependency"
+	edgeTypeDockerfileFrom edgeType = "dockerfileFrom"
+)
+
+type imagesGraph struct {
+	Images []*imageNode `json:"images"`
+	Edges  []*imageEdge `json:"edges"`
+}
+
+type imageNode struct {
+	Name   string        `json:"name"`
+	Type   imageNodeType `json:"type"`
+	Stages []string      `json:"stages,omitempty"`
+}
+
+// imageEdge is directed from the image that is required to the image that requires it.
+// FromStage and ToStage are set only when the graph is built with stages.
+type imageEdge struct {
+	From      string   `json:"from"`
+	FromStage string   `json:"fromStage,omitempty"`
+	To        string   `json:"to"`
+	ToStage   string   `json:"toStage,omitempty"`
+	Type      edgeType `json:"type"`
+}
+
+type buildImagesGraphOptions struct {
+	WithStages bool
+}
+
+func buildImagesGraph(ctx context.Context, werfConfig *config.WerfConfig, imagesToProcess config.ImagesToProcess, giterminismManager giterminism_manager.Interface, opts buildImagesGraphOptions) (*imagesGraph, error) {
+	graphList, err := werfConfig.GetImageGraphList(imagesToProcess)
+	if err != nil {
+		return nil, err
+	}
+
+	g := &imagesGraph{}
+	externalImages := map[string]bool{}
+	addExternalImage := func(name string) {
+		if externalImages[name] {
+			return
+		}
+		externalImages[name] = true
+		g.Images = append(g.Images, &imageNode{Name: name, Type: imageNodeTypeExternal})
+	}
+
+	for _, imageGraph := range graphList {
+		switch imageConfig := werfConfig.GetImage(imageGraph.ImageName).(type) {
+		case config.StapelImageInterface:
+			imageBaseConfig := imageConfig.ImageBaseConfig()
+			node := &imageNode{Name: imageBaseConfig.Name, Type: imageNodeTypeStapel}
+			if opts.WithStages {
+				for _, stageName := range image.StapelStageNames(ctx, imageConfig) {
+					node.Stages = append(node.Stages, string(stageName))
+				}
+			}
+			g.Images = append(g.Images, node)
+
+			if imageGraph.DependsOn.From != "" {
+				g.Edges = append(g.Edges, &imageEdge{From: imageGraph.DependsOn.From, To: node.Name, ToStage: stageIfEnabled(opts, string(stage.From)), Type: edgeTypeFromImage})
+			} else if imageBaseConfig.FromExternal && imageBaseConfig.From != "" && imageBaseConfig.From != "scratch" {
+				addExternalImage(imageBaseConfig.From)
+				g.Edges = append(g.Edges, &imageEdge{From: imageBaseConfig.From, To: node.Name, ToStage: stageIfEnabled(opts, string(stage.From)), Type: edgeTypeFromImage})
+			}
+
+			for _, imp := range imageBaseConfig.Import {
+				fromImage := imp.ImageName
+				if fromImage == "" {
+					fromImage = imp.ArtifactName
+				}
+				if fromImage == "" || imp.ExternalImage {
+					continue
+				}
+
+				g.Edges = append(g.Edges, &imageEdge{From: fromImage, FromStage: stageIfEnabled(opts, imp.Stage), To: node.Name, ToStage: stageIfEnabled(opts, dependenciesStageName(imp.Before, imp.After)), Type: edgeTypeImport})
+			}
+
+			for _, dep := range imageBaseConfig.Dependencies {
+				g.Edges = append(g.Edges, &imageEdge{From: dep.ImageName, To: node.Name, ToStage: stageIfEnabled(opts, dependenciesStageName(dep.Before, dep.After)), Type: edgeTypeDependency})
+			}
+		case *config.ImageFromDockerfile:
+			node := &imageNode{Name: imageConfig.Name, Type: imageNodeTypeDockerfile}
+			g.Images = append(g.Images, node)
+
+			for _, dep := range imageConfig.Dependencies {
+				g.Edges = append(g.Edges, &imageEdge{From: dep.ImageName, To: node.Name, Type: edgeTypeDependency})
+			}
+
+			d, err := parseDockerfile(ctx, imageConfig, giterminismManager)
+			if err != nil {
+				return nil, err
+			}
+
+			stages, err := dockerfileTargetStages(d)
+			if err != nil {
+				return nil, fmt.Errorf("unable to get target stages of image %q dockerfile: %w", imageConfig.Name, err)
+			}
+
+			stageNames := dockerfileStageNames(d)
+			stageName := func(s *dockerfile.DockerfileStage) (string, error) {
+				name, ok := stageNames[s]
+				if !ok {
+					return "", fmt.Errorf("stage %q is not found in image %q dockerfile", s.BaseName, imageConfig.Name)
+				}
+				return name, nil
+			}
+
+			for _, dockerfileStage := range stages {
+				name, err := stageName(dockerfileStage)
+				if err != nil {
+					return nil, err
+				}
+
+				if opts.WithStages {
+					node.Stages = append(node.Stages, name)
+				}
+
+				if dockerfileStage.BaseStage != nil {
+					if opts.WithStages {
+						baseStageName, err := stageName(dockerfileStage.BaseStage)
+						if err != nil {
+							return nil, err
+						}
+						g.Edges = append(g.Edges, &imageEdge{From: node.Name, FromStage: baseStageName, To: node.Name, ToStage: name, Type: edgeTypeDockerfileFrom})
+					}
+				} else if depImageName := dependencyImageNameByArg(dockerfileStage.BaseName, imageConfig.Dependencies); depImageName != "" {
+					g.Edges = append(g.Edges, &imageEdge{From: depImageName, To: node.Name, ToStage: stageIfEnabled(opts, name), Type: edgeTypeDockerfileFrom})
+				} else if dockerfileStage.BaseName != "scratch" {
+					addExternalImage(dockerfileStage.BaseName)
+					g.Edges = append(g.Edges, &imageEdge{From: dockerfileStage.BaseName, To: node.Name, ToStage: stageIfEnabled(opts, name), Type: edgeTypeDockerfileFrom})
+				}
+
+				if !opts.WithStages {
+					continue
+				}
+
+				for _, dep := range dockerfileStage.Dependencies {
+					if dep == dockerfileStage.BaseStage {
+						continue
+					}
+
+					depStageName, err := stageName(dep)
+					if err != nil {
+						return nil, err
+					}
+					g.Edges = append(g.Edges, &imageEdge{From: node.Name, FromStage: depStageName, To: node.Name, ToStage: name, Type: edgeTypeImport})
+				}
+			}
+		default:
+			return nil, fmt.Errorf("unexpected image %q config type %T", imageGraph.ImageName, imageConfig)
+		}
+	}
+
+	return g, nil
+}
+
+func stageIfEnabled(opts buildImagesGraphOptions, stageName string) string {
+	if !opts.WithStages {
+		return ""
+	}
+	return stageName
+}
+
+func dependenciesStageName(before, after string) string {
+	switch {
+	case before == string(stage.Install):
+		return string(stage.DependenciesBeforeInstall)
+	case after == string(stage.Install):
+		return string(stage.DependenciesAfterInstall)
+	case before == string(stage.Setup):
+		return string(stage.DependenciesBeforeSetup)
+	case after == string(stage.Setup):
+		return string(stage.DependenciesAfterSetup)
+	default:
+		return ""
+	}
+}
+
+func parseDockerfile(ctx context.Context, imageConfig *config.ImageFromDockerfile, giterminismManager giterminism_manager.Interface) (*dockerfile.Dockerfile, error) {
+	relDockerfilePath := filepath.Join(imageConfig.Context, imageConfig.Dockerfile)
+	dockerfileData, err := giterminismManager.FileReader().ReadDockerfile(ctx, relDockerfilePath)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read dockerfile %s: %w", relDockerfilePath, err)
+	}
+
+	var dependenciesArgsKeys []string
+	for _, dep := range imageConfig.Dependencies {
+		for _, imp := range dep.Imports {
+			dependenciesArgsKeys = append(dependenciesArgsKeys, imp.TargetBuildArg)
+		}
+	}
+
+	d, err := frontend.ParseDockerfileWithBuildkit(util.Sha256Hash(filepath.Clean(relDockerfilePath)), dockerfileData, imageConfig.Name, dockerfile.DockerfileOptions{
+		Target:               imageConfig.Target,
+		BuildArgs:            util.MapStringInterfaceToMapStringString(imageConfig.Args),
+		DependenciesArgsKeys: dependenciesArgsKeys,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse dockerfile %s: %w", relDockerfilePath, err)
+	}
+
+	return d, nil
+}
+
+// dockerfileTargetStages returns the stages required to build the target stage ordered as in the Dockerfile.
+func dockerfileTargetStages(d *dockerfile.Dockerfile) ([]*dockerfile.DockerfileStage, error) {
+	targetStage, err := d.GetTargetStage()
+	if err != nil {
+		return nil, err
+	}
+
+	required := map[*dockerfile.DockerfileStage]bool{}
+	queue := []*dockerfile.DockerfileStage{targetStage}
+	for len(queue) > 0 {
+		stage := queue[0]
+		queue = queue[1:]
+		if required[stage] {
+			continue
+		}
+		required[stage] = true
+		queue = append(queue, stage.Dependencies...)
+	}
+
+	var stages []*dockerfile.DockerfileStage
+	for _, stage := range d.Stages {
+		if required[stage] {
+			stages = append(stages, stage)
+		}
+	}
+
+	return stages, nil
+}
+
+// dockerfileStageNames returns the names of the Dockerfile stages, the unnamed stage is named by its index.
+func dockerfileStageNames(d *dockerfile.Dockerfile) map[*dockerfile.DockerfileStage]string {
+	names := make(map[*dockerfile.DockerfileStage]string, len(d.Stages))
+	for i, s := range d.Stages {
+		if s.HasStageName() {
+			names[s] = s.StageName
+		} else {
+			names[s] = fmt.Sprintf("stage%d", i)
+		}
+	}
+
+	return names
+}
+
+var dockerfileArgRefRegexp = regexp.MustCompile(`^\$\{?([a-zA-Z_][a-zA-Z0-9_]*)\}?$`)
+
+// dependencyImageNameByArg returns the werf image name if the base image of the Dockerfile stage is set by the dependency build arg, e.g. FROM $BASE_IMAGE.
+func dependencyImageNameByArg(baseName string, dependencies []*config.Dependency) string {
+	match := dockerfileArgRefRegexp.FindStringSubmatch(baseName)
+	if match == nil {
+		return ""
+	}
+
+	for _, dep := range dependencies {
+		for _, imp := range dep.Imports {
+			if imp.TargetBuildArg == match[1] {
+				return dep.ImageName
+			}
+		}
+	}
+
+	return ""
+}
@@ -0,0 +1,187 @@
+package graph
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+type outputFormat string
+
+const (
+	outputFormatYaml    outputFormat = "yaml"
+	outputFormatJson    outputFormat = "json"
+	outputFormatDot     outputFormat = "dot"
+	outputFormatMermaid outputFormat = "mermaid"
+)
+
+var dotEdgeAttrsByType = map[edgeType]string{
+	edgeTypeFromImage:      `style=bold`,
+	edgeTypeImport:         `style=dashed`,
+	edgeTypeDependency:     `style=dotted`,
+	edgeTypeDockerfileFrom: `style=bold, color=blue`,
+}
+
+var mermaidArrowByType = map[edgeType]string{
+	edgeTypeFromImage:      "==>",
+	edgeTypeImport:         "-.->",
+	edgeTypeDependency:     "-.->",
+	edgeTypeDockerfileFrom: "==>",
+}
+
+func renderJson(g *imagesGraph) (string, error) {
+	data, err := json.MarshalIndent(g, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("marshal graph to json: %w", err)
+	}
+
+	return string(data), nil
+}
+
+func renderDot(g *imagesGraph) string {
+	var b strings.Builder
+
+	b.WriteString("digraph werf {\n")
+	b.WriteString("  rankdir=LR;\n")
+	b.WriteString("  compound=true;\n")
+	b.WriteString("  node [shape=box];\n")
+
+	for i, image := range g.Images {
+		switch {
+		case image.Type == imageNodeTypeExternal:
+			fmt.Fprintf(&b, "  %s [style=dashed];\n", dotQuote(image.Name))
+		case len(image.Stages) == 0:
+			fmt.Fprintf(&b, "  %s;\n", dotQuote(image.Name))
+		default:
+			fmt.Fprintf(&b, "  subgraph cluster_%d {\n", i)
+			fmt.Fprintf(&b, "    label=%s;\n", dotQuote(image.Name))
+			for _, stage := range image.Stages {
+				fmt.Fprintf(&b, "    %s [label=%s];\n", dotQuote(stageNodeKey(image.Name, stage)), dotQuote(stage))
+			}
+			for _, chainEdge := range stapelStagesChain(image) {
+				fmt.Fprintf(&b, "    %s -> %s;\n", dotQuote(chainEdge[0]), dotQuote(chainEdge[1]))
+			}
+			b.WriteString("  }\n")
+		}
+	}
+
+	for _, edge := range g.Edges {
+		from, to := resolveEdgeNodeKeys(g, edge)
+		fmt.Fprintf(&b, "  %s -> %s [label=%s, %s];\n", dotQuote(from), dotQuote(to), dotQuote(string(edge.Type)), dotEdgeAttrsByType[edge.Type])
+	}
+
+	b.WriteString("}")
+
+	return b.String()
+}
+
+func renderMermaid(g *imagesGraph) string {
+	var b strings.Builder
+
+	ids := map[string]string{}
+	id := func(key string) string {
+		if v, ok := ids[key]; ok {
+			return v
+		}
+		ids[key] = fmt.Sprintf("n%d", len(ids))
+		return ids[key]
+	}
+
+	b.WriteString("flowchart LR\n")
+
+	for i, image := range g.Images {
+		switch {
+		case image.Type == imageNodeTypeExternal:
+			fmt.Fprintf(&b, "  %s([%s])\n", id(image.Name), mermaidQuote(image.Name))
+		case len(image.Stages) == 0:
+			fmt.Fprintf(&b, "  %s[%s]\n", id(image.Name), mermaidQuote(image.Name))
+		default:
+			fmt.Fprintf(&b, "  subgraph s%d[%s]\n", i, mermaidQuote(image.Name))
+			for _, stage := range image.Stages {
+				fmt.Fprintf(&b, "    %s[%s]\n", id(stageNodeKey(image.Name, stage)), mermaidQuote(stage))
+			}
+			for _, chainEdge := range stapelStagesChain(image) {
+				fmt.Fprintf(&b, "    %s --> %s\n", id(chainEdge[0]), id(chainEdge[1]))
+			}
+			b.WriteString("  end\n")
+		}
+	}
+
+	for _, edge := range g.Edges {
+		from, to := resolveEdgeNodeKeys(g, edge)
+		fmt.Fprintf(&b, "  %s %s|%s| %s\n", id(from), mermaidArrowByType[edge.Type], edge.Type, id(to))
+	}
+
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+// resolveEdgeNodeKeys returns keys of the nodes the edge should be drawn between.
+// If the stage is not specified for the image with stages, the last stage is used for the source image
+// and the first stage is used for the target image.
+func resolveEdgeNodeKeys(g *imagesGraph, edge *imageEdge) (string, string) {
+	from := edge.From
+	if image := findImageNode(g, edge.From); image != nil && len(image.Stages) > 0 {
+		stage := edge.FromStage
+		if stage == "" || !containsString(image.Stages, stage) {
+			stage = image.Stages[len(image.Stages)-1]
+		}
+		from = stageNodeKey(image.Name, stage)
+	}
+
+	to := edge.To
+	if image := findImageNode(g, edge.To); image != nil && len(image.Stages) > 0 {
+		stage := edge.ToStage
+		if stage == "" || !containsString(image.Stages, stage) {
+			stage = image.Stages[0]
+		}
+		to = stageNodeKey(image.Name, stage)
+	}
+
+	return from, to
+}
+
+// stapelStagesChain returns edges between the consecutive stapel stages, because each stapel stage is based on the previous one.
+func stapelStagesChain(image *imageNode) [][2]string {
+	if image.Type != imageNodeTypeStapel {
+		return nil
+	}
+
+	var chain [][2]string
+	for i := 1; i < len(image.Stages); i++ {
+		chain = append(chain, [2]string{stageNodeKey(image.Name, image.Stages[i-1]), stageNodeKey(image.Name, image.Stages[i])})
+	}
+
+	return chain
+}
+
+func findImageNode(g *imagesGraph, name string) *imageNode {
+	for _, image := range g.Images {
+		if image.Name == name {
+			return image
+		}
+	}
+
+	return nil
+}
+
+func stageNodeKey(imageName, stageName string) string {
+	return fmt.Sprintf("%s/%s", imageName, stageName)
+}
+
+func containsString(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+
+	return false
+}
+
+func dotQuote(s string) string {
+	return fmt.Sprintf("%q", s)
+}
+
+func mermaidQuote(s string) string {
+	return fmt.Sprintf(`"%s"`, strings.ReplaceAll(s, `"`, "#quot;"))
+}
@@ -0,0 +1,71 @@
+package graph
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("images graph render", func() {
+	stagesGraph := func() *imagesGraph {
+		return &imagesGraph{
+			Images: []*imageNode{
+				{Name: "base", Type: imageNodeTypeStapel, Stages: []string{"from", "install"}},
+				{Name: "app", Type: imageNodeTypeDockerfile, Stages: []string{"builder", "stage1"}},
+				{Name: "alpine:3.20", Type: imageNodeTypeExternal},
+			},
+			Edges: []*imageEdge{
+				{From: "alpine:3.20", To: "base", ToStage: "from", Type: edgeTypeFromImage},
+				{From: "base", To: "app", ToStage: "builder", Type: edgeTypeDockerfileFrom},
+				{From: "app", FromStage: "builder", To: "app", ToStage: "stage1", Type: edgeTypeImport},
+			},
+		}
+	}
+
+	It("should resolve edge endpoints to the last stage of the source image and the first stage of the target image", func() {
+		from, to := resolveEdgeNodeKeys(stagesGraph(), &imageEdge{From: "base", To: "app", Type: edgeTypeDependency})
+		Expect(from).To(Equal("base/install"))
+		Expect(to).To(Equal("app/builder"))
+	})
+
+	It("should render dot with clusters and styled edges", func() {
+		Expect(renderDot(stagesGraph())).To(Equal(`digraph werf {
+  rankdir=LR;
+  compound=true;
+  node [shape=box];
+  subgraph cluster_0 {
+    label="base";
+    "base/from" [label="from"];
+    "base/install" [label="install"];
+    "base/from" -> "base/install";
+  }
+  subgraph cluster_1 {
+    label="app";
+    "app/builder" [label="builder"];
+    "app/stage1" [label="stage1"];
+  }
+  "alpine:3.20" [style=dashed];
+  "alpine:3.20" -> "base/from" [label="fromImage", style=bold];
+  "base/install" -> "app/builder" [label="dockerfileFrom", style=bold, color=blue];
+  "app/builder" -> "app/stage1" [label="import", style=dashed];
+}`))
+	})
+
+	It("should render mermaid without stages", func() {
+		g := &imagesGraph{
+			Images: []*imageNode{
+				{Name: "app", Type: imageNodeTypeStapel},
+				{Name: "base", Type: imageNodeTypeStapel},
+			},
+			Edges: []*imageEdge{
+				{From: "base", To: "app", Type: edgeTypeFromImage},
+				{From: "base", To: "app", Type: edgeTypeDependency},
+			},
+		}
+
+		Expect(renderMermaid(g)).To(Equal(`flowchart LR
+  n0["app"]
+  n1["base"]
+  n1 ==>|fromImage| n0
+  n1 -.->|dependency| n0`))
+	})
+})
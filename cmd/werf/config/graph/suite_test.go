@@ -0,0 +1,13 @@
+package graph
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestCmdConfigGraph(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Cmd Config Graph Suite")
+}
@@ -6,6 +6,7 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"path/filepath"
 	"strings"
 	"text/template"
 
@@ -65,11 +66,6 @@ func NewExportCmd(ctx context.Context) *cobra.Command {
 				return err
 			}
 
-			if len(tagTemplateList) == 0 {
-				common.PrintHelp(cmd)
-				return fmt.Errorf("required at least one tag template: use the --tag option to specify templates")
-			}
-
 			result, err := common.InputArrayToKeyValueMap(
 				append(util.PredefinedValuesByEnvNamePrefix("WERF_EXPORT_ADD_LABEL_", "WERF_EXPORT_ADD_LABEL_SEPARATOR"), addLabelArray...),
 				addLabelSeparator,
@@ -118,6 +114,7 @@ func NewExportCmd(ctx context.Context) *cobra.Command {
 	common.StubSetupInsecureHelmDependencies(&commonCmdData, cmd)
 	common.SetupSkipTlsVerifyRegistry(&commonCmdData, cmd)
 	common.SetupContainerRegistryMirror(&commonCmdData, cmd)
+	common.SetupRegistryAuthConfig(&commonCmdData, cmd)
 
 	common.SetupLogOptions(&commonCmdData, cmd)
 	common.SetupLogProjectDir(&commonCmdData, cmd)
@@ -132,7 +129,7 @@ func NewExportCmd(ctx context.Context) *cobra.Command {
 	commonCmdData.SetupFinalImagesOnly(cmd, true)
 	commonCmdData.SetupAllowIncludesUpdate(cmd)
 
-	cmd.Flags().StringArrayVarP(&tagTemplateList, "tag", "", []string{}, `Set a tag template (can specify multiple).
+	cmd.Flags().StringArrayVarP(&tagTemplateList, "tag", "", []string{}, `Set a tag template (can specify multiple), not required if the exported images have export targets in werf.yaml.
 It is necessary to use image name shortcut %image% or %image_slug% if multiple images are exported (e.g. REPO:TAG-%image% or REPO-%image%:TAG)`)
 
 	cmd.Flags().StringArrayVarP(&addLabelArray, "add-label", "", []string{}, fmt.Sprintf(`Add label to exported images (can specify multiple).
@@ -203,6 +200,15 @@ func run(ctx context.Context, imageNameListFromArgs, tagTemplateList []string, e
 		return err
 	}
 
+	exportTargets, err := getExportTargets(werfConfig, imagesToProcess.FinalImageNameList, giterminismManager.ProjectDir())
+	if err != nil {
+		return err
+	}
+
+	if len(tagTemplateList) == 0 && len(exportTargets) == 0 {
+		return fmt.Errorf("required at least one tag template: use the --tag option to specify templates or the `export` directive in werf.yaml to specify export targets")
+	}
+
 	projectName := werfConfig.Meta.Project
 
 	projectTmpDir, err := tmp_manager.CreateProjectDir(ctx)
@@ -246,6 +252,7 @@ func run(ctx context.Context, imageNameListFromArgs, tagTemplateList []string, e
 			return c.ExportFromReport(ctx, build.ExportOptions{
 				ExportImageNameList: imagesToProcess.FinalImageNameList,
 				ExportTagFuncList:   tagFuncList,
+				ExportTargets:       exportTargets,
 				MutateConfigFunc: func(config v1.Config) (v1.Config, error) {
 					for k, v := range extraLabels {
 						config.Labels[k] = v
@@ -267,6 +274,7 @@ func run(ctx context.Context, imageNameListFromArgs, tagTemplateList []string, e
 			return c.Export(ctx, build.ExportOptions{
 				ExportImageNameList: imagesToProcess.FinalImageNameList,
 				ExportTagFuncList:   tagFuncList,
+				ExportTargets:       exportTargets,
 				MutateConfigFunc: func(config v1.Config) (v1.Config, error) {
 					for k, v := range extraLabels {
 						config.Labels[k] = v
@@ -278,15 +286,18 @@ func run(ctx context.Context, imageNameListFromArgs, tagTemplateList []string, e
 	})
 }
 
-func getTagFuncList(imageNameList, tagTemplateList []string) ([]image.ExportTagFunc, error) {
-	templateName := "--tag"
-	tmpl := template.New(templateName).Delims("%", "%")
-	tmpl = tmpl.Funcs(map[string]interface{}{
+func newTagTemplate(templateName string) *template.Template {
+	return template.New(templateName).Delims("%", "%").Funcs(map[string]interface{}{
 		"image":                   func() string { return "%[1]s" },
 		"image_slug":              func() string { return "%[2]s" },
 		"image_safe_slug":         func() string { return "%[3]s" },
 		"image_content_based_tag": func() string { return "%[4]s" },
 	})
+}
+
+func getTagFuncList(imageNameList, tagTemplateList []string) ([]image.ExportTagFunc, error) {
+	templateName := "--tag"
+	tmpl := newTagTemplate(templateName)
 
 	var tagFuncList []image.ExportTagFunc
 	for _, tagTemplate := range tagTemplateList {
@@ -348,3 +359,81 @@ func getExportTagFunc(tmpl *template.Template, templateName string, imageNameLis
 
 	return tagFunc, nil
 }
+
+// getExportTargets returns the werf.yaml export targets of the images: the tags of the registry targets are the tag
+// templates of the image and the OCI archive paths are resolved relative to the project directory.
+func getExportTargets(werfConfig *config.WerfConfig, imageNameList []string, projectDir string) (map[string][]build.ExportTarget, error) {
+	res := map[string][]build.ExportTarget{}
+	imageNameByOCIArchivePath := map[string]string{}
+
+	for _, imageName := range imageNameList {
+		for _, target := range werfConfig.GetExportTargets(imageName) {
+			if target.OCIArchive != "" {
+				archivePath, err := getExportOCIArchivePath(imageName, target.OCIArchive, projectDir)
+				if err != nil {
+					return nil, fmt.Errorf("invalid OCI archive path %q of image %q: %w", target.OCIArchive, imageName, err)
+				}
+
+				if prevImageName, ok := imageNameByOCIArchivePath[archivePath]; ok {
+					if prevImageName == imageName {
+						return nil, fmt.Errorf("OCI archive path %q is used for image %q more than once", target.OCIArchive, imageName)
+					}
+					return nil, fmt.Errorf("OCI archive path %q is used for images %q and %q: OCI archive path must contain image name shortcut %%image%% or %%image_slug%% if multiple images are exported", target.OCIArchive, prevImageName, imageName)
+				}
+				imageNameByOCIArchivePath[archivePath] = imageName
+
+				res[imageName] = append(res[imageName], build.ExportTarget{
+					OCIArchivePath: archivePath,
+					Platforms:      target.Platforms,
+				})
+				continue
+			}
+
+			var tagFuncList []image.ExportTagFunc
+			for _, tag := range target.Tags {
+				tagTemplate := fmt.Sprintf("%s:%s", target.Repo, tag)
+
+				templateName := "export"
+				tagFunc, err := getExportTagFunc(newTagTemplate(templateName), templateName, []string{imageName}, tagTemplate)
+				if err != nil {
+					return nil, fmt.Errorf("invalid export target tag template %q of image %q: %w", tagTemplate, imageName, err)
+				}
+
+				tagFuncList = append(tagFuncList, tagFunc)
+			}
+
+			res[imageName] = append(res[imageName], build.ExportTarget{
+				TagFuncList: tagFuncList,
+				Platforms:   target.Platforms,
+			})
+		}
+	}
+
+	return res, nil
+}
+
+// getExportOCIArchivePath expands the image name shortcuts of the OCI archive path, %image_content_based_tag% is not
+// supported since the path is known before the images are built.
+func getExportOCIArchivePath(imageName, pathTemplate, projectDir string) (string, error) {
+	templateName := "ociArchive"
+	tmpl, err := template.New(templateName).Delims("%", "%").Funcs(map[string]interface{}{
+		"image":           func() string { return imageName },
+		"image_slug":      func() string { return slug.Slug(imageName) },
+		"image_safe_slug": func() string { return slug.DockerTag(imageName) },
+	}).Parse(pathTemplate)
+	if err != nil {
+		return "", err
+	}
+
+	buf := bytes.NewBuffer(nil)
+	if err := tmpl.ExecuteTemplate(buf, templateName, nil); err != nil {
+		return "", err
+	}
+
+	archivePath := filepath.Join(projectDir, filepath.FromSlash(buf.String()))
+	if !util.IsSubpathOfBasePath(projectDir, archivePath) {
+		return "", fmt.Errorf("path %q is outside of the project directory", buf.String())
+	}
+
+	return archivePath, nil
+}
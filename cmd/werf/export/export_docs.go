@@ -9,6 +9,7 @@ func GetExportDocs() structs.DocsStruct {
 The tag may contain the following shortcuts:
 - %image%, %image_slug% or %image_safe_slug% to use the image name (necessary if there is more than one image in the werf config);
 - %image_content_based_tag% to use a content-based tag.
+The images are also exported to the targets specified by the export directive in werf.yaml: the registries and the OCI archives. The --tag option is not required if the exported images have such targets.
 All meta-information related to werf is removed from the exported images, and then images are completely under the user's responsibility.`
 
 	docs.LongMD = "Export images to an arbitrary repository according to a template specified " +
@@ -16,7 +17,10 @@ All meta-information related to werf is removed from the exported images, and th
 		"The tag may contain the following shortcuts:\n" +
 		"- `image`, `image_slug` or `image_safe_slug` to use the image name (necessary " +
 		"if there is more than one image in the werf config);\n" +
-		"- `image_content_based_tag` to use a content-based tag.\n" +
+		"- `image_content_based_tag` to use a content-based tag.\n\n" +
+		"The images are also exported to the targets specified by the `export` directive in werf.yaml: " +
+		"the registries and the OCI archives. The `--tag` option is not required if the exported images " +
+		"have such targets.\n\n" +
 		"All meta-information related to werf is removed from the exported images, and then images " +
 		"are completely under the user's responsibility."
 
@@ -2,6 +2,7 @@ package cleanup
 
 import (
 	"context"
+	"errors"
 	"fmt"
 
 	"github.com/spf13/cobra"
@@ -59,6 +60,8 @@ func NewCmd(ctx context.Context) *cobra.Command {
 	common.SetupLogOptions(&commonCmdData, cmd)
 
 	common.SetupDryRun(&commonCmdData, cmd)
+	common.SetupSaveCleanupReport(&commonCmdData, cmd)
+	common.SetupCleanupReportPath(&commonCmdData, cmd)
 
 	common.SetupDisableAutoHostCleanup(&commonCmdData, cmd)
 	common.SetupAllowedBackendStorageVolumeUsage(&commonCmdData, cmd)
@@ -70,6 +73,7 @@ func NewCmd(ctx context.Context) *cobra.Command {
 	common.SetupInsecureRegistry(&commonCmdData, cmd)
 	common.SetupSkipTlsVerifyRegistry(&commonCmdData, cmd)
 	common.SetupContainerRegistryMirror(&commonCmdData, cmd)
+	common.SetupRegistryAuthConfig(&commonCmdData, cmd)
 
 	cmd.Flags().BoolVarP(&cmdData.Force, "force", "", util.GetBoolEnvironmentDefaultFalse("WERF_FORCE"), "Force deletion of images which are being used by some containers (default $WERF_FORCE)")
 
@@ -104,6 +108,11 @@ func runCleanup(ctx context.Context) error {
 
 	logboek.LogOptionalLn()
 
+	report, reportPath, err := common.NewHostCleanupReport(ctx, &commonCmdData, "host cleanup", *commonCmdData.DryRun)
+	if err != nil {
+		return err
+	}
+
 	hostCleanupOptions := host_cleaning.HostCleanupOptions{
 		DryRun:                                 *commonCmdData.DryRun,
 		Force:                                  cmdData.Force,
@@ -112,7 +121,10 @@ func runCleanup(ctx context.Context) error {
 		AllowedLocalCacheVolumeUsage:           commonCmdData.AllowedLocalCacheVolumeUsage,
 		AllowedLocalCacheVolumeUsageMargin:     commonCmdData.AllowedLocalCacheVolumeUsageMargin,
 		BackendStoragePath:                     commonCmdData.BackendStoragePath,
+		Report:                                 report,
 	}
 
-	return host_cleaning.RunHostCleanup(ctx, commonManager.ContainerBackend(), hostCleanupOptions)
+	runErr := host_cleaning.RunHostCleanup(ctx, commonManager.ContainerBackend(), hostCleanupOptions)
+
+	return errors.Join(runErr, report.Save(ctx, reportPath))
 }
@@ -6,12 +6,18 @@ import (
 
 	"github.com/spf13/cobra"
 
+	"github.com/werf/common-go/pkg/util"
 	"github.com/werf/logboek"
 	"github.com/werf/werf/v2/cmd/werf/common"
+	"github.com/werf/werf/v2/pkg/includes"
 	"github.com/werf/werf/v2/pkg/tmp_manager"
 	"github.com/werf/werf/v2/pkg/true_git"
 )
 
+var cmdData struct {
+	Check bool
+}
+
 var commonCmdData common.CmdData
 
 func NewCmd(ctx context.Context) *cobra.Command {
@@ -20,6 +26,11 @@ func NewCmd(ctx context.Context) *cobra.Command {
 		Use:   "update",
 		Short: "Create or update includes lock file (default: werf-includes.lock).",
 		Long:  "Create or update includes lock file by resolving git references in the includes config to their latest commits (default: werf-includes.lock).",
+		Example: `  # Update includes lock file
+  $ werf includes update
+
+  # Check that includes lock file is up to date without changing it
+  $ werf includes update --check`,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			ctx := cmd.Context()
 
@@ -41,11 +52,21 @@ func NewCmd(ctx context.Context) *cobra.Command {
 				}
 			}()
 
-			_, err = common.GetGiterminismManager(ctx, &commonCmdData)
+			if cmdData.Check {
+				// The lock file is compared with the latest versions by checkLock, so includes are not initialized from the lock file.
+				commonCmdData.CreateIncludesLockFile = false
+				commonCmdData.SkipIncludesInit = true
+			}
+
+			giterminismManager, err := common.GetGiterminismManager(ctx, &commonCmdData)
 			if err != nil {
 				return err
 			}
 
+			if cmdData.Check {
+				return checkLock(ctx, giterminismManager.FileReader())
+			}
+
 			logboek.Context(ctx).Default().LogOptionalLn()
 
 			logboek.Context(ctx).Default().LogLn("Includes updated successfully")
@@ -75,5 +96,61 @@ func NewCmd(ctx context.Context) *cobra.Command {
 
 	commonCmdData.SetupCreateIncludesLockFile()
 
+	cmd.Flags().BoolVarP(&cmdData.Check, "check", "", util.GetBoolEnvironmentDefaultFalse("WERF_INCLUDES_CHECK"), "Do not update the lock file, but exit with non-zero code and print the difference if any include has newer version than in the lock file (default $WERF_INCLUDES_CHECK or false)")
+
 	return cmd
 }
+
+func checkLock(ctx context.Context, fileReader includes.GiterminismManagerFileReader) error {
+	drifts, err := includes.CheckLock(ctx, includes.CheckLockOptions{FileReader: fileReader})
+	if err != nil {
+		return fmt.Errorf("unable to check includes lock file: %w", err)
+	}
+
+	logboek.Context(ctx).Default().LogOptionalLn()
+
+	if len(drifts) == 0 {
+		logboek.Context(ctx).Default().LogLn("Includes lock file is up to date")
+		return nil
+	}
+
+	for _, drift := range drifts {
+		printLockDrift(drift)
+	}
+
+	return fmt.Errorf("includes lock file %s is outdated: %d include(s) changed, run `werf includes update` to update it", includes.GetWerfIncludesLockConfigRelPath(), len(drifts))
+}
+
+func printLockDrift(drift *includes.LockDrift) {
+	pinName := "commit"
+	if drift.Ref == "" {
+		pinName = "digest"
+	}
+
+	header := drift.Source
+	if drift.Ref != "" {
+		header = fmt.Sprintf("%s (%s)", drift.Source, drift.Ref)
+	}
+	fmt.Println(header)
+
+	if drift.Locked == "" {
+		fmt.Println("  not found in the lock file")
+	} else {
+		fmt.Printf("- %s: %s\n", pinName, drift.Locked)
+	}
+	fmt.Printf("+ %s: %s\n", pinName, drift.Latest)
+
+	switch {
+	case drift.ChangedFilesUnknown:
+		fmt.Println("  changed files: unknown")
+	case len(drift.ChangedFiles) > 0:
+		fmt.Printf("  changed files (%d):\n", len(drift.ChangedFiles))
+		for _, path := range drift.ChangedFiles {
+			fmt.Printf("    %s\n", path)
+		}
+	case drift.Locked != "" && drift.Ref != "":
+		fmt.Println("  no changes in the included files")
+	}
+
+	fmt.Println()
+}
@@ -0,0 +1,142 @@
+package kube_run
+
+import (
+	"archive/tar"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// writeTarArchive writes the local file or directory src into the tar stream as name.
+func writeTarArchive(w io.Writer, src, name string) error {
+	tw := tar.NewWriter(w)
+
+	if err := filepath.WalkDir(src, func(filePath string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		relPath, err := filepath.Rel(src, filePath)
+		if err != nil {
+			return fmt.Errorf("unable to get relative path for %q: %w", filePath, err)
+		}
+
+		info, err := d.Info()
+		if err != nil {
+			return fmt.Errorf("unable to stat %q: %w", filePath, err)
+		}
+
+		var linkTarget string
+		if info.Mode()&fs.ModeSymlink != 0 {
+			if linkTarget, err = os.Readlink(filePath); err != nil {
+				return fmt.Errorf("unable to read link %q: %w", filePath, err)
+			}
+		}
+
+		header, err := tar.FileInfoHeader(info, linkTarget)
+		if err != nil {
+			return fmt.Errorf("unable to create tar header for %q: %w", filePath, err)
+		}
+		header.Name = path.Join(name, filepath.ToSlash(relPath))
+		if info.IsDir() {
+			header.Name += "/"
+		}
+
+		if err := tw.WriteHeader(header); err != nil {
+			return fmt.Errorf("unable to write tar header for %q: %w", filePath, err)
+		}
+
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+
+		f, err := os.Open(filePath)
+		if err != nil {
+			return fmt.Errorf("unable to open %q: %w", filePath, err)
+		}
+		defer f.Close()
+
+		if _, err := io.Copy(tw, f); err != nil {
+			return fmt.Errorf("unable to write %q into archive: %w", filePath, err)
+		}
+
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	return tw.Close()
+}
+
+// extractTarArchive extracts the entry name (file or directory) from the tar stream to the local path dst.
+// Symlinks are skipped as well as any entries pointing outside of dst.
+func extractTarArchive(r io.Reader, name, dst string) error {
+	tr := tar.NewReader(r)
+
+	for {
+		header, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			return nil
+		} else if err != nil {
+			return fmt.Errorf("unable to read tar header: %w", err)
+		}
+
+		relPath, ok := archiveEntryRelPath(header.Name, name)
+		if !ok {
+			continue
+		}
+
+		target := filepath.Join(dst, filepath.FromSlash(relPath))
+		if target != filepath.Clean(dst) && !strings.HasPrefix(target, filepath.Clean(dst)+string(filepath.Separator)) {
+			return fmt.Errorf("illegal path %q in archive", header.Name)
+		}
+
+		switch header.Typeflag {
+		case tar.TypeDir:
+			if err := os.MkdirAll(target, os.FileMode(header.Mode)|0o700); err != nil {
+				return fmt.Errorf("unable to create directory %q: %w", target, err)
+			}
+		case tar.TypeReg:
+			if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+				return fmt.Errorf("unable to create directory %q: %w", filepath.Dir(target), err)
+			}
+
+			if err := extractTarFile(tr, target, os.FileMode(header.Mode)); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func extractTarFile(r io.Reader, target string, mode os.FileMode) error {
+	f, err := os.OpenFile(target, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, mode)
+	if err != nil {
+		return fmt.Errorf("unable to create file %q: %w", target, err)
+	}
+	defer f.Close()
+
+	if _, err := io.Copy(f, r); err != nil {
+		return fmt.Errorf("unable to write file %q: %w", target, err)
+	}
+
+	return nil
+}
+
+// archiveEntryRelPath returns the path of the entry relative to the archived file or directory name.
+func archiveEntryRelPath(entryName, name string) (string, bool) {
+	entryName = path.Clean(strings.TrimPrefix(entryName, "./"))
+
+	switch {
+	case entryName == name:
+		return "", true
+	case strings.HasPrefix(entryName, name+"/"):
+		return strings.TrimPrefix(entryName, name+"/"), true
+	default:
+		return "", false
+	}
+}
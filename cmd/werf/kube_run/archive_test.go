@@ -0,0 +1,81 @@
+package kube_run
+
+import (
+	"archive/tar"
+	"bytes"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("tar archive", func() {
+	It("should copy a directory under the new name", func() {
+		srcDir := filepath.Join(GinkgoT().TempDir(), "src")
+		Expect(os.MkdirAll(filepath.Join(srcDir, "sub"), 0o755)).To(Succeed())
+		Expect(os.WriteFile(filepath.Join(srcDir, "a.txt"), []byte("a"), 0o644)).To(Succeed())
+		Expect(os.WriteFile(filepath.Join(srcDir, "sub", "b.sh"), []byte("b"), 0o755)).To(Succeed())
+
+		var archive bytes.Buffer
+		Expect(writeTarArchive(&archive, srcDir, "app")).To(Succeed())
+
+		dstDir := filepath.Join(GinkgoT().TempDir(), "dst")
+		Expect(extractTarArchive(&archive, "app", dstDir)).To(Succeed())
+
+		Expect(os.ReadFile(filepath.Join(dstDir, "a.txt"))).To(Equal([]byte("a")))
+		Expect(os.ReadFile(filepath.Join(dstDir, "sub", "b.sh"))).To(Equal([]byte("b")))
+
+		info, err := os.Stat(filepath.Join(dstDir, "sub", "b.sh"))
+		Expect(err).To(Succeed())
+		Expect(info.Mode().Perm()).To(Equal(os.FileMode(0o755)))
+	})
+
+	It("should copy a single file", func() {
+		srcFile := filepath.Join(GinkgoT().TempDir(), "config.yaml")
+		Expect(os.WriteFile(srcFile, []byte("key: value"), 0o644)).To(Succeed())
+
+		var archive bytes.Buffer
+		Expect(writeTarArchive(&archive, srcFile, "settings.yaml")).To(Succeed())
+
+		dstFile := filepath.Join(GinkgoT().TempDir(), "out.yaml")
+		Expect(extractTarArchive(&archive, "settings.yaml", dstFile)).To(Succeed())
+
+		Expect(os.ReadFile(dstFile)).To(Equal([]byte("key: value")))
+	})
+
+	It("should skip entries outside of the requested name and symlinks", func() {
+		var archive bytes.Buffer
+		tw := tar.NewWriter(&archive)
+		Expect(tw.WriteHeader(&tar.Header{Name: "other/file", Typeflag: tar.TypeReg, Mode: 0o644, Size: 1})).To(Succeed())
+		_, err := tw.Write([]byte("x"))
+		Expect(err).To(Succeed())
+		Expect(tw.WriteHeader(&tar.Header{Name: "app/../../escape", Typeflag: tar.TypeReg, Mode: 0o644, Size: 1})).To(Succeed())
+		_, err = tw.Write([]byte("x"))
+		Expect(err).To(Succeed())
+		Expect(tw.WriteHeader(&tar.Header{Name: "app/link", Typeflag: tar.TypeSymlink, Linkname: "/etc/passwd"})).To(Succeed())
+		Expect(tw.Close()).To(Succeed())
+
+		tmpDir := GinkgoT().TempDir()
+		dstDir := filepath.Join(tmpDir, "dst")
+		Expect(extractTarArchive(&archive, "app", dstDir)).To(Succeed())
+
+		Expect(filepath.Join(dstDir, "link")).NotTo(BeAnExistingFile())
+		Expect(filepath.Join(tmpDir, "escape")).NotTo(BeAnExistingFile())
+		Expect(filepath.Join(dstDir, "file")).NotTo(BeAnExistingFile())
+	})
+})
+
+var _ = DescribeTable("archiveEntryRelPath",
+	func(entryName, name, expectedRelPath string, expectedOk bool) {
+		relPath, ok := archiveEntryRelPath(entryName, name)
+		Expect(ok).To(Equal(expectedOk))
+		Expect(relPath).To(Equal(expectedRelPath))
+	},
+	Entry("the entry itself", "app", "app", "", true),
+	Entry("the entry itself with trailing slash", "app/", "app", "", true),
+	Entry("nested entry", "app/dir/file", "app", "dir/file", true),
+	Entry("entry with ./ prefix", "./app/file", "app", "file", true),
+	Entry("entry with the same prefix", "application/file", "app", "", false),
+	Entry("escaping entry", "app/../file", "app", "", false),
+)
@@ -0,0 +1,245 @@
+package kube_run
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"path"
+	"strconv"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/portforward"
+	"k8s.io/client-go/tools/remotecommand"
+	"k8s.io/client-go/transport/spdy"
+	utilexec "k8s.io/client-go/util/exec"
+	"k8s.io/kubectl/pkg/scheme"
+
+	"github.com/werf/kubedog/pkg/kube"
+	"github.com/werf/werf/v2/cmd/werf/common"
+	werfExec "github.com/werf/werf/v2/pkg/werf/exec"
+)
+
+// kubeRunClient talks to the Kubernetes API directly, so that kube-run does not need kubectl on the runner.
+type kubeRunClient struct {
+	config    *rest.Config
+	clientset kubernetes.Interface
+}
+
+func newKubeRunClient() (*kubeRunClient, error) {
+	kubeConfig, err := kube.GetKubeConfig(common.GetOndemandKubeInitializer().KubeConfigOptions())
+	if err != nil {
+		return nil, fmt.Errorf("unable to load kube config: %w", err)
+	}
+
+	clientset, err := kubernetes.NewForConfig(kubeConfig.Config)
+	if err != nil {
+		return nil, fmt.Errorf("unable to create kubernetes client: %w", err)
+	}
+
+	return &kubeRunClient{
+		config:    kubeConfig.Config,
+		clientset: clientset,
+	}, nil
+}
+
+type execOptions struct {
+	Command []string
+
+	Stdin  io.Reader
+	Stdout io.Writer
+	Stderr io.Writer
+
+	TTY               bool
+	TerminalSizeQueue remotecommand.TerminalSizeQueue
+}
+
+// exec runs the command in the container. A non-zero exit code of the command is returned as werfExec.ExitCodeError.
+func (c *kubeRunClient) exec(ctx context.Context, namespace, pod, container string, opts execOptions) error {
+	req := c.clientset.CoreV1().RESTClient().Post().
+		Resource("pods").
+		Namespace(namespace).
+		Name(pod).
+		SubResource("exec").
+		VersionedParams(&corev1.PodExecOptions{
+			Container: container,
+			Command:   opts.Command,
+			Stdin:     opts.Stdin != nil,
+			Stdout:    opts.Stdout != nil,
+			Stderr:    opts.Stderr != nil && !opts.TTY,
+			TTY:       opts.TTY,
+		}, scheme.ParameterCodec)
+
+	executor, err := remotecommand.NewSPDYExecutor(c.config, http.MethodPost, req.URL())
+	if err != nil {
+		return fmt.Errorf("unable to create executor: %w", err)
+	}
+
+	streamOpts := remotecommand.StreamOptions{
+		Stdin:             opts.Stdin,
+		Stdout:            opts.Stdout,
+		Tty:               opts.TTY,
+		TerminalSizeQueue: opts.TerminalSizeQueue,
+	}
+	if !opts.TTY {
+		streamOpts.Stderr = opts.Stderr
+	}
+
+	if err := executor.StreamWithContext(ctx, streamOpts); err != nil {
+		var exitErr utilexec.ExitError
+		if errors.As(err, &exitErr) && exitErr.Exited() {
+			return werfExec.NewExitCodeError(exitErr.ExitStatus(), err)
+		}
+
+		return err
+	}
+
+	return nil
+}
+
+// containerExecFunc runs the command in the particular container.
+type containerExecFunc func(ctx context.Context, opts execOptions) error
+
+func (c *kubeRunClient) containerExec(namespace, pod, container string) containerExecFunc {
+	return func(ctx context.Context, opts execOptions) error {
+		return c.exec(ctx, namespace, pod, container, opts)
+	}
+}
+
+// execQuiet runs the command in the container and returns its stderr as part of the error.
+func (c *kubeRunClient) execQuiet(ctx context.Context, namespace, pod, container string, command []string, stdin io.Reader) error {
+	return runQuiet(ctx, c.containerExec(namespace, pod, container), command, stdin, io.Discard)
+}
+
+// runQuiet runs the command writing its stdout to the stdout and returns its stderr as part of the error.
+func runQuiet(ctx context.Context, exec containerExecFunc, command []string, stdin io.Reader, stdout io.Writer) error {
+	var stderr bytes.Buffer
+	if err := exec(ctx, execOptions{
+		Command: command,
+		Stdin:   stdin,
+		Stdout:  stdout,
+		Stderr:  &stderr,
+	}); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return fmt.Errorf("%w\n%s", err, msg)
+		}
+
+		return err
+	}
+
+	return nil
+}
+
+// copyToPod copies the local file or directory src to the path dst in the container.
+func (c *kubeRunClient) copyToPod(ctx context.Context, namespace, pod, container, src, dst string) error {
+	reader, writer := io.Pipe()
+	go func() {
+		writer.CloseWithError(writeTarArchive(writer, src, path.Base(dst)))
+	}()
+	defer reader.Close()
+
+	return c.execQuiet(ctx, namespace, pod, container, []string{"tar", "-xmf", "-", "-C", path.Dir(dst)}, reader)
+}
+
+// copyFromPod copies the file or directory src from the container to the local path dst.
+func (c *kubeRunClient) copyFromPod(ctx context.Context, namespace, pod, container, src, dst string) error {
+	return copyFromContainer(ctx, c.containerExec(namespace, pod, container), src, dst)
+}
+
+func copyFromContainer(ctx context.Context, exec containerExecFunc, src, dst string) error {
+	reader, writer := io.Pipe()
+
+	execErrCh := make(chan error, 1)
+	go func() {
+		err := runQuiet(ctx, exec, []string{"tar", "-cf", "-", "-C", path.Dir(src), path.Base(src)}, nil, writer)
+		writer.CloseWithError(err)
+		execErrCh <- err
+	}()
+
+	extractErr := extractTarArchive(reader, path.Base(src), dst)
+	if extractErr == nil {
+		// Read the padding tar writes after the end of the archive, so tar does not fail on the closed pipe.
+		_, extractErr = io.Copy(io.Discard, reader)
+	}
+	// Unblock the exec stdout if the extraction stopped before the end of the archive.
+	reader.Close()
+
+	if err := <-execErrCh; err != nil {
+		return fmt.Errorf("unable to archive %q in container: %w", src, err)
+	}
+
+	if extractErr != nil {
+		return fmt.Errorf("unable to extract archive: %w", extractErr)
+	}
+
+	return nil
+}
+
+// startPortForward forwards local ports to the pod. The returned function stops forwarding.
+func (c *kubeRunClient) startPortForward(ctx context.Context, namespace, pod string, ports []string, out io.Writer) (func(), error) {
+	transport, upgrader, err := spdy.RoundTripperFor(c.config)
+	if err != nil {
+		return nil, fmt.Errorf("unable to create round tripper: %w", err)
+	}
+
+	req := c.clientset.CoreV1().RESTClient().Post().
+		Resource("pods").
+		Namespace(namespace).
+		Name(pod).
+		SubResource("portforward")
+
+	dialer := spdy.NewDialer(upgrader, &http.Client{Transport: transport}, http.MethodPost, req.URL())
+
+	stopCh := make(chan struct{})
+	readyCh := make(chan struct{})
+
+	forwarder, err := portforward.NewOnAddresses(dialer, []string{"localhost"}, ports, stopCh, readyCh, out, out)
+	if err != nil {
+		return nil, fmt.Errorf("unable to create port forwarder: %w", err)
+	}
+
+	forwardErrCh := make(chan error, 1)
+	go func() {
+		forwardErrCh <- forwarder.ForwardPorts()
+	}()
+
+	select {
+	case <-readyCh:
+	case err := <-forwardErrCh:
+		return nil, fmt.Errorf("unable to forward ports: %w", err)
+	case <-ctx.Done():
+		close(stopCh)
+		return nil, ctx.Err()
+	}
+
+	return func() {
+		close(stopCh)
+		<-forwardErrCh
+	}, nil
+}
+
+// validatePortForward validates the port forwarding spec in the format [LOCAL_PORT:]REMOTE_PORT.
+func validatePortForward(spec string) error {
+	parts := strings.Split(spec, ":")
+	if len(parts) > 2 {
+		return fmt.Errorf("wrong format %q: expected [LOCAL_PORT:]REMOTE_PORT", spec)
+	}
+
+	remotePort := parts[len(parts)-1]
+	if port, err := strconv.ParseUint(remotePort, 10, 16); err != nil || port == 0 {
+		return fmt.Errorf("invalid remote port %q in %q", remotePort, spec)
+	}
+
+	if len(parts) == 2 && parts[0] != "" {
+		if _, err := strconv.ParseUint(parts[0], 10, 16); err != nil {
+			return fmt.Errorf("invalid local port %q in %q", parts[0], spec)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,69 @@
+package kube_run
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = DescribeTable("validatePortForward",
+	func(spec string, expectErr bool) {
+		err := validatePortForward(spec)
+		if expectErr {
+			Expect(err).To(HaveOccurred())
+		} else {
+			Expect(err).To(Succeed())
+		}
+	},
+	Entry("local and remote ports", "8080:80", false),
+	Entry("remote port only", "80", false),
+	Entry("random local port", ":80", false),
+	Entry("zero local port", "0:80", false),
+	Entry("zero remote port", "8080:0", true),
+	Entry("empty remote port", "8080:", true),
+	Entry("not a number", "http", true),
+	Entry("port out of range", "8080:70000", true),
+	Entry("too many parts", "127.0.0.1:8080:80", true),
+)
+
+var _ = Describe("copyFromContainer", func() {
+	// localExec runs the command on the host as if it is run in the container.
+	localExec := func(ctx context.Context, opts execOptions) error {
+		cmd := exec.CommandContext(ctx, opts.Command[0], opts.Command[1:]...)
+		cmd.Stdin = opts.Stdin
+		cmd.Stdout = opts.Stdout
+		cmd.Stderr = opts.Stderr
+		return cmd.Run()
+	}
+
+	It("should copy the archive produced by tar in the container", func(ctx SpecContext) {
+		srcDir := filepath.Join(GinkgoT().TempDir(), "results")
+		Expect(os.MkdirAll(filepath.Join(srcDir, "sub"), 0o755)).To(Succeed())
+		Expect(os.WriteFile(filepath.Join(srcDir, "report.xml"), []byte("<report/>"), 0o644)).To(Succeed())
+		Expect(os.WriteFile(filepath.Join(srcDir, "sub", "coverage.out"), []byte("mode: set"), 0o644)).To(Succeed())
+
+		dstDir := filepath.Join(GinkgoT().TempDir(), "out")
+		Expect(copyFromContainer(ctx, localExec, filepath.ToSlash(srcDir), dstDir)).To(Succeed())
+
+		Expect(os.ReadFile(filepath.Join(dstDir, "report.xml"))).To(Equal([]byte("<report/>")))
+		Expect(os.ReadFile(filepath.Join(dstDir, "sub", "coverage.out"))).To(Equal([]byte("mode: set")))
+	})
+
+	It("should return the error with stderr if tar fails", func(ctx SpecContext) {
+		err := copyFromContainer(ctx, localExec, filepath.ToSlash(filepath.Join(GinkgoT().TempDir(), "missing")), GinkgoT().TempDir())
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("missing"))
+	})
+
+	It("should return the exec error", func(ctx SpecContext) {
+		err := copyFromContainer(ctx, func(context.Context, execOptions) error {
+			return errors.New("exec failed")
+		}, "/results", GinkgoT().TempDir())
+		Expect(err).To(MatchError(ContainSubstring("exec failed")))
+	})
+})
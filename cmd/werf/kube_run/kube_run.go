@@ -6,32 +6,39 @@ import (
 	"encoding/base64"
 	"encoding/json"
 	"fmt"
+	"io"
 	"math/rand"
 	"os"
 	"path"
 	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/containers/image/v5/docker/reference"
 	config2 "github.com/containers/image/v5/pkg/docker/config"
 	imgtypes "github.com/containers/image/v5/types"
 	"github.com/samber/lo"
 	"github.com/spf13/cobra"
+	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
 	errorsK8s "k8s.io/apimachinery/pkg/api/errors"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/strategicpatch"
 	"k8s.io/apimachinery/pkg/util/validation"
 	"k8s.io/kubectl/pkg/scheme"
+	"k8s.io/kubectl/pkg/util/term"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/yaml"
 
 	"github.com/werf/common-go/pkg/util"
-	"github.com/werf/kubedog/pkg/kube"
 	"github.com/werf/logboek"
 	"github.com/werf/werf/v2/cmd/werf/common"
 	"github.com/werf/werf/v2/pkg/build"
 	"github.com/werf/werf/v2/pkg/config"
 	"github.com/werf/werf/v2/pkg/config/deploy_params"
 	"github.com/werf/werf/v2/pkg/container_backend"
+	"github.com/werf/werf/v2/pkg/follow"
 	"github.com/werf/werf/v2/pkg/giterminism_manager"
 	"github.com/werf/werf/v2/pkg/ssh_agent"
 	"github.com/werf/werf/v2/pkg/tmp_manager"
@@ -47,6 +54,7 @@ type cmdDataType struct {
 	Rm              bool
 	RmWithNamespace bool
 	AutoPullSecret  bool
+	AsJob           bool
 
 	Pod             string
 	Command         []string
@@ -55,10 +63,18 @@ type cmdDataType struct {
 	RunExtraOptions string
 	CopyFrom        []string
 	CopyTo          []string
+	PortForward     []string
+
+	JobBackoffLimit            int
+	JobTTLSecondsAfterFinished int
 
 	registryCredsFound bool
+	runningPod         string
 }
 
+// quitFilePath is created in the main container to stop it. It contains the exit code for the container.
+const quitFilePath = "/tmp/werf-kube-run-quit"
+
 type copyFromTo struct {
 	Src string
 	Dst string
@@ -141,6 +157,16 @@ func NewCmd(ctx context.Context) *cobra.Command {
 				return fmt.Errorf("error validating --copy-to: %w", err)
 			}
 
+			for _, portForward := range getPortForward() {
+				if err := validatePortForward(portForward); err != nil {
+					return fmt.Errorf("error validating --port-forward: %w", err)
+				}
+			}
+
+			if cmdData.JobBackoffLimit < 0 {
+				return fmt.Errorf("--job-backoff-limit should not be negative")
+			}
+
 			return runMain(ctx)
 		},
 	})
@@ -179,6 +205,7 @@ func NewCmd(ctx context.Context) *cobra.Command {
 	common.SetupInsecureRegistry(&commonCmdData, cmd)
 	common.SetupSkipTlsVerifyRegistry(&commonCmdData, cmd)
 	common.SetupContainerRegistryMirror(&commonCmdData, cmd)
+	common.SetupRegistryAuthConfig(&commonCmdData, cmd)
 
 	common.SetupLogOptions(&commonCmdData, cmd)
 	common.SetupLogProjectDir(&commonCmdData, cmd)
@@ -195,6 +222,7 @@ func NewCmd(ctx context.Context) *cobra.Command {
 	cmd.Flags().StringVarP(&cmdData.Pod, "pod", "", os.Getenv("WERF_POD"), "Set created pod name (default $WERF_POD or autogenerated if not specified)")
 	cmd.Flags().StringVarP(&cmdData.Overrides, "overrides", "", os.Getenv("WERF_OVERRIDES"), "Inline JSON to override/extend any fields in created Pod, e.g. to add imagePullSecrets field (default $WERF_OVERRIDES). %pod_name%, %container_name%, and %container_image% will be replaced with the names of the created pod, container, and container image, respectively.")
 	cmd.Flags().StringVarP(&cmdData.RunExtraOptions, "extra-options", "", os.Getenv("WERF_EXTRA_OPTIONS"), "Pass extra options to \"kubectl run\" command, which will create a Pod (default $WERF_EXTRA_OPTIONS)")
+	lo.Must0(cmd.Flags().MarkDeprecated("extra-options", "kubectl is not used anymore, use --overrides instead"))
 	cmd.Flags().BoolVarP(&cmdData.Rm, "rm", "", util.GetBoolEnvironmentDefaultTrue("WERF_RM"), "Remove pod and other created resources after command completion (default $WERF_RM or true if not specified)")
 	cmd.Flags().BoolVarP(&cmdData.RmWithNamespace, "rm-with-namespace", "", util.GetBoolEnvironmentDefaultFalse("WERF_RM_WITH_NAMESPACE"), "Remove also a namespace after command completion (default $WERF_RM_WITH_NAMESPACE or false if not specified)")
 	cmd.Flags().BoolVarP(&cmdData.Interactive, "interactive", "i", util.GetBoolEnvironmentDefaultFalse("WERF_INTERACTIVE"), "Enable interactive mode (default $WERF_INTERACTIVE or false if not specified)")
@@ -202,6 +230,10 @@ func NewCmd(ctx context.Context) *cobra.Command {
 	cmd.Flags().BoolVarP(&cmdData.AutoPullSecret, "auto-pull-secret", "", util.GetBoolEnvironmentDefaultTrue("WERF_AUTO_PULL_SECRET"), "Automatically create docker config secret in the namespace and plug it via pod's imagePullSecrets for private registry access (default $WERF_AUTO_PULL_SECRET or true if not specified)")
 	cmd.Flags().StringArrayVarP(&cmdData.CopyFrom, "copy-from", "", []string{}, "Copy file/dir from container to local machine after user command execution. Example: \"/from/file:to\". Can be specified multiple times. Can also be defined with \"$WERF_COPY_FROM_*\", e.g. \"WERF_COPY_FROM_1=from:to\".")
 	cmd.Flags().StringArrayVarP(&cmdData.CopyTo, "copy-to", "", []string{}, "Copy file/dir from local machine to container before user command execution. Example: \"from:/to/file\". Can be specified multiple times. Can also be defined with \"$WERF_COPY_TO_*\", e.g. \"WERF_COPY_TO_1=from:to\".")
+	cmd.Flags().StringArrayVarP(&cmdData.PortForward, "port-forward", "", []string{}, "Forward local port to the pod port while user command is running. Format: \"[LOCAL_PORT:]REMOTE_PORT\", e.g. \"8080:80\". Can be specified multiple times. Can also be defined with \"$WERF_PORT_FORWARD_*\", e.g. \"WERF_PORT_FORWARD_1=8080:80\".")
+	cmd.Flags().BoolVarP(&cmdData.AsJob, "as-job", "", util.GetBoolEnvironmentDefaultFalse("WERF_AS_JOB"), "Create a Job instead of a bare Pod, so that the Pod is recreated by Kubernetes if it fails before the command is started (default $WERF_AS_JOB or false if not specified)")
+	cmd.Flags().IntVarP(&cmdData.JobBackoffLimit, "job-backoff-limit", "", lo.Must(util.GetIntEnvVarDefault("WERF_JOB_BACKOFF_LIMIT", 0)), "Number of Pod retries before the Job created with --as-job is considered failed. The Pod is not retried if the command has failed (default $WERF_JOB_BACKOFF_LIMIT or 0)")
+	cmd.Flags().IntVarP(&cmdData.JobTTLSecondsAfterFinished, "job-ttl-seconds-after-finished", "", lo.Must(util.GetIntEnvVarDefault("WERF_JOB_TTL_SECONDS_AFTER_FINISHED", -1)), "Let Kubernetes delete the Job created with --as-job the specified number of seconds after it finishes, useful with --rm=false. Negative value disables automatic deletion (default $WERF_JOB_TTL_SECONDS_AFTER_FINISHED or -1)")
 
 	commonCmdData.SetupSkipImageSpecStage(cmd)
 	commonCmdData.SetupDebugTemplates(cmd)
@@ -261,6 +293,11 @@ func runMain(ctx context.Context) error {
 		return err
 	}
 
+	kubeClient, err := newKubeRunClient()
+	if err != nil {
+		return err
+	}
+
 	defer func() {
 		if err := tmp_manager.DelegateCleanup(ctx); err != nil {
 			logboek.Context(ctx).Warn().LogF("Temporary files cleanup preparation failed: %s\n", err)
@@ -301,21 +338,31 @@ func runMain(ctx context.Context) error {
 	}
 
 	defer func() {
-		cleanupResources(ctx, pod, secret, namespace)
+		cleanupResources(ctx, kubeClient, pod, secret, namespace)
 	}()
 
 	if *commonCmdData.Follow {
-		return common.FollowGitHead(ctx, &commonCmdData, func(ctx context.Context, headCommitGiterminismManager *giterminism_manager.Manager) error {
-			cleanupResources(ctx, pod, secret, namespace)
+		return common.FollowGitHeadWithOptions(ctx, &commonCmdData, common.FollowGitHeadOptions{
+			SyncFilesFunc: func(ctx context.Context, files []*follow.SyncFile) error {
+				return syncFilesIntoPod(ctx, kubeClient, namespace, cmdData.runningPod, pod, getImageName(werfConfig), giterminismManager.LocalGitRepo().GetWorkTreeDir(), files)
+			},
+			SyncWhileTaskRunning: true,
+		}, func(ctx context.Context, headCommitGiterminismManager *giterminism_manager.Manager) error {
+			cleanupResources(ctx, kubeClient, pod, secret, namespace)
 
-			if err := run(ctx, pod, secret, namespace, werfConfig, containerBackend, giterminismManager); err != nil {
+			_, headCommitWerfConfig, err := common.GetRequiredWerfConfig(ctx, &commonCmdData, headCommitGiterminismManager, common.GetWerfConfigOptions(&commonCmdData, false))
+			if err != nil {
+				return fmt.Errorf("unable to load werf config: %w", err)
+			}
+
+			if err := run(ctx, kubeClient, pod, secret, namespace, headCommitWerfConfig, containerBackend, headCommitGiterminismManager); err != nil {
 				return err
 			}
 
 			return nil
 		})
 	} else {
-		if err := run(ctx, pod, secret, namespace, werfConfig, containerBackend, giterminismManager); err != nil {
+		if err := run(ctx, kubeClient, pod, secret, namespace, werfConfig, containerBackend, giterminismManager); err != nil {
 			return err
 		}
 	}
@@ -323,7 +370,7 @@ func runMain(ctx context.Context) error {
 	return nil
 }
 
-func run(ctx context.Context, pod, secret, namespace string, werfConfig *config.WerfConfig, containerBackend container_backend.ContainerBackend, giterminismManager giterminism_manager.Interface) error {
+func run(ctx context.Context, kubeClient *kubeRunClient, pod, secret, namespace string, werfConfig *config.WerfConfig, containerBackend container_backend.ContainerBackend, giterminismManager giterminism_manager.Interface) error {
 	projectName := werfConfig.Meta.Project
 
 	userExtraAnnotations, err := common.GetUserExtraAnnotations(&commonCmdData)
@@ -344,11 +391,7 @@ func run(ctx context.Context, pod, secret, namespace string, werfConfig *config.
 		return fmt.Errorf("getting project tmp dir failed: %w", err)
 	}
 
-	imageName := cmdData.ImageName
-	if imageName == "" && len(werfConfig.Images(true)) == 1 {
-		// The only final image by default.
-		imageName = werfConfig.Images(true)[0].GetName()
-	}
+	imageName := getImageName(werfConfig)
 
 	imagesToProcess, err := config.NewImagesToProcess(werfConfig, []string{imageName}, false, false)
 	if err != nil {
@@ -434,43 +477,57 @@ func run(ctx context.Context, pod, secret, namespace string, werfConfig *config.
 		}
 	}
 
-	commonArgs, err := createCommonKubectlArgs(namespace)
-	if err != nil {
-		return fmt.Errorf("error creating common kubectl args: %w", err)
-	}
-
-	if err := createNamespace(ctx, namespace); err != nil {
+	if err := createNamespace(ctx, kubeClient, namespace); err != nil {
 		return fmt.Errorf("unable to create namespace: %w", err)
 	}
 
-	if err := createDockerRegistrySecret(ctx, secret, namespace, namedRef, dockerAuthConf); err != nil {
+	if err := createDockerRegistrySecret(ctx, kubeClient, secret, namespace, namedRef, dockerAuthConf); err != nil {
 		return fmt.Errorf("unable to create docker registry secret: %w", err)
 	}
 
-	return logboek.Streams().DoErrorWithoutProxyStreamDataFormatting(func() error {
-		if err := createPod(ctx, namespace, pod, image, secret, commonArgs, userExtraAnnotations, userExtraLabels); err != nil {
+	return logboek.Streams().DoErrorWithoutProxyStreamDataFormatting(func() (runErr error) {
+		if err := createPod(ctx, kubeClient, namespace, pod, image, secret, userExtraAnnotations, userExtraLabels); err != nil {
 			return fmt.Errorf("error creating Pod: %w", err)
 		}
 
-		if err := waitPodReadiness(ctx, namespace, pod, commonArgs); err != nil {
+		var job string
+		if cmdData.AsJob {
+			job = pod
+		}
+
+		runningPod, err := waitPodReadiness(ctx, kubeClient, namespace, pod, job)
+		if err != nil {
 			return fmt.Errorf("error waiting for Pod readiness: %w", err)
 		}
+		cmdData.runningPod = runningPod
 
-		defer stopContainer(ctx, namespace, pod, pod, commonArgs)
+		defer func() {
+			stopContainer(ctx, kubeClient, namespace, runningPod, pod, werfExec.ExitCode(runErr))
+		}()
 
 		for _, copyTo := range getCopyTo() {
-			if err := copyToPod(ctx, namespace, pod, pod, copyTo, commonArgs); err != nil {
+			if err := copyToPod(ctx, kubeClient, namespace, runningPod, pod, copyTo); err != nil {
 				return fmt.Errorf("error copying to Pod: %w", err)
 			}
 		}
 
 		defer func() {
 			for _, copyFrom := range getCopyFrom() {
-				copyFromPod(ctx, namespace, pod, pod, copyFrom, commonArgs)
+				copyFromPod(ctx, kubeClient, namespace, runningPod, pod, copyFrom)
 			}
 		}()
 
-		if err := execCommandInPod(ctx, namespace, pod, pod, cmdData.Command, commonArgs); err != nil {
+		if portForward := getPortForward(); len(portForward) > 0 && !*commonCmdData.DryRun {
+			logboek.Context(ctx).LogF("Forwarding ports %s to pod ...\n", strings.Join(portForward, ", "))
+
+			stopPortForward, err := kubeClient.startPortForward(ctx, namespace, runningPod, portForward, logboek.Context(ctx).OutStream())
+			if err != nil {
+				return fmt.Errorf("error forwarding ports to Pod: %w", err)
+			}
+			defer stopPortForward()
+		}
+
+		if err := execCommandInPod(ctx, kubeClient, namespace, runningPod, pod, cmdData.Command); err != nil {
 			return fmt.Errorf("error running command in Pod: %w", err)
 		}
 
@@ -478,80 +535,147 @@ func run(ctx context.Context, pod, secret, namespace string, werfConfig *config.
 	})
 }
 
-func createCommonKubectlArgs(namespace string) ([]string, error) {
-	commonArgs := []string{
-		"--namespace", namespace,
-	}
-
-	if commonCmdData.KubeContextCurrent != "" {
-		commonArgs = append(commonArgs, "--context", commonCmdData.KubeContextCurrent)
-	}
-
-	if commonCmdData.KubeConfigBase64 != "" {
-		commonArgs = append(commonArgs, "--kube-config-base64", commonCmdData.KubeConfigBase64)
-	} else if commonCmdData.LegacyKubeConfigPath != "" {
-		if err := os.Setenv("KUBECONFIG", commonCmdData.LegacyKubeConfigPath); err != nil {
-			return nil, fmt.Errorf("unable to set $KUBECONFIG env var: %w", err)
-		}
-	} else if len(commonCmdData.LegacyKubeConfigPathsMergeList) > 0 {
-		if err := os.Setenv("KUBECONFIG", common.GetFirstExistingKubeConfigEnvVar()); err != nil {
-			return nil, fmt.Errorf("unable to set $KUBECONFIG env var: %w", err)
-		}
+func getImageName(werfConfig *config.WerfConfig) string {
+	imageName := cmdData.ImageName
+	if imageName == "" && len(werfConfig.Images(true)) == 1 {
+		// The only final image by default.
+		imageName = werfConfig.Images(true)[0].GetName()
 	}
 
-	return commonArgs, nil
+	return imageName
 }
 
-func createPod(ctx context.Context, namespace, pod, image, secret string, extraArgs []string, extraAnnos, extraLabels map[string]string) error {
-	logboek.Context(ctx).LogF("Running pod %q ...\n", pod)
-
-	args, err := createKubectlRunArgs(pod, image, secret, extraArgs, extraAnnos, extraLabels)
+func createPod(ctx context.Context, client *kubeRunClient, namespace, pod, image, secret string, extraAnnos, extraLabels map[string]string) error {
+	podManifest, err := generatePod(namespace, pod, image, secret, extraAnnos, extraLabels)
 	if err != nil {
-		return fmt.Errorf("error creating kubectl run args: %w", err)
+		return fmt.Errorf("error generating pod manifest: %w", err)
 	}
 
-	cmd := werfExec.PrepareGracefulCancellation(util.ExecKubectlCmdContext(ctx, args...))
+	var manifest interface{} = podManifest
+	if cmdData.AsJob {
+		logboek.Context(ctx).LogF("Running job %q ...\n", pod)
+		manifest = generateJob(podManifest, cmdData.JobBackoffLimit, cmdData.JobTTLSecondsAfterFinished)
+	} else {
+		logboek.Context(ctx).LogF("Running pod %q ...\n", pod)
+	}
 
 	if *commonCmdData.DryRun {
-		fmt.Println(cmd.String())
+		manifestYaml, err := yaml.Marshal(manifest)
+		if err != nil {
+			return fmt.Errorf("error marshaling manifest: %w", err)
+		}
+
+		fmt.Println(string(manifestYaml))
 		return nil
 	}
 
-	if err := cmd.Run(); err != nil {
-		werfExec.TerminateIfCanceled(ctx)
-		return fmt.Errorf("error running pod: %w", err)
+	switch m := manifest.(type) {
+	case *batchv1.Job:
+		if _, err := client.clientset.BatchV1().Jobs(namespace).Create(ctx, m, v1.CreateOptions{}); err != nil {
+			return fmt.Errorf("error creating job %s/%s: %w", namespace, pod, err)
+		}
+	case *corev1.Pod:
+		if _, err := client.clientset.CoreV1().Pods(namespace).Create(ctx, m, v1.CreateOptions{}); err != nil {
+			return fmt.Errorf("error creating pod %s/%s: %w", namespace, pod, err)
+		}
 	}
 
 	return nil
 }
 
-func createKubectlRunArgs(pod, image, secret string, extraArgs []string, extraAnnos, extraLabels map[string]string) ([]string, error) {
-	args := []string{
-		"run",
-		pod,
-		"--image", image,
-		"--command",
-		"--restart", "Never",
-		"--quiet",
-		"--pod-running-timeout=6h",
+// generatePod builds the Pod the same way as "kubectl run --overrides --override-type strategic" did.
+func generatePod(namespace, pod, image, secret string, extraAnnos, extraLabels map[string]string) (*corev1.Pod, error) {
+	basePod := &corev1.Pod{
+		TypeMeta: v1.TypeMeta{
+			APIVersion: "v1",
+			Kind:       "Pod",
+		},
+		ObjectMeta: v1.ObjectMeta{
+			Name:      pod,
+			Namespace: namespace,
+			Labels:    map[string]string{"run": pod},
+		},
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{
+				{
+					Name:  pod,
+					Image: image,
+				},
+			},
+			RestartPolicy: corev1.RestartPolicyNever,
+		},
 	}
 
-	args = append(args, extraArgs...)
+	basePodJson, err := json.Marshal(basePod)
+	if err != nil {
+		return nil, fmt.Errorf("error marshaling pod: %w", err)
+	}
 
-	if overrides, err := generateOverrides(pod, secret, extraAnnos, extraLabels); err != nil {
+	overrides, err := generateOverrides(pod, secret, extraAnnos, extraLabels)
+	if err != nil {
 		return nil, fmt.Errorf("error generating --overrides: %w", err)
-	} else if overrides != nil {
-		args = append(args, "--overrides", string(overrides), "--override-type", "strategic")
 	}
 
-	if cmdData.RunExtraOptions != "" {
-		args = append(args, strings.Fields(cmdData.RunExtraOptions)...)
+	podJson, err := strategicpatch.StrategicMergePatch(basePodJson, overrides, corev1.Pod{})
+	if err != nil {
+		return nil, fmt.Errorf("error applying --overrides: %w", err)
 	}
 
-	return args, nil
+	result := &corev1.Pod{}
+	if err := json.Unmarshal(podJson, result); err != nil {
+		return nil, fmt.Errorf("error unmarshaling pod: %w", err)
+	}
+	result.Namespace = namespace
+
+	return result, nil
+}
+
+// generateJob wraps the Pod into the Job. Negative ttlSecondsAfterFinished leaves the finished Job in place.
+// The Pod is retried only if it fails before the user command is run: the main container (named as the Pod) exits
+// with the exit code of the user command, and a non-zero one fails the Job at once.
+func generateJob(pod *corev1.Pod, backoffLimit, ttlSecondsAfterFinished int) *batchv1.Job {
+	job := &batchv1.Job{
+		TypeMeta: v1.TypeMeta{
+			APIVersion: "batch/v1",
+			Kind:       "Job",
+		},
+		ObjectMeta: v1.ObjectMeta{
+			Name:        pod.Name,
+			Namespace:   pod.Namespace,
+			Labels:      pod.Labels,
+			Annotations: pod.Annotations,
+		},
+		Spec: batchv1.JobSpec{
+			BackoffLimit: ptr.To(int32(backoffLimit)),
+			PodFailurePolicy: &batchv1.PodFailurePolicy{
+				Rules: []batchv1.PodFailurePolicyRule{
+					{
+						Action: batchv1.PodFailurePolicyActionFailJob,
+						OnExitCodes: &batchv1.PodFailurePolicyOnExitCodesRequirement{
+							ContainerName: ptr.To(pod.Name),
+							Operator:      batchv1.PodFailurePolicyOnExitCodesOpNotIn,
+							Values:        []int32{0},
+						},
+					},
+				},
+			},
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: v1.ObjectMeta{
+					Labels:      pod.Labels,
+					Annotations: pod.Annotations,
+				},
+				Spec: pod.Spec,
+			},
+		},
+	}
+
+	if ttlSecondsAfterFinished >= 0 {
+		job.Spec.TTLSecondsAfterFinished = ptr.To(int32(ttlSecondsAfterFinished))
+	}
+
+	return job
 }
 
-// Can return nil overrides.
 func generateOverrides(container, secret string, extraAnnos, extraLabels map[string]string) ([]byte, error) {
 	codec := runtime.NewCodec(scheme.DefaultJSONEncoder(), scheme.Codecs.UniversalDeserializer())
 
@@ -591,11 +715,12 @@ func createMainContainer(pod *corev1.Pod, container string) {
 		pod.Spec.Containers = append(pod.Spec.Containers, corev1.Container{Name: container})
 	}
 
+	// The container exits with the exit code of the user command, so that the Pod and Job statuses reflect it.
 	pod.Spec.Containers[getContainerIndex(pod, container)].Command = []string{
 		"sh", "-euc",
 	}
 	pod.Spec.Containers[getContainerIndex(pod, container)].Args = []string{
-		"until [ -f /tmp/werf-kube-run-quit ]; do sleep 1; done",
+		fmt.Sprintf(`until [ -f %[1]s ]; do sleep 1; done; exit "$(cat %[1]s)"`, quitFilePath),
 	}
 }
 
@@ -625,232 +750,179 @@ func cleanPodManifest(podJsonManifest []byte) ([]byte, error) {
 	}
 }
 
-func waitPodReadiness(ctx context.Context, namespace, pod string, extraArgs []string) error {
-	if *commonCmdData.DryRun {
-		return nil
-	}
-
-	logboek.Context(ctx).LogF("Waiting for pod to be ready ...\n")
-
-	for {
-		phase, err := getPodPhase(ctx, namespace, pod, extraArgs)
-		if err != nil {
-			return fmt.Errorf("error getting Pod phase: %w", err)
-		}
+func copyFromPod(ctx context.Context, client *kubeRunClient, namespace, pod, container string, copyFrom copyFromTo) {
+	ctx = context.WithoutCancel(ctx)
 
-		switch phase {
-		case corev1.PodFailed:
-			return fmt.Errorf("pod %s/%s failed", namespace, pod)
-		case corev1.PodSucceeded:
-			return fmt.Errorf("pod %s/%s stopped too early", namespace, pod)
-		case corev1.PodRunning:
-			if ready, err := isPodReady(ctx, namespace, pod, extraArgs); err != nil {
-				return fmt.Errorf("error checking pod readiness: %w", err)
-			} else if ready {
-				return nil
-			} else {
-				continue
-			}
-		default:
-			continue
-		}
-	}
-}
+	logboek.Context(ctx).LogF("Copying %q from pod to %q ...\n", copyFrom.Src, copyFrom.Dst)
 
-func getPodPhase(ctx context.Context, namespace, pod string, extraArgs []string) (corev1.PodPhase, error) {
-	args := []string{
-		"get", "pod", "--template", "{{.status.phase}}", pod,
+	if *commonCmdData.DryRun {
+		return
 	}
 
-	args = append(args, extraArgs...)
-
-	cmd := werfExec.PrepareGracefulCancellation(util.ExecKubectlCmdContext(ctx, args...))
-
-	var stdout bytes.Buffer
-	cmd.Stdout = &stdout
-
-	if err := cmd.Run(); err != nil {
-		werfExec.TerminateIfCanceled(ctx)
-		return "", fmt.Errorf("error getting pod %s/%s spec: %w", namespace, pod, err)
+	if err := client.copyFromPod(ctx, namespace, pod, container, copyFrom.Src, copyFrom.Dst); err != nil {
+		logboek.Context(ctx).Warn().LogF("Error copying %q from pod %s/%s: %s\n", copyFrom.Src, namespace, pod, err)
 	}
-
-	return corev1.PodPhase(strings.TrimSpace(stdout.String())), nil
 }
 
-func isPodReady(ctx context.Context, namespace, pod string, extraArgs []string) (bool, error) {
-	args := []string{
-		"get", "pod", "--template", "{{range .status.conditions}}{{if eq .type \"Ready\"}}{{.status}}{{end}}{{end}}", pod,
-	}
-
-	args = append(args, extraArgs...)
-
-	cmd := werfExec.PrepareGracefulCancellation(util.ExecKubectlCmdContext(ctx, args...))
+func copyToPod(ctx context.Context, client *kubeRunClient, namespace, pod, container string, copyTo copyFromTo) error {
+	logboek.Context(ctx).LogF("Copying %q to %q in pod ...\n", copyTo.Src, copyTo.Dst)
 
-	var stdout bytes.Buffer
-	cmd.Stdout = &stdout
+	if *commonCmdData.DryRun {
+		return nil
+	}
 
-	if err := cmd.Run(); err != nil {
+	if err := client.copyToPod(ctx, namespace, pod, container, copyTo.Src, copyTo.Dst); err != nil {
 		werfExec.TerminateIfCanceled(ctx)
-		return false, fmt.Errorf("error getting pod %s/%s spec: %w", namespace, pod, err)
+		return fmt.Errorf("error copying %q to pod %s/%s: %w", copyTo.Src, namespace, pod, err)
 	}
 
-	switch strings.TrimSpace(stdout.String()) {
-	case "True":
-		return true, nil
-	default:
-		return false, nil
-	}
+	return nil
 }
 
-func copyFromPod(ctx context.Context, namespace, pod, container string, copyFrom copyFromTo, extraArgs []string) {
-	ctx = context.WithoutCancel(ctx)
-
-	logboek.Context(ctx).LogF("Copying %q from pod to %q ...\n", copyFrom.Src, copyFrom.Dst)
-
-	args := []string{
-		"cp", fmt.Sprint(namespace, "/", pod, ":", copyFrom.Src), copyFrom.Dst, "-c", container,
-	}
-
-	args = append(args, extraArgs...)
-
-	cmd := werfExec.PrepareGracefulCancellation(util.ExecKubectlCmdContext(ctx, args...))
-
-	if *commonCmdData.DryRun {
-		fmt.Println(cmd.String())
-		return
-	}
-
-	if err := cmd.Run(); err != nil {
-		logboek.Context(ctx).Warn().LogF("Error copying %q from pod %s/s: %s\n", copyFrom.Src, namespace, pod, err)
+// syncFilesIntoPod copies the changed files of the image into the container of the running pod.
+func syncFilesIntoPod(ctx context.Context, client *kubeRunClient, namespace, pod, container, imageName, workTreeDir string, files []*follow.SyncFile) error {
+	files = follow.GroupSyncFilesByImage(files)[imageName]
+	if len(files) == 0 {
+		return nil
 	}
-}
-
-func copyToPod(ctx context.Context, namespace, pod, container string, copyFrom copyFromTo, extraArgs []string) error {
-	logboek.Context(ctx).LogF("Copying %q to %q in pod ...\n", copyFrom.Src, copyFrom.Dst)
 
-	args := []string{
-		"cp", copyFrom.Src, fmt.Sprint(namespace, "/", pod, ":", copyFrom.Dst), "-c", container,
+	archive := bytes.NewBuffer(nil)
+	commands, err := follow.WriteSyncFilesArchive(archive, workTreeDir, files)
+	if err != nil {
+		return fmt.Errorf("unable to prepare files archive: %w", err)
 	}
 
-	args = append(args, extraArgs...)
-
-	cmd := werfExec.PrepareGracefulCancellation(util.ExecKubectlCmdContext(ctx, args...))
+	commands = append([][]string{{"tar", "-xf", "-", "-C", "/"}}, commands...)
+	for i, command := range commands {
+		var stdin io.Reader
+		if i == 0 {
+			stdin = archive
+		}
 
-	if *commonCmdData.DryRun {
-		fmt.Println(cmd.String())
-		return nil
+		if err := client.execQuiet(ctx, namespace, pod, container, command, stdin); err != nil {
+			werfExec.TerminateIfCanceled(ctx)
+			return fmt.Errorf("error running %q in pod %s/%s: %w", strings.Join(command, " "), namespace, pod, err)
+		}
 	}
 
-	if err := cmd.Run(); err != nil {
-		werfExec.TerminateIfCanceled(ctx)
-		return fmt.Errorf("error copying %q to pod %s/%s: %w", copyFrom.Src, namespace, pod, err)
-	}
+	logboek.Context(ctx).Default().LogF("Synced %d file(s) into pod %s/%s\n", len(files), namespace, pod)
 
 	return nil
 }
 
-func stopContainer(ctx context.Context, namespace, pod, container string, extraArgs []string) {
+// stopContainer makes the main container exit with the exit code of the user command.
+func stopContainer(ctx context.Context, client *kubeRunClient, namespace, pod, container string, exitCode int) {
 	ctx = context.WithoutCancel(ctx)
 
 	logboek.Context(ctx).LogF("Stopping container %q in pod ...\n", container)
 
-	args := []string{
-		"exec", pod, "-q", "--pod-running-timeout", "5h", "-c", container,
-	}
-
-	args = append(args, extraArgs...)
-	args = append(args, "--", "touch", "/tmp/werf-kube-run-quit")
-
-	cmd := werfExec.PrepareGracefulCancellation(util.ExecKubectlCmdContext(ctx, args...))
-
 	if *commonCmdData.DryRun {
-		fmt.Println(cmd.String())
 		return
 	}
 
-	if err := cmd.Run(); err != nil {
+	if exitCode < 0 || exitCode > 255 {
+		exitCode = 1
+	}
+
+	command := []string{"sh", "-c", fmt.Sprintf("echo %d > %[2]s.tmp && mv %[2]s.tmp %[2]s", exitCode, quitFilePath)}
+	if err := client.execQuiet(ctx, namespace, pod, container, command, nil); err != nil {
 		logboek.Context(ctx).Warn().LogF("Error stopping service container %s/%s/%s for copying files: %s\n", namespace, pod, container, err)
 	}
 }
 
-func signalContainer(ctx context.Context, namespace, pod, container string, extraArgs []string) error {
+func signalContainer(ctx context.Context, client *kubeRunClient, namespace, pod, container string) error {
 	ctx = context.WithoutCancel(ctx)
 
 	logboek.Context(ctx).LogF("Signal container %q in pod ...\n", container)
 
-	args := []string{
-		"exec", pod, "-q", "--pod-running-timeout", "5s", "-c", container,
-	}
-
-	args = append(args, extraArgs...)
-	args = append(args, "--", "pkill", "-P", "0")
-
-	cmd := werfExec.PrepareGracefulCancellation(util.ExecKubectlCmdContext(ctx, args...))
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
 
-	if err := cmd.Run(); err != nil {
+	if err := client.execQuiet(ctx, namespace, pod, container, []string{"pkill", "-P", "0"}, nil); err != nil {
 		return fmt.Errorf("signal container error: %w", err)
 	}
 
 	return nil
 }
 
-func execCommandInPod(ctx context.Context, namespace, pod, container string, command, extraArgs []string) error {
+func execCommandInPod(ctx context.Context, client *kubeRunClient, namespace, pod, container string, command []string) error {
 	logboek.Context(ctx).LogF("Executing into pod ...\n")
 
-	args := []string{
-		"exec", pod, "-q", "--pod-running-timeout", "5h", "-c", container,
+	if *commonCmdData.DryRun {
+		return nil
 	}
 
-	args = append(args, extraArgs...)
-
+	opts := execOptions{
+		Command: command,
+		Stdout:  os.Stdout,
+		Stderr:  os.Stderr,
+	}
 	if cmdData.Interactive {
-		args = append(args, "-i")
+		opts.Stdin = os.Stdin
 	}
 
-	if cmdData.AllocateTty {
-		args = append(args, "-t")
+	execFunc := func() error {
+		return client.exec(ctx, namespace, pod, container, opts)
 	}
 
-	args = append(args, "--")
-	args = append(args, command...)
-
-	cmd := werfExec.PrepareGracefulCancellation(util.ExecKubectlCmdContext(ctx, args...))
-	cmd.Cancel = func() error {
-		return signalContainer(ctx, namespace, pod, container, extraArgs)
+	if cmdData.AllocateTty {
+		tty := term.TTY{In: os.Stdin, Out: os.Stdout, Raw: true}
+		opts.Stdin = tty.In
+		opts.Stdout = tty.Out
+		opts.TTY = true
+		opts.TerminalSizeQueue = tty.MonitorSize(tty.GetSize())
+
+		execFunc = func() error {
+			return tty.Safe(func() error {
+				return client.exec(ctx, namespace, pod, container, opts)
+			})
+		}
 	}
 
-	if *commonCmdData.DryRun {
-		fmt.Println(cmd.String())
-		return nil
-	}
+	if err := execFunc(); err != nil {
+		if ctx.Err() != nil {
+			if err := signalContainer(ctx, client, namespace, pod, container); err != nil {
+				logboek.Context(ctx).Warn().LogF("WARNING: %s\n", err)
+			}
+			werfExec.TerminateIfCanceled(ctx)
+		}
 
-	if err := cmd.Run(); err != nil {
-		werfExec.TerminateIfCanceled(ctx)
-		return fmt.Errorf("error running command %q in pod %s/%s: %w", cmd, namespace, pod, err)
+		return fmt.Errorf("error running command %q in pod %s/%s: %w", strings.Join(command, " "), namespace, pod, err)
 	}
 
 	return nil
 }
 
-func cleanupResources(ctx context.Context, pod, secret, namespace string) {
+func cleanupResources(ctx context.Context, client *kubeRunClient, pod, secret, namespace string) {
 	ctx = context.WithoutCancel(ctx)
 
 	if !cmdData.Rm || *commonCmdData.DryRun {
 		return
 	}
 
-	logboek.Context(ctx).LogF("Cleaning up pod %q ...\n", pod)
-	if err := kube.Client.CoreV1().Pods(namespace).Delete(ctx, pod, v1.DeleteOptions{}); err != nil {
-		if errorsK8s.IsNotFound(err) {
-			logboek.Context(ctx).LogF("Pod %q not found\n", pod)
-		} else {
-			logboek.Context(ctx).Warn().LogF("WARNING: pod cleaning up failed: %s\n", err)
+	if cmdData.AsJob {
+		logboek.Context(ctx).LogF("Cleaning up job %q ...\n", pod)
+		if err := client.clientset.BatchV1().Jobs(namespace).Delete(ctx, pod, v1.DeleteOptions{PropagationPolicy: ptr.To(v1.DeletePropagationBackground)}); err != nil {
+			if errorsK8s.IsNotFound(err) {
+				logboek.Context(ctx).LogF("Job %q not found\n", pod)
+			} else {
+				logboek.Context(ctx).Warn().LogF("WARNING: job cleaning up failed: %s\n", err)
+			}
+		}
+	} else {
+		logboek.Context(ctx).LogF("Cleaning up pod %q ...\n", pod)
+		if err := client.clientset.CoreV1().Pods(namespace).Delete(ctx, pod, v1.DeleteOptions{}); err != nil {
+			if errorsK8s.IsNotFound(err) {
+				logboek.Context(ctx).LogF("Pod %q not found\n", pod)
+			} else {
+				logboek.Context(ctx).Warn().LogF("WARNING: pod cleaning up failed: %s\n", err)
+			}
 		}
 	}
 
 	if cmdData.AutoPullSecret && cmdData.registryCredsFound {
 		logboek.Context(ctx).LogF("Cleaning up secret %q ...\n", secret)
-		if err := kube.Client.CoreV1().Secrets(namespace).Delete(ctx, secret, v1.DeleteOptions{}); err != nil {
+		if err := client.clientset.CoreV1().Secrets(namespace).Delete(ctx, secret, v1.DeleteOptions{}); err != nil {
 			if errorsK8s.IsNotFound(err) {
 				logboek.Context(ctx).LogF("Secret %q not found\n", secret)
 			} else {
@@ -861,20 +933,20 @@ func cleanupResources(ctx context.Context, pod, secret, namespace string) {
 
 	if cmdData.RmWithNamespace {
 		logboek.Context(ctx).LogF("Cleaning up namespace %q ...\n", namespace)
-		if err := kube.Client.CoreV1().Namespaces().Delete(ctx, namespace, v1.DeleteOptions{}); err != nil {
+		if err := client.clientset.CoreV1().Namespaces().Delete(ctx, namespace, v1.DeleteOptions{}); err != nil {
 			logboek.Context(ctx).Warn().LogF("WARNING: namespace cleaning up failed: %s\n", err)
 		}
 	}
 }
 
-func createNamespace(ctx context.Context, namespace string) error {
+func createNamespace(ctx context.Context, client *kubeRunClient, namespace string) error {
 	if *commonCmdData.DryRun {
 		return nil
 	}
 
 	logboek.Context(ctx).LogF("Creating namespace %q ...\n", namespace)
 
-	if _, err := kube.Client.CoreV1().Namespaces().Create(
+	if _, err := client.clientset.CoreV1().Namespaces().Create(
 		ctx,
 		&corev1.Namespace{
 			ObjectMeta: v1.ObjectMeta{
@@ -896,7 +968,7 @@ func createNamespace(ctx context.Context, namespace string) error {
 	return nil
 }
 
-func createDockerRegistrySecret(ctx context.Context, name, namespace string, ref reference.Named, dockerAuthConf imgtypes.DockerAuthConfig) error {
+func createDockerRegistrySecret(ctx context.Context, client *kubeRunClient, name, namespace string, ref reference.Named, dockerAuthConf imgtypes.DockerAuthConfig) error {
 	if *commonCmdData.DryRun || !cmdData.registryCredsFound {
 		return nil
 	}
@@ -934,7 +1006,7 @@ func createDockerRegistrySecret(ctx context.Context, name, namespace string, ref
 	secret.Data[corev1.DockerConfigJsonKey] = dockerConf
 
 	logboek.Context(ctx).LogF("Creating secret %q ...\n", name)
-	if _, err := kube.Client.CoreV1().Secrets(namespace).Create(ctx, secret, v1.CreateOptions{}); err != nil {
+	if _, err := client.clientset.CoreV1().Secrets(namespace).Create(ctx, secret, v1.CreateOptions{}); err != nil {
 		return fmt.Errorf("error creating secret %s/%s: %w", namespace, secret, err)
 	}
 
@@ -1134,3 +1206,7 @@ func getCopyFromRaw() []string {
 func getCopyToRaw() []string {
 	return append(util.PredefinedValuesByEnvNamePrefix("WERF_COPY_TO_"), cmdData.CopyTo...)
 }
+
+func getPortForward() []string {
+	return append(util.PredefinedValuesByEnvNamePrefix("WERF_PORT_FORWARD_"), cmdData.PortForward...)
+}
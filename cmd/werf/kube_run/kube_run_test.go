@@ -0,0 +1,85 @@
+package kube_run
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/utils/ptr"
+)
+
+var _ = Describe("kube-run manifests", func() {
+	var savedCmdData cmdDataType
+
+	BeforeEach(func() {
+		savedCmdData = cmdData
+	})
+
+	AfterEach(func() {
+		cmdData = savedCmdData
+	})
+
+	It("should apply overrides to the generated pod", func() {
+		cmdData.Overrides = `{"spec":{"nodeSelector":{"pool":"ci"},"containers":[{"name":"werf-run","env":[{"name":"MODE","value":"test"}]}]}}`
+		cmdData.AutoPullSecret = true
+		cmdData.registryCredsFound = true
+
+		pod, err := generatePod("ns", "werf-run", "registry.example.com/app:tag", "werf-run", map[string]string{"anno": "value"}, map[string]string{"label": "value"})
+		Expect(err).To(Succeed())
+
+		Expect(pod.Name).To(Equal("werf-run"))
+		Expect(pod.Namespace).To(Equal("ns"))
+		Expect(pod.Labels).To(Equal(map[string]string{"run": "werf-run", "label": "value"}))
+		Expect(pod.Annotations).To(Equal(map[string]string{"anno": "value"}))
+		Expect(pod.Spec.RestartPolicy).To(Equal(corev1.RestartPolicyNever))
+		Expect(pod.Spec.NodeSelector).To(Equal(map[string]string{"pool": "ci"}))
+		Expect(pod.Spec.ImagePullSecrets).To(Equal([]corev1.LocalObjectReference{{Name: "werf-run"}}))
+
+		Expect(pod.Spec.Containers).To(HaveLen(1))
+		container := pod.Spec.Containers[0]
+		Expect(container.Image).To(Equal("registry.example.com/app:tag"))
+		Expect(container.Env).To(Equal([]corev1.EnvVar{{Name: "MODE", Value: "test"}}))
+		Expect(container.Command).To(Equal([]string{"sh", "-euc"}))
+		Expect(container.Args).To(HaveLen(1))
+		Expect(container.Args[0]).To(ContainSubstring(quitFilePath))
+	})
+
+	It("should keep sidecar containers from overrides", func() {
+		cmdData.Overrides = `{"spec":{"containers":[{"name":"sidecar","image":"busybox"}]}}`
+
+		pod, err := generatePod("ns", "werf-run", "app:tag", "werf-run", nil, nil)
+		Expect(err).To(Succeed())
+
+		Expect(pod.Spec.Containers).To(HaveLen(2))
+		Expect(pod.Spec.Containers[getContainerIndex(pod, "werf-run")].Image).To(Equal("app:tag"))
+		Expect(pod.Spec.Containers[getContainerIndex(pod, "sidecar")].Image).To(Equal("busybox"))
+	})
+
+	It("should wrap the pod into the job", func() {
+		cmdData.Overrides = `{}`
+
+		pod, err := generatePod("ns", "werf-run", "app:tag", "werf-run", nil, map[string]string{"label": "value"})
+		Expect(err).To(Succeed())
+
+		job := generateJob(pod, 2, -1)
+		Expect(job.Name).To(Equal("werf-run"))
+		Expect(job.Namespace).To(Equal("ns"))
+		Expect(*job.Spec.BackoffLimit).To(Equal(int32(2)))
+		Expect(job.Spec.TTLSecondsAfterFinished).To(BeNil())
+		Expect(job.Spec.Template.Labels).To(Equal(pod.Labels))
+		Expect(job.Spec.Template.Spec).To(Equal(pod.Spec))
+		Expect(job.Spec.PodFailurePolicy.Rules).To(Equal([]batchv1.PodFailurePolicyRule{
+			{
+				Action: batchv1.PodFailurePolicyActionFailJob,
+				OnExitCodes: &batchv1.PodFailurePolicyOnExitCodesRequirement{
+					ContainerName: ptr.To("werf-run"),
+					Operator:      batchv1.PodFailurePolicyOnExitCodesOpNotIn,
+					Values:        []int32{0},
+				},
+			},
+		}))
+
+		job = generateJob(pod, 0, 600)
+		Expect(*job.Spec.TTLSecondsAfterFinished).To(Equal(int32(600)))
+	})
+})
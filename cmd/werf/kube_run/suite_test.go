@@ -0,0 +1,13 @@
+package kube_run
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestCmdKubeRun(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Cmd Kube Run Suite")
+}
@@ -0,0 +1,195 @@
+package kube_run
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/tools/cache"
+	watchtools "k8s.io/client-go/tools/watch"
+
+	"github.com/werf/logboek"
+)
+
+// podReadinessTracker follows the pod of kube-run (or the pods of its Job) until one becomes ready.
+type podReadinessTracker struct {
+	// JobName is set if the pods are created by the Job.
+	JobName      string
+	BackoffLimit int
+
+	OnStatusChange func(pod, status string)
+
+	ReadyPod string
+
+	lastStatuses map[string]string
+	failedPods   map[string]struct{}
+}
+
+func newPodReadinessTracker(jobName string, backoffLimit int, onStatusChange func(pod, status string)) *podReadinessTracker {
+	return &podReadinessTracker{
+		JobName:        jobName,
+		BackoffLimit:   backoffLimit,
+		OnStatusChange: onStatusChange,
+		lastStatuses:   map[string]string{},
+		failedPods:     map[string]struct{}{},
+	}
+}
+
+// Condition implements watchtools.ConditionFunc.
+func (t *podReadinessTracker) Condition(event watch.Event) (bool, error) {
+	if event.Type == watch.Error {
+		return false, apierrors.FromObject(event.Object)
+	}
+
+	pod, ok := event.Object.(*corev1.Pod)
+	if !ok {
+		return false, nil
+	}
+
+	if event.Type == watch.Deleted {
+		if t.JobName == "" {
+			return false, fmt.Errorf("pod %s/%s was deleted", pod.Namespace, pod.Name)
+		}
+		return false, nil
+	}
+
+	if status := describePodStatus(pod); t.lastStatuses[pod.Name] != status {
+		t.lastStatuses[pod.Name] = status
+		if t.OnStatusChange != nil {
+			t.OnStatusChange(pod.Name, status)
+		}
+	}
+
+	switch pod.Status.Phase {
+	case corev1.PodFailed:
+		if t.JobName == "" {
+			return false, fmt.Errorf("pod %s/%s failed", pod.Namespace, pod.Name)
+		}
+
+		t.failedPods[pod.Name] = struct{}{}
+		if len(t.failedPods) > t.BackoffLimit {
+			return false, fmt.Errorf("job %s/%s failed: backoff limit %d reached", pod.Namespace, t.JobName, t.BackoffLimit)
+		}
+	case corev1.PodSucceeded:
+		return false, fmt.Errorf("pod %s/%s stopped too early", pod.Namespace, pod.Name)
+	case corev1.PodRunning:
+		if isPodReady(pod) {
+			t.ReadyPod = pod.Name
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+func isPodReady(pod *corev1.Pod) bool {
+	for _, cond := range pod.Status.Conditions {
+		if cond.Type == corev1.PodReady {
+			return cond.Status == corev1.ConditionTrue
+		}
+	}
+
+	return false
+}
+
+// describePodStatus returns the pod phase with the reason of the first not running container, e.g. "Pending (ImagePullBackOff)".
+func describePodStatus(pod *corev1.Pod) string {
+	phase := string(pod.Status.Phase)
+	if phase == "" {
+		phase = string(corev1.PodPending)
+	}
+
+	statuses := append(append([]corev1.ContainerStatus{}, pod.Status.InitContainerStatuses...), pod.Status.ContainerStatuses...)
+	for _, status := range statuses {
+		switch {
+		case status.State.Waiting != nil && status.State.Waiting.Reason != "":
+			return fmt.Sprintf("%s (%s)", phase, status.State.Waiting.Reason)
+		case status.State.Terminated != nil && status.State.Terminated.Reason != "" && pod.Status.Phase != corev1.PodRunning:
+			return fmt.Sprintf("%s (%s)", phase, status.State.Terminated.Reason)
+		}
+	}
+
+	for _, cond := range pod.Status.Conditions {
+		if cond.Type == corev1.PodScheduled && cond.Status == corev1.ConditionFalse && cond.Reason != "" {
+			return fmt.Sprintf("%s (%s)", phase, cond.Reason)
+		}
+	}
+
+	return phase
+}
+
+// waitPodReadiness waits for the pod (or for a pod of the job) to become ready and returns its name.
+// Events of the pods and of the job are printed while waiting.
+func waitPodReadiness(ctx context.Context, client *kubeRunClient, namespace, pod, job string) (string, error) {
+	if *commonCmdData.DryRun {
+		return pod, nil
+	}
+
+	logboek.Context(ctx).LogF("Waiting for pod to be ready ...\n")
+
+	eventsCtx, stopEvents := context.WithCancel(ctx)
+	defer stopEvents()
+	go streamEvents(eventsCtx, client, namespace, func(obj corev1.ObjectReference) bool {
+		if job != "" {
+			return (obj.Kind == "Job" && obj.Name == job) || (obj.Kind == "Pod" && strings.HasPrefix(obj.Name, job+"-"))
+		}
+		return obj.Kind == "Pod" && obj.Name == pod
+	})
+
+	tracker := newPodReadinessTracker(job, cmdData.JobBackoffLimit, func(pod, status string) {
+		logboek.Context(ctx).LogF("Pod %q status: %s\n", pod, status)
+	})
+
+	lw := cache.NewFilteredListWatchFromClient(client.clientset.CoreV1().RESTClient(), "pods", namespace, func(options *v1.ListOptions) {
+		if job != "" {
+			options.LabelSelector = labels.Set{"job-name": job}.String()
+		} else {
+			options.FieldSelector = fields.OneTermEqualSelector("metadata.name", pod).String()
+		}
+	})
+
+	if _, err := watchtools.UntilWithSync(ctx, lw, &corev1.Pod{}, nil, tracker.Condition); err != nil {
+		return "", err
+	}
+
+	return tracker.ReadyPod, nil
+}
+
+// streamEvents prints the events of matching objects until the context is canceled.
+func streamEvents(ctx context.Context, client *kubeRunClient, namespace string, match func(obj corev1.ObjectReference) bool) {
+	since := time.Now().Truncate(time.Second)
+
+	lw := cache.NewFilteredListWatchFromClient(client.clientset.CoreV1().RESTClient(), "events", namespace, nil)
+	_, _ = watchtools.UntilWithSync(ctx, lw, &corev1.Event{}, nil, func(event watch.Event) (bool, error) {
+		if event.Type != watch.Added && event.Type != watch.Modified {
+			return false, nil
+		}
+
+		ev, ok := event.Object.(*corev1.Event)
+		if !ok || !match(ev.InvolvedObject) || eventTime(ev).Before(since) {
+			return false, nil
+		}
+
+		logboek.Context(ctx).LogF("Event for %s %q: %s: %s\n", strings.ToLower(ev.InvolvedObject.Kind), ev.InvolvedObject.Name, ev.Reason, strings.TrimSpace(ev.Message))
+
+		return false, nil
+	})
+}
+
+func eventTime(ev *corev1.Event) time.Time {
+	switch {
+	case !ev.LastTimestamp.IsZero():
+		return ev.LastTimestamp.Time
+	case !ev.EventTime.IsZero():
+		return ev.EventTime.Time
+	default:
+		return ev.CreationTimestamp.Time
+	}
+}
@@ -0,0 +1,109 @@
+package kube_run
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/watch"
+)
+
+func newTestPod(name string, phase corev1.PodPhase, ready bool, waitingReason string) *corev1.Pod {
+	pod := &corev1.Pod{
+		ObjectMeta: v1.ObjectMeta{Name: name, Namespace: "ns"},
+		Status:     corev1.PodStatus{Phase: phase},
+	}
+
+	readyStatus := corev1.ConditionFalse
+	if ready {
+		readyStatus = corev1.ConditionTrue
+	}
+	pod.Status.Conditions = []corev1.PodCondition{{Type: corev1.PodReady, Status: readyStatus}}
+
+	if waitingReason != "" {
+		pod.Status.ContainerStatuses = []corev1.ContainerStatus{
+			{Name: "main", State: corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: waitingReason}}},
+		}
+	}
+
+	return pod
+}
+
+var _ = Describe("podReadinessTracker", func() {
+	var statuses []string
+
+	newTracker := func(jobName string, backoffLimit int) *podReadinessTracker {
+		statuses = nil
+		return newPodReadinessTracker(jobName, backoffLimit, func(pod, status string) {
+			statuses = append(statuses, pod+": "+status)
+		})
+	}
+
+	It("should wait for the pod to become ready and report status changes once", func() {
+		tracker := newTracker("", 0)
+
+		for _, pod := range []*corev1.Pod{
+			newTestPod("werf-run", corev1.PodPending, false, "ContainerCreating"),
+			newTestPod("werf-run", corev1.PodPending, false, "ContainerCreating"),
+			newTestPod("werf-run", corev1.PodRunning, false, ""),
+		} {
+			done, err := tracker.Condition(watch.Event{Type: watch.Modified, Object: pod})
+			Expect(err).To(Succeed())
+			Expect(done).To(BeFalse())
+		}
+
+		done, err := tracker.Condition(watch.Event{Type: watch.Modified, Object: newTestPod("werf-run", corev1.PodRunning, true, "")})
+		Expect(err).To(Succeed())
+		Expect(done).To(BeTrue())
+		Expect(tracker.ReadyPod).To(Equal("werf-run"))
+		Expect(statuses).To(Equal([]string{"werf-run: Pending (ContainerCreating)", "werf-run: Running"}))
+	})
+
+	It("should fail if the pod failed or stopped", func() {
+		_, err := newTracker("", 0).Condition(watch.Event{Type: watch.Modified, Object: newTestPod("werf-run", corev1.PodFailed, false, "")})
+		Expect(err).To(MatchError(ContainSubstring("failed")))
+
+		_, err = newTracker("", 0).Condition(watch.Event{Type: watch.Modified, Object: newTestPod("werf-run", corev1.PodSucceeded, false, "")})
+		Expect(err).To(MatchError(ContainSubstring("stopped too early")))
+
+		_, err = newTracker("", 0).Condition(watch.Event{Type: watch.Deleted, Object: newTestPod("werf-run", corev1.PodRunning, false, "")})
+		Expect(err).To(MatchError(ContainSubstring("deleted")))
+	})
+
+	It("should wait for the next pod of the job until backoff limit is reached", func() {
+		tracker := newTracker("werf-run", 1)
+
+		done, err := tracker.Condition(watch.Event{Type: watch.Modified, Object: newTestPod("werf-run-aaaaa", corev1.PodFailed, false, "")})
+		Expect(err).To(Succeed())
+		Expect(done).To(BeFalse())
+
+		done, err = tracker.Condition(watch.Event{Type: watch.Deleted, Object: newTestPod("werf-run-aaaaa", corev1.PodFailed, false, "")})
+		Expect(err).To(Succeed())
+		Expect(done).To(BeFalse())
+
+		_, err = tracker.Condition(watch.Event{Type: watch.Modified, Object: newTestPod("werf-run-bbbbb", corev1.PodFailed, false, "")})
+		Expect(err).To(MatchError(ContainSubstring("backoff limit 1 reached")))
+	})
+
+	It("should return the ready pod of the job", func() {
+		tracker := newTracker("werf-run", 0)
+
+		done, err := tracker.Condition(watch.Event{Type: watch.Added, Object: newTestPod("werf-run-ccccc", corev1.PodRunning, true, "")})
+		Expect(err).To(Succeed())
+		Expect(done).To(BeTrue())
+		Expect(tracker.ReadyPod).To(Equal("werf-run-ccccc"))
+	})
+})
+
+var _ = DescribeTable("describePodStatus",
+	func(pod *corev1.Pod, expected string) {
+		Expect(describePodStatus(pod)).To(Equal(expected))
+	},
+	Entry("no phase yet", &corev1.Pod{}, "Pending"),
+	Entry("waiting container", newTestPod("p", corev1.PodPending, false, "ImagePullBackOff"), "Pending (ImagePullBackOff)"),
+	Entry("running", newTestPod("p", corev1.PodRunning, true, ""), "Running"),
+	Entry("unschedulable", &corev1.Pod{Status: corev1.PodStatus{
+		Phase:      corev1.PodPending,
+		Conditions: []corev1.PodCondition{{Type: corev1.PodScheduled, Status: corev1.ConditionFalse, Reason: "Unschedulable"}},
+	}}, "Pending (Unschedulable)"),
+)
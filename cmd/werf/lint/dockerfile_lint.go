@@ -0,0 +1,107 @@
+package lint
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/werf/common-go/pkg/util"
+	"github.com/werf/logboek"
+	"github.com/werf/werf/v2/pkg/config"
+	"github.com/werf/werf/v2/pkg/dockerfile/linter"
+	"github.com/werf/werf/v2/pkg/giterminism_manager"
+	"github.com/werf/werf/v2/pkg/werf"
+)
+
+// lintDockerfiles lints the Dockerfiles of the images. The stapel images have no Dockerfile, so only their base images are checked.
+func lintDockerfiles(ctx context.Context, giterminismManager giterminism_manager.Interface, werfConfigPath string, werfConfig *config.WerfConfig, imagesToProcess config.ImagesToProcess) ([]*linter.Issue, error) {
+	var issues []*linter.Issue
+
+	// The Dockerfile can be shared by several images with different targets,
+	// so a stage is unused only if it is not used by any of these images.
+	targetsByDockerfile := map[string][]string{}
+	for _, img := range werfConfig.Images(false) {
+		if dockerfileImage, ok := img.(*config.ImageFromDockerfile); ok {
+			path := dockerfileImagePath(dockerfileImage)
+			targetsByDockerfile[path] = append(targetsByDockerfile[path], dockerfileImage.Target)
+		}
+	}
+
+	for _, imageName := range imagesToProcess.ImageNameList {
+		if stapelImage, ok := werfConfig.GetImage(imageName).(config.StapelImageInterface); ok {
+			if imageBaseConfig := stapelImage.ImageBaseConfig(); imageBaseConfig.FromExternal && imageBaseConfig.From != "" {
+				opts := linter.LintOptions{
+					Image: imageName,
+					File:  werfConfigPath,
+				}
+				if imageBaseConfig.Lint != nil {
+					opts.Suppress = imageBaseConfig.Lint.Suppress
+				}
+
+				issues = append(issues, linter.LintBaseImage(imageBaseConfig.From, opts)...)
+			}
+			continue
+		}
+
+		dockerfileImage, ok := werfConfig.GetImage(imageName).(*config.ImageFromDockerfile)
+		if !ok {
+			continue
+		}
+
+		path := dockerfileImagePath(dockerfileImage)
+
+		data, err := giterminismManager.FileReader().ReadDockerfile(ctx, path)
+		if err != nil {
+			return nil, fmt.Errorf("unable to read dockerfile %q of image %q: %w", path, imageName, err)
+		}
+
+		opts := linter.LintOptions{
+			Image:     imageName,
+			File:      path,
+			Targets:   util.UniqStrings(targetsByDockerfile[path]),
+			BuildArgs: util.MapStringInterfaceToMapStringString(dockerfileImage.Args),
+		}
+		if dockerfileImage.Lint != nil {
+			opts.Suppress = dockerfileImage.Lint.Suppress
+		}
+
+		imageIssues, err := linter.Lint(data, opts)
+		if err != nil {
+			return nil, fmt.Errorf("unable to lint dockerfile %q of image %q: %w", path, imageName, err)
+		}
+
+		issues = append(issues, imageIssues...)
+	}
+
+	return issues, nil
+}
+
+func dockerfileImagePath(dockerfileImage *config.ImageFromDockerfile) string {
+	return filepath.Join(dockerfileImage.Context, dockerfileImage.Dockerfile)
+}
+
+func printDockerfileLintIssues(ctx context.Context, issues []*linter.Issue) {
+	for _, issue := range issues {
+		logboek.Context(ctx).Warn().LogF("WARNING: image %q: %s\n", issue.Image, issue.String())
+	}
+}
+
+func writeDockerfileLintReport(issues []*linter.Issue, path string) error {
+	format, err := linter.GetReportFormatByPath(path)
+	if err != nil {
+		return err
+	}
+
+	var buf bytes.Buffer
+	if err := linter.WriteReport(&buf, issues, format, linter.WriteReportOptions{ToolName: "werf", ToolVersion: werf.Version}); err != nil {
+		return err
+	}
+
+	if err := os.WriteFile(path, buf.Bytes(), 0o644); err != nil {
+		return fmt.Errorf("unable to write dockerfile lint report %q: %w", path, err)
+	}
+
+	return nil
+}
@@ -33,7 +33,9 @@ import (
 )
 
 var cmdData struct {
-	Validate bool
+	Validate                 bool
+	SkipDockerfileLint       bool
+	DockerfileLintReportPath string
 }
 
 var commonCmdData common.CmdData
@@ -42,7 +44,7 @@ func NewCmd(ctx context.Context) *cobra.Command {
 	ctx = common.NewContextWithCmdData(ctx, &commonCmdData)
 	cmd := common.SetCommandContext(ctx, &cobra.Command{
 		Use:                   "lint [IMAGE_NAME...]",
-		Short:                 "Lint Helm chart",
+		Short:                 "Lint Helm chart and Dockerfile images",
 		Long:                  common.GetLongCommandDescription(GetLintDocs().Long),
 		DisableFlagsInUseLine: true,
 		Annotations: map[string]string{
@@ -98,6 +100,7 @@ func NewCmd(ctx context.Context) *cobra.Command {
 	common.SetupInsecureRegistry(&commonCmdData, cmd)
 	common.SetupSkipTlsVerifyRegistry(&commonCmdData, cmd)
 	common.SetupContainerRegistryMirror(&commonCmdData, cmd)
+	common.SetupRegistryAuthConfig(&commonCmdData, cmd)
 
 	common.SetupLogOptions(&commonCmdData, cmd)
 	common.SetupLogProjectDir(&commonCmdData, cmd)
@@ -149,6 +152,8 @@ func NewCmd(ctx context.Context) *cobra.Command {
 	common.SetupTSOptions(&commonCmdData, cmd)
 
 	cmd.Flags().BoolVarP(&cmdData.Validate, "validate", "", util.GetBoolEnvironmentDefaultFalse("WERF_VALIDATE"), "Validate your manifests against the Kubernetes cluster you are currently pointing at (default $WERF_VALIDATE)")
+	cmd.Flags().BoolVarP(&cmdData.SkipDockerfileLint, "skip-dockerfile-lint", "", util.GetBoolEnvironmentDefaultFalse("WERF_SKIP_DOCKERFILE_LINT"), "Skip linting of the Dockerfile images (default $WERF_SKIP_DOCKERFILE_LINT)")
+	cmd.Flags().StringVarP(&cmdData.DockerfileLintReportPath, "dockerfile-lint-report-path", "", os.Getenv("WERF_DOCKERFILE_LINT_REPORT_PATH"), "Save the Dockerfile lint issues to the specified file (default $WERF_DOCKERFILE_LINT_REPORT_PATH). Extension must be either .json for JSON format or .sarif for SARIF format")
 
 	return cmd
 }
@@ -195,6 +200,25 @@ func runLint(ctx context.Context, imageNameListFromArgs []string) error {
 		return err
 	}
 
+	if !cmdData.SkipDockerfileLint && !imagesToProcess.WithoutImages {
+		if err := logboek.Context(ctx).LogProcess("Linting Dockerfiles").DoError(func() error {
+			issues, err := lintDockerfiles(ctx, giterminismManager, werfConfigPath, werfConfig, imagesToProcess)
+			if err != nil {
+				return err
+			}
+
+			printDockerfileLintIssues(ctx, issues)
+
+			if cmdData.DockerfileLintReportPath != "" {
+				return writeDockerfileLintReport(issues, cmdData.DockerfileLintReportPath)
+			}
+
+			return nil
+		}); err != nil {
+			return err
+		}
+	}
+
 	projectName := werfConfig.Meta.Project
 
 	projectTmpDir, err := tmp_manager.CreateProjectDir(ctx)
@@ -5,10 +5,17 @@ import "github.com/werf/werf/v2/cmd/werf/docs/structs"
 func GetLintDocs() structs.DocsStruct {
 	var docs structs.DocsStruct
 
-	docs.Long = `Lint Helm chart. This command will calculate digests and build (if needed) all images defined in the werf.yaml.`
+	docs.Long = `Lint Helm chart and Dockerfile images. This command will calculate digests and build (if needed) all images defined in the werf.yaml.
 
-	docs.LongMD = "Lint Helm chart. This command will calculate digests and build " +
-		"(if needed) all images defined in the `werf.yaml`."
+Dockerfile images are checked for apt-get without cleanup, ADD of remote URLs, COPY without --chown after non-root USER, latest tags in FROM, secrets passed as build args and unused stages. The from of stapel images is checked for latest tags only. Issues are printed as warnings and can be saved with --dockerfile-lint-report-path. Rules can be suppressed per image with the lint.suppress directive in the werf.yaml.`
+
+	docs.LongMD = "Lint Helm chart and Dockerfile images. This command will calculate digests and build " +
+		"(if needed) all images defined in the `werf.yaml`.\n\n" +
+		"Dockerfile images are checked for `apt-get` without cleanup, `ADD` of remote URLs, `COPY` without `--chown` " +
+		"after non-root `USER`, `latest` tags in `FROM`, secrets passed as build args and unused stages. " +
+		"The `from` of stapel images is checked for `latest` tags only. " +
+		"Issues are printed as warnings and can be saved with `--dockerfile-lint-report-path`. " +
+		"Rules can be suppressed per image with the `lint.suppress` directive in the `werf.yaml`."
 
 	return docs
 }
@@ -17,6 +17,7 @@ import (
 	"github.com/werf/werf/v2/pkg/background"
 	"github.com/werf/werf/v2/pkg/logging"
 	"github.com/werf/werf/v2/pkg/process_exterminator"
+	werfExec "github.com/werf/werf/v2/pkg/werf/exec"
 )
 
 func main() {
@@ -84,6 +85,10 @@ func main() {
 			common.ShutdownTelemetry(ctx, 3)
 			graceful.Terminate(ctx, action.ErrReleaseInstallPlanned, 3)
 			return
+		} else if code, ok := werfExec.IsExitCodeError(err); ok {
+			common.ShutdownTelemetry(ctx, code)
+			graceful.Terminate(ctx, err, code)
+			return
 		} else {
 			common.ShutdownTelemetry(ctx, 1)
 			graceful.Terminate(ctx, err, 1)
@@ -0,0 +1,234 @@
+package sync
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/samber/lo"
+	"github.com/spf13/cobra"
+
+	"github.com/werf/common-go/pkg/util"
+	"github.com/werf/logboek"
+	"github.com/werf/logboek/pkg/level"
+	"github.com/werf/werf/v2/cmd/werf/common"
+	"github.com/werf/werf/v2/pkg/storage"
+	"github.com/werf/werf/v2/pkg/tmp_manager"
+	"github.com/werf/werf/v2/pkg/true_git"
+)
+
+var cmdData struct {
+	RemoveOrphans bool
+	Migrate       []string
+}
+
+var commonCmdData common.CmdData
+
+func NewCmd(ctx context.Context) *cobra.Command {
+	ctx = common.NewContextWithCmdData(ctx, &commonCmdData)
+	cmd := common.SetCommandContext(ctx, &cobra.Command{
+		Use:                   "sync",
+		DisableFlagsInUseLine: true,
+		Short:                 "Reconcile the list of managed images with the images declared in werf.yaml.",
+		Long: common.GetLongCommandDescription(`Reconcile the list of managed images with the images declared in werf.yaml.
+
+The images declared in werf.yaml are added to the list of managed images. The managed images, which are not declared in werf.yaml anymore, and the image metadata without the managed image are listed as orphans with the number of the image metadata records and stages attached to them. The orphans are preserved by default and removed along with their image metadata with the --remove-orphans option.
+
+The image metadata of the renamed image can be moved to the new name with the --migrate OLD=NEW option, so the cleanup keeps the stages built for the old name according to the git history.`),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+
+			if err := common.ProcessLogOptions(&commonCmdData); err != nil {
+				common.PrintHelp(cmd)
+				return err
+			}
+
+			return run(ctx)
+		},
+	})
+
+	common.SetupDir(&commonCmdData, cmd)
+	common.SetupGitWorkTree(&commonCmdData, cmd)
+	common.SetupConfigTemplatesDir(&commonCmdData, cmd)
+	common.SetupConfigRenderPath(&commonCmdData, cmd)
+	common.SetupConfigPath(&commonCmdData, cmd)
+	common.SetupGiterminismConfigPath(&commonCmdData, cmd)
+	common.SetupEnvironment(&commonCmdData, cmd)
+
+	common.SetupGiterminismOptions(&commonCmdData, cmd)
+
+	common.SetupTmpDir(&commonCmdData, cmd, common.SetupTmpDirOptions{})
+	common.SetupHomeDir(&commonCmdData, cmd, common.SetupHomeDirOptions{})
+	common.SetupSSHKey(&commonCmdData, cmd)
+
+	common.SetupSecondaryStagesStorageOptions(&commonCmdData, cmd)
+	common.SetupCacheStagesStorageOptions(&commonCmdData, cmd)
+	common.SetupRepoOptions(&commonCmdData, cmd, common.RepoDataOptions{})
+	common.SetupFinalRepo(&commonCmdData, cmd)
+
+	common.SetupDockerConfig(&commonCmdData, cmd, "Command needs granted permissions to read and write images to the specified repo")
+	common.SetupInsecureRegistry(&commonCmdData, cmd)
+	common.StubSetupInsecureHelmDependencies(&commonCmdData, cmd)
+	common.SetupSkipTlsVerifyRegistry(&commonCmdData, cmd)
+	common.SetupContainerRegistryMirror(&commonCmdData, cmd)
+	common.SetupRegistryAuthConfig(&commonCmdData, cmd)
+
+	common.SetupLogOptions(&commonCmdData, cmd)
+	common.SetupLogProjectDir(&commonCmdData, cmd)
+
+	common.SetupSynchronization(&commonCmdData, cmd)
+	common.SetupDryRun(&commonCmdData, cmd)
+
+	commonCmdData.SetupPlatform(cmd)
+	commonCmdData.SetupDebugTemplates(cmd)
+	commonCmdData.SetupAllowIncludesUpdate(cmd)
+
+	lo.Must0(common.SetupMinimalKubeConnectionFlags(&commonCmdData, cmd))
+
+	cmd.Flags().BoolVarP(&cmdData.RemoveOrphans, "remove-orphans", "", util.GetBoolEnvironmentDefaultFalse("WERF_REMOVE_ORPHANS"), "Remove the orphaned managed images and their image metadata (default $WERF_REMOVE_ORPHANS)")
+	cmd.Flags().StringArrayVarP(&cmdData.Migrate, "migrate", "", []string{}, `Move the managed image record and the image metadata from the old image name to the new one declared in werf.yaml in the OLD=NEW format (can specify multiple).
+Also, can be specified with $WERF_MIGRATE_* (e.g. $WERF_MIGRATE_1=backend=api, $WERF_MIGRATE_2=...)`)
+
+	return cmd
+}
+
+func run(ctx context.Context) error {
+	migrations, err := getMigrations(append(util.PredefinedValuesByEnvNamePrefix("WERF_MIGRATE_"), cmdData.Migrate...))
+	if err != nil {
+		return err
+	}
+
+	commonManager, ctx, err := common.InitCommonComponents(ctx, common.InitCommonComponentsOptions{
+		Cmd: &commonCmdData,
+		InitTrueGitWithOptions: &common.InitTrueGitOptions{
+			Options: true_git.Options{LiveGitOutput: *commonCmdData.LogDebug},
+		},
+		InitDockerRegistry:           true,
+		InitProcessContainerBackend:  true,
+		InitWerf:                     true,
+		InitGitDataManager:           true,
+		InitManifestCache:            true,
+		InitLRUImagesCache:           true,
+		SetupOndemandKubeInitializer: true,
+	})
+	if err != nil {
+		return fmt.Errorf("component init error: %w", err)
+	}
+
+	defer func() {
+		if err := tmp_manager.DelegateCleanup(ctx); err != nil {
+			logboek.Context(ctx).Warn().LogF("Temporary files cleanup preparation failed: %s\n", err)
+		}
+	}()
+
+	containerBackend := commonManager.ContainerBackend()
+
+	if logboek.Context(ctx).IsAcceptedLevel(level.Default) {
+		logboek.Context(ctx).SetAcceptedLevel(level.Error)
+	}
+
+	_, err = tmp_manager.CreateProjectDir(ctx)
+	if err != nil {
+		return fmt.Errorf("getting project tmp dir failed: %w", err)
+	}
+
+	giterminismManager, err := common.GetGiterminismManager(ctx, &commonCmdData)
+	if err != nil {
+		return err
+	}
+
+	_, werfConfig, err := common.GetOptionalWerfConfig(ctx, &commonCmdData, giterminismManager, common.GetWerfConfigOptions(&commonCmdData, false))
+	if err != nil {
+		return fmt.Errorf("unable to load werf config: %w", err)
+	}
+
+	var projectName string
+	if werfConfig != nil {
+		projectName = werfConfig.Meta.Project
+	} else {
+		return fmt.Errorf("run command in the project directory with werf.yaml")
+	}
+
+	storageManager, err := common.NewStorageManager(ctx, &common.NewStorageManagerConfig{
+		ProjectName:                    projectName,
+		ContainerBackend:               containerBackend,
+		CmdData:                        &commonCmdData,
+		CleanupDisabled:                werfConfig.Meta.Cleanup.DisableCleanup,
+		GitHistoryBasedCleanupDisabled: werfConfig.Meta.Cleanup.DisableGitHistoryBasedPolicy,
+	})
+	if err != nil {
+		return fmt.Errorf("unable to init storage manager: %w", err)
+	}
+
+	plan, err := storage.PlanManagedImagesSync(ctx, storageManager.StagesStorage, projectName, storage.PlanManagedImagesSyncOptions{
+		ImageNames: werfConfig.GetImageNameList(false),
+		Migrations: migrations,
+	})
+	if err != nil {
+		return fmt.Errorf("unable to plan managed images sync for project %q: %w", projectName, err)
+	}
+
+	printPlan(plan)
+
+	if *commonCmdData.DryRun {
+		return nil
+	}
+
+	if err := storage.ApplyManagedImagesSync(ctx, storageManager.StagesStorage, projectName, plan, storage.ApplyManagedImagesSyncOptions{
+		RemoveOrphans: cmdData.RemoveOrphans,
+	}); err != nil {
+		return fmt.Errorf("unable to sync managed images for project %q: %w", projectName, err)
+	}
+
+	return nil
+}
+
+func getMigrations(specs []string) (map[string]string, error) {
+	migrations := map[string]string{}
+	for _, spec := range specs {
+		from, to, found := strings.Cut(spec, "=")
+		if !found {
+			return nil, fmt.Errorf("bad --migrate value %q: OLD=NEW format expected", spec)
+		}
+
+		from, to = common.GetManagedImageName(from), common.GetManagedImageName(to)
+		if prevTo, ok := migrations[from]; ok && prevTo != to {
+			return nil, fmt.Errorf("bad --migrate value %q: image %q is already migrated to %q", spec, from, prevTo)
+		}
+
+		migrations[from] = to
+	}
+
+	return migrations, nil
+}
+
+func printPlan(plan *storage.ManagedImagesSyncPlan) {
+	for _, imageName := range plan.Add {
+		fmt.Printf("add: %s\n", imageNameForOutput(imageName))
+	}
+
+	for _, migration := range plan.Migrations {
+		fmt.Printf("migrate: %s -> %s (metadata records: %d)\n", imageNameForOutput(migration.From), imageNameForOutput(migration.To), migration.MetadataRecordsNumber())
+	}
+
+	for _, orphan := range plan.Orphans {
+		name := imageNameForOutput(orphan.ImageName)
+		if orphan.IsMetadataID {
+			name = "metadata ID " + orphan.ImageName
+		}
+
+		action := "orphan"
+		if cmdData.RemoveOrphans {
+			action = "remove orphan"
+		}
+
+		fmt.Printf("%s: %s (managed: %t, metadata records: %d, stages: %d)\n", action, name, orphan.Managed, orphan.MetadataRecordsNumber(), orphan.StagesNumber())
+	}
+}
+
+func imageNameForOutput(imageName string) string {
+	if imageName == "" {
+		return "~"
+	}
+	return imageName
+}
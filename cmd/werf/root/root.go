@@ -47,6 +47,7 @@ import (
 	managed_images_add "github.com/werf/werf/v2/cmd/werf/managed_images/add"
 	managed_images_ls "github.com/werf/werf/v2/cmd/werf/managed_images/ls"
 	managed_images_rm "github.com/werf/werf/v2/cmd/werf/managed_images/rm"
+	managed_images_sync "github.com/werf/werf/v2/cmd/werf/managed_images/sync"
 	"github.com/werf/werf/v2/cmd/werf/plan"
 	"github.com/werf/werf/v2/cmd/werf/purge"
 	"github.com/werf/werf/v2/cmd/werf/render"
@@ -210,6 +211,7 @@ func managedImagesCmd(ctx context.Context) *cobra.Command {
 		managed_images_add.NewCmd(ctx),
 		managed_images_ls.NewCmd(ctx),
 		managed_images_rm.NewCmd(ctx),
+		managed_images_sync.NewCmd(ctx),
 	)
 
 	return cmd
@@ -298,6 +300,8 @@ func SetupTelemetryInit(rootCmd *cobra.Command) {
 		commandsQueue = append(commandsQueue, cmd.Commands()...)
 
 		if cmd.Runnable() {
+			common.SetupTelemetryOtlpEndpoint(cmd)
+
 			oldRunE := cmd.RunE
 			cmd.RunE = nil
 
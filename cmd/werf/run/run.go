@@ -2,8 +2,10 @@ package run
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
+	"os/exec"
 	"strings"
 	"time"
 
@@ -14,6 +16,7 @@ import (
 	"github.com/spf13/cobra"
 
 	"github.com/werf/common-go/pkg/graceful"
+	"github.com/werf/common-go/pkg/util"
 	"github.com/werf/logboek"
 	"github.com/werf/werf/v2/cmd/werf/common"
 	"github.com/werf/werf/v2/pkg/build"
@@ -24,6 +27,7 @@ import (
 	"github.com/werf/werf/v2/pkg/giterminism_manager"
 	"github.com/werf/werf/v2/pkg/tmp_manager"
 	"github.com/werf/werf/v2/pkg/true_git"
+	werfExec "github.com/werf/werf/v2/pkg/werf/exec"
 	"github.com/werf/werf/v2/pkg/werf/global_warnings"
 )
 
@@ -31,10 +35,14 @@ type cmdDataType struct {
 	Shell            bool
 	Bash             bool
 	RawDockerOptions string
+	Publish          []string
+	Volumes          []string
+	ContainerEnvs    []string
 
 	DockerOptions []string
 	DockerCommand []string
 	ImageName     string
+	RunOpts       container_backend.RunOpts
 }
 
 var (
@@ -55,23 +63,20 @@ func NewCmd(ctx context.Context) *cobra.Command {
   # Run image with predefined docker run options and command for debug
   $ werf run --shell
 
+  # Run image with published port, mounted directory and environment variable (Docker and Buildah modes)
+  $ werf run --publish 8080:8080 --volume $(pwd)/data:/data --container-env DEBUG=1 application
+
   # Run image with specified docker run options and command
   $ werf run --docker-options="-d -p 5000:5000 --restart=always --name registry" -- /app/run.sh
 
   # Print a resulting docker run command
   $ werf run --shell --dry-run
-  docker run -ti --rm image-stage-test:1ffe83860127e68e893b6aece5b0b7619f903f8492a285c6410371c87018c6a0 /bin/sh`,
+  docker run -i -t --rm --entrypoint /bin/sh image-stage-test:1ffe83860127e68e893b6aece5b0b7619f903f8492a285c6410371c87018c6a0`,
 		Annotations: map[string]string{
 			common.DisableOptionsInUseLineAnno: "1",
 			common.DocsLongMD:                  GetRunDocs().LongMD,
 		},
 		RunE: func(cmd *cobra.Command, args []string) error {
-			if mode, _, err := common.GetBuildahMode(); err != nil {
-				return err
-			} else if *mode != buildah.ModeDisabled {
-				return fmt.Errorf(`command "werf run" is not implemented for Buildah mode`)
-			}
-
 			ctx := cmd.Context()
 
 			defer global_warnings.PrintGlobalWarnings(ctx)
@@ -90,28 +95,32 @@ func NewCmd(ctx context.Context) *cobra.Command {
 				cmdData.DockerOptions = strings.Fields(cmdData.RawDockerOptions)
 			}
 
+			mode, _, err := common.GetBuildahMode()
+			if err != nil {
+				return err
+			}
+
+			if *mode != buildah.ModeDisabled {
+				if len(cmdData.DockerOptions) > 0 {
+					return fmt.Errorf("--docker-options is not supported for Buildah mode: use --publish, --volume and --container-env options instead")
+				}
+
+				if *commonCmdData.Follow {
+					return fmt.Errorf("follow mode is not supported for Buildah mode")
+				}
+			}
+
 			if cmdData.Shell && cmdData.Bash {
 				return fmt.Errorf("cannot use --shell and --bash options at the same time")
 			}
 
-			if cmdData.Shell || cmdData.Bash {
-				if len(cmdData.DockerOptions) == 0 && len(cmdData.DockerCommand) == 0 {
-					cmdData.DockerOptions = []string{"-ti", "--rm"}
-					if cmdData.Shell {
-						cmdData.DockerOptions = append(cmdData.DockerOptions, "--entrypoint=/bin/sh")
-					}
-
-					if cmdData.Bash {
-						cmdData.DockerOptions = append(cmdData.DockerOptions, "--entrypoint=/bin/bash")
-					}
-				} else {
-					common.PrintHelp(cmd)
-					return fmt.Errorf("shell option cannot be used with other docker run arguments")
-				}
-			} else if len(cmdData.DockerOptions) == 0 {
-				cmdData.DockerOptions = append(cmdData.DockerOptions, "--rm")
+			if (cmdData.Shell || cmdData.Bash) && (len(cmdData.DockerOptions) > 0 || len(cmdData.DockerCommand) > 0) {
+				common.PrintHelp(cmd)
+				return fmt.Errorf("shell option cannot be used with other docker run arguments")
 			}
 
+			cmdData.RunOpts = getRunOpts()
+
 			return runMain(ctx)
 		},
 	})
@@ -147,6 +156,7 @@ func NewCmd(ctx context.Context) *cobra.Command {
 	common.StubSetupInsecureHelmDependencies(&commonCmdData, cmd)
 	common.SetupSkipTlsVerifyRegistry(&commonCmdData, cmd)
 	common.SetupContainerRegistryMirror(&commonCmdData, cmd)
+	common.SetupRegistryAuthConfig(&commonCmdData, cmd)
 
 	common.SetupLogOptions(&commonCmdData, cmd)
 	common.SetupLogProjectDir(&commonCmdData, cmd)
@@ -162,7 +172,14 @@ func NewCmd(ctx context.Context) *cobra.Command {
 
 	cmd.Flags().BoolVarP(&cmdData.Shell, "shell", "", false, "Use predefined docker options and command for debug")
 	cmd.Flags().BoolVarP(&cmdData.Bash, "bash", "", false, "Use predefined docker options and command for debug")
-	cmd.Flags().StringVarP(&cmdData.RawDockerOptions, "docker-options", "", os.Getenv("WERF_DOCKER_OPTIONS"), "Define docker run options (default $WERF_DOCKER_OPTIONS)")
+	cmd.Flags().StringVarP(&cmdData.RawDockerOptions, "docker-options", "", os.Getenv("WERF_DOCKER_OPTIONS"), "Define docker run options (default $WERF_DOCKER_OPTIONS). Not supported for Buildah mode")
+	cmd.Flags().StringArrayVarP(&cmdData.Publish, "publish", "", []string{}, `Publish the container port to the host in the format [HOST_IP:][HOST_PORT:]CONTAINER_PORT[/PROTOCOL] (can specify multiple).
+Buildah mode uses the host network, so HOST_PORT must be omitted or equal to CONTAINER_PORT.
+Also, can be specified with $WERF_PUBLISH_* (e.g. $WERF_PUBLISH_1=8080:8080, $WERF_PUBLISH_2=...)`)
+	cmd.Flags().StringArrayVarP(&cmdData.Volumes, "volume", "", []string{}, `Mount the host directory into the container in the format SOURCE:DESTINATION[:OPTIONS] (can specify multiple).
+Also, can be specified with $WERF_VOLUME_* (e.g. $WERF_VOLUME_1=/data:/data:ro, $WERF_VOLUME_2=...)`)
+	cmd.Flags().StringArrayVarP(&cmdData.ContainerEnvs, "container-env", "", []string{}, `Set the environment variable in the container in the format KEY=VALUE (can specify multiple).
+Also, can be specified with $WERF_CONTAINER_ENV_* (e.g. $WERF_CONTAINER_ENV_1=DEBUG=1, $WERF_CONTAINER_ENV_2=...)`)
 
 	commonCmdData.SetupSkipImageSpecStage(cmd)
 	commonCmdData.SetupDebugTemplates(cmd)
@@ -204,6 +221,33 @@ func processArgs(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+// getRunOpts returns the container options that are common for the Docker and Buildah modes.
+func getRunOpts() container_backend.RunOpts {
+	opts := container_backend.RunOpts{
+		Command:       cmdData.DockerCommand,
+		Envs:          append(util.PredefinedValuesByEnvNamePrefix("WERF_CONTAINER_ENV_"), cmdData.ContainerEnvs...),
+		Volumes:       append(util.PredefinedValuesByEnvNamePrefix("WERF_VOLUME_"), cmdData.Volumes...),
+		Ports:         append(util.PredefinedValuesByEnvNamePrefix("WERF_PUBLISH_"), cmdData.Publish...),
+		DockerOptions: cmdData.DockerOptions,
+		// The container is removed by default unless the docker run options are specified.
+		Rm: len(cmdData.DockerOptions) == 0,
+	}
+
+	switch {
+	case cmdData.Shell:
+		opts.Entrypoint = []string{"/bin/sh"}
+	case cmdData.Bash:
+		opts.Entrypoint = []string{"/bin/bash"}
+	}
+
+	if cmdData.Shell || cmdData.Bash {
+		opts.Interactive = true
+		opts.TTY = true
+	}
+
+	return opts
+}
+
 func checkDetachDockerOption() error {
 	for _, value := range cmdData.DockerOptions {
 		if value == "-d" || value == "--detach" {
@@ -318,13 +362,28 @@ func runMain(ctx context.Context) error {
 				return err
 			}
 
-			return err
+			return withContainerExitCode(err)
 		}
 
 		return nil
 	}
 }
 
+// withContainerExitCode makes the exit code of the container command the exit code of werf. The buildah backend
+// returns the exit code itself, the docker CLI exits with the exit code of the command.
+func withContainerExitCode(err error) error {
+	if _, ok := werfExec.IsExitCodeError(err); ok {
+		return err
+	}
+
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		return werfExec.NewExitCodeError(exitErr.ExitCode(), err)
+	}
+
+	return err
+}
+
 func run(ctx context.Context, containerBackend container_backend.ContainerBackend, giterminismManager giterminism_manager.Interface) error {
 	_, werfConfig, err := common.GetRequiredWerfConfig(ctx, &commonCmdData, giterminismManager, common.GetWerfConfigOptions(&commonCmdData, false))
 	if err != nil {
@@ -406,19 +465,13 @@ func run(ctx context.Context, containerBackend container_backend.ContainerBacken
 		return err
 	}
 
-	var dockerRunArgs []string
-	dockerRunArgs = append(dockerRunArgs, cmdData.DockerOptions...)
-	dockerRunArgs = append(dockerRunArgs, dockerImageName)
-	dockerRunArgs = append(dockerRunArgs, cmdData.DockerCommand...)
-
 	if *commonCmdData.DryRun {
-		fmt.Printf("docker run %s\n", strings.Join(dockerRunArgs, " "))
+		// Buildah mode accepts the same subset of options, so the equivalent docker run command is printed.
+		fmt.Printf("docker run %s\n", strings.Join(container_backend.DockerRunArgs(dockerImageName, cmdData.RunOpts), " "))
 		return nil
-	} else {
-		return logboek.Streams().DoErrorWithoutProxyStreamDataFormatting(func() error {
-			return docker.CliRun_ProvidedOutput(ctx, os.Stdout, os.Stderr, dockerRunArgs...)
-		})
 	}
+
+	return containerBackend.Run(ctx, dockerImageName, cmdData.RunOpts)
 }
 
 func safeDockerCliRmFunc(ctx context.Context, containerName string) error {
@@ -0,0 +1,38 @@
+package run
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	werfExec "github.com/werf/werf/v2/pkg/werf/exec"
+)
+
+var _ = Describe("withContainerExitCode", func() {
+	It("keeps the exit code returned by the buildah backend", func() {
+		err := fmt.Errorf("unable to run: %w", werfExec.NewExitCodeError(3, errors.New("RunCommand failed")))
+
+		code, ok := werfExec.IsExitCodeError(withContainerExitCode(err))
+		Expect(ok).To(BeTrue())
+		Expect(code).To(Equal(3))
+	})
+
+	It("takes the exit code of the docker CLI", func() {
+		exitErr := exec.Command("sh", "-c", "exit 4").Run()
+		err := fmt.Errorf("docker run failed: %w", exitErr)
+
+		code, ok := werfExec.IsExitCodeError(withContainerExitCode(err))
+		Expect(ok).To(BeTrue())
+		Expect(code).To(Equal(4))
+	})
+
+	It("keeps the error without the exit code", func() {
+		err := errors.New("image not found")
+
+		_, ok := werfExec.IsExitCodeError(withContainerExitCode(err))
+		Expect(ok).To(BeFalse())
+	})
+})
@@ -0,0 +1,13 @@
+package run
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestCmdRun(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Cmd Run Suite")
+}
@@ -17,6 +17,7 @@ import (
 	"github.com/werf/logboek/pkg/types"
 	"github.com/werf/werf/v2/pkg/build/cleanup"
 	"github.com/werf/werf/v2/pkg/build/image"
+	"github.com/werf/werf/v2/pkg/build/secrets"
 	"github.com/werf/werf/v2/pkg/build/stage"
 	"github.com/werf/werf/v2/pkg/build/stage/instruction"
 	"github.com/werf/werf/v2/pkg/container_backend"
@@ -49,6 +50,7 @@ type BuildOptions struct {
 	SkipImageMetadataPublication bool
 	SkipAddManagedImagesRecords  bool
 	CustomTagFuncList            []imagePkg.CustomTagFunc
+	VulnerabilityScanOptions     VulnerabilityScanOptions
 }
 
 type IntrospectOptions struct {
@@ -72,9 +74,10 @@ func (opts *IntrospectOptions) ImageStageShouldBeIntrospected(imageName, stageNa
 
 func NewBuildPhase(c *Conveyor, opts BuildPhaseOptions) *BuildPhase {
 	return &BuildPhase{
-		BasePhase:         BasePhase{c},
-		BuildPhaseOptions: opts,
-		ImagesReport:      NewImagesReport(),
+		BasePhase:                BasePhase{c},
+		BuildPhaseOptions:        opts,
+		ImagesReport:             NewImagesReport(),
+		vulnerabilityScanResults: newVulnerabilityScanResults(),
 	}
 }
 
@@ -85,7 +88,8 @@ type BuildPhase struct {
 	StagesIterator *StagesIterator
 	ImagesReport   *ImagesReport
 
-	buildContextArchive container_backend.BuildContextArchiver
+	buildContextArchive      container_backend.BuildContextArchiver
+	vulnerabilityScanResults *vulnerabilityScanResults
 }
 
 func GenerateImageEnv(werfImageName, imageName string) string {
@@ -129,7 +133,7 @@ func (phase *BuildPhase) BeforeImages(ctx context.Context) error {
 
 	telemetry.GetTelemetryWerfIO().BuildStarted(ctx, len(imagesPairs), backend, werfInContainer)
 
-	return nil
+	return phase.checkFinalImagesConfigPolicy()
 }
 
 func (phase *BuildPhase) AfterImages(ctx context.Context) error {
@@ -142,6 +146,10 @@ func (phase *BuildPhase) AfterImages(ctx context.Context) error {
 		commonTargetPlatforms = []string{phase.Conveyor.ContainerBackend.GetDefaultPlatform()}
 	}
 
+	if err := phase.checkFinalImagesPolicy(ctx); err != nil {
+		return err
+	}
+
 	imagesPairs := phase.Conveyor.imagesTree.GetImagesByName(false)
 
 	if err := parallel.DoTasks(ctx, len(imagesPairs), parallel.DoTasksOptions{
@@ -168,6 +176,10 @@ func (phase *BuildPhase) AfterImages(ctx context.Context) error {
 				logboek.Context(ctx).LogOptionalLn()
 			}
 
+			if err := phase.scanFinalImage(ctx, img); err != nil {
+				return err
+			}
+
 			// TODO: Separate LocalStagesStorage and RepoStagesStorage interfaces, local should not include metadata publishing methods at all
 			if _, isLocal := phase.Conveyor.StorageManager.GetStagesStorage().(*storage.LocalStagesStorage); !isLocal {
 				if err := phase.publishImageMetadata(ctx, name, img); err != nil {
@@ -202,6 +214,12 @@ func (phase *BuildPhase) AfterImages(ctx context.Context) error {
 				}
 			}
 
+			for _, pImg := range img.Images {
+				if err := phase.scanFinalImage(ctx, pImg); err != nil {
+					return err
+				}
+			}
+
 			if _, isLocal := phase.Conveyor.StorageManager.GetStagesStorage().(*storage.LocalStagesStorage); !isLocal {
 				if err := phase.publishMultiplatformImageCustomTags(ctx, name, img); err != nil {
 					return fmt.Errorf("unable to publish image %q multiplatform custom tags: %w", name, err)
@@ -216,7 +234,11 @@ func (phase *BuildPhase) AfterImages(ctx context.Context) error {
 
 	telemetry.GetTelemetryWerfIO().BuildFinished(ctx, true)
 
-	return phase.createReport(ctx, imagesPairs)
+	if err := phase.createReport(ctx, imagesPairs); err != nil {
+		return err
+	}
+
+	return phase.checkVulnerabilityScanThreshold(imagesPairs)
 }
 
 func (phase *BuildPhase) targetPlatforms(ctx context.Context, forcedTargetPlatforms, commonTargetPlatforms []string, name string, images []*image.Image) ([]string, error) {
@@ -437,6 +459,10 @@ func (phase *BuildPhase) BeforeImageStages(ctx context.Context, img *image.Image
 		return nil, fmt.Errorf("unable to setup base image: %w", err)
 	}
 
+	if err := phase.checkBaseImagesPolicy(img); err != nil {
+		return nil, err
+	}
+
 	if img.UsesBuildContext() {
 		phase.buildContextArchive = image.NewBuildContextArchive(phase.Conveyor.giterminismManager, img.TmpDir)
 		if err := phase.buildContextArchive.Create(ctx, container_backend.BuildContextArchiveCreateOptions{
@@ -678,7 +704,7 @@ func (phase *BuildPhase) onImageStage(ctx context.Context, img *image.Image, stg
 		logboek.Context(ctx).LogOptionalLn()
 
 		if phase.IntrospectOptions.ImageStageShouldBeIntrospected(img.GetName(), string(stg.Name())) {
-			if err := introspectStage(ctx, stg); err != nil {
+			if err := phase.introspectStage(ctx, img, stg); err != nil {
 				return err
 			}
 		}
@@ -1074,7 +1100,7 @@ func (phase *BuildPhase) buildStage(ctx context.Context, img *image.Image, stg s
 	}
 
 	if phase.IntrospectOptions.ImageStageShouldBeIntrospected(img.GetName(), string(stg.Name())) {
-		if err := introspectStage(ctx, stg); err != nil {
+		if err := phase.introspectStage(ctx, img, stg); err != nil {
 			return err
 		}
 	}
@@ -1229,14 +1255,24 @@ func (phase *BuildPhase) atomicBuildStageImage(ctx context.Context, img *image.I
 	return nil
 }
 
-func introspectStage(ctx context.Context, s stage.Interface) error {
+func (phase *BuildPhase) introspectStage(ctx context.Context, img *image.Image, s stage.Interface) error {
 	return logboek.Context(ctx).Info().LogProcess("Introspecting stage %s", s.Name()).
 		Options(func(options types.LogProcessOptionsInterface) {
 			options.Style(style.Highlight())
 		}).
 		DoError(func() error {
 			if err := logboek.Context(ctx).Streams().DoErrorWithoutProxyStreamDataFormatting(func() error {
-				return s.GetStageImage().Image.Introspect(ctx) // FIXME(stapel-to-buildah): use container backend operation
+				if !img.IsDockerfileImage && phase.Conveyor.UseLegacyStapelBuilder(phase.Conveyor.ContainerBackend) {
+					return s.GetStageImage().Image.Introspect(ctx)
+				}
+
+				opts, cleanupFunc, err := getIntrospectOpts(img)
+				if err != nil {
+					return err
+				}
+				defer cleanupFunc()
+
+				return phase.Conveyor.ContainerBackend.Introspect(ctx, stageImageRef(s), opts)
 			}); err != nil {
 				return fmt.Errorf("introspect error failed: %w", err)
 			}
@@ -1245,6 +1281,54 @@ func introspectStage(ctx context.Context, s stage.Interface) error {
 		})
 }
 
+// getIntrospectOpts returns the introspection options with the same secrets as the build of the image has. The secrets
+// of the stapel image are written into the tmp dir, which is removed by the returned cleanup func.
+func getIntrospectOpts(img *image.Image) (container_backend.IntrospectOpts, func(), error) {
+	opts := container_backend.IntrospectOpts{
+		CommonOpts: container_backend.CommonOpts{TargetPlatform: img.TargetPlatform},
+	}
+
+	switch {
+	case img.IsDockerfileImage:
+		for _, s := range img.DockerfileImageConfig.Secrets {
+			secret, err := secrets.GetSecretStringArg(s)
+			if err != nil {
+				return opts, nil, fmt.Errorf("unable to get build secrets: %w", err)
+			}
+			opts.Secrets = append(opts.Secrets, secret)
+		}
+		opts.SSH = img.DockerfileImageConfig.SSH
+	case img.StapelImageConfig != nil && len(img.StapelImageConfig.ImageBaseConfig().Secrets) > 0:
+		secretsDir, err := os.MkdirTemp(img.TmpDir, "introspect-secrets-")
+		if err != nil {
+			return opts, nil, fmt.Errorf("unable to create tmp dir: %w", err)
+		}
+		cleanupFunc := func() { _ = os.RemoveAll(secretsDir) }
+
+		for _, s := range img.StapelImageConfig.ImageBaseConfig().Secrets {
+			volume, err := secrets.GetMountPath(s, secretsDir)
+			if err != nil {
+				cleanupFunc()
+				return opts, nil, fmt.Errorf("unable to get build secrets: %w", err)
+			}
+			opts.BuildVolumes = append(opts.BuildVolumes, volume)
+		}
+
+		return opts, cleanupFunc, nil
+	}
+
+	return opts, func() {}, nil
+}
+
+// stageImageRef returns the local built image id or the stage image name, if the stage has been found in the storage.
+func stageImageRef(s stage.Interface) string {
+	if builtID := s.GetStageImage().Image.BuiltID(); builtID != "" {
+		return builtID
+	}
+
+	return s.GetStageImage().Image.Name()
+}
+
 type calculateDigestOptions struct {
 	TargetPlatform string
 	BaseImage      string // TODO(staged-dockerfile): legacy compatibility field
@@ -15,12 +15,14 @@ import (
 
 	"github.com/werf/common-go/pkg/util"
 	"github.com/werf/logboek"
+	"github.com/werf/logboek/pkg/level"
 	"github.com/werf/werf/v2/pkg/build/image"
 	"github.com/werf/werf/v2/pkg/config"
 	imagePkg "github.com/werf/werf/v2/pkg/image"
 	"github.com/werf/werf/v2/pkg/opstats"
 	"github.com/werf/werf/v2/pkg/storage"
 	"github.com/werf/werf/v2/pkg/telemetry"
+	"github.com/werf/werf/v2/pkg/vulnerability_scanner"
 )
 
 type ReportFormat string
@@ -55,6 +57,8 @@ type ReportImageRecord struct {
 	BuildTime         string
 	Commit            string
 	Stages            []ReportStageRecord
+	Policy            *ReportPolicyRecord
+	VulnerabilityScan *ReportVulnerabilityScanRecord
 }
 
 type ReportStageRecord struct {
@@ -72,6 +76,28 @@ type ReportStageRecord struct {
 	Commit            string
 }
 
+// ReportPolicyRecord describes the image policy checks the image has passed.
+type ReportPolicyRecord struct {
+	BaseImages []string
+	Checks     []string
+}
+
+// ReportVulnerabilityScanRecord is the number of the vulnerabilities found in the image by severity. Cached is true if
+// the result is taken from the previous scan of the same image.
+type ReportVulnerabilityScanRecord struct {
+	Scanner         vulnerability_scanner.ScannerType
+	Cached          bool
+	Vulnerabilities map[vulnerability_scanner.Severity]int
+}
+
+func newReportVulnerabilityScanRecord(summary *vulnerability_scanner.Summary, cached bool) *ReportVulnerabilityScanRecord {
+	return &ReportVulnerabilityScanRecord{
+		Scanner:         summary.Scanner,
+		Cached:          cached,
+		Vulnerabilities: summary.Vulnerabilities,
+	}
+}
+
 type ReportOperationRecord struct {
 	Count            int
 	TotalTimeSeconds float64
@@ -87,6 +113,7 @@ type ImagesReport struct {
 	ImagesByPlatform map[string]map[string]ReportImageRecord
 	Operations       map[string]ReportOperationRecord `json:"Operations,omitempty"`
 	StageCache       map[string]int                   `json:"StageCache,omitempty"`
+	PullEndpoints    map[string]int                   `json:"PullEndpoints,omitempty"`
 }
 
 func NewImagesReport() *ImagesReport {
@@ -96,6 +123,17 @@ func NewImagesReport() *ImagesReport {
 	}
 }
 
+// SetPullEndpoints sets the number of pulls served by each mirror or origin registry.
+func (report *ImagesReport) SetPullEndpoints(endpoints []opstats.PullEndpointSummary) {
+	report.mux.Lock()
+	defer report.mux.Unlock()
+
+	report.PullEndpoints = make(map[string]int, len(endpoints))
+	for _, e := range endpoints {
+		report.PullEndpoints[e.Endpoint] = e.Count
+	}
+}
+
 func (report *ImagesReport) SetImageRecord(name string, imageRecord ReportImageRecord) {
 	report.mux.Lock()
 	defer report.mux.Unlock()
@@ -270,6 +308,8 @@ func createBuildReport(ctx context.Context, phase *BuildPhase, imagePairs []util
 				Commit:            stageDesc.Info.Labels[imagePkg.WerfProjectRepoCommitLabel],
 				Stages:            stages,
 				ConfigType:        configType,
+				Policy:            getPolicyReport(phase.Conveyor.giterminismManager.Policy(), img),
+				VulnerabilityScan: phase.vulnerabilityScanResults.get(img),
 			}
 
 			if os.Getenv("WERF_ENABLE_REPORT_BY_PLATFORM") == "1" {
@@ -320,6 +360,8 @@ func createBuildReport(ctx context.Context, phase *BuildPhase, imagePairs []util
 					BuildTime:         fmt.Sprintf("%.2f", buildDuration),
 					Commit:            stageDesc.Info.Labels[imagePkg.WerfProjectRepoCommitLabel],
 					Stages:            stages,
+					Policy:            getPolicyReport(phase.Conveyor.giterminismManager.Policy(), img.Images...),
+					VulnerabilityScan: phase.vulnerabilityScanResults.get(img.Images...),
 				}
 				phase.ImagesReport.SetImageRecord(img.Name, record)
 			}
@@ -329,7 +371,12 @@ func createBuildReport(ctx context.Context, phase *BuildPhase, imagePairs []util
 	phase.ImagesReport.sendTelemetry(ctx)
 
 	if collector := opstats.FromContext(ctx); collector != nil {
-		phase.ImagesReport.SetOperationsSummary(collector.Summary(), collector.EventSummary())
+		telemetry.GetTelemetryWerfIO().OperationsSummary(ctx, collector.Summary(), collector.EventSummary())
+
+		if logboek.Context(ctx).IsAcceptedLevel(level.Debug) {
+			phase.ImagesReport.SetOperationsSummary(collector.Summary(), collector.EventSummary())
+			phase.ImagesReport.SetPullEndpoints(collector.PullEndpointSummary())
+		}
 	}
 
 	if phase.ReportPath != "" {
@@ -50,6 +50,23 @@ var _ = Describe("ImagesReport operations summary", func() {
 		Expect(decoded.StageCache).To(Equal(map[string]int{"built": 1}))
 	})
 
+	It("serializes pull endpoints to json", func() {
+		report := NewImagesReport()
+		report.SetPullEndpoints([]opstats.PullEndpointSummary{
+			{Endpoint: "mirror.example.com", Count: 2},
+			{Endpoint: "docker.io", Count: 1},
+		})
+
+		data, err := report.ToJsonData()
+		Expect(err).NotTo(HaveOccurred())
+
+		var decoded struct {
+			PullEndpoints map[string]int
+		}
+		Expect(json.Unmarshal(data, &decoded)).To(Succeed())
+		Expect(decoded.PullEndpoints).To(Equal(map[string]int{"mirror.example.com": 2, "docker.io": 1}))
+	})
+
 	It("omits aggregates from json when not set", func() {
 		report := NewImagesReport()
 
@@ -57,5 +74,6 @@ var _ = Describe("ImagesReport operations summary", func() {
 		Expect(err).NotTo(HaveOccurred())
 		Expect(string(data)).NotTo(ContainSubstring("Operations"))
 		Expect(string(data)).NotTo(ContainSubstring("StageCache"))
+		Expect(string(data)).NotTo(ContainSubstring("PullEndpoints"))
 	})
 })
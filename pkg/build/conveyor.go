@@ -783,8 +783,9 @@ func disableUnlessDebugConveyorPhases(logProcess types.LogProcessInterface) type
 	return logProcess
 }
 
+// newOperationsCollector returns the collector for the debug log or for the detailed telemetry events.
 func (c *Conveyor) newOperationsCollector(ctx context.Context) (context.Context, *opstats.Collector, time.Time) {
-	if !logboek.Context(ctx).IsAcceptedLevel(level.Debug) {
+	if !logboek.Context(ctx).IsAcceptedLevel(level.Debug) && !telemetry.IsDetailed() {
 		return ctx, nil, time.Time{}
 	}
 
@@ -793,7 +794,7 @@ func (c *Conveyor) newOperationsCollector(ctx context.Context) (context.Context,
 }
 
 func (c *Conveyor) logOperationsSummary(ctx context.Context, collector *opstats.Collector, buildTime time.Duration) {
-	if collector == nil {
+	if collector == nil || !logboek.Context(ctx).IsAcceptedLevel(level.Debug) {
 		return
 	}
 
@@ -816,6 +817,8 @@ func (c *Conveyor) logOperationsSummary(ctx context.Context, collector *opstats.
 			})
 	}
 
+	c.logPullEndpointsSummary(ctx, collector)
+
 	events := collector.EventSummary()
 	if len(events) == 0 {
 		return
@@ -837,6 +840,23 @@ func (c *Conveyor) logOperationsSummary(ctx context.Context, collector *opstats.
 		})
 }
 
+func (c *Conveyor) logPullEndpointsSummary(ctx context.Context, collector *opstats.Collector) {
+	endpoints := collector.PullEndpointSummary()
+	if len(endpoints) == 0 {
+		return
+	}
+
+	logboek.Context(ctx).LogBlock("Pull endpoints summary").
+		Options(func(options types.LogBlockOptionsInterface) {
+			options.Style(stylePkg.Highlight())
+		}).
+		Do(func() {
+			for _, e := range endpoints {
+				logboek.Context(ctx).LogFHighlight("- %-30s %5d pull(s)\n", e.Endpoint, e.Count)
+			}
+		})
+}
+
 func (c *Conveyor) runPhases(ctx context.Context, phases []Phase, logImages bool) error {
 	for _, phase := range phases {
 		logProcess := disableUnlessDebugConveyorPhases(logboek.Context(ctx).Debug().LogProcess("Phase %s -- BeforeImages()", phase.Name()))
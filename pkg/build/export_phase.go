@@ -8,7 +8,6 @@ import (
 	v1 "github.com/google/go-containerregistry/pkg/v1"
 	"k8s.io/utils/strings/slices"
 
-	"github.com/werf/common-go/pkg/util"
 	"github.com/werf/logboek"
 	"github.com/werf/logboek/pkg/style"
 	"github.com/werf/logboek/pkg/types"
@@ -23,10 +22,23 @@ type Exporter struct {
 	Conveyor *Conveyor
 }
 
+// ExportTarget is the werf.yaml export target of the image.
+type ExportTarget struct {
+	// TagFuncList is set for the container registry target.
+	TagFuncList []image.ExportTagFunc
+	// OCIArchivePath is the absolute path of the OCI archive target.
+	OCIArchivePath string
+	// Platforms are the platforms of the multiplatform image to export, all platforms are exported if empty.
+	Platforms []string
+}
+
 type ExportOptions struct {
 	ExportImageNameList []string
 	ExportTagFuncList   []image.ExportTagFunc
-	MutateConfigFunc    func(config v1.Config) (v1.Config, error)
+	// ExportTargets are the werf.yaml export targets by the image name, the images are exported to them in addition
+	// to ExportTagFuncList.
+	ExportTargets    map[string][]ExportTarget
+	MutateConfigFunc func(config v1.Config) (v1.Config, error)
 }
 
 func NewExporter(c *Conveyor, opts ExportOptions) *Exporter {
@@ -36,6 +48,23 @@ func NewExporter(c *Conveyor, opts ExportOptions) *Exporter {
 	}
 }
 
+// exportImage is the final image to export from the images tree or from the build report.
+type exportImage struct {
+	Name      string
+	StageID   string
+	StageDesc *image.StageDesc
+	// TargetPlatform is empty for the multiplatform image.
+	TargetPlatform string
+}
+
+// exportTask is the export of the image to the single tag or OCI archive.
+type exportTask struct {
+	Image          *exportImage
+	Tag            string
+	OCIArchivePath string
+	Platforms      []string
+}
+
 func (e *Exporter) getMaxNumberOfWorkers() int {
 	if !e.Conveyor.Parallel {
 		return 1
@@ -43,25 +72,31 @@ func (e *Exporter) getMaxNumberOfWorkers() int {
 	return int(e.Conveyor.ParallelTasksLimit)
 }
 
+func (e *Exporter) hasTargets() bool {
+	return len(e.ExportTagFuncList) > 0 || len(e.ExportTargets) > 0
+}
+
 func (e *Exporter) Run(ctx context.Context) error {
-	if len(e.ExportTagFuncList) == 0 {
+	if !e.hasTargets() {
 		return nil
 	}
 
-	images := e.Conveyor.imagesTree.GetImagesByName(true, build_image.WithExportImageNameList(e.ExportImageNameList))
+	var imagesToExport []*exportImage
+	for _, pair := range e.Conveyor.imagesTree.GetImagesByName(true, build_image.WithExportImageNameList(e.ExportImageNameList)) {
+		name, images := pair.Unpair()
 
-	if err := parallel.DoTasks(ctx, len(images), parallel.DoTasksOptions{
-		MaxNumberOfWorkers: e.getMaxNumberOfWorkers(),
-	}, func(ctx context.Context, taskId int) error {
-		pair := images[taskId]
-		name, imagesToExport := pair.Unpair()
-
-		targetPlatforms := util.MapFuncToSlice(imagesToExport, func(img *build_image.Image) string { return img.TargetPlatform })
-		if len(targetPlatforms) == 1 {
-			img := imagesToExport[0]
-			if err := e.exportImage(ctx, img); err != nil {
-				return fmt.Errorf("unable to export image %q: %w", img.Name, err)
+		if len(images) == 1 {
+			img := images[0]
+			if !slices.Contains(e.ExportImageNameList, img.Name) {
+				continue
 			}
+
+			imagesToExport = append(imagesToExport, &exportImage{
+				Name:           img.GetName(),
+				StageID:        img.GetStageID(),
+				StageDesc:      img.GetLastNonEmptyStage().GetStageImage().Image.GetStageDesc(),
+				TargetPlatform: img.TargetPlatform,
+			})
 		} else {
 			// FIXME(multiarch): Support multiplatform manifest by pushing local images to repo first, then create manifest list.
 			// FIXME(multiarch): Also support multiplatform manifest in werf build command in local mode with enabled final-repo.
@@ -71,20 +106,24 @@ func (e *Exporter) Run(ctx context.Context) error {
 
 			// multiplatform mode
 			img := e.Conveyor.imagesTree.GetMultiplatformImage(name)
-			if err := e.exportMultiplatformImage(ctx, img); err != nil {
-				return fmt.Errorf("unable to export multiplatform image %q: %w", img.Name, err)
-			}
+			imagesToExport = append(imagesToExport, &exportImage{
+				Name:      img.Name,
+				StageID:   img.GetStageID().String(),
+				StageDesc: img.GetStageDesc(),
+			})
 		}
-		return nil
-	}); err != nil {
+	}
+
+	if err := e.export(ctx, imagesToExport); err != nil {
 		return fmt.Errorf("export failed: %w", err)
 	}
 
 	return nil
 }
 
+// RunFromReport exports the images of the build report, so the images are not loaded from werf.yaml.
 func (e *Exporter) RunFromReport(ctx context.Context, reportPath string) error {
-	if len(e.ExportTagFuncList) == 0 {
+	if !e.hasTargets() {
 		return nil
 	}
 
@@ -93,23 +132,17 @@ func (e *Exporter) RunFromReport(ctx context.Context, reportPath string) error {
 		return fmt.Errorf("unable to load build report: %w", err)
 	}
 
-	imagesToExport := e.filterImagesFromReport(report)
-	if len(imagesToExport) == 0 {
-		return nil
-	}
-
-	if err := parallel.DoTasks(ctx, len(imagesToExport), parallel.DoTasksOptions{
-		MaxNumberOfWorkers: e.getMaxNumberOfWorkers(),
-	}, func(ctx context.Context, taskId int) error {
-		imgRecord := imagesToExport[taskId]
-		isMultiplatform := imgRecord.TargetPlatform == ""
-
-		if err := e.exportImageFromReportRecord(ctx, imgRecord, isMultiplatform); err != nil {
-			return fmt.Errorf("unable to export image %q from report: %w", imgRecord.WerfImageName, err)
+	var imagesToExport []*exportImage
+	for _, record := range e.filterImagesFromReport(report) {
+		img, err := exportImageFromReportRecord(record)
+		if err != nil {
+			return fmt.Errorf("unable to export image %q from report: %w", record.WerfImageName, err)
 		}
 
-		return nil
-	}); err != nil {
+		imagesToExport = append(imagesToExport, img)
+	}
+
+	if err := e.export(ctx, imagesToExport); err != nil {
 		return fmt.Errorf("export from report failed: %w", err)
 	}
 
@@ -135,105 +168,143 @@ func (e *Exporter) filterImagesFromReport(report *ImagesReport) []ReportImageRec
 	return result
 }
 
-func (e *Exporter) exportImageFromReportRecord(ctx context.Context, record ReportImageRecord, isMultiplatform bool) error {
-	if len(e.ExportTagFuncList) == 0 {
-		return nil
+func exportImageFromReportRecord(record ReportImageRecord) (*exportImage, error) {
+	stageDesc, err := stageDescFromReportRecord(record)
+	if err != nil {
+		return nil, fmt.Errorf("unable to get stage desc from report record: %w", err)
 	}
 
-	return logboek.Context(ctx).Default().LogProcess(fmt.Sprintf("Exporting image %s (from report)", record.WerfImageName)).
-		Options(func(options types.LogProcessOptionsInterface) {
-			options.Style(style.Highlight())
-		}).
-		DoError(func() error {
-			stageDesc, err := stageDescFromReportRecord(record)
-			if err != nil {
-				return fmt.Errorf("unable to get stage desc from report record: %w", err)
-			}
+	isMultiplatform := record.TargetPlatform == ""
+	if isMultiplatform {
+		stageDesc.Info.IsIndex = true
+	}
 
-			if isMultiplatform {
-				stageDesc.Info.IsIndex = true
-			}
+	stageID, err := extractStageIDFromReport(record)
+	if err != nil {
+		return nil, fmt.Errorf("unable to extract stage id from report record: %w", err)
+	}
+
+	return &exportImage{
+		Name:           record.WerfImageName,
+		StageID:        stageID,
+		StageDesc:      stageDesc,
+		TargetPlatform: record.TargetPlatform,
+	}, nil
+}
 
-			for _, tagFunc := range e.ExportTagFuncList {
-				stageID, err := extractStageIDFromReport(record)
+// export exports the images to all the tags and OCI archives in parallel.
+func (e *Exporter) export(ctx context.Context, imagesToExport []*exportImage) error {
+	tasks, err := e.getExportTasks(ctx, imagesToExport)
+	if err != nil {
+		return err
+	}
+
+	return parallel.DoTasks(ctx, len(tasks), parallel.DoTasksOptions{
+		MaxNumberOfWorkers: e.getMaxNumberOfWorkers(),
+	}, func(ctx context.Context, taskId int) error {
+		task := tasks[taskId]
+
+		if err := e.doExportTask(ctx, task); err != nil {
+			return fmt.Errorf("unable to export image %q: %w", task.Image.Name, err)
+		}
+
+		return nil
+	})
+}
+
+func (e *Exporter) getExportTasks(ctx context.Context, imagesToExport []*exportImage) ([]*exportTask, error) {
+	var tasks []*exportTask
+	for _, img := range imagesToExport {
+		for _, tagFunc := range e.ExportTagFuncList {
+			tasks = append(tasks, &exportTask{Image: img, Tag: tagFunc(img.Name, img.StageID)})
+		}
+
+		for _, target := range e.ExportTargets[img.Name] {
+			platforms := target.Platforms
+
+			// The platforms of the target select the manifests of the multiplatform image, the single-platform image
+			// is exported as is or not exported at all.
+			if img.TargetPlatform != "" && len(platforms) > 0 {
+				matched, err := matchExportPlatform(img.TargetPlatform, platforms)
 				if err != nil {
-					return fmt.Errorf("unable to extract stage id from report record: %w", err)
+					return nil, err
 				}
 
-				tag := tagFunc(record.WerfImageName, stageID)
-				if err := logboek.Context(ctx).Default().LogProcess("tag %s", tag).
-					DoError(func() error {
-						if err := e.Conveyor.StorageManager.GetStagesStorage().ExportStage(ctx, stageDesc, tag, e.MutateConfigFunc); err != nil {
-							return fmt.Errorf("unable to export stage %s: %w", stageDesc.StageID.String(), err)
-						}
-
-						return nil
-					}); err != nil {
-					return err
+				if !matched {
+					logboek.Context(ctx).Info().LogF("Skipping export target of image %s: platform %s is not one of %v\n", img.Name, img.TargetPlatform, platforms)
+					continue
 				}
+
+				platforms = nil
 			}
 
-			return nil
-		})
-}
+			if target.OCIArchivePath != "" {
+				tasks = append(tasks, &exportTask{Image: img, OCIArchivePath: target.OCIArchivePath, Platforms: platforms})
+				continue
+			}
 
-func (e *Exporter) exportMultiplatformImage(ctx context.Context, img *build_image.MultiplatformImage) error {
-	return logboek.Context(ctx).Default().LogProcess(fmt.Sprintf("Exporting image %s", img.Name)).
-		Options(func(options types.LogProcessOptionsInterface) {
-			options.Style(style.Highlight())
-		}).
-		DoError(func() error {
-			for _, tagFunc := range e.ExportTagFuncList {
-				tag := tagFunc(img.Name, img.GetStageID().String())
-				if err := logboek.Context(ctx).Default().LogProcess("tag %s", tag).
-					DoError(func() error {
-						stageDesc := img.GetStageDesc()
-						if err := e.Conveyor.StorageManager.GetStagesStorage().ExportStage(ctx, stageDesc, tag, e.MutateConfigFunc); err != nil {
-							return fmt.Errorf("unable to export stage %s: %w", stageDesc.StageID.String(), err)
-						}
-
-						return nil
-					}); err != nil {
-					return err
-				}
+			for _, tagFunc := range target.TagFuncList {
+				tasks = append(tasks, &exportTask{Image: img, Tag: tagFunc(img.Name, img.StageID), Platforms: platforms})
 			}
+		}
+	}
 
-			return nil
-		})
+	return tasks, nil
 }
 
-func (e *Exporter) exportImage(ctx context.Context, img *build_image.Image) error {
-	if !slices.Contains(e.ExportImageNameList, img.Name) {
-		return nil
+func (e *Exporter) doExportTask(ctx context.Context, task *exportTask) error {
+	opts := storage.ExportStageOptions{
+		MutateConfigFunc: e.MutateConfigFunc,
+		Platforms:        task.Platforms,
 	}
-	if len(e.ExportTagFuncList) == 0 {
-		return nil
+
+	if task.OCIArchivePath != "" {
+		return logboek.Context(ctx).Default().LogProcess("Exporting image %s to OCI archive %s", task.Image.Name, task.OCIArchivePath).
+			Options(func(options types.LogProcessOptionsInterface) {
+				options.Style(style.Highlight())
+			}).
+			DoError(func() error {
+				if err := e.Conveyor.StorageManager.GetStagesStorage().ExportStageToOCIArchive(ctx, task.Image.StageDesc, task.OCIArchivePath, task.Image.Name, opts); err != nil {
+					return fmt.Errorf("unable to export stage %s: %w", task.Image.StageDesc.StageID.String(), err)
+				}
+
+				return nil
+			})
 	}
 
-	return logboek.Context(ctx).Default().LogProcess(fmt.Sprintf("Exporting image %s", img.Name)).
+	return logboek.Context(ctx).Default().LogProcess("Exporting image %s to %s", task.Image.Name, task.Tag).
 		Options(func(options types.LogProcessOptionsInterface) {
 			options.Style(style.Highlight())
 		}).
 		DoError(func() error {
-			for _, tagFunc := range e.ExportTagFuncList {
-				tag := tagFunc(img.GetName(), img.GetStageID())
-				if err := logboek.Context(ctx).Default().LogProcess("tag %s", tag).
-					DoError(func() error {
-						stageDesc := img.GetLastNonEmptyStage().GetStageImage().Image.GetStageDesc()
-						if err := e.Conveyor.StorageManager.GetStagesStorage().ExportStage(ctx, stageDesc, tag, e.MutateConfigFunc); err != nil {
-							return err
-						}
-
-						return nil
-					}); err != nil {
-					return err
-				}
+			if err := e.Conveyor.StorageManager.GetStagesStorage().ExportStage(ctx, task.Image.StageDesc, task.Tag, opts); err != nil {
+				return fmt.Errorf("unable to export stage %s: %w", task.Image.StageDesc.StageID.String(), err)
 			}
 
 			return nil
 		})
 }
 
+func matchExportPlatform(platform string, platforms []string) (bool, error) {
+	p, err := v1.ParsePlatform(platform)
+	if err != nil {
+		return false, fmt.Errorf("unable to parse platform %q: %w", platform, err)
+	}
+
+	for _, spec := range platforms {
+		specPlatform, err := v1.ParsePlatform(spec)
+		if err != nil {
+			return false, fmt.Errorf("unable to parse platform %q: %w", spec, err)
+		}
+
+		if p.Satisfies(*specPlatform) {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
 func stageDescFromReportRecord(record ReportImageRecord) (*image.StageDesc, error) {
 	var createdAtUnixNano int64
 	if len(record.Stages) > 0 {
@@ -0,0 +1,69 @@
+package build
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/werf/werf/v2/pkg/image"
+)
+
+var _ = Describe("Exporter", func() {
+	tagFunc := func(repo string) image.ExportTagFunc {
+		return func(imageName, contentBasedTag string) string {
+			return repo + ":" + imageName + "-" + contentBasedTag
+		}
+	}
+
+	getTasks := func(ctx context.Context, opts ExportOptions, imagesToExport ...*exportImage) []exportTask {
+		tasks, err := NewExporter(&Conveyor{}, opts).getExportTasks(ctx, imagesToExport)
+		Expect(err).NotTo(HaveOccurred())
+
+		var res []exportTask
+		for _, task := range tasks {
+			res = append(res, *task)
+		}
+		return res
+	}
+
+	It("exports the images to the tags and the werf.yaml targets of the image", func(ctx SpecContext) {
+		app := &exportImage{Name: "app", StageID: "stage-id"}
+		worker := &exportImage{Name: "worker", StageID: "stage-id"}
+
+		tasks := getTasks(ctx, ExportOptions{
+			ExportTagFuncList: []image.ExportTagFunc{tagFunc("registry-1")},
+			ExportTargets: map[string][]ExportTarget{
+				"app": {
+					{TagFuncList: []image.ExportTagFunc{tagFunc("registry-2"), tagFunc("registry-3")}, Platforms: []string{"linux/amd64"}},
+					{OCIArchivePath: "/project/app.tar"},
+				},
+			},
+		}, app, worker)
+
+		Expect(tasks).To(Equal([]exportTask{
+			{Image: app, Tag: "registry-1:app-stage-id"},
+			{Image: app, Tag: "registry-2:app-stage-id", Platforms: []string{"linux/amd64"}},
+			{Image: app, Tag: "registry-3:app-stage-id", Platforms: []string{"linux/amd64"}},
+			{Image: app, OCIArchivePath: "/project/app.tar"},
+			{Image: worker, Tag: "registry-1:worker-stage-id"},
+		}))
+	})
+
+	It("exports the single-platform image only to the targets of its platform", func(ctx SpecContext) {
+		app := &exportImage{Name: "app", StageID: "stage-id", TargetPlatform: "linux/arm64"}
+
+		tasks := getTasks(ctx, ExportOptions{
+			ExportTargets: map[string][]ExportTarget{
+				"app": {
+					{OCIArchivePath: "/project/amd64.tar", Platforms: []string{"linux/amd64"}},
+					{OCIArchivePath: "/project/arm64.tar", Platforms: []string{"linux/amd64", "linux/arm64"}},
+				},
+			},
+		}, app)
+
+		Expect(tasks).To(Equal([]exportTask{
+			{Image: app, OCIArchivePath: "/project/arm64.tar"},
+		}))
+	})
+})
@@ -390,6 +390,38 @@ func (i *Image) GetBaseImageRepoDigest() string {
 	return i.baseImageRepoDigest
 }
 
+// GetRegistryBaseImageReferences returns the references of the registry images the image is built from: the base
+// image or, for the dockerfile image built without stages, the images of FROM instructions. The scratch image is
+// skipped. The base image should be set up before the call.
+func (i *Image) GetRegistryBaseImageReferences() ([]string, error) {
+	switch i.baseImageType {
+	case ImageFromRegistryAsBaseImage:
+		if i.baseImageReference == "scratch" {
+			return nil, nil
+		}
+		return []string{i.baseImageReference}, nil
+	case NoBaseImage:
+		for _, stg := range i.stages {
+			if dockerfileStage, ok := stg.(*stage.FullDockerfileStage); ok {
+				return dockerfileStage.GetBaseImageReferences(i.Conveyor)
+			}
+		}
+	}
+
+	return nil, nil
+}
+
+func (i *Image) GetImageSpecConfig() *config.ImageSpec {
+	switch {
+	case i.IsDockerfileImage:
+		return i.DockerfileImageConfig.ImageSpec
+	case i.StapelImageConfig != nil:
+		return i.StapelImageConfig.ImageBaseConfig().ImageSpec
+	default:
+		return nil
+	}
+}
+
 const (
 	BaseImageSourceTypeRepo     = "repo"
 	BaseImageSourceTypeRegistry = "registry"
@@ -305,6 +305,10 @@ func filterAndLogGitMappings(ctx context.Context, gitMappings []*stage.GitMappin
 					logboek.Context(ctx).Info().LogFDetails("group: %s\n", gitMapping.Group)
 				}
 
+				if gitMapping.CacheMode != config.GitCacheModeAncestry {
+					logboek.Context(ctx).Info().LogFDetails("cacheMode: %s\n", gitMapping.CacheMode)
+				}
+
 				if len(gitMapping.StagesDependencies) != 0 {
 					logboek.Context(ctx).Info().LogLnDetails("stageDependencies:")
 
@@ -401,6 +405,7 @@ func baseGitMappingInit(local *config.GitLocalExport, imageName string, conveyor
 	gitMapping.Owner = local.Owner
 	gitMapping.Group = local.Group
 	gitMapping.StagesDependencies = stageDependencies
+	gitMapping.CacheMode = local.CacheMode
 
 	return gitMapping
 }
@@ -427,9 +432,10 @@ func makeGitMappingTo(ctx context.Context, gitMapping *stage.GitMapping, gitMapp
 
 func stageDependenciesToMap(sd *config.StageDependencies) map[stage.StageName][]string {
 	result := map[stage.StageName][]string{
-		stage.Install:     sd.Install,
-		stage.BeforeSetup: sd.BeforeSetup,
-		stage.Setup:       sd.Setup,
+		stage.BeforeInstall: sd.BeforeInstall,
+		stage.Install:       sd.Install,
+		stage.BeforeSetup:   sd.BeforeSetup,
+		stage.Setup:         sd.Setup,
 	}
 
 	return result
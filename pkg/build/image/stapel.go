@@ -64,8 +64,6 @@ func mapStapelConfigToImage(ctx context.Context, metaConfig *config.Meta, stapel
 }
 
 func initStages(ctx context.Context, image *Image, metaConfig *config.Meta, stapelImageConfig config.StapelImageInterface, opts CommonImageOptions) error {
-	var stages []stage.Interface
-
 	imageBaseConfig := stapelImageConfig.ImageBaseConfig()
 	imageName := imageBaseConfig.Name
 
@@ -102,14 +100,53 @@ func initStages(ctx context.Context, image *Image, metaConfig *config.Meta, stap
 	// TODO(v3): make this a hard error instead of a warning.
 	warnStageDependenciesWithoutInstructions(ctx, imageBaseConfig, gitMappings)
 
-	imageCacheVersion := option.ValueOrDefault(stapelImageConfig.CacheVersion(), metaConfig.Build.CacheVersion)
+	stages := generateStapelStages(ctx, stapelImageConfig, generateStapelStagesOptions{
+		BaseImageRepoId:        image.baseImageRepoId,
+		ImageCacheVersion:      option.ValueOrDefault(stapelImageConfig.CacheVersion(), metaConfig.Build.CacheVersion),
+		GitMappingsExist:       gitMappingsExist,
+		SkipImageSpecStage:     opts.Conveyor.SkipImageSpecStage(),
+		BaseStageOptions:       baseStageOptions,
+		GitArchiveStageOptions: gitArchiveStageOptions,
+		GitPatchStageOptions:   gitPatchStageOptions,
+	})
+
+	if len(gitMappings) != 0 {
+		logboek.Context(ctx).Info().LogLnDetails("Using git stages")
+
+		for _, s := range stages {
+			s.SetGitMappings(gitMappings)
+		}
+	}
+
+	image.SetStages(stages)
+
+	return nil
+}
+
+type generateStapelStagesOptions struct {
+	BaseImageRepoId        string
+	ImageCacheVersion      string
+	GitMappingsExist       bool
+	SkipImageSpecStage     bool
+	BaseStageOptions       *stage.BaseStageOptions
+	GitArchiveStageOptions *stage.NewGitArchiveStageOptions
+	GitPatchStageOptions   *stage.NewGitPatchStageOptions
+}
 
-	stages = appendIfExist(stages, stage.GenerateFromStage(imageBaseConfig, image.baseImageRepoId, imageCacheVersion, baseStageOptions))
+// generateStapelStages returns the stages of the stapel image in the build order.
+func generateStapelStages(ctx context.Context, stapelImageConfig config.StapelImageInterface, opts generateStapelStagesOptions) []stage.Interface {
+	var stages []stage.Interface
+
+	imageBaseConfig := stapelImageConfig.ImageBaseConfig()
+	baseStageOptions := opts.BaseStageOptions
+	gitPatchStageOptions := opts.GitPatchStageOptions
+
+	stages = appendIfExist(stages, stage.GenerateFromStage(imageBaseConfig, opts.BaseImageRepoId, opts.ImageCacheVersion, baseStageOptions))
 	stages = appendIfExist(stages, stage.GenerateBeforeInstallStage(ctx, imageBaseConfig, baseStageOptions))
 	stages = appendIfExist(stages, stage.GenerateDependenciesBeforeInstallStage(imageBaseConfig, baseStageOptions))
 
-	if gitMappingsExist {
-		stages = append(stages, stage.NewGitArchiveStage(gitArchiveStageOptions, baseStageOptions))
+	if opts.GitMappingsExist {
+		stages = append(stages, stage.NewGitArchiveStage(opts.GitArchiveStageOptions, baseStageOptions))
 	}
 
 	stages = appendIfExist(stages, stage.GenerateInstallStage(ctx, imageBaseConfig, gitPatchStageOptions, baseStageOptions))
@@ -120,7 +157,7 @@ func initStages(ctx context.Context, image *Image, metaConfig *config.Meta, stap
 	stages = appendIfExist(stages, stage.GenerateDependenciesAfterSetupStage(imageBaseConfig, baseStageOptions))
 
 	if !stapelImageConfig.IsGitAfterPatchDisabled() {
-		if gitMappingsExist {
+		if opts.GitMappingsExist {
 			stages = append(stages, stage.NewGitCacheStage(gitPatchStageOptions, baseStageOptions))
 			stages = append(stages, stage.NewGitLatestPatchStage(gitPatchStageOptions, baseStageOptions))
 		}
@@ -128,21 +165,31 @@ func initStages(ctx context.Context, image *Image, metaConfig *config.Meta, stap
 		stages = appendIfExist(stages, stage.GenerateStapelDockerInstructionsStage(stapelImageConfig.(*config.StapelImage), baseStageOptions))
 	}
 
-	if imageBaseConfig.ImageSpec != nil && !opts.Conveyor.SkipImageSpecStage() {
+	if imageBaseConfig.ImageSpec != nil && !opts.SkipImageSpecStage {
 		stages = appendIfExist(stages, stage.GenerateImageSpecStage(imageBaseConfig.ImageSpec, baseStageOptions))
 	}
 
-	if len(gitMappings) != 0 {
-		logboek.Context(ctx).Info().LogLnDetails("Using git stages")
+	return stages
+}
 
-		for _, s := range stages {
-			s.SetGitMappings(gitMappings)
-		}
-	}
+// StapelStageNames returns the names of the stages of the stapel image in the build order without initializing the
+// image. The git stages are expected if the image config has any git mapping.
+func StapelStageNames(ctx context.Context, stapelImageConfig config.StapelImageInterface) []stage.StageName {
+	imageBaseConfig := stapelImageConfig.ImageBaseConfig()
 
-	image.SetStages(stages)
+	stages := generateStapelStages(ctx, stapelImageConfig, generateStapelStagesOptions{
+		GitMappingsExist:       imageBaseConfig.Git != nil && len(imageBaseConfig.Git.Local)+len(imageBaseConfig.Git.Remote) > 0,
+		BaseStageOptions:       &stage.BaseStageOptions{ImageName: imageBaseConfig.Name},
+		GitArchiveStageOptions: &stage.NewGitArchiveStageOptions{},
+		GitPatchStageOptions:   &stage.NewGitPatchStageOptions{},
+	})
 
-	return nil
+	names := make([]stage.StageName, 0, len(stages))
+	for _, s := range stages {
+		names = append(names, s.Name())
+	}
+
+	return names
 }
 
 // TODO(v3): make this a hard error instead of a warning.
@@ -169,6 +216,8 @@ func warnStageDependenciesWithoutInstructions(ctx context.Context, imageBaseConf
 func hasStageInstructions(imageBaseConfig *config.StapelImageBase, stageName stage.StageName) bool {
 	if imageBaseConfig.Shell != nil {
 		switch stageName {
+		case stage.BeforeInstall:
+			return len(imageBaseConfig.Shell.BeforeInstall) > 0
 		case stage.Install:
 			return len(imageBaseConfig.Shell.Install) > 0
 		case stage.BeforeSetup:
@@ -180,6 +229,8 @@ func hasStageInstructions(imageBaseConfig *config.StapelImageBase, stageName sta
 
 	if imageBaseConfig.Ansible != nil {
 		switch stageName {
+		case stage.BeforeInstall:
+			return len(imageBaseConfig.Ansible.BeforeInstall) > 0
 		case stage.Install:
 			return len(imageBaseConfig.Ansible.Install) > 0
 		case stage.BeforeSetup:
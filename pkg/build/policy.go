@@ -0,0 +1,219 @@
+package build
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/docker/distribution/reference"
+	"github.com/docker/go-units"
+
+	"github.com/werf/werf/v2/pkg/build/image"
+	"github.com/werf/werf/v2/pkg/config"
+	giterminismConfig "github.com/werf/werf/v2/pkg/giterminism_manager/config"
+	imagePkg "github.com/werf/werf/v2/pkg/image"
+)
+
+const (
+	PolicyCheckBaseImages     = "baseImages"
+	PolicyCheckRequireLabels  = "requireLabels"
+	PolicyCheckMaxSize        = "maxSize"
+	PolicyCheckForbidRootUser = "forbidRootUser"
+	PolicyCheckRequireAuthor  = "requireAuthor"
+)
+
+// checkBaseImagesPolicy fails if any registry base image of the image is not allowed by the policy.
+func (phase *BuildPhase) checkBaseImagesPolicy(img *image.Image) error {
+	policy := phase.Conveyor.giterminismManager.Policy().BaseImages
+	if policy.IsEmpty() {
+		return nil
+	}
+
+	refs, err := img.GetRegistryBaseImageReferences()
+	if err != nil {
+		return fmt.Errorf("unable to get base images of image %s: %w", img.LogName(), err)
+	}
+
+	for _, ref := range refs {
+		if err := checkBaseImageReference(policy, ref); err != nil {
+			return fmt.Errorf("image %s violates the policy: %w", img.LogName(), err)
+		}
+	}
+
+	return nil
+}
+
+// checkFinalImagesConfigPolicy fails if the config of any final image does not comply with the policy. The check does
+// not need the built image and is done before the build.
+func (phase *BuildPhase) checkFinalImagesConfigPolicy() error {
+	policy := phase.Conveyor.giterminismManager.Policy().FinalImages
+	if policy.IsEmpty() {
+		return nil
+	}
+
+	for _, img := range phase.Conveyor.imagesTree.GetImages() {
+		if !img.IsFinal {
+			continue
+		}
+
+		if err := checkFinalImageConfig(policy, img.GetImageSpecConfig()); err != nil {
+			return fmt.Errorf("image %s violates the policy: %w", img.LogName(), err)
+		}
+	}
+
+	return nil
+}
+
+// checkFinalImagesPolicy fails if any built final image does not comply with the policy. The check is done before the
+// images are published to the final repo and tagged.
+func (phase *BuildPhase) checkFinalImagesPolicy(ctx context.Context) error {
+	policy := phase.Conveyor.giterminismManager.Policy().FinalImages
+	if policy.IsEmpty() {
+		return nil
+	}
+
+	for _, img := range phase.Conveyor.imagesTree.GetImages() {
+		if !img.IsFinal {
+			continue
+		}
+
+		info, err := phase.getFinalImageInfo(ctx, img, policy)
+		if err != nil {
+			return err
+		}
+
+		if err := checkFinalImage(policy, info); err != nil {
+			return fmt.Errorf("image %s violates the policy: %w", img.LogName(), err)
+		}
+	}
+
+	return nil
+}
+
+// getFinalImageInfo returns the info of the last stage of the image. The manifest cache records written by the previous
+// werf versions have no user of the image, such info is fetched from the stages storage.
+func (phase *BuildPhase) getFinalImageInfo(ctx context.Context, img *image.Image, policy giterminismConfig.FinalImagesPolicy) (*imagePkg.Info, error) {
+	stageDesc := img.GetLastNonEmptyStage().GetStageImage().Image.GetStageDesc()
+	if !policy.ForbidRootUser || stageDesc.Info.User != nil {
+		return stageDesc.Info, nil
+	}
+
+	stageDesc, err := phase.Conveyor.StorageManager.GetStagesStorage().GetStageDesc(ctx, phase.Conveyor.ProjectName(), *stageDesc.StageID)
+	if err != nil {
+		return nil, fmt.Errorf("unable to get image %s info: %w", img.LogName(), err)
+	}
+
+	return stageDesc.Info, nil
+}
+
+func checkBaseImageReference(policy giterminismConfig.BaseImagesPolicy, ref string) error {
+	named, err := reference.ParseNormalizedNamed(ref)
+	if err != nil {
+		return fmt.Errorf("unable to parse base image reference %q: %w", ref, err)
+	}
+
+	if digested, ok := named.(reference.Digested); ok && slices.Contains(policy.AllowDigests, digested.Digest().String()) {
+		return nil
+	}
+
+	for _, registry := range policy.AllowRegistries {
+		registry = strings.TrimSuffix(registry, "/")
+		if named.Name() == registry || strings.HasPrefix(named.Name(), registry+"/") {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("base image %q is not allowed: the repository %q is not in policy.baseImages.allowRegistries and the image is not pinned to a digest from policy.baseImages.allowDigests", ref, named.Name())
+}
+
+func checkFinalImageConfig(policy giterminismConfig.FinalImagesPolicy, imageSpec *config.ImageSpec) error {
+	for _, label := range policy.RequireLabels {
+		if imageSpec == nil || imageSpec.Labels[label] == "" {
+			return fmt.Errorf("required label %q is not set (add it with imageSpec.labels directive)", label)
+		}
+	}
+
+	if policy.RequireAuthor && (imageSpec == nil || imageSpec.Author == "") {
+		return fmt.Errorf("imageSpec.author directive is required")
+	}
+
+	return nil
+}
+
+func checkFinalImage(policy giterminismConfig.FinalImagesPolicy, info *imagePkg.Info) error {
+	if policy.MaxSize != "" {
+		maxSize, err := policy.MaxSizeBytes()
+		if err != nil {
+			return fmt.Errorf("invalid policy.finalImages.maxSize %q: %w", policy.MaxSize, err)
+		}
+
+		if info.Size > maxSize {
+			return fmt.Errorf("image size %s exceeds the max size %s", units.BytesSize(float64(info.Size)), units.BytesSize(float64(maxSize)))
+		}
+	}
+
+	if policy.ForbidRootUser {
+		if info.User == nil {
+			return fmt.Errorf("unable to check the user of the image: the user is unknown")
+		}
+
+		if isRootUser(*info.User) {
+			return fmt.Errorf("image runs as root user %q (set a non-root user with USER instruction or imageSpec.user directive)", *info.User)
+		}
+	}
+
+	return nil
+}
+
+// isRootUser returns true if the user of the image config is root, the image without the user runs as root.
+func isRootUser(user string) bool {
+	name, _, _ := strings.Cut(user, ":")
+	return name == "" || name == "root" || name == "0"
+}
+
+func getPolicyReport(policy giterminismConfig.Policy, images ...*image.Image) *ReportPolicyRecord {
+	if policy.IsEmpty() {
+		return nil
+	}
+
+	record := &ReportPolicyRecord{}
+	addCheck := func(check string) {
+		if !slices.Contains(record.Checks, check) {
+			record.Checks = append(record.Checks, check)
+		}
+	}
+
+	for _, img := range images {
+		if !policy.BaseImages.IsEmpty() {
+			addCheck(PolicyCheckBaseImages)
+
+			// The base images are already checked at this point, so the error is not expected.
+			refs, _ := img.GetRegistryBaseImageReferences()
+			for _, ref := range refs {
+				if !slices.Contains(record.BaseImages, ref) {
+					record.BaseImages = append(record.BaseImages, ref)
+				}
+			}
+		}
+
+		if !img.IsFinal {
+			continue
+		}
+
+		if len(policy.FinalImages.RequireLabels) != 0 {
+			addCheck(PolicyCheckRequireLabels)
+		}
+		if policy.FinalImages.MaxSize != "" {
+			addCheck(PolicyCheckMaxSize)
+		}
+		if policy.FinalImages.ForbidRootUser {
+			addCheck(PolicyCheckForbidRootUser)
+		}
+		if policy.FinalImages.RequireAuthor {
+			addCheck(PolicyCheckRequireAuthor)
+		}
+	}
+
+	return record
+}
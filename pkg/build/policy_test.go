@@ -0,0 +1,103 @@
+package build
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/samber/lo"
+
+	"github.com/werf/werf/v2/pkg/config"
+	giterminismConfig "github.com/werf/werf/v2/pkg/giterminism_manager/config"
+	imagePkg "github.com/werf/werf/v2/pkg/image"
+)
+
+var _ = Describe("Policy", func() {
+	DescribeTable("checking base image reference",
+		func(ref string, expectAllowed bool) {
+			policy := giterminismConfig.BaseImagesPolicy{
+				AllowRegistries: []string{"registry.example.com/", "docker.io/library/alpine"},
+				AllowDigests:    []string{"sha256:3b80b2c7a4b5e6a1e0b7e9a0e8f1f2c3d4e5f60718293a4b5c6d7e8f90a1b2c3"},
+			}
+
+			err := checkBaseImageReference(policy, ref)
+			if expectAllowed {
+				Expect(err).NotTo(HaveOccurred())
+			} else {
+				Expect(err).To(HaveOccurred())
+			}
+		},
+		Entry("the image of the allowed registry", "registry.example.com/base/app:1.0", true),
+		Entry("the image of the registry with the same prefix", "registry.example.com.evil.io/app:1.0", false),
+		Entry("the allowed repository with short name", "alpine:3.20", true),
+		Entry("the repository with the same prefix", "alpine-evil:3.20", false),
+		Entry("the image pinned to the allowed digest", "ubuntu@sha256:3b80b2c7a4b5e6a1e0b7e9a0e8f1f2c3d4e5f60718293a4b5c6d7e8f90a1b2c3", true),
+		Entry("the image pinned to another digest", "ubuntu@sha256:0000000000000000000000000000000000000000000000000000000000000000", false),
+		Entry("the image of another registry", "ghcr.io/org/app:latest", false),
+	)
+
+	DescribeTable("checking final image config",
+		func(policy giterminismConfig.FinalImagesPolicy, imageSpec *config.ImageSpec, expectedErr string) {
+			err := checkFinalImageConfig(policy, imageSpec)
+			if expectedErr == "" {
+				Expect(err).NotTo(HaveOccurred())
+			} else {
+				Expect(err).To(MatchError(ContainSubstring(expectedErr)))
+			}
+		},
+		Entry("the config complying with the policy",
+			giterminismConfig.FinalImagesPolicy{RequireLabels: []string{"org.opencontainers.image.source"}, RequireAuthor: true},
+			&config.ImageSpec{Author: "team@example.com", Labels: map[string]string{"org.opencontainers.image.source": "https://example.com"}},
+			"",
+		),
+		Entry("the config without the required label",
+			giterminismConfig.FinalImagesPolicy{RequireLabels: []string{"org.opencontainers.image.source"}},
+			&config.ImageSpec{Labels: map[string]string{"org.opencontainers.image.title": "app"}},
+			`required label "org.opencontainers.image.source" is not set`,
+		),
+		Entry("the config without imageSpec",
+			giterminismConfig.FinalImagesPolicy{RequireLabels: []string{"org.opencontainers.image.source"}},
+			nil,
+			`required label "org.opencontainers.image.source" is not set`,
+		),
+		Entry("the config without the author",
+			giterminismConfig.FinalImagesPolicy{RequireAuthor: true},
+			&config.ImageSpec{},
+			"imageSpec.author directive is required",
+		),
+	)
+
+	DescribeTable("checking final image",
+		func(policy giterminismConfig.FinalImagesPolicy, info *imagePkg.Info, expectedErr string) {
+			err := checkFinalImage(policy, info)
+			if expectedErr == "" {
+				Expect(err).NotTo(HaveOccurred())
+			} else {
+				Expect(err).To(MatchError(ContainSubstring(expectedErr)))
+			}
+		},
+		Entry("the image complying with the policy",
+			giterminismConfig.FinalImagesPolicy{MaxSize: "100MiB", ForbidRootUser: true},
+			&imagePkg.Info{Size: 50 * 1024 * 1024, User: lo.ToPtr("app:app")},
+			"",
+		),
+		Entry("the image exceeding the max size",
+			giterminismConfig.FinalImagesPolicy{MaxSize: "100M"},
+			&imagePkg.Info{Size: 101 * 1024 * 1024},
+			"exceeds the max size",
+		),
+		Entry("the image without the user",
+			giterminismConfig.FinalImagesPolicy{ForbidRootUser: true},
+			&imagePkg.Info{User: lo.ToPtr("")},
+			"runs as root user",
+		),
+		Entry("the image with the unknown user",
+			giterminismConfig.FinalImagesPolicy{ForbidRootUser: true},
+			&imagePkg.Info{},
+			"the user is unknown",
+		),
+		Entry("the image with the root uid",
+			giterminismConfig.FinalImagesPolicy{ForbidRootUser: true},
+			&imagePkg.Info{User: lo.ToPtr("0:1000")},
+			"runs as root user",
+		),
+	)
+})
@@ -290,6 +290,18 @@ ScanImages:
 				continue ScanImages
 			}
 
+			if gitMapping.CacheMode == config.GitCacheModeContent {
+				_, isContentSame, err := gitMapping.isLatestContentSameAsInBuiltImage(ctx, c, stageDesc.Info.Labels)
+				if err != nil {
+					return nil, fmt.Errorf("error comparing git mapping %s content with stage %s: %w", gitMapping.Name, stageDesc.Info.Name, err)
+				}
+
+				if isContentSame {
+					logboek.Context(ctx).Debug().LogF("Stage %s git mapping %s content is the same as in commit %s\n", stageDesc.Info.Name, gitMapping.Name, currentCommit)
+					continue
+				}
+			}
+
 			var commitToCheckAncestry string
 			if imageCommitInfo.VirtualMerge {
 				commitToCheckAncestry = imageCommitInfo.VirtualMergeFromCommit
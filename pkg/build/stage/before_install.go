@@ -2,10 +2,13 @@ package stage
 
 import (
 	"context"
+	"fmt"
 
+	"github.com/werf/common-go/pkg/util"
 	"github.com/werf/werf/v2/pkg/build/builder"
 	"github.com/werf/werf/v2/pkg/config"
 	"github.com/werf/werf/v2/pkg/container_backend"
+	"github.com/werf/werf/v2/pkg/git_repo"
 )
 
 func GenerateBeforeInstallStage(ctx context.Context, imageBaseConfig *config.StapelImageBase, baseStageOptions *BaseStageOptions) *BeforeInstallStage {
@@ -28,7 +31,17 @@ type BeforeInstallStage struct {
 }
 
 func (s *BeforeInstallStage) GetDependencies(ctx context.Context, c Conveyor, cb container_backend.ContainerBackend, prevImage, prevBuiltImage *StageImage, buildContextArchive container_backend.BuildContextArchiver) (string, error) {
-	return s.builder.BeforeInstallChecksum(ctx), nil
+	// Keep digests of existing stages without stage dependencies unchanged.
+	if !s.hasStageDependencies(BeforeInstall) {
+		return s.builder.BeforeInstallChecksum(ctx), nil
+	}
+
+	stageDependenciesChecksum, err := s.getStageDependenciesChecksum(ctx, c, BeforeInstall)
+	if err != nil {
+		return "", err
+	}
+
+	return util.Sha256Hash(s.builder.BeforeInstallChecksum(ctx), stageDependenciesChecksum), nil
 }
 
 func (s *BeforeInstallStage) PrepareImage(ctx context.Context, c Conveyor, cb container_backend.ContainerBackend, prevBuiltImage, stageImage *StageImage, buildContextArchive container_backend.BuildContextArchiver) error {
@@ -36,6 +49,21 @@ func (s *BeforeInstallStage) PrepareImage(ctx context.Context, c Conveyor, cb co
 		return err
 	}
 
+	// The beforeInstall stage is built before the gitArchive stage, so add the dependency files explicitly.
+	if s.hasStageDependencies(BeforeInstall) {
+		for _, gitMapping := range s.gitMappings {
+			if err := gitMapping.PrepareStageDependenciesArchiveForImage(ctx, c, cb, stageImage, BeforeInstall); err != nil {
+				return fmt.Errorf("unable to prepare git mapping %s stage dependencies for image stage: %w", gitMapping.Name, err)
+			}
+		}
+
+		if c.UseLegacyStapelBuilder(cb) {
+			gitMapping := s.gitMappings[0]
+			stageImage.Builder.LegacyStapelStageBuilder().Container().RunOptions().AddVolume(fmt.Sprintf("%s:%s:ro", git_repo.CommonGitDataManager.GetArchivesCacheDir(), gitMapping.ContainerArchivesDir))
+			stageImage.Builder.LegacyStapelStageBuilder().Container().RunOptions().AddVolume(fmt.Sprintf("%s:%s:ro", gitMapping.ScriptsDir, gitMapping.ContainerScriptsDir))
+		}
+	}
+
 	if err := s.builder.BeforeInstall(ctx, cb, stageImage.Builder, c.UseLegacyStapelBuilder(cb)); err != nil {
 		return err
 	}
@@ -301,19 +301,9 @@ func (s *FullDockerfileStage) FetchDependencies(_ context.Context, _ Conveyor, _
 func (s *FullDockerfileStage) GetDependencies(ctx context.Context, c Conveyor, _ container_backend.ContainerBackend, _, _ *StageImage, _ container_backend.BuildContextArchiver) (string, error) {
 	resolvedDependenciesArgsHash := ResolveDependenciesArgs(s.targetPlatform, s.dependencies, c)
 
-	var resolvedDockerMetaArgsHash map[string]string
-	{
-		metaArgs, err := s.resolveDockerMetaArgs(resolvedDependenciesArgsHash)
-		if err != nil {
-			return "", fmt.Errorf("unable to resolve docker meta args: %w", err)
-		}
-
-		platformMetaArgs, err := platformutil.GetPlatformMetaArgsMap(s.targetPlatform)
-		if err != nil {
-			return "", fmt.Errorf("unable to get platform args: %w", err)
-		}
-
-		resolvedDockerMetaArgsHash = util.MergeMaps(platformMetaArgs, metaArgs)
+	resolvedDockerMetaArgsHash, err := s.resolveDockerMetaArgsWithPlatformArgs(resolvedDependenciesArgsHash)
+	if err != nil {
+		return "", err
 	}
 
 	var stagesDependencies [][]string
@@ -394,6 +384,58 @@ func (s *FullDockerfileStage) GetDependencies(ctx context.Context, c Conveyor, _
 	return util.Sha256Hash(dependencies...), nil
 }
 
+// GetBaseImageReferences returns the resolved references of the registry images used in FROM instructions of the
+// dockerfile stages up to the target one. The scratch image and the references to other stages are skipped.
+func (s *FullDockerfileStage) GetBaseImageReferences(c Conveyor) ([]string, error) {
+	resolvedDependenciesArgsHash := ResolveDependenciesArgs(s.targetPlatform, s.dependencies, c)
+
+	resolvedDockerMetaArgsHash, err := s.resolveDockerMetaArgsWithPlatformArgs(resolvedDependenciesArgsHash)
+	if err != nil {
+		return nil, err
+	}
+
+	var res []string
+	for ind, stage := range s.dockerStages[:s.dockerTargetStageIndex+1] {
+		isStageRef := slices.ContainsFunc(s.dockerStages[:ind], func(relatedStage instructions.Stage) bool {
+			return stage.BaseName == relatedStage.Name
+		})
+		if isStageRef {
+			continue
+		}
+
+		resolvedBaseName, err := s.ShlexProcessWordWithMetaArgs(stage.BaseName, resolvedDockerMetaArgsHash)
+		if err != nil {
+			return nil, err
+		}
+
+		if resolvedBaseName == "" {
+			return nil, ErrInvalidBaseImage
+		}
+
+		if resolvedBaseName == "scratch" || slices.Contains(res, resolvedBaseName) {
+			continue
+		}
+
+		res = append(res, resolvedBaseName)
+	}
+
+	return res, nil
+}
+
+func (s *FullDockerfileStage) resolveDockerMetaArgsWithPlatformArgs(resolvedDependenciesArgsHash map[string]string) (map[string]string, error) {
+	metaArgs, err := s.resolveDockerMetaArgs(resolvedDependenciesArgsHash)
+	if err != nil {
+		return nil, fmt.Errorf("unable to resolve docker meta args: %w", err)
+	}
+
+	platformMetaArgs, err := platformutil.GetPlatformMetaArgsMap(s.targetPlatform)
+	if err != nil {
+		return nil, fmt.Errorf("unable to get platform args: %w", err)
+	}
+
+	return util.MergeMaps(platformMetaArgs, metaArgs), nil
+}
+
 func (s *FullDockerfileStage) MutateImage(_ context.Context, _ ImageMutatorPusher, _, _ *StageImage) error {
 	panic("not implemented")
 }
@@ -380,6 +380,35 @@ RUN --mount=type=bind,from=build,source=/usr/local/test_project/dist,target=/usr
 			}
 		})
 	})
+
+	When("Dockerfile has multiple stages", func() {
+		It("should return the registry base images of the stages up to the target one", func() {
+			dockerfile := []byte(`
+ARG BASE_IMAGE=alpine:latest
+
+FROM ${BASE_IMAGE} AS build
+RUN echo hello
+
+FROM scratch AS assets
+COPY --from=build /hello /hello
+
+FROM build AS app
+COPY --from=assets /hello /hello
+
+FROM ubuntu:22.04 AS debug
+`)
+
+			conveyor := NewConveyorStubForDependencies(NewGiterminismManagerStub(NewLocalGitRepoStub("9d8059842b6fde712c58315ca0ab4713d90761c0"), NewGiterminismInspectorStub()), nil)
+
+			dockerStages, dockerMetaArgs := testDockerfileToDockerStages(dockerfile)
+
+			stage := newTestFullDockerfileStage(dockerfile, "app", map[string]interface{}{"BASE_IMAGE": "registry.example.com/alpine:3.20"}, dockerStages, dockerMetaArgs, nil, "")
+
+			refs, err := stage.GetBaseImageReferences(conveyor)
+			Expect(err).To(Succeed())
+			Expect(refs).To(Equal([]string{"registry.example.com/alpine:3.20"}))
+		})
+	})
 })
 
 type TestDockerfileDependencies struct {
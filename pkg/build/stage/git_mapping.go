@@ -20,6 +20,7 @@ import (
 
 	"github.com/werf/common-go/pkg/util"
 	"github.com/werf/logboek"
+	"github.com/werf/werf/v2/pkg/config"
 	"github.com/werf/werf/v2/pkg/container_backend"
 	"github.com/werf/werf/v2/pkg/git_repo"
 	"github.com/werf/werf/v2/pkg/path_matcher"
@@ -39,6 +40,7 @@ type GitMapping struct {
 	IncludePaths       []string
 	ExcludePaths       []string
 	StagesDependencies map[StageName][]string
+	CacheMode          config.GitCacheMode
 
 	ContainerPatchesDir  string
 	ContainerArchivesDir string
@@ -101,6 +103,10 @@ func (gm *GitMapping) GitRepo() git_repo.GitRepo {
 }
 
 func (gm *GitMapping) makeArchiveOptions(ctx context.Context, commit string) (*git_repo.ArchiveOptions, error) {
+	return gm.makeArchiveOptionsWithPathMatcher(ctx, commit, gm.getPathMatcher())
+}
+
+func (gm *GitMapping) makeArchiveOptionsWithPathMatcher(ctx context.Context, commit string, pathMatcher path_matcher.PathMatcher) (*git_repo.ArchiveOptions, error) {
 	fileRenames, err := gm.getFileRenames(ctx, commit)
 	if err != nil {
 		return nil, fmt.Errorf("unable to make git archive options: %w", err)
@@ -113,7 +119,7 @@ func (gm *GitMapping) makeArchiveOptions(ctx context.Context, commit string) (*g
 
 	return &git_repo.ArchiveOptions{
 		PathScope:   pathScope,
-		PathMatcher: gm.getPathMatcher(),
+		PathMatcher: pathMatcher,
 		Commit:      commit,
 		FileRenames: fileRenames,
 		Owner:       gm.Owner,
@@ -305,10 +311,18 @@ func (gm *GitMapping) GetLatestCommitInfo(ctx context.Context, c Conveyor) (Imag
 	return res, nil
 }
 
-func (gm *GitMapping) AddGitCommitToImageLabels(ctx context.Context, c Conveyor, cb container_backend.ContainerBackend, stageImage *StageImage, commitInfo ImageCommitInfo) {
+func (gm *GitMapping) AddGitCommitToImageLabels(ctx context.Context, c Conveyor, cb container_backend.ContainerBackend, stageImage *StageImage, commitInfo ImageCommitInfo) error {
 	addLabels := make(map[string]string)
 	addLabels[gm.ImageGitCommitLabel()] = commitInfo.Commit
 
+	if gm.CacheMode == config.GitCacheModeContent {
+		contentChecksum, err := gm.getContentChecksum(ctx, commitInfo.Commit)
+		if err != nil {
+			return fmt.Errorf("unable to calculate content checksum for commit %s: %w", commitInfo.Commit, err)
+		}
+		addLabels[gm.ImageGitContentChecksumLabel()] = contentChecksum
+	}
+
 	if commitInfo.VirtualMerge {
 		addLabels[gm.VirtualMergeLabel()] = "true"
 		addLabels[gm.VirtualMergeFromCommitLabel()] = commitInfo.VirtualMergeFromCommit
@@ -324,6 +338,8 @@ func (gm *GitMapping) AddGitCommitToImageLabels(ctx context.Context, c Conveyor,
 			stageImage.Builder.StapelStageBuilder().AddLabels(addLabels)
 		}
 	}
+
+	return nil
 }
 
 func (gm *GitMapping) GetBaseCommitForPrevBuiltImage(ctx context.Context, c Conveyor, prevBuiltImage *StageImage) (string, error) {
@@ -339,6 +355,20 @@ func (gm *GitMapping) GetBaseCommitForPrevBuiltImage(ctx context.Context, c Conv
 		return "", fmt.Errorf("error getting prev built image %s commits info: %w", prevBuiltImage.Image.Name(), err)
 	}
 
+	if gm.CacheMode == config.GitCacheModeContent {
+		latestCommitInfo, isContentSame, err := gm.isLatestContentSameAsInBuiltImage(ctx, c, prevBuiltImage.Image.GetStageDesc().Info.Labels)
+		if err != nil {
+			return "", err
+		}
+
+		// The same content means that there is nothing to patch, so the latest commit can be used as the base one,
+		// even if the commit of the prev built image is not available anymore (e.g. after squash or rebase).
+		if isContentSame {
+			gm.BaseCommitByPrevBuiltImageName[prevBuiltImage.Image.Name()] = latestCommitInfo.Commit
+			return latestCommitInfo.Commit, nil
+		}
+	}
+
 	var baseCommit string
 	if prevBuiltImageCommitInfo.VirtualMerge {
 		if latestCommit, err := gm.getCommit(ctx); err != nil {
@@ -399,10 +429,44 @@ func (gm *GitMapping) GetBuiltImageCommitInfo(builtImageLabels map[string]string
 	return res, nil
 }
 
+func (gm *GitMapping) isLatestContentSameAsInBuiltImage(ctx context.Context, c Conveyor, builtImageLabels map[string]string) (ImageCommitInfo, bool, error) {
+	builtContentChecksum, hasKey := builtImageLabels[gm.ImageGitContentChecksumLabel()]
+	if !hasKey {
+		return ImageCommitInfo{}, false, nil
+	}
+
+	latestCommitInfo, err := gm.GetLatestCommitInfo(ctx, c)
+	if err != nil {
+		return ImageCommitInfo{}, false, fmt.Errorf("unable to get latest commit info: %w", err)
+	}
+
+	latestContentChecksum, err := gm.getContentChecksum(ctx, latestCommitInfo.Commit)
+	if err != nil {
+		return ImageCommitInfo{}, false, fmt.Errorf("unable to calculate content checksum for commit %s: %w", latestCommitInfo.Commit, err)
+	}
+
+	return latestCommitInfo, latestContentChecksum == builtContentChecksum, nil
+}
+
+func (gm *GitMapping) getContentChecksum(ctx context.Context, commit string) (string, error) {
+	return gm.GitRepo().GetOrCreateChecksum(ctx, git_repo.ChecksumOptions{
+		LsTreeOptions: git_repo.LsTreeOptions{
+			PathScope:   gm.Add,
+			PathMatcher: gm.getPathMatcher(),
+			AllFiles:    false,
+		},
+		Commit: commit,
+	})
+}
+
 func (gm *GitMapping) ImageGitCommitLabel() string {
 	return fmt.Sprintf("werf-git-%s-commit", gm.GetParamshash())
 }
 
+func (gm *GitMapping) ImageGitContentChecksumLabel() string {
+	return fmt.Sprintf("werf-git-%s-content-checksum", gm.GetParamshash())
+}
+
 func (gm *GitMapping) VirtualMergeLabel() string {
 	return fmt.Sprintf("werf-git-%s-virtual-merge", gm.GetParamshash())
 }
@@ -594,7 +658,7 @@ func (gm *GitMapping) PreparePatchForImage(ctx context.Context, c Conveyor, cb c
 			return err
 		}
 
-		if err := gm.applyScript(stageImage, commands); err != nil {
+		if err := gm.applyScript(stageImage, gm.GetParamshash(), commands); err != nil {
 			return err
 		}
 	} else {
@@ -667,9 +731,7 @@ func (gm *GitMapping) PreparePatchForImage(ctx context.Context, c Conveyor, cb c
 		}
 	}
 
-	gm.AddGitCommitToImageLabels(ctx, c, cb, stageImage, toCommitInfo)
-
-	return nil
+	return gm.AddGitCommitToImageLabels(ctx, c, cb, stageImage, toCommitInfo)
 }
 
 func filterTarArchive(ctx context.Context, in io.Reader, out io.Writer, includePaths []string) (resErr error) {
@@ -702,7 +764,7 @@ func (gm *GitMapping) PrepareArchiveForImage(ctx context.Context, c Conveyor, cb
 			return err
 		}
 
-		if err := gm.applyScript(stageImage, commands); err != nil {
+		if err := gm.applyScript(stageImage, gm.GetParamshash(), commands); err != nil {
 			return err
 		}
 	} else {
@@ -741,14 +803,84 @@ func (gm *GitMapping) PrepareArchiveForImage(ctx context.Context, c Conveyor, cb
 		})
 	}
 
-	gm.AddGitCommitToImageLabels(ctx, c, cb, stageImage, commitInfo)
+	return gm.AddGitCommitToImageLabels(ctx, c, cb, stageImage, commitInfo)
+}
+
+// PrepareStageDependenciesArchiveForImage adds files matched by the stage dependencies of the specified stage into the stage image.
+// It is used for the stages which are built before the gitArchive stage, so the dependency files are not available otherwise.
+func (gm *GitMapping) PrepareStageDependenciesArchiveForImage(ctx context.Context, c Conveyor, cb container_backend.ContainerBackend, stageImage *StageImage, stageName StageName) error {
+	if len(gm.StagesDependencies[stageName]) == 0 {
+		return nil
+	}
+
+	commitInfo, err := gm.GetLatestCommitInfo(ctx, c)
+	if err != nil {
+		return fmt.Errorf("unable to get latest commit info: %w", err)
+	}
+
+	archiveOpts, err := gm.makeArchiveOptionsWithPathMatcher(ctx, commitInfo.Commit, gm.getStageDependenciesPathMatcher(stageName))
+	if err != nil {
+		return err
+	}
+
+	archive, err := gm.GitRepo().GetOrCreateArchive(ctx, *archiveOpts)
+	if err != nil {
+		return fmt.Errorf("unable to create git archive for commit %s with path scope %s: %w", archiveOpts.Commit, archiveOpts.PathScope, err)
+	}
+
+	gitArchiveType, err := gm.getArchiveType(ctx, commitInfo.Commit)
+	if err != nil {
+		return fmt.Errorf("unable to determine git archive type: %w", err)
+	}
+
+	if c.UseLegacyStapelBuilder(cb) {
+		archiveFile, err := gm.prepareArchiveFile(archive)
+		if err != nil {
+			return fmt.Errorf("cannot prepare archive file: %w", err)
+		}
+
+		commands, err := gm.applyArchiveCommand(archiveFile, gitArchiveType)
+		if err != nil {
+			return err
+		}
+
+		return gm.applyScript(stageImage, fmt.Sprintf("%s-%s", gm.GetParamshash(), stageName), commands)
+	}
+
+	var archiveType container_backend.ArchiveType
+	switch gitArchiveType {
+	case git_repo.FileArchive:
+		archiveType = container_backend.FileArchive
+	case git_repo.DirectoryArchive:
+		archiveType = container_backend.DirectoryArchive
+	}
+
+	f, err := os.Open(archive.GetFilePath())
+	if err != nil {
+		return fmt.Errorf("unable to open archive file %q: %w", archive.GetFilePath(), err)
+	}
+
+	stageImage.Builder.StapelStageBuilder().AddDataArchive(f, archiveType, gm.To, container_backend.AddDataArchiveOptions{
+		Owner: gm.Owner,
+		Group: gm.Group,
+	})
 
 	return nil
 }
 
-func (gm *GitMapping) applyScript(stageImage *StageImage, commands []string) error {
-	stageHostTmpScriptFilePath := filepath.Join(gm.ScriptsDir, gm.GetParamshash())
-	containerTmpScriptFilePath := path.Join(gm.ContainerScriptsDir, gm.GetParamshash())
+func (gm *GitMapping) getStageDependenciesPathMatcher(stageName StageName) path_matcher.PathMatcher {
+	return path_matcher.NewMultiPathMatcher(
+		gm.getPathMatcher(),
+		path_matcher.NewPathMatcher(path_matcher.PathMatcherOptions{
+			BasePath:     gm.Add,
+			IncludeGlobs: gm.StagesDependencies[stageName],
+		}),
+	)
+}
+
+func (gm *GitMapping) applyScript(stageImage *StageImage, scriptName string, commands []string) error {
+	stageHostTmpScriptFilePath := filepath.Join(gm.ScriptsDir, scriptName)
+	containerTmpScriptFilePath := path.Join(gm.ContainerScriptsDir, scriptName)
 
 	if err := stapel.CreateScript(stageHostTmpScriptFilePath, commands); err != nil {
 		return err
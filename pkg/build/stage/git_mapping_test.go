@@ -8,6 +8,7 @@ import (
 	. "github.com/onsi/gomega"
 
 	"github.com/werf/werf/v2/pkg/build/stage"
+	"github.com/werf/werf/v2/pkg/config"
 	"github.com/werf/werf/v2/pkg/container_backend"
 	"github.com/werf/werf/v2/pkg/git_repo"
 	"github.com/werf/werf/v2/pkg/image"
@@ -231,6 +232,45 @@ var _ = Describe("GitMapping", func() {
 				Expect(baseCommit).To(Equal(data.BuiltCommitLabel))
 			}),
 	)
+
+	DescribeTable("getting base commit from prev built image in content cache mode",
+		func(ctx context.Context, builtContentChecksum, currentContentChecksum string, expectSameContent bool) {
+			ctx = logging.WithLogger(ctx)
+
+			builtCommit := "ae6feb44da273003cfada392c33dfe33748a5e2f"
+			currentCommit := "a4009816bf7d8f62b2d1cd3331d36f45b3df7d99"
+
+			c := NewConveyorStub(stage.VirtualMergeOptions{})
+			containerBackend := stage.NewContainerBackendStub()
+
+			gitRepo := NewGitRepoStub("own", true, currentCommit)
+			gitRepo.checksumByCommit = map[string]string{currentCommit: currentContentChecksum}
+			gitMapping.SetGitRepo(gitRepo)
+			gitMapping.CacheMode = config.GitCacheModeContent
+
+			prevBuiltImage := NewBuiltImageStub("stub", &image.StageDesc{
+				Info: &image.Info{
+					Labels: map[string]string{
+						gitMapping.ImageGitCommitLabel():          builtCommit,
+						gitMapping.VirtualMergeLabel():            "false",
+						gitMapping.ImageGitContentChecksumLabel(): builtContentChecksum,
+					},
+				},
+			})
+			img := stage.NewStageImage(containerBackend, "", prevBuiltImage)
+
+			baseCommit, err := gitMapping.GetBaseCommitForPrevBuiltImage(ctx, c, img)
+			Expect(err).To(Succeed())
+
+			if expectSameContent {
+				Expect(baseCommit).To(Equal(currentCommit))
+			} else {
+				Expect(baseCommit).To(Equal(builtCommit))
+			}
+		},
+		Entry("when content is the same", "checksum-1", "checksum-1", true),
+		Entry("when content is changed", "checksum-1", "checksum-2", false),
+	)
 })
 
 type BuiltImageStub struct {
@@ -274,9 +314,10 @@ func (c *ConveyorStub) GetLocalGitRepoVirtualMergeOptions() stage.VirtualMergeOp
 type GitRepoStub struct {
 	git_repo.GitRepo
 
-	isLocal        bool
-	name           string
-	headCommitHash string
+	isLocal          bool
+	name             string
+	headCommitHash   string
+	checksumByCommit map[string]string
 }
 
 func NewGitRepoStub(name string, isLocal bool, headCommitHash string) *GitRepoStub {
@@ -299,6 +340,10 @@ func (gitRepo *GitRepoStub) HeadCommitHash(_ context.Context) (string, error) {
 	return gitRepo.headCommitHash, nil
 }
 
+func (gitRepo *GitRepoStub) GetOrCreateChecksum(_ context.Context, opts git_repo.ChecksumOptions) (string, error) {
+	return gitRepo.checksumByCommit[opts.Commit], nil
+}
+
 func (gitRepo *GitRepoStub) CreateDetachedMergeCommit(ctx context.Context, fromCommit, toCommit string) (string, error) {
 	return constructVirtualMergeCommit(fromCommit, toCommit), nil
 }
@@ -3,7 +3,7 @@ package instruction
 import (
 	"context"
 	"fmt"
-	"strings"
+	"path/filepath"
 
 	"github.com/moby/buildkit/frontend/dockerfile/instructions"
 
@@ -17,10 +17,15 @@ import (
 
 type Add struct {
 	*Base[*instructions.AddCommand, *backend_instruction.Add]
+
+	downloadDir string
 }
 
 func NewAdd(i *dockerfile.DockerfileStageInstruction[*instructions.AddCommand], dependencies []*config.Dependency, hasPrevStage bool, opts *stage.BaseStageOptions) *Add {
-	return &Add{Base: NewBase(i, backend_instruction.NewAdd(*i.Data), dependencies, hasPrevStage, opts)}
+	return &Add{
+		Base:        NewBase(i, backend_instruction.NewAdd(*i.Data), dependencies, hasPrevStage, opts),
+		downloadDir: filepath.Join(opts.ImageTmpDir, "add-http-sources"),
+	}
 }
 
 func (stg *Add) ExpandDependencies(ctx context.Context, c stage.Conveyor, baseEnv map[string]string) error {
@@ -45,14 +50,7 @@ func (stg *Add) GetDependencies(ctx context.Context, c stage.Conveyor, cb contai
 	args = append(args, "Chown", stg.instruction.Data.Chown)
 	args = append(args, "Chmod", stg.instruction.Data.Chmod)
 
-	var fileGlobSrc []string
-	for _, src := range stg.instruction.Data.SourcePaths {
-		if !strings.HasPrefix(src, "http://") && !strings.HasPrefix(src, "https://") {
-			fileGlobSrc = append(fileGlobSrc, src)
-		}
-	}
-
-	if len(fileGlobSrc) > 0 {
+	if fileGlobSrc := stg.backendInstruction.LocalSourcePaths(); len(fileGlobSrc) > 0 {
 		if srcChecksum, err := buildContextArchive.CalculateGlobsChecksum(ctx, fileGlobSrc, true); err != nil {
 			return "", fmt.Errorf("unable to calculate build context globs checksum: %w", err)
 		} else {
@@ -60,10 +58,28 @@ func (stg *Add) GetDependencies(ctx context.Context, c stage.Conveyor, cb contai
 		}
 	}
 
-	// TODO(staged-dockerfile): support http src and --checksum option: https://docs.docker.com/engine/reference/builder/#verifying-a-remote-file-checksum-add---checksumchecksum-http-src-dest
-	// TODO(staged-dockerfile): support git ref: https://docs.docker.com/engine/reference/builder/#adding-a-git-repository-add-git-ref-dir
-	// TODO(staged-dockerfile): support --keep-git-dir for git: https://docs.docker.com/engine/reference/builder/#adding-a-git-repository-add-git-ref-dir
-	// TODO(staged-dockerfile): support --link
+	if len(stg.instruction.Data.ExcludePatterns) > 0 {
+		args = append(args, append([]string{"ExcludePatterns"}, stg.instruction.Data.ExcludePatterns...)...)
+	}
+
+	if stg.instruction.Data.KeepGitDir {
+		args = append(args, "KeepGitDir", "true")
+	}
+
+	if err := stg.backendInstruction.ResolveRemoteSources(ctx, backend_instruction.ResolveRemoteSourcesOptions{
+		DownloadDir: stg.downloadDir,
+	}); err != nil {
+		return "", fmt.Errorf("unable to resolve remote sources: %w", err)
+	}
+
+	for _, remoteSource := range stg.backendInstruction.RemoteSources {
+		switch remoteSource.Type {
+		case backend_instruction.AddRemoteSourceTypeHTTP:
+			args = append(args, "RemoteSourceChecksum", remoteSource.Source, remoteSource.Checksum.String())
+		case backend_instruction.AddRemoteSourceTypeGit:
+			args = append(args, "RemoteSourceCommit", remoteSource.Source, remoteSource.Commit)
+		}
+	}
 
 	return util.Sha256Hash(args...), nil
 }
@@ -195,4 +195,79 @@ var _ = DescribeTable("ADD digest",
 			},
 		},
 	)),
+
+	Entry("ADD with HTTP source and checksum", NewTestData(
+		instruction.NewAdd(
+			dockerfile.NewDockerfileStageInstruction(
+				&instructions.AddCommand{
+					SourcesAndDest: instructions.SourcesAndDest{
+						DestPath:    "/app/",
+						SourcePaths: []string{"https://example.com/file.tar.gz"},
+					},
+					Checksum: "sha256:24454f830cdb571e2c4ad15481119c43b3cafd48dd869a9b2945d1036d1dc68d",
+				},
+				dockerfile.DockerfileStageInstructionOptions{},
+			),
+			nil, false,
+			&stage.BaseStageOptions{
+				ImageName:   "example-image",
+				ProjectName: "example-project",
+			},
+		),
+		"dcf2259b9329f831ddfe723d19db239765ab7b516c054456776f5333540773a9",
+		TestDataOptions{},
+	)),
+
+	Entry("ADD with git source pinned to commit", NewTestData(
+		instruction.NewAdd(
+			dockerfile.NewDockerfileStageInstruction(
+				&instructions.AddCommand{
+					SourcesAndDest: instructions.SourcesAndDest{
+						DestPath:    "/app/",
+						SourcePaths: []string{"https://github.com/werf/werf.git#9d8059842b6fde712c58315ca0ab4713d90761c0:docs"},
+					},
+					KeepGitDir: true,
+				},
+				dockerfile.DockerfileStageInstructionOptions{},
+			),
+			nil, false,
+			&stage.BaseStageOptions{
+				ImageName:   "example-image",
+				ProjectName: "example-project",
+			},
+		),
+		"6aad3645ade7480e9b68d528a4b06c5d03513ea2ce70bde513ee06dff4c7539a",
+		TestDataOptions{},
+	)),
 )
+
+var _ = Describe("ADD digest", func() {
+	It("fails if --checksum is specified for the local source", func(ctx SpecContext) {
+		data := NewTestData(
+			instruction.NewAdd(
+				dockerfile.NewDockerfileStageInstruction(
+					&instructions.AddCommand{
+						SourcesAndDest: instructions.SourcesAndDest{
+							DestPath:    "/app/",
+							SourcePaths: []string{"file.tar.gz"},
+						},
+						Checksum: "sha256:24454f830cdb571e2c4ad15481119c43b3cafd48dd869a9b2945d1036d1dc68d",
+					},
+					dockerfile.DockerfileStageInstructionOptions{},
+				),
+				nil, false,
+				&stage.BaseStageOptions{
+					ImageName:   "example-image",
+					ProjectName: "example-project",
+				},
+			),
+			"",
+			TestDataOptions{
+				Files: []*FileData{{Name: "file.tar.gz", Data: []byte(`data`)}},
+			},
+		)
+
+		_, err := data.Stage.GetDependencies(ctx, data.Conveyor, data.ContainerBackend, nil, data.StageImage, data.BuildContext)
+		Expect(err).To(MatchError(ContainSubstring(`--checksum is supported only for HTTP sources, got source "file.tar.gz"`)))
+	})
+})
@@ -61,9 +61,15 @@ func (stg *Copy) GetDependencies(ctx context.Context, c stage.Conveyor, cb conta
 		}
 	}
 
-	// TODO(ilya-lesikov): should checksum of files from other image be calculated if --from specified?
+	if stg.instruction.Data.Parents {
+		args = append(args, "Parents", "true")
+	}
 
-	// TODO(staged-dockerfile): support --link option: https://docs.docker.com/engine/reference/builder/#copy---link
+	if len(stg.instruction.Data.ExcludePatterns) > 0 {
+		args = append(args, append([]string{"ExcludePatterns"}, stg.instruction.Data.ExcludePatterns...)...)
+	}
+
+	// TODO(ilya-lesikov): should checksum of files from other image be calculated if --from specified?
 
 	return util.Sha256Hash(args...), nil
 }
@@ -223,4 +223,33 @@ var _ = DescribeTable("COPY digest",
 			},
 		},
 	)),
+
+	Entry("COPY with parents and exclude patterns", NewTestData(
+		instruction.NewCopy(
+			dockerfile.NewDockerfileStageInstruction(
+				&instructions.CopyCommand{
+					SourcesAndDest: instructions.SourcesAndDest{
+						DestPath:    "/app",
+						SourcePaths: []string{"src/", "doc/"},
+					},
+					Parents:         true,
+					ExcludePatterns: []string{"*.cs"},
+				},
+				dockerfile.DockerfileStageInstructionOptions{},
+			),
+			nil, false,
+			&stage.BaseStageOptions{
+				ImageName:   "example-image",
+				ProjectName: "example-project",
+			},
+		),
+		"f9d0ef8308422947e8c0e0423f3f7e38a99f82df575e685ee87d6263eda4cb2b",
+		TestDataOptions{
+			Files: []*FileData{
+				{Name: "src/main/java/worker/Worker.java", Data: []byte(`package worker;`)},
+				{Name: "src/Worker/Program.cs", Data: []byte(`namespace Worker {}`)},
+				{Name: "doc/README.md", Data: []byte(`# README.md`)},
+			},
+		},
+	)),
 )
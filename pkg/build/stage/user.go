@@ -36,6 +36,16 @@ type UserStage struct {
 	builder builder.Builder
 }
 
+func (s *UserStage) hasStageDependencies(name StageName) bool {
+	for _, gitMapping := range s.gitMappings {
+		if len(gitMapping.StagesDependencies[name]) != 0 {
+			return true
+		}
+	}
+
+	return false
+}
+
 func (s *UserStage) getStageDependenciesChecksum(ctx context.Context, c Conveyor, name StageName) (string, error) {
 	var args []string
 	for _, gitMapping := range s.gitMappings {
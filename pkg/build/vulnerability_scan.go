@@ -0,0 +1,200 @@
+package build
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/werf/common-go/pkg/util"
+	"github.com/werf/logboek"
+	"github.com/werf/werf/v2/pkg/build/image"
+	"github.com/werf/werf/v2/pkg/storage"
+	"github.com/werf/werf/v2/pkg/vulnerability_scanner"
+	"github.com/werf/werf/v2/pkg/werf"
+)
+
+type VulnerabilityScanOptions struct {
+	// Scanner is the external scanner to run against the final images, the scan is disabled if empty.
+	Scanner vulnerability_scanner.ScannerType
+	// SeverityThreshold fails the build if the final image has vulnerabilities of this or higher severity, the
+	// build does not fail if empty.
+	SeverityThreshold vulnerability_scanner.Severity
+	// ReportFormat is the format the scanner report is requested and parsed in, JSON if empty.
+	ReportFormat vulnerability_scanner.ReportFormat
+	// CacheTTL is how long the stored scan result of the image that is not rebuilt is reused. The expired result is
+	// not used and the image is scanned again to take into account the updates of the scanner database.
+	CacheTTL time.Duration
+}
+
+// vulnerabilityScanResults collects the scan results of the images. It is shared by the clones of the build phase.
+type vulnerabilityScanResults struct {
+	mux     sync.Mutex
+	records map[*image.Image]*ReportVulnerabilityScanRecord
+}
+
+func newVulnerabilityScanResults() *vulnerabilityScanResults {
+	return &vulnerabilityScanResults{records: map[*image.Image]*ReportVulnerabilityScanRecord{}}
+}
+
+func (r *vulnerabilityScanResults) set(img *image.Image, record *ReportVulnerabilityScanRecord) {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+	r.records[img] = record
+}
+
+// get returns the merged scan record of the images (all platforms of the multiplatform image) or nil if no image is
+// scanned.
+func (r *vulnerabilityScanResults) get(images ...*image.Image) *ReportVulnerabilityScanRecord {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+
+	var merged *ReportVulnerabilityScanRecord
+	for _, img := range images {
+		record, ok := r.records[img]
+		if !ok {
+			continue
+		}
+
+		if merged == nil {
+			merged = &ReportVulnerabilityScanRecord{
+				Scanner:         record.Scanner,
+				Cached:          true,
+				Vulnerabilities: map[vulnerability_scanner.Severity]int{},
+			}
+		}
+
+		merged.Cached = merged.Cached && record.Cached
+		for severity, count := range record.Vulnerabilities {
+			merged.Vulnerabilities[severity] += count
+		}
+	}
+
+	return merged
+}
+
+// scanFinalImage runs the configured scanner against the final image published to the repo. The result for the image
+// that is not rebuilt is taken from the stages storage if the image with the same digest has been scanned within the
+// cache TTL.
+func (phase *BuildPhase) scanFinalImage(ctx context.Context, img *image.Image) error {
+	scanner := phase.VulnerabilityScanOptions.Scanner
+	if scanner == "" || !img.IsFinal || phase.ShouldBeBuiltMode {
+		return nil
+	}
+
+	stageImage := img.GetLastNonEmptyStage().GetStageImage().Image
+	desc := stageImage.GetFinalStageDesc()
+	if desc == nil {
+		desc = stageImage.GetStageDesc()
+	}
+	imageDigest := desc.Info.GetDigest()
+
+	stagesStorage := phase.Conveyor.StorageManager.GetStagesStorage()
+	projectName := phase.Conveyor.ProjectName()
+
+	if !img.GetRebuilt() && imageDigest != "" {
+		metadata, err := stagesStorage.GetVulnerabilityScanMetadata(ctx, projectName, scanner, imageDigest)
+		switch {
+		case storage.IsErrVulnerabilityScanMetadataNotFound(err):
+		case err != nil:
+			return fmt.Errorf("unable to get vulnerability scan metadata of image %s: %w", img.LogName(), err)
+		case time.Since(metadata.ScannedAt) >= phase.VulnerabilityScanOptions.CacheTTL:
+			logboek.Context(ctx).Info().LogF("Cached vulnerability scan result of image %s is expired, the image will be scanned again\n", img.LogName())
+		default:
+			logboek.Context(ctx).Default().LogF("Using cached vulnerability scan result of image %s: %s\n", img.LogName(), formatVulnerabilities(metadata.Summary.Vulnerabilities))
+			phase.vulnerabilityScanResults.set(img, newReportVulnerabilityScanRecord(metadata.Summary, true))
+			return nil
+		}
+	}
+
+	var summary *vulnerability_scanner.Summary
+	if err := logboek.Context(ctx).Default().LogProcess("Scanning image %s for vulnerabilities with %s", img.LogName(), scanner).DoError(func() error {
+		layoutDir, err := os.MkdirTemp(werf.GetTmpDir(), "werf-vulnerability-scan-")
+		if err != nil {
+			return fmt.Errorf("unable to create tmp dir: %w", err)
+		}
+		defer os.RemoveAll(layoutDir)
+
+		if err := stagesStorage.WriteStageToOCILayout(ctx, desc, layoutDir, desc.Info.Tag); err != nil {
+			return fmt.Errorf("unable to write image %s into OCI image layout: %w", desc.Info.Name, err)
+		}
+
+		summary, err = vulnerability_scanner.Scan(ctx, scanner, vulnerability_scanner.ScanOptions{
+			ImageRef:     desc.Info.Name,
+			OCILayoutDir: layoutDir,
+			ReportFormat: phase.VulnerabilityScanOptions.ReportFormat,
+		})
+		if err != nil {
+			return err
+		}
+
+		logboek.Context(ctx).Default().LogF("Found vulnerabilities: %s\n", formatVulnerabilities(summary.Vulnerabilities))
+
+		return nil
+	}); err != nil {
+		return fmt.Errorf("unable to scan image %s for vulnerabilities: %w", img.LogName(), err)
+	}
+
+	if imageDigest != "" {
+		if err := stagesStorage.PutVulnerabilityScanMetadata(ctx, projectName, &storage.VulnerabilityScanMetadata{
+			ImageDigest: imageDigest,
+			Scanner:     scanner,
+			Summary:     summary,
+			ScannedAt:   time.Now(),
+		}); err != nil {
+			return fmt.Errorf("unable to put vulnerability scan metadata of image %s: %w", img.LogName(), err)
+		}
+	}
+
+	phase.vulnerabilityScanResults.set(img, newReportVulnerabilityScanRecord(summary, false))
+
+	return nil
+}
+
+// checkVulnerabilityScanThreshold fails if any scanned image has vulnerabilities of the threshold or higher severity.
+func (phase *BuildPhase) checkVulnerabilityScanThreshold(imagesPairs []util.Pair[string, []*image.Image]) error {
+	threshold := phase.VulnerabilityScanOptions.SeverityThreshold
+	if phase.VulnerabilityScanOptions.Scanner == "" || threshold == "" {
+		return nil
+	}
+
+	var violations []string
+	for _, pair := range imagesPairs {
+		_, images := pair.Unpair()
+		for _, img := range images {
+			record := phase.vulnerabilityScanResults.get(img)
+			if record == nil {
+				continue
+			}
+
+			summary := &vulnerability_scanner.Summary{Vulnerabilities: record.Vulnerabilities}
+			if count := summary.CountAtLeast(threshold); count > 0 {
+				violations = append(violations, fmt.Sprintf("%s: %d", img.LogName(), count))
+			}
+		}
+	}
+
+	if len(violations) > 0 {
+		return fmt.Errorf("vulnerabilities of %s or higher severity found in images:\n%s", threshold, strings.Join(violations, "\n"))
+	}
+
+	return nil
+}
+
+func formatVulnerabilities(vulnerabilities map[vulnerability_scanner.Severity]int) string {
+	var parts []string
+	for i := len(vulnerability_scanner.Severities) - 1; i >= 0; i-- {
+		severity := vulnerability_scanner.Severities[i]
+		if count := vulnerabilities[severity]; count > 0 {
+			parts = append(parts, fmt.Sprintf("%s %d", severity, count))
+		}
+	}
+
+	if len(parts) == 0 {
+		return "none"
+	}
+
+	return strings.Join(parts, ", ")
+}
@@ -9,6 +9,7 @@ import (
 	"path/filepath"
 	"runtime"
 	"strings"
+	"time"
 
 	"github.com/moby/buildkit/frontend/dockerfile/instructions"
 	"github.com/opencontainers/runtime-spec/specs-go"
@@ -17,6 +18,7 @@ import (
 	"github.com/werf/werf/v2/pkg/buildah/thirdparty"
 	"github.com/werf/werf/v2/pkg/container_backend/filter"
 	"github.com/werf/werf/v2/pkg/container_backend/info"
+	"github.com/werf/werf/v2/pkg/docker_registry/credhelper"
 	"github.com/werf/werf/v2/pkg/image"
 	"github.com/werf/werf/v2/pkg/werf"
 )
@@ -87,6 +89,12 @@ type RunCommandOpts struct {
 	GlobalMounts []*specs.Mount
 	// Mounts as allowed in Dockerfile RUN --mount option. Have more restrictions than GlobalMounts (e.g. Source of bind-mount can't be outside of ContextDir or container root).
 	RunMounts []*instructions.Mount
+	// Interactive attaches the command to the stdin, stdout and stderr of the current process and allocates the terminal (e.g. for the stage introspection).
+	Interactive bool
+	// Stdin, Stdout and Stderr attach the command to the given streams without the terminal. Ignored in the Interactive mode.
+	Stdin  io.Reader
+	Stdout io.Writer
+	Stderr io.Writer
 }
 
 type RmiOpts struct {
@@ -112,6 +120,19 @@ type PruneImagesReport struct {
 	SpaceReclaimed uint64
 }
 
+type PruneLayersOptions struct {
+	CommonOpts
+
+	// Until keeps the layers created within the specified period, because the layer is created
+	// before the image or the container referring to it.
+	Until time.Duration
+}
+
+type PruneLayersReport struct {
+	ItemsDeleted   []string
+	SpaceReclaimed uint64
+}
+
 type ConfigOpts struct {
 	CommonOpts
 
@@ -151,6 +172,8 @@ type AddOpts struct {
 	Chown      string
 	Chmod      string
 	Ignores    []string
+	// Checksum is the expected digest of the remote source content.
+	Checksum string
 }
 
 type ImagesOptions struct {
@@ -197,6 +220,7 @@ type Buildah interface {
 	Images(ctx context.Context, opts ImagesOptions) (image.ImagesList, error)
 	Containers(ctx context.Context, opts ContainersOptions) (image.ContainerList, error)
 	PruneImages(ctx context.Context, opts PruneImagesOptions) (PruneImagesReport, error)
+	PruneLayers(ctx context.Context, opts PruneLayersOptions) (PruneLayersReport, error)
 	SaveImageToStream(ctx context.Context, imageName string) (io.ReadCloser, error)
 	LoadImageFromStream(ctx context.Context, input io.Reader) (string, error)
 }
@@ -217,6 +241,8 @@ type CommonBuildahOpts struct {
 	RegistryMirrors              []string
 	InsecureRegistries           []string
 	StandaloneInsecureRegistries []string
+	// CredentialsKeychain resolves the credentials of the registries with the configured credential helpers for pull and push.
+	CredentialsKeychain *credhelper.Keychain
 }
 
 type NativeModeOpts struct {
@@ -13,6 +13,7 @@ import (
 	"io/ioutil"
 	"math/rand"
 	"os"
+	"os/exec"
 	"path/filepath"
 	"sort"
 	"strconv"
@@ -29,6 +30,7 @@ import (
 	"github.com/containers/buildah/pkg/parse"
 	"github.com/containers/buildah/pkg/sshagent"
 	"github.com/containers/common/libimage"
+	"github.com/containers/image/v5/docker/reference"
 	"github.com/containers/image/v5/manifest"
 	imgstor "github.com/containers/image/v5/storage"
 	storageTransport "github.com/containers/image/v5/storage"
@@ -49,8 +51,11 @@ import (
 	"github.com/werf/werf/v2/pkg/buildah/thirdparty"
 	"github.com/werf/werf/v2/pkg/container_backend/filter"
 	"github.com/werf/werf/v2/pkg/container_backend/info"
+	"github.com/werf/werf/v2/pkg/docker_registry/credhelper"
+	"github.com/werf/werf/v2/pkg/docker_registry/mirror"
 	"github.com/werf/werf/v2/pkg/image"
 	"github.com/werf/werf/v2/pkg/ssh_agent"
+	werfExec "github.com/werf/werf/v2/pkg/werf/exec"
 )
 
 const (
@@ -74,16 +79,19 @@ func NativeProcessStartupHook() bool {
 }
 
 type NativeBuildah struct {
-	Isolation               thirdparty.Isolation
-	TmpDir                  string
-	InstanceTmpDir          string
-	ConfigTmpDir            string
-	SignaturePolicyPath     string
-	ContainersConfigPath    string
-	RegistriesConfigPath    string
-	RegistriesConfigDirPath string
-	Insecure                bool
-	InsecureRegistries      []string
+	Isolation            thirdparty.Isolation
+	TmpDir               string
+	InstanceTmpDir       string
+	ConfigTmpDir         string
+	SignaturePolicyPath  string
+	ContainersConfigPath string
+	RegistriesConfigPath string
+	// PullRegistriesConfigPath is used for the explicit pulls, which are made with failover between mirrors by the container backend.
+	PullRegistriesConfigPath string
+	RegistriesConfigDirPath  string
+	Insecure                 bool
+	InsecureRegistries       []string
+	CredentialsKeychain      *credhelper.Keychain
 
 	Store storage.Store
 
@@ -97,15 +105,17 @@ type NativeBuildah struct {
 func (b *NativeBuildah) Info(ctx context.Context) (info.Info, error) {
 	return info.Info{
 		StoreGraphRoot: b.Store.GraphRoot(),
+		StoreDriver:    b.Store.GraphDriverName(),
 	}, nil
 }
 
 func NewNativeBuildah(commonOpts CommonBuildahOpts, opts NativeModeOpts) (*NativeBuildah, error) {
 	b := &NativeBuildah{
-		Isolation:          *commonOpts.Isolation,
-		TmpDir:             commonOpts.TmpDir,
-		Insecure:           commonOpts.Insecure,
-		InsecureRegistries: commonOpts.InsecureRegistries,
+		Isolation:           *commonOpts.Isolation,
+		TmpDir:              commonOpts.TmpDir,
+		Insecure:            commonOpts.Insecure,
+		InsecureRegistries:  commonOpts.InsecureRegistries,
+		CredentialsKeychain: commonOpts.CredentialsKeychain,
 	}
 
 	if err := os.MkdirAll(b.TmpDir, os.ModePerm); err != nil {
@@ -147,6 +157,16 @@ func NewNativeBuildah(commonOpts CommonBuildahOpts, opts NativeModeOpts) (*Nativ
 		return nil, fmt.Errorf("unable to write file %q: %w", b.RegistriesConfigPath, err)
 	}
 
+	pullRegistriesConfig, err := generatePullRegistriesConfig(commonOpts.RegistryMirrors, commonOpts.InsecureRegistries, commonOpts.StandaloneInsecureRegistries)
+	if err != nil {
+		return nil, fmt.Errorf("unable to generate pull registries config: %w", err)
+	}
+
+	b.PullRegistriesConfigPath = filepath.Join(b.ConfigTmpDir, "registries-pull.conf")
+	if err := ioutil.WriteFile(b.PullRegistriesConfigPath, []byte(pullRegistriesConfig), os.ModePerm); err != nil {
+		return nil, fmt.Errorf("unable to write file %q: %w", b.PullRegistriesConfigPath, err)
+	}
+
 	b.RegistriesConfigDirPath = filepath.Join(b.ConfigTmpDir, "registries.conf.d")
 	if err := os.MkdirAll(b.RegistriesConfigDirPath, os.ModePerm); err != nil {
 		return nil, fmt.Errorf("unable to create dir %q: %w", b.RegistriesConfigDirPath, err)
@@ -236,12 +256,42 @@ func (b *NativeBuildah) getSystemContext(targetPlatform string) (*imgtypes.Syste
 	return systemContext, nil
 }
 
+// setRegistryCredentials sets the credentials of the reference registry, if the registry has the credential helper.
+// The credentials are resolved on each pull and push, so the short-lived token does not expire during the long build.
+func (b *NativeBuildah) setRegistryCredentials(sysCtx *imgtypes.SystemContext, ref string) error {
+	named, err := reference.ParseNormalizedNamed(ref)
+	if err != nil {
+		return fmt.Errorf("unable to parse reference %q: %w", ref, err)
+	}
+
+	cfg, found, err := b.CredentialsKeychain.Get(reference.Domain(named))
+	if err != nil {
+		return err
+	}
+
+	if found {
+		sysCtx.DockerAuthConfig = &imgtypes.DockerAuthConfig{
+			Username:      cfg.Username,
+			Password:      cfg.Password,
+			IdentityToken: cfg.IdentityToken,
+		}
+	}
+
+	return nil
+}
+
 func generateRegistriesConfig(mirrors, insecureRegistries, standaloneInsecureRegistries []string) (string, error) {
 	type mirrorEntry struct {
 		Location string
 		Insecure bool
 	}
 
+	type registryEntry struct {
+		Registry string
+		Insecure bool
+		Mirrors  []mirrorEntry
+	}
+
 	insecureHosts := make(map[string]bool, len(insecureRegistries))
 	for _, h := range insecureRegistries {
 		h = strings.TrimPrefix(h, "https://")
@@ -251,20 +301,32 @@ func generateRegistriesConfig(mirrors, insecureRegistries, standaloneInsecureReg
 		}
 	}
 
+	parsedMirrors, err := mirror.ParseList(mirrors)
+	if err != nil {
+		return "", err
+	}
+
+	dockerHub := &registryEntry{Registry: mirror.DockerHubRegistry}
+	var otherRegistries []*registryEntry
+	registryByName := map[string]*registryEntry{mirror.DockerHubRegistry: dockerHub}
+
 	mirrorSeen := make(map[string]bool)
-	var mrs []mirrorEntry
-	for _, mirror := range mirrors {
-		isHTTP := strings.HasPrefix(mirror, "http://")
-		mirror = strings.TrimPrefix(mirror, "https://")
-		mirror = strings.TrimPrefix(mirror, "http://")
-		mirror = strings.TrimSuffix(mirror, "/")
-		if mirror == "" || mirrorSeen[mirror] {
+	for _, m := range parsedMirrors {
+		if mirrorSeen[m.Registry+"="+m.Location] {
 			continue
 		}
-		mirrorSeen[mirror] = true
-		mrs = append(mrs, mirrorEntry{
-			Location: mirror,
-			Insecure: isHTTP || insecureHosts[mirror],
+		mirrorSeen[m.Registry+"="+m.Location] = true
+
+		reg, ok := registryByName[m.Registry]
+		if !ok {
+			reg = &registryEntry{Registry: m.Registry}
+			registryByName[m.Registry] = reg
+			otherRegistries = append(otherRegistries, reg)
+		}
+
+		reg.Mirrors = append(reg.Mirrors, mirrorEntry{
+			Location: m.Location,
+			Insecure: m.Insecure || insecureHosts[m.Location],
 		})
 	}
 
@@ -274,15 +336,24 @@ func generateRegistriesConfig(mirrors, insecureRegistries, standaloneInsecureReg
 		host = strings.TrimPrefix(host, "https://")
 		host = strings.TrimPrefix(host, "http://")
 		host = strings.TrimSuffix(host, "/")
-		if host != "" && !standaloneSeen[host] {
-			standaloneSeen[host] = true
-			standaloneInsecure = append(standaloneInsecure, host)
+		if host == "" || standaloneSeen[host] {
+			continue
+		}
+		standaloneSeen[host] = true
+
+		// The registry with mirrors already has its own entry, which cannot be duplicated.
+		if reg, ok := registryByName[host]; ok && reg != dockerHub {
+			reg.Insecure = true
+			continue
 		}
+
+		standaloneInsecure = append(standaloneInsecure, host)
 	}
 	sort.Strings(standaloneInsecure)
 
 	type templateData struct {
 		Mirrors            []mirrorEntry
+		Registries         []*registryEntry
 		InsecureRegistries []string
 	}
 
@@ -298,6 +369,19 @@ location = "docker.io"
 location = "{{ .Location }}"
 insecure = {{ .Insecure }}
 
+{{ end -}}
+{{ range .Registries -}}
+[[registry]]
+prefix = "{{ .Registry }}"
+location = "{{ .Registry }}"
+insecure = {{ .Insecure }}
+
+{{ range .Mirrors -}}
+[[registry.mirror]]
+location = "{{ .Location }}"
+insecure = {{ .Insecure }}
+
+{{ end -}}
 {{ end -}}
 {{ range .InsecureRegistries -}}
 [[registry]]
@@ -312,13 +396,37 @@ insecure = true
 	}
 
 	var result bytes.Buffer
-	if err = tmpl.Execute(&result, templateData{Mirrors: mrs, InsecureRegistries: standaloneInsecure}); err != nil {
+	if err = tmpl.Execute(&result, templateData{Mirrors: dockerHub.Mirrors, Registries: otherRegistries, InsecureRegistries: standaloneInsecure}); err != nil {
 		return "", err
 	}
 
 	return result.String(), nil
 }
 
+// generatePullRegistriesConfig generates the registries config without mirrors,
+// because the mirrors are tried by the container backend itself when pulling the image.
+// The insecure mirrors are added as the standalone insecure registries to be pulled explicitly.
+func generatePullRegistriesConfig(mirrors, insecureRegistries, standaloneInsecureRegistries []string) (string, error) {
+	parsedMirrors, err := mirror.ParseList(mirrors)
+	if err != nil {
+		return "", err
+	}
+
+	insecureHosts := make(map[string]bool, len(insecureRegistries))
+	for _, h := range insecureRegistries {
+		insecureHosts[strings.TrimPrefix(strings.TrimPrefix(h, "https://"), "http://")] = true
+	}
+
+	standalone := append([]string{}, standaloneInsecureRegistries...)
+	for _, m := range parsedMirrors {
+		if m.Insecure || insecureHosts[m.Location] || insecureHosts[m.Host()] {
+			standalone = append(standalone, m.Host())
+		}
+	}
+
+	return generateRegistriesConfig(nil, insecureRegistries, standalone)
+}
+
 func (b *NativeBuildah) getRuntime(systemContext *imgtypes.SystemContext) (*libimage.Runtime, error) {
 	return libimage.RuntimeFromStore(b.Store, &libimage.RuntimeOptions{
 		SystemContext: systemContext,
@@ -368,6 +476,10 @@ func (b *NativeBuildah) Push(ctx context.Context, ref string, opts PushOpts) err
 		return err
 	}
 
+	if err := b.setRegistryCredentials(sysCtx, ref); err != nil {
+		return err
+	}
+
 	pushOpts := buildah.PushOptions{
 		Compression:         define.Gzip,
 		SignaturePolicyPath: b.SignaturePolicyPath,
@@ -543,8 +655,36 @@ func (b *NativeBuildah) RunCommand(ctx context.Context, container string, comman
 		SSHSources:       sshSources,
 	}
 
+	if opts.Interactive {
+		runOpts.Stdin = os.Stdin
+		runOpts.Stdout = os.Stdout
+		runOpts.Stderr = os.Stderr
+		runOpts.Terminal = buildah.WithTerminal
+	} else {
+		if opts.Stdin != nil {
+			runOpts.Stdin = opts.Stdin
+		}
+		if opts.Stdout != nil {
+			runOpts.Stdout = opts.Stdout
+		}
+		if opts.Stderr != nil {
+			runOpts.Stderr = opts.Stderr
+		}
+		if opts.Stdin != nil || opts.Stdout != nil || opts.Stderr != nil {
+			runOpts.Terminal = buildah.WithoutTerminal
+		}
+	}
+
 	if err := builder.Run(command, runOpts); err != nil {
-		return fmt.Errorf("RunCommand failed:\n%s\n%w", stderrBuf.String(), err)
+		err = fmt.Errorf("RunCommand failed:\n%s\n%w", stderrBuf.String(), err)
+
+		// The runtime subprocess exits with the exit code of the command.
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			return werfExec.NewExitCodeError(exitErr.ExitCode(), err)
+		}
+
+		return err
 	}
 
 	return nil
@@ -582,6 +722,11 @@ func (b *NativeBuildah) Pull(ctx context.Context, ref string, opts PullOpts) (st
 	if err != nil {
 		return "", err
 	}
+	sysCtx.SystemRegistriesConfPath = b.PullRegistriesConfigPath
+
+	if err := b.setRegistryCredentials(sysCtx, ref); err != nil {
+		return "", err
+	}
 
 	pullOpts := buildah.PullOptions{
 		SignaturePolicyPath: b.SignaturePolicyPath,
@@ -685,6 +830,91 @@ func (b *NativeBuildah) PruneImages(ctx context.Context, opts PruneImagesOptions
 	return mapImageReportsToPruneImagesReport(imageReports), nil
 }
 
+// PruneLayers removes the layers which are not referred to by any image or container. Such layers are left behind
+// when the build is interrupted between the layer and the image creation.
+func (b *NativeBuildah) PruneLayers(ctx context.Context, opts PruneLayersOptions) (PruneLayersReport, error) {
+	layers, err := b.Store.Layers()
+	if err != nil {
+		return PruneLayersReport{}, fmt.Errorf("unable to list layers: %w", err)
+	}
+
+	images, err := b.Store.Images()
+	if err != nil {
+		return PruneLayersReport{}, fmt.Errorf("unable to list images: %w", err)
+	}
+
+	containers, err := b.Store.Containers()
+	if err != nil {
+		return PruneLayersReport{}, fmt.Errorf("unable to list containers: %w", err)
+	}
+
+	layerByID := make(map[string]storage.Layer, len(layers))
+	for _, layer := range layers {
+		layerByID[layer.ID] = layer
+	}
+
+	used := make(map[string]bool, len(layers))
+	var markUsed func(id string)
+	markUsed = func(id string) {
+		for id != "" && !used[id] {
+			used[id] = true
+			id = layerByID[id].Parent
+		}
+	}
+
+	for _, img := range images {
+		markUsed(img.TopLayer)
+		for _, id := range img.MappedTopLayers {
+			markUsed(id)
+		}
+	}
+	for _, c := range containers {
+		markUsed(c.LayerID)
+	}
+
+	untilTime := time.Now().Add(-opts.Until)
+	for _, layer := range layers {
+		// Keep the recent and the mounted layers and their parents.
+		if layer.Created.After(untilTime) || layer.MountCount > 0 {
+			markUsed(layer.ID)
+		}
+	}
+
+	report := PruneLayersReport{}
+
+	// The child layers are removed before their parents.
+	for removed := true; removed; {
+		removed = false
+
+		parents := make(map[string]bool, len(layerByID))
+		for _, layer := range layerByID {
+			parents[layer.Parent] = true
+		}
+
+		for id, layer := range layerByID {
+			if used[id] || parents[id] {
+				continue
+			}
+
+			if err := b.Store.DeleteLayer(id); err != nil {
+				return report, fmt.Errorf("unable to delete layer %q: %w", id, err)
+			}
+			delete(layerByID, id)
+
+			report.ItemsDeleted = append(report.ItemsDeleted, id)
+			report.SpaceReclaimed += uint64(layer.UncompressedSize)
+			removed = true
+		}
+	}
+
+	// Remove the remainders of the containers and the layers, which are not referred to by the store.
+	if err := b.Store.GarbageCollect(); err != nil {
+		return report, fmt.Errorf("unable to collect store garbage: %w", err)
+	}
+
+	return report, nil
+}
+
 func (b *NativeBuildah) Commit(ctx context.Context, container string, opts CommitOpts) (string, error) {
 	builder, err := b.openContainerBuilder(ctx, container)
 	if err != nil {
@@ -875,6 +1105,7 @@ func (b *NativeBuildah) Add(ctx context.Context, container string, src []string,
 	if err := builder.Add(dst, true, buildah.AddAndCopyOptions{
 		Chmod:             opts.Chmod,
 		Chown:             opts.Chown,
+		Checksum:          opts.Checksum,
 		PreserveOwnership: false,
 		ContextDir:        opts.ContextDir,
 		Excludes:          opts.Ignores,
@@ -1021,6 +1252,16 @@ func (b *NativeBuildah) Containers(ctx context.Context, opts ContainersOptions)
 		return nil, err
 	}
 
+	storeContainers, err := b.Store.Containers()
+	if err != nil {
+		return nil, fmt.Errorf("unable to list store containers: %w", err)
+	}
+
+	createdByID := make(map[string]time.Time, len(storeContainers))
+	for _, c := range storeContainers {
+		createdByID[c.ID] = c.Created
+	}
+
 	seenImages := make(map[string]string)
 	imageNameForID := func(id string) string {
 		if id == "" {
@@ -1059,6 +1300,7 @@ SelectContainers:
 			ID:      builder.ContainerID,
 			ImageID: builder.FromImageID,
 			Names:   []string{builder.Container},
+			Created: createdByID[builder.ContainerID],
 		})
 	}
 
@@ -145,6 +145,51 @@ var _ = Describe("buildah", func() {
 			Expect(strings.Count(config, `[[registry.mirror]]`)).To(Equal(2))
 			Expect(strings.Count(config, `location = "local-registry.test:32768"`)).To(Equal(2))
 		})
+
+		It("should generate [[registry]] entry with mirrors for non-docker.io registry", func() {
+			config, err := generateRegistriesConfig(
+				[]string{"https://dh-mirror.example.com", "ghcr.io=https://nexus.example.com/ghcr", "ghcr.io=http://ghcr-mirror.local:5000"},
+				[]string{},
+				[]string{},
+			)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(config).To(ContainSubstring("[[registry]]\nprefix = \"ghcr.io\"\nlocation = \"ghcr.io\"\ninsecure = false"))
+			Expect(config).To(ContainSubstring("[[registry.mirror]]\nlocation = \"nexus.example.com/ghcr\"\ninsecure = false"))
+			Expect(config).To(ContainSubstring("[[registry.mirror]]\nlocation = \"ghcr-mirror.local:5000\"\ninsecure = true"))
+			Expect(strings.Count(config, `[[registry.mirror]]`)).To(Equal(3))
+		})
+
+		It("should mark registry with mirrors as insecure instead of generating standalone entry", func() {
+			config, err := generateRegistriesConfig(
+				[]string{"registry.local:5000=https://mirror.example.com"},
+				[]string{"registry.local:5000"},
+				[]string{"registry.local:5000"},
+			)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(strings.Count(config, `location = "registry.local:5000"`)).To(Equal(1))
+			Expect(config).To(ContainSubstring("prefix = \"registry.local:5000\"\nlocation = \"registry.local:5000\"\ninsecure = true"))
+		})
+
+		It("should fail on bad mirror", func() {
+			_, err := generateRegistriesConfig([]string{"ghcr.io/org=https://mirror.example.com"}, []string{}, []string{})
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	Describe("generatePullRegistriesConfig", func() {
+		It("should generate config without mirrors and with insecure mirrors as standalone registries", func() {
+			config, err := generatePullRegistriesConfig(
+				[]string{"http://mirror.local:5000", "https://secure.example.com", "ghcr.io=https://insecure-https.example.com/ghcr"},
+				[]string{"insecure-https.example.com"},
+				[]string{"localhost:5000"},
+			)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(config).NotTo(ContainSubstring(`[[registry.mirror]]`))
+			Expect(config).To(ContainSubstring("[[registry]]\nlocation = \"mirror.local:5000\"\ninsecure = true"))
+			Expect(config).To(ContainSubstring("[[registry]]\nlocation = \"insecure-https.example.com\"\ninsecure = true"))
+			Expect(config).To(ContainSubstring("[[registry]]\nlocation = \"localhost:5000\"\ninsecure = true"))
+			Expect(config).NotTo(ContainSubstring(`"secure.example.com"`))
+		})
 	})
 
 	Describe("GetInsecureRegistriesFromConfig", func() {
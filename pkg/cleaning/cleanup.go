@@ -9,6 +9,7 @@ import (
 
 	"github.com/go-git/go-git/v5"
 	"github.com/gookit/color"
+	"github.com/opencontainers/go-digest"
 	"github.com/rodaine/table"
 	"github.com/samber/lo"
 
@@ -41,6 +42,10 @@ type CleanupOptions struct {
 	ParallelTasksLimit              int64
 	KeepList                        KeepList
 	Report                          *cleanup_report.Report
+
+	// DeployedDockerImages are the images scanned in advance with ScanDeployedDockerImages, so the cleanups of multiple projects
+	// share a single scan. The Kubernetes clusters are scanned by the cleanup if nil.
+	DeployedDockerImages []*DeployedDockerImage
 }
 
 func Cleanup(ctx context.Context, projectName string, storageManager *manager.StorageManager, options CleanupOptions) error {
@@ -61,6 +66,7 @@ func newCleanupManager(projectName string, storageManager *manager.StorageManage
 		LocalGit:                        options.LocalGit,
 		KubernetesContextClients:        options.KubernetesContextClients,
 		KubernetesNamespacesByContext:   options.KubernetesNamespacesByContext,
+		DeployedDockerImages:            options.DeployedDockerImages,
 		WithoutKube:                     options.WithoutKube,
 		ConfigMetaCleanup:               options.ConfigMetaCleanup,
 		KeepStagesBuiltWithinLastNHours: options.KeepStagesBuiltWithinLastNHours,
@@ -79,6 +85,7 @@ type cleanupManager struct {
 	LocalGit                        GitRepo
 	KubernetesContextClients        []*kube.ContextClient
 	KubernetesNamespacesByContext   map[string][]string
+	DeployedDockerImages            []*DeployedDockerImage
 	WithoutKube                     bool
 	ConfigMetaCleanup               config.MetaCleanup
 	KeepStagesBuiltWithinLastNHours *uint64
@@ -150,13 +157,17 @@ func (m *cleanupManager) run(ctx context.Context) error {
 	}
 
 	if !(m.WithoutKube || m.ConfigMetaCleanup.DisableKubernetesBasedPolicy) {
-		if len(m.KubernetesContextClients) == 0 {
-			return fmt.Errorf("cleanup requires Kubernetes access (token or kubeconfig), pass --without-kube to skip Kubernetes cleanup")
-		}
+		deployedDockerImages := m.DeployedDockerImages
+		if deployedDockerImages == nil {
+			if len(m.KubernetesContextClients) == 0 {
+				return fmt.Errorf("cleanup requires Kubernetes access (token or kubeconfig), pass --without-kube to skip Kubernetes cleanup")
+			}
 
-		deployedDockerImages, err := m.deployedDockerImages(ctx)
-		if err != nil {
-			return fmt.Errorf("error getting deployed docker images names from Kubernetes: %w", err)
+			var err error
+			deployedDockerImages, err = ScanDeployedDockerImages(ctx, m.KubernetesContextClients, m.KubernetesNamespacesByContext)
+			if err != nil {
+				return fmt.Errorf("error getting deployed docker images names from Kubernetes: %w", err)
+			}
 		}
 
 		if err := logboek.Context(ctx).LogProcess("Skipping repo tags that are being used in Kubernetes").DoError(func() error {
@@ -236,6 +247,12 @@ func (m *cleanupManager) run(ctx context.Context) error {
 		}
 	}
 
+	if err := logboek.Context(ctx).LogProcess("Cleanup vulnerability scan metadata").DoError(func() error {
+		return m.deleteUnusedVulnerabilityScanMetadata(ctx)
+	}); err != nil {
+		return err
+	}
+
 	if err := logboek.Context(ctx).LogProcess("Push last cleanup info to meta image").DoError(func() error {
 		err := m.StorageManager.GetStagesStorage().PostLastCleanupRecord(ctx, m.ProjectName)
 		if err != nil {
@@ -399,7 +416,9 @@ AppendNewImages:
 	return
 }
 
-func (m *cleanupManager) deployedDockerImages(ctx context.Context) ([]*DeployedDockerImage, error) {
+// ScanDeployedDockerImages returns the images used in the namespaces of the Kubernetes contexts.
+// All namespaces of the context are scanned if no namespaces are specified for it.
+func ScanDeployedDockerImages(ctx context.Context, contextClients []*kube.ContextClient, namespacesByContext map[string][]string) ([]*DeployedDockerImage, error) {
 	var deployedDockerImages []*DeployedDockerImage
 
 	scanNamespace := func(contextClient *kube.ContextClient, namespace string) error {
@@ -427,8 +446,8 @@ func (m *cleanupManager) deployedDockerImages(ctx context.Context) ([]*DeployedD
 		return nil
 	}
 
-	for _, contextClient := range m.KubernetesContextClients {
-		namespaces := m.KubernetesNamespacesByContext[contextClient.ContextName]
+	for _, contextClient := range contextClients {
+		namespaces := namespacesByContext[contextClient.ContextName]
 		if len(namespaces) == 0 {
 			if err := scanNamespace(contextClient, ""); err != nil {
 				return nil, err
@@ -444,6 +463,11 @@ func (m *cleanupManager) deployedDockerImages(ctx context.Context) ([]*DeployedD
 		}
 	}
 
+	// The empty scan result is not nil, so it is distinguished from the images which are not scanned yet.
+	if deployedDockerImages == nil {
+		deployedDockerImages = []*DeployedDockerImage{}
+	}
+
 	return deployedDockerImages, nil
 }
 
@@ -1131,6 +1155,77 @@ FilterOutFinalStages:
 	return nil
 }
 
+// deleteUnusedVulnerabilityScanMetadata deletes the vulnerability scan metadata of the images that are not kept: the
+// metadata is stored by the digest of the final image, which is in the final repo if it is used.
+func (m *cleanupManager) deleteUnusedVulnerabilityScanMetadata(ctx context.Context) error {
+	vulnerabilityScanMetadataIDs, err := m.StorageManager.GetStagesStorage().GetVulnerabilityScanMetadataIDs(ctx, m.ProjectName, storage.WithCache())
+	if err != nil {
+		return fmt.Errorf("unable to get vulnerability scan metadata ids: %w", err)
+	}
+
+	if len(vulnerabilityScanMetadataIDs) == 0 {
+		return nil
+	}
+
+	keptStageDescSet := m.stageManager.GetStageDescSet()
+	if m.StorageManager.GetFinalStagesStorage() != nil {
+		keptStageDescSet = m.stageManager.GetFinalStageDescSet()
+	}
+
+	keptImageDigests := map[string]bool{}
+	for stageDesc := range keptStageDescSet.Iter() {
+		imageInfoList := append([]*image.Info{stageDesc.Info}, stageDesc.Info.Index...)
+		for _, imageInfo := range imageInfoList {
+			if imageDigest := imageInfo.GetDigest(); imageDigest != "" {
+				keptImageDigests[digest.Digest(imageDigest).Encoded()] = true
+			}
+		}
+	}
+
+	var vulnerabilityScanMetadataIDsToDelete []string
+	for _, id := range vulnerabilityScanMetadataIDs {
+		if !keptImageDigests[storage.GetImageDigestEncodedFromVulnerabilityScanMetadataID(id)] {
+			vulnerabilityScanMetadataIDsToDelete = append(vulnerabilityScanMetadataIDsToDelete, id)
+		}
+	}
+
+	if len(vulnerabilityScanMetadataIDsToDelete) == 0 {
+		return nil
+	}
+
+	return logboek.Context(ctx).Default().LogProcess("Deleting vulnerability scan metadata (%d/%d)", len(vulnerabilityScanMetadataIDsToDelete), len(vulnerabilityScanMetadataIDs)).DoError(func() error {
+		return deleteVulnerabilityScanMetadata(ctx, m.ProjectName, m.StorageManager, vulnerabilityScanMetadataIDsToDelete, m.DryRun, m.report)
+	})
+}
+
+func deleteVulnerabilityScanMetadata(ctx context.Context, projectName string, storageManager manager.StorageManagerInterface, vulnerabilityScanMetadataIDs []string, dryRun bool, report *cleanup_report.Report) error {
+	if dryRun {
+		for _, id := range vulnerabilityScanMetadataIDs {
+			logboek.Context(ctx).Info().LogFDetails("  vulnerabilityScanMetadataID: %s\n", id)
+			logboek.Context(ctx).Info().LogOptionalLn()
+			report.AddDeleted(ctx, cleanup_report.Item{Type: cleanup_report.ItemTypeVulnerabilityScanMetadata, ID: id})
+		}
+		return nil
+	}
+
+	return storageManager.ForEachRmVulnerabilityScanMetadata(ctx, projectName, vulnerabilityScanMetadataIDs, func(ctx context.Context, id string, err error) error {
+		if err != nil {
+			if err := handleDeletionError(err); err != nil {
+				return err
+			}
+
+			logboek.Context(ctx).Warn().LogF("WARNING: Vulnerability scan metadata ID %s deletion failed: %s\n", id, err)
+
+			return nil
+		}
+
+		logboek.Context(ctx).Info().LogFDetails("  vulnerabilityScanMetadataID: %s\n", id)
+		report.AddDeleted(ctx, cleanup_report.Item{Type: cleanup_report.ItemTypeVulnerabilityScanMetadata, ID: id})
+
+		return nil
+	})
+}
+
 func (m *cleanupManager) initImportsMetadata(ctx context.Context) error {
 	if util.GetBoolEnvironmentDefaultFalse("WERF_EXPERIMENTAL_IMPORT_BY_SOURCE_IMAGE_TAG") {
 		return nil
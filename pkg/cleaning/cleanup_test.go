@@ -31,6 +31,8 @@ type fakePrimaryStagesStorage struct {
 	deletedImages  []image.StageID
 	deletedRecords []image.StageID
 	deletedTags    []string
+
+	vulnerabilityScanMetadataIDs []string
 }
 
 func (f *fakePrimaryStagesStorage) GetRejectedStageIDs(_ context.Context, _ ...storage.Option) ([]image.StageID, error) {
@@ -61,7 +63,8 @@ func (f *fakePrimaryStagesStorage) DeleteStageCustomTag(_ context.Context, tag s
 type fakeStorageManager struct {
 	manager.StorageManagerInterface
 
-	stages *fakePrimaryStagesStorage
+	stages      *fakePrimaryStagesStorage
+	finalStages storage.StagesStorage
 
 	importMetadataErrs map[string]error
 
@@ -436,3 +439,98 @@ func TestCleanupFinalStages_ReportRecordsKeptFinalStageWithReason(t *testing.T)
 	}}, report.Kept)
 	assert.Empty(t, report.Deleted)
 }
+
+func (f *fakePrimaryStagesStorage) GetVulnerabilityScanMetadataIDs(_ context.Context, _ string, _ ...storage.Option) ([]string, error) {
+	return f.vulnerabilityScanMetadataIDs, nil
+}
+
+func (f *fakeStorageManager) GetFinalStagesStorage() storage.StagesStorage {
+	return f.finalStages
+}
+
+func (f *fakeStorageManager) ForEachRmVulnerabilityScanMetadata(ctx context.Context, _ string, ids []string, cb func(ctx context.Context, id string, err error) error) error {
+	for _, id := range ids {
+		if err := cb(ctx, id, nil); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func newVulnerabilityScanTestStageDesc(digest string, creationTs int64, imageDigest string) *image.StageDesc {
+	stageID := image.NewStageID(digest, creationTs)
+	return &image.StageDesc{
+		StageID: stageID,
+		Info:    &image.Info{Tag: stageID.String(), RepoDigest: "registry.example.com/app@" + imageDigest},
+	}
+}
+
+func TestDeleteUnusedVulnerabilityScanMetadata_DeletesMetadataOfNotKeptImages(t *testing.T) {
+	ctx := context.Background()
+
+	keptImageDigest := "sha256:1e09fb543b4ef442ce5ed36bfeee6b27866bf1e68541db5995962b24aa11bb22"
+
+	sm := newFakeStorageManager()
+	sm.stageDescSet = image.NewStageDescSet(newVulnerabilityScanTestStageDesc("1e09fb", 1749456960043, keptImageDigest))
+	sm.stages.vulnerabilityScanMetadataIDs = []string{
+		"trivy-1e09fb543b4ef442ce5ed36bfeee6b27866bf1e68541db5995962b24aa11bb22",
+		"grype-1e09fb543b4ef442ce5ed36bfeee6b27866bf1e68541db5995962b24aa11bb22",
+		"trivy-ff00112233445566778899aabbccddeeff00112233445566778899aabbccddee",
+	}
+
+	stageManager := stage_manager.NewManager()
+	require.NoError(t, stageManager.InitStageDescSet(ctx, sm))
+
+	report := newTestReport()
+	m := &cleanupManager{stageManager: stageManager, StorageManager: sm, ProjectName: "myproject", report: report}
+
+	require.NoError(t, m.deleteUnusedVulnerabilityScanMetadata(ctx))
+
+	assert.Equal(t, []cleanup_report.Item{
+		{Type: cleanup_report.ItemTypeVulnerabilityScanMetadata, ID: "trivy-ff00112233445566778899aabbccddeeff00112233445566778899aabbccddee"},
+	}, report.Deleted)
+}
+
+func TestDeleteUnusedVulnerabilityScanMetadata_KeepsMetadataOfFinalRepoImages(t *testing.T) {
+	ctx := context.Background()
+
+	finalImageDigest := "sha256:c7d8e9f0a1b2c3d4e5f6a7b8c9d0e1f2a3b4c5d6e7f8a9b0c1d2e3f4a5b6c7d8"
+	repoImageDigest := "sha256:1e09fb543b4ef442ce5ed36bfeee6b27866bf1e68541db5995962b24aa11bb22"
+
+	sm := newFakeStorageManager()
+	sm.finalStages = &fakePrimaryStagesStorage{}
+	sm.stageDescSet = image.NewStageDescSet(newVulnerabilityScanTestStageDesc("1e09fb", 1749456960043, repoImageDigest))
+	sm.finalStageDescSet = image.NewStageDescSet(newVulnerabilityScanTestStageDesc("1e09fb", 1749456960043, finalImageDigest))
+	sm.stages.vulnerabilityScanMetadataIDs = []string{
+		"trivy-c7d8e9f0a1b2c3d4e5f6a7b8c9d0e1f2a3b4c5d6e7f8a9b0c1d2e3f4a5b6c7d8",
+		"trivy-1e09fb543b4ef442ce5ed36bfeee6b27866bf1e68541db5995962b24aa11bb22",
+	}
+
+	stageManager := stage_manager.NewManager()
+	require.NoError(t, stageManager.InitStageDescSet(ctx, sm))
+	require.NoError(t, stageManager.InitFinalStageDescSet(ctx, sm))
+
+	report := newTestReport()
+	m := &cleanupManager{stageManager: stageManager, StorageManager: sm, ProjectName: "myproject", report: report}
+
+	require.NoError(t, m.deleteUnusedVulnerabilityScanMetadata(ctx))
+
+	assert.Equal(t, []cleanup_report.Item{
+		{Type: cleanup_report.ItemTypeVulnerabilityScanMetadata, ID: "trivy-1e09fb543b4ef442ce5ed36bfeee6b27866bf1e68541db5995962b24aa11bb22"},
+	}, report.Deleted, "the metadata is stored by the digest of the image in the final repo")
+}
+
+func TestDeleteVulnerabilityScanMetadata_ReportDryRunMatchesRealRun(t *testing.T) {
+	ids := []string{
+		"trivy-1e09fb543b4ef442ce5ed36bfeee6b27866bf1e68541db5995962b24aa11bb22",
+		"grype-ff00112233445566778899aabbccddeeff00112233445566778899aabbccddee",
+	}
+
+	realReport := newTestReport()
+	require.NoError(t, deleteVulnerabilityScanMetadata(context.Background(), "myproject", newFakeStorageManager(), ids, false, realReport))
+
+	dryReport := newTestReport()
+	require.NoError(t, deleteVulnerabilityScanMetadata(context.Background(), "myproject", newFakeStorageManager(), ids, true, dryReport))
+
+	assert.ElementsMatch(t, realReport.Deleted, dryReport.Deleted)
+}
@@ -64,6 +64,17 @@ func (m *purgeManager) run(ctx context.Context) error {
 		return err
 	}
 
+	if err := logboek.Context(ctx).Default().LogProcess("Deleting vulnerability scan metadata").DoError(func() error {
+		vulnerabilityScanMetadataIDs, err := m.StorageManager.GetStagesStorage().GetVulnerabilityScanMetadataIDs(ctx, m.ProjectName, storage.WithCache())
+		if err != nil {
+			return err
+		}
+
+		return deleteVulnerabilityScanMetadata(ctx, m.ProjectName, m.StorageManager, vulnerabilityScanMetadataIDs, m.DryRun, m.report)
+	}); err != nil {
+		return err
+	}
+
 	if err := m.purgeManagedImages(ctx); err != nil {
 		return err
 	}
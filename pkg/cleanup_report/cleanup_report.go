@@ -12,14 +12,20 @@ import (
 type ItemType string
 
 const (
-	ItemTypeStage               ItemType = "stage"
-	ItemTypeFinalStage          ItemType = "finalStage"
-	ItemTypeCustomTag           ItemType = "customTag"
-	ItemTypeRejectedStage       ItemType = "rejectedStage"
-	ItemTypeRejectedStageMarker ItemType = "rejectedStageMarker"
-	ItemTypeImageMetadata       ItemType = "imageMetadata"
-	ItemTypeManagedImage        ItemType = "managedImage"
-	ItemTypeImportMetadata      ItemType = "importMetadata"
+	ItemTypeStage                     ItemType = "stage"
+	ItemTypeFinalStage                ItemType = "finalStage"
+	ItemTypeCustomTag                 ItemType = "customTag"
+	ItemTypeRejectedStage             ItemType = "rejectedStage"
+	ItemTypeRejectedStageMarker       ItemType = "rejectedStageMarker"
+	ItemTypeImageMetadata             ItemType = "imageMetadata"
+	ItemTypeManagedImage              ItemType = "managedImage"
+	ItemTypeImportMetadata            ItemType = "importMetadata"
+	ItemTypeVulnerabilityScanMetadata ItemType = "vulnerabilityScanMetadata"
+
+	ItemTypeLocalContainer ItemType = "localContainer"
+	ItemTypeLocalImage     ItemType = "localImage"
+	ItemTypeLocalVolume    ItemType = "localVolume"
+	ItemTypeLocalLayer     ItemType = "localLayer"
 )
 
 type Item struct {
@@ -91,6 +97,77 @@ func (r *Report) Save(ctx context.Context, path string) error {
 	return writeJSON(ctx, path, r)
 }
 
+// CombinedReport is the report of the cleanup of multiple projects in a single run.
+type CombinedReport struct {
+	mux sync.Mutex
+
+	Command  string           `json:"command"`
+	DryRun   bool             `json:"dryRun"`
+	Projects []*ProjectReport `json:"projects"`
+}
+
+// ProjectReport is the report of the project cleanup. The project report fields are omitted if the cleanup
+// failed before the report was created.
+type ProjectReport struct {
+	Project string `json:"project"`
+	Error   string `json:"error,omitempty"`
+
+	*Report
+}
+
+func NewCombinedReport(_ context.Context, command string, dryRun bool) *CombinedReport {
+	return &CombinedReport{
+		Command:  command,
+		DryRun:   dryRun,
+		Projects: []*ProjectReport{},
+	}
+}
+
+// AddProject adds the project report, which is filled in by the project cleanup, to the combined report.
+func (r *CombinedReport) AddProject(_ context.Context, project string, report *Report) *ProjectReport {
+	if r == nil {
+		return nil
+	}
+
+	r.mux.Lock()
+	defer r.mux.Unlock()
+
+	projectReport := &ProjectReport{Project: project, Report: report}
+	r.Projects = append(r.Projects, projectReport)
+
+	return projectReport
+}
+
+// SetError records the project cleanup error.
+func (r *CombinedReport) SetError(_ context.Context, projectReport *ProjectReport, err error) {
+	if r == nil || projectReport == nil || err == nil {
+		return
+	}
+
+	r.mux.Lock()
+	defer r.mux.Unlock()
+
+	projectReport.Error = err.Error()
+}
+
+func (r *CombinedReport) Save(ctx context.Context, path string) error {
+	if r == nil {
+		return nil
+	}
+
+	r.mux.Lock()
+	defer r.mux.Unlock()
+
+	for _, projectReport := range r.Projects {
+		if projectReport.Report != nil {
+			projectReport.Report.mux.Lock()
+			defer projectReport.Report.mux.Unlock()
+		}
+	}
+
+	return writeJSON(ctx, path, r)
+}
+
 // CheckWritable fails before any destructive work when the report could not be written
 // afterwards, so a cleanup does not delete objects only to lose its record of them.
 func CheckWritable(_ context.Context, path string) error {
@@ -214,3 +214,74 @@ func TestCheckWritableRejectsDirectory(t *testing.T) {
 	require.Error(t, err, "a directory at the report path must be rejected before any deletion happens")
 	assert.Contains(t, err.Error(), "is not writable")
 }
+
+func TestCombinedReportJSON(t *testing.T) {
+	ctx := context.Background()
+
+	combined := NewCombinedReport(ctx, "cleanup", false)
+
+	backend := NewReport(ctx, "cleanup", false, "registry.mydomain.com/backend/werf", NewReportOptions{})
+	combined.AddProject(ctx, "backend", backend)
+	backend.AddDeleted(ctx, Item{Type: ItemTypeManagedImage, ImageName: "api"})
+
+	frontend := combined.AddProject(ctx, "frontend", nil)
+	combined.SetError(ctx, frontend, fmt.Errorf("unable to load werf config"))
+
+	path := filepath.Join(t.TempDir(), "report.json")
+	require.NoError(t, combined.Save(ctx, path))
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+
+	assert.JSONEq(t, `{
+  "command": "cleanup",
+  "dryRun": false,
+  "projects": [
+    {
+      "project": "backend",
+      "command": "cleanup",
+      "dryRun": false,
+      "repo": "registry.mydomain.com/backend/werf",
+      "kept": [],
+      "deleted": [
+        { "type": "managedImage", "imageName": "api" }
+      ]
+    },
+    { "project": "frontend", "error": "unable to load werf config" }
+  ]
+}`, string(data))
+}
+
+func TestHostReportJSON(t *testing.T) {
+	ctx := context.Background()
+
+	report := NewHostReport(ctx, "host cleanup", false)
+	report.SetBackend(ctx, "Buildah", "overlay")
+	report.AddDeleted(ctx,
+		Item{Type: ItemTypeLocalContainer, ID: "3c1d0f5e9a2b", Reason: "stale working container"},
+		Item{Type: ItemTypeLocalLayer, ID: "9f8e7d6c5b4a"},
+	)
+	report.AddSpaceReclaimed(ctx, "containers", 1024)
+	report.AddSpaceReclaimed(ctx, "volumes", 2048)
+
+	path := filepath.Join(t.TempDir(), "report.json")
+	require.NoError(t, report.Save(ctx, path))
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+
+	assert.JSONEq(t, `{
+  "command": "host cleanup",
+  "dryRun": false,
+  "backend": "Buildah",
+  "storageDriver": "overlay",
+  "spaceReclaimed": [
+    { "step": "containers", "bytes": 1024 },
+    { "step": "volumes", "bytes": 2048 }
+  ],
+  "deleted": [
+    { "type": "localContainer", "id": "3c1d0f5e9a2b", "reason": "stale working container" },
+    { "type": "localLayer", "id": "9f8e7d6c5b4a" }
+  ]
+}`, string(data))
+}
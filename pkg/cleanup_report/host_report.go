@@ -0,0 +1,80 @@
+package cleanup_report
+
+import (
+	"context"
+	"sync"
+)
+
+// HostReport is the report of the local container backend and local cache cleanup by the host cleanup.
+type HostReport struct {
+	mux sync.Mutex
+
+	Command        string           `json:"command"`
+	DryRun         bool             `json:"dryRun"`
+	Backend        string           `json:"backend"`
+	StorageDriver  string           `json:"storageDriver,omitempty"`
+	SpaceReclaimed []SpaceReclaimed `json:"spaceReclaimed"`
+	Deleted        []Item           `json:"deleted"`
+}
+
+// SpaceReclaimed is the space reclaimed by the host cleanup step. For Buildah the space is measured by the storage
+// driver directories, for Docker by the storage volume usage. For the local cache steps it is the size of the removed
+// files, in the dry run mode the size of the files to remove.
+type SpaceReclaimed struct {
+	Step  string `json:"step"`
+	Bytes uint64 `json:"bytes"`
+}
+
+func NewHostReport(_ context.Context, command string, dryRun bool) *HostReport {
+	return &HostReport{
+		Command:        command,
+		DryRun:         dryRun,
+		SpaceReclaimed: []SpaceReclaimed{},
+		Deleted:        []Item{},
+	}
+}
+
+func (r *HostReport) SetBackend(_ context.Context, backend, storageDriver string) {
+	if r == nil {
+		return
+	}
+
+	r.mux.Lock()
+	defer r.mux.Unlock()
+
+	r.Backend = backend
+	r.StorageDriver = storageDriver
+}
+
+func (r *HostReport) AddDeleted(_ context.Context, items ...Item) {
+	if r == nil {
+		return
+	}
+
+	r.mux.Lock()
+	defer r.mux.Unlock()
+
+	r.Deleted = append(r.Deleted, items...)
+}
+
+func (r *HostReport) AddSpaceReclaimed(_ context.Context, step string, bytes uint64) {
+	if r == nil {
+		return
+	}
+
+	r.mux.Lock()
+	defer r.mux.Unlock()
+
+	r.SpaceReclaimed = append(r.SpaceReclaimed, SpaceReclaimed{Step: step, Bytes: bytes})
+}
+
+func (r *HostReport) Save(ctx context.Context, path string) error {
+	if r == nil {
+		return nil
+	}
+
+	r.mux.Lock()
+	defer r.mux.Unlock()
+
+	return writeJSON(ctx, path, r)
+}
@@ -0,0 +1,7 @@
+package config
+
+import "github.com/werf/werf/v2/pkg/dockerfile/linter"
+
+type DockerfileLint struct {
+	Suppress []linter.Rule
+}
@@ -0,0 +1,36 @@
+package config
+
+// ExportTarget is the destination the final image is exported to by werf export: the container registry or the OCI
+// archive.
+type ExportTarget struct {
+	// Repo and Tags are set for the container registry target, the tags are the templates of the --tag option of werf
+	// export without the repository part.
+	Repo string
+	Tags []string
+
+	// OCIArchive is the path of the OCI archive relative to the project directory, it can contain the image name
+	// shortcuts of the tag templates.
+	OCIArchive string
+
+	// Platforms are the platforms of the multiplatform image to export, all platforms are exported if empty.
+	Platforms []string
+
+	raw *rawExportTarget
+}
+
+func (c *ExportTarget) validate() error {
+	switch {
+	case c.Repo == "" && c.OCIArchive == "":
+		return newDetailedConfigError("one of `repo: REPO` and `ociArchive: PATH` required for export target!", c.raw, c.raw.doc())
+	case c.Repo != "" && c.OCIArchive != "":
+		return newDetailedConfigError("only one of `repo: REPO` and `ociArchive: PATH` can be used for export target!", c.raw, c.raw.doc())
+	case c.Repo != "" && len(c.Tags) == 0:
+		return newDetailedConfigError("`tags: [TAG, ...]` required for export target with `repo: REPO`!", c.raw, c.raw.doc())
+	case c.OCIArchive != "" && len(c.Tags) != 0:
+		return newDetailedConfigError("`tags: [TAG, ...]` is not supported for export target with `ociArchive: PATH`!", c.raw, c.raw.doc())
+	case c.OCIArchive != "" && !isRelativePath(c.OCIArchive):
+		return newDetailedConfigError("`ociArchive: PATH` should be relative to project directory!", c.raw, c.raw.doc())
+	}
+
+	return nil
+}
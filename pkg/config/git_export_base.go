@@ -4,9 +4,20 @@ import (
 	"strings"
 )
 
+type GitCacheMode string
+
+const (
+	// GitCacheModeAncestry reuses git stages built from ancestor commits of the current commit.
+	GitCacheModeAncestry GitCacheMode = "ancestry"
+	// GitCacheModeContent additionally reuses git stages built from any commit with the same content of the git mapping,
+	// e.g. after squash or rebase.
+	GitCacheModeContent GitCacheMode = "content"
+)
+
 type GitExportBase struct {
 	*GitExport
 	StageDependencies *StageDependencies
+	CacheMode         GitCacheMode
 }
 
 func (c *ExportBase) GitMappingAdd() string {
@@ -1,5 +1,7 @@
 package config
 
+import "fmt"
+
 type GitLocalExport struct {
 	*GitExportBase
 
@@ -7,5 +9,11 @@ type GitLocalExport struct {
 }
 
 func (c *GitLocalExport) validate() error {
+	switch c.CacheMode {
+	case GitCacheModeAncestry, GitCacheModeContent:
+	default:
+		return newDetailedConfigError(fmt.Sprintf("`cacheMode: %s` is not supported, expected one of: %s, %s!", c.CacheMode, GitCacheModeAncestry, GitCacheModeContent), c.raw, c.raw.rawStapelImage.doc)
+	}
+
 	return nil
 }
@@ -22,6 +22,8 @@ type ImageFromDockerfile struct {
 	Staged          bool
 	Secrets         []Secret
 	ImageSpec       *ImageSpec
+	Lint            *DockerfileLint
+	Export          []*ExportTarget
 
 	cacheVersion string
 	platform     []string
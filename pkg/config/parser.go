@@ -9,6 +9,7 @@ import (
 	"os"
 	"path/filepath"
 	"regexp"
+	"sort"
 	"strconv"
 	"strings"
 	"text/template"
@@ -82,12 +83,14 @@ func GetWerfConfig(ctx context.Context, customWerfConfigRelPath, customWerfConfi
 	var path string
 	var config *WerfConfig
 	err := logboek.Context(ctx).Info().LogProcess("Render werf config").DoError(func() error {
+		lookedUpImages := &imageLookups{}
 		werfConfigPath, werfConfigRenderContent, err := renderWerfConfigYaml(ctx, renderWerfConfigYamlOpts{
 			customWerfConfigRelPath:             customWerfConfigRelPath,
 			customWerfConfigTemplatesDirRelPath: customWerfConfigTemplatesDirRelPath,
 			giterminismManager:                  giterminismManager,
 			env:                                 opts.Env,
 			debugTemplates:                      opts.DebugTemplates,
+			imageLookups:                        lookedUpImages,
 		})
 		if err != nil {
 			return fmt.Errorf("unable to render werf config: %w", err)
@@ -157,6 +160,10 @@ func GetWerfConfig(ctx context.Context, customWerfConfigRelPath, customWerfConfi
 			return err
 		}
 
+		if err := lookedUpImages.validate(werfConfig); err != nil {
+			return err
+		}
+
 		path = werfConfigPath
 		config = werfConfig
 
@@ -235,11 +242,12 @@ type renderWerfConfigYamlOpts struct {
 	env                                 string
 	includesConfigRelPath               string
 	debugTemplates                      bool
+	imageLookups                        *imageLookups
 }
 
 func renderWerfConfigYaml(ctx context.Context, opts renderWerfConfigYamlOpts) (string, string, error) {
 	tmpl := template.New("werfConfig")
-	tmpl.Funcs(funcMap(ctx, tmpl, opts.giterminismManager, opts.debugTemplates))
+	tmpl.Funcs(funcMap(ctx, tmpl, opts.giterminismManager, opts.imageLookups, opts.debugTemplates))
 
 	err := parseWerfConfigTemplatesDir(ctx, parseWerfConfigTemplatesDirOpts{
 		tmpl:                                tmpl,
@@ -336,7 +344,7 @@ func addTemplate(tmpl *template.Template, templateName, templateContent string)
 	return err
 }
 
-func funcMap(ctx context.Context, tmpl *template.Template, giterminismManager giterminism_manager.Interface, debug bool) template.FuncMap {
+func funcMap(ctx context.Context, tmpl *template.Template, giterminismManager giterminism_manager.Interface, lookups *imageLookups, debug bool) template.FuncMap {
 	funcMap := sprig.TxtFuncMap()
 	delete(funcMap, "expandenv")
 
@@ -436,6 +444,61 @@ func funcMap(ctx context.Context, tmpl *template.Template, giterminismManager gi
 		return val, nil
 	}
 
+	funcMap["fileDigest"] = func(relPath string) (string, error) {
+		hash, err := giterminismManager.(*giterminism_manager.Manager).FileManager.ConfigGoTemplateFilesGetBlobHash(ctx, relPath)
+		if err != nil {
+			return "", fmt.Errorf("{{ fileDigest %q }}: %w", relPath, err)
+		}
+
+		return hash, nil
+	}
+
+	funcMap["filesDigest"] = func(glob string) (string, error) {
+		res, err := giterminismManager.(*giterminism_manager.Manager).FileManager.ConfigGoTemplateFilesGlobBlobHashes(ctx, glob)
+		if err != nil {
+			return "", fmt.Errorf("{{ filesDigest %q }}: %w", glob, err)
+		}
+
+		if len(res) == 0 {
+			return "", fmt.Errorf("{{ filesDigest %q }}: no files matched", glob)
+		}
+
+		return filesDigest(res), nil
+	}
+
+	funcMap["gitLog"] = func(relPath string) (map[string]interface{}, error) {
+		cleanRelPath := filepath.Clean(relPath)
+		if filepath.IsAbs(cleanRelPath) || cleanRelPath == ".." || strings.HasPrefix(cleanRelPath, ".."+string(filepath.Separator)) {
+			return nil, fmt.Errorf("{{ gitLog %q }}: path must be relative to the project directory", relPath)
+		}
+
+		gitPath := filepath.ToSlash(filepath.Join(giterminismManager.RelativeToGitProjectDir(), cleanRelPath))
+		commit, err := giterminismManager.LocalGitRepo().GetLastPathCommit(ctx, giterminismManager.HeadCommit(ctx), gitPath)
+		if err != nil {
+			return nil, fmt.Errorf("{{ gitLog %q }}: %w", relPath, err)
+		}
+
+		if commit == nil {
+			return nil, fmt.Errorf("{{ gitLog %q }}: no commits found for the path", relPath)
+		}
+
+		return map[string]interface{}{
+			"Hash": commit.Hash,
+			"Date": map[string]string{
+				"Human": commit.Time.String(),
+				"Unix":  strconv.FormatInt(commit.Time.Unix(), 10),
+			},
+		}, nil
+	}
+
+	funcMap["lookupImage"] = func(name string) string {
+		if lookups != nil {
+			lookups.add(name)
+		}
+
+		return name
+	}
+
 	// debug functions
 	funcMap["tpl_debug"] = func(templateContent string, data interface{}) (string, error) {
 		templateName := buildTplTemplateName(templateContent)
@@ -562,6 +625,41 @@ func detailedTemplateError(tmpl *template.Template, d detailedTemplateErrorData,
 	return fmt.Errorf("%w\n%s", err, engine.GetTemplateErrHint())
 }
 
+// filesDigest returns the digest of the files git blob hashes along with their paths, so renaming a file changes the digest too.
+func filesDigest(blobHashesByPath map[string]string) string {
+	paths := make([]string, 0, len(blobHashesByPath))
+	for path := range blobHashesByPath {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	var args []string
+	for _, path := range paths {
+		args = append(args, path, blobHashesByPath[path])
+	}
+
+	return util.Sha256Hash(args...)
+}
+
+// imageLookups collects the image names requested by the lookupImage function to check them when the config is parsed.
+type imageLookups struct {
+	names []string
+}
+
+func (l *imageLookups) add(name string) {
+	l.names = append(l.names, name)
+}
+
+func (l *imageLookups) validate(werfConfig *WerfConfig) error {
+	for _, name := range l.names {
+		if werfConfig.GetImage(name) == nil {
+			return fmt.Errorf("{{ lookupImage %q }}: image is not defined in werf.yaml", name)
+		}
+	}
+
+	return nil
+}
+
 type files struct {
 	ctx                context.Context
 	giterminismManager *giterminism_manager.Manager
@@ -0,0 +1,54 @@
+package config
+
+import (
+	"context"
+	"text/template"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("filesDigest", func() {
+	It("should not depend on the files order", func() {
+		Expect(filesDigest(map[string]string{"a": "1", "b": "2"})).To(Equal(filesDigest(map[string]string{"b": "2", "a": "1"})))
+	})
+
+	It("should change when the file is renamed", func() {
+		Expect(filesDigest(map[string]string{"a": "1"})).NotTo(Equal(filesDigest(map[string]string{"b": "1"})))
+	})
+
+	It("should change when the content is moved between files", func() {
+		Expect(filesDigest(map[string]string{"a": "12", "b": ""})).NotTo(Equal(filesDigest(map[string]string{"a": "1", "b": "2"})))
+	})
+})
+
+var _ = Describe("lookupImage", func() {
+	render := func(content string, lookups *imageLookups) (string, error) {
+		tmpl := template.New("werfConfig")
+		tmpl.Funcs(funcMap(context.Background(), tmpl, nil, lookups, false))
+		if err := addTemplate(tmpl, "werfConfig", content); err != nil {
+			return "", err
+		}
+
+		return executeTemplate(tmpl, "werfConfig", nil)
+	}
+
+	werfConfig := NewWerfConfig(nil, []ImageInterface{&ImageFromDockerfile{Name: "backend"}})
+
+	It("should render the image name", func() {
+		lookups := &imageLookups{}
+
+		content, err := render(`fromImage: {{ lookupImage "backend" }}`, lookups)
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(content).To(Equal("fromImage: backend"))
+		Expect(lookups.validate(werfConfig)).To(Succeed())
+	})
+
+	It("should fail validation if the image is not defined", func() {
+		lookups := &imageLookups{}
+
+		_, err := render(`fromImage: {{ lookupImage "frontend" }}`, lookups)
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(lookups.validate(werfConfig)).To(MatchError(ContainSubstring(`{{ lookupImage "frontend" }}: image is not defined in werf.yaml`)))
+	})
+})
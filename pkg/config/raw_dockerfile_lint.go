@@ -0,0 +1,62 @@
+package config
+
+import (
+	"fmt"
+
+	"github.com/werf/werf/v2/pkg/dockerfile/linter"
+)
+
+type rawDockerfileLint struct {
+	Suppress []string `yaml:"suppress,omitempty"`
+
+	rawStapelImage         *rawStapelImage         `yaml:"-"` // possible parent
+	rawImageFromDockerfile *rawImageFromDockerfile `yaml:"-"` // possible parent
+
+	UnsupportedAttributes map[string]interface{} `yaml:",inline"`
+}
+
+func (c *rawDockerfileLint) doc() *doc {
+	if c.rawStapelImage != nil {
+		return c.rawStapelImage.doc
+	} else if c.rawImageFromDockerfile != nil {
+		return c.doc()
+	} else {
+		panic("runtime error")
+	}
+}
+
+func (c *rawDockerfileLint) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	switch parent := parentStack.Peek().(type) {
+	case *rawStapelImage:
+		c.rawStapelImage = parent
+	case *rawImageFromDockerfile:
+		c.rawImageFromDockerfile = parent
+	}
+
+	parentStack.Push(c)
+	type plain rawDockerfileLint
+	err := unmarshal((*plain)(c))
+	parentStack.Pop()
+	if err != nil {
+		return err
+	}
+
+	if err := checkOverflow(c.UnsupportedAttributes, nil, c.doc()); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (c *rawDockerfileLint) toDirective() (*DockerfileLint, error) {
+	directive := &DockerfileLint{}
+	for _, rule := range c.Suppress {
+		if !linter.IsKnownRule(rule) {
+			return nil, newDetailedConfigError(fmt.Sprintf("unknown dockerfile lint rule %q in `lint.suppress`!", rule), c, c.doc())
+		}
+
+		directive.Suppress = append(directive.Suppress, linter.Rule(rule))
+	}
+
+	return directive, nil
+}
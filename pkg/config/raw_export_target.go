@@ -0,0 +1,96 @@
+package config
+
+import (
+	"fmt"
+
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+)
+
+type rawExportTarget struct {
+	Repo       string   `yaml:"repo,omitempty"`
+	Tags       []string `yaml:"tags,omitempty"`
+	OCIArchive string   `yaml:"ociArchive,omitempty"`
+	Platforms  []string `yaml:"platforms,omitempty"`
+
+	rawStapelImage         *rawStapelImage         `yaml:"-"` // possible parent
+	rawImageFromDockerfile *rawImageFromDockerfile `yaml:"-"` // possible parent
+
+	UnsupportedAttributes map[string]interface{} `yaml:",inline"`
+}
+
+func (c *rawExportTarget) doc() *doc {
+	if c.rawStapelImage != nil {
+		return c.rawStapelImage.doc
+	} else if c.rawImageFromDockerfile != nil {
+		return c.rawImageFromDockerfile.doc
+	} else {
+		panic("runtime error")
+	}
+}
+
+func (c *rawExportTarget) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	switch parent := parentStack.Peek().(type) {
+	case *rawStapelImage:
+		c.rawStapelImage = parent
+	case *rawImageFromDockerfile:
+		c.rawImageFromDockerfile = parent
+	}
+
+	parentStack.Push(c)
+	type plain rawExportTarget
+	err := unmarshal((*plain)(c))
+	parentStack.Pop()
+	if err != nil {
+		return err
+	}
+
+	if err := checkOverflow(c.UnsupportedAttributes, c, c.doc()); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (c *rawExportTarget) toDirective() (*ExportTarget, error) {
+	directive := &ExportTarget{
+		Repo:       c.Repo,
+		Tags:       c.Tags,
+		OCIArchive: c.OCIArchive,
+		Platforms:  c.Platforms,
+		raw:        c,
+	}
+
+	for _, platform := range c.Platforms {
+		if _, err := v1.ParsePlatform(platform); err != nil {
+			return nil, newDetailedConfigError(fmt.Sprintf("invalid platform %q in `platforms: [PLATFORM, ...]`: %s", platform, err), c, c.doc())
+		}
+	}
+
+	if err := directive.validate(); err != nil {
+		return nil, err
+	}
+
+	return directive, nil
+}
+
+func toExportTargetDirectives(rawExportTargets []*rawExportTarget, isFinal bool, doc *doc) ([]*ExportTarget, error) {
+	if len(rawExportTargets) == 0 {
+		return nil, nil
+	}
+
+	if !isFinal {
+		return nil, newDetailedConfigError("`export` directive is supported only for final images!", nil, doc)
+	}
+
+	var exportTargets []*ExportTarget
+	for _, rawExportTarget := range rawExportTargets {
+		exportTarget, err := rawExportTarget.toDirective()
+		if err != nil {
+			return nil, err
+		}
+
+		exportTargets = append(exportTargets, exportTarget)
+	}
+
+	return exportTargets, nil
+}
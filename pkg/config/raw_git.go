@@ -16,6 +16,7 @@ type rawGit struct {
 	Tag                  string                         `yaml:"tag,omitempty"`
 	Commit               string                         `yaml:"commit,omitempty"`
 	RawStageDependencies *rawStageDependencies          `yaml:"stageDependencies,omitempty"`
+	CacheMode            string                         `yaml:"cacheMode,omitempty"`
 
 	rawStapelImage *rawStapelImage `yaml:"-"` // parent
 
@@ -108,6 +109,11 @@ func (c *rawGit) toGitLocalExportDirective() (gitLocalExport *GitLocalExport, er
 		}
 	}
 
+	gitLocalExport.CacheMode = GitCacheModeAncestry
+	if c.CacheMode != "" {
+		gitLocalExport.CacheMode = GitCacheMode(c.CacheMode)
+	}
+
 	gitLocalExport.raw = c
 
 	if err := c.validateGitLocalExportDirective(gitLocalExport); err != nil {
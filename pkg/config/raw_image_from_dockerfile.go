@@ -25,6 +25,8 @@ type rawImageFromDockerfile struct {
 	Platform        []string               `yaml:"platform,omitempty"`
 	RawSecrets      []*rawSecret           `yaml:"secrets,omitempty"`
 	RawImageSpec    *rawImageSpec          `yaml:"imageSpec,omitempty"`
+	RawLint         *rawDockerfileLint     `yaml:"lint,omitempty"`
+	RawExport       []*rawExportTarget     `yaml:"export,omitempty"`
 
 	doc          *doc `yaml:"-"` // parent
 	isFillStaged bool `yaml:"-"` // indicates whether 'staged' field is explicitly set in the image section
@@ -151,6 +153,21 @@ func (c *rawImageFromDockerfile) toImageFromDockerfileDirective(giterminismManag
 		image.ImageSpec = c.RawImageSpec.toDirective()
 	}
 
+	if c.RawLint != nil {
+		lint, err := c.RawLint.toDirective()
+		if err != nil {
+			return nil, err
+		}
+
+		image.Lint = lint
+	}
+
+	if export, err := toExportTargetDirectives(c.RawExport, image.final, c.doc); err != nil {
+		return nil, err
+	} else {
+		image.Export = export
+	}
+
 	if err := image.validate(giterminismManager); err != nil {
 		return nil, err
 	}
@@ -8,6 +8,7 @@ import (
 	"gopkg.in/yaml.v2"
 
 	"github.com/werf/common-go/pkg/util"
+	"github.com/werf/werf/v2/pkg/dockerfile/linter"
 )
 
 var _ = Describe("rawImageFromDockerfile", func() {
@@ -60,6 +61,27 @@ var _ = Describe("rawImageFromDockerfile", func() {
 				final:        true,
 			},
 		),
+		Entry(
+			"with lint suppressions",
+			map[string]interface{}{
+				"image": "image1",
+				"lint": map[string]interface{}{
+					"suppress": []string{"from-latest-tag", "unused-stage"},
+				},
+			},
+			&ImageFromDockerfile{
+				Name:            "image1",
+				ContextAddFiles: []string{},
+				AddHost:         []string{},
+				Secrets:         []Secret{},
+				Lint: &DockerfileLint{
+					Suppress: []linter.Rule{linter.RuleFromLatestTag, linter.RuleUnusedStage},
+				},
+
+				platform: []string{},
+				final:    true,
+			},
+		),
 	)
 
 	DescribeTable("unmarshal and convert to directive succeed and produce expected Dependencies",
@@ -226,6 +248,53 @@ var _ = Describe("rawImageFromDockerfile", func() {
 		),
 	)
 
+	DescribeTable("unmarshal and convert to directive succeed and produce expected export targets",
+		func(yamlMap map[string]interface{}, expected []*ExportTarget) {
+			rawYaml, err := yaml.Marshal(yamlMap)
+			Expect(err).To(Succeed())
+
+			doc := &doc{Content: rawYaml}
+			rawDockerfileImage := &rawImageFromDockerfile{doc: doc}
+
+			Expect(yaml.UnmarshalStrict(doc.Content, rawDockerfileImage)).To(Succeed())
+
+			dockerfileImage, err := rawDockerfileImage.toImageFromDockerfileDirective(giterminismManager, "image1")
+			Expect(err).To(Succeed())
+
+			for _, exportTarget := range dockerfileImage.Export {
+				exportTarget.raw = nil // set to nil for correct deep comparison
+			}
+			Expect(dockerfileImage.Export).To(Equal(expected))
+		},
+		Entry(
+			"with registry and OCI archive targets",
+			map[string]interface{}{
+				"image":      "image1",
+				"dockerfile": "Dockerfile",
+				"export": []map[string]interface{}{
+					{
+						"repo":      "registry.example.com/app",
+						"tags":      []string{"latest", "%image_content_based_tag%"},
+						"platforms": []string{"linux/amd64"},
+					},
+					{
+						"ociArchive": "dist/%image%.tar",
+					},
+				},
+			},
+			[]*ExportTarget{
+				{
+					Repo:      "registry.example.com/app",
+					Tags:      []string{"latest", "%image_content_based_tag%"},
+					Platforms: []string{"linux/amd64"},
+				},
+				{
+					OCIArchive: "dist/%image%.tar",
+				},
+			},
+		),
+	)
+
 	DescribeTable("unmarshal and convert to directive fail with configError",
 		func(yamlMap map[string]interface{}) {
 			if len(yamlMap) == 0 {
@@ -336,5 +405,79 @@ var _ = Describe("rawImageFromDockerfile", func() {
 				}},
 			},
 		),
+		Entry(
+			"with export target without repo and ociArchive",
+			map[string]interface{}{
+				"image":      "image1",
+				"dockerfile": "Dockerfile",
+				"export": []map[string]interface{}{{
+					"tags": []string{"latest"},
+				}},
+			},
+		),
+		Entry(
+			"with export target with both repo and ociArchive",
+			map[string]interface{}{
+				"image":      "image1",
+				"dockerfile": "Dockerfile",
+				"export": []map[string]interface{}{{
+					"repo":       "registry.example.com/app",
+					"tags":       []string{"latest"},
+					"ociArchive": "app.tar",
+				}},
+			},
+		),
+		Entry(
+			"with export target repo without tags",
+			map[string]interface{}{
+				"image":      "image1",
+				"dockerfile": "Dockerfile",
+				"export": []map[string]interface{}{{
+					"repo": "registry.example.com/app",
+				}},
+			},
+		),
+		Entry(
+			"with export target ociArchive outside of project directory",
+			map[string]interface{}{
+				"image":      "image1",
+				"dockerfile": "Dockerfile",
+				"export": []map[string]interface{}{{
+					"ociArchive": "../app.tar",
+				}},
+			},
+		),
+		Entry(
+			"with export target invalid platform",
+			map[string]interface{}{
+				"image":      "image1",
+				"dockerfile": "Dockerfile",
+				"export": []map[string]interface{}{{
+					"ociArchive": "app.tar",
+					"platforms":  []string{"linux/amd64/v1/extra"},
+				}},
+			},
+		),
+		Entry(
+			"with export targets of non-final image",
+			map[string]interface{}{
+				"image":      "image1",
+				"dockerfile": "Dockerfile",
+				"final":      false,
+				"export": []map[string]interface{}{{
+					"ociArchive": "app.tar",
+				}},
+			},
+		),
+		Entry(
+			"with unknown lint rule",
+			map[string]interface{}{
+				"image":      "image1",
+				"dockerfile": "Dockerfile",
+				"lint": map[string]interface{}{
+					"suppress": []string{"unknown-rule"},
+				},
+			},
+		),
 	)
 })
@@ -1,9 +1,10 @@
 package config
 
 type rawStageDependencies struct {
-	Install     interface{} `yaml:"install,omitempty"`
-	Setup       interface{} `yaml:"setup,omitempty"`
-	BeforeSetup interface{} `yaml:"beforeSetup,omitempty"`
+	BeforeInstall interface{} `yaml:"beforeInstall,omitempty"`
+	Install       interface{} `yaml:"install,omitempty"`
+	Setup         interface{} `yaml:"setup,omitempty"`
+	BeforeSetup   interface{} `yaml:"beforeSetup,omitempty"`
 
 	rawGit *rawGit `yaml:"-"` // parent
 
@@ -30,6 +31,12 @@ func (c *rawStageDependencies) UnmarshalYAML(unmarshal func(interface{}) error)
 func (c *rawStageDependencies) toDirective() (stageDependencies *StageDependencies, err error) {
 	stageDependencies = &StageDependencies{}
 
+	if beforeInstall, err := InterfaceToStringArray(c.BeforeInstall, c, c.rawGit.rawStapelImage.doc); err != nil {
+		return nil, err
+	} else {
+		stageDependencies.BeforeInstall = beforeInstall
+	}
+
 	if install, err := InterfaceToStringArray(c.Install, c, c.rawGit.rawStapelImage.doc); err != nil {
 		return nil, err
 	} else {
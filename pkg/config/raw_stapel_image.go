@@ -8,27 +8,29 @@ import (
 )
 
 type rawStapelImage struct {
-	Images               []string         `yaml:"-"`
-	Final                *bool            `yaml:"final,omitempty"`
-	Artifact             string           `yaml:"artifact,omitempty"`
-	CacheVersion         string           `yaml:"cacheVersion,omitempty"`
-	From                 string           `yaml:"from,omitempty"`
-	FromLatest           bool             `yaml:"fromLatest,omitempty"`
-	FromCacheVersion     string           `yaml:"fromCacheVersion,omitempty"`
-	FromImage            string           `yaml:"fromImage,omitempty"`
-	FromArtifact         string           `yaml:"fromArtifact,omitempty"`
-	DisableGitAfterPatch bool             `yaml:"disableGitAfterPatch,omitempty"`
-	RawGit               []*rawGit        `yaml:"git,omitempty"`
-	RawShell             *rawShell        `yaml:"shell,omitempty"`
-	RawAnsible           *rawAnsible      `yaml:"ansible,omitempty"`
-	RawMount             []*rawMount      `yaml:"mount,omitempty"`
-	RawDocker            *rawDocker       `yaml:"docker,omitempty"`
-	RawImport            []*rawImport     `yaml:"import,omitempty"`
-	RawDependencies      []*rawDependency `yaml:"dependencies,omitempty"`
-	Platform             []string         `yaml:"platform,omitempty"`
-	Network              string           `yaml:"network,omitempty"`
-	RawSecrets           []*rawSecret     `yaml:"secrets,omitempty"`
-	RawImageSpec         *rawImageSpec    `yaml:"imageSpec,omitempty"`
+	Images               []string           `yaml:"-"`
+	Final                *bool              `yaml:"final,omitempty"`
+	Artifact             string             `yaml:"artifact,omitempty"`
+	CacheVersion         string             `yaml:"cacheVersion,omitempty"`
+	From                 string             `yaml:"from,omitempty"`
+	FromLatest           bool               `yaml:"fromLatest,omitempty"`
+	FromCacheVersion     string             `yaml:"fromCacheVersion,omitempty"`
+	FromImage            string             `yaml:"fromImage,omitempty"`
+	FromArtifact         string             `yaml:"fromArtifact,omitempty"`
+	DisableGitAfterPatch bool               `yaml:"disableGitAfterPatch,omitempty"`
+	RawGit               []*rawGit          `yaml:"git,omitempty"`
+	RawShell             *rawShell          `yaml:"shell,omitempty"`
+	RawAnsible           *rawAnsible        `yaml:"ansible,omitempty"`
+	RawMount             []*rawMount        `yaml:"mount,omitempty"`
+	RawDocker            *rawDocker         `yaml:"docker,omitempty"`
+	RawImport            []*rawImport       `yaml:"import,omitempty"`
+	RawDependencies      []*rawDependency   `yaml:"dependencies,omitempty"`
+	Platform             []string           `yaml:"platform,omitempty"`
+	Network              string             `yaml:"network,omitempty"`
+	RawSecrets           []*rawSecret       `yaml:"secrets,omitempty"`
+	RawImageSpec         *rawImageSpec      `yaml:"imageSpec,omitempty"`
+	RawExport            []*rawExportTarget `yaml:"export,omitempty"`
+	RawLint              *rawDockerfileLint `yaml:"lint,omitempty"`
 
 	doc *doc `yaml:"-"` // parent
 
@@ -149,6 +151,12 @@ func (c *rawStapelImage) toStapelImageDirective(giterminismManager giterminism_m
 
 	image.StapelImageBase.final = option.PtrValueOrDefault(c.Final, true)
 
+	if export, err := toExportTargetDirectives(c.RawExport, image.StapelImageBase.final, c.doc); err != nil {
+		return nil, err
+	} else {
+		image.Export = export
+	}
+
 	if c.RawDocker != nil {
 		if docker, err := c.RawDocker.toDirective(); err != nil {
 			return nil, err
@@ -215,6 +223,8 @@ func (c *rawStapelImage) validateStapelImageArtifactDirective(imageArtifact *Sta
 		return newDetailedConfigError("`final` directive is not supported for artifact!", nil, c.doc)
 	} else if c.DisableGitAfterPatch {
 		return newDetailedConfigError("`disableGitAfterPatch` directive is not supported for artifact!", nil, c.doc)
+	} else if len(c.RawExport) > 0 {
+		return newDetailedConfigError("`export` directive is not supported for artifact!", nil, c.doc)
 	}
 
 	if err := imageArtifact.validate(); err != nil {
@@ -315,6 +325,15 @@ func (c *rawStapelImage) toStapelImageBaseDirective(giterminismManager gitermini
 		imageBase.ImageSpec = c.RawImageSpec.toDirective()
 	}
 
+	if c.RawLint != nil {
+		lint, err := c.RawLint.toDirective()
+		if err != nil {
+			return nil, err
+		}
+
+		imageBase.Lint = lint
+	}
+
 	if err := c.validateStapelImageBaseDirective(giterminismManager, imageBase); err != nil {
 		return nil, err
 	}
@@ -8,6 +8,7 @@ import (
 	"gopkg.in/yaml.v2"
 
 	"github.com/werf/common-go/pkg/util"
+	"github.com/werf/werf/v2/pkg/dockerfile/linter"
 )
 
 var _ = Describe("rawStapelImage", func() {
@@ -64,6 +65,31 @@ var _ = Describe("rawStapelImage", func() {
 				Docker: nil,
 			},
 		),
+		Entry(
+			"with lint suppressions",
+			map[string]interface{}{
+				"image": "image1",
+				"from":  "alpine:latest",
+				"lint": map[string]interface{}{
+					"suppress": []string{"from-latest-tag"},
+				},
+			},
+			&StapelImage{
+				StapelImageBase: &StapelImageBase{
+					Name:    "image1",
+					From:    "alpine:latest",
+					Git:     &GitManager{},
+					Secrets: []Secret{},
+					Lint: &DockerfileLint{
+						Suppress: []linter.Rule{linter.RuleFromLatestTag},
+					},
+
+					platform: []string{},
+					final:    true,
+				},
+				Docker: nil,
+			},
+		),
 	)
 
 	DescribeTable("unmarshal and convert to directive succeed and produce expected Dependencies",
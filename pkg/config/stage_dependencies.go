@@ -1,15 +1,18 @@
 package config
 
 type StageDependencies struct {
-	Install     []string
-	Setup       []string
-	BeforeSetup []string
+	BeforeInstall []string
+	Install       []string
+	Setup         []string
+	BeforeSetup   []string
 
 	raw *rawStageDependencies
 }
 
 func (c *StageDependencies) validate() error {
 	switch {
+	case !allRelativePaths(c.BeforeInstall):
+		return newDetailedConfigError("`beforeInstall: [PATH, ...]|PATH` should be relative paths!", c.raw, c.raw.rawGit.rawStapelImage.doc)
 	case !allRelativePaths(c.Install):
 		return newDetailedConfigError("`install: [PATH, ...]|PATH` should be relative paths!", c.raw, c.raw.rawGit.rawStapelImage.doc)
 	case !allRelativePaths(c.Setup):
@@ -9,6 +9,7 @@ import (
 type StapelImage struct {
 	*StapelImageBase
 	Docker *Docker
+	Export []*ExportTarget
 }
 
 func (c *StapelImage) validate() error {
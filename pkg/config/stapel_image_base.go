@@ -23,6 +23,7 @@ type StapelImageBase struct {
 	Dependencies     []*Dependency
 	Secrets          []Secret
 	ImageSpec        *ImageSpec
+	Lint             *DockerfileLint
 	Network          string
 
 	FromExternal bool
@@ -72,6 +72,18 @@ func (c *WerfConfig) GetImage(imageName string) ImageInterface {
 	return nil
 }
 
+// GetExportTargets returns the export targets of the image declared in werf.yaml.
+func (c *WerfConfig) GetExportTargets(imageName string) []*ExportTarget {
+	switch image := c.GetImage(imageName).(type) {
+	case *StapelImage:
+		return image.Export
+	case *ImageFromDockerfile:
+		return image.Export
+	default:
+		return nil
+	}
+}
+
 func (c *WerfConfig) validateConflictBetweenImagesNames() error {
 	imageByName := map[string]ImageInterface{}
 	for _, image := range c.Images(false) {
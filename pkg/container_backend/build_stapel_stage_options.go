@@ -50,6 +50,9 @@ type BuildStapelStageOptions struct {
 	DataArchiveSpecs      []DataArchiveSpec
 	RemoveDataSpecs       []RemoveDataSpec
 	DependencyImportSpecs []DependencyImportSpec
+
+	IntrospectBeforeError bool
+	IntrospectAfterError  bool
 }
 
 type ArchiveType int
@@ -16,6 +16,7 @@ import (
 	"strconv"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/containers/storage/types"
 	securejoin "github.com/cyphar/filepath-securejoin"
@@ -23,18 +24,24 @@ import (
 	"github.com/moby/buildkit/frontend/dockerfile/instructions"
 	"github.com/moby/buildkit/frontend/dockerfile/parser"
 	"github.com/opencontainers/runtime-spec/specs-go"
+	"github.com/samber/lo"
 
+	"github.com/werf/common-go/pkg/locker"
 	"github.com/werf/common-go/pkg/util"
 	copyrec "github.com/werf/copy-recurse"
+	"github.com/werf/lockgate"
 	"github.com/werf/logboek"
 	"github.com/werf/logboek/pkg/level"
 	"github.com/werf/werf/v2/pkg/buildah"
 	"github.com/werf/werf/v2/pkg/buildah/thirdparty"
 	"github.com/werf/werf/v2/pkg/container_backend/info"
 	"github.com/werf/werf/v2/pkg/container_backend/prune"
+	"github.com/werf/werf/v2/pkg/docker_registry/mirror"
+	"github.com/werf/werf/v2/pkg/docker_registry/ratelimit"
 	"github.com/werf/werf/v2/pkg/image"
 	"github.com/werf/werf/v2/pkg/opstats"
 	"github.com/werf/werf/v2/pkg/path_matcher"
+	"github.com/werf/werf/v2/pkg/ssh_agent"
 	"github.com/werf/werf/v2/pkg/tmp_manager"
 )
 
@@ -48,8 +55,15 @@ type BuildahBackend struct {
 
 type BuildahBackendOptions struct {
 	TmpDir string
+	// RegistryMirrors are used to pull the images with failover to the origin registry.
+	RegistryMirrors []*mirror.Mirror
+	// HostLocker locks the working containers, so the host cleanup does not remove the containers in use.
+	HostLocker *locker.HostLocker
 }
 
+// buildahUnusedLayerMinAge protects the layers of the builds in progress from pruning.
+const buildahUnusedLayerMinAge = time.Hour
+
 func NewBuildahBackend(buildah buildah.Buildah, opts BuildahBackendOptions) *BuildahBackend {
 	return &BuildahBackend{
 		buildah:               buildah,
@@ -154,68 +168,127 @@ type containerDesc struct {
 	ImageName string
 	Name      string
 	RootMount string
+	lock      *lockgate.LockHandle
 }
 
-func (backend *BuildahBackend) createContainers(ctx context.Context, images []string, opts CommonOpts) ([]*containerDesc, error) {
-	var res []*containerDesc
+// lockContainer prevents the host cleanup from removing the working container in use.
+func (backend *BuildahBackend) lockContainer(ctx context.Context, containerName string) (*lockgate.LockHandle, error) {
+	if backend.HostLocker == nil {
+		return nil, nil
+	}
 
-	for _, img := range images {
-		containerID := fmt.Sprintf("werf-%s", uuid.New().String())
+	containerLockName := ContainerLockName(containerName)
+	_, lock, err := backend.HostLocker.AcquireLock(ctx, containerLockName, lockgate.AcquireOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to lock %s: %w", containerLockName, err)
+	}
+
+	return &lock, nil
+}
+
+func (backend *BuildahBackend) unlockContainer(lock *lockgate.LockHandle) error {
+	if lock == nil {
+		return nil
+	}
+	return backend.HostLocker.ReleaseLock(*lock)
+}
 
-		if img == "" {
-			panic("cannot start container for an empty image param")
+// createContainers creates the containers for the images. If any container cannot be created,
+// the already created containers are removed and unlocked, because they are not returned to the caller.
+func (backend *BuildahBackend) createContainers(ctx context.Context, images []string, opts CommonOpts) (_ []*containerDesc, resErr error) {
+	var res []*containerDesc
+	defer func() {
+		if resErr == nil {
+			return
 		}
 
-		resolvedImg := img
-		usedCachedImageID := false
-		if opts.TargetPlatform != "" {
-			if id, ok := backend.getPulledImageID(img, opts.TargetPlatform); ok {
-				resolvedImg = id
-				usedCachedImageID = true
-			} else if inspect, err := backend.buildah.Inspect(ctx, img); err == nil && inspect != nil {
-				if !platformMatches(inspect, opts.TargetPlatform) {
-					return nil, fmt.Errorf("local image %q has platform %s/%s, but target platform is %q; pull the correct image first", img, inspect.OCIv1.OS, inspect.OCIv1.Architecture, opts.TargetPlatform)
-				}
+		for _, cont := range res {
+			if err := backend.buildah.Rm(ctx, cont.Name, buildah.RmOpts(backend.getBuildahCommonOpts(ctx, true, nil, opts.TargetPlatform))); err != nil {
+				logboek.Context(ctx).Warn().LogF("WARNING: unable to remove container %q: %s\n", cont.Name, err)
+			}
+			if err := backend.unlockContainer(cont.lock); err != nil {
+				logboek.Context(ctx).Warn().LogF("WARNING: unable to unlock container %q: %s\n", cont.Name, err)
 			}
 		}
+	}()
 
-		fromCommandOpts := buildah.FromCommandOpts(backend.getBuildahCommonOpts(ctx, true, nil, opts.TargetPlatform))
-		_, err := backend.buildah.FromCommand(ctx, containerID, resolvedImg, fromCommandOpts)
-		if err != nil && opts.TargetPlatform != "" && usedCachedImageID && isImageNotKnownError(err) {
-			logboek.Context(ctx).Debug().LogF("Cached imageID %q for %q not found locally, pulling by ref and retrying\n", resolvedImg, img)
+	for _, img := range images {
+		cont, err := backend.createContainer(ctx, img, opts)
+		if err != nil {
+			return nil, err
+		}
 
-			pulledImageID, pullErr := func() (string, error) {
-				mu := backend.getPullMutex(img)
-				mu.Lock()
-				defer mu.Unlock()
+		res = append(res, cont)
+	}
 
-				pulledImageID, pullErr := backend.buildah.Pull(ctx, img, buildah.PullOpts(backend.getBuildahCommonOpts(ctx, true, nil, opts.TargetPlatform)))
-				if pullErr == nil && pulledImageID != "" {
-					backend.storePulledImageID(img, opts.TargetPlatform, pulledImageID)
-				}
+	return res, nil
+}
+
+// createContainer creates the locked container for the image. The lock is released if the container cannot be created.
+func (backend *BuildahBackend) createContainer(ctx context.Context, img string, opts CommonOpts) (_ *containerDesc, resErr error) {
+	containerID := fmt.Sprintf("%s%s", image.BuildahContainerNamePrefix, uuid.New().String())
+
+	if img == "" {
+		panic("cannot start container for an empty image param")
+	}
+
+	lock, err := backend.lockContainer(ctx, containerID)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if resErr != nil {
+			_ = backend.unlockContainer(lock)
+		}
+	}()
 
-				return pulledImageID, pullErr
-			}()
-			if pullErr != nil {
-				return nil, fmt.Errorf("unable to pull image %q after cached imageID miss: %w", img, pullErr)
+	resolvedImg := img
+	usedCachedImageID := false
+	if opts.TargetPlatform != "" {
+		if id, ok := backend.getPulledImageID(img, opts.TargetPlatform); ok {
+			resolvedImg = id
+			usedCachedImageID = true
+		} else if inspect, err := backend.buildah.Inspect(ctx, img); err == nil && inspect != nil {
+			if !platformMatches(inspect, opts.TargetPlatform) {
+				return nil, fmt.Errorf("local image %q has platform %s/%s, but target platform is %q; pull the correct image first", img, inspect.OCIv1.OS, inspect.OCIv1.Architecture, opts.TargetPlatform)
 			}
+		}
+	}
+
+	fromCommandOpts := buildah.FromCommandOpts(backend.getBuildahCommonOpts(ctx, true, nil, opts.TargetPlatform))
+	_, err = backend.buildah.FromCommand(ctx, containerID, resolvedImg, fromCommandOpts)
+	if err != nil && opts.TargetPlatform != "" && usedCachedImageID && isImageNotKnownError(err) {
+		logboek.Context(ctx).Debug().LogF("Cached imageID %q for %q not found locally, pulling by ref and retrying\n", resolvedImg, img)
+
+		pulledImageID, pullErr := func() (string, error) {
+			mu := backend.getPullMutex(img)
+			mu.Lock()
+			defer mu.Unlock()
 
-			resolvedImg = img
-			if pulledImageID != "" {
-				resolvedImg = pulledImageID
+			pulledImageID, pullErr := backend.pullFromMirrors(ctx, img, backend.getBuildahCommonOpts(ctx, true, nil, opts.TargetPlatform))
+			if pullErr == nil && pulledImageID != "" {
+				backend.storePulledImageID(img, opts.TargetPlatform, pulledImageID)
 			}
 
-			_, err = backend.buildah.FromCommand(ctx, containerID, resolvedImg, fromCommandOpts)
+			return pulledImageID, pullErr
+		}()
+		if pullErr != nil {
+			return nil, fmt.Errorf("unable to pull image %q after cached imageID miss: %w", img, pullErr)
 		}
-		if err != nil {
-			return nil, fmt.Errorf("unable to create container using base image %q: %w", img, err)
+
+		resolvedImg = img
+		if pulledImageID != "" {
+			resolvedImg = pulledImageID
 		}
 
-		logboek.Context(ctx).Debug().LogF("Started container %q for image %q (resolved %q)\n", containerID, img, resolvedImg)
-		res = append(res, &containerDesc{ImageName: img, Name: containerID})
+		_, err = backend.buildah.FromCommand(ctx, containerID, resolvedImg, fromCommandOpts)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("unable to create container using base image %q: %w", img, err)
 	}
 
-	return res, nil
+	logboek.Context(ctx).Debug().LogF("Started container %q for image %q (resolved %q)\n", containerID, img, resolvedImg)
+	return &containerDesc{ImageName: img, Name: containerID, lock: lock}, nil
 }
 
 func (backend *BuildahBackend) removeContainers(ctx context.Context, containers []*containerDesc, opts CommonOpts) error {
@@ -223,6 +296,9 @@ func (backend *BuildahBackend) removeContainers(ctx context.Context, containers
 		if err := backend.buildah.Rm(ctx, cont.Name, buildah.RmOpts(backend.getBuildahCommonOpts(ctx, true, nil, opts.TargetPlatform))); err != nil {
 			return fmt.Errorf("unable to remove container %q: %w", cont.Name, err)
 		}
+		if err := backend.unlockContainer(cont.lock); err != nil {
+			return fmt.Errorf("unable to unlock container %q: %w", cont.Name, err)
+		}
 	}
 
 	return nil
@@ -759,6 +835,10 @@ func (backend *BuildahBackend) BuildDockerfileStage(ctx context.Context, baseIma
 
 	for _, instruction := range instructions {
 		if err := instruction.Apply(ctx, container.Name, backend.buildah, backend.getBuildahCommonOpts(ctx, false, nil, opts.TargetPlatform), opts.BuildContextArchive); err != nil {
+			if introspectErr := backend.introspectDockerfileStageError(ctx, baseImage, container, instruction, opts); introspectErr != nil {
+				return "", fmt.Errorf("introspect error failed (original error %s): %w", err, introspectErr)
+			}
+
 			return "", fmt.Errorf("unable to apply instruction %s: %w", instruction.Name(), err)
 		}
 	}
@@ -789,7 +869,6 @@ func (backend *BuildahBackend) BuildStapelStage(ctx context.Context, baseImage s
 			logboek.Context(ctx).Error().LogF("ERROR: unable to remove temporal build container: %s\n", err)
 		}
 	}()
-	// TODO(stapel-to-buildah): cleanup orphan build containers in werf-host-cleanup procedure
 
 	if len(opts.DependencyImportSpecs)+len(opts.DataArchiveSpecs)+len(opts.RemoveDataSpecs) > 0 {
 		if Debug() {
@@ -825,6 +904,10 @@ func (backend *BuildahBackend) BuildStapelStage(ctx context.Context, baseImage s
 	}
 	if len(opts.Commands) > 0 {
 		if err := backend.applyCommands(ctx, container, opts, commonOpts); err != nil {
+			if introspectErr := backend.introspectStapelStageError(ctx, baseImage, container, opts); introspectErr != nil {
+				return "", fmt.Errorf("introspect error failed (original error %s): %w", err, introspectErr)
+			}
+
 			return "", err
 		}
 	}
@@ -861,6 +944,213 @@ func (backend *BuildahBackend) BuildStapelStage(ctx context.Context, baseImage s
 	return imgID, nil
 }
 
+func (backend *BuildahBackend) Introspect(ctx context.Context, ref string, opts IntrospectOpts) error {
+	var container *containerDesc
+	if c, err := backend.createContainers(ctx, []string{ref}, opts.CommonOpts); err != nil {
+		return err
+	} else {
+		container = c[0]
+	}
+	defer func() {
+		if err := backend.removeContainers(ctx, []*containerDesc{container}, opts.CommonOpts); err != nil {
+			logboek.Context(ctx).Error().LogF("ERROR: unable to remove temporal introspection container: %s\n", err)
+		}
+	}()
+
+	return backend.introspectContainer(ctx, container.Name, opts)
+}
+
+// Run runs the command of the image in the temporary container. Buildah cannot map ports, so the published ports
+// require the host network and the same host and container port.
+func (backend *BuildahBackend) Run(ctx context.Context, ref string, opts RunOpts) error {
+	if len(opts.DockerOptions) > 0 {
+		return fmt.Errorf("docker options are not supported by the buildah backend")
+	}
+
+	networkType := opts.Network
+	if len(opts.Ports) > 0 {
+		if networkType != "" && networkType != "host" {
+			return fmt.Errorf("publishing ports with the buildah backend requires the host network, got %q", networkType)
+		}
+		networkType = "host"
+
+		for _, port := range opts.Ports {
+			if err := validateBuildahPublishPort(port); err != nil {
+				return err
+			}
+		}
+	}
+
+	command, err := backend.resolveRunCommand(ctx, ref, opts)
+	if err != nil {
+		return err
+	}
+
+	mounts, err := makeBuildahMounts(opts.Volumes)
+	if err != nil {
+		return err
+	}
+
+	var container *containerDesc
+	if c, err := backend.createContainers(ctx, []string{ref}, opts.CommonOpts); err != nil {
+		return err
+	} else {
+		container = c[0]
+	}
+	defer func() {
+		if err := backend.removeContainers(ctx, []*containerDesc{container}, opts.CommonOpts); err != nil {
+			logboek.Context(ctx).Error().LogF("ERROR: unable to remove run container: %s\n", err)
+		}
+	}()
+
+	runOpts := buildah.RunCommandOpts{
+		CommonOpts:   backend.getBuildahCommonOpts(ctx, true, nil, opts.TargetPlatform),
+		User:         opts.User,
+		WorkingDir:   opts.WorkDir,
+		Envs:         opts.Envs,
+		GlobalMounts: mounts,
+		NetworkType:  networkType,
+	}
+	if opts.TTY {
+		runOpts.Interactive = true
+	} else {
+		runOpts.Stdout = os.Stdout
+		runOpts.Stderr = os.Stderr
+		if opts.Interactive {
+			runOpts.Stdin = os.Stdin
+		}
+	}
+
+	return logboek.Context(ctx).Streams().DoErrorWithoutProxyStreamDataFormatting(func() error {
+		return backend.buildah.RunCommand(ctx, container.Name, command, runOpts)
+	})
+}
+
+// resolveRunCommand combines the entrypoint and the command the same way "docker run" does:
+// the entrypoint override drops the command of the image.
+func (backend *BuildahBackend) resolveRunCommand(ctx context.Context, ref string, opts RunOpts) ([]string, error) {
+	if len(opts.Entrypoint) > 0 {
+		return append(append([]string{}, opts.Entrypoint...), opts.Command...), nil
+	}
+
+	inspect, err := backend.buildah.Inspect(ctx, ref)
+	if err != nil {
+		return nil, fmt.Errorf("inspect image %q: %w", ref, err)
+	}
+	if inspect == nil {
+		return nil, fmt.Errorf("image %q not found", ref)
+	}
+
+	command := append([]string{}, inspect.OCIv1.Config.Entrypoint...)
+	if len(opts.Command) > 0 {
+		command = append(command, opts.Command...)
+	} else {
+		command = append(command, inspect.OCIv1.Config.Cmd...)
+	}
+
+	if len(command) == 0 {
+		return nil, fmt.Errorf("no command specified for image %q", ref)
+	}
+
+	return command, nil
+}
+
+// validateBuildahPublishPort accepts "[HOST_IP:][HOST_PORT:]CONTAINER_PORT[/PROTOCOL]" only if HOST_PORT is omitted
+// or equal to CONTAINER_PORT.
+func validateBuildahPublishPort(spec string) error {
+	parts := strings.Split(strings.SplitN(spec, "/", 2)[0], ":")
+	if len(parts) > 3 {
+		return fmt.Errorf("wrong port format %q: expected [HOST_IP:][HOST_PORT:]CONTAINER_PORT[/PROTOCOL]", spec)
+	}
+
+	containerPort := parts[len(parts)-1]
+	if port, err := strconv.ParseUint(containerPort, 10, 16); err != nil || port == 0 {
+		return fmt.Errorf("invalid container port %q in %q", containerPort, spec)
+	}
+
+	if len(parts) > 1 {
+		if hostPort := parts[len(parts)-2]; hostPort != "" && hostPort != containerPort {
+			return fmt.Errorf("unable to publish port %q: the buildah backend uses the host network and cannot map host port %s to container port %s", spec, hostPort, containerPort)
+		}
+	}
+
+	return nil
+}
+
+func (backend *BuildahBackend) introspectContainer(ctx context.Context, containerName string, opts IntrospectOpts) error {
+	mounts, err := makeBuildahMounts(opts.BuildVolumes)
+	if err != nil {
+		return err
+	}
+
+	return runIntrospectShell(ctx, func() error {
+		return backend.buildah.RunCommand(ctx, containerName, IntrospectShellCommand, buildah.RunCommandOpts{
+			CommonOpts:   backend.getBuildahCommonOpts(ctx, true, nil, opts.TargetPlatform),
+			User:         opts.User,
+			Envs:         opts.Envs,
+			GlobalMounts: mounts,
+			RunMounts:    makeBuildahIntrospectRunMounts(opts.Secrets, opts.SSH),
+			Secrets:      opts.Secrets,
+			SSH:          opts.SSH,
+			Interactive:  true,
+		})
+	})
+}
+
+// introspectStapelStageError runs the introspection shell either in the base image (before error)
+// or in the failed build container with the partial changes (after error).
+func (backend *BuildahBackend) introspectStapelStageError(ctx context.Context, baseImage string, container *containerDesc, opts BuildStapelStageOptions) error {
+	if !opts.IntrospectBeforeError && !opts.IntrospectAfterError {
+		return nil
+	}
+
+	logboek.Context(ctx).Default().LogFDetails("Launched command: %s\n", strings.Join(opts.Commands, " && "))
+
+	introspectOpts := IntrospectOpts{
+		CommonOpts:   CommonOpts{TargetPlatform: opts.TargetPlatform},
+		User:         "0:0",
+		Envs:         makeBuildahEnvs(opts.Envs),
+		BuildVolumes: opts.BuildVolumes,
+	}
+
+	if opts.IntrospectBeforeError {
+		return backend.Introspect(ctx, baseImage, introspectOpts)
+	}
+
+	return backend.introspectContainer(ctx, container.Name, introspectOpts)
+}
+
+// introspectDockerfileStageError runs the introspection shell either in the base image (before error)
+// or in the failed build container with the partial changes (after error).
+// The RUN instruction shell gets the same mounts, secrets and environment as the failed command.
+func (backend *BuildahBackend) introspectDockerfileStageError(ctx context.Context, baseImage string, container *containerDesc, failedInstruction InstructionInterface, opts BuildDockerfileStageOptions) error {
+	if !opts.IntrospectBeforeError && !opts.IntrospectAfterError {
+		return nil
+	}
+
+	introspectContainer := container
+	if opts.IntrospectBeforeError {
+		if c, err := backend.createContainers(ctx, []string{baseImage}, opts.CommonOpts); err != nil {
+			return err
+		} else {
+			introspectContainer = c[0]
+		}
+		defer func() {
+			if err := backend.removeContainers(ctx, []*containerDesc{introspectContainer}, opts.CommonOpts); err != nil {
+				logboek.Context(ctx).Error().LogF("ERROR: unable to remove temporal introspection container: %s\n", err)
+			}
+		}()
+	}
+
+	if i, ok := failedInstruction.(IntrospectableInstructionInterface); ok {
+		return runIntrospectShell(ctx, func() error {
+			return i.Introspect(ctx, introspectContainer.Name, backend.buildah, backend.getBuildahCommonOpts(ctx, true, nil, opts.TargetPlatform), opts.BuildContextArchive)
+		})
+	}
+
+	return backend.introspectContainer(ctx, introspectContainer.Name, IntrospectOpts{CommonOpts: opts.CommonOpts})
+}
+
 // GetImageInfo returns nil, nil if image not found.
 func (backend *BuildahBackend) GetImageInfo(ctx context.Context, ref string, opts GetImageInfoOpts) (*image.Info, error) {
 	defer opstats.Observe(ctx, opstats.OperationImageInspect)()
@@ -931,6 +1221,7 @@ func (backend *BuildahBackend) GetImageInfo(ctx context.Context, ref string, opt
 		CreatedAtUnixNano: inspect.Docker.Created.UnixNano(),
 		OnBuild:           inspect.Docker.Config.OnBuild,
 		Env:               inspect.Docker.Config.Env,
+		User:              lo.ToPtr(inspect.Docker.Config.User),
 		ID:                imageID,
 		ParentID:          parentID,
 		Size:              inspect.Docker.Size,
@@ -976,10 +1267,7 @@ func (backend *BuildahBackend) Pull(ctx context.Context, ref string, opts PullOp
 		logWriter = logboek.Context(ctx).OutStream()
 	}
 
-	imageID, err := backend.buildah.Pull(
-		ctx, ref,
-		buildah.PullOpts(backend.getBuildahCommonOpts(ctx, false, logWriter, opts.TargetPlatform)),
-	)
+	imageID, err := backend.pullFromMirrors(ctx, ref, backend.getBuildahCommonOpts(ctx, false, logWriter, opts.TargetPlatform))
 	if err != nil {
 		return err
 	}
@@ -991,6 +1279,33 @@ func (backend *BuildahBackend) Pull(ctx context.Context, ref string, opts PullOp
 	return nil
 }
 
+// pullFromMirrors pulls the image from the registry mirrors or from the origin registry and returns the image id.
+// It should be called under the pull mutex of the ref.
+func (backend *BuildahBackend) pullFromMirrors(ctx context.Context, ref string, commonOpts buildah.CommonOpts) (string, error) {
+	var imageID string
+	err := pullFromMirrors(ctx, ref, backend.RegistryMirrors, pullFromMirrorsOptions{
+		Pull: func(ctx context.Context, endpointRef string) error {
+			id, err := backend.buildah.Pull(ctx, endpointRef, buildah.PullOpts(commonOpts))
+			if err != nil {
+				return err
+			}
+
+			imageID = id
+			return nil
+		},
+		Retag: func(ctx context.Context, mirrorRef, ref string) error {
+			if err := backend.buildah.Tag(ctx, mirrorRef, ref, buildah.TagOpts(commonOpts)); err != nil {
+				return err
+			}
+
+			// The image is kept by the original reference, so only the mirror name is removed.
+			return backend.buildah.Rmi(ctx, mirrorRef, buildah.RmiOpts{CommonOpts: commonOpts})
+		},
+	})
+
+	return imageID, err
+}
+
 func (backend *BuildahBackend) Tag(ctx context.Context, ref, newRef string, opts TagOpts) error {
 	var logWriter io.Writer
 	if logboek.Context(ctx).Info().IsAccepted() {
@@ -1012,10 +1327,12 @@ func (backend *BuildahBackend) Push(ctx context.Context, ref string, opts PushOp
 		logWriter = io.Discard
 	}
 
-	return backend.buildah.Push(
-		ctx, ref,
-		buildah.PushOpts(backend.getBuildahCommonOpts(ctx, false, logWriter, opts.TargetPlatform)),
-	)
+	return ratelimit.DefaultScheduler.Do(ctx, ref, 0, func() error {
+		return backend.buildah.Push(
+			ctx, ref,
+			buildah.PushOpts(backend.getBuildahCommonOpts(ctx, false, logWriter, opts.TargetPlatform)),
+		)
+	})
 }
 
 func (backend *BuildahBackend) TagImageByName(ctx context.Context, img LegacyImageInterface) error {
@@ -1219,6 +1536,26 @@ func makeBuildahMounts(volumes []string) ([]*specs.Mount, error) {
 	return mounts, nil
 }
 
+// makeBuildahIntrospectRunMounts mounts the secrets and the ssh agent socket the same way as the RUN instruction with
+// --mount=type=secret and --mount=type=ssh does.
+func makeBuildahIntrospectRunMounts(secrets []string, ssh string) []*instructions.Mount {
+	var mounts []*instructions.Mount
+
+	for _, secret := range secrets {
+		for _, field := range strings.Split(secret, ",") {
+			if id, ok := strings.CutPrefix(field, "id="); ok {
+				mounts = append(mounts, &instructions.Mount{Type: instructions.MountTypeSecret, CacheID: id})
+			}
+		}
+	}
+
+	if ssh != "" || ssh_agent.SSHAuthSock != "" {
+		mounts = append(mounts, &instructions.Mount{Type: instructions.MountTypeSSH})
+	}
+
+	return mounts
+}
+
 func makeBuildahEnvs(envs map[string]string) []string {
 	env := make([]string, 0, len(envs))
 	for k, v := range envs {
@@ -1430,8 +1767,16 @@ func (backend *BuildahBackend) PruneImages(ctx context.Context, options prune.Op
 	return prune.Report(report), nil
 }
 
-func (backend *BuildahBackend) PruneVolumes(_ context.Context, _ prune.Options) (prune.Report, error) {
-	return prune.Report{}, ErrUnsupportedFeature
+// PruneVolumes removes the layers which are not referred to by any image or container and the store remainders of
+// the removed containers, including the mounted stapel and import volumes. There are no named volumes in Buildah.
+func (backend *BuildahBackend) PruneVolumes(ctx context.Context, _ prune.Options) (prune.Report, error) {
+	report, err := backend.buildah.PruneLayers(ctx, buildah.PruneLayersOptions{
+		Until: buildahUnusedLayerMinAge,
+	})
+	if err != nil {
+		return prune.Report{}, fmt.Errorf("unable to prune layers: %w", err)
+	}
+	return prune.Report(report), nil
 }
 
 func (backend *BuildahBackend) SaveImageToStream(ctx context.Context, imageName string) (io.ReadCloser, error) {
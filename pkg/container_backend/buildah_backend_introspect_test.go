@@ -0,0 +1,118 @@
+package container_backend
+
+import (
+	"context"
+	"errors"
+
+	"github.com/moby/buildkit/frontend/dockerfile/instructions"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/werf/werf/v2/pkg/buildah"
+	werfExec "github.com/werf/werf/v2/pkg/werf/exec"
+	"github.com/werf/werf/v2/test/pkg/buildahstub"
+)
+
+var _ = Describe("BuildahBackend introspection", func() {
+	type runCommandCall struct {
+		Container string
+		Command   []string
+		Opts      buildah.RunCommandOpts
+	}
+
+	var fakeBuildah *buildahstub.BuildahStub
+	var backend *BuildahBackend
+	var runCommandCalls []runCommandCall
+	var runCommandErr error
+
+	BeforeEach(func() {
+		runCommandCalls = nil
+		runCommandErr = nil
+
+		fakeBuildah = &buildahstub.BuildahStub{}
+		fakeBuildah.RunCommandFunc = func(_ context.Context, container string, command []string, opts buildah.RunCommandOpts) error {
+			runCommandCalls = append(runCommandCalls, runCommandCall{Container: container, Command: command, Opts: opts})
+			return runCommandErr
+		}
+
+		backend = NewBuildahBackend(fakeBuildah, BuildahBackendOptions{})
+	})
+
+	It("runs the interactive shell in the container created from the image", func() {
+		Expect(backend.Introspect(context.Background(), "image:tag", IntrospectOpts{
+			User:         "0:0",
+			Envs:         []string{"A=B"},
+			BuildVolumes: []string{"/host:/container"},
+			Secrets:      []string{"id=secret,src=/tmp/secret"},
+		})).To(Succeed())
+
+		Expect(fakeBuildah.FromCommandImages).To(Equal([]string{"image:tag"}))
+		Expect(runCommandCalls).To(HaveLen(1))
+		Expect(runCommandCalls[0].Command).To(Equal(IntrospectShellCommand))
+		Expect(runCommandCalls[0].Opts.Interactive).To(BeTrue())
+		Expect(runCommandCalls[0].Opts.User).To(Equal("0:0"))
+		Expect(runCommandCalls[0].Opts.Envs).To(Equal([]string{"A=B"}))
+		Expect(runCommandCalls[0].Opts.Secrets).To(Equal([]string{"id=secret,src=/tmp/secret"}))
+		Expect(runCommandCalls[0].Opts.GlobalMounts).To(HaveLen(1))
+		Expect(runCommandCalls[0].Opts.GlobalMounts[0].Source).To(Equal("/host"))
+		Expect(runCommandCalls[0].Opts.GlobalMounts[0].Destination).To(Equal("/container"))
+	})
+
+	It("mounts the secrets and the ssh agent socket", func() {
+		Expect(backend.Introspect(context.Background(), "image:tag", IntrospectOpts{
+			Secrets: []string{"id=npmrc,src=/tmp/npmrc", "id=token,env=TOKEN"},
+			SSH:     "default",
+		})).To(Succeed())
+
+		Expect(runCommandCalls).To(HaveLen(1))
+		Expect(runCommandCalls[0].Opts.SSH).To(Equal("default"))
+		Expect(runCommandCalls[0].Opts.RunMounts).To(Equal([]*instructions.Mount{
+			{Type: instructions.MountTypeSecret, CacheID: "npmrc"},
+			{Type: instructions.MountTypeSecret, CacheID: "token"},
+			{Type: instructions.MountTypeSSH},
+		}))
+	})
+
+	It("ignores the exit code of the shell", func() {
+		runCommandErr = werfExec.NewExitCodeError(3, errors.New("RunCommand failed"))
+
+		Expect(backend.Introspect(context.Background(), "image:tag", IntrospectOpts{})).To(Succeed())
+	})
+
+	It("returns the error if the shell cannot be started", func() {
+		runCommandErr = errors.New("runtime error")
+
+		Expect(backend.Introspect(context.Background(), "image:tag", IntrospectOpts{})).To(MatchError("runtime error"))
+	})
+
+	DescribeTable("introspectStapelStageError",
+		func(introspectBeforeError, introspectAfterError bool, expectedFromCommandImages []string, expectedContainer string) {
+			container := &containerDesc{Name: "build-container"}
+			Expect(backend.introspectStapelStageError(context.Background(), "base:tag", container, BuildStapelStageOptions{
+				BuildVolumes:          []string{"/host:/container"},
+				Commands:              []string{"false"},
+				IntrospectBeforeError: introspectBeforeError,
+				IntrospectAfterError:  introspectAfterError,
+			})).To(Succeed())
+
+			if expectedFromCommandImages == nil {
+				Expect(fakeBuildah.FromCommandImages).To(BeEmpty())
+			} else {
+				Expect(fakeBuildah.FromCommandImages).To(Equal(expectedFromCommandImages))
+			}
+
+			if expectedContainer == "" {
+				Expect(runCommandCalls).To(BeEmpty())
+				return
+			}
+
+			Expect(runCommandCalls).To(HaveLen(1))
+			Expect(runCommandCalls[0].Container).To(HavePrefix(expectedContainer))
+			Expect(runCommandCalls[0].Opts.Interactive).To(BeTrue())
+			Expect(runCommandCalls[0].Opts.GlobalMounts).To(HaveLen(1))
+		},
+		Entry("without introspection", false, false, nil, ""),
+		Entry("before error in the new container from the base image", true, false, []string{"base:tag"}, "werf-"),
+		Entry("after error in the failed build container", false, true, nil, "build-container"),
+	)
+})
@@ -0,0 +1,125 @@
+package container_backend
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	v1 "github.com/opencontainers/image-spec/specs-go/v1"
+
+	"github.com/werf/werf/v2/pkg/buildah"
+	"github.com/werf/werf/v2/pkg/buildah/thirdparty"
+	"github.com/werf/werf/v2/test/pkg/buildahstub"
+)
+
+var _ = Describe("BuildahBackend run", func() {
+	type runCommandCall struct {
+		Command []string
+		Opts    buildah.RunCommandOpts
+	}
+
+	var fakeBuildah *buildahstub.BuildahStub
+	var backend *BuildahBackend
+	var runCommandCalls []runCommandCall
+
+	BeforeEach(func() {
+		runCommandCalls = nil
+
+		fakeBuildah = &buildahstub.BuildahStub{}
+		fakeBuildah.RunCommandFunc = func(_ context.Context, _ string, command []string, opts buildah.RunCommandOpts) error {
+			runCommandCalls = append(runCommandCalls, runCommandCall{Command: command, Opts: opts})
+			return nil
+		}
+		fakeBuildah.InspectFunc = func(context.Context, string) (*thirdparty.BuilderInfo, error) {
+			info := &thirdparty.BuilderInfo{}
+			info.OCIv1.Config = v1.ImageConfig{Entrypoint: []string{"/entrypoint.sh"}, Cmd: []string{"serve"}}
+			return info, nil
+		}
+
+		backend = NewBuildahBackend(fakeBuildah, BuildahBackendOptions{})
+	})
+
+	DescribeTable("resolves the command like docker run",
+		func(opts RunOpts, expectedCommand []string) {
+			Expect(backend.Run(context.Background(), "image:tag", opts)).To(Succeed())
+
+			Expect(fakeBuildah.FromCommandImages).To(Equal([]string{"image:tag"}))
+			Expect(runCommandCalls).To(HaveLen(1))
+			Expect(runCommandCalls[0].Command).To(Equal(expectedCommand))
+		},
+		Entry("image entrypoint and command", RunOpts{}, []string{"/entrypoint.sh", "serve"}),
+		Entry("command override", RunOpts{Command: []string{"migrate", "up"}}, []string{"/entrypoint.sh", "migrate", "up"}),
+		Entry("entrypoint override drops the image command", RunOpts{Entrypoint: []string{"/bin/sh"}}, []string{"/bin/sh"}),
+		Entry("entrypoint and command overrides", RunOpts{Entrypoint: []string{"/bin/sh"}, Command: []string{"-c", "id"}}, []string{"/bin/sh", "-c", "id"}),
+	)
+
+	It("passes the container options to buildah", func() {
+		Expect(backend.Run(context.Background(), "image:tag", RunOpts{
+			User:    "1000",
+			WorkDir: "/app",
+			Envs:    []string{"A=B"},
+			Volumes: []string{"/host:/container"},
+			Ports:   []string{"8080:8080", "9090"},
+		})).To(Succeed())
+
+		Expect(runCommandCalls).To(HaveLen(1))
+		opts := runCommandCalls[0].Opts
+		Expect(opts.User).To(Equal("1000"))
+		Expect(opts.WorkingDir).To(Equal("/app"))
+		Expect(opts.Envs).To(Equal([]string{"A=B"}))
+		Expect(opts.NetworkType).To(Equal("host"))
+		Expect(opts.GlobalMounts).To(HaveLen(1))
+		Expect(opts.GlobalMounts[0].Destination).To(Equal("/container"))
+		Expect(opts.Interactive).To(BeFalse())
+		Expect(opts.Stdout).NotTo(BeNil())
+		Expect(opts.Stdin).To(BeNil())
+	})
+
+	It("allocates the terminal in the tty mode", func() {
+		Expect(backend.Run(context.Background(), "image:tag", RunOpts{Entrypoint: []string{"/bin/sh"}, Interactive: true, TTY: true})).To(Succeed())
+
+		Expect(runCommandCalls).To(HaveLen(1))
+		Expect(runCommandCalls[0].Opts.Interactive).To(BeTrue())
+	})
+
+	DescribeTable("rejects unsupported options",
+		func(opts RunOpts, expectedErr string) {
+			Expect(backend.Run(context.Background(), "image:tag", opts)).To(MatchError(ContainSubstring(expectedErr)))
+			Expect(runCommandCalls).To(BeEmpty())
+			Expect(fakeBuildah.FromCommandImages).To(BeEmpty())
+		},
+		Entry("docker options", RunOpts{DockerOptions: []string{"--privileged"}}, "docker options are not supported"),
+		Entry("port mapping", RunOpts{Ports: []string{"8080:80"}}, "cannot map host port 8080 to container port 80"),
+		Entry("ports with the non-host network", RunOpts{Ports: []string{"80"}, Network: "bridge"}, "requires the host network"),
+		Entry("invalid port", RunOpts{Ports: []string{"http"}}, "invalid container port"),
+	)
+
+	It("fails if the image has no command", func() {
+		fakeBuildah.InspectFunc = func(context.Context, string) (*thirdparty.BuilderInfo, error) {
+			return &thirdparty.BuilderInfo{}, nil
+		}
+
+		Expect(backend.Run(context.Background(), "image:tag", RunOpts{})).To(MatchError(ContainSubstring("no command specified")))
+	})
+})
+
+var _ = DescribeTable("DockerRunArgs",
+	func(opts RunOpts, expected []string) {
+		Expect(DockerRunArgs("image:tag", opts)).To(Equal(expected))
+	},
+	Entry("defaults", RunOpts{}, []string{"image:tag"}),
+	Entry("shell", RunOpts{Entrypoint: []string{"/bin/sh"}, Interactive: true, TTY: true, Rm: true}, []string{"-i", "-t", "--rm", "--entrypoint", "/bin/sh", "image:tag"}),
+	Entry("container options",
+		RunOpts{
+			CommonOpts: CommonOpts{TargetPlatform: "linux/arm64"},
+			User:       "1000",
+			WorkDir:    "/app",
+			Envs:       []string{"A=B"},
+			Volumes:    []string{"/host:/container"},
+			Ports:      []string{"8080:80"},
+			Command:    []string{"serve"},
+		},
+		[]string{"--platform", "linux/arm64", "--user", "1000", "--workdir", "/app", "--env", "A=B", "--volume", "/host:/container", "--publish", "8080:80", "image:tag", "serve"},
+	),
+	Entry("docker options go before the image", RunOpts{DockerOptions: []string{"--privileged"}, Entrypoint: []string{"/bin/sh", "-c"}, Command: []string{"id"}}, []string{"--entrypoint", "/bin/sh", "--privileged", "image:tag", "-c", "id"}),
+)
@@ -325,6 +325,28 @@ var _ = Describe("BuildahBackend createContainers", func() {
 		Expect(cachedID).To(Equal("sha256:fresh"))
 	})
 
+	It("removes the created containers when a later container cannot be created", func() {
+		var createdContainers []string
+
+		fakeBuildah := &buildahstub.BuildahStub{}
+		fakeBuildah.FromCommandFunc = func(_ context.Context, container, imageRef string, _ buildah.FromCommandOpts) (string, error) {
+			if imageRef == "broken" {
+				return "", errors.New("broken image")
+			}
+
+			createdContainers = append(createdContainers, container)
+			return container, nil
+		}
+
+		backend := NewBuildahBackend(fakeBuildah, BuildahBackendOptions{})
+
+		containers, err := backend.createContainers(context.Background(), []string{"alpine", "broken"}, CommonOpts{})
+		Expect(err).To(HaveOccurred())
+		Expect(containers).To(BeNil())
+		Expect(createdContainers).To(HaveLen(1))
+		Expect(fakeBuildah.RmContainers).To(Equal(createdContainers))
+	})
+
 	It("serializes re-pulls after cached imageID misses", func() {
 		const (
 			imageRef     = "registry.example.org/project/stage:tag"
@@ -22,6 +22,8 @@ import (
 	"github.com/werf/werf/v2/pkg/container_backend/info"
 	"github.com/werf/werf/v2/pkg/container_backend/prune"
 	"github.com/werf/werf/v2/pkg/docker"
+	"github.com/werf/werf/v2/pkg/docker_registry/mirror"
+	"github.com/werf/werf/v2/pkg/docker_registry/ratelimit"
 	"github.com/werf/werf/v2/pkg/image"
 	"github.com/werf/werf/v2/pkg/opstats"
 	"github.com/werf/werf/v2/pkg/ssh_agent"
@@ -29,12 +31,27 @@ import (
 )
 
 type DockerServerBackend struct {
-	locker lockgate.Locker
+	locker          lockgate.Locker
+	registryMirrors []*mirror.Mirror
 }
 
-func NewDockerServerBackend(locker lockgate.Locker) *DockerServerBackend {
+type DockerServerBackendOptions struct {
+	// RegistryMirrors are used to pull the images with failover to the origin registry.
+	// The mirrors of docker.io are skipped, because they are used by the Docker daemon itself (registry-mirrors in daemon.json).
+	RegistryMirrors []*mirror.Mirror
+}
+
+func NewDockerServerBackend(locker lockgate.Locker, opts DockerServerBackendOptions) *DockerServerBackend {
+	var registryMirrors []*mirror.Mirror
+	for _, m := range opts.RegistryMirrors {
+		if m.Registry != mirror.DockerHubRegistry {
+			registryMirrors = append(registryMirrors, m)
+		}
+	}
+
 	return &DockerServerBackend{
-		locker: locker,
+		locker:          locker,
+		registryMirrors: registryMirrors,
 	}
 }
 
@@ -57,6 +74,7 @@ func (backend *DockerServerBackend) Info(ctx context.Context) (info.Info, error)
 	} else {
 		res.StoreGraphRoot = sysInfo.DockerRootDir
 	}
+	res.StoreDriver = sysInfo.Driver
 
 	return res, nil
 }
@@ -85,6 +103,87 @@ func (backend *DockerServerBackend) CalculateDependencyImportChecksum(ctx contex
 	panic("CalculateDependencyImportChecksum does not implemented for DockerServerBackend. Please report the bug if you've received this message.")
 }
 
+func (backend *DockerServerBackend) Introspect(ctx context.Context, ref string, opts IntrospectOpts) error {
+	args := []string{"-ti", "--rm", "--entrypoint", IntrospectShellCommand[0]}
+	if opts.TargetPlatform != "" {
+		args = append(args, "--platform", opts.TargetPlatform)
+	}
+	if opts.User != "" {
+		args = append(args, "--user", opts.User)
+	}
+	for _, env := range opts.Envs {
+		args = append(args, "--env", env)
+	}
+	for _, volume := range opts.BuildVolumes {
+		args = append(args, "--volume", volume)
+	}
+	args = append(args, ref)
+	args = append(args, IntrospectShellCommand[1:]...)
+
+	if err := logboek.Context(ctx).Streams().DoErrorWithoutProxyStreamDataFormatting(func() error {
+		return docker.CliRun_LiveOutput(ctx, args...)
+	}); err != nil {
+		if !strings.Contains(err.Error(), "Code: ") || IsStartContainerErr(err) {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (backend *DockerServerBackend) Run(ctx context.Context, ref string, opts RunOpts) error {
+	return logboek.Context(ctx).Streams().DoErrorWithoutProxyStreamDataFormatting(func() error {
+		return docker.CliRun_ProvidedOutput(ctx, os.Stdout, os.Stderr, DockerRunArgs(ref, opts)...)
+	})
+}
+
+// DockerRunArgs returns the "docker run" arguments for the image.
+func DockerRunArgs(ref string, opts RunOpts) []string {
+	var args []string
+	if opts.Interactive {
+		args = append(args, "-i")
+	}
+	if opts.TTY {
+		args = append(args, "-t")
+	}
+	if opts.Rm {
+		args = append(args, "--rm")
+	}
+	if opts.TargetPlatform != "" {
+		args = append(args, "--platform", opts.TargetPlatform)
+	}
+	if opts.User != "" {
+		args = append(args, "--user", opts.User)
+	}
+	if opts.WorkDir != "" {
+		args = append(args, "--workdir", opts.WorkDir)
+	}
+	if opts.Network != "" {
+		args = append(args, "--network", opts.Network)
+	}
+	for _, env := range opts.Envs {
+		args = append(args, "--env", env)
+	}
+	for _, volume := range opts.Volumes {
+		args = append(args, "--volume", volume)
+	}
+	for _, port := range opts.Ports {
+		args = append(args, "--publish", port)
+	}
+	if len(opts.Entrypoint) > 0 {
+		args = append(args, "--entrypoint", opts.Entrypoint[0])
+	}
+	args = append(args, opts.DockerOptions...)
+
+	args = append(args, ref)
+	if len(opts.Entrypoint) > 1 {
+		args = append(args, opts.Entrypoint[1:]...)
+	}
+	args = append(args, opts.Command...)
+
+	return args
+}
+
 func (backend *DockerServerBackend) BuildDockerfile(ctx context.Context, _ []byte, opts BuildDockerfileOpts) (string, error) {
 	defer opstats.Observe(ctx, opstats.OperationImageBuild)()
 	switch {
@@ -288,7 +387,15 @@ func (backend *DockerServerBackend) RemoveImage(ctx context.Context, img LegacyI
 
 func (backend *DockerServerBackend) PullImageFromRegistry(ctx context.Context, img LegacyImageInterface) error {
 	defer opstats.Observe(ctx, opstats.OperationImagePull)()
-	if err := img.Pull(ctx); err != nil {
+	if err := pullFromMirrors(ctx, img.Name(), backend.registryMirrors, pullFromMirrorsOptions{
+		Pull: func(ctx context.Context, ref string) error {
+			if ref == img.Name() {
+				return img.Pull(ctx)
+			}
+			return docker.CliPullWithRetries(ctx, dockerPullArgs(ref, img.GetTargetPlatform())...)
+		},
+		Retag: backend.retagMirrorImage,
+	}); err != nil {
 		err = SanitizeError(err)
 		return fmt.Errorf("unable to pull image %s: %w", img.Name(), err)
 	}
@@ -308,23 +415,42 @@ func (backend *DockerServerBackend) Tag(ctx context.Context, ref, newRef string,
 
 func (backend *DockerServerBackend) Push(ctx context.Context, ref string, opts PushOpts) error {
 	defer opstats.Observe(ctx, opstats.OperationImagePush)()
-	return docker.CliPushWithRetries(ctx, ref)
+	return ratelimit.DefaultScheduler.Do(ctx, ref, 0, func() error {
+		return docker.CliPushWithRetries(ctx, ref)
+	})
 }
 
 func (backend *DockerServerBackend) Pull(ctx context.Context, ref string, opts PullOpts) error {
 	defer opstats.Observe(ctx, opstats.OperationImagePull)()
-	var args []string
-	if opts.TargetPlatform != "" {
-		args = append(args, "--platform", opts.TargetPlatform)
-	}
-	args = append(args, ref)
 
-	if err := docker.CliPull(ctx, args...); err != nil {
+	if err := pullFromMirrors(ctx, ref, backend.registryMirrors, pullFromMirrorsOptions{
+		Pull: func(ctx context.Context, endpointRef string) error {
+			return docker.CliPull(ctx, dockerPullArgs(endpointRef, opts.TargetPlatform)...)
+		},
+		Retag: backend.retagMirrorImage,
+	}); err != nil {
 		return fmt.Errorf("unable to pull image %s: %w", ref, err)
 	}
 	return nil
 }
 
+func dockerPullArgs(ref, targetPlatform string) []string {
+	var args []string
+	if targetPlatform != "" {
+		args = append(args, "--platform", targetPlatform)
+	}
+	return append(args, ref)
+}
+
+func (backend *DockerServerBackend) retagMirrorImage(ctx context.Context, mirrorRef, ref string) error {
+	if err := docker.CliTag(ctx, mirrorRef, ref); err != nil {
+		return err
+	}
+
+	// The image is kept by the original reference, so only the mirror tag is removed.
+	return docker.CliRmi(ctx, mirrorRef)
+}
+
 func (backend *DockerServerBackend) Rmi(ctx context.Context, ref string, opts RmiOpts) error {
 	args := []string{ref}
 	if opts.Force {
@@ -347,7 +473,9 @@ func (backend *DockerServerBackend) Rm(ctx context.Context, ref string, opts RmO
 
 func (backend *DockerServerBackend) PushImage(ctx context.Context, img LegacyImageInterface) error {
 	if err := logboek.Context(ctx).Info().LogProcess(fmt.Sprintf("Pushing %s", img.Name())).DoError(func() error {
-		return docker.CliPushWithRetries(ctx, img.Name())
+		return ratelimit.DefaultScheduler.Do(ctx, img.Name(), 0, func() error {
+			return docker.CliPushWithRetries(ctx, img.Name())
+		})
 	}); err != nil {
 		return err
 	}
@@ -442,6 +570,7 @@ func (backend *DockerServerBackend) Containers(ctx context.Context, opts Contain
 			ID:      container.ID,
 			ImageID: container.ImageID,
 			Names:   container.Names,
+			Created: time.Unix(container.Created, 0),
 		}
 	}
 
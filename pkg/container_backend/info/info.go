@@ -2,4 +2,6 @@ package info
 
 type Info struct {
 	StoreGraphRoot string
+	// StoreDriver is the storage driver of the backend store (e.g. overlay or vfs).
+	StoreDriver string
 }
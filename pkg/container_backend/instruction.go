@@ -11,3 +11,10 @@ type InstructionInterface interface {
 	Apply(ctx context.Context, containerName string, drv buildah.Buildah, drvOpts buildah.CommonOpts, buildContextArchive BuildContextArchiver) error
 	UsesBuildContext() bool
 }
+
+// IntrospectableInstructionInterface is implemented by the instructions, which run commands in the build container,
+// so that the introspection shell of the failed instruction gets the same mounts, secrets and environment.
+type IntrospectableInstructionInterface interface {
+	InstructionInterface
+	Introspect(ctx context.Context, containerName string, drv buildah.Buildah, drvOpts buildah.CommonOpts, buildContextArchive BuildContextArchiver) error
+}
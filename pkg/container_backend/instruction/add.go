@@ -3,15 +3,67 @@ package instruction
 import (
 	"context"
 	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
 
 	"github.com/moby/buildkit/frontend/dockerfile/instructions"
+	"github.com/moby/buildkit/util/gitutil"
+	"github.com/opencontainers/go-digest"
 
+	"github.com/werf/common-go/pkg/util"
 	"github.com/werf/werf/v2/pkg/buildah"
 	"github.com/werf/werf/v2/pkg/container_backend"
+	"github.com/werf/werf/v2/pkg/true_git"
+	"github.com/werf/werf/v2/pkg/werf"
 )
 
+// addHTTPSourceDownloadTimeout limits the whole download of the HTTP source including the body.
+const addHTTPSourceDownloadTimeout = 10 * time.Minute
+
+var (
+	fullLengthCommitSHARegexp = regexp.MustCompile(`^[0-9a-fA-F]{40}$`)
+	addHTTPSourceClient       = &http.Client{Timeout: addHTTPSourceDownloadTimeout}
+)
+
+type AddRemoteSourceType string
+
+const (
+	AddRemoteSourceTypeHTTP AddRemoteSourceType = "http"
+	AddRemoteSourceTypeGit  AddRemoteSourceType = "git"
+)
+
+type AddRemoteSource struct {
+	Type   AddRemoteSourceType
+	Source string
+
+	// Checksum is the digest of the HTTP source content and DownloadedPath is the file the content has been downloaded
+	// to while resolving, so it is not downloaded again when the instruction is applied.
+	Checksum       digest.Digest
+	DownloadedPath string
+
+	// GitRef is the parsed git source and Commit is the commit the git source ref points to.
+	GitRef *gitutil.GitRef
+	Commit string
+}
+
 type Add struct {
 	instructions.AddCommand
+
+	// RemoteSources are resolved on the host by ResolveRemoteSources before the instruction is applied.
+	RemoteSources []*AddRemoteSource
+}
+
+type ResolveRemoteSourcesOptions struct {
+	// DownloadDir is the dir the HTTP sources without --checksum are downloaded to.
+	DownloadDir string
 }
 
 func NewAdd(i instructions.AddCommand) *Add {
@@ -19,27 +71,335 @@ func NewAdd(i instructions.AddCommand) *Add {
 }
 
 func (i *Add) UsesBuildContext() bool {
-	return true
+	return len(i.LocalSourcePaths()) > 0
+}
+
+// LocalSourcePaths returns the sources from the build context.
+func (i *Add) LocalSourcePaths() []string {
+	var res []string
+	for _, src := range i.SourcePaths {
+		if isAddRemoteSource(src) {
+			continue
+		}
+		res = append(res, src)
+	}
+	return res
+}
+
+// ResolveRemoteSources downloads the HTTP sources to calculate the content digest if --checksum is not specified and
+// resolves the commits of the git sources refs, so the remote sources are taken into account in the stage digest
+// and are verified to be the same when the instruction is applied.
+func (i *Add) ResolveRemoteSources(ctx context.Context, opts ResolveRemoteSourcesOptions) error {
+	var remoteSources []*AddRemoteSource
+
+	for _, src := range i.SourcePaths {
+		if gitRef, isGit := parseAddGitSource(src); isGit {
+			if i.Checksum != "" {
+				return fmt.Errorf("--checksum is not supported for git source %q", src)
+			}
+
+			commit, err := resolveAddGitSourceCommit(ctx, gitRef)
+			if err != nil {
+				return fmt.Errorf("unable to resolve commit of git source %q: %w", src, err)
+			}
+
+			remoteSources = append(remoteSources, &AddRemoteSource{Type: AddRemoteSourceTypeGit, Source: src, GitRef: gitRef, Commit: commit})
+			continue
+		}
+
+		if isAddHTTPSource(src) {
+			remoteSource, err := i.resolveHTTPSource(ctx, src, opts.DownloadDir)
+			if err != nil {
+				return fmt.Errorf("unable to resolve HTTP source %q: %w", src, err)
+			}
+
+			remoteSources = append(remoteSources, remoteSource)
+			continue
+		}
+
+		if i.Checksum != "" {
+			return fmt.Errorf("--checksum is supported only for HTTP sources, got source %q", src)
+		}
+	}
+
+	i.RemoteSources = remoteSources
+
+	return nil
+}
+
+func (i *Add) resolveHTTPSource(ctx context.Context, src, downloadDir string) (*AddRemoteSource, error) {
+	if i.Checksum != "" {
+		checksum, err := digest.Parse(i.Checksum)
+		if err != nil {
+			return nil, fmt.Errorf("invalid checksum %q: %w", i.Checksum, err)
+		}
+		return &AddRemoteSource{Type: AddRemoteSourceTypeHTTP, Source: src, Checksum: checksum}, nil
+	}
+
+	downloadedPath, checksum, err := downloadAddHTTPSource(ctx, src, downloadDir)
+	if err != nil {
+		return nil, err
+	}
+
+	return &AddRemoteSource{Type: AddRemoteSourceTypeHTTP, Source: src, Checksum: checksum, DownloadedPath: downloadedPath}, nil
 }
 
 func (i *Add) Apply(ctx context.Context, containerName string, drv buildah.Buildah, drvOpts buildah.CommonOpts, buildContextArchive container_backend.BuildContextArchiver) error {
-	var contextDir string
-	if i.UsesBuildContext() {
+	if len(i.LocalSourcePaths())+len(i.RemoteSources) != len(i.SourcePaths) {
+		return fmt.Errorf("remote sources of %v are not resolved", i.SourcePaths)
+	}
+
+	// The sources are added in the Dockerfile order, so the later sources overwrite the earlier ones.
+	// The consecutive local sources are added at once.
+	remoteSources := i.RemoteSources
+	var localSourcePaths []string
+	for _, src := range i.SourcePaths {
+		if !isAddRemoteSource(src) {
+			localSourcePaths = append(localSourcePaths, src)
+			continue
+		}
+
+		if err := i.applyLocalSources(ctx, containerName, drv, drvOpts, buildContextArchive, localSourcePaths); err != nil {
+			return err
+		}
+		localSourcePaths = nil
+
+		remoteSource := remoteSources[0]
+		remoteSources = remoteSources[1:]
+
 		var err error
-		contextDir, err = buildContextArchive.ExtractOrGetExtractedDir(ctx)
+		switch remoteSource.Type {
+		case AddRemoteSourceTypeHTTP:
+			err = i.applyHTTPSource(ctx, containerName, drv, drvOpts, remoteSource)
+		case AddRemoteSourceTypeGit:
+			err = i.applyGitSource(ctx, containerName, drv, drvOpts, remoteSource)
+		}
 		if err != nil {
-			return fmt.Errorf("unable to extract build context: %w", err)
+			return fmt.Errorf("error adding %s to %s for container %s: %w", remoteSource.Source, i.DestPath, containerName, err)
 		}
 	}
 
-	if err := drv.Add(ctx, containerName, i.SourcePaths, i.DestPath, buildah.AddOpts{
+	return i.applyLocalSources(ctx, containerName, drv, drvOpts, buildContextArchive, localSourcePaths)
+}
+
+func (i *Add) applyLocalSources(ctx context.Context, containerName string, drv buildah.Buildah, drvOpts buildah.CommonOpts, buildContextArchive container_backend.BuildContextArchiver, localSourcePaths []string) error {
+	if len(localSourcePaths) == 0 {
+		return nil
+	}
+
+	contextDir, err := buildContextArchive.ExtractOrGetExtractedDir(ctx)
+	if err != nil {
+		return fmt.Errorf("unable to extract build context: %w", err)
+	}
+
+	if err := drv.Add(ctx, containerName, localSourcePaths, i.DestPath, buildah.AddOpts{
 		CommonOpts: drvOpts,
 		ContextDir: contextDir,
 		Chown:      i.Chown,
 		Chmod:      i.Chmod,
+		Ignores:    i.ExcludePatterns,
 	}); err != nil {
-		return fmt.Errorf("error adding %v to %s for container %s: %w", i.SourcePaths, i.DestPath, containerName, err)
+		return fmt.Errorf("error adding %v to %s for container %s: %w", localSourcePaths, i.DestPath, containerName, err)
 	}
 
 	return nil
 }
+
+// applyHTTPSource copies the HTTP source content downloaded while resolving. If the source has not been downloaded
+// because of --checksum, it is downloaded and verified here.
+func (i *Add) applyHTTPSource(ctx context.Context, containerName string, drv buildah.Buildah, drvOpts buildah.CommonOpts, remoteSource *AddRemoteSource) error {
+	downloadedPath := remoteSource.DownloadedPath
+	if downloadedPath == "" {
+		tmpDir, err := os.MkdirTemp(werf.GetTmpDir(), "werf-add-http-source-")
+		if err != nil {
+			return fmt.Errorf("unable to create tmp dir: %w", err)
+		}
+		defer os.RemoveAll(tmpDir)
+
+		filePath, checksum, err := downloadAddHTTPSource(ctx, remoteSource.Source, tmpDir)
+		if err != nil {
+			return err
+		}
+
+		if checksum != remoteSource.Checksum {
+			return fmt.Errorf("unexpected content digest %s, expected %s", checksum, remoteSource.Checksum)
+		}
+
+		downloadedPath = filePath
+	}
+
+	return drv.Copy(ctx, containerName, filepath.Dir(downloadedPath), []string{filepath.Base(downloadedPath)}, i.DestPath, buildah.CopyOpts{
+		CommonOpts: drvOpts,
+		Chown:      i.Chown,
+		Chmod:      i.Chmod,
+	})
+}
+
+func (i *Add) applyGitSource(ctx context.Context, containerName string, drv buildah.Buildah, drvOpts buildah.CommonOpts, remoteSource *AddRemoteSource) error {
+	tmpDir, err := os.MkdirTemp(werf.GetTmpDir(), "werf-add-git-source-")
+	if err != nil {
+		return fmt.Errorf("unable to create tmp dir: %w", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	// The commit is fetched by the ref if possible and verified to be the resolved one.
+	ref := remoteSource.GitRef.Commit
+	if fullLengthCommitSHARegexp.MatchString(ref) {
+		ref = ""
+	}
+
+	workTreeDir := filepath.Join(tmpDir, "repo")
+	if err := true_git.ShallowCheckout(ctx, workTreeDir, remoteSource.GitRef.Remote, ref, remoteSource.Commit, true_git.ShallowCheckoutOptions{}); err != nil {
+		return fmt.Errorf("unable to checkout commit %s of %s: %w", remoteSource.Commit, remoteSource.GitRef.Remote, err)
+	}
+
+	if !i.KeepGitDir {
+		if err := removeDotGitEntries(workTreeDir); err != nil {
+			return err
+		}
+	}
+
+	srcDir := filepath.Join(workTreeDir, filepath.FromSlash(remoteSource.GitRef.SubDir))
+	if srcDir != workTreeDir && !util.IsSubpathOfBasePath(workTreeDir, srcDir) {
+		return fmt.Errorf("subdir %q is outside of the repository", remoteSource.GitRef.SubDir)
+	}
+
+	return drv.Copy(ctx, containerName, srcDir, []string{"."}, i.DestPath, buildah.CopyOpts{
+		CommonOpts: drvOpts,
+		Chown:      i.Chown,
+		Chmod:      i.Chmod,
+	})
+}
+
+// removeDotGitEntries removes the .git dir of the repository and the .git files of the submodules.
+func removeDotGitEntries(workTreeDir string) error {
+	var dotGitPaths []string
+	if err := filepath.WalkDir(workTreeDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if d.Name() == ".git" {
+			dotGitPaths = append(dotGitPaths, path)
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+		}
+
+		return nil
+	}); err != nil {
+		return fmt.Errorf("unable to walk dir %q: %w", workTreeDir, err)
+	}
+
+	for _, path := range dotGitPaths {
+		if err := os.RemoveAll(path); err != nil {
+			return fmt.Errorf("unable to remove %q: %w", path, err)
+		}
+	}
+
+	return nil
+}
+
+// parseAddGitSource parses the source the same way as buildkit does: the source is a git repository if it is a git URL,
+// and the HTTP URL is a git repository only if its path has the .git suffix.
+func parseAddGitSource(src string) (*gitutil.GitRef, bool) {
+	gitRef, err := gitutil.ParseGitRef(src)
+	if err != nil || gitRef.IndistinguishableFromLocal {
+		return nil, false
+	}
+	return gitRef, true
+}
+
+func isAddRemoteSource(src string) bool {
+	_, isGit := parseAddGitSource(src)
+	return isGit || isAddHTTPSource(src)
+}
+
+func isAddHTTPSource(src string) bool {
+	return strings.HasPrefix(src, "http://") || strings.HasPrefix(src, "https://")
+}
+
+func resolveAddGitSourceCommit(ctx context.Context, gitRef *gitutil.GitRef) (string, error) {
+	if fullLengthCommitSHARegexp.MatchString(gitRef.Commit) {
+		return strings.ToLower(gitRef.Commit), nil
+	}
+
+	return true_git.LsRemoteRef(ctx, gitRef.Remote, gitRef.Commit, true_git.LsRemoteRefOptions{})
+}
+
+// downloadAddHTTPSource downloads the HTTP source to the new dir inside the dir and returns the file path and the content
+// digest. The file is named, and its mode and mtime are set, the same way as buildah does for the remote ADD source.
+func downloadAddHTTPSource(ctx context.Context, src, dir string) (string, digest.Digest, error) {
+	srcURL, err := url.Parse(src)
+	if err != nil {
+		return "", "", fmt.Errorf("unable to parse url: %w", err)
+	}
+
+	name := path.Base(srcURL.Path)
+	if name == "." || name == "/" {
+		name = "__unnamed__"
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, src, nil)
+	if err != nil {
+		return "", "", fmt.Errorf("unable to create request: %w", err)
+	}
+
+	resp, err := addHTTPSourceClient.Do(req)
+	if err != nil {
+		return "", "", fmt.Errorf("unable to download: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", "", fmt.Errorf("unable to download: unexpected status %q", resp.Status)
+	}
+
+	modTime := time.Unix(0, 0).UTC()
+	if lastModified := resp.Header.Get("Last-Modified"); lastModified != "" {
+		if modTime, err = http.ParseTime(lastModified); err != nil {
+			return "", "", fmt.Errorf("unable to parse Last-Modified header %q: %w", lastModified, err)
+		}
+	}
+
+	if err := os.MkdirAll(dir, os.ModePerm); err != nil {
+		return "", "", fmt.Errorf("unable to create dir %q: %w", dir, err)
+	}
+
+	fileDir, err := os.MkdirTemp(dir, "add-http-source-")
+	if err != nil {
+		return "", "", fmt.Errorf("unable to create tmp dir: %w", err)
+	}
+
+	filePath := filepath.Join(fileDir, name)
+	checksum, err := writeAddHTTPSourceFile(filePath, resp.Body, modTime)
+	if err != nil {
+		os.RemoveAll(fileDir)
+		return "", "", err
+	}
+
+	return filePath, checksum, nil
+}
+
+func writeAddHTTPSourceFile(filePath string, r io.Reader, modTime time.Time) (digest.Digest, error) {
+	f, err := os.OpenFile(filePath, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o600)
+	if err != nil {
+		return "", fmt.Errorf("unable to create file %q: %w", filePath, err)
+	}
+
+	digester := digest.SHA256.Digester()
+	if _, err := io.Copy(io.MultiWriter(f, digester.Hash()), r); err != nil {
+		f.Close()
+		return "", fmt.Errorf("unable to download: %w", err)
+	}
+
+	if err := f.Close(); err != nil {
+		return "", fmt.Errorf("unable to close file %q: %w", filePath, err)
+	}
+
+	if err := os.Chtimes(filePath, modTime, modTime); err != nil {
+		return "", fmt.Errorf("unable to set mtime of %q: %w", filePath, err)
+	}
+
+	return digester.Digest(), nil
+}
@@ -3,9 +3,15 @@ package instruction
 import (
 	"context"
 	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
 
+	"github.com/bmatcuk/doublestar/v4"
 	"github.com/moby/buildkit/frontend/dockerfile/instructions"
 
+	"github.com/werf/common-go/pkg/util"
 	"github.com/werf/werf/v2/pkg/buildah"
 	"github.com/werf/werf/v2/pkg/container_backend"
 )
@@ -42,13 +48,80 @@ func (i *Copy) Apply(ctx context.Context, containerName string, drv buildah.Buil
 		}
 	}
 
-	if err := drv.Copy(ctx, containerName, contextDir, i.SourcePaths, i.DestPath, buildah.CopyOpts{
+	// The --link option is applied as a regular copy: the resulting files are the same,
+	// only the layer is not independent of the previous ones.
+	copyOpts := buildah.CopyOpts{
 		CommonOpts: drvOpts,
 		Chown:      i.Chown,
 		Chmod:      i.Chmod,
-	}); err != nil {
+		Ignores:    i.ExcludePatterns,
+	}
+
+	if i.Parents {
+		return i.copyWithParents(ctx, containerName, drv, contextDir, copyOpts)
+	}
+
+	if err := drv.Copy(ctx, containerName, contextDir, i.SourcePaths, i.DestPath, copyOpts); err != nil {
 		return fmt.Errorf("error copying %v to %s for container %s: %w", i.SourcePaths, i.DestPath, containerName, err)
 	}
 
 	return nil
 }
+
+// copyWithParents copies each file or dir matching the sources with its parent dirs relative to the context dir or
+// to the pivot point "/./" of the source, e.g. "COPY --parents ./a/./b/*.txt /dest/" copies a/b/c.txt to /dest/b/c.txt.
+// The sources are matched with doublestar, so "**" matches any number of dirs as in buildkit.
+func (i *Copy) copyWithParents(ctx context.Context, containerName string, drv buildah.Buildah, contextDir string, copyOpts buildah.CopyOpts) error {
+	for _, src := range i.SourcePaths {
+		parentsBaseDir, pattern := "", src
+		if base, rest, ok := strings.Cut(src, "/./"); ok {
+			parentsBaseDir, pattern = base, rest
+		}
+
+		parentsBaseDir = filepath.Join(contextDir, filepath.FromSlash(parentsBaseDir))
+		if parentsBaseDir != contextDir && !util.IsSubpathOfBasePath(contextDir, parentsBaseDir) {
+			return fmt.Errorf("source %q is outside of the build context", src)
+		}
+
+		pattern = strings.TrimPrefix(path.Clean("/"+pattern), "/")
+		if pattern == "" {
+			pattern = "."
+		}
+
+		matches, err := doublestar.Glob(os.DirFS(parentsBaseDir), pattern)
+		if err != nil {
+			return fmt.Errorf("bad source %q: %w", src, err)
+		}
+
+		if len(matches) == 0 {
+			return fmt.Errorf("source %q not found", src)
+		}
+
+		for _, match := range matches {
+			absPath := filepath.Join(parentsBaseDir, filepath.FromSlash(match))
+
+			contextRelPath, err := filepath.Rel(contextDir, absPath)
+			if err != nil {
+				return fmt.Errorf("unable to get relative path of %q: %w", absPath, err)
+			}
+
+			info, err := os.Stat(absPath)
+			if err != nil {
+				return fmt.Errorf("unable to stat %q: %w", absPath, err)
+			}
+
+			// The dir content is copied into the dest dir, so the dir itself is a parent of its content.
+			destDir := match
+			if !info.IsDir() {
+				destDir = path.Dir(destDir)
+			}
+			dest := path.Join(i.DestPath, destDir) + "/"
+
+			if err := drv.Copy(ctx, containerName, contextDir, []string{contextRelPath}, dest, copyOpts); err != nil {
+				return fmt.Errorf("error copying %s to %s for container %s: %w", contextRelPath, dest, containerName, err)
+			}
+		}
+	}
+
+	return nil
+}
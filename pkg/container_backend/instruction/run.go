@@ -3,14 +3,17 @@ package instruction
 import (
 	"context"
 	"fmt"
+	"os"
 	"strings"
 
 	"github.com/moby/buildkit/frontend/dockerfile/instructions"
+	"github.com/opencontainers/runtime-spec/specs-go"
 
 	"github.com/werf/logboek"
 	"github.com/werf/werf/v2/pkg/buildah"
 	"github.com/werf/werf/v2/pkg/container_backend"
 	"github.com/werf/werf/v2/pkg/dockerfile"
+	"github.com/werf/werf/v2/pkg/git_repo/gitdata"
 )
 
 type Run struct {
@@ -47,12 +50,49 @@ func (i *Run) GetNetwork() string {
 }
 
 func (i *Run) Apply(ctx context.Context, containerName string, drv buildah.Buildah, drvOpts buildah.CommonOpts, buildContextArchive container_backend.BuildContextArchiver) error {
+	runOpts, releaseCacheMounts, err := i.runCommandOpts(ctx, drvOpts, buildContextArchive)
+	if err != nil {
+		return err
+	}
+	defer releaseCacheMounts()
+
+	if len(i.Files) > 0 {
+		full, prependShell := dockerfile.MapToCorrectHeredocCmd(i.ShellDependantCmdLine)
+		i.CmdLine = []string{full}
+		i.PrependShell = prependShell
+		runOpts.PrependShell = prependShell
+	}
+
+	logboek.Context(ctx).Default().LogF("$ %s\n", strings.Join(i.CmdLine, " "))
+
+	if err := drv.RunCommand(ctx, containerName, i.CmdLine, runOpts); err != nil {
+		return fmt.Errorf("error running command %v for container %s: %w", i.CmdLine, containerName, err)
+	}
+
+	return nil
+}
+
+// Introspect runs the interactive shell in the container with the same mounts, secrets and environment as the command has.
+func (i *Run) Introspect(ctx context.Context, containerName string, drv buildah.Buildah, drvOpts buildah.CommonOpts, buildContextArchive container_backend.BuildContextArchiver) error {
+	runOpts, releaseCacheMounts, err := i.runCommandOpts(ctx, drvOpts, buildContextArchive)
+	if err != nil {
+		return err
+	}
+	defer releaseCacheMounts()
+
+	runOpts.PrependShell = false
+	runOpts.Interactive = true
+
+	return drv.RunCommand(ctx, containerName, container_backend.IntrospectShellCommand, runOpts)
+}
+
+func (i *Run) runCommandOpts(ctx context.Context, drvOpts buildah.CommonOpts, buildContextArchive container_backend.BuildContextArchiver) (buildah.RunCommandOpts, func(), error) {
 	var contextDir string
 	if i.UsesBuildContext() {
 		var err error
 		contextDir, err = buildContextArchive.ExtractOrGetExtractedDir(ctx)
 		if err != nil {
-			return fmt.Errorf("unable to extract build context: %w", err)
+			return buildah.RunCommandOpts{}, nil, fmt.Errorf("unable to extract build context: %w", err)
 		}
 	}
 
@@ -61,27 +101,84 @@ func (i *Run) Apply(ctx context.Context, containerName string, drv buildah.Build
 		addCapabilities = []string{"all"}
 	}
 
-	if len(i.Files) > 0 {
-		full, prependShell := dockerfile.MapToCorrectHeredocCmd(i.ShellDependantCmdLine)
-		i.CmdLine = []string{full}
-		i.PrependShell = prependShell
+	globalMounts, runMounts, releaseCacheMounts, err := acquireCacheMounts(ctx, i.GetMounts())
+	if err != nil {
+		return buildah.RunCommandOpts{}, nil, err
 	}
 
-	logboek.Context(ctx).Default().LogF("$ %s\n", strings.Join(i.CmdLine, " "))
-
-	if err := drv.RunCommand(ctx, containerName, i.CmdLine, buildah.RunCommandOpts{
+	return buildah.RunCommandOpts{
 		CommonOpts:      drvOpts,
 		ContextDir:      contextDir,
 		PrependShell:    i.PrependShell,
 		AddCapabilities: addCapabilities,
 		NetworkType:     i.GetNetwork(),
-		RunMounts:       i.GetMounts(),
+		GlobalMounts:    globalMounts,
+		RunMounts:       runMounts,
 		Envs:            i.Envs,
 		Secrets:         i.Secrets,
 		SSH:             i.SSH,
-	}); err != nil {
-		return fmt.Errorf("error running command %v for container %s: %w", i.CmdLine, containerName, err)
+	}, releaseCacheMounts, nil
+}
+
+// acquireCacheMounts replaces the cache mounts with the bind mounts of the host cache directories, which are persisted
+// between builds in the local cache. The cache mounts populated from another image are left to buildah.
+func acquireCacheMounts(ctx context.Context, mounts []*instructions.Mount) ([]*specs.Mount, []*instructions.Mount, func(), error) {
+	var globalMounts []*specs.Mount
+	var runMounts []*instructions.Mount
+	var cacheMounts []*gitdata.BuildCacheMount
+
+	release := func() {
+		for _, cacheMount := range cacheMounts {
+			if err := cacheMount.Release(); err != nil {
+				logboek.Context(ctx).Warn().LogF("WARNING: unable to release cache mount %q: %s\n", cacheMount.Path, err)
+			}
+		}
 	}
 
-	return nil
+	for _, mount := range mounts {
+		if mount.Type != instructions.MountTypeCache || mount.From != "" {
+			runMounts = append(runMounts, mount)
+			continue
+		}
+
+		id := mount.CacheID
+		if id == "" {
+			id = mount.Target
+		}
+
+		opts := gitdata.AcquireBuildCacheMountOptions{Sharing: gitdata.BuildCacheMountSharing(mount.CacheSharing)}
+		if mount.Mode != nil {
+			mode := os.FileMode(*mount.Mode)
+			opts.Mode = &mode
+		}
+		if mount.UID != nil {
+			uid := int(*mount.UID)
+			opts.UID = &uid
+		}
+		if mount.GID != nil {
+			gid := int(*mount.GID)
+			opts.GID = &gid
+		}
+
+		cacheMount, err := gitdata.AcquireBuildCacheMount(ctx, id, opts)
+		if err != nil {
+			release()
+			return nil, nil, nil, err
+		}
+		cacheMounts = append(cacheMounts, cacheMount)
+
+		mode := "rw"
+		if mount.ReadOnly {
+			mode = "ro"
+		}
+
+		globalMounts = append(globalMounts, &specs.Mount{
+			Type:        "bind",
+			Source:      cacheMount.Path,
+			Destination: mount.Target,
+			Options:     []string{mode},
+		})
+	}
+
+	return globalMounts, runMounts, release, nil
 }
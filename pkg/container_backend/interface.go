@@ -50,7 +50,9 @@ type BuildDockerfileOpts struct {
 
 type BuildDockerfileStageOptions struct {
 	CommonOpts
-	BuildContextArchive BuildContextArchiver
+	BuildContextArchive   BuildContextArchiver
+	IntrospectBeforeError bool
+	IntrospectAfterError  bool
 }
 
 type BuildOptions struct {
@@ -60,6 +62,38 @@ type BuildOptions struct {
 	IntrospectAfterError  bool
 }
 
+type IntrospectOpts struct {
+	CommonOpts
+	User         string
+	Envs         []string
+	BuildVolumes []string
+	// Secrets and SSH are supported only by the Buildah backend.
+	Secrets []string
+	SSH     string
+}
+
+type RunOpts struct {
+	CommonOpts
+	// Entrypoint overrides the entrypoint of the image. The command of the image is not used in this case.
+	Entrypoint []string
+	// Command overrides the command of the image.
+	Command []string
+	User    string
+	WorkDir string
+	Envs    []string // {"KEY=VALUE", ...}
+	Volumes []string // {"SOURCE:DESTINATION[:OPTIONS]", ...}
+	// Ports to publish in the docker format "[HOST_IP:][HOST_PORT:]CONTAINER_PORT[/PROTOCOL]".
+	Ports   []string
+	Network string
+	// Interactive keeps the stdin of the current process attached to the container.
+	Interactive bool
+	TTY         bool
+	// Rm removes the container after the command exits. The Buildah backend always removes its container.
+	Rm bool
+	// DockerOptions are passed to "docker run" as is. Supported only by the Docker backend.
+	DockerOptions []string
+}
+
 type ImagesOptions struct {
 	CommonOpts
 	Filters []util.Pair[string, string]
@@ -93,6 +127,10 @@ type ContainerBackend interface {
 	BuildDockerfileStage(ctx context.Context, baseImage string, opts BuildDockerfileStageOptions, instructions ...InstructionInterface) (string, error)
 	BuildStapelStage(ctx context.Context, baseImage string, opts BuildStapelStageOptions) (string, error)
 	CalculateDependencyImportChecksum(ctx context.Context, dependencyImport DependencyImportSpec, opts CalculateDependencyImportChecksum) (string, error)
+	// Introspect runs the interactive shell in the temporary container created from the image.
+	Introspect(ctx context.Context, ref string, opts IntrospectOpts) error
+	// Run starts the container from the image in the foreground and waits for its command to exit.
+	Run(ctx context.Context, ref string, opts RunOpts) error
 
 	HasStapelBuildSupport() bool
 	GetDefaultPlatform() string
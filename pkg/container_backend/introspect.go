@@ -0,0 +1,23 @@
+package container_backend
+
+import (
+	"context"
+
+	"github.com/werf/logboek"
+	werfExec "github.com/werf/werf/v2/pkg/werf/exec"
+)
+
+// IntrospectShellCommand runs bash if it is available in the container, otherwise sh.
+var IntrospectShellCommand = []string{"/bin/sh", "-c", "if command -v bash >/dev/null 2>&1; then exec bash; else exec sh; fi"}
+
+// runIntrospectShell runs the interactive shell without the log formatting.
+// The exit code of the shell is chosen by the user, thus it is not an error.
+func runIntrospectShell(ctx context.Context, runFunc func() error) error {
+	err := logboek.Context(ctx).Streams().DoErrorWithoutProxyStreamDataFormatting(runFunc)
+
+	if _, ok := werfExec.IsExitCodeError(err); ok {
+		return nil
+	}
+
+	return err
+}
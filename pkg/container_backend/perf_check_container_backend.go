@@ -98,6 +98,22 @@ func (runtime *PerfCheckContainerBackend) BuildStapelStage(ctx context.Context,
 	return
 }
 
+func (runtime *PerfCheckContainerBackend) Introspect(ctx context.Context, ref string, opts IntrospectOpts) (resErr error) {
+	logboek.Context(ctx).Default().LogProcess("ContainerBackend.Introspect %q", ref).
+		Do(func() {
+			resErr = runtime.ContainerBackend.Introspect(ctx, ref, opts)
+		})
+	return
+}
+
+func (runtime *PerfCheckContainerBackend) Run(ctx context.Context, ref string, opts RunOpts) (resErr error) {
+	logboek.Context(ctx).Default().LogProcess("ContainerBackend.Run %q", ref).
+		Do(func() {
+			resErr = runtime.ContainerBackend.Run(ctx, ref, opts)
+		})
+	return
+}
+
 func (runtime *PerfCheckContainerBackend) CalculateDependencyImportChecksum(ctx context.Context, dependencyImport DependencyImportSpec, opts CalculateDependencyImportChecksum) (resID string, resErr error) {
 	logboek.Context(ctx).Default().LogProcess("ContainerBackend.BuildDockerfile").
 		Do(func() {
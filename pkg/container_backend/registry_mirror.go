@@ -0,0 +1,68 @@
+package container_backend
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/werf/logboek"
+	"github.com/werf/werf/v2/pkg/docker_registry/mirror"
+	"github.com/werf/werf/v2/pkg/docker_registry/ratelimit"
+	"github.com/werf/werf/v2/pkg/opstats"
+)
+
+type pullFromMirrorsOptions struct {
+	// Pull pulls the image by the reference of the endpoint.
+	Pull func(ctx context.Context, ref string) error
+	// Retag tags the image pulled from the mirror with the original reference and removes the mirror reference.
+	Retag func(ctx context.Context, mirrorRef, ref string) error
+}
+
+// pullFromMirrors pulls the image from the healthy mirrors of its registry with failover to the origin registry
+// and counts the endpoint, which served the pull. The pulls wait for the rate limit of the endpoint registry.
+// The image pulled by digest cannot be retagged, so it is pulled from the origin registry only.
+func pullFromMirrors(ctx context.Context, ref string, mirrors []*mirror.Mirror, opts pullFromMirrorsOptions) error {
+	if strings.Contains(ref, "@") {
+		mirrors = nil
+	}
+
+	endpoint, err := mirror.Failover(ctx, ref, mirrors, mirror.FailoverOptions{IsNotFoundErr: isPullNotFoundErr}, func(ctx context.Context, endpoint mirror.Endpoint) error {
+		if err := ratelimit.DefaultScheduler.Do(ctx, endpoint.Reference, 1, func() error {
+			return opts.Pull(ctx, endpoint.Reference)
+		}); err != nil {
+			return err
+		}
+
+		if endpoint.Mirror == nil {
+			return nil
+		}
+
+		logboek.Context(ctx).Info().LogF("Image %q is pulled from mirror %s\n", ref, endpoint.Mirror.URL())
+
+		if err := opts.Retag(ctx, endpoint.Reference, ref); err != nil {
+			return fmt.Errorf("unable to tag image %s pulled from mirror as %s: %w", endpoint.Reference, ref, err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if host := endpoint.Host(); host != "" {
+		opstats.CountPullEndpoint(ctx, host)
+	}
+
+	return nil
+}
+
+// isPullNotFoundErr reports the pull errors, which mean that the mirror has no image, but it is available.
+func isPullNotFoundErr(err error) bool {
+	msg := strings.ToLower(err.Error())
+	for _, s := range []string{"manifest unknown", "name unknown", "not found"} {
+		if strings.Contains(msg, s) {
+			return true
+		}
+	}
+	return false
+}
@@ -61,8 +61,10 @@ func (b *DockerfileStageBuilder) SetBuildContextArchive(buildContextArchive cont
 func (b *DockerfileStageBuilder) Build(ctx context.Context, opts container_backend.BuildOptions) error {
 	instructions := append(append(b.preInstructions, b.instructions...), b.postInstructions...)
 	backendOpts := container_backend.BuildDockerfileStageOptions{
-		CommonOpts:          container_backend.CommonOpts{TargetPlatform: opts.TargetPlatform},
-		BuildContextArchive: b.buildContextArchive,
+		CommonOpts:            container_backend.CommonOpts{TargetPlatform: opts.TargetPlatform},
+		BuildContextArchive:   b.buildContextArchive,
+		IntrospectBeforeError: opts.IntrospectBeforeError,
+		IntrospectAfterError:  opts.IntrospectAfterError,
 	}
 
 	if builtID, err := b.containerBackend.BuildDockerfileStage(ctx, b.baseImage, backendOpts, instructions...); err != nil {
@@ -34,7 +34,8 @@ func (builder *StapelStageBuilder) Build(ctx context.Context, opts container_bac
 	if opts.Network != "" {
 		finalOpts.Network = opts.Network
 	}
-	// TODO: support introspect options
+	finalOpts.IntrospectBeforeError = opts.IntrospectBeforeError
+	finalOpts.IntrospectAfterError = opts.IntrospectAfterError
 
 	builtID, err := builder.ContainerBackend.BuildStapelStage(ctx, builder.BaseImage, finalOpts)
 	if err != nil {
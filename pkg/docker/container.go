@@ -45,6 +45,11 @@ func ContainerRemove(ctx context.Context, ref string, options types.ContainerRem
 	return apiCli(ctx).ContainerRemove(ctx, ref, options)
 }
 
+// ContainerCopyTo extracts the tar archive content into the dstPath dir of the container.
+func ContainerCopyTo(ctx context.Context, ref, dstPath string, content io.Reader) error {
+	return apiCli(ctx).CopyToContainer(ctx, ref, dstPath, content, types.CopyToContainerOptions{AllowOverwriteDirWithFile: true})
+}
+
 func doCliCreate(ctx context.Context, c command.Cli, args ...string) error {
 	return prepareCliCmd(ctx, container.NewCreateCommand(c), args...).Execute()
 }
@@ -96,3 +101,13 @@ func CliRm_RecordedOutput(ctx context.Context, args ...string) (string, error) {
 		return doCliRm(ctx, c, args...)
 	})
 }
+
+func doCliExec(ctx context.Context, c command.Cli, args ...string) error {
+	return prepareCliCmd(ctx, container.NewExecCommand(c), args...).Execute()
+}
+
+func CliExec_RecordedOutput(ctx context.Context, args ...string) (string, error) {
+	return callCliWithRecordedOutput(ctx, func(c command.Cli) error {
+		return doCliExec(ctx, c, args...)
+	})
+}
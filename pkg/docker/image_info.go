@@ -4,6 +4,7 @@ import (
 	"strings"
 
 	"github.com/docker/docker/api/types"
+	"github.com/samber/lo"
 
 	"github.com/werf/werf/v2/pkg/image"
 )
@@ -37,6 +38,7 @@ func NewInfoFromInspect(ref string, inspect *types.ImageInspect) *image.Info {
 		Labels:            inspect.Config.Labels,
 		OnBuild:           inspect.Config.OnBuild,
 		Env:               inspect.Config.Env,
+		User:              lo.ToPtr(inspect.Config.User),
 		CreatedAtUnixNano: image.MustParseTimestampString(created).UnixNano(),
 		RepoDigest:        repoDigest,
 		ID:                inspect.ID,
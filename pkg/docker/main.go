@@ -27,6 +27,7 @@ var (
 	defaultPlatform      string
 	runtimePlatform      string
 	useBuildx            bool
+	credentialHelpers    map[string]string
 
 	DockerConfigDir string
 )
@@ -45,6 +46,9 @@ type InitOptions struct {
 	ClaimPlatforms  []string
 	Verbose         bool
 	Debug           bool
+	// CredentialHelpers are added to the credential helpers of the docker config,
+	// so docker requests the fresh credentials from the helper on each pull and push.
+	CredentialHelpers map[string]string
 }
 
 func Init(ctx context.Context, opts InitOptions) error {
@@ -55,6 +59,7 @@ func Init(ctx context.Context, opts InitOptions) error {
 
 	isDebug = os.Getenv("WERF_DEBUG_DOCKER") == "1"
 	liveCliOutputEnabled = opts.Verbose || opts.Debug
+	credentialHelpers = opts.CredentialHelpers
 
 	defaultCLI, err = newDockerCli(defaultCliOptions(ctx))
 	if err != nil {
@@ -175,6 +180,17 @@ func newDockerCli(opts []command.CLIOption) (command.Cli, error) {
 	if err := newCli.Initialize(clientOpts, command.WithInitializeClient(makeWrappedClient)); err != nil {
 		return nil, err
 	}
+
+	if len(credentialHelpers) > 0 {
+		configFile := newCli.ConfigFile()
+		if configFile.CredentialHelpers == nil {
+			configFile.CredentialHelpers = make(map[string]string, len(credentialHelpers))
+		}
+		for registry, helper := range credentialHelpers {
+			configFile.CredentialHelpers[registry] = helper
+		}
+	}
+
 	return newCli, nil
 }
 
@@ -23,10 +23,12 @@ import (
 	"github.com/google/go-containerregistry/pkg/v1/remote/transport"
 	"github.com/google/go-containerregistry/pkg/v1/tarball"
 	"github.com/google/go-containerregistry/pkg/v1/types"
+	"github.com/samber/lo"
 
 	"github.com/werf/logboek"
 	registry_api "github.com/werf/werf/v2/pkg/docker_registry/api"
 	"github.com/werf/werf/v2/pkg/docker_registry/container_registry_extensions"
+	"github.com/werf/werf/v2/pkg/docker_registry/credhelper"
 	"github.com/werf/werf/v2/pkg/image"
 	"github.com/werf/werf/v2/pkg/werf"
 )
@@ -264,6 +266,7 @@ func (api *api) getRepoImageByDesc(ctx context.Context, originalTag string, desc
 		repoImage.Labels = configFile.Config.Labels
 		repoImage.OnBuild = configFile.Config.OnBuild
 		repoImage.Env = configFile.Config.Env
+		repoImage.User = lo.ToPtr(configFile.Config.User)
 		repoImage.SetCreatedAtUnix(configFile.Created.Unix())
 		repoImage.Volumes = configFile.Config.Volumes
 
@@ -499,10 +502,16 @@ func (api *api) parseReferenceOptions() []name.Option {
 	return options
 }
 
+// keychain resolves the credentials with the credential helpers from the werf registry auth config
+// and falls back to the docker config for the other registries.
+func keychain() authn.Keychain {
+	return authn.NewMultiKeychain(credhelper.DefaultKeychain(), authn.DefaultKeychain)
+}
+
 func (api *api) defaultRemoteOptions(ctx context.Context) []remote.Option {
 	return []remote.Option{
 		remote.WithContext(ctx),
-		remote.WithAuthFromKeychain(authn.DefaultKeychain),
+		remote.WithAuthFromKeychain(keychain()),
 		remote.WithTransport(api.httpTransport),
 		remote.WithUserAgent(werf.UserAgent),
 	}
@@ -516,7 +525,7 @@ func (api *api) defaultRemoteOptionsForHost(ctx context.Context, reference strin
 	if api.shouldUseInsecureRegistry(reference) {
 		return []remote.Option{
 			remote.WithContext(ctx),
-			remote.WithAuthFromKeychain(authn.DefaultKeychain),
+			remote.WithAuthFromKeychain(keychain()),
 			remote.WithTransport(api.insecureHttpTransport),
 			remote.WithUserAgent(werf.UserAgent),
 		}
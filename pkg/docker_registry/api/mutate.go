@@ -19,6 +19,7 @@ type mutateOptions struct {
 	mutateConfigFileFunc          func(context.Context, *v1.ConfigFile) (*v1.ConfigFile, error)
 	mutateImageLayersFunc         func(context.Context, []v1.Layer) ([]mutate.Addendum, error)
 	mutateManifestAnnotationsFunc func(context.Context, *v1.Manifest) (map[string]string, error)
+	platforms                     []*v1.Platform
 }
 
 func WithConfigMutation(f func(context.Context, v1.Config) (v1.Config, error)) MutateOption {
@@ -45,6 +46,24 @@ func WithManifestAnnotationsFunc(f func(context.Context, *v1.Manifest) (map[stri
 	}
 }
 
+// WithPlatforms keeps only the manifests of the specified platforms in the image index, the image must be of one of
+// the specified platforms.
+func WithPlatforms(platforms []string) (MutateOption, error) {
+	var parsedPlatforms []*v1.Platform
+	for _, platform := range platforms {
+		parsedPlatform, err := v1.ParsePlatform(platform)
+		if err != nil {
+			return nil, fmt.Errorf("unable to parse platform %q: %w", platform, err)
+		}
+
+		parsedPlatforms = append(parsedPlatforms, parsedPlatform)
+	}
+
+	return func(opts *mutateOptions) {
+		opts.platforms = parsedPlatforms
+	}, nil
+}
+
 type Api interface {
 	MutateImageOrIndex(ctx context.Context, imageOrIndex interface{}, dest name.Reference, isDestRefByDigest bool, opts ...MutateOption) (interface{}, error)
 }
@@ -59,6 +78,9 @@ type MutateImageOrIndexOpts struct {
 func MutateImageOrIndex(ctx context.Context, opts MutateImageOrIndexOpts) (interface{}, name.Reference, error) {
 	switch obj := opts.ImageOrIndex.(type) {
 	case v1.Image:
+		if err := checkImagePlatform(obj, applyMutateOptions(opts.MutateOptions...).platforms); err != nil {
+			return nil, nil, err
+		}
 		return mutateImage(ctx, obj, opts.Dest, opts.IsDestRefByDigest, opts.MutateOptions...)
 	case v1.ImageIndex:
 		return mutateIndex(ctx, obj, opts.Dest, opts.IsDestRefByDigest, opts.MutateOptions...)
@@ -148,10 +170,16 @@ func mutateIndex(ctx context.Context, index v1.ImageIndex, dest name.Reference,
 		return nil, nil, fmt.Errorf("getting image index manifest: %w", err)
 	}
 
+	options := applyMutateOptions(opts...)
+
 	newIndex := mutate.IndexMediaType(empty.Index, types.DockerManifestList)
 	addenda := make([]mutate.IndexAddendum, 0, len(indexManifest.Manifests))
 
 	for _, desc := range indexManifest.Manifests {
+		if len(options.platforms) > 0 && desc.Platform != nil && !matchPlatforms(*desc.Platform, options.platforms) {
+			continue
+		}
+
 		subRef := dest.Context().Digest(desc.Digest.String())
 		addendum, err := mutateManifestEntry(ctx, index, desc, subRef, opts...)
 		if err != nil {
@@ -160,6 +188,10 @@ func mutateIndex(ctx context.Context, index v1.ImageIndex, dest name.Reference,
 		addenda = append(addenda, *addendum)
 	}
 
+	if len(addenda) == 0 {
+		return nil, nil, fmt.Errorf("no manifests of the specified platforms found in the image index")
+	}
+
 	newIndex = mutate.AppendManifests(newIndex, addenda...)
 
 	digest, err := newIndex.Digest()
@@ -221,6 +253,32 @@ func resolveDestRef(dest name.Reference, digest v1.Hash, byDigest bool) name.Ref
 	return dest
 }
 
+func checkImagePlatform(image v1.Image, platforms []*v1.Platform) error {
+	if len(platforms) == 0 {
+		return nil
+	}
+
+	cf, err := image.ConfigFile()
+	if err != nil {
+		return fmt.Errorf("error reading config file: %w", err)
+	}
+
+	if platform := cf.Platform(); platform == nil || !matchPlatforms(*platform, platforms) {
+		return fmt.Errorf("image platform %v does not match the specified platforms", platform)
+	}
+
+	return nil
+}
+
+func matchPlatforms(platform v1.Platform, platforms []*v1.Platform) bool {
+	for _, spec := range platforms {
+		if platform.Satisfies(*spec) {
+			return true
+		}
+	}
+	return false
+}
+
 func applyMutateOptions(opts ...MutateOption) *mutateOptions {
 	options := &mutateOptions{}
 	for _, o := range opts {
@@ -0,0 +1,76 @@
+// Package credhelper resolves the container registry credentials with the docker credential helpers
+// (docker-credential-<name>) configured per registry in the werf registry auth config.
+package credhelper
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+)
+
+// DefaultRefreshInterval is the period after which the credentials are requested from the helper again.
+// It is less than the lifetime of the short-lived registry tokens (e.g. AWS ECR, Azure ACR, Google GAR),
+// so the token does not expire during the long build.
+const DefaultRefreshInterval = 10 * time.Minute
+
+// Config is the werf registry auth config, e.g.:
+//
+//	{
+//	  "credHelpers": {
+//	    "123456789012.dkr.ecr.eu-central-1.amazonaws.com": "ecr-login",
+//	    "ghcr.io": "pass"
+//	  },
+//	  "refreshInterval": "5m"
+//	}
+type Config struct {
+	// CredHelpers maps the registry host to the credential helper name, e.g. ecr-login for docker-credential-ecr-login.
+	CredHelpers map[string]string `json:"credHelpers,omitempty"`
+	// RefreshInterval is the duration string, DefaultRefreshInterval is used if not specified.
+	RefreshInterval string `json:"refreshInterval,omitempty"`
+}
+
+// LoadConfig loads the config from the path. The empty config is returned if the file does not exist.
+func LoadConfig(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return &Config{}, nil
+		}
+		return nil, fmt.Errorf("unable to read registry auth config %q: %w", path, err)
+	}
+
+	cfg := &Config{}
+	if err := json.Unmarshal(data, cfg); err != nil {
+		return nil, fmt.Errorf("unable to parse registry auth config %q: %w", path, err)
+	}
+
+	if _, err := cfg.GetRefreshInterval(); err != nil {
+		return nil, fmt.Errorf("bad registry auth config %q: %w", path, err)
+	}
+
+	for registry, helper := range cfg.CredHelpers {
+		if registry == "" || helper == "" {
+			return nil, fmt.Errorf("bad registry auth config %q: registry and credential helper name expected, got %q: %q", path, registry, helper)
+		}
+	}
+
+	return cfg, nil
+}
+
+func (c *Config) GetRefreshInterval() (time.Duration, error) {
+	if c.RefreshInterval == "" {
+		return DefaultRefreshInterval, nil
+	}
+
+	d, err := time.ParseDuration(c.RefreshInterval)
+	if err != nil {
+		return 0, fmt.Errorf("unable to parse refreshInterval %q: %w", c.RefreshInterval, err)
+	}
+
+	if d <= 0 {
+		return 0, fmt.Errorf("refreshInterval should be positive, got %q", c.RefreshInterval)
+	}
+
+	return d, nil
+}
@@ -0,0 +1,151 @@
+package credhelper
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/docker/docker-credential-helpers/client"
+	"github.com/docker/docker-credential-helpers/credentials"
+	"github.com/google/go-containerregistry/pkg/authn"
+
+	"github.com/werf/werf/v2/pkg/docker_registry/mirror"
+)
+
+// DockerHubServerURL is the server url, which is used by docker for the docker.io credentials.
+const DockerHubServerURL = "https://index.docker.io/v1/"
+
+// tokenUsername is returned by the helper instead of the username for the identity token.
+const tokenUsername = "<token>"
+
+var defaultKeychain = &Keychain{}
+
+// Init sets the keychain, which is used by all werf components in the process.
+func Init(cfg *Config) error {
+	k, err := NewKeychain(cfg)
+	if err != nil {
+		return err
+	}
+
+	defaultKeychain = k
+	return nil
+}
+
+func DefaultKeychain() *Keychain {
+	return defaultKeychain
+}
+
+// Keychain resolves the credentials of the registries with the configured credential helpers.
+// The credentials are cached and requested from the helper again after the refresh interval,
+// so the short-lived tokens are refreshed during the long operations.
+// The anonymous credentials are returned for the registries without the helper.
+type Keychain struct {
+	helpers         map[string]string
+	refreshInterval time.Duration
+
+	program func(helper string) client.ProgramFunc
+	now     func() time.Time
+
+	mu    sync.Mutex
+	cache map[string]cachedCredentials
+}
+
+type cachedCredentials struct {
+	config    authn.AuthConfig
+	expiresAt time.Time
+}
+
+func NewKeychain(cfg *Config) (*Keychain, error) {
+	refreshInterval, err := cfg.GetRefreshInterval()
+	if err != nil {
+		return nil, err
+	}
+
+	helpers := make(map[string]string, len(cfg.CredHelpers))
+	for registry, helper := range cfg.CredHelpers {
+		helpers[mirror.NormalizeRegistry(registry)] = helper
+	}
+
+	return &Keychain{
+		helpers:         helpers,
+		refreshInterval: refreshInterval,
+		program: func(helper string) client.ProgramFunc {
+			return client.NewShellProgramFunc("docker-credential-" + helper)
+		},
+		now:   time.Now,
+		cache: make(map[string]cachedCredentials),
+	}, nil
+}
+
+// CredentialHelpers returns the credential helpers in the docker config format, where docker.io is keyed by DockerHubServerURL.
+func (k *Keychain) CredentialHelpers() map[string]string {
+	if k == nil || len(k.helpers) == 0 {
+		return nil
+	}
+
+	res := make(map[string]string, len(k.helpers))
+	for registry, helper := range k.helpers {
+		res[serverURL(registry)] = helper
+	}
+
+	return res
+}
+
+// Resolve implements authn.Keychain.
+func (k *Keychain) Resolve(resource authn.Resource) (authn.Authenticator, error) {
+	cfg, found, err := k.Get(resource.RegistryStr())
+	if err != nil {
+		return nil, err
+	}
+
+	if !found {
+		return authn.Anonymous, nil
+	}
+
+	return authn.FromConfig(cfg), nil
+}
+
+// Get returns the credentials of the registry and false if the registry has no credential helper or the helper has no credentials.
+func (k *Keychain) Get(registry string) (authn.AuthConfig, bool, error) {
+	if k == nil {
+		return authn.AuthConfig{}, false, nil
+	}
+
+	registry = mirror.NormalizeRegistry(registry)
+
+	helper, ok := k.helpers[registry]
+	if !ok {
+		return authn.AuthConfig{}, false, nil
+	}
+
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	if cached, ok := k.cache[registry]; ok && k.now().Before(cached.expiresAt) {
+		return cached.config, true, nil
+	}
+
+	creds, err := client.Get(k.program(helper), serverURL(registry))
+	if err != nil {
+		if credentials.IsErrCredentialsNotFound(err) {
+			return authn.AuthConfig{}, false, nil
+		}
+		return authn.AuthConfig{}, false, fmt.Errorf("unable to get credentials for registry %q with docker-credential-%s: %w", registry, helper, err)
+	}
+
+	cfg := authn.AuthConfig{Username: creds.Username, Password: creds.Secret}
+	if creds.Username == tokenUsername {
+		cfg = authn.AuthConfig{IdentityToken: creds.Secret}
+	}
+
+	k.cache[registry] = cachedCredentials{config: cfg, expiresAt: k.now().Add(k.refreshInterval)}
+
+	return cfg, true, nil
+}
+
+func serverURL(registry string) string {
+	if registry == mirror.DockerHubRegistry {
+		return DockerHubServerURL
+	}
+	return registry
+}
@@ -0,0 +1,143 @@
+package credhelper
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/docker/docker-credential-helpers/client"
+	"github.com/google/go-containerregistry/pkg/authn"
+	"github.com/google/go-containerregistry/pkg/name"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+type fakeProgram struct {
+	output func(serverURL string) ([]byte, error)
+	input  bytes.Buffer
+}
+
+func (p *fakeProgram) Output() ([]byte, error) {
+	return p.output(p.input.String())
+}
+
+func (p *fakeProgram) Input(in io.Reader) {
+	_, _ = p.input.ReadFrom(in)
+}
+
+var _ = Describe("LoadConfig", func() {
+	It("returns the empty config if the file does not exist", func() {
+		cfg, err := LoadConfig(filepath.Join(GinkgoT().TempDir(), "absent.json"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(cfg.CredHelpers).To(BeEmpty())
+	})
+
+	It("loads the credential helpers and the refresh interval", func() {
+		path := filepath.Join(GinkgoT().TempDir(), "registry-auth.json")
+		Expect(os.WriteFile(path, []byte(`{"credHelpers": {"ghcr.io": "pass"}, "refreshInterval": "5m"}`), 0o644)).To(Succeed())
+
+		cfg, err := LoadConfig(path)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(cfg.CredHelpers).To(Equal(map[string]string{"ghcr.io": "pass"}))
+
+		refreshInterval, err := cfg.GetRefreshInterval()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(refreshInterval).To(Equal(5 * time.Minute))
+	})
+
+	It("fails on the bad refresh interval", func() {
+		path := filepath.Join(GinkgoT().TempDir(), "registry-auth.json")
+		Expect(os.WriteFile(path, []byte(`{"refreshInterval": "-1m"}`), 0o644)).To(Succeed())
+
+		_, err := LoadConfig(path)
+		Expect(err).To(HaveOccurred())
+	})
+})
+
+var _ = Describe("Keychain", func() {
+	var keychain *Keychain
+	var now time.Time
+	var calls []string
+	var secret string
+	var helperErr error
+
+	BeforeEach(func() {
+		var err error
+		keychain, err = NewKeychain(&Config{CredHelpers: map[string]string{
+			"123456789012.dkr.ecr.eu-central-1.amazonaws.com": "ecr-login",
+			"index.docker.io": "pass",
+		}})
+		Expect(err).NotTo(HaveOccurred())
+
+		now = time.Now()
+		calls = nil
+		secret = "token-1"
+		helperErr = nil
+
+		keychain.now = func() time.Time { return now }
+		keychain.program = func(helper string) client.ProgramFunc {
+			return func(args ...string) client.Program {
+				return &fakeProgram{output: func(serverURL string) ([]byte, error) {
+					calls = append(calls, helper+" "+serverURL)
+					if helperErr != nil {
+						return []byte(helperErr.Error()), helperErr
+					}
+					return []byte(`{"ServerURL": "` + serverURL + `", "Username": "AWS", "Secret": "` + secret + `"}`), nil
+				}}
+			}
+		}
+	})
+
+	resolve := func(reference string) authn.AuthConfig {
+		ref, err := name.ParseReference(reference)
+		Expect(err).NotTo(HaveOccurred())
+
+		authenticator, err := keychain.Resolve(ref.Context())
+		Expect(err).NotTo(HaveOccurred())
+
+		cfg, err := authenticator.Authorization()
+		Expect(err).NotTo(HaveOccurred())
+
+		return *cfg
+	}
+
+	It("refreshes the credentials after the refresh interval", func() {
+		Expect(resolve("123456789012.dkr.ecr.eu-central-1.amazonaws.com/app:1")).To(Equal(authn.AuthConfig{Username: "AWS", Password: "token-1"}))
+
+		secret = "token-2"
+		Expect(resolve("123456789012.dkr.ecr.eu-central-1.amazonaws.com/app:2").Password).To(Equal("token-1"))
+
+		now = now.Add(DefaultRefreshInterval + time.Second)
+		Expect(resolve("123456789012.dkr.ecr.eu-central-1.amazonaws.com/app:3").Password).To(Equal("token-2"))
+
+		Expect(calls).To(Equal([]string{
+			"ecr-login 123456789012.dkr.ecr.eu-central-1.amazonaws.com",
+			"ecr-login 123456789012.dkr.ecr.eu-central-1.amazonaws.com",
+		}))
+	})
+
+	It("uses the docker server url for docker.io", func() {
+		Expect(resolve("alpine:3.20").Password).To(Equal("token-1"))
+		Expect(calls).To(Equal([]string{"pass " + DockerHubServerURL}))
+		Expect(keychain.CredentialHelpers()).To(HaveKeyWithValue(DockerHubServerURL, "pass"))
+	})
+
+	It("returns the anonymous credentials for the registry without the helper", func() {
+		Expect(resolve("ghcr.io/werf/app:1")).To(Equal(authn.AuthConfig{}))
+		Expect(calls).To(BeEmpty())
+	})
+
+	It("returns the anonymous credentials if the helper has no credentials", func() {
+		helperErr = errors.New("credentials not found in native keychain")
+		Expect(resolve("alpine:3.20")).To(Equal(authn.AuthConfig{}))
+	})
+
+	It("fails if the helper fails", func() {
+		helperErr = errors.New("exit status 1")
+		_, _, err := keychain.Get("docker.io")
+		Expect(err).To(HaveOccurred())
+	})
+})
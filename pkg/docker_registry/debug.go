@@ -122,6 +122,20 @@ func (r *DockerRegistryTracer) PullImageArchive(ctx context.Context, archiveWrit
 	return
 }
 
+func (r *DockerRegistryTracer) MutateAndWriteOCIArchive(ctx context.Context, sourceReference, archivePath, refName string, opts ...registry_api.MutateOption) (err error) {
+	logboek.Context(ctx).Default().LogProcess("DockerRegistryTracer.MutateAndWriteOCIArchive %q -> %q", sourceReference, archivePath).Do(func() {
+		err = r.DockerRegistry.MutateAndWriteOCIArchive(ctx, sourceReference, archivePath, refName, opts...)
+	})
+	return
+}
+
+func (r *DockerRegistryTracer) WriteOCILayout(ctx context.Context, sourceReference, layoutDir, refName string) (err error) {
+	logboek.Context(ctx).Default().LogProcess("DockerRegistryTracer.WriteOCILayout %q -> %q", sourceReference, layoutDir).Do(func() {
+		err = r.DockerRegistry.WriteOCILayout(ctx, sourceReference, layoutDir, refName)
+	})
+	return
+}
+
 func (r *DockerRegistryTracer) PushManifestList(ctx context.Context, reference string, opts ManifestListOptions) (err error) {
 	logboek.Context(ctx).Default().LogProcess("DockerRegistryTracer.PushManifestList %q", reference).Do(func() {
 		err = r.DockerRegistry.PushManifestList(ctx, reference, opts)
@@ -2,34 +2,35 @@ package docker_registry
 
 import (
 	"context"
+	"errors"
 	"fmt"
-	"net/url"
 
-	"github.com/google/go-containerregistry/pkg/name"
 	v1 "github.com/google/go-containerregistry/pkg/v1"
 
 	registry_api "github.com/werf/werf/v2/pkg/docker_registry/api"
+	"github.com/werf/werf/v2/pkg/docker_registry/mirror"
 	"github.com/werf/werf/v2/pkg/image"
 )
 
 type genericApi struct {
 	commonApi *api
-	mirrors   []string
+	mirrors   []*mirror.Mirror
 }
 
 func newGenericApi(_ context.Context, options apiOptions) (*genericApi, error) {
-	// Pre-parse http:// mirrors and add them to insecure hosts list
+	mirrors, err := mirror.ParseList(options.RegistryMirrors)
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse registry mirrors: %w", err)
+	}
+
+	// Add http:// mirrors to insecure hosts list
 	// to avoid runtime mutation of insecureRegistryHosts map.
-	insecureHosts := make([]string, 0, len(options.InsecureRegistryHosts)+len(options.RegistryMirrors))
+	insecureHosts := make([]string, 0, len(options.InsecureRegistryHosts)+len(mirrors))
 	insecureHosts = append(insecureHosts, options.InsecureRegistryHosts...)
 
-	for _, mirror := range options.RegistryMirrors {
-		mirrorUrl, err := url.Parse(mirror)
-		if err != nil {
-			continue
-		}
-		if mirrorUrl.Scheme == "http" && mirrorUrl.Host != "" {
-			insecureHosts = append(insecureHosts, mirrorUrl.Host)
+	for _, m := range mirrors {
+		if m.Insecure {
+			insecureHosts = append(insecureHosts, m.Host())
 		}
 	}
 
@@ -38,7 +39,7 @@ func newGenericApi(_ context.Context, options apiOptions) (*genericApi, error) {
 
 	d := &genericApi{}
 	d.commonApi = newAPI(opts)
-	d.mirrors = options.RegistryMirrors
+	d.mirrors = mirrors
 	return d, nil
 }
 
@@ -46,42 +47,32 @@ func (api *genericApi) MutateAndPushImage(ctx context.Context, sourceReference,
 	return api.commonApi.MutateAndPushImage(ctx, sourceReference, destinationReference, opts...)
 }
 
-func trySequentially[T any](items []string, getter func(string) (T, bool, error)) (T, bool, error) {
-	var zero T
+// errMirrorImageNotFound is used to try the next endpoint when the image is not found in the mirror.
+var errMirrorImageNotFound = errors.New("image not found in mirror")
 
-	for _, item := range items {
-		value, ok, err := getter(item)
-		if err != nil {
-			continue
-		}
-		if ok {
-			return value, true, nil
-		}
+func (api *genericApi) failoverOptions() mirror.FailoverOptions {
+	return mirror.FailoverOptions{
+		IsNotFoundErr: func(err error) bool {
+			return errors.Is(err, errMirrorImageNotFound) || IsStatusNotFoundErr(err) || IsImageNotFoundError(err) || IsBrokenImageError(err)
+		},
 	}
-
-	return zero, false, nil
 }
 
 func (api *genericApi) GetRepoImageConfigFile(ctx context.Context, reference string) (*v1.ConfigFile, error) {
-	mirrorReferenceList, err := api.mirrorReferenceList(ctx, reference)
-	if err != nil {
-		return nil, fmt.Errorf("unable to prepare mirror reference list: %w", err)
-	}
-
-	for _, mirrorReference := range mirrorReferenceList {
-		config, err := api.getRepoImageConfigFile(ctx, mirrorReference)
+	var config *v1.ConfigFile
+	if _, err := mirror.Failover(ctx, reference, api.mirrors, api.failoverOptions(), func(ctx context.Context, endpoint mirror.Endpoint) error {
+		c, err := api.getRepoImageConfigFile(ctx, endpoint.Reference)
 		if err != nil {
-			if IsStatusNotFoundErr(err) || IsImageNotFoundError(err) || IsBrokenImageError(err) {
-				continue
-			}
-
-			return nil, err
+			return err
 		}
 
-		return config, nil
+		config = c
+		return nil
+	}); err != nil {
+		return nil, err
 	}
 
-	return api.getRepoImageConfigFile(ctx, reference)
+	return config, nil
 }
 
 func (api *genericApi) getRepoImageConfigFile(ctx context.Context, reference string) (*v1.ConfigFile, error) {
@@ -99,62 +90,31 @@ func (api *genericApi) getRepoImageConfigFile(ctx context.Context, reference str
 }
 
 func (api *genericApi) GetRepoImage(ctx context.Context, reference string) (*image.Info, error) {
-	mirrorReferenceList, err := api.mirrorReferenceList(ctx, reference)
-	if err != nil {
-		return nil, fmt.Errorf("unable to prepare mirror reference list: %w", err)
-	}
+	var info *image.Info
+	if _, err := mirror.Failover(ctx, reference, api.mirrors, api.failoverOptions(), func(ctx context.Context, endpoint mirror.Endpoint) error {
+		if endpoint.Mirror == nil {
+			i, err := api.commonApi.GetRepoImage(ctx, endpoint.Reference)
+			if err != nil {
+				return err
+			}
 
-	info, found, err := trySequentially(mirrorReferenceList, func(mirrorReference string) (*image.Info, bool, error) {
-		info, err := api.commonApi.TryGetRepoImage(ctx, mirrorReference)
-		if err != nil {
-			return nil, false, err
-		}
-		if info == nil {
-			return nil, false, nil
+			info = i
+			return nil
 		}
-		return info, true, nil
-	})
-	if err != nil {
-		return nil, err
-	}
-	if found {
-		return info, nil
-	}
-
-	return api.commonApi.GetRepoImage(ctx, reference)
-}
-
-func (api *genericApi) mirrorReferenceList(_ context.Context, reference string) ([]string, error) {
-	var referenceList []string
 
-	referenceParts, err := api.commonApi.parseReferenceParts(reference)
-	if err != nil {
-		return nil, fmt.Errorf("unable to parse reference %q: %w", reference, err)
-	}
-
-	// nothing if container registry is not Docker Hub
-	if referenceParts.registry != name.DefaultRegistry {
-		return nil, nil
-	}
-
-	for _, mirrorRegistry := range api.mirrors {
-		mirrorRegistryUrl, err := url.Parse(mirrorRegistry)
+		i, err := api.commonApi.TryGetRepoImage(ctx, endpoint.Reference)
 		if err != nil {
-			return nil, fmt.Errorf("unable to parse mirror registry url %q: %w", mirrorRegistry, err)
+			return err
 		}
-
-		mirrorHost := mirrorRegistryUrl.Host
-
-		mirrorReference := mirrorHost
-		mirrorReference += "/" + referenceParts.repository
-		mirrorReference += ":" + referenceParts.tag
-
-		if referenceParts.digest != "" {
-			mirrorReference += "@" + referenceParts.digest
+		if i == nil {
+			return errMirrorImageNotFound
 		}
 
-		referenceList = append(referenceList, mirrorReference)
+		info = i
+		return nil
+	}); err != nil {
+		return nil, err
 	}
 
-	return referenceList, nil
+	return info, nil
 }
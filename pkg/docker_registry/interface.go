@@ -31,6 +31,8 @@ type Interface interface {
 	PushImageArchive(ctx context.Context, archiveOpener ArchiveOpener, reference string) error
 	PullImageArchive(ctx context.Context, archiveWriter io.Writer, reference string) error
 	PushManifestList(ctx context.Context, reference string, opts ManifestListOptions) error
+	MutateAndWriteOCIArchive(ctx context.Context, sourceReference, archivePath, refName string, opts ...docker_registry_api.MutateOption) error
+	WriteOCILayout(ctx context.Context, sourceReference, layoutDir, refName string) error
 
 	String() string
 
@@ -0,0 +1,165 @@
+package mirror
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/google/go-containerregistry/pkg/name"
+
+	"github.com/werf/logboek"
+)
+
+// DefaultUnhealthyPeriod is the period during which the failed mirror is skipped.
+const DefaultUnhealthyPeriod = 5 * time.Minute
+
+// DefaultHealth is shared by all werf components in the process, so the mirror,
+// which is found unavailable by one of them, is skipped by the others.
+var DefaultHealth = NewHealth(DefaultUnhealthyPeriod)
+
+// Health tracks the unavailable mirrors.
+type Health struct {
+	mu              sync.Mutex
+	unhealthyPeriod time.Duration
+	unhealthyUntil  map[string]time.Time
+	now             func() time.Time
+}
+
+func NewHealth(unhealthyPeriod time.Duration) *Health {
+	return &Health{
+		unhealthyPeriod: unhealthyPeriod,
+		unhealthyUntil:  make(map[string]time.Time),
+		now:             time.Now,
+	}
+}
+
+func (h *Health) MarkFailed(m *Mirror) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.unhealthyUntil[m.Location] = h.now().Add(h.unhealthyPeriod)
+}
+
+func (h *Health) MarkHealthy(m *Mirror) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	delete(h.unhealthyUntil, m.Location)
+}
+
+func (h *Health) IsHealthy(m *Mirror) bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	until, ok := h.unhealthyUntil[m.Location]
+	if !ok {
+		return true
+	}
+
+	if h.now().After(until) {
+		delete(h.unhealthyUntil, m.Location)
+		return true
+	}
+
+	return false
+}
+
+// Endpoint is the reference of the image in the mirror or in the origin registry.
+type Endpoint struct {
+	Reference string
+	// Mirror is nil for the origin registry.
+	Mirror *Mirror
+}
+
+// Host returns the mirror host or the origin registry host, which serves the endpoint.
+func (e Endpoint) Host() string {
+	if e.Mirror != nil {
+		return e.Mirror.Host()
+	}
+
+	ref, err := name.ParseReference(e.Reference)
+	if err != nil {
+		return ""
+	}
+
+	return NormalizeRegistry(ref.Context().RegistryStr())
+}
+
+// Endpoints returns the endpoints to try in order: the healthy mirrors of the reference registry and then the origin.
+func Endpoints(reference string, mirrors []*Mirror, health *Health) ([]Endpoint, error) {
+	ref, err := name.ParseReference(reference)
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse reference %q: %w", reference, err)
+	}
+
+	var endpoints []Endpoint
+	for _, m := range ForRegistry(mirrors, ref.Context().RegistryStr()) {
+		if health != nil && !health.IsHealthy(m) {
+			continue
+		}
+
+		endpoints = append(endpoints, Endpoint{
+			Reference: mirrorReference(m, ref),
+			Mirror:    m,
+		})
+	}
+
+	return append(endpoints, Endpoint{Reference: reference}), nil
+}
+
+// mirrorReference returns the reference of the image in the mirror, the tag is omitted for the digest reference.
+func mirrorReference(m *Mirror, ref name.Reference) string {
+	res := m.Location + "/" + ref.Context().RepositoryStr()
+
+	switch r := ref.(type) {
+	case name.Digest:
+		res += "@" + r.DigestStr()
+	case name.Tag:
+		res += ":" + r.TagStr()
+	}
+
+	return res
+}
+
+type FailoverOptions struct {
+	// Health is DefaultHealth if not specified.
+	Health *Health
+	// IsNotFoundErr reports the errors, which mean that the mirror is available, but has no image.
+	// Such a mirror is skipped for the current image only.
+	IsNotFoundErr func(err error) bool
+}
+
+// Failover calls f for the endpoints of the reference until it succeeds and returns the endpoint, which served the request.
+// The failed mirror is marked as unhealthy and skipped by the subsequent calls during the unhealthy period.
+// The error of the origin registry is returned as is.
+func Failover(ctx context.Context, reference string, mirrors []*Mirror, opts FailoverOptions, f func(ctx context.Context, endpoint Endpoint) error) (Endpoint, error) {
+	health := opts.Health
+	if health == nil {
+		health = DefaultHealth
+	}
+
+	endpoints, err := Endpoints(reference, mirrors, health)
+	if err != nil {
+		// The origin reports the proper error for the bad reference.
+		endpoints = []Endpoint{{Reference: reference}}
+	}
+
+	for _, endpoint := range endpoints {
+		err := f(ctx, endpoint)
+		if endpoint.Mirror == nil || err == nil {
+			if endpoint.Mirror != nil {
+				health.MarkHealthy(endpoint.Mirror)
+			}
+			return endpoint, err
+		}
+
+		if opts.IsNotFoundErr != nil && opts.IsNotFoundErr(err) {
+			logboek.Context(ctx).Debug().LogF("Image %q not found in mirror %s, trying next endpoint\n", reference, endpoint.Mirror.URL())
+			continue
+		}
+
+		health.MarkFailed(endpoint.Mirror)
+		logboek.Context(ctx).Warn().LogF("WARNING: Mirror %s is unavailable for %q, trying next endpoint: %s\n", endpoint.Mirror.URL(), reference, err)
+	}
+
+	panic("unreachable: the origin endpoint is always the last one")
+}
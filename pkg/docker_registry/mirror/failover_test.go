@@ -0,0 +1,111 @@
+package mirror
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var errNotFound = errors.New("not found")
+
+var _ = Describe("Endpoints", func() {
+	DescribeTable("returns the mirror references followed by the origin reference",
+		func(reference string, expected []string) {
+			mirrors, err := ParseList([]string{"mirror.example.com", "ghcr.io=https://nexus.example.com/ghcr"})
+			Expect(err).NotTo(HaveOccurred())
+
+			endpoints, err := Endpoints(reference, mirrors, nil)
+			Expect(err).NotTo(HaveOccurred())
+
+			var references []string
+			for _, endpoint := range endpoints {
+				references = append(references, endpoint.Reference)
+			}
+			Expect(references).To(Equal(expected))
+		},
+		Entry("official docker.io image", "alpine:3.20", []string{"mirror.example.com/library/alpine:3.20", "alpine:3.20"}),
+		Entry("docker.io image without tag", "werf/werf", []string{"mirror.example.com/werf/werf:latest", "werf/werf"}),
+		Entry("ghcr.io image by digest", "ghcr.io/werf/app@sha256:0000000000000000000000000000000000000000000000000000000000000000", []string{
+			"nexus.example.com/ghcr/werf/app@sha256:0000000000000000000000000000000000000000000000000000000000000000",
+			"ghcr.io/werf/app@sha256:0000000000000000000000000000000000000000000000000000000000000000",
+		}),
+		Entry("registry without mirrors", "quay.io/org/app:1", []string{"quay.io/org/app:1"}),
+	)
+})
+
+var _ = Describe("Failover", func() {
+	var mirrors []*Mirror
+	var health *Health
+	var now time.Time
+
+	BeforeEach(func() {
+		var err error
+		mirrors, err = ParseList([]string{"bad.example.com", "good.example.com"})
+		Expect(err).NotTo(HaveOccurred())
+
+		now = time.Now()
+		health = NewHealth(time.Minute)
+		health.now = func() time.Time { return now }
+	})
+
+	failover := func(f func(endpoint Endpoint) error) (Endpoint, []string, error) {
+		var calls []string
+		endpoint, err := Failover(context.Background(), "alpine:3.20", mirrors, FailoverOptions{
+			Health:        health,
+			IsNotFoundErr: func(err error) bool { return errors.Is(err, errNotFound) },
+		}, func(_ context.Context, endpoint Endpoint) error {
+			calls = append(calls, endpoint.Reference)
+			return f(endpoint)
+		})
+		return endpoint, calls, err
+	}
+
+	It("skips the failed mirror and uses the next one", func() {
+		endpoint, calls, err := failover(func(endpoint Endpoint) error {
+			if endpoint.Mirror != nil && endpoint.Mirror.Location == "bad.example.com" {
+				return errors.New("dns failure")
+			}
+			return nil
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(endpoint.Host()).To(Equal("good.example.com"))
+		Expect(calls).To(Equal([]string{"bad.example.com/library/alpine:3.20", "good.example.com/library/alpine:3.20"}))
+
+		By("skipping the unhealthy mirror on the next call")
+		_, calls, err = failover(func(Endpoint) error { return nil })
+		Expect(err).NotTo(HaveOccurred())
+		Expect(calls).To(Equal([]string{"good.example.com/library/alpine:3.20"}))
+
+		By("trying the mirror again after the unhealthy period")
+		now = now.Add(2 * time.Minute)
+		_, calls, err = failover(func(Endpoint) error { return nil })
+		Expect(err).NotTo(HaveOccurred())
+		Expect(calls).To(Equal([]string{"bad.example.com/library/alpine:3.20"}))
+	})
+
+	It("falls back to the origin and keeps the mirrors healthy if the image is not found", func() {
+		endpoint, calls, err := failover(func(endpoint Endpoint) error {
+			if endpoint.Mirror != nil {
+				return errNotFound
+			}
+			return nil
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(endpoint.Mirror).To(BeNil())
+		Expect(endpoint.Host()).To(Equal("docker.io"))
+		Expect(calls).To(HaveLen(3))
+
+		for _, m := range mirrors {
+			Expect(health.IsHealthy(m)).To(BeTrue())
+		}
+	})
+
+	It("returns the error of the origin", func() {
+		_, calls, err := failover(func(Endpoint) error { return errors.New("lookup failed") })
+		Expect(err).To(MatchError("lookup failed"))
+		Expect(calls).To(Equal([]string{"bad.example.com/library/alpine:3.20", "good.example.com/library/alpine:3.20", "alpine:3.20"}))
+	})
+})
@@ -0,0 +1,139 @@
+// Package mirror describes the pull-through cache mirrors of the container registries
+// and the failover from an unavailable mirror to the next one or to the origin registry.
+package mirror
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+const DockerHubRegistry = "docker.io"
+
+// Mirror is the mirror of the upstream registry.
+type Mirror struct {
+	// Registry is the upstream registry host, e.g. docker.io or ghcr.io.
+	Registry string
+	// Location is the mirror host with the optional port and path, e.g. mirror.example.com:5000/ghcr.
+	Location string
+	// Insecure is true for the http:// mirrors.
+	Insecure bool
+}
+
+// Parse parses the mirror specification in the [REGISTRY=]URL format, e.g. ghcr.io=https://ghcr-mirror.example.com.
+// The mirror of docker.io is used if the registry is not specified. The https scheme is used by default.
+func Parse(spec string) (*Mirror, error) {
+	registry, mirrorUrl, found := strings.Cut(spec, "=")
+	if !found || strings.Contains(registry, "://") {
+		registry, mirrorUrl = DockerHubRegistry, spec
+	}
+
+	registry = NormalizeRegistry(strings.TrimSpace(registry))
+	if strings.ContainsAny(registry, "/:") && !isHostWithPort(registry) {
+		return nil, fmt.Errorf("bad mirror %q: registry host expected, got %q", spec, registry)
+	}
+
+	mirrorUrl = strings.TrimSpace(mirrorUrl)
+	if !strings.Contains(mirrorUrl, "://") {
+		mirrorUrl = "https://" + mirrorUrl
+	}
+
+	u, err := url.Parse(mirrorUrl)
+	if err != nil {
+		return nil, fmt.Errorf("bad mirror %q url: %w", spec, err)
+	}
+
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, fmt.Errorf("bad mirror %q url: http or https scheme expected", spec)
+	}
+
+	if u.Host == "" {
+		return nil, fmt.Errorf("bad mirror %q url: host expected", spec)
+	}
+
+	return &Mirror{
+		Registry: registry,
+		Location: u.Host + strings.TrimSuffix(u.Path, "/"),
+		Insecure: u.Scheme == "http",
+	}, nil
+}
+
+// ParseList parses the mirrors specifications, see Parse. The empty specifications are skipped.
+func ParseList(specs []string) ([]*Mirror, error) {
+	var mirrors []*Mirror
+	for _, spec := range specs {
+		if strings.TrimSpace(spec) == "" {
+			continue
+		}
+
+		m, err := Parse(spec)
+		if err != nil {
+			return nil, err
+		}
+		mirrors = append(mirrors, m)
+	}
+
+	return mirrors, nil
+}
+
+// URL returns the mirror url with the scheme.
+func (m *Mirror) URL() string {
+	if m.Insecure {
+		return "http://" + m.Location
+	}
+	return "https://" + m.Location
+}
+
+// Host returns the mirror host with the optional port.
+func (m *Mirror) Host() string {
+	host, _, _ := strings.Cut(m.Location, "/")
+	return host
+}
+
+// String returns the specification of the mirror, which can be parsed by Parse.
+// The registry is omitted for the mirrors of docker.io to keep the specification backward compatible.
+func (m *Mirror) String() string {
+	if m.Registry == DockerHubRegistry {
+		return m.URL()
+	}
+	return m.Registry + "=" + m.URL()
+}
+
+// ForRegistry returns the mirrors of the specified registry in the original order.
+func ForRegistry(mirrors []*Mirror, registry string) []*Mirror {
+	registry = NormalizeRegistry(registry)
+
+	var result []*Mirror
+	for _, m := range mirrors {
+		if m.Registry == registry {
+			result = append(result, m)
+		}
+	}
+
+	return result
+}
+
+// NormalizeRegistry returns docker.io for all Docker Hub registry aliases.
+func NormalizeRegistry(registry string) string {
+	switch registry {
+	case "", "index.docker.io", "registry-1.docker.io":
+		return DockerHubRegistry
+	default:
+		return registry
+	}
+}
+
+func isHostWithPort(s string) bool {
+	host, port, found := strings.Cut(s, ":")
+	if !found || host == "" || port == "" || strings.Contains(s, "/") {
+		return false
+	}
+
+	for _, r := range port {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,44 @@
+package mirror
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Parse", func() {
+	DescribeTable("parses the mirror specification",
+		func(spec string, expected Mirror, expectedString string) {
+			m, err := Parse(spec)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(*m).To(Equal(expected))
+			Expect(m.String()).To(Equal(expectedString))
+		},
+		Entry("docker.io mirror without scheme", "mirror.gcr.io", Mirror{Registry: "docker.io", Location: "mirror.gcr.io"}, "https://mirror.gcr.io"),
+		Entry("docker.io mirror with https scheme", "https://mirror.gcr.io/", Mirror{Registry: "docker.io", Location: "mirror.gcr.io"}, "https://mirror.gcr.io"),
+		Entry("insecure docker.io mirror", "http://mirror.local:5000", Mirror{Registry: "docker.io", Location: "mirror.local:5000", Insecure: true}, "http://mirror.local:5000"),
+		Entry("ghcr.io mirror", "ghcr.io=https://nexus.example.com/ghcr", Mirror{Registry: "ghcr.io", Location: "nexus.example.com/ghcr"}, "ghcr.io=https://nexus.example.com/ghcr"),
+		Entry("registry with port", "registry.local:5000=mirror.local", Mirror{Registry: "registry.local:5000", Location: "mirror.local"}, "registry.local:5000=https://mirror.local"),
+		Entry("docker.io alias", "index.docker.io=mirror.local", Mirror{Registry: "docker.io", Location: "mirror.local"}, "https://mirror.local"),
+	)
+
+	DescribeTable("fails on the bad specification",
+		func(spec string) {
+			_, err := Parse(spec)
+			Expect(err).To(HaveOccurred())
+		},
+		Entry("registry path", "ghcr.io/org=mirror.local"),
+		Entry("unsupported scheme", "ftp://mirror.local"),
+		Entry("empty host", "ghcr.io=https://"),
+	)
+})
+
+var _ = Describe("ForRegistry", func() {
+	It("returns the mirrors of the registry in the original order", func() {
+		mirrors, err := ParseList([]string{"a.example.com", "ghcr.io=b.example.com", "c.example.com"})
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(ForRegistry(mirrors, "index.docker.io")).To(Equal([]*Mirror{mirrors[0], mirrors[2]}))
+		Expect(ForRegistry(mirrors, "ghcr.io")).To(Equal([]*Mirror{mirrors[1]}))
+		Expect(ForRegistry(mirrors, "quay.io")).To(BeEmpty())
+	})
+})
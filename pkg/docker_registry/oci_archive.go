@@ -0,0 +1,198 @@
+package docker_registry
+
+import (
+	"archive/tar"
+	"context"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/empty"
+	"github.com/google/go-containerregistry/pkg/v1/layout"
+
+	"github.com/werf/logboek"
+	registry_api "github.com/werf/werf/v2/pkg/docker_registry/api"
+	"github.com/werf/werf/v2/pkg/werf"
+)
+
+// ociRefNameAnnotation is the annotation of the OCI image layout index with the name of the image reference.
+const ociRefNameAnnotation = "org.opencontainers.image.ref.name"
+
+func (api *api) MutateAndWriteOCIArchive(ctx context.Context, sourceReference, archivePath, refName string, opts ...registry_api.MutateOption) error {
+	imageOrIndex, srcRef, err := api.getImageOrIndex(ctx, sourceReference)
+	if err != nil {
+		return err
+	}
+
+	newImage, _, err := registry_api.MutateImageOrIndex(ctx, registry_api.MutateImageOrIndexOpts{
+		ImageOrIndex:  imageOrIndex,
+		Dest:          srcRef,
+		MutateOptions: opts,
+	})
+	if err != nil {
+		return fmt.Errorf("error mutating image: %w", err)
+	}
+
+	return logboek.Context(ctx).Info().LogProcess("Writing OCI archive %s", archivePath).DoError(func() error {
+		return writeOCIArchive(archivePath, refName, newImage)
+	})
+}
+
+func (api *api) WriteOCILayout(ctx context.Context, sourceReference, layoutDir, refName string) error {
+	imageOrIndex, _, err := api.getImageOrIndex(ctx, sourceReference)
+	if err != nil {
+		return err
+	}
+
+	return logboek.Context(ctx).Info().LogProcess("Writing OCI image layout %s", layoutDir).DoError(func() error {
+		return writeOCILayout(layoutDir, refName, imageOrIndex)
+	})
+}
+
+func (api *api) getImageOrIndex(ctx context.Context, reference string) (interface{}, name.Reference, error) {
+	desc, ref, err := api.getImageDesc(ctx, reference)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error reading image %q: %w", reference, err)
+	}
+
+	switch {
+	case desc.MediaType.IsIndex():
+		index, err := desc.ImageIndex()
+		if err != nil {
+			return nil, nil, fmt.Errorf("getting image index: %w", err)
+		}
+		return index, ref, nil
+	case desc.MediaType.IsImage():
+		img, err := desc.Image()
+		if err != nil {
+			return nil, nil, fmt.Errorf("error getting image manifest: %w", err)
+		}
+		return img, ref, nil
+	default:
+		return nil, nil, fmt.Errorf("unsupported media type %q", desc.MediaType)
+	}
+}
+
+// writeOCIArchive writes the image or index as the tar archive of the OCI image layout with the single image
+// reference. The archive is replaced atomically.
+func writeOCIArchive(archivePath, refName string, imageOrIndex interface{}) error {
+	layoutDir, err := os.MkdirTemp(werf.GetTmpDir(), "werf-oci-layout-")
+	if err != nil {
+		return fmt.Errorf("unable to create tmp dir: %w", err)
+	}
+	defer os.RemoveAll(layoutDir)
+
+	if err := writeOCILayout(layoutDir, refName, imageOrIndex); err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(archivePath), os.ModePerm); err != nil {
+		return fmt.Errorf("unable to create dir %q: %w", filepath.Dir(archivePath), err)
+	}
+
+	// The unique tmp archive in the target dir does not clash with the concurrent writes of the same archive.
+	tmpArchive, err := os.CreateTemp(filepath.Dir(archivePath), filepath.Base(archivePath)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("unable to create tmp archive: %w", err)
+	}
+	tmpArchivePath := tmpArchive.Name()
+
+	if err := writeDirTar(tmpArchive, layoutDir); err != nil {
+		_ = tmpArchive.Close()
+		_ = os.Remove(tmpArchivePath)
+		return fmt.Errorf("unable to write archive %q: %w", tmpArchivePath, err)
+	}
+
+	if err := os.Rename(tmpArchivePath, archivePath); err != nil {
+		_ = os.Remove(tmpArchivePath)
+		return fmt.Errorf("unable to rename %q to %q: %w", tmpArchivePath, archivePath, err)
+	}
+
+	return nil
+}
+
+// writeOCILayout writes the image or index into the empty or not existing dir as the OCI image layout with the single
+// image reference.
+func writeOCILayout(layoutDir, refName string, imageOrIndex interface{}) error {
+	layoutPath, err := layout.Write(layoutDir, empty.Index)
+	if err != nil {
+		return fmt.Errorf("unable to init OCI image layout: %w", err)
+	}
+
+	annotations := layout.WithAnnotations(map[string]string{ociRefNameAnnotation: refName})
+	switch i := imageOrIndex.(type) {
+	case v1.Image:
+		err = layoutPath.AppendImage(i, annotations)
+	case v1.ImageIndex:
+		err = layoutPath.AppendIndex(i, annotations)
+	default:
+		panic(fmt.Sprintf("unexpected object type %#v", i))
+	}
+	if err != nil {
+		return fmt.Errorf("unable to write OCI image layout: %w", err)
+	}
+
+	return nil
+}
+
+func writeDirTar(f *os.File, dir string) error {
+	if err := f.Chmod(0o644); err != nil {
+		return err
+	}
+
+	tw := tar.NewWriter(f)
+
+	if err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || path == dir {
+			return err
+		}
+
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+
+		header, err := tar.FileInfoHeader(info, "")
+		if err != nil {
+			return err
+		}
+
+		relPath, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		header.Name = filepath.ToSlash(relPath)
+		if d.IsDir() {
+			header.Name += "/"
+		}
+
+		if err := tw.WriteHeader(header); err != nil {
+			return err
+		}
+
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+
+		src, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer src.Close()
+
+		_, err = io.Copy(tw, src)
+		return err
+	}); err != nil {
+		return err
+	}
+
+	if err := tw.Close(); err != nil {
+		return err
+	}
+
+	return f.Close()
+}
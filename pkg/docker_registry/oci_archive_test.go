@@ -0,0 +1,178 @@
+package docker_registry
+
+import (
+	"archive/tar"
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/empty"
+	"github.com/google/go-containerregistry/pkg/v1/layout"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/google/go-containerregistry/pkg/v1/random"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	registry_api "github.com/werf/werf/v2/pkg/docker_registry/api"
+)
+
+var _ = Describe("OCI archive", func() {
+	newPlatformImage := func(platform string) v1.Image {
+		p, err := v1.ParsePlatform(platform)
+		Expect(err).NotTo(HaveOccurred())
+
+		img, err := random.Image(64, 1)
+		Expect(err).NotTo(HaveOccurred())
+
+		cf, err := img.ConfigFile()
+		Expect(err).NotTo(HaveOccurred())
+		cf.OS, cf.Architecture = p.OS, p.Architecture
+
+		img, err = mutate.ConfigFile(img, cf)
+		Expect(err).NotTo(HaveOccurred())
+
+		return img
+	}
+
+	newIndex := func(platforms ...string) v1.ImageIndex {
+		var addenda []mutate.IndexAddendum
+		for _, platform := range platforms {
+			p, err := v1.ParsePlatform(platform)
+			Expect(err).NotTo(HaveOccurred())
+
+			addenda = append(addenda, mutate.IndexAddendum{
+				Add:        newPlatformImage(platform),
+				Descriptor: v1.Descriptor{Platform: p},
+			})
+		}
+
+		return mutate.AppendManifests(empty.Index, addenda...)
+	}
+
+	readArchiveLayout := func(archivePath string) layout.Path {
+		dir := GinkgoT().TempDir()
+
+		f, err := os.Open(archivePath)
+		Expect(err).NotTo(HaveOccurred())
+		defer f.Close()
+
+		tr := tar.NewReader(f)
+		for {
+			header, err := tr.Next()
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			Expect(err).NotTo(HaveOccurred())
+
+			path := filepath.Join(dir, header.Name)
+			if header.Typeflag == tar.TypeDir {
+				Expect(os.MkdirAll(path, 0o755)).To(Succeed())
+				continue
+			}
+
+			data, err := io.ReadAll(tr)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(os.WriteFile(path, data, 0o644)).To(Succeed())
+		}
+
+		return layout.Path(dir)
+	}
+
+	It("writes the image with the reference name", func() {
+		archivePath := filepath.Join(GinkgoT().TempDir(), "dist", "app.tar")
+
+		img := newPlatformImage("linux/amd64")
+		Expect(writeOCIArchive(archivePath, "app", img)).To(Succeed())
+		Expect(filepath.Glob(archivePath + ".*.tmp")).To(BeEmpty())
+
+		index, err := readArchiveLayout(archivePath).ImageIndex()
+		Expect(err).NotTo(HaveOccurred())
+
+		indexManifest, err := index.IndexManifest()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(indexManifest.Manifests).To(HaveLen(1))
+		Expect(indexManifest.Manifests[0].Annotations).To(HaveKeyWithValue(ociRefNameAnnotation, "app"))
+
+		expectedDigest, err := img.Digest()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(indexManifest.Manifests[0].Digest).To(Equal(expectedDigest))
+
+		_, err = index.Image(expectedDigest)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("writes the same archive concurrently", func() {
+		archivePath := filepath.Join(GinkgoT().TempDir(), "app.tar")
+		img := newPlatformImage("linux/amd64")
+
+		var wg sync.WaitGroup
+		errs := make([]error, 4)
+		for i := range errs {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				errs[i] = writeOCIArchive(archivePath, "app", img)
+			}()
+		}
+		wg.Wait()
+
+		for _, err := range errs {
+			Expect(err).NotTo(HaveOccurred())
+		}
+		Expect(filepath.Glob(archivePath + ".*.tmp")).To(BeEmpty())
+
+		index, err := readArchiveLayout(archivePath).ImageIndex()
+		Expect(err).NotTo(HaveOccurred())
+
+		indexManifest, err := index.IndexManifest()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(indexManifest.Manifests).To(HaveLen(1))
+	})
+
+	It("writes the index with the selected platforms only", func(ctx context.Context) {
+		archivePath := filepath.Join(GinkgoT().TempDir(), "app.tar")
+
+		platformsOpt, err := registry_api.WithPlatforms([]string{"linux/arm64"})
+		Expect(err).NotTo(HaveOccurred())
+
+		newIndex, _, err := registry_api.MutateImageOrIndex(ctx, registry_api.MutateImageOrIndexOpts{
+			ImageOrIndex:  newIndex("linux/amd64", "linux/arm64"),
+			Dest:          name.MustParseReference("registry.example.com/app:latest"),
+			MutateOptions: []registry_api.MutateOption{platformsOpt},
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(writeOCIArchive(archivePath, "app", newIndex)).To(Succeed())
+
+		index, err := readArchiveLayout(archivePath).ImageIndex()
+		Expect(err).NotTo(HaveOccurred())
+
+		indexManifest, err := index.IndexManifest()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(indexManifest.Manifests).To(HaveLen(1))
+
+		appIndex, err := index.ImageIndex(indexManifest.Manifests[0].Digest)
+		Expect(err).NotTo(HaveOccurred())
+
+		appIndexManifest, err := appIndex.IndexManifest()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(appIndexManifest.Manifests).To(HaveLen(1))
+		Expect(appIndexManifest.Manifests[0].Platform.Architecture).To(Equal("arm64"))
+	})
+
+	It("fails to select the platforms of the image with another platform", func(ctx context.Context) {
+		platformsOpt, err := registry_api.WithPlatforms([]string{"linux/arm64"})
+		Expect(err).NotTo(HaveOccurred())
+
+		_, _, err = registry_api.MutateImageOrIndex(ctx, registry_api.MutateImageOrIndexOpts{
+			ImageOrIndex:  newPlatformImage("linux/amd64"),
+			Dest:          name.MustParseReference("registry.example.com/app:latest"),
+			MutateOptions: []registry_api.MutateOption{platformsOpt},
+		})
+		Expect(err).To(HaveOccurred())
+	})
+})
@@ -0,0 +1,299 @@
+// Package ratelimit schedules the container registry requests of all werf workers according to
+// the rate limits reported by the registry, so the parallel build waits for the limit instead of failing.
+package ratelimit
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/go-containerregistry/pkg/name"
+
+	"github.com/werf/logboek"
+	"github.com/werf/werf/v2/pkg/docker_registry/mirror"
+	"github.com/werf/werf/v2/pkg/opstats"
+)
+
+const (
+	// DefaultThrottlePeriod is used when the registry rejects the request because of the rate limit without the Retry-After header.
+	DefaultThrottlePeriod = time.Minute
+	// MaxWait is the maximum duration of the single wait, the longer limits are reported as errors by the registry.
+	MaxWait = 10 * time.Minute
+
+	rateLimitLimitHeader     = "RateLimit-Limit"
+	rateLimitRemainingHeader = "RateLimit-Remaining"
+	retryAfterHeader         = "Retry-After"
+)
+
+// DefaultScheduler is shared by all werf workers in the process.
+var DefaultScheduler = NewScheduler()
+
+// Scheduler keeps the token bucket per registry host. The bucket is filled from the RateLimit-Limit
+// and RateLimit-Remaining headers (e.g. "100;w=21600" for Docker Hub), and the registry host is blocked
+// for the Retry-After period when the registry rejects the request with 429 or 503 status.
+type Scheduler struct {
+	mu             sync.Mutex
+	buckets        map[string]*bucket
+	throttlePeriod time.Duration
+	now            func() time.Time
+}
+
+type bucket struct {
+	tokens       float64
+	capacity     float64
+	refillPerSec float64
+	updatedAt    time.Time
+	blockedUntil time.Time
+}
+
+func NewScheduler() *Scheduler {
+	return &Scheduler{
+		buckets:        make(map[string]*bucket),
+		throttlePeriod: DefaultThrottlePeriod,
+		now:            time.Now,
+	}
+}
+
+// Wait waits until the registry host is not blocked and has cost tokens in its bucket and takes them.
+// The time spent waiting is reported as the opstats operation.
+func (s *Scheduler) Wait(ctx context.Context, host string, cost float64) error {
+	host = mirror.NormalizeRegistry(host)
+
+	var logged bool
+	for {
+		delay := s.reserve(host, cost)
+		if delay <= 0 {
+			return nil
+		}
+
+		if delay > MaxWait {
+			delay = MaxWait
+		}
+
+		if !logged {
+			logboek.Context(ctx).Warn().LogF("WARNING: Rate limit of registry %s is reached, waiting %s...\n", host, delay.Round(time.Second))
+			logged = true
+		}
+
+		done := opstats.Observe(ctx, opstats.OperationRegistryRateLimitWait)
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			done()
+			return ctx.Err()
+		case <-timer.C:
+			done()
+		}
+	}
+}
+
+// reserve takes cost tokens and returns zero or returns the delay after which the tokens should be available.
+func (s *Scheduler) reserve(host string, cost float64) time.Duration {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	b, ok := s.buckets[host]
+	if !ok {
+		return 0
+	}
+
+	now := s.now()
+	if now.Before(b.blockedUntil) {
+		return b.blockedUntil.Sub(now)
+	}
+
+	b.refill(now)
+
+	if cost <= 0 || b.capacity == 0 {
+		return 0
+	}
+
+	if b.tokens >= cost {
+		b.tokens -= cost
+		return 0
+	}
+
+	if b.refillPerSec <= 0 {
+		return 0
+	}
+
+	return time.Duration((cost - b.tokens) / b.refillPerSec * float64(time.Second))
+}
+
+func (b *bucket) refill(now time.Time) {
+	if b.refillPerSec > 0 && now.After(b.updatedAt) {
+		b.tokens += now.Sub(b.updatedAt).Seconds() * b.refillPerSec
+		if b.tokens > b.capacity {
+			b.tokens = b.capacity
+		}
+	}
+	b.updatedAt = now
+}
+
+// ObserveResponse updates the bucket of the registry host with the rate limit headers of the response.
+func (s *Scheduler) ObserveResponse(host string, resp *http.Response) {
+	host = mirror.NormalizeRegistry(host)
+
+	limit, window, hasLimit := parseRateLimitHeader(resp.Header.Get(rateLimitLimitHeader))
+	remaining, _, hasRemaining := parseRateLimitHeader(resp.Header.Get(rateLimitRemainingHeader))
+
+	var retryAfter time.Duration
+	var hasRetryAfter bool
+	if resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode == http.StatusServiceUnavailable {
+		retryAfter, hasRetryAfter = ParseRetryAfter(resp.Header.Get(retryAfterHeader), s.now())
+		if !hasRetryAfter && resp.StatusCode == http.StatusTooManyRequests {
+			retryAfter, hasRetryAfter = s.throttlePeriod, true
+		}
+	}
+
+	if !hasLimit && !hasRemaining && !hasRetryAfter {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	b := s.getOrCreateBucket(host, now)
+
+	if hasLimit {
+		b.capacity = limit
+		if window > 0 {
+			b.refillPerSec = limit / window.Seconds()
+		}
+	}
+
+	if hasRemaining {
+		b.tokens = remaining
+		b.updatedAt = now
+		if b.capacity < remaining {
+			b.capacity = remaining
+		}
+	}
+
+	if hasRetryAfter {
+		if until := now.Add(retryAfter); until.After(b.blockedUntil) {
+			b.blockedUntil = until
+		}
+	}
+}
+
+// Throttle blocks the registry host for the period, it is used when the rate limit error is reported without the response headers.
+func (s *Scheduler) Throttle(host string, period time.Duration) {
+	host = mirror.NormalizeRegistry(host)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	b := s.getOrCreateBucket(host, now)
+	if until := now.Add(period); until.After(b.blockedUntil) {
+		b.blockedUntil = until
+	}
+}
+
+func (s *Scheduler) getOrCreateBucket(host string, now time.Time) *bucket {
+	b, ok := s.buckets[host]
+	if !ok {
+		b = &bucket{updatedAt: now}
+		s.buckets[host] = b
+	}
+	return b
+}
+
+// parseRateLimitHeader parses the value in the "<quota>;w=<window seconds>" format, the window is optional.
+func parseRateLimitHeader(value string) (float64, time.Duration, bool) {
+	if value == "" {
+		return 0, 0, false
+	}
+
+	parts := strings.Split(value, ";")
+
+	quota, err := strconv.ParseFloat(strings.TrimSpace(parts[0]), 64)
+	if err != nil || quota < 0 {
+		return 0, 0, false
+	}
+
+	var window time.Duration
+	for _, param := range parts[1:] {
+		k, v, found := strings.Cut(strings.TrimSpace(param), "=")
+		if !found || k != "w" {
+			continue
+		}
+
+		if seconds, err := strconv.ParseUint(v, 10, 64); err == nil {
+			window = time.Duration(seconds) * time.Second
+		}
+	}
+
+	return quota, window, true
+}
+
+// ParseRetryAfter parses the Retry-After header value in the seconds or the http-date format.
+func ParseRetryAfter(value string, now time.Time) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+
+	if seconds, err := strconv.ParseUint(value, 10, 32); err == nil {
+		return time.Duration(seconds) * time.Second, true
+	}
+
+	if t, err := http.ParseTime(value); err == nil {
+		if d := t.Sub(now); d > 0 {
+			return d, true
+		}
+		return 0, true
+	}
+
+	return 0, false
+}
+
+// IsRateLimitErr reports the rate limit errors of the container backends, which have no access to the response headers.
+func IsRateLimitErr(err error) bool {
+	msg := strings.ToLower(err.Error())
+	for _, s := range []string{"toomanyrequests", "429 too many requests", "you have reached your pull rate limit"} {
+		if strings.Contains(msg, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// MaxRateLimitedAttempts is the number of attempts of the operation, which fails with the rate limit error.
+const MaxRateLimitedAttempts = 5
+
+// Do waits for the rate limit of the reference registry and calls f. If f fails with the rate limit error,
+// the registry host is throttled for all workers and f is called again after the throttle period.
+func (s *Scheduler) Do(ctx context.Context, ref string, cost float64, f func() error) error {
+	host := ReferenceHost(ref)
+	if host == "" {
+		return f()
+	}
+
+	for attempt := 1; ; attempt++ {
+		if err := s.Wait(ctx, host, cost); err != nil {
+			return err
+		}
+
+		err := f()
+		if err == nil || !IsRateLimitErr(err) || attempt == MaxRateLimitedAttempts {
+			return err
+		}
+
+		s.Throttle(host, s.throttlePeriod)
+	}
+}
+
+// ReferenceHost returns the registry host of the image reference or an empty string if the reference is invalid.
+func ReferenceHost(ref string) string {
+	parsed, err := name.ParseReference(ref)
+	if err != nil {
+		return ""
+	}
+	return mirror.NormalizeRegistry(parsed.Context().RegistryStr())
+}
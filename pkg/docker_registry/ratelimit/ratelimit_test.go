@@ -0,0 +1,113 @@
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func response(status int, headers map[string]string) *http.Response {
+	resp := &http.Response{StatusCode: status, Header: http.Header{}}
+	for k, v := range headers {
+		resp.Header.Set(k, v)
+	}
+	return resp
+}
+
+var _ = Describe("Scheduler", func() {
+	var scheduler *Scheduler
+	var now time.Time
+
+	BeforeEach(func() {
+		now = time.Now()
+		scheduler = NewScheduler()
+		scheduler.now = func() time.Time { return now }
+	})
+
+	It("does not wait for the registry without the rate limit", func() {
+		Expect(scheduler.reserve("ghcr.io", 1)).To(BeZero())
+	})
+
+	It("takes the tokens reported by the registry and waits for the refill", func() {
+		scheduler.ObserveResponse("registry-1.docker.io", response(http.StatusOK, map[string]string{
+			"RateLimit-Limit":     "100;w=21600",
+			"RateLimit-Remaining": "2;w=21600",
+		}))
+
+		Expect(scheduler.Wait(context.Background(), "index.docker.io", 1)).To(Succeed())
+		Expect(scheduler.reserve("docker.io", 1)).To(BeZero())
+		Expect(scheduler.reserve("docker.io", 1)).To(Equal(216 * time.Second))
+
+		By("not taking the tokens for the requests without the cost")
+		Expect(scheduler.reserve("docker.io", 0)).To(BeZero())
+
+		now = now.Add(216 * time.Second)
+		Expect(scheduler.reserve("docker.io", 1)).To(BeZero())
+	})
+
+	It("blocks the registry for the Retry-After period", func() {
+		scheduler.ObserveResponse("quay.io", response(http.StatusTooManyRequests, map[string]string{"Retry-After": "30"}))
+
+		Expect(scheduler.reserve("quay.io", 0)).To(Equal(30 * time.Second))
+
+		now = now.Add(31 * time.Second)
+		Expect(scheduler.reserve("quay.io", 0)).To(BeZero())
+	})
+
+	It("blocks the registry for the default period if Retry-After is not specified", func() {
+		scheduler.ObserveResponse("quay.io", response(http.StatusTooManyRequests, nil))
+		Expect(scheduler.reserve("quay.io", 0)).To(Equal(DefaultThrottlePeriod))
+	})
+
+	It("retries the operation failed with the rate limit error", func() {
+		scheduler.now = time.Now
+		scheduler.throttlePeriod = 10 * time.Millisecond
+
+		var calls int
+		err := scheduler.Do(context.Background(), "ghcr.io/werf/app:1", 1, func() error {
+			calls++
+			if calls == 1 {
+				return errors.New("toomanyrequests: retry later")
+			}
+			return nil
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(calls).To(Equal(2))
+	})
+
+	It("does not retry the operation failed with the other error", func() {
+		var calls int
+		err := scheduler.Do(context.Background(), "ghcr.io/werf/app:1", 1, func() error {
+			calls++
+			return errors.New("manifest unknown")
+		})
+		Expect(err).To(MatchError("manifest unknown"))
+		Expect(calls).To(Equal(1))
+	})
+
+	It("stops waiting when the context is cancelled", func() {
+		scheduler.Throttle("ghcr.io", time.Hour)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		Expect(scheduler.Wait(ctx, "ghcr.io", 0)).To(MatchError(context.Canceled))
+	})
+})
+
+var _ = DescribeTable("ParseRetryAfter",
+	func(value string, expected time.Duration, expectedOk bool) {
+		now := time.Date(2024, 10, 29, 16, 56, 0, 0, time.UTC)
+		d, ok := ParseRetryAfter(value, now)
+		Expect(ok).To(Equal(expectedOk))
+		Expect(d).To(Equal(expected))
+	},
+	Entry("seconds", "120", 2*time.Minute, true),
+	Entry("http-date", "Tue, 29 Oct 2024 16:56:32 GMT", 32*time.Second, true),
+	Entry("past http-date", "Tue, 29 Oct 2024 16:55:00 GMT", time.Duration(0), true),
+	Entry("empty", "", time.Duration(0), false),
+	Entry("bad value", "soon", time.Duration(0), false),
+)
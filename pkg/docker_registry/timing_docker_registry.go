@@ -87,6 +87,16 @@ func (r *timingDockerRegistry) PushImageArchive(ctx context.Context, archiveOpen
 	return r.Interface.PushImageArchive(ctx, archiveOpener, reference)
 }
 
+func (r *timingDockerRegistry) MutateAndWriteOCIArchive(ctx context.Context, sourceReference, archivePath, refName string, opts ...registry_api.MutateOption) error {
+	defer observeRegistry(ctx, "MutateAndWriteOCIArchive")()
+	return r.Interface.MutateAndWriteOCIArchive(ctx, sourceReference, archivePath, refName, opts...)
+}
+
+func (r *timingDockerRegistry) WriteOCILayout(ctx context.Context, sourceReference, layoutDir, refName string) error {
+	defer observeRegistry(ctx, "WriteOCILayout")()
+	return r.Interface.WriteOCILayout(ctx, sourceReference, layoutDir, refName)
+}
+
 func (r *timingDockerRegistry) PullImageArchive(ctx context.Context, archiveWriter io.Writer, reference string) error {
 	defer observeRegistry(ctx, "PullImageArchive")()
 	return r.Interface.PullImageArchive(ctx, archiveWriter, reference)
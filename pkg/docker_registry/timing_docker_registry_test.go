@@ -61,6 +61,14 @@ func (r *fakeRegistry) PullImageArchive(_ context.Context, _ io.Writer, _ string
 	return nil
 }
 
+func (r *fakeRegistry) MutateAndWriteOCIArchive(_ context.Context, _, _, _ string, _ ...registry_api.MutateOption) error {
+	return nil
+}
+
+func (r *fakeRegistry) WriteOCILayout(_ context.Context, _, _, _ string) error {
+	return nil
+}
+
 func (r *fakeRegistry) PushManifestList(_ context.Context, _ string, _ ManifestListOptions) error {
 	return nil
 }
@@ -152,6 +160,12 @@ var _ = Describe("timingDockerRegistry", func() {
 		Entry("PushManifestList", "PushManifestList", func(ctx context.Context, r Interface) error {
 			return r.PushManifestList(ctx, "repo:tag", ManifestListOptions{})
 		}),
+		Entry("MutateAndWriteOCIArchive", "MutateAndWriteOCIArchive", func(ctx context.Context, r Interface) error {
+			return r.MutateAndWriteOCIArchive(ctx, "src", "image.tar", "latest")
+		}),
+		Entry("WriteOCILayout", "WriteOCILayout", func(ctx context.Context, r Interface) error {
+			return r.WriteOCILayout(ctx, "src", "layout", "latest")
+		}),
 	)
 
 	It("records the measurement when the wrapped call fails", func() {
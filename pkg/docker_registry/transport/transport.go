@@ -2,11 +2,13 @@ package transport
 
 import (
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/cenkalti/backoff/v5"
 
 	"github.com/werf/logboek"
+	"github.com/werf/werf/v2/pkg/docker_registry/ratelimit"
 	"github.com/werf/werf/v2/pkg/util/parallel"
 )
 
@@ -20,11 +22,17 @@ func NewTransport(underlying http.RoundTripper) http.RoundTripper {
 
 func (t *transport) RoundTrip(req *http.Request) (*http.Response, error) {
 	operation := func() (*http.Response, error) {
+		if err := ratelimit.DefaultScheduler.Wait(req.Context(), req.URL.Host, requestCost(req)); err != nil {
+			return nil, backoff.Permanent(err)
+		}
+
 		resp, err := t.underlying.RoundTrip(req)
 		if err != nil {
 			return nil, backoff.Permanent(err)
 		}
 
+		ratelimit.DefaultScheduler.ObserveResponse(req.URL.Host, resp)
+
 		return backoffHttpRetryAfterHandler(resp)
 	}
 
@@ -53,3 +61,12 @@ func (t *transport) RoundTrip(req *http.Request) (*http.Response, error) {
 		backoff.WithMaxElapsedTime(5*time.Minute), // Maximum time for all retries.
 		backoff.WithNotify(notify))
 }
+
+// requestCost returns the number of the rate limit tokens taken by the request.
+// The registries (e.g. Docker Hub) count the manifest downloads as pulls.
+func requestCost(req *http.Request) float64 {
+	if req.Method == http.MethodGet && strings.Contains(req.URL.Path, "/manifests/") {
+		return 1
+	}
+	return 0
+}
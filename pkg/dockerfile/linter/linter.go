@@ -0,0 +1,162 @@
+package linter
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+
+	"github.com/moby/buildkit/frontend/dockerfile/instructions"
+	"github.com/moby/buildkit/frontend/dockerfile/parser"
+	"github.com/moby/buildkit/frontend/dockerfile/shell"
+)
+
+type Rule string
+
+const (
+	RuleAptGetNoCleanup  Rule = "apt-get-no-cleanup"
+	RuleAddRemoteURL     Rule = "add-remote-url"
+	RuleCopyMissingChown Rule = "copy-missing-chown"
+	RuleFromLatestTag    Rule = "from-latest-tag"
+	RuleSecretBuildArg   Rule = "secret-build-arg"
+	RuleUnusedStage      Rule = "unused-stage"
+)
+
+// Rules are all the supported rules with their descriptions.
+var Rules = []RuleInfo{
+	{Rule: RuleAptGetNoCleanup, Description: "apt-get install should be followed by the removal of /var/lib/apt/lists or use a cache mount"},
+	{Rule: RuleAddRemoteURL, Description: "ADD of a remote URL should be replaced with COPY of a downloaded file or pinned with --checksum"},
+	{Rule: RuleCopyMissingChown, Description: "COPY and ADD after a non-root USER should set the owner with --chown"},
+	{Rule: RuleFromLatestTag, Description: "base images should be pinned to a specific tag or digest instead of latest"},
+	{Rule: RuleSecretBuildArg, Description: "secrets should be passed with build secrets instead of build args or environment variables"},
+	{Rule: RuleUnusedStage, Description: "stages that are not used to build the target stage should be removed"},
+}
+
+type RuleInfo struct {
+	Rule        Rule
+	Description string
+}
+
+func IsKnownRule(rule string) bool {
+	for _, info := range Rules {
+		if string(info.Rule) == rule {
+			return true
+		}
+	}
+
+	return false
+}
+
+type Issue struct {
+	Rule    Rule   `json:"rule"`
+	Message string `json:"message"`
+	Image   string `json:"image,omitempty"`
+	File    string `json:"file,omitempty"`
+	Line    int    `json:"line,omitempty"`
+}
+
+func (i *Issue) String() string {
+	if i.Line == 0 {
+		return fmt.Sprintf("%s: %s (%s)", i.File, i.Message, i.Rule)
+	}
+	return fmt.Sprintf("%s:%d: %s (%s)", i.File, i.Line, i.Message, i.Rule)
+}
+
+type LintOptions struct {
+	// Image is the werf image name, which is set in the issues.
+	Image string
+	// File is the Dockerfile path, which is set in the issues.
+	File string
+	// Targets are the stages to build, the last stage is used for the empty target or if no targets specified.
+	// All targets of the werf images sharing the Dockerfile should be passed to avoid false unused stages.
+	Targets []string
+	// BuildArgs are used to expand the base image names.
+	BuildArgs map[string]string
+	// Suppress are the rules which are skipped.
+	Suppress []Rule
+}
+
+// Lint checks the Dockerfile for common mistakes and returns the found issues sorted by line.
+func Lint(dockerfileData []byte, opts LintOptions) ([]*Issue, error) {
+	p, err := parser.Parse(bytes.NewReader(dockerfileData))
+	if err != nil {
+		return nil, fmt.Errorf("parsing dockerfile data: %w", err)
+	}
+
+	stages, metaArgs, err := instructions.Parse(p.AST)
+	if err != nil {
+		return nil, fmt.Errorf("parsing instructions tree: %w", err)
+	}
+
+	l := &linter{
+		stages:    stages,
+		metaArgs:  metaArgs,
+		lex:       shell.NewLex(p.EscapeToken),
+		buildArgs: opts.BuildArgs,
+		targets:   opts.Targets,
+	}
+
+	if err := l.expandRunMounts(); err != nil {
+		return nil, err
+	}
+
+	checks := map[Rule]func() ([]*Issue, error){
+		RuleAptGetNoCleanup:  l.checkAptGetNoCleanup,
+		RuleAddRemoteURL:     l.checkAddRemoteURL,
+		RuleCopyMissingChown: l.checkCopyMissingChown,
+		RuleFromLatestTag:    l.checkFromLatestTag,
+		RuleSecretBuildArg:   l.checkSecretBuildArg,
+		RuleUnusedStage:      l.checkUnusedStage,
+	}
+
+	suppressed := make(map[Rule]bool, len(opts.Suppress))
+	for _, rule := range opts.Suppress {
+		suppressed[rule] = true
+	}
+
+	var issues []*Issue
+	for _, info := range Rules {
+		if suppressed[info.Rule] {
+			continue
+		}
+
+		ruleIssues, err := checks[info.Rule]()
+		if err != nil {
+			return nil, fmt.Errorf("rule %s: %w", info.Rule, err)
+		}
+
+		for _, issue := range ruleIssues {
+			issue.Rule = info.Rule
+			issue.Image = opts.Image
+			issue.File = opts.File
+		}
+
+		issues = append(issues, ruleIssues...)
+	}
+
+	sort.SliceStable(issues, func(i, j int) bool {
+		return issues[i].Line < issues[j].Line
+	})
+
+	return issues, nil
+}
+
+// LintBaseImage checks the base image of the image without Dockerfile, e.g. the stapel image from, with the from-latest-tag rule.
+// The issues have no line, because the base image is not specified in the Dockerfile.
+func LintBaseImage(baseImage string, opts LintOptions) []*Issue {
+	for _, rule := range opts.Suppress {
+		if rule == RuleFromLatestTag {
+			return nil
+		}
+	}
+
+	if !isBaseImageUnpinned(baseImage) {
+		return nil
+	}
+
+	return []*Issue{{
+		Rule:    RuleFromLatestTag,
+		Message: fromLatestTagMessage(baseImage),
+		Image:   opts.Image,
+		File:    opts.File,
+	}}
+}
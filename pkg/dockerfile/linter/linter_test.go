@@ -0,0 +1,146 @@
+package linter
+
+import (
+	"fmt"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Lint", func() {
+	lint := func(dockerfile string, opts LintOptions) []*Issue {
+		issues, err := Lint([]byte(dockerfile), opts)
+		Expect(err).NotTo(HaveOccurred())
+		return issues
+	}
+
+	issueLines := func(issues []*Issue, rule Rule) []int {
+		var lines []int
+		for _, issue := range issues {
+			if issue.Rule == rule {
+				lines = append(lines, issue.Line)
+			}
+		}
+		return lines
+	}
+
+	DescribeTable("finds issues",
+		func(rule Rule, dockerfile string, expectedLines []int) {
+			Expect(issueLines(lint(dockerfile, LintOptions{}), rule)).To(Equal(expectedLines))
+		},
+		Entry("apt-get without cleanup", RuleAptGetNoCleanup, `FROM ubuntu:22.04
+RUN apt-get update && apt-get install -y curl
+RUN apt-get update && apt-get install -y git && rm -rf /var/lib/apt/lists/*
+RUN --mount=type=cache,target=/var/cache/apt apt-get update && apt-get -y install make
+RUN apt install -y vim
+`, []int{2, 5}),
+		Entry("ADD of remote URLs", RuleAddRemoteURL, `FROM alpine:3.19
+ADD https://example.com/app.tar.gz /tmp/
+ADD --checksum=sha256:24454f830cdb571e2c4ad15481119c43b3cafd48dd869a9b2945d1036d1dc68d https://example.com/app.tar.gz /tmp/
+ADD https://github.com/werf/werf.git /src
+ADD app.tar.gz /tmp/
+`, []int{2}),
+		Entry("COPY without --chown after non-root USER", RuleCopyMissingChown, `FROM alpine:3.19 AS base
+COPY a /a
+USER app
+COPY b /b
+ADD --chown=app c /c
+
+FROM base
+COPY d /d
+USER root
+COPY e /e
+`, []int{4, 8}),
+		Entry("latest tags", RuleFromLatestTag, `ARG BASE=alpine
+ARG PINNED=alpine:3.19
+FROM ubuntu AS a
+FROM registry.example.com:5000/ubuntu:latest AS b
+FROM registry.example.com:5000/ubuntu AS c
+FROM ubuntu:22.04 AS d
+FROM ubuntu@sha256:0000000000000000000000000000000000000000000000000000000000000000 AS e
+FROM scratch AS f
+FROM a AS g
+FROM $BASE AS h
+FROM ${PINNED} AS i
+`, []int{3, 4, 5, 10}),
+		Entry("secrets in build args and environment", RuleSecretBuildArg, `ARG NPM_TOKEN
+FROM alpine:3.19
+ARG DB_PASSWORD VERSION
+ARG SECRET_FILE=/run/secrets/secret
+ENV API_KEY=123 MODE=prod
+`, []int{1, 3, 5}),
+		Entry("unused stages", RuleUnusedStage, `FROM alpine:3.19 AS base
+FROM alpine:3.19 AS unused
+FROM golang:1.22 AS builder
+FROM alpine:3.19 AS mount
+FROM alpine:3.19 AS other
+FROM base
+COPY --from=builder /app /app
+COPY --from=3 /bin /bin
+RUN --mount=from=mount,target=/mnt true
+`, []int{2, 5}),
+	)
+
+	It("uses the target to find unused stages", func() {
+		dockerfile := `FROM alpine:3.19 AS base
+FROM base AS dev
+FROM base AS prod
+FROM base AS test
+`
+		Expect(issueLines(lint(dockerfile, LintOptions{Targets: []string{"dev"}}), RuleUnusedStage)).To(Equal([]int{3, 4}))
+		Expect(issueLines(lint(dockerfile, LintOptions{Targets: []string{"dev", "prod"}}), RuleUnusedStage)).To(Equal([]int{4}))
+	})
+
+	It("fails if the target does not exist", func() {
+		_, err := Lint([]byte("FROM alpine:3.19\n"), LintOptions{Targets: []string{"prod"}})
+		Expect(err).To(MatchError(ContainSubstring("prod is not a valid target dockerfile stage")))
+	})
+
+	It("expands the base image with the build args", func() {
+		dockerfile := `ARG BASE=alpine:3.19
+FROM $BASE
+`
+		Expect(lint(dockerfile, LintOptions{})).To(BeEmpty())
+		Expect(issueLines(lint(dockerfile, LintOptions{BuildArgs: map[string]string{"BASE": "alpine"}}), RuleFromLatestTag)).To(Equal([]int{2}))
+	})
+
+	It("skips the suppressed rules and sets the issue location", func() {
+		dockerfile := `FROM ubuntu
+RUN apt-get install -y curl
+`
+		issues := lint(dockerfile, LintOptions{Image: "app", File: "app/Dockerfile", Suppress: []Rule{RuleAptGetNoCleanup}})
+		Expect(issues).To(Equal([]*Issue{{
+			Rule:    RuleFromLatestTag,
+			Message: `base image "ubuntu" is not pinned, specify a tag other than latest or a digest`,
+			Image:   "app",
+			File:    "app/Dockerfile",
+			Line:    1,
+		}}))
+	})
+})
+
+var _ = Describe("LintBaseImage", func() {
+	DescribeTable("checks the base image",
+		func(baseImage string, expectIssue bool) {
+			issues := LintBaseImage(baseImage, LintOptions{Image: "app", File: "werf.yaml"})
+			if expectIssue {
+				Expect(issues).To(Equal([]*Issue{{
+					Rule:    RuleFromLatestTag,
+					Message: fmt.Sprintf("base image %q is not pinned, specify a tag other than latest or a digest", baseImage),
+					Image:   "app",
+					File:    "werf.yaml",
+				}}))
+			} else {
+				Expect(issues).To(BeEmpty())
+			}
+		},
+		Entry("no tag", "alpine", true),
+		Entry("latest tag", "registry.example.com:5000/alpine:latest", true),
+		Entry("pinned tag", "registry.example.com:5000/alpine:3.19", false),
+		Entry("digest", "alpine@sha256:c5b1261d6d3e43071626931fc004f70149baeba2c8ec672bd4f27761f8e1ad6b", false),
+	)
+
+	It("skips the suppressed rule", func() {
+		Expect(LintBaseImage("alpine", LintOptions{Suppress: []Rule{RuleFromLatestTag}})).To(BeEmpty())
+	})
+})
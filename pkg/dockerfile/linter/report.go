@@ -0,0 +1,167 @@
+package linter
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+)
+
+type ReportFormat string
+
+const (
+	ReportFormatJSON  ReportFormat = "json"
+	ReportFormatSARIF ReportFormat = "sarif"
+)
+
+const (
+	sarifVersion = "2.1.0"
+	sarifSchema  = "https://json.schemastore.org/sarif-2.1.0.json"
+)
+
+// GetReportFormatByPath returns the report format by the file extension: .json or .sarif.
+func GetReportFormatByPath(path string) (ReportFormat, error) {
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".json":
+		return ReportFormatJSON, nil
+	case ".sarif":
+		return ReportFormatSARIF, nil
+	default:
+		return "", fmt.Errorf("unsupported report extension %q, expected .json or .sarif", ext)
+	}
+}
+
+type WriteReportOptions struct {
+	ToolName    string
+	ToolVersion string
+}
+
+func WriteReport(w io.Writer, issues []*Issue, format ReportFormat, opts WriteReportOptions) error {
+	var report interface{}
+	switch format {
+	case ReportFormatJSON:
+		report = newJSONReport(issues)
+	case ReportFormatSARIF:
+		report = newSARIFReport(issues, opts)
+	default:
+		return fmt.Errorf("unsupported report format %q", format)
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(report); err != nil {
+		return fmt.Errorf("unable to encode %s report: %w", format, err)
+	}
+
+	return nil
+}
+
+type jsonReport struct {
+	Issues []*Issue `json:"issues"`
+}
+
+func newJSONReport(issues []*Issue) *jsonReport {
+	if issues == nil {
+		issues = []*Issue{}
+	}
+	return &jsonReport{Issues: issues}
+}
+
+type sarifReport struct {
+	Version string     `json:"version"`
+	Schema  string     `json:"$schema"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name    string      `json:"name"`
+	Version string      `json:"version,omitempty"`
+	Rules   []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID               string       `json:"id"`
+	ShortDescription sarifMessage `json:"shortDescription"`
+}
+
+type sarifResult struct {
+	RuleID     string            `json:"ruleId"`
+	Level      string            `json:"level"`
+	Message    sarifMessage      `json:"message"`
+	Locations  []sarifLocation   `json:"locations,omitempty"`
+	Properties map[string]string `json:"properties,omitempty"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+	Region           *sarifRegion          `json:"region,omitempty"`
+}
+
+type sarifArtifactLocation struct {
+	URI string `json:"uri"`
+}
+
+type sarifRegion struct {
+	StartLine int `json:"startLine"`
+}
+
+func newSARIFReport(issues []*Issue, opts WriteReportOptions) *sarifReport {
+	driver := sarifDriver{Name: opts.ToolName, Version: opts.ToolVersion}
+	for _, info := range Rules {
+		driver.Rules = append(driver.Rules, sarifRule{
+			ID:               string(info.Rule),
+			ShortDescription: sarifMessage{Text: info.Description},
+		})
+	}
+
+	results := []sarifResult{}
+	for _, issue := range issues {
+		result := sarifResult{
+			RuleID:  string(issue.Rule),
+			Level:   "warning",
+			Message: sarifMessage{Text: issue.Message},
+		}
+
+		if issue.File != "" {
+			location := sarifLocation{
+				PhysicalLocation: sarifPhysicalLocation{
+					ArtifactLocation: sarifArtifactLocation{URI: filepath.ToSlash(issue.File)},
+				},
+			}
+			if issue.Line > 0 {
+				location.PhysicalLocation.Region = &sarifRegion{StartLine: issue.Line}
+			}
+			result.Locations = append(result.Locations, location)
+		}
+
+		if issue.Image != "" {
+			result.Properties = map[string]string{"image": issue.Image}
+		}
+
+		results = append(results, result)
+	}
+
+	return &sarifReport{
+		Version: sarifVersion,
+		Schema:  sarifSchema,
+		Runs:    []sarifRun{{Tool: sarifTool{Driver: driver}, Results: results}},
+	}
+}
@@ -0,0 +1,65 @@
+package linter
+
+import (
+	"bytes"
+	"encoding/json"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("WriteReport", func() {
+	issues := []*Issue{{Rule: RuleFromLatestTag, Message: "message", Image: "app", File: "app/Dockerfile", Line: 3}}
+
+	It("writes the JSON report", func() {
+		var buf bytes.Buffer
+		Expect(WriteReport(&buf, issues, ReportFormatJSON, WriteReportOptions{})).To(Succeed())
+		Expect(buf.String()).To(MatchJSON(`{"issues": [{"rule": "from-latest-tag", "message": "message", "image": "app", "file": "app/Dockerfile", "line": 3}]}`))
+	})
+
+	It("writes the empty JSON report", func() {
+		var buf bytes.Buffer
+		Expect(WriteReport(&buf, nil, ReportFormatJSON, WriteReportOptions{})).To(Succeed())
+		Expect(buf.String()).To(MatchJSON(`{"issues": []}`))
+	})
+
+	It("writes the SARIF report", func() {
+		var buf bytes.Buffer
+		Expect(WriteReport(&buf, issues, ReportFormatSARIF, WriteReportOptions{ToolName: "werf", ToolVersion: "v2"})).To(Succeed())
+
+		var report map[string]interface{}
+		Expect(json.Unmarshal(buf.Bytes(), &report)).To(Succeed())
+		Expect(report["version"]).To(Equal("2.1.0"))
+
+		run := report["runs"].([]interface{})[0].(map[string]interface{})
+		driver := run["tool"].(map[string]interface{})["driver"].(map[string]interface{})
+		Expect(driver["name"]).To(Equal("werf"))
+		Expect(driver["rules"]).To(HaveLen(len(Rules)))
+
+		Expect(run["results"]).To(Equal([]interface{}{map[string]interface{}{
+			"ruleId":  "from-latest-tag",
+			"level":   "warning",
+			"message": map[string]interface{}{"text": "message"},
+			"locations": []interface{}{map[string]interface{}{
+				"physicalLocation": map[string]interface{}{
+					"artifactLocation": map[string]interface{}{"uri": "app/Dockerfile"},
+					"region":           map[string]interface{}{"startLine": float64(3)},
+				},
+			}},
+			"properties": map[string]interface{}{"image": "app"},
+		}}))
+	})
+
+	DescribeTable("detects the report format by the path",
+		func(path string, expected ReportFormat) {
+			Expect(GetReportFormatByPath(path)).To(Equal(expected))
+		},
+		Entry("json", "report.json", ReportFormatJSON),
+		Entry("sarif", "dir/report.SARIF", ReportFormatSARIF),
+	)
+
+	It("fails on the unsupported report path extension", func() {
+		_, err := GetReportFormatByPath("report.txt")
+		Expect(err).To(MatchError(ContainSubstring(`unsupported report extension ".txt"`)))
+	})
+})
@@ -0,0 +1,392 @@
+package linter
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/moby/buildkit/frontend/dockerfile/instructions"
+	"github.com/moby/buildkit/frontend/dockerfile/parser"
+	"github.com/moby/buildkit/frontend/dockerfile/shell"
+)
+
+var (
+	aptGetInstallRegexp   = regexp.MustCompile(`\bapt(-get)?\s+(-\S+\s+)*install\b`)
+	aptListsCleanupRegexp = regexp.MustCompile(`\brm\s+(-\S+\s+)*\S*/var/lib/apt/lists`)
+	secretNameRegexp      = regexp.MustCompile(`(?i)(PASSWORD|PASSWD|SECRET|TOKEN|API_?KEY|PRIVATE_?KEY|ACCESS_?KEY|CREDENTIALS?)`)
+	// Names like TOKEN_FILE or SECRET_PATH point to the secret instead of containing it.
+	secretReferenceNameRegexp = regexp.MustCompile(`(?i)_(FILE|PATH|DIR)$`)
+)
+
+type linter struct {
+	stages    []instructions.Stage
+	metaArgs  []instructions.ArgCommand
+	lex       *shell.Lex
+	buildArgs map[string]string
+	targets   []string
+}
+
+// expandRunMounts parses the RUN --mount flags, which are evaluated by buildkit only on expansion.
+func (l *linter) expandRunMounts() error {
+	env := l.metaArgsEnv()
+	expander := func(word string) (string, error) {
+		return l.lex.ProcessWordWithMap(word, env)
+	}
+
+	for _, stage := range l.stages {
+		for _, cmd := range stage.Commands {
+			if run, ok := cmd.(*instructions.RunCommand); ok {
+				if err := run.Expand(expander); err != nil {
+					return fmt.Errorf("unable to expand RUN at line %d: %w", locationLine(run.Location()), err)
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+func (l *linter) checkAptGetNoCleanup() ([]*Issue, error) {
+	var issues []*Issue
+	for _, stage := range l.stages {
+		for _, cmd := range stage.Commands {
+			run, ok := cmd.(*instructions.RunCommand)
+			if !ok {
+				continue
+			}
+
+			script := strings.Join(run.CmdLine, " ")
+			for _, file := range run.Files {
+				script += "\n" + file.Data
+			}
+
+			if !aptGetInstallRegexp.MatchString(script) || aptListsCleanupRegexp.MatchString(script) || hasAptCacheMount(run) {
+				continue
+			}
+
+			issues = append(issues, &Issue{
+				Message: "apt-get install without removing /var/lib/apt/lists, add `rm -rf /var/lib/apt/lists/*` to the same RUN or use a cache mount",
+				Line:    locationLine(run.Location()),
+			})
+		}
+	}
+
+	return issues, nil
+}
+
+func hasAptCacheMount(run *instructions.RunCommand) bool {
+	for _, mount := range instructions.GetMounts(run) {
+		if mount.Type != instructions.MountTypeCache {
+			continue
+		}
+
+		if strings.HasPrefix(mount.Target, "/var/lib/apt") || strings.HasPrefix(mount.Target, "/var/cache/apt") {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (l *linter) checkAddRemoteURL() ([]*Issue, error) {
+	var issues []*Issue
+	for _, stage := range l.stages {
+		for _, cmd := range stage.Commands {
+			add, ok := cmd.(*instructions.AddCommand)
+			if !ok || add.Checksum != "" {
+				continue
+			}
+
+			for _, src := range add.SourcePaths {
+				if !isHTTPURL(src) || isGitURL(src) {
+					continue
+				}
+
+				issues = append(issues, &Issue{
+					Message: fmt.Sprintf("ADD of the remote URL %q is not verified, use ADD --checksum or download the file with a verification in RUN", src),
+					Line:    locationLine(add.Location()),
+				})
+			}
+		}
+	}
+
+	return issues, nil
+}
+
+func isHTTPURL(src string) bool {
+	src = strings.ToLower(src)
+	return strings.HasPrefix(src, "http://") || strings.HasPrefix(src, "https://")
+}
+
+func isGitURL(src string) bool {
+	path, _, _ := strings.Cut(src, "#")
+	return strings.HasSuffix(path, ".git")
+}
+
+func (l *linter) checkCopyMissingChown() ([]*Issue, error) {
+	var issues []*Issue
+
+	stageUsers := make([]string, len(l.stages))
+	for i, stage := range l.stages {
+		var user string
+		if baseIndex := l.stageIndex(stage.BaseName, i); baseIndex >= 0 {
+			user = stageUsers[baseIndex]
+		}
+
+		for _, cmd := range stage.Commands {
+			var chown string
+			switch c := cmd.(type) {
+			case *instructions.UserCommand:
+				user = c.User
+				continue
+			case *instructions.CopyCommand:
+				chown = c.Chown
+			case *instructions.AddCommand:
+				chown = c.Chown
+			default:
+				continue
+			}
+
+			if chown != "" || !isNonRootUser(user) {
+				continue
+			}
+
+			issues = append(issues, &Issue{
+				Message: fmt.Sprintf("%s without --chown after USER %s, the files are owned by root", strings.ToUpper(cmd.Name()), user),
+				Line:    locationLine(cmd.Location()),
+			})
+		}
+
+		stageUsers[i] = user
+	}
+
+	return issues, nil
+}
+
+func isNonRootUser(user string) bool {
+	name, _, _ := strings.Cut(user, ":")
+	return name != "" && name != "root" && name != "0"
+}
+
+func (l *linter) checkFromLatestTag() ([]*Issue, error) {
+	env := l.metaArgsEnv()
+
+	var issues []*Issue
+	for i, stage := range l.stages {
+		if l.stageIndex(stage.BaseName, i) >= 0 {
+			continue
+		}
+
+		baseName, err := l.lex.ProcessWordWithMap(stage.BaseName, env)
+		if err != nil || baseName == "" || strings.Contains(baseName, "$") {
+			continue
+		}
+
+		if !isBaseImageUnpinned(baseName) {
+			continue
+		}
+
+		issues = append(issues, &Issue{
+			Message: fromLatestTagMessage(baseName),
+			Line:    locationLine(stage.Location),
+		})
+	}
+
+	return issues, nil
+}
+
+// isBaseImageUnpinned returns true if the base image has no tag, the latest tag and no digest.
+func isBaseImageUnpinned(baseName string) bool {
+	if strings.EqualFold(baseName, "scratch") || strings.Contains(baseName, "@") {
+		return false
+	}
+
+	name := baseName[strings.LastIndex(baseName, "/")+1:]
+	_, tag, _ := strings.Cut(name, ":")
+	return tag == "" || tag == "latest"
+}
+
+func fromLatestTagMessage(baseName string) string {
+	return fmt.Sprintf("base image %q is not pinned, specify a tag other than latest or a digest", baseName)
+}
+
+func (l *linter) checkSecretBuildArg() ([]*Issue, error) {
+	var issues []*Issue
+
+	checkArg := func(arg *instructions.ArgCommand) {
+		for _, kv := range arg.Args {
+			if isSecretName(kv.Key) {
+				issues = append(issues, &Issue{
+					Message: fmt.Sprintf("build arg %q looks like a secret, which is saved in the image history, use build secrets instead", kv.Key),
+					Line:    locationLine(arg.Location()),
+				})
+			}
+		}
+	}
+
+	for i := range l.metaArgs {
+		checkArg(&l.metaArgs[i])
+	}
+
+	for _, stage := range l.stages {
+		for _, cmd := range stage.Commands {
+			switch c := cmd.(type) {
+			case *instructions.ArgCommand:
+				checkArg(c)
+			case *instructions.EnvCommand:
+				for _, kv := range c.Env {
+					if isSecretName(kv.Key) {
+						issues = append(issues, &Issue{
+							Message: fmt.Sprintf("environment variable %q looks like a secret, which is saved in the image, use build secrets instead", kv.Key),
+							Line:    locationLine(c.Location()),
+						})
+					}
+				}
+			}
+		}
+	}
+
+	return issues, nil
+}
+
+func isSecretName(name string) bool {
+	return secretNameRegexp.MatchString(name) && !secretReferenceNameRegexp.MatchString(name)
+}
+
+func (l *linter) checkUnusedStage() ([]*Issue, error) {
+	if len(l.stages) == 0 {
+		return nil, nil
+	}
+
+	targets := l.targets
+	if len(targets) == 0 {
+		targets = []string{""}
+	}
+
+	var targetIndexes []int
+	for _, target := range targets {
+		if target == "" {
+			targetIndexes = append(targetIndexes, len(l.stages)-1)
+			continue
+		}
+
+		index := l.stageIndex(target, len(l.stages))
+		if index < 0 {
+			return nil, fmt.Errorf("%s is not a valid target dockerfile stage", target)
+		}
+		targetIndexes = append(targetIndexes, index)
+	}
+
+	env := l.metaArgsEnv()
+	used := make([]bool, len(l.stages))
+
+	var visit func(i int)
+	visit = func(i int) {
+		if used[i] {
+			return
+		}
+		used[i] = true
+
+		for _, ref := range l.stageRefs(i, env) {
+			if index := l.stageIndex(ref, i); index >= 0 {
+				visit(index)
+			}
+		}
+	}
+
+	for _, index := range targetIndexes {
+		visit(index)
+	}
+
+	var issues []*Issue
+	for i, stage := range l.stages {
+		if used[i] {
+			continue
+		}
+
+		issues = append(issues, &Issue{
+			Message: fmt.Sprintf("stage %s is not used to build the target stage", stageDisplayName(stage, i)),
+			Line:    locationLine(stage.Location),
+		})
+	}
+
+	return issues, nil
+}
+
+// stageRefs returns the references of the stage to the other stages or images: the base image, COPY --from and RUN --mount from.
+func (l *linter) stageRefs(i int, env map[string]string) []string {
+	refs := []string{l.stages[i].BaseName}
+
+	for _, cmd := range l.stages[i].Commands {
+		switch c := cmd.(type) {
+		case *instructions.CopyCommand:
+			if c.From != "" {
+				refs = append(refs, c.From)
+			}
+		case *instructions.RunCommand:
+			for _, mount := range instructions.GetMounts(c) {
+				if mount.From != "" {
+					refs = append(refs, mount.From)
+				}
+			}
+		}
+	}
+
+	for j, ref := range refs {
+		if expanded, err := l.lex.ProcessWordWithMap(ref, env); err == nil {
+			refs[j] = expanded
+		}
+	}
+
+	return refs
+}
+
+// stageIndex returns the index of the stage with the specified name or index among the stages before the stage with index
+// before, or -1 if the reference is not a stage.
+func (l *linter) stageIndex(ref string, before int) int {
+	if index, err := strconv.Atoi(ref); err == nil {
+		if index >= 0 && index < before {
+			return index
+		}
+		return -1
+	}
+
+	for i := 0; i < before; i++ {
+		if l.stages[i].Name != "" && strings.EqualFold(l.stages[i].Name, ref) {
+			return i
+		}
+	}
+
+	return -1
+}
+
+// metaArgsEnv returns the values of the ARGs declared before the first FROM.
+func (l *linter) metaArgsEnv() map[string]string {
+	env := map[string]string{}
+	for _, arg := range l.metaArgs {
+		for _, kv := range arg.Args {
+			if value, ok := l.buildArgs[kv.Key]; ok {
+				env[kv.Key] = value
+			} else if kv.Value != nil {
+				env[kv.Key] = *kv.Value
+			}
+		}
+	}
+
+	return env
+}
+
+func stageDisplayName(stage instructions.Stage, index int) string {
+	if stage.Name != "" {
+		return strconv.Quote(stage.Name)
+	}
+	return fmt.Sprintf("#%d", index)
+}
+
+func locationLine(location []parser.Range) int {
+	if len(location) == 0 {
+		return 0
+	}
+	return location[0].Start.Line
+}
@@ -0,0 +1,220 @@
+package follow
+
+import (
+	"fmt"
+	"path"
+	"slices"
+	"strings"
+
+	"github.com/werf/werf/v2/pkg/build/stage"
+	"github.com/werf/werf/v2/pkg/config"
+	"github.com/werf/werf/v2/pkg/path_matcher"
+)
+
+// SyncFile is the changed file, which can be synced into the running containers of the image without rebuilding.
+type SyncFile struct {
+	ImageName string
+	// Path is relative to the git work tree dir. The file should be removed from the container if the path does not exist.
+	Path          string
+	ContainerPath string
+	Owner         string
+	Group         string
+}
+
+type ImageChanges struct {
+	ImageName string
+	// Stages are the first stages to be rebuilt because of the changes.
+	Stages []stage.StageName
+	Paths  []string
+}
+
+type ChangesAnalysis struct {
+	Images    []*ImageChanges
+	SyncFiles []*SyncFile
+	// DependentImages are the images that import files from or depend on the changed images.
+	DependentImages []string
+	// UnmappedPaths are the changed paths that are not used by any image, e.g. werf.yaml, the chart files or docker-compose.yml.
+	UnmappedPaths []string
+}
+
+// SyncOnly returns true when all changes are mapped into the gitLatestPatch stage only,
+// thus the files can be synced into the running containers instead of rebuilding the images.
+func (a *ChangesAnalysis) SyncOnly() bool {
+	if len(a.SyncFiles) == 0 || len(a.UnmappedPaths) > 0 || len(a.DependentImages) > 0 {
+		return false
+	}
+
+	for _, image := range a.Images {
+		for _, stageName := range image.Stages {
+			if stageName != stage.GitLatestPatch {
+				return false
+			}
+		}
+	}
+
+	return true
+}
+
+type AnalyzeChangesOptions struct {
+	// RelativeToGitProjectDir is used to resolve the contexts of the Dockerfile images.
+	RelativeToGitProjectDir string
+}
+
+// AnalyzeChanges maps the changed paths relative to the git work tree dir to the images and the stages,
+// which use these paths through the git mappings, the stage dependencies or the Dockerfile contexts.
+func AnalyzeChanges(werfConfig *config.WerfConfig, paths []string, opts AnalyzeChangesOptions) (*ChangesAnalysis, error) {
+	analysis := &ChangesAnalysis{}
+	imageChangesByName := map[string]*ImageChanges{}
+
+	getImageChanges := func(imageName string) *ImageChanges {
+		if _, ok := imageChangesByName[imageName]; !ok {
+			imageChangesByName[imageName] = &ImageChanges{ImageName: imageName}
+			analysis.Images = append(analysis.Images, imageChangesByName[imageName])
+		}
+		return imageChangesByName[imageName]
+	}
+
+	for _, p := range paths {
+		var mapped bool
+		for _, image := range werfConfig.Images(false) {
+			var stages []stage.StageName
+			var syncFiles []*SyncFile
+
+			switch imageConfig := image.(type) {
+			case config.StapelImageInterface:
+				stages, syncFiles = analyzeStapelImagePath(imageConfig, p)
+			case *config.ImageFromDockerfile:
+				if dockerfileContextPathMatcher(imageConfig, opts.RelativeToGitProjectDir).IsPathMatched(p) {
+					stages = []stage.StageName{stage.Dockerfile}
+				}
+			}
+
+			if len(stages) == 0 {
+				continue
+			}
+			mapped = true
+
+			imageChanges := getImageChanges(image.GetName())
+			imageChanges.Paths = appendUniq(imageChanges.Paths, p)
+			for _, stageName := range stages {
+				imageChanges.Stages = appendUniq(imageChanges.Stages, stageName)
+			}
+			analysis.SyncFiles = append(analysis.SyncFiles, syncFiles...)
+		}
+
+		if !mapped {
+			analysis.UnmappedPaths = append(analysis.UnmappedPaths, p)
+		}
+	}
+
+	if len(analysis.Images) == 0 {
+		return analysis, nil
+	}
+
+	imagesToProcess, err := config.NewImagesToProcess(werfConfig, nil, false, false)
+	if err != nil {
+		return nil, err
+	}
+
+	graphList, err := werfConfig.GetImageGraphList(imagesToProcess)
+	if err != nil {
+		return nil, fmt.Errorf("unable to get images graph: %w", err)
+	}
+
+	for _, graph := range graphList {
+		var relatedImageNames []string
+		if graph.DependsOn.From != "" {
+			relatedImageNames = append(relatedImageNames, graph.DependsOn.From)
+		}
+		relatedImageNames = append(relatedImageNames, graph.DependsOn.Imports...)
+		relatedImageNames = append(relatedImageNames, graph.DependsOn.Dependencies...)
+
+		for _, name := range relatedImageNames {
+			if _, ok := imageChangesByName[name]; ok {
+				analysis.DependentImages = appendUniq(analysis.DependentImages, graph.ImageName)
+				break
+			}
+		}
+	}
+
+	return analysis, nil
+}
+
+func analyzeStapelImagePath(imageConfig config.StapelImageInterface, p string) ([]stage.StageName, []*SyncFile) {
+	imageBaseConfig := imageConfig.ImageBaseConfig()
+	if imageBaseConfig.Git == nil {
+		return nil, nil
+	}
+
+	var stages []stage.StageName
+	var syncFiles []*SyncFile
+	for _, gitLocal := range imageBaseConfig.Git.Local {
+		add := gitLocal.GitMappingAdd()
+		if !gitMappingPathMatcher(gitLocal.GitLocalExport).IsPathMatched(p) {
+			continue
+		}
+
+		var dependentStages []stage.StageName
+		if gitLocal.StageDependencies != nil {
+			for _, dep := range []struct {
+				stageName stage.StageName
+				globs     []string
+			}{
+				{stage.BeforeInstall, gitLocal.StageDependencies.BeforeInstall},
+				{stage.Install, gitLocal.StageDependencies.Install},
+				{stage.BeforeSetup, gitLocal.StageDependencies.BeforeSetup},
+				{stage.Setup, gitLocal.StageDependencies.Setup},
+			} {
+				if len(dep.globs) == 0 {
+					continue
+				}
+
+				if path_matcher.NewPathMatcher(path_matcher.PathMatcherOptions{BasePath: add, IncludeGlobs: dep.globs}).IsPathMatched(p) {
+					dependentStages = append(dependentStages, dep.stageName)
+				}
+			}
+		}
+
+		switch {
+		case len(dependentStages) > 0:
+			stages = append(stages, dependentStages...)
+		case imageConfig.IsGitAfterPatchDisabled():
+			stages = append(stages, stage.GitArchive)
+		default:
+			stages = append(stages, stage.GitLatestPatch)
+			syncFiles = append(syncFiles, &SyncFile{
+				ImageName:     imageConfig.GetName(),
+				Path:          p,
+				ContainerPath: path.Join(gitLocal.GitMappingTo(), strings.TrimPrefix(strings.TrimPrefix(p, add), "/")),
+				Owner:         gitLocal.Owner,
+				Group:         gitLocal.Group,
+			})
+		}
+	}
+
+	return stages, syncFiles
+}
+
+func gitMappingPathMatcher(gitLocal *config.GitLocalExport) path_matcher.PathMatcher {
+	return path_matcher.NewPathMatcher(path_matcher.PathMatcherOptions{
+		BasePath:     gitLocal.GitMappingAdd(),
+		IncludeGlobs: gitLocal.IncludePaths,
+		ExcludeGlobs: gitLocal.ExcludePaths,
+	})
+}
+
+func dockerfileContextPathMatcher(imageConfig *config.ImageFromDockerfile, relativeToGitProjectDir string) path_matcher.PathMatcher {
+	contextPath := path.Join(relativeToGitProjectDir, imageConfig.Context)
+	if contextPath == "." {
+		contextPath = ""
+	}
+
+	return path_matcher.NewPathMatcher(path_matcher.PathMatcherOptions{BasePath: contextPath})
+}
+
+func appendUniq[T comparable](list []T, v T) []T {
+	if slices.Contains(list, v) {
+		return list
+	}
+	return append(list, v)
+}
@@ -0,0 +1,94 @@
+package follow
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/werf/werf/v2/pkg/build/stage"
+	"github.com/werf/werf/v2/pkg/config"
+)
+
+func newStapelImage(name string, gitLocal *config.GitLocalExport) *config.StapelImage {
+	return &config.StapelImage{
+		StapelImageBase: &config.StapelImageBase{
+			Name: name,
+			From: "alpine",
+			Git:  &config.GitManager{Local: []*config.GitLocal{{GitLocalExport: gitLocal}}},
+		},
+	}
+}
+
+func newGitLocalExport(add, to string, stageDependencies *config.StageDependencies) *config.GitLocalExport {
+	return &config.GitLocalExport{
+		GitExportBase: &config.GitExportBase{
+			GitExport:         &config.GitExport{ExportBase: &config.ExportBase{Add: add, To: to}},
+			StageDependencies: stageDependencies,
+		},
+	}
+}
+
+var _ = Describe("AnalyzeChanges", func() {
+	var werfConfig *config.WerfConfig
+
+	BeforeEach(func() {
+		werfConfig = config.NewWerfConfig(&config.Meta{}, []config.ImageInterface{
+			newStapelImage("backend", newGitLocalExport("/backend", "/app", &config.StageDependencies{Install: []string{"go.mod", "go.sum"}})),
+			&config.ImageFromDockerfile{Name: "frontend", Context: "frontend", Dockerfile: "Dockerfile"},
+		})
+	})
+
+	It("should sync files mapped into the gitLatestPatch stage only", func() {
+		analysis, err := AnalyzeChanges(werfConfig, []string{"backend/main.go", "backend/pkg/util.go"}, AnalyzeChangesOptions{})
+		Expect(err).ShouldNot(HaveOccurred())
+
+		Expect(analysis.SyncOnly()).Should(BeTrue())
+		Expect(analysis.Images).Should(HaveLen(1))
+		Expect(analysis.Images[0].ImageName).Should(Equal("backend"))
+		Expect(analysis.Images[0].Stages).Should(Equal([]stage.StageName{stage.GitLatestPatch}))
+		Expect(analysis.SyncFiles).Should(Equal([]*SyncFile{
+			{ImageName: "backend", Path: "backend/main.go", ContainerPath: "/app/main.go"},
+			{ImageName: "backend", Path: "backend/pkg/util.go", ContainerPath: "/app/pkg/util.go"},
+		}))
+	})
+
+	It("should require rebuild for stage dependencies", func() {
+		analysis, err := AnalyzeChanges(werfConfig, []string{"backend/main.go", "backend/go.mod"}, AnalyzeChangesOptions{})
+		Expect(err).ShouldNot(HaveOccurred())
+
+		Expect(analysis.SyncOnly()).Should(BeFalse())
+		Expect(analysis.Images[0].Stages).Should(Equal([]stage.StageName{stage.GitLatestPatch, stage.Install}))
+	})
+
+	It("should require rebuild for Dockerfile context", func() {
+		analysis, err := AnalyzeChanges(werfConfig, []string{"project/frontend/index.js"}, AnalyzeChangesOptions{RelativeToGitProjectDir: "project"})
+		Expect(err).ShouldNot(HaveOccurred())
+
+		Expect(analysis.SyncOnly()).Should(BeFalse())
+		Expect(analysis.Images).Should(HaveLen(1))
+		Expect(analysis.Images[0].ImageName).Should(Equal("frontend"))
+		Expect(analysis.Images[0].Stages).Should(Equal([]stage.StageName{stage.Dockerfile}))
+	})
+
+	It("should require rerun for unmapped paths", func() {
+		analysis, err := AnalyzeChanges(werfConfig, []string{"backend/main.go", "werf.yaml"}, AnalyzeChangesOptions{})
+		Expect(err).ShouldNot(HaveOccurred())
+
+		Expect(analysis.SyncOnly()).Should(BeFalse())
+		Expect(analysis.UnmappedPaths).Should(Equal([]string{"werf.yaml"}))
+	})
+
+	It("should require rebuild for images depending on the changed image", func() {
+		worker := newStapelImage("worker", newGitLocalExport("/worker", "/app", nil))
+		worker.Dependencies = []*config.Dependency{{ImageName: "backend"}}
+		werfConfig = config.NewWerfConfig(&config.Meta{}, []config.ImageInterface{
+			newStapelImage("backend", newGitLocalExport("/backend", "/app", nil)),
+			worker,
+		})
+
+		analysis, err := AnalyzeChanges(werfConfig, []string{"backend/main.go"}, AnalyzeChangesOptions{})
+		Expect(err).ShouldNot(HaveOccurred())
+
+		Expect(analysis.SyncOnly()).Should(BeFalse())
+		Expect(analysis.DependentImages).Should(Equal([]string{"worker"}))
+	})
+})
@@ -0,0 +1,92 @@
+package follow
+
+import (
+	"archive/tar"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/werf/common-go/pkg/util"
+)
+
+// WriteSyncFilesArchive writes the existing files into the tar archive, which should be extracted into the container root,
+// and returns the commands to run in the container after that to remove the deleted files and to set the ownership.
+func WriteSyncFilesArchive(w io.Writer, workTreeDir string, files []*SyncFile) ([][]string, error) {
+	tw := tar.NewWriter(w)
+
+	var removedPaths []string
+	var syncedFiles []*SyncFile
+	for _, file := range files {
+		sourcePath := filepath.Join(workTreeDir, filepath.FromSlash(file.Path))
+
+		stat, err := os.Lstat(sourcePath)
+		switch {
+		case os.IsNotExist(err):
+			removedPaths = append(removedPaths, file.ContainerPath)
+			continue
+		case err != nil:
+			return nil, fmt.Errorf("unable to stat %q: %w", sourcePath, err)
+		case stat.IsDir():
+			continue
+		}
+
+		if err := util.CopyFileIntoTar(tw, strings.TrimPrefix(file.ContainerPath, "/"), sourcePath); err != nil {
+			return nil, err
+		}
+		syncedFiles = append(syncedFiles, file)
+	}
+
+	if err := tw.Close(); err != nil {
+		return nil, fmt.Errorf("unable to close tar writer: %w", err)
+	}
+
+	var commands [][]string
+	if len(removedPaths) > 0 {
+		commands = append(commands, append([]string{"rm", "-rf"}, removedPaths...))
+	}
+
+	return append(commands, chownCommands(syncedFiles)...), nil
+}
+
+// chownCommands returns the commands to set the owner and the group of the synced files, as the git mappings do.
+func chownCommands(files []*SyncFile) [][]string {
+	pathsByOwnership := map[string][]string{}
+	for _, file := range files {
+		if file.Owner == "" && file.Group == "" {
+			continue
+		}
+
+		ownership := file.Owner
+		if file.Group != "" {
+			ownership += ":" + file.Group
+		}
+
+		pathsByOwnership[ownership] = append(pathsByOwnership[ownership], file.ContainerPath)
+	}
+
+	ownerships := make([]string, 0, len(pathsByOwnership))
+	for ownership := range pathsByOwnership {
+		ownerships = append(ownerships, ownership)
+	}
+	sort.Strings(ownerships)
+
+	var commands [][]string
+	for _, ownership := range ownerships {
+		commands = append(commands, append([]string{"chown", ownership}, pathsByOwnership[ownership]...))
+	}
+
+	return commands
+}
+
+// GroupSyncFilesByImage returns the files grouped by the image name.
+func GroupSyncFilesByImage(files []*SyncFile) map[string][]*SyncFile {
+	filesByImage := map[string][]*SyncFile{}
+	for _, file := range files {
+		filesByImage[file.ImageName] = append(filesByImage[file.ImageName], file)
+	}
+
+	return filesByImage
+}
@@ -0,0 +1,44 @@
+package follow
+
+import (
+	"archive/tar"
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("WriteSyncFilesArchive", func() {
+	It("should archive existing files and remove deleted ones", func() {
+		workTreeDir := GinkgoT().TempDir()
+		Expect(os.MkdirAll(filepath.Join(workTreeDir, "backend", "pkg"), os.ModePerm)).To(Succeed())
+		Expect(os.WriteFile(filepath.Join(workTreeDir, "backend", "pkg", "util.go"), []byte("package pkg"), 0o644)).To(Succeed())
+
+		archive := bytes.NewBuffer(nil)
+		commands, err := WriteSyncFilesArchive(archive, workTreeDir, []*SyncFile{
+			{ImageName: "backend", Path: "backend/pkg/util.go", ContainerPath: "/app/pkg/util.go", Owner: "app", Group: "app"},
+			{ImageName: "backend", Path: "backend/main.go", ContainerPath: "/app/main.go", Owner: "app"},
+		})
+		Expect(err).ShouldNot(HaveOccurred())
+
+		Expect(commands).Should(Equal([][]string{
+			{"rm", "-rf", "/app/main.go"},
+			{"chown", "app:app", "/app/pkg/util.go"},
+		}))
+
+		tr := tar.NewReader(archive)
+		hdr, err := tr.Next()
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(hdr.Name).Should(Equal("app/pkg/util.go"))
+
+		data, err := io.ReadAll(tr)
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(string(data)).Should(Equal("package pkg"))
+
+		_, err = tr.Next()
+		Expect(err).Should(Equal(io.EOF))
+	})
+})
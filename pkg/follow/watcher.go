@@ -0,0 +1,329 @@
+package follow
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+
+	"github.com/werf/logboek"
+	"github.com/werf/werf/v2/pkg/true_git"
+)
+
+// debounceInterval is the time to wait for the subsequent events, so that the whole editor save or git checkout is reported at once.
+const debounceInterval = 200 * time.Millisecond
+
+type Changes struct {
+	// Paths are the changed work tree paths relative to the work tree dir, ignored by git paths are skipped.
+	Paths []string
+	// GitChanged is set when HEAD or refs of the repository are changed, e.g. after commit, checkout or pull.
+	GitChanged bool
+}
+
+type NewWatcherOptions struct {
+	WorkTreeDir string
+	GitDir      string
+}
+
+// Watcher watches the git work tree and the git dir with inotify (or the platform alternative) and reports the changes in batches.
+type Watcher struct {
+	workTreeDir string
+	gitDir      string
+
+	fsWatcher *fsnotify.Watcher
+	changes   chan *Changes
+}
+
+func NewWatcher(ctx context.Context, opts NewWatcherOptions) (*Watcher, error) {
+	fsWatcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, fmt.Errorf("unable to create fs watcher: %w", err)
+	}
+
+	w := &Watcher{
+		workTreeDir: opts.WorkTreeDir,
+		gitDir:      opts.GitDir,
+		fsWatcher:   fsWatcher,
+		changes:     make(chan *Changes),
+	}
+
+	if err := w.addWorkTreeDir(ctx, w.workTreeDir); err != nil {
+		fsWatcher.Close()
+		return nil, fmt.Errorf("unable to watch work tree dir %q: %w", w.workTreeDir, err)
+	}
+
+	if err := w.addGitDir(); err != nil {
+		fsWatcher.Close()
+		return nil, fmt.Errorf("unable to watch git dir %q: %w", w.gitDir, err)
+	}
+
+	go w.run(ctx)
+
+	return w, nil
+}
+
+// Changes returns the channel with the batches of changes. The channel is closed when the watcher is closed.
+func (w *Watcher) Changes() <-chan *Changes {
+	return w.changes
+}
+
+func (w *Watcher) Close() error {
+	return w.fsWatcher.Close()
+}
+
+func (w *Watcher) run(ctx context.Context) {
+	defer close(w.changes)
+
+	for {
+		batch, ok := w.collectBatch(ctx)
+		if !ok {
+			return
+		}
+
+		changes, err := w.processBatch(ctx, batch)
+		if err != nil {
+			logboek.Context(ctx).Warn().LogF("WARNING: unable to process file system events: %s\n", err)
+			changes = &Changes{GitChanged: true}
+		}
+
+		if len(changes.Paths) == 0 && !changes.GitChanged {
+			continue
+		}
+
+		select {
+		case w.changes <- changes:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// collectBatch blocks until the first event and then collects the events until there are no new ones for the debounceInterval.
+func (w *Watcher) collectBatch(ctx context.Context) ([]fsnotify.Event, bool) {
+	var batch []fsnotify.Event
+	var debounce <-chan time.Time
+
+	for {
+		select {
+		case event, ok := <-w.fsWatcher.Events:
+			if !ok {
+				return nil, false
+			}
+
+			if event.Op == fsnotify.Chmod {
+				continue
+			}
+
+			batch = append(batch, event)
+			debounce = time.After(debounceInterval)
+		case err, ok := <-w.fsWatcher.Errors:
+			if !ok {
+				return nil, false
+			}
+
+			logboek.Context(ctx).Warn().LogF("WARNING: file system watcher error: %s\n", err)
+		case <-debounce:
+			return batch, true
+		case <-ctx.Done():
+			return nil, false
+		}
+	}
+}
+
+func (w *Watcher) processBatch(ctx context.Context, batch []fsnotify.Event) (*Changes, error) {
+	changes := &Changes{}
+	pathsSet := map[string]bool{}
+
+	for _, event := range batch {
+		if isSubPath(w.gitDir, event.Name) {
+			if w.isGitStateFile(event.Name) {
+				changes.GitChanged = true
+			}
+
+			if event.Has(fsnotify.Create) && isDir(event.Name) {
+				if err := w.addDirRecursive(event.Name); err != nil {
+					return nil, err
+				}
+			}
+
+			continue
+		}
+
+		relPath, err := filepath.Rel(w.workTreeDir, event.Name)
+		if err != nil || strings.HasPrefix(relPath, "..") {
+			continue
+		}
+		relPath = filepath.ToSlash(relPath)
+
+		if event.Has(fsnotify.Create) && isDir(event.Name) {
+			if err := w.addWorkTreeDir(ctx, event.Name); err != nil {
+				return nil, err
+			}
+
+			// The files could be created before the watch is added.
+			if err := filepath.WalkDir(event.Name, func(p string, d fs.DirEntry, err error) error {
+				if err != nil || d.IsDir() {
+					return nil
+				}
+
+				if rel, err := filepath.Rel(w.workTreeDir, p); err == nil {
+					pathsSet[filepath.ToSlash(rel)] = true
+				}
+
+				return nil
+			}); err != nil {
+				return nil, fmt.Errorf("unable to walk dir %q: %w", event.Name, err)
+			}
+
+			continue
+		}
+
+		pathsSet[relPath] = true
+	}
+
+	paths := make([]string, 0, len(pathsSet))
+	for p := range pathsSet {
+		paths = append(paths, p)
+	}
+
+	ignoredPaths, err := true_git.CheckIgnore(ctx, w.workTreeDir, paths)
+	if err != nil {
+		return nil, err
+	}
+
+	ignoredPathsSet := map[string]bool{}
+	for _, p := range ignoredPaths {
+		ignoredPathsSet[p] = true
+	}
+
+	for _, p := range paths {
+		if !ignoredPathsSet[p] {
+			changes.Paths = append(changes.Paths, p)
+		}
+	}
+	sort.Strings(changes.Paths)
+
+	return changes, nil
+}
+
+// addWorkTreeDir watches the dir and all its subdirs except the git dir and the dirs ignored by git, e.g. node_modules.
+func (w *Watcher) addWorkTreeDir(ctx context.Context, dir string) error {
+	queue := []string{dir}
+	for len(queue) > 0 {
+		var subDirs []string
+		for _, d := range queue {
+			if err := w.fsWatcher.Add(d); err != nil {
+				return fmt.Errorf("unable to watch dir %q: %w", d, err)
+			}
+
+			entries, err := os.ReadDir(d)
+			if err != nil {
+				if os.IsNotExist(err) {
+					continue
+				}
+				return fmt.Errorf("unable to read dir %q: %w", d, err)
+			}
+
+			for _, entry := range entries {
+				subDir := filepath.Join(d, entry.Name())
+				if entry.IsDir() && entry.Name() != ".git" && !isSubPath(w.gitDir, subDir) {
+					subDirs = append(subDirs, subDir)
+				}
+			}
+		}
+
+		relSubDirs := make([]string, 0, len(subDirs))
+		for _, d := range subDirs {
+			rel, err := filepath.Rel(w.workTreeDir, d)
+			if err != nil {
+				return fmt.Errorf("unable to get relative path of %q: %w", d, err)
+			}
+			relSubDirs = append(relSubDirs, filepath.ToSlash(rel)+"/")
+		}
+
+		ignoredDirs, err := true_git.CheckIgnore(ctx, w.workTreeDir, relSubDirs)
+		if err != nil {
+			return err
+		}
+
+		ignoredDirsSet := map[string]bool{}
+		for _, d := range ignoredDirs {
+			ignoredDirsSet[d] = true
+		}
+
+		queue = nil
+		for i, d := range subDirs {
+			if !ignoredDirsSet[relSubDirs[i]] {
+				queue = append(queue, d)
+			}
+		}
+	}
+
+	return nil
+}
+
+// addGitDir watches HEAD, packed-refs and refs of the repository.
+func (w *Watcher) addGitDir() error {
+	if err := w.fsWatcher.Add(w.gitDir); err != nil {
+		return fmt.Errorf("unable to watch dir %q: %w", w.gitDir, err)
+	}
+
+	refsDir := filepath.Join(w.gitDir, "refs")
+	if !isDir(refsDir) {
+		return nil
+	}
+
+	return w.addDirRecursive(refsDir)
+}
+
+func (w *Watcher) addDirRecursive(dir string) error {
+	return filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if !d.IsDir() {
+			return nil
+		}
+
+		if err := w.fsWatcher.Add(p); err != nil {
+			return fmt.Errorf("unable to watch dir %q: %w", p, err)
+		}
+
+		return nil
+	})
+}
+
+func (w *Watcher) isGitStateFile(path string) bool {
+	relPath, err := filepath.Rel(w.gitDir, path)
+	if err != nil {
+		return false
+	}
+	relPath = filepath.ToSlash(relPath)
+
+	if strings.HasSuffix(relPath, ".lock") {
+		return false
+	}
+
+	return relPath == "HEAD" || relPath == "packed-refs" || strings.HasPrefix(relPath, "refs/")
+}
+
+func isSubPath(dir, path string) bool {
+	rel, err := filepath.Rel(dir, path)
+	if err != nil {
+		return false
+	}
+
+	return rel == "." || (rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)))
+}
+
+func isDir(path string) bool {
+	stat, err := os.Lstat(path)
+	return err == nil && stat.IsDir()
+}
@@ -62,6 +62,7 @@ type GitRepo interface {
 
 	CreateDetachedMergeCommit(ctx context.Context, fromCommit, toCommit string) (string, error)
 	GetCommitTreeEntry(ctx context.Context, commit, path string) (*ls_tree.LsTreeEntry, error)
+	GetLastPathCommit(ctx context.Context, commit, path string) (*true_git.PathCommit, error)
 	GetMergeCommitParents(ctx context.Context, commit string) ([]string, error)
 	GetOrCreateArchive(ctx context.Context, opts ArchiveOptions) (Archive, error)
 	GetOrCreateChangedPaths(ctx context.Context, fromCommit, toCommit string) ([]true_git.ChangedPath, error)
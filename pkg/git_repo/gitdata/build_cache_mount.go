@@ -0,0 +1,265 @@
+package gitdata
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/werf/common-go/pkg/util"
+	"github.com/werf/common-go/pkg/util/timestamps"
+	"github.com/werf/lockgate"
+	"github.com/werf/logboek"
+	"github.com/werf/werf/v2/pkg/volumeutils"
+	"github.com/werf/werf/v2/pkg/werf"
+)
+
+// Before changing: read the local_cache contract in the package doc of pkg/git_repo/gitdata.
+const BuildCacheMountsCacheVersion = "1"
+
+type BuildCacheMountSharing string
+
+const (
+	BuildCacheMountSharingShared  BuildCacheMountSharing = "shared"
+	BuildCacheMountSharingPrivate BuildCacheMountSharing = "private"
+	BuildCacheMountSharingLocked  BuildCacheMountSharing = "locked"
+)
+
+func GetBuildCacheMountsDir() string {
+	return filepath.Join(werf.GetLocalCacheDir(), "build_cache_mounts", BuildCacheMountsCacheVersion)
+}
+
+type AcquireBuildCacheMountOptions struct {
+	// Sharing is the same as the sharing option of RUN --mount=type=cache: shared by default.
+	Sharing BuildCacheMountSharing
+	// Mode, UID and GID are applied to the newly created cache directory.
+	Mode *os.FileMode
+	UID  *int
+	GID  *int
+}
+
+type BuildCacheMount struct {
+	// Path is the host directory to mount into the container.
+	Path string
+
+	lock lockgate.LockHandle
+}
+
+// AcquireBuildCacheMount returns the host directory for the cache mount with the specified id and locks it according
+// to the sharing mode until Release: the shared mode locks the directory in the shared mode, the locked mode waits for
+// the exclusive lock, and the private mode uses another instance of the directory if the current one is locked.
+func AcquireBuildCacheMount(ctx context.Context, id string, opts AcquireBuildCacheMountOptions) (*BuildCacheMount, error) {
+	idDir := filepath.Join(GetBuildCacheMountsDir(), util.Sha256Hash(id))
+
+	var instanceDir string
+	var lock lockgate.LockHandle
+	switch opts.Sharing {
+	case "", BuildCacheMountSharingShared, BuildCacheMountSharingLocked:
+		instanceDir = filepath.Join(idDir, "0")
+
+		var err error
+		_, lock, err = werf.HostLocker().AcquireLock(ctx, buildCacheMountLockName(instanceDir), lockgate.AcquireOptions{Shared: opts.Sharing != BuildCacheMountSharingLocked})
+		if err != nil {
+			return nil, fmt.Errorf("unable to lock cache mount %q: %w", id, err)
+		}
+	case BuildCacheMountSharingPrivate:
+		for instance := 0; ; instance++ {
+			instanceDir = filepath.Join(idDir, strconv.Itoa(instance))
+
+			acquired, instanceLock, err := werf.HostLocker().AcquireLock(ctx, buildCacheMountLockName(instanceDir), lockgate.AcquireOptions{NonBlocking: true})
+			if err != nil {
+				return nil, fmt.Errorf("unable to lock cache mount %q: %w", id, err)
+			}
+
+			if acquired {
+				lock = instanceLock
+				break
+			}
+		}
+	default:
+		return nil, fmt.Errorf("unsupported cache mount sharing mode %q", opts.Sharing)
+	}
+
+	// The instance dir could be removed by GC before the instance lock has been acquired,
+	// so it is (re)created under the GC lock to not race with the removal of the empty parent dirs.
+	if err := func() error {
+		if gcLock, err := lockGC(ctx, true); err != nil {
+			return err
+		} else {
+			defer werf.HostLocker().ReleaseLock(gcLock)
+		}
+
+		return prepareBuildCacheMountInstanceDir(instanceDir, opts)
+	}(); err != nil {
+		_ = werf.HostLocker().ReleaseLock(lock)
+		return nil, fmt.Errorf("unable to prepare cache mount %q: %w", id, err)
+	}
+
+	return &BuildCacheMount{Path: filepath.Join(instanceDir, "data"), lock: lock}, nil
+}
+
+func (m *BuildCacheMount) Release() error {
+	return werf.HostLocker().ReleaseLock(m.lock)
+}
+
+func prepareBuildCacheMountInstanceDir(instanceDir string, opts AcquireBuildCacheMountOptions) error {
+	dataDir := filepath.Join(instanceDir, "data")
+
+	if _, err := os.Stat(dataDir); os.IsNotExist(err) {
+		if err := os.MkdirAll(instanceDir, os.ModePerm); err != nil {
+			return fmt.Errorf("unable to create dir %q: %w", instanceDir, err)
+		}
+
+		if err := os.Mkdir(dataDir, os.ModePerm); err != nil {
+			return fmt.Errorf("unable to create dir %q: %w", dataDir, err)
+		}
+
+		mode := os.FileMode(0o755)
+		if opts.Mode != nil {
+			mode = *opts.Mode
+		}
+
+		if err := os.Chmod(dataDir, mode); err != nil {
+			return fmt.Errorf("unable to chmod dir %q: %w", dataDir, err)
+		}
+
+		// In the rootless mode the host user is mapped to the root in the container, so the owner can be changed only by the root.
+		if (opts.UID != nil || opts.GID != nil) && os.Geteuid() == 0 {
+			uid, gid := 0, 0
+			if opts.UID != nil {
+				uid = *opts.UID
+			}
+			if opts.GID != nil {
+				gid = *opts.GID
+			}
+
+			if err := os.Lchown(dataDir, uid, gid); err != nil {
+				return fmt.Errorf("unable to chown dir %q: %w", dataDir, err)
+			}
+		}
+	} else if err != nil {
+		return fmt.Errorf("error accessing dir %q: %w", dataDir, err)
+	}
+
+	lastAccessAtPath := filepath.Join(instanceDir, "last_access_at")
+	if err := timestamps.WriteTimestampFile(lastAccessAtPath, time.Now()); err != nil {
+		return fmt.Errorf("unable to update last access timestamp file %q: %w", lastAccessAtPath, err)
+	}
+
+	return nil
+}
+
+func buildCacheMountLockName(instanceDir string) string {
+	return fmt.Sprintf("build_cache_mount.%s_%s", filepath.Base(filepath.Dir(instanceDir)), filepath.Base(instanceDir))
+}
+
+type BuildCacheMountDesc struct {
+	Path          string
+	LastAccessAt  time.Time
+	Size          uint64
+	CacheBasePath string
+}
+
+func (entry *BuildCacheMountDesc) GetPaths() []string {
+	return []string{entry.Path}
+}
+
+func (entry *BuildCacheMountDesc) GetSize() uint64 {
+	return entry.Size
+}
+
+func (entry *BuildCacheMountDesc) GetLastAccessAt() time.Time {
+	return entry.LastAccessAt
+}
+
+func (entry *BuildCacheMountDesc) GetCacheBasePath() string {
+	return entry.CacheBasePath
+}
+
+// TryLock locks the cache mount instance to remove it, the instance is used by a build if the lock is not acquired.
+func (entry *BuildCacheMountDesc) TryLock(ctx context.Context) (bool, lockgate.LockHandle, error) {
+	return werf.HostLocker().AcquireLock(ctx, buildCacheMountLockName(entry.Path), lockgate.AcquireOptions{NonBlocking: true})
+}
+
+// GetBuildCacheMountsAndRemoveInvalid scans the given cacheVersionRoot directory and returns
+// a list of BuildCacheMountDesc for each valid cache mount instance found. It removes invalid
+// entries and handles errors appropriately.
+//
+// The directory structure expected is as follows:
+// ├── <id_hash>/
+// │   ├── 0/
+// │   │   ├── data/
+// │   │   │   └── ... (cache files)
+// │   │   └── last_access_at
+// │   └── ... (other instances of the private cache mount)
+// └── ... (other cache mount ids)
+func GetBuildCacheMountsAndRemoveInvalid(ctx context.Context, cacheVersionRoot string) ([]GitDataEntry, error) {
+	var res []GitDataEntry
+
+	if _, err := os.Stat(cacheVersionRoot); os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("error accessing dir %q: %w", cacheVersionRoot, err)
+	}
+
+	idDirs, err := ioutil.ReadDir(cacheVersionRoot)
+	if err != nil {
+		return nil, fmt.Errorf("error reading dir %q: %w", cacheVersionRoot, err)
+	}
+
+	for _, idDirInfo := range idDirs {
+		idDir := filepath.Join(cacheVersionRoot, idDirInfo.Name())
+
+		if !idDirInfo.IsDir() {
+			logboek.Context(ctx).Warn().LogF("Removing invalid entry %q: not a directory\n", idDir)
+			if err := os.RemoveAll(idDir); err != nil {
+				return nil, fmt.Errorf("unable to remove %q: %w", idDir, err)
+			}
+			continue
+		}
+
+		instanceDirs, err := ioutil.ReadDir(idDir)
+		if err != nil {
+			return nil, fmt.Errorf("error reading dir %q: %w", idDir, err)
+		}
+
+		for _, instanceDirInfo := range instanceDirs {
+			instanceDir := filepath.Join(idDir, instanceDirInfo.Name())
+
+			if !instanceDirInfo.IsDir() {
+				logboek.Context(ctx).Warn().LogF("Removing invalid entry %q: not a directory\n", instanceDir)
+				if err := os.RemoveAll(instanceDir); err != nil {
+					return nil, fmt.Errorf("unable to remove %q: %w", instanceDir, err)
+				}
+				continue
+			}
+
+			lastAccessAtPath := filepath.Join(instanceDir, "last_access_at")
+			lastAccessAt, err := timestamps.ReadTimestampFile(lastAccessAtPath)
+			if err != nil {
+				logboek.Context(ctx).Warn().LogF("Removing invalid entry %q: unable to read last access timestamp file %q: %s\n", instanceDir, lastAccessAtPath, err)
+				if err := os.RemoveAll(instanceDir); err != nil {
+					return nil, fmt.Errorf("unable to remove %q: %w", instanceDir, err)
+				}
+				continue
+			}
+
+			size, err := volumeutils.DirSizeBytes(instanceDir)
+			if err != nil {
+				return nil, fmt.Errorf("error getting dir %q size: %w", instanceDir, err)
+			}
+
+			res = append(res, &BuildCacheMountDesc{
+				Path:          instanceDir,
+				Size:          size,
+				LastAccessAt:  lastAccessAt,
+				CacheBasePath: cacheVersionRoot,
+			})
+		}
+	}
+
+	return res, nil
+}
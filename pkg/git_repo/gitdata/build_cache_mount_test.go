@@ -0,0 +1,73 @@
+package gitdata
+
+import (
+	"os"
+	"path/filepath"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/werf/common-go/pkg/util/timestamps"
+)
+
+var _ = Describe("build cache mounts", func() {
+	var root string
+
+	BeforeEach(func() {
+		root = GinkgoT().TempDir()
+	})
+
+	It("creates the cache mount instance dir with the specified mode", func() {
+		instanceDir := filepath.Join(root, "id", "0")
+		mode := os.FileMode(0o700)
+
+		Expect(prepareBuildCacheMountInstanceDir(instanceDir, AcquireBuildCacheMountOptions{Mode: &mode})).To(Succeed())
+
+		info, err := os.Stat(filepath.Join(instanceDir, "data"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(info.IsDir()).To(BeTrue())
+		Expect(info.Mode().Perm()).To(Equal(mode))
+
+		lastAccessAt, err := timestamps.ReadTimestampFile(filepath.Join(instanceDir, "last_access_at"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(lastAccessAt).To(BeTemporally("~", time.Now(), time.Minute))
+	})
+
+	It("keeps the data of the existing cache mount instance", func() {
+		instanceDir := filepath.Join(root, "id", "0")
+		Expect(prepareBuildCacheMountInstanceDir(instanceDir, AcquireBuildCacheMountOptions{})).To(Succeed())
+
+		cachedFile := filepath.Join(instanceDir, "data", "cached")
+		Expect(os.WriteFile(cachedFile, []byte("x"), 0o644)).To(Succeed())
+
+		Expect(prepareBuildCacheMountInstanceDir(instanceDir, AcquireBuildCacheMountOptions{})).To(Succeed())
+		Expect(cachedFile).To(BeAnExistingFile())
+	})
+
+	It("returns the cache mount instances and removes the invalid entries", func(ctx SpecContext) {
+		validInstanceDir := filepath.Join(root, "id", "1")
+		Expect(prepareBuildCacheMountInstanceDir(validInstanceDir, AcquireBuildCacheMountOptions{})).To(Succeed())
+
+		invalidInstanceEntry := filepath.Join(root, "id", "0")
+		Expect(os.WriteFile(invalidInstanceEntry, []byte("x"), 0o644)).To(Succeed())
+
+		invalidIDEntry := filepath.Join(root, "file")
+		Expect(os.WriteFile(invalidIDEntry, []byte("x"), 0o644)).To(Succeed())
+
+		entries, err := GetBuildCacheMountsAndRemoveInvalid(ctx, root)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(entries).To(HaveLen(1))
+		Expect(entries[0].GetPaths()).To(Equal([]string{validInstanceDir}))
+		Expect(entries[0].GetCacheBasePath()).To(Equal(root))
+
+		Expect(invalidInstanceEntry).NotTo(BeAnExistingFile())
+		Expect(invalidIDEntry).NotTo(BeAnExistingFile())
+	})
+
+	It("returns nothing if the cache root does not exist", func(ctx SpecContext) {
+		entries, err := GetBuildCacheMountsAndRemoveInvalid(ctx, filepath.Join(root, "missing"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(entries).To(BeEmpty())
+	})
+})
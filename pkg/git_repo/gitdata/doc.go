@@ -1,7 +1,7 @@
-// Package gitdata manages garbage collection of werf's host-local git data
-// under $WERF_HOME/local_cache. It is the only package that performs
-// selective GC of local_cache data; every rule below exists because that
-// directory is shared.
+// Package gitdata manages garbage collection of werf's host-local git data,
+// build cache mounts and image caches under $WERF_HOME/local_cache. It is the only package
+// that performs selective GC of local_cache data; every rule below exists
+// because that directory is shared.
 //
 // # Base invariant
 //
@@ -21,13 +21,17 @@
 //	git_worktrees/<v>  version: git_repo.GitWorktreesCacheVersion  cleaned: wipeCacheDirs + LRU
 //	git_archives/<v>   version: GitArchivesCacheVersion            cleaned: wipeCacheDirs + LRU
 //	git_patches/<v>    version: GitPatchesCacheVersion             cleaned: wipeCacheDirs + LRU
-//	manifests/<v>      version: image.ManifestCacheVersion         cleaned: never
-//	lru_images/<v>     version: lrumeta.LRUImagesCacheVersion      cleaned: never
+//	build_cache_mounts/<v>  version: BuildCacheMountsCacheVersion  cleaned: wipeCacheDirs + LRU
+//	manifests/<v>      version: image.ManifestCacheVersion         cleaned: wipeCacheDirs + age + LRU
+//	lru_images/<v>     version: lrumeta.LRUImagesCacheVersion      cleaned: wipeCacheDirs + LRU
 //	helm_chart_dependencies/<v>  version: nelm chart loader          cleaned: never
 //
-// Nothing cleans manifests, lru_images and helm_chart_dependencies (the last
-// is owned by the nelm chart loader via loader.SetLocalCacheDir): a version
-// bump there leaks the old data forever but destroys nothing. The whole
+// The records of manifests not accessed for imageCacheRecordMaxAge are
+// removed on every GC regardless of the volume usage. The records of
+// lru_images are not removed by age: the local backend GC orders the images
+// by them. Nothing cleans helm_chart_dependencies (owned by the nelm chart
+// loader via loader.SetLocalCacheDir): a version bump there leaks the old
+// data forever but destroys nothing. The whole
 // local_cache is removed only by `werf host purge`.
 //
 // # Bumping a cache version
@@ -54,7 +58,8 @@
 //     immediately (see wipeCacheDirs for the window).
 //   - Never read or write inside another version's namespace.
 //   - Never put a non-directory directly into a version root: collectors
-//     remove such entries as invalid.
+//     remove such entries as invalid (lru_images predates the rule, see
+//     below).
 //
 // # Load-bearing artifacts (backward compatibility)
 //
@@ -101,6 +106,26 @@
 // same plus <id>.patch and <id>.patch.<hash>.paths_list; see the doc comments
 // of GetGitArchivesAndRemoveInvalid and GetGitPatchesAndRemoveInvalid.
 //
+// build_cache_mounts/<v>/<idHash>/<instance> holds data/, which is mounted
+// into the RUN --mount=type=cache of the staged Dockerfile builds, and
+// last_access_at. <idHash> is util.Sha256Hash of the cache id, <instance> is
+// 0 for the shared and locked modes, the private mode takes the first
+// instance which is not locked. An instance is used by a build outside of the
+// git_data_manager lock while its build_cache_mount.<idHash>_<instance> lock
+// is held, so the collector removes only instances it manages to lock; the
+// dirs are (re)created under the shared git_data_manager lock.
+//
+// manifests/<v>/<storageSlug>/<hash> and lru_images/<v>/<hash> are JSON
+// records (image.ManifestCacheRecord, lrumeta.LRUImagesCacheRecord) rewritten
+// in place on every access, so the file mtime is the last access time. The
+// records are used outside of the git_data_manager lock under the
+// manifest_cache.<storageSlug>.<imageName> and lru_images_cache.<imageRef>
+// locks, whose names the collector reads from the record itself; a record
+// is removed only if the lock is acquired and the mtime has not changed
+// since the scan. A removed record is a cache miss for every version. Unlike
+// the other roots, lru_images keeps non-directories directly in its version
+// root: its collector removes directories there instead.
+//
 // Additive changes are safe exactly as far as other versions' collectors and
 // readers tolerate them, and tolerance differs per root: the flat git_repos
 // collector turns any extra directory in its version root into an LRU entry
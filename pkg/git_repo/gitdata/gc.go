@@ -19,7 +19,10 @@ import (
 	"github.com/werf/kubedog/pkg/utils"
 	"github.com/werf/lockgate"
 	"github.com/werf/logboek"
+	"github.com/werf/werf/v2/pkg/cleanup_report"
 	"github.com/werf/werf/v2/pkg/git_repo"
+	"github.com/werf/werf/v2/pkg/image"
+	"github.com/werf/werf/v2/pkg/storage/lrumeta"
 	"github.com/werf/werf/v2/pkg/volumeutils"
 	"github.com/werf/werf/v2/pkg/werf"
 )
@@ -41,6 +44,9 @@ type RunGCOptions struct {
 	AllowedLocalCacheVolumeUsageBytes       uint64
 	AllowedLocalCacheVolumeUsageMarginBytes uint64
 	DryRun                                  bool
+
+	// Report collects the space freed by the removal of the manifest cache and LRU images cache records.
+	Report *cleanup_report.HostReport
 }
 
 func RunGC(ctx context.Context, options RunGCOptions) error {
@@ -85,6 +91,67 @@ func RunGC(ctx context.Context, options RunGCOptions) error {
 		}
 	}
 
+	{
+		cacheRoot := filepath.Join(werf.GetLocalCacheDir(), "build_cache_mounts")
+		if err := wipeCacheDirs(ctx, cacheRoot, []string{BuildCacheMountsCacheVersion}); err != nil {
+			return fmt.Errorf("unable to wipe old build cache mounts dirs in %q: %w", cacheRoot, err)
+		}
+	}
+
+	{
+		cacheRoot := filepath.Join(werf.GetLocalCacheDir(), "manifests")
+		if err := wipeCacheDirs(ctx, cacheRoot, []string{image.ManifestCacheVersion}); err != nil {
+			return fmt.Errorf("unable to wipe old manifest cache dirs in %q: %w", cacheRoot, err)
+		}
+	}
+
+	{
+		cacheRoot := filepath.Join(werf.GetLocalCacheDir(), "lru_images")
+		if err := wipeCacheDirs(ctx, cacheRoot, []string{lrumeta.LRUImagesCacheVersion}); err != nil {
+			return fmt.Errorf("unable to wipe old LRU images cache dirs in %q: %w", cacheRoot, err)
+		}
+	}
+
+	var imageCacheRecords []GitDataEntry
+
+	imageCacheFreedBytes := map[string]uint64{}
+	defer func() {
+		for _, step := range []string{manifestCacheReportStep, lruImagesCacheReportStep} {
+			options.Report.AddSpaceReclaimed(ctx, step, imageCacheFreedBytes[step])
+		}
+	}()
+
+	{
+		cacheVersionRoot := image.GetManifestCacheDir()
+
+		entries, err := GetManifestCacheRecordsAndRemoveInvalid(ctx, cacheVersionRoot)
+		if err != nil {
+			return fmt.Errorf("unable to process manifest cache records from %q: %w", cacheVersionRoot, err)
+		}
+
+		// The manifest cache records are removed by age regardless of the volume usage, because the number of the
+		// records grows faster than the space they take.
+		entries, err = removeExpiredImageCacheRecords(ctx, entries, imageCacheFreedBytes, options.DryRun)
+		if err != nil {
+			return fmt.Errorf("unable to remove expired manifest cache records: %w", err)
+		}
+
+		imageCacheRecords = append(imageCacheRecords, entries...)
+	}
+
+	{
+		cacheVersionRoot := lrumeta.GetLRUImagesCacheDir()
+
+		// The LRU images cache records are not removed by age: the local backend GC, which runs after this GC, orders
+		// the images by the last access time of the records.
+		entries, err := GetLRUImagesCacheRecordsAndRemoveInvalid(ctx, cacheVersionRoot)
+		if err != nil {
+			return fmt.Errorf("unable to process LRU images cache records from %q: %w", cacheVersionRoot, err)
+		}
+
+		imageCacheRecords = append(imageCacheRecords, entries...)
+	}
+
 	vu, err := volumeutils.GetVolumeUsageByPath(ctx, werf.GetLocalCacheDir())
 	if err != nil {
 		return fmt.Errorf("error getting volume usage by path %q: %w", werf.GetLocalCacheDir(), err)
@@ -168,25 +235,39 @@ func RunGC(ctx context.Context, options RunGCOptions) error {
 		gitDataEntries = append(gitDataEntries, entries...)
 	}
 
+	{
+		cacheVersionRoot := GetBuildCacheMountsDir()
+
+		entries, err := GetBuildCacheMountsAndRemoveInvalid(ctx, cacheVersionRoot)
+		if err != nil {
+			return fmt.Errorf("unable to process build cache mounts from %q: %w", cacheVersionRoot, err)
+		}
+
+		gitDataEntries = append(gitDataEntries, entries...)
+	}
+
+	gitDataEntries = append(gitDataEntries, imageCacheRecords...)
+
 	gitDataEntries = keepGitDataByLru(gitDataEntries)
 
 	var freedBytes uint64
 
 	for _, entry := range gitDataEntries {
-		for _, path := range entry.GetPaths() {
-			logboek.Context(ctx).LogF("Removing %q inside scope %q\n", path, entry.GetCacheBasePath())
-
-			if options.DryRun {
-				continue
-			}
+		removed, err := removeGitDataEntry(ctx, entry, options.DryRun)
+		if err != nil {
+			return err
+		}
 
-			if err := RemovePathWithEmptyParentDirsInsideScope(entry.GetCacheBasePath(), path); err != nil {
-				return fmt.Errorf("unable to remove %q: %w", path, err)
-			}
+		if !removed {
+			continue
 		}
 
 		freedBytes += entry.GetSize()
 
+		if record, ok := entry.(*ImageCacheRecordDesc); ok {
+			imageCacheFreedBytes[record.reportStep] += entry.GetSize()
+		}
+
 		if freedBytes >= bytesToFree {
 			break
 		}
@@ -195,6 +276,42 @@ func RunGC(ctx context.Context, options RunGCOptions) error {
 	return nil
 }
 
+func removeGitDataEntry(ctx context.Context, entry GitDataEntry, dryRun bool) (bool, error) {
+	if lockableEntry, ok := entry.(LockableGitDataEntry); ok {
+		acquired, lock, err := lockableEntry.TryLock(ctx)
+		if err != nil {
+			return false, fmt.Errorf("unable to lock %q: %w", entry.GetPaths(), err)
+		}
+
+		if !acquired {
+			logboek.Context(ctx).Debug().LogF("Skipping %q inside scope %q: in use\n", entry.GetPaths(), entry.GetCacheBasePath())
+			return false, nil
+		}
+
+		defer werf.HostLocker().ReleaseLock(lock)
+	}
+
+	logger := logboek.Context(ctx).Default()
+	// There are too many image cache records to log each of them.
+	if _, ok := entry.(*ImageCacheRecordDesc); ok {
+		logger = logboek.Context(ctx).Debug()
+	}
+
+	for _, path := range entry.GetPaths() {
+		logger.LogF("Removing %q inside scope %q\n", path, entry.GetCacheBasePath())
+
+		if dryRun {
+			continue
+		}
+
+		if err := RemovePathWithEmptyParentDirsInsideScope(entry.GetCacheBasePath(), path); err != nil {
+			return false, fmt.Errorf("unable to remove %q: %w", path, err)
+		}
+	}
+
+	return true, nil
+}
+
 func RemovePathWithEmptyParentDirsInsideScope(scopeDir, path string) error {
 	if !util.IsSubpathOfBasePath(scopeDir, path) {
 		return nil
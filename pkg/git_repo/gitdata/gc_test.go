@@ -1,12 +1,19 @@
 package gitdata
 
 import (
+	"context"
+	"math"
 	"os"
 	"path/filepath"
 	"time"
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
+
+	"github.com/werf/werf/v2/pkg/image"
+	"github.com/werf/werf/v2/pkg/logging"
+	"github.com/werf/werf/v2/pkg/storage/lrumeta"
+	"github.com/werf/werf/v2/pkg/werf"
 )
 
 var _ = Describe("wipeCacheDirs", func() {
@@ -69,3 +76,36 @@ var _ = Describe("wipeCacheDirs", func() {
 			}, true),
 	)
 })
+
+var _ = Describe("RunGC", func() {
+	BeforeEach(func() {
+		Expect(werf.Init("", GinkgoT().TempDir())).To(Succeed())
+	})
+
+	It("removes the expired manifest cache records and keeps the expired LRU images cache records", func(ctx context.Context) {
+		ctx = logging.WithLogger(ctx)
+		expiredTime := time.Now().Add(-imageCacheRecordMaxAge - time.Hour)
+
+		Expect(image.NewManifestCache(image.GetManifestCacheDir()).StoreImageInfo(ctx, "storage", &image.Info{Name: "image"})).To(Succeed())
+		Expect(lrumeta.NewLRUImagesCache(lrumeta.GetLRUImagesCacheDir()).AccessImage(ctx, "registry.example.com/image:tag")).To(Succeed())
+
+		manifestEntries, err := GetManifestCacheRecordsAndRemoveInvalid(ctx, image.GetManifestCacheDir())
+		Expect(err).NotTo(HaveOccurred())
+		Expect(manifestEntries).To(HaveLen(1))
+
+		lruEntries, err := GetLRUImagesCacheRecordsAndRemoveInvalid(ctx, lrumeta.GetLRUImagesCacheDir())
+		Expect(err).NotTo(HaveOccurred())
+		Expect(lruEntries).To(HaveLen(1))
+
+		manifestRecordPath := manifestEntries[0].GetPaths()[0]
+		lruRecordPath := lruEntries[0].GetPaths()[0]
+		for _, path := range []string{manifestRecordPath, lruRecordPath} {
+			Expect(os.Chtimes(path, expiredTime, expiredTime)).To(Succeed())
+		}
+
+		Expect(RunGC(ctx, RunGCOptions{AllowedLocalCacheVolumeUsageBytes: math.MaxUint64})).To(Succeed())
+
+		Expect(manifestRecordPath).NotTo(BeAnExistingFile())
+		Expect(lruRecordPath).To(BeAnExistingFile())
+	})
+})
@@ -1,10 +1,13 @@
 package gitdata
 
 import (
+	"context"
 	"slices"
 	"time"
 
 	"github.com/samber/lo"
+
+	"github.com/werf/lockgate"
 )
 
 type GitDataEntry interface {
@@ -14,6 +17,13 @@ type GitDataEntry interface {
 	GetCacheBasePath() string
 }
 
+// LockableGitDataEntry is implemented by the entries which are used without the GC lock,
+// so they must be locked to be removed.
+type LockableGitDataEntry interface {
+	GitDataEntry
+	TryLock(ctx context.Context) (bool, lockgate.LockHandle, error)
+}
+
 func shouldPreserveGitDataEntryByLru(entry GitDataEntry) bool {
 	return time.Since(entry.GetLastAccessAt()) < 3*time.Hour
 }
@@ -0,0 +1,246 @@
+package gitdata
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/werf/common-go/pkg/util"
+	"github.com/werf/lockgate"
+	"github.com/werf/logboek"
+	"github.com/werf/werf/v2/pkg/image"
+	"github.com/werf/werf/v2/pkg/storage/lrumeta"
+	"github.com/werf/werf/v2/pkg/werf"
+)
+
+// imageCacheRecordMaxAge is the age of the manifest cache records which are removed regardless of the local cache
+// volume usage: the records are tiny, but on the long-lived hosts there are millions of them.
+const imageCacheRecordMaxAge = 14 * 24 * time.Hour
+
+const (
+	manifestCacheReportStep  = "manifestCache"
+	lruImagesCacheReportStep = "lruImagesCache"
+)
+
+// ImageCacheRecordDesc is the record file of the manifest cache or the LRU images cache. The record is rewritten on
+// every access, so the modification time of the file is the last access time of the record.
+type ImageCacheRecordDesc struct {
+	Path          string
+	LastAccessAt  time.Time
+	Size          uint64
+	CacheBasePath string
+
+	// reportStep is the name of the host cleanup report step the freed space is reported in.
+	reportStep string
+	// lockName returns the name of the lock the record is accessed under by the record data.
+	lockName func(data []byte) (string, error)
+}
+
+func (entry *ImageCacheRecordDesc) GetPaths() []string {
+	return []string{entry.Path}
+}
+
+func (entry *ImageCacheRecordDesc) GetSize() uint64 {
+	return entry.Size
+}
+
+func (entry *ImageCacheRecordDesc) GetLastAccessAt() time.Time {
+	return entry.LastAccessAt
+}
+
+func (entry *ImageCacheRecordDesc) GetCacheBasePath() string {
+	return entry.CacheBasePath
+}
+
+// TryLock locks the record to remove it, the record is accessed by werf if the lock is not acquired. The record which
+// has been accessed since it was collected is not locked.
+func (entry *ImageCacheRecordDesc) TryLock(ctx context.Context) (bool, lockgate.LockHandle, error) {
+	data, err := ioutil.ReadFile(entry.Path)
+	if os.IsNotExist(err) {
+		return false, lockgate.LockHandle{}, nil
+	} else if err != nil {
+		return false, lockgate.LockHandle{}, fmt.Errorf("error reading %q: %w", entry.Path, err)
+	}
+
+	lockName, err := entry.lockName(data)
+	if err != nil {
+		// The invalid record is reset by werf on access, so there is nothing to lock but the removal itself.
+		logboek.Context(ctx).Debug().LogF("Invalid record %q: %s\n", entry.Path, err)
+		lockName = fmt.Sprintf("image_cache_record.%s", util.Sha256Hash(entry.Path))
+	}
+
+	acquired, lock, err := werf.HostLocker().AcquireLock(ctx, lockName, lockgate.AcquireOptions{NonBlocking: true})
+	if err != nil || !acquired {
+		return acquired, lock, err
+	}
+
+	info, err := os.Stat(entry.Path)
+	if err != nil || !info.ModTime().Equal(entry.LastAccessAt) {
+		_ = werf.HostLocker().ReleaseLock(lock)
+
+		if err != nil && !os.IsNotExist(err) {
+			return false, lockgate.LockHandle{}, fmt.Errorf("error accessing %q: %w", entry.Path, err)
+		}
+		return false, lockgate.LockHandle{}, nil
+	}
+
+	return true, lock, nil
+}
+
+// GetManifestCacheRecordsAndRemoveInvalid scans the given cacheVersionRoot directory of the manifest cache and returns
+// a list of ImageCacheRecordDesc for each record found. It removes invalid entries and handles errors appropriately.
+//
+// The directory structure expected is as follows:
+// ├── <storage_slug>/
+// │   ├── <image_name_hash> (json record)
+// │   └── ... (other images of the storage)
+// └── ... (other storages)
+func GetManifestCacheRecordsAndRemoveInvalid(ctx context.Context, cacheVersionRoot string) ([]GitDataEntry, error) {
+	var res []GitDataEntry
+
+	if _, err := os.Stat(cacheVersionRoot); os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("error accessing dir %q: %w", cacheVersionRoot, err)
+	}
+
+	storageDirs, err := ioutil.ReadDir(cacheVersionRoot)
+	if err != nil {
+		return nil, fmt.Errorf("error reading dir %q: %w", cacheVersionRoot, err)
+	}
+
+	for _, storageDirInfo := range storageDirs {
+		storageDir := filepath.Join(cacheVersionRoot, storageDirInfo.Name())
+
+		if !storageDirInfo.IsDir() {
+			logboek.Context(ctx).Warn().LogF("Removing invalid entry %q: not a directory\n", storageDir)
+			if err := os.RemoveAll(storageDir); err != nil {
+				return nil, fmt.Errorf("unable to remove %q: %w", storageDir, err)
+			}
+			continue
+		}
+
+		entries, err := getImageCacheRecordsAndRemoveInvalid(ctx, storageDir, cacheVersionRoot, manifestCacheReportStep, manifestCacheRecordLockNameFunc(storageDirInfo.Name()))
+		if err != nil {
+			return nil, err
+		}
+
+		res = append(res, entries...)
+	}
+
+	return res, nil
+}
+
+// GetLRUImagesCacheRecordsAndRemoveInvalid scans the given cacheVersionRoot directory of the LRU images cache and
+// returns a list of ImageCacheRecordDesc for each record found. It removes invalid entries and handles errors
+// appropriately.
+//
+// The directory structure expected is as follows:
+// ├── <image_ref_hash> (json record)
+// └── ... (other images)
+func GetLRUImagesCacheRecordsAndRemoveInvalid(ctx context.Context, cacheVersionRoot string) ([]GitDataEntry, error) {
+	if _, err := os.Stat(cacheVersionRoot); os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("error accessing dir %q: %w", cacheVersionRoot, err)
+	}
+
+	return getImageCacheRecordsAndRemoveInvalid(ctx, cacheVersionRoot, cacheVersionRoot, lruImagesCacheReportStep, lruImagesCacheRecordLockName)
+}
+
+func getImageCacheRecordsAndRemoveInvalid(ctx context.Context, recordsDir, cacheVersionRoot, reportStep string, lockName func(data []byte) (string, error)) ([]GitDataEntry, error) {
+	var res []GitDataEntry
+
+	recordInfos, err := ioutil.ReadDir(recordsDir)
+	if err != nil {
+		return nil, fmt.Errorf("error reading dir %q: %w", recordsDir, err)
+	}
+
+	for _, recordInfo := range recordInfos {
+		recordPath := filepath.Join(recordsDir, recordInfo.Name())
+
+		if !recordInfo.Mode().IsRegular() {
+			logboek.Context(ctx).Warn().LogF("Removing invalid entry %q: not a regular file\n", recordPath)
+			if err := os.RemoveAll(recordPath); err != nil {
+				return nil, fmt.Errorf("unable to remove %q: %w", recordPath, err)
+			}
+			continue
+		}
+
+		res = append(res, &ImageCacheRecordDesc{
+			Path:          recordPath,
+			LastAccessAt:  recordInfo.ModTime(),
+			Size:          uint64(recordInfo.Size()),
+			CacheBasePath: cacheVersionRoot,
+			reportStep:    reportStep,
+			lockName:      lockName,
+		})
+	}
+
+	return res, nil
+}
+
+func manifestCacheRecordLockNameFunc(storageDirName string) func(data []byte) (string, error) {
+	return func(data []byte) (string, error) {
+		var record image.ManifestCacheRecord
+		if err := json.Unmarshal(data, &record); err != nil {
+			return "", fmt.Errorf("unable to unmarshal json: %w", err)
+		}
+
+		if record.Info == nil || record.Info.Name == "" {
+			return "", errors.New("image name not found")
+		}
+
+		return image.ManifestCacheLockName(storageDirName, record.Info.Name), nil
+	}
+}
+
+func lruImagesCacheRecordLockName(data []byte) (string, error) {
+	var record lrumeta.LRUImagesCacheRecord
+	if err := json.Unmarshal(data, &record); err != nil {
+		return "", fmt.Errorf("unable to unmarshal json: %w", err)
+	}
+
+	if record.ImageRef == "" {
+		return "", errors.New("image ref not found")
+	}
+
+	return lrumeta.LRUImagesCacheLockName(record.ImageRef), nil
+}
+
+// removeExpiredImageCacheRecords removes the records not accessed for imageCacheRecordMaxAge and returns the rest.
+func removeExpiredImageCacheRecords(ctx context.Context, entries []GitDataEntry, freedBytes map[string]uint64, dryRun bool) ([]GitDataEntry, error) {
+	var res []GitDataEntry
+	var removedCount int
+
+	for _, entry := range entries {
+		if time.Since(entry.GetLastAccessAt()) < imageCacheRecordMaxAge {
+			res = append(res, entry)
+			continue
+		}
+
+		removed, err := removeGitDataEntry(ctx, entry, dryRun)
+		if err != nil {
+			return nil, err
+		}
+
+		if !removed {
+			res = append(res, entry)
+			continue
+		}
+
+		freedBytes[entry.(*ImageCacheRecordDesc).reportStep] += entry.GetSize()
+		removedCount++
+	}
+
+	if removedCount > 0 {
+		logboek.Context(ctx).LogF("Removed %d image cache records not accessed for more than %d days\n", removedCount, int(imageCacheRecordMaxAge.Hours()/24))
+	}
+
+	return res, nil
+}
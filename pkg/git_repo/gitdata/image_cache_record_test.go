@@ -0,0 +1,154 @@
+package gitdata
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/werf/lockgate"
+	"github.com/werf/werf/v2/pkg/image"
+	"github.com/werf/werf/v2/pkg/logging"
+	"github.com/werf/werf/v2/pkg/storage/lrumeta"
+	"github.com/werf/werf/v2/pkg/werf"
+)
+
+var _ = Describe("image cache records", func() {
+	var manifestCacheRoot, lruImagesCacheRoot string
+
+	expiredTime := time.Now().Add(-imageCacheRecordMaxAge - time.Hour)
+
+	setMtime := func(path string, mtime time.Time) {
+		Expect(os.Chtimes(path, mtime, mtime)).To(Succeed())
+	}
+
+	BeforeEach(func() {
+		homeDir := GinkgoT().TempDir()
+		Expect(werf.Init("", homeDir)).To(Succeed())
+
+		manifestCacheRoot = filepath.Join(homeDir, "manifests")
+		lruImagesCacheRoot = filepath.Join(homeDir, "lru_images")
+	})
+
+	storeManifest := func(ctx context.Context, storageName, imageName string) string {
+		Expect(image.NewManifestCache(manifestCacheRoot).StoreImageInfo(ctx, storageName, &image.Info{Name: imageName})).To(Succeed())
+
+		entries, err := GetManifestCacheRecordsAndRemoveInvalid(ctx, manifestCacheRoot)
+		Expect(err).NotTo(HaveOccurred())
+		for _, entry := range entries {
+			path := entry.GetPaths()[0]
+			if filepath.Base(filepath.Dir(path)) == storageName {
+				return path
+			}
+		}
+
+		Fail("manifest cache record not found")
+		return ""
+	}
+
+	It("returns the manifest cache records and removes the invalid entries", func(ctx context.Context) {
+		ctx = logging.WithLogger(ctx)
+
+		recordPath := storeManifest(ctx, "storage", "image")
+
+		invalidStorageEntry := filepath.Join(manifestCacheRoot, "file")
+		Expect(os.WriteFile(invalidStorageEntry, []byte("x"), 0o644)).To(Succeed())
+
+		invalidRecordEntry := filepath.Join(manifestCacheRoot, "storage", "dir")
+		Expect(os.Mkdir(invalidRecordEntry, 0o755)).To(Succeed())
+
+		entries, err := GetManifestCacheRecordsAndRemoveInvalid(ctx, manifestCacheRoot)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(entries).To(HaveLen(1))
+		Expect(entries[0].GetPaths()).To(Equal([]string{recordPath}))
+		Expect(entries[0].GetCacheBasePath()).To(Equal(manifestCacheRoot))
+
+		Expect(invalidStorageEntry).NotTo(BeAnExistingFile())
+		Expect(invalidRecordEntry).NotTo(BeAnExistingFile())
+	})
+
+	It("returns the LRU images cache records and removes the invalid entries", func(ctx context.Context) {
+		ctx = logging.WithLogger(ctx)
+
+		Expect(lrumeta.NewLRUImagesCache(lruImagesCacheRoot).AccessImage(ctx, "registry.example.com/image:tag")).To(Succeed())
+
+		invalidRecordEntry := filepath.Join(lruImagesCacheRoot, "dir")
+		Expect(os.Mkdir(invalidRecordEntry, 0o755)).To(Succeed())
+
+		entries, err := GetLRUImagesCacheRecordsAndRemoveInvalid(ctx, lruImagesCacheRoot)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(entries).To(HaveLen(1))
+		Expect(entries[0].GetLastAccessAt()).To(BeTemporally("~", time.Now(), time.Minute))
+		Expect(entries[0].GetCacheBasePath()).To(Equal(lruImagesCacheRoot))
+
+		Expect(invalidRecordEntry).NotTo(BeAnExistingFile())
+	})
+
+	It("returns nothing if the cache root does not exist", func(ctx context.Context) {
+		entries, err := GetManifestCacheRecordsAndRemoveInvalid(ctx, manifestCacheRoot)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(entries).To(BeEmpty())
+
+		entries, err = GetLRUImagesCacheRecordsAndRemoveInvalid(ctx, lruImagesCacheRoot)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(entries).To(BeEmpty())
+	})
+
+	It("removes only the expired records which are not locked", func(ctx context.Context) {
+		ctx = logging.WithLogger(ctx)
+
+		expiredRecordPath := storeManifest(ctx, "storage", "expired")
+		setMtime(expiredRecordPath, expiredTime)
+
+		lockedRecordPath := storeManifest(ctx, "storage", "locked")
+		setMtime(lockedRecordPath, expiredTime)
+
+		freshRecordPath := storeManifest(ctx, "storage", "fresh")
+
+		invalidRecordPath := filepath.Join(manifestCacheRoot, "storage", "invalid")
+		Expect(os.WriteFile(invalidRecordPath, []byte("{"), 0o644)).To(Succeed())
+		setMtime(invalidRecordPath, expiredTime)
+
+		_, lock, err := werf.HostLocker().AcquireLock(ctx, image.ManifestCacheLockName("storage", "locked"), lockgate.AcquireOptions{})
+		Expect(err).NotTo(HaveOccurred())
+		defer werf.HostLocker().ReleaseLock(lock)
+
+		entries, err := GetManifestCacheRecordsAndRemoveInvalid(ctx, manifestCacheRoot)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(entries).To(HaveLen(4))
+
+		freedBytes := map[string]uint64{}
+		rest, err := removeExpiredImageCacheRecords(ctx, entries, freedBytes, false)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(rest).To(HaveLen(2))
+
+		Expect(expiredRecordPath).NotTo(BeAnExistingFile())
+		Expect(invalidRecordPath).NotTo(BeAnExistingFile())
+		Expect(lockedRecordPath).To(BeAnExistingFile())
+		Expect(freshRecordPath).To(BeAnExistingFile())
+
+		Expect(freedBytes[manifestCacheReportStep]).To(BeNumerically(">", 1))
+		Expect(freedBytes).NotTo(HaveKey(lruImagesCacheReportStep))
+	})
+
+	It("does not lock the record accessed after the scan", func(ctx context.Context) {
+		ctx = logging.WithLogger(ctx)
+
+		recordPath := storeManifest(ctx, "storage", "image")
+		setMtime(recordPath, expiredTime)
+
+		entries, err := GetManifestCacheRecordsAndRemoveInvalid(ctx, manifestCacheRoot)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(entries).To(HaveLen(1))
+
+		_, err = image.NewManifestCache(manifestCacheRoot).GetImageInfo(ctx, "storage", "image")
+		Expect(err).NotTo(HaveOccurred())
+
+		acquired, _, err := entries[0].(LockableGitDataEntry).TryLock(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(acquired).To(BeFalse())
+	})
+})
@@ -259,6 +259,10 @@ func (repo *Local) IsEmpty(ctx context.Context) (bool, error) {
 	return repo.isEmpty(ctx, repo.WorkTreeDir)
 }
 
+func (repo *Local) GetLastPathCommit(ctx context.Context, commit, path string) (*true_git.PathCommit, error) {
+	return true_git.GetLastPathCommit(ctx, repo.GitDir, commit, path)
+}
+
 func (repo *Local) IsAncestor(ctx context.Context, ancestorCommit, descendantCommit string) (bool, error) {
 	return true_git.IsAncestor(ctx, ancestorCommit, descendantCommit, repo.GitDir)
 }
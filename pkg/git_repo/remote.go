@@ -151,6 +151,10 @@ func (repo *Remote) IsShallowClone(ctx context.Context) (bool, error) {
 	return true_git.IsShallowClone(ctx, repo.GetClonePath())
 }
 
+func (repo *Remote) GetLastPathCommit(ctx context.Context, commit, path string) (*true_git.PathCommit, error) {
+	return true_git.GetLastPathCommit(ctx, repo.GetClonePath(), commit, path)
+}
+
 func (repo *Remote) IsAncestor(ctx context.Context, ancestorCommit, descendantCommit string) (bool, error) {
 	return true_git.IsAncestor(ctx, ancestorCommit, descendantCommit, repo.GetClonePath())
 }
@@ -36,6 +36,10 @@ func NewConfig(ctx context.Context, fileReader fileReader, configRelPath string)
 		panic(fmt.Sprint("unexpected error: ", err))
 	}
 
+	if err := c.Policy.validate(); err != nil {
+		return c, fmt.Errorf("the giterminism config validation failed: %w", err)
+	}
+
 	return c, err
 }
 
@@ -49,6 +53,7 @@ type Config struct {
 	Config   config   `json:"config"`
 	Helm     helm     `json:"helm"`
 	Includes includes `json:"includes"`
+	Policy   Policy   `json:"policy"`
 }
 
 func (c Config) IsCustomTagsAccepted() bool {
@@ -30,6 +30,8 @@ properties:
     $ref: '#/definitions/Config'
   helm:
     $ref: '#/definitions/Helm'
+  policy:
+    $ref: '#/definitions/Policy'
 definitions:
   CLI:
     type: object
@@ -115,6 +117,40 @@ definitions:
         type: array
         items:
           type: string
+  Policy:
+    type: object
+    additionalProperties: {}
+    properties:
+      baseImages:
+        $ref: '#/definitions/PolicyBaseImages'
+      finalImages:
+        $ref: '#/definitions/PolicyFinalImages'
+  PolicyBaseImages:
+    type: object
+    additionalProperties: {}
+    properties:
+      allowRegistries:
+        type: array
+        items:
+          type: string
+      allowDigests:
+        type: array
+        items:
+          type: string
+  PolicyFinalImages:
+    type: object
+    additionalProperties: {}
+    properties:
+      requireLabels:
+        type: array
+        items:
+          type: string
+      maxSize:
+        type: string
+      forbidRootUser:
+        type: boolean
+      requireAuthor:
+        type: boolean
 `
 )
 
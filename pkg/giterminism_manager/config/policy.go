@@ -0,0 +1,66 @@
+package config
+
+import (
+	"fmt"
+
+	"github.com/docker/go-units"
+	"github.com/opencontainers/go-digest"
+)
+
+// Policy is the set of rules the images built by werf must comply with. Unlike the rest of the config, the policy
+// tightens the build and is not loosened by --loose-giterminism or --dev.
+type Policy struct {
+	BaseImages  BaseImagesPolicy  `json:"baseImages"`
+	FinalImages FinalImagesPolicy `json:"finalImages"`
+}
+
+func (p Policy) IsEmpty() bool {
+	return p.BaseImages.IsEmpty() && p.FinalImages.IsEmpty()
+}
+
+func (p Policy) validate() error {
+	for _, d := range p.BaseImages.AllowDigests {
+		if _, err := digest.Parse(d); err != nil {
+			return fmt.Errorf("invalid policy.baseImages.allowDigests item %q: %w", d, err)
+		}
+	}
+
+	if p.FinalImages.MaxSize != "" {
+		if _, err := p.FinalImages.MaxSizeBytes(); err != nil {
+			return fmt.Errorf("invalid policy.finalImages.maxSize %q: %w", p.FinalImages.MaxSize, err)
+		}
+	}
+
+	return nil
+}
+
+// BaseImagesPolicy restricts the registry images used as base images by from directive and FROM instructions. The
+// image is allowed if its repository is in one of the allowed registries or it is pinned to one of the allowed
+// digests.
+type BaseImagesPolicy struct {
+	AllowRegistries []string `json:"allowRegistries"`
+	AllowDigests    []string `json:"allowDigests"`
+}
+
+func (p BaseImagesPolicy) IsEmpty() bool {
+	return len(p.AllowRegistries) == 0 && len(p.AllowDigests) == 0
+}
+
+// FinalImagesPolicy restricts the final images. The labels and the author are checked in the image config before the
+// build, the size and the user are checked in the built image.
+type FinalImagesPolicy struct {
+	RequireLabels  []string `json:"requireLabels"`
+	MaxSize        string   `json:"maxSize"`
+	ForbidRootUser bool     `json:"forbidRootUser"`
+	RequireAuthor  bool     `json:"requireAuthor"`
+}
+
+func (p FinalImagesPolicy) IsEmpty() bool {
+	return len(p.RequireLabels) == 0 && p.MaxSize == "" && !p.ForbidRootUser && !p.RequireAuthor
+}
+
+// MaxSizeBytes returns the max size in bytes, the size is parsed with binary prefixes: 500M, 500MB and 500MiB are
+// all 500*1024*1024 bytes.
+func (p FinalImagesPolicy) MaxSizeBytes() (int64, error) {
+	return units.RAMInBytes(p.MaxSize)
+}
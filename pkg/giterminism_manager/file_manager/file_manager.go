@@ -59,6 +59,8 @@ type NewFileManagerOptions struct {
 	Inspector              inspector.Inspector
 	CreateIncludesLockFile bool
 	AllowIncludesUpdate    bool
+	// SkipIncludesInit leaves includes uninitialized, so neither the lock file is read nor the remotes are fetched.
+	SkipIncludesInit bool
 }
 
 func NewFileManager(ctx context.Context, opts NewFileManagerOptions) (*FileManager, error) {
@@ -67,18 +69,22 @@ func NewFileManager(ctx context.Context, opts NewFileManagerOptions) (*FileManag
 			return nil, err
 		}
 	}
-	includes, err := includes.Init(ctx, includes.InitIncludesOptions{
-		FileReader:             opts.FileReader,
-		CreateOrUpdateLockFile: opts.CreateIncludesLockFile,
-		UseLatestVersion:       opts.AllowIncludesUpdate,
-		ProjectDir:             opts.ProjectDir,
-	})
-	if err != nil {
-		return nil, err
+	var includesList []*includes.Include
+	if !opts.SkipIncludesInit {
+		var err error
+		includesList, err = includes.Init(ctx, includes.InitIncludesOptions{
+			FileReader:             opts.FileReader,
+			CreateOrUpdateLockFile: opts.CreateIncludesLockFile,
+			UseLatestVersion:       opts.AllowIncludesUpdate,
+			ProjectDir:             opts.ProjectDir,
+		})
+		if err != nil {
+			return nil, err
+		}
 	}
 	return &FileManager{
 		fileReader: opts.FileReader,
-		includes:   includes,
+		includes:   includesList,
 		caches: &caches{
 			dockerFiles: make(map[string][]byte),
 		},
@@ -179,6 +185,27 @@ func (f *FileManager) ConfigGoTemplateFilesGet(ctx context.Context, relPath stri
 	return res, nil
 }
 
+func (f *FileManager) ConfigGoTemplateFilesGetBlobHash(ctx context.Context, relPath string) (string, error) {
+	data, err := f.ConfigGoTemplateFilesGet(ctx, relPath)
+	if err != nil {
+		return "", err
+	}
+	return file_reader.GitBlobHash(data), nil
+}
+
+func (f *FileManager) ConfigGoTemplateFilesGlobBlobHashes(ctx context.Context, pattern string) (map[string]string, error) {
+	res, err := f.ConfigGoTemplateFilesGlob(ctx, pattern)
+	if err != nil {
+		return nil, err
+	}
+
+	hashes := make(map[string]string, len(res))
+	for relPath, data := range res {
+		hashes[relPath] = file_reader.GitBlobHash([]byte(fmt.Sprint(data)))
+	}
+	return hashes, nil
+}
+
 func (f *FileManager) ConfigGoTemplateFilesGlob(ctx context.Context, pattern string) (map[string]interface{}, error) {
 	res, err := f.fileReader.ConfigGoTemplateFilesGlob(ctx, pattern)
 	if err != nil {
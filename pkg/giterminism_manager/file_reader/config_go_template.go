@@ -5,8 +5,15 @@ import (
 	"errors"
 	"fmt"
 	"path/filepath"
+
+	"github.com/go-git/go-git/v5/plumbing"
 )
 
+// GitBlobHash returns the hash git assigns to the blob with the given content, so for a committed file it matches the hash in the commit tree.
+func GitBlobHash(data []byte) string {
+	return plumbing.ComputeHash(plumbing.BlobObject, data).String()
+}
+
 func (r FileReader) ConfigGoTemplateFilesGlob(ctx context.Context, glob string) (map[string]interface{}, error) {
 	result := map[string]interface{}{}
 
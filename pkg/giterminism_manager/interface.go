@@ -5,12 +5,14 @@ import (
 
 	"github.com/werf/nelm/pkg/export/helm/werf/file"
 	"github.com/werf/werf/v2/pkg/git_repo"
+	"github.com/werf/werf/v2/pkg/giterminism_manager/config"
 	"github.com/werf/werf/v2/pkg/path_matcher"
 )
 
 type Interface interface {
 	FileReader() FileReader
 	Inspector() Inspector
+	Policy() config.Policy
 	LocalGitRepo() git_repo.GitRepo
 	HeadCommit(ctx context.Context) string
 	ProjectDir() string
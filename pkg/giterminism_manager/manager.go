@@ -18,6 +18,7 @@ type NewManagerOptions struct {
 	Dev                    bool
 	CreateIncludesLockFile bool
 	AllowIncludesUpdate    bool
+	SkipIncludesInit       bool
 }
 
 func NewManager(ctx context.Context, configRelPath, projectDir string, localGitRepo *git_repo.Local, headCommit string, options NewManagerOptions) (*Manager, error) {
@@ -50,6 +51,7 @@ func NewManager(ctx context.Context, configRelPath, projectDir string, localGitR
 		sharedOptions: sharedOptions,
 		fileReader:    fr,
 		inspector:     i,
+		policy:        c.Policy,
 	}
 
 	m.FileManager, err = filemanager.NewFileManager(ctx, filemanager.NewFileManagerOptions{
@@ -58,6 +60,7 @@ func NewManager(ctx context.Context, configRelPath, projectDir string, localGitR
 		Inspector:              i,
 		CreateIncludesLockFile: options.CreateIncludesLockFile,
 		AllowIncludesUpdate:    options.AllowIncludesUpdate,
+		SkipIncludesInit:       options.SkipIncludesInit,
 	})
 	if err != nil {
 		return nil, err
@@ -71,6 +74,7 @@ func NewManager(ctx context.Context, configRelPath, projectDir string, localGitR
 type Manager struct {
 	fileReader FileReader
 	inspector  Inspector
+	policy     config.Policy
 
 	FileManager *filemanager.FileManager
 
@@ -85,6 +89,10 @@ func (m Manager) Inspector() Inspector {
 	return m.inspector
 }
 
+func (m Manager) Policy() config.Policy {
+	return m.policy
+}
+
 type sharedOptions struct {
 	projectDir       string
 	headCommit       string
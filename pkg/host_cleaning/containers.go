@@ -50,6 +50,16 @@ func werfContainerName(container image.Container) string {
 	return containerName
 }
 
+// buildahContainerName returns the name of the Buildah working container created by werf or an empty string.
+func buildahContainerName(container image.Container) string {
+	for _, name := range container.Names {
+		if strings.HasPrefix(name, image.BuildahContainerNamePrefix) {
+			return name
+		}
+	}
+	return ""
+}
+
 func buildContainersOptions(filters ...image.ContainerFilter) container_backend.ContainersOptions {
 	opts := container_backend.ContainersOptions{}
 	opts.Filters = filters
@@ -6,6 +6,7 @@ import (
 	"fmt"
 
 	"github.com/werf/logboek"
+	"github.com/werf/werf/v2/pkg/cleanup_report"
 	"github.com/werf/werf/v2/pkg/container_backend"
 	"github.com/werf/werf/v2/pkg/git_repo/gitdata"
 	"github.com/werf/werf/v2/pkg/host_cleaning/units"
@@ -39,6 +40,10 @@ type HostCleanupOptions struct {
 
 	DryRun bool
 	Force  bool
+
+	// Report collects the items deleted from the local container backend and the reclaimed space,
+	// including the space freed in the local cache.
+	Report *cleanup_report.HostReport
 }
 
 func getRequirementInBytes(val *units.UnitValue, defaultPercent, totalBytes uint64) uint64 {
@@ -123,6 +128,7 @@ func RunHostCleanup(ctx context.Context, backend container_backend.ContainerBack
 			AllowedLocalCacheVolumeUsageBytes:       allowedLocalCacheVolumeUsageBytes,
 			AllowedLocalCacheVolumeUsageMarginBytes: allowedLocalCacheVolumeUsageMarginBytes,
 			DryRun:                                  options.DryRun,
+			Report:                                  options.Report,
 		}); err != nil {
 			return fmt.Errorf("git repo GC failed: %w", err)
 		}
@@ -159,6 +165,7 @@ func RunHostCleanup(ctx context.Context, backend container_backend.ContainerBack
 			StoragePath:                          *options.BackendStoragePath,
 			Force:                                options.Force,
 			DryRun:                               options.DryRun,
+			Report:                               options.Report,
 		})
 		if err != nil {
 			return fmt.Errorf("local %s backend GC failed: %w", cleaner.BackendName(), err)
@@ -5,8 +5,10 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io/fs"
 	"math"
 	"os"
+	"path/filepath"
 	"slices"
 	"time"
 
@@ -16,6 +18,7 @@ import (
 	"github.com/werf/kubedog/pkg/utils"
 	"github.com/werf/lockgate"
 	"github.com/werf/logboek"
+	"github.com/werf/werf/v2/pkg/cleanup_report"
 	"github.com/werf/werf/v2/pkg/container_backend"
 	"github.com/werf/werf/v2/pkg/container_backend/filter"
 	"github.com/werf/werf/v2/pkg/container_backend/prune"
@@ -29,12 +32,16 @@ var ErrUnsupportedContainerBackend = errors.New("unsupported container backend")
 
 var errOptionDryRunNotSupported = errors.New("option dry-run not supported")
 
+// staleBuildahContainerAge is the age of the unlocked Buildah working container to consider it left behind.
+const staleBuildahContainerAge = 3 * time.Hour
+
 type RunGCOptions struct {
 	AllowedStorageVolumeUsageBytes       uint64
 	AllowedStorageVolumeUsageMarginBytes uint64
 	StoragePath                          string
 	Force                                bool
 	DryRun                               bool
+	Report                               *cleanup_report.HostReport
 }
 
 type RunAutoGCOptions struct {
@@ -45,11 +52,13 @@ type RunAutoGCOptions struct {
 //go:generate mockgen -package mock -destination ../../test/mock/locker.go github.com/werf/lockgate Locker
 
 type LocalBackendCleaner struct {
-	backend     container_backend.ContainerBackend
-	backendType containerBackendType
-	locker      lockgate.Locker
+	backend       container_backend.ContainerBackend
+	backendType   containerBackendType
+	storageDriver string
+	locker        lockgate.Locker
 	// refs for stubbing in testing
 	volumeutilsGetVolumeUsageByPath func(ctx context.Context, path string) (volumeutils.VolumeUsage, error)
+	volumeutilsDirSizeBytes         func(path string) (uint64, error)
 	werfGetWerfLastRunAtV1_1        func(ctx context.Context) (time.Time, error)
 	lrumetaGetImageLastAccessTime   func(ctx context.Context, imageRef string) (time.Time, error)
 }
@@ -60,6 +69,7 @@ func NewLocalBackendCleaner(backend container_backend.ContainerBackend, locker l
 		locker:  locker,
 		// refs for stubbing in testing
 		volumeutilsGetVolumeUsageByPath: volumeutils.GetVolumeUsageByPath,
+		volumeutilsDirSizeBytes:         volumeutils.DirSizeBytes,
 		werfGetWerfLastRunAtV1_1:        werf.GetWerfLastRunAtV1_1,
 		lrumetaGetImageLastAccessTime:   lrumeta.CommonLRUImagesCache.GetImageLastAccessTime,
 	}
@@ -246,12 +256,24 @@ func (cleaner *LocalBackendCleaner) RunGC(ctx context.Context, options RunGCOpti
 	// We can clarify StoragePath from now to further usage
 	options.StoragePath = backendStoragePath
 
+	if cleaner.backendType == containerBackendBuildah {
+		info, err := cleaner.backend.Info(ctx)
+		if err != nil {
+			return fmt.Errorf("error getting local %s backend info: %w", cleaner.BackendName(), err)
+		}
+		cleaner.storageDriver = info.StoreDriver
+	}
+	options.Report.SetBackend(ctx, cleaner.BackendName(), cleaner.storageDriver)
+
 	logboek.Context(ctx).LogF("Storage path: %s\n", options.StoragePath)
+	if cleaner.storageDriver != "" {
+		logboek.Context(ctx).LogF("Storage driver: %s\n", cleaner.storageDriver)
+	}
 	logboek.Context(ctx).LogOptionalLn()
 
-	vu, err := cleaner.volumeutilsGetVolumeUsageByPath(ctx, backendStoragePath)
+	vu, err := cleaner.storageUsage(ctx, backendStoragePath)
 	if err != nil {
-		return fmt.Errorf("error getting volume usage by path %q: %w", backendStoragePath, err)
+		return err
 	}
 
 	// initialVolumeUsage stores the baseline volume usage to calculate the total factual freed space at the end of the GC process.
@@ -276,41 +298,44 @@ func (cleaner *LocalBackendCleaner) RunGC(ctx context.Context, options RunGCOpti
 		logboek.Context(ctx).LogF("Needed to free: %s\n", utils.RedF("%s", humanize.Bytes(neededToFreeBytes)))
 	})
 
-	// Step 1. Prune unused anonymous volumes
-	err = logboek.Context(ctx).LogBlock("Prune all unused anonymous volumes").DoError(func() error {
-		reportVolumes, err := cleaner.pruneVolumes(ctx, options)
-		if handleError(ctx, err) != nil {
-			return err
-		}
-
-		var spaceReclaimed uint64
-		if spaceReclaimed, vu, err = cleaner.measureReclaimedSpace(ctx, options.StoragePath, vu); err != nil {
-			return err
+	// Step 0. Remove stale Buildah working containers left behind by the interrupted builds
+	if cleaner.backendType == containerBackendBuildah {
+		err = cleaner.runGCStep(ctx, options, &vu, gcStep{
+			Title:    fmt.Sprintf("Remove Buildah working containers created more than %s ago", staleBuildahContainerAge),
+			Name:     "staleBuildahContainers",
+			ItemType: cleanup_report.ItemTypeLocalContainer,
+			Reason:   "stale working container",
+		}, func() (cleanupReport, error) {
+			return cleaner.cleanupStaleBuildahContainers(ctx, options)
+		})
+		if err != nil {
+			return fmt.Errorf("unable to remove stale Buildah working containers: %w", err)
 		}
-		logboek.Context(ctx).LogF("Freed space: %s\n", utils.RedF("%s", humanize.Bytes(spaceReclaimed)))
-		logDeletedItems(ctx, reportVolumes.ItemsDeleted)
+	}
 
-		return nil
+	// Step 1. Prune unused anonymous volumes (unused layers for Buildah)
+	err = cleaner.runGCStep(ctx, options, &vu, gcStep{
+		Title:    "Prune all unused anonymous volumes",
+		Name:     "volumes",
+		ItemType: cleaner.volumeItemType(),
+		Reason:   "unused",
+	}, func() (cleanupReport, error) {
+		report, err := cleaner.pruneVolumes(ctx, options)
+		return report, handleError(ctx, err)
 	})
 	if err != nil {
 		return fmt.Errorf("unable to prune unused anonymous volumes: %w", err)
 	}
 
 	// Step 2. Prune werf dangling images
-	err = logboek.Context(ctx).LogBlock("Prune werf dangling images created more than 1 hour ago").DoError(func() error {
-		reportImages, err := cleaner.pruneImages(ctx, options)
-		if handleError(ctx, err) != nil {
-			return err
-		}
-
-		var spaceReclaimed uint64
-		if spaceReclaimed, vu, err = cleaner.measureReclaimedSpace(ctx, options.StoragePath, vu); err != nil {
-			return err
-		}
-		logboek.Context(ctx).LogF("Freed space: %s\n", utils.RedF("%s", humanize.Bytes(spaceReclaimed)))
-		logDeletedItems(ctx, reportImages.ItemsDeleted)
-
-		return nil
+	err = cleaner.runGCStep(ctx, options, &vu, gcStep{
+		Title:    "Prune werf dangling images created more than 1 hour ago",
+		Name:     "danglingImages",
+		ItemType: cleanup_report.ItemTypeLocalImage,
+		Reason:   "dangling",
+	}, func() (cleanupReport, error) {
+		report, err := cleaner.pruneImages(ctx, options)
+		return report, handleError(ctx, err)
 	})
 	if err != nil {
 		return fmt.Errorf("unable to prune werf dangling images: %w", err)
@@ -329,40 +354,25 @@ func (cleaner *LocalBackendCleaner) RunGC(ctx context.Context, options RunGCOpti
 	}
 
 	// Step 3. Remove werf containers
-	err = logboek.Context(ctx).LogBlock("Cleanup werf containers").DoError(func() error {
-		reportWerfContainers, err := cleaner.cleanupWerfContainers(ctx, options)
-		if err != nil {
-			return err
-		}
-
-		var spaceReclaimed uint64
-		if spaceReclaimed, vu, err = cleaner.measureReclaimedSpace(ctx, options.StoragePath, vu); err != nil {
-			return err
-		}
-		logboek.Context(ctx).LogF("Freed space: %s\n", utils.RedF("%s", humanize.Bytes(spaceReclaimed)))
-		logDeletedItems(ctx, reportWerfContainers.ItemsDeleted)
-
-		return nil
+	err = cleaner.runGCStep(ctx, options, &vu, gcStep{
+		Title:    "Cleanup werf containers",
+		Name:     "werfContainers",
+		ItemType: cleanup_report.ItemTypeLocalContainer,
+	}, func() (cleanupReport, error) {
+		return cleaner.cleanupWerfContainers(ctx, options)
 	})
 	if err != nil {
 		return fmt.Errorf("unable to remove werf containers: %w", err)
 	}
 
 	// Step 4. Remove werf images
-	err = logboek.Context(ctx).LogBlock("Cleanup werf images").DoError(func() error {
-		reportWerfImages, err := cleaner.cleanupWerfImages(ctx, options, targetVolumeUsageBytes)
-		if err != nil {
-			return err
-		}
-
-		var spaceReclaimed uint64
-		if spaceReclaimed, vu, err = cleaner.measureReclaimedSpace(ctx, options.StoragePath, vu); err != nil {
-			return err
-		}
-		logboek.Context(ctx).LogF("Freed space: %s\n", utils.RedF("%s", humanize.Bytes(spaceReclaimed)))
-		logDeletedItems(ctx, reportWerfImages.ItemsDeleted)
-
-		return nil
+	err = cleaner.runGCStep(ctx, options, &vu, gcStep{
+		Title:    "Cleanup werf images",
+		Name:     "werfImages",
+		ItemType: cleanup_report.ItemTypeLocalImage,
+		Reason:   "least recently used",
+	}, func() (cleanupReport, error) {
+		return cleaner.cleanupWerfImages(ctx, options, targetVolumeUsageBytes)
 	})
 	if err != nil {
 		return fmt.Errorf("unable to cleanup werf images: %w", err)
@@ -390,12 +400,97 @@ func (cleaner *LocalBackendCleaner) RunGC(ctx context.Context, options RunGCOpti
 	return nil
 }
 
+type gcStep struct {
+	// Title is the log block title.
+	Title string
+	// Name is the step name in the cleanup report.
+	Name     string
+	ItemType cleanup_report.ItemType
+	Reason   string
+}
+
+// runGCStep runs the cleanup step, logs and reports the freed space and the deleted items.
+func (cleaner *LocalBackendCleaner) runGCStep(ctx context.Context, options RunGCOptions, vu *storageUsage, step gcStep, cleanup func() (cleanupReport, error)) error {
+	return logboek.Context(ctx).LogBlock(step.Title).DoError(func() error {
+		report, err := cleanup()
+		if err != nil {
+			return err
+		}
+
+		var spaceReclaimed uint64
+		if spaceReclaimed, *vu, err = cleaner.measureReclaimedSpace(ctx, options.StoragePath, *vu); err != nil {
+			return err
+		}
+		logboek.Context(ctx).LogF("Freed space: %s\n", utils.RedF("%s", humanize.Bytes(spaceReclaimed)))
+		logDeletedItems(ctx, report.ItemsDeleted)
+
+		options.Report.AddSpaceReclaimed(ctx, step.Name, spaceReclaimed)
+		for _, id := range report.ItemsDeleted {
+			options.Report.AddDeleted(ctx, cleanup_report.Item{Type: step.ItemType, ID: id, Reason: step.Reason})
+		}
+
+		return nil
+	})
+}
+
+// storageUsage is the storage volume usage along with the size of the storage driver directories.
+type storageUsage struct {
+	volumeutils.VolumeUsage
+	// DriverUsedBytes is measured for Buildah only, because its storage is usually placed on the volume
+	// shared with other data, so the volume usage does not show the space reclaimed by the cleanup.
+	DriverUsedBytes uint64
+}
+
+func (cleaner *LocalBackendCleaner) storageUsage(ctx context.Context, storagePath string) (storageUsage, error) {
+	vu, err := cleaner.volumeutilsGetVolumeUsageByPath(ctx, storagePath)
+	if err != nil {
+		return storageUsage{}, fmt.Errorf("error getting volume usage by path %q: %w", storagePath, err)
+	}
+
+	if cleaner.storageDriver == "" {
+		return storageUsage{VolumeUsage: vu}, nil
+	}
+
+	var driverUsedBytes uint64
+	for _, dir := range storageDriverDirs(storagePath, cleaner.storageDriver) {
+		if _, err := os.Stat(dir); errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+
+		size, err := cleaner.volumeutilsDirSizeBytes(dir)
+		if err != nil {
+			// The files are added and removed by the concurrent builds.
+			logboek.Context(ctx).Info().LogF("NOTE: Unable to measure %s storage driver usage, the volume usage is used instead: %s\n", cleaner.storageDriver, err)
+			return storageUsage{VolumeUsage: vu}, nil
+		}
+		driverUsedBytes += size
+	}
+
+	return storageUsage{VolumeUsage: vu, DriverUsedBytes: driverUsedBytes}, nil
+}
+
+// storageDriverDirs returns the directories of the containers/storage driver: the layers data and the layers,
+// images and containers metadata.
+func storageDriverDirs(storagePath, storageDriver string) []string {
+	return []string{
+		filepath.Join(storagePath, storageDriver),
+		filepath.Join(storagePath, storageDriver+"-layers"),
+		filepath.Join(storagePath, storageDriver+"-images"),
+		filepath.Join(storagePath, storageDriver+"-containers"),
+	}
+}
+
 // measureReclaimedSpace gets the actual disk state, calculates the factual
 // freed space relative to vuBefore and returns this volume along with the new state.
-func (cleaner *LocalBackendCleaner) measureReclaimedSpace(ctx context.Context, storagePath string, vuBefore volumeutils.VolumeUsage) (uint64, volumeutils.VolumeUsage, error) {
-	vuAfter, err := cleaner.volumeutilsGetVolumeUsageByPath(ctx, storagePath)
+// The freed space is measured by the storage driver directories if both states have them.
+func (cleaner *LocalBackendCleaner) measureReclaimedSpace(ctx context.Context, storagePath string, vuBefore storageUsage) (uint64, storageUsage, error) {
+	vuAfter, err := cleaner.storageUsage(ctx, storagePath)
 	if err != nil {
-		return 0, volumeutils.VolumeUsage{}, fmt.Errorf("error getting volume usage by path %q: %w", storagePath, err)
+		return 0, storageUsage{}, err
+	}
+
+	if vuBefore.DriverUsedBytes > 0 && vuAfter.DriverUsedBytes > 0 {
+		return uint64(math.Max(float64(vuBefore.DriverUsedBytes)-float64(vuAfter.DriverUsedBytes), 0)), vuAfter, nil
 	}
 
 	spaceReclaimed := uint64(math.Max(float64(vuBefore.UsedBytes)-float64(vuAfter.UsedBytes), 0))
@@ -403,6 +498,13 @@ func (cleaner *LocalBackendCleaner) measureReclaimedSpace(ctx context.Context, s
 	return spaceReclaimed, vuAfter, nil
 }
 
+func (cleaner *LocalBackendCleaner) volumeItemType() cleanup_report.ItemType {
+	if cleaner.backendType == containerBackendBuildah {
+		return cleanup_report.ItemTypeLocalLayer
+	}
+	return cleanup_report.ItemTypeLocalVolume
+}
+
 // pruneImages removes werf dangling images
 func (cleaner *LocalBackendCleaner) pruneImages(ctx context.Context, options RunGCOptions) (cleanupReport, error) {
 	filters := filter.FilterList{
@@ -505,6 +607,43 @@ func (cleaner *LocalBackendCleaner) cleanupWerfContainers(ctx context.Context, o
 	return report.Normalize(), nil
 }
 
+// cleanupStaleBuildahContainers removes the Buildah working containers left behind by the interrupted builds.
+// The containers in use are locked by werf, the age threshold protects the containers of the older werf versions.
+func (cleaner *LocalBackendCleaner) cleanupStaleBuildahContainers(ctx context.Context, options RunGCOptions) (cleanupReport, error) {
+	containers, err := cleaner.backend.Containers(ctx, buildContainersOptions())
+	if err != nil {
+		return cleanupReport{}, fmt.Errorf("cannot get Buildah working containers: %w", err)
+	}
+
+	report := cleanupReport{
+		ItemsDeleted: make([]string, 0, len(containers)),
+	}
+
+	staleBefore := time.Now().Add(-staleBuildahContainerAge)
+
+	for _, container := range containers {
+		containerName := buildahContainerName(container)
+		if containerName == "" || container.Created.IsZero() || container.Created.After(staleBefore) {
+			continue
+		}
+
+		if ok, err := cleaner.isLocked(container_backend.ContainerLockName(containerName)); err != nil {
+			return cleanupReport{}, fmt.Errorf("checking lock %q: %w", container_backend.ContainerLockName(containerName), err)
+		} else if ok {
+			continue
+		}
+
+		if err := cleaner.removeContainerRef(ctx, container.ID, options); err != nil {
+			logboek.Context(ctx).Info().LogF("Cannot remove container by id %q: %s\n", container.ID, err)
+			continue
+		}
+
+		report.ItemsDeleted = append(report.ItemsDeleted, container.ID)
+	}
+
+	return report.Normalize(), nil
+}
+
 func (cleaner *LocalBackendCleaner) cleanupWerfImages(ctx context.Context, options RunGCOptions, targetVolumeUsageBytes uint64) (cleanupReport, error) {
 	images, err := cleaner.werfImages(ctx)
 	if err != nil {
@@ -5,6 +5,8 @@ import (
 	"errors"
 	"fmt"
 	"math"
+	"os"
+	"path/filepath"
 	"time"
 
 	. "github.com/onsi/ginkgo/v2"
@@ -15,6 +17,7 @@ import (
 
 	"github.com/werf/common-go/pkg/util"
 	"github.com/werf/lockgate"
+	"github.com/werf/werf/v2/pkg/cleanup_report"
 	"github.com/werf/werf/v2/pkg/container_backend"
 	"github.com/werf/werf/v2/pkg/container_backend/filter"
 	"github.com/werf/werf/v2/pkg/container_backend/info"
@@ -265,6 +268,64 @@ var _ = Describe("LocalBackendCleaner", func() {
 		),
 	)
 
+	Describe("cleanupStaleBuildahContainers", func() {
+		It("should remove only the unlocked Buildah working containers older than the threshold", func(ctx context.Context) {
+			ctx = logging.WithLogger(ctx)
+
+			containers := image.ContainerList{
+				{ID: "stale", Names: []string{image.BuildahContainerNamePrefix + "stale"}, Created: time.Now().Add(-2 * staleBuildahContainerAge)},
+				{ID: "locked", Names: []string{image.BuildahContainerNamePrefix + "locked"}, Created: time.Now().Add(-2 * staleBuildahContainerAge)},
+				{ID: "recent", Names: []string{image.BuildahContainerNamePrefix + "recent"}, Created: time.Now()},
+				{ID: "foreign", Names: []string{"foreign"}, Created: time.Now().Add(-2 * staleBuildahContainerAge)},
+			}
+
+			backend.EXPECT().Containers(ctx, buildContainersOptions()).Return(containers, nil)
+
+			locker.EXPECT().
+				Acquire(container_backend.ContainerLockName(image.BuildahContainerNamePrefix+"stale"), lockgate.AcquireOptions{NonBlocking: true}).
+				Return(true, lockgate.LockHandle{}, nil)
+			locker.EXPECT().Release(lockgate.LockHandle{}).Return(nil)
+			locker.EXPECT().
+				Acquire(container_backend.ContainerLockName(image.BuildahContainerNamePrefix+"locked"), lockgate.AcquireOptions{NonBlocking: true}).
+				Return(false, lockgate.LockHandle{}, nil)
+
+			backend.EXPECT().Rm(ctx, "stale", container_backend.RmOpts{}).Return(nil)
+
+			report, err := cleaner.cleanupStaleBuildahContainers(ctx, RunGCOptions{})
+			Expect(err).To(Succeed())
+			Expect(report).To(Equal(cleanupReport{ItemsDeleted: []string{"stale"}}))
+		})
+	})
+
+	Describe("measureReclaimedSpace", func() {
+		It("should measure the freed space by the storage driver directories", func(ctx SpecContext) {
+			storagePath := t.TempDir()
+			Expect(os.MkdirAll(filepath.Join(storagePath, "overlay"), os.ModePerm)).To(Succeed())
+			Expect(os.MkdirAll(filepath.Join(storagePath, "overlay-layers"), os.ModePerm)).To(Succeed())
+
+			cleaner.storageDriver = "overlay"
+			stubs.StubFunc(&cleaner.volumeutilsGetVolumeUsageByPath, volumeutils.VolumeUsage{UsedBytes: 500, TotalBytes: 1000}, nil)
+			stubs.StubFunc(&cleaner.volumeutilsDirSizeBytes, uint64(100), nil)
+
+			spaceReclaimed, vu, err := cleaner.measureReclaimedSpace(ctx, storagePath, storageUsage{
+				VolumeUsage:     volumeutils.VolumeUsage{UsedBytes: 500, TotalBytes: 1000},
+				DriverUsedBytes: 350,
+			})
+			Expect(err).To(Succeed())
+			Expect(spaceReclaimed).To(Equal(uint64(150)))
+			Expect(vu.DriverUsedBytes).To(Equal(uint64(200)))
+		})
+		It("should measure the freed space by the volume usage without the storage driver", func(ctx SpecContext) {
+			stubs.StubFunc(&cleaner.volumeutilsGetVolumeUsageByPath, volumeutils.VolumeUsage{UsedBytes: 400, TotalBytes: 1000}, nil)
+
+			spaceReclaimed, _, err := cleaner.measureReclaimedSpace(ctx, t.TempDir(), storageUsage{
+				VolumeUsage: volumeutils.VolumeUsage{UsedBytes: 500, TotalBytes: 1000},
+			})
+			Expect(err).To(Succeed())
+			Expect(spaceReclaimed).To(Equal(uint64(100)))
+		})
+	})
+
 	Describe("werfImages", func() {
 		It("should return images as merged and sorted result of several backend calls", func(ctx context.Context) {
 			ctx = logging.WithLogger(ctx)
@@ -564,8 +625,18 @@ var _ = Describe("LocalBackendCleaner", func() {
 				}).Return(nil),
 			)
 
+			report := cleanup_report.NewHostReport(ctx, "host cleanup", false)
+			options.Report = report
+
 			err := cleaner.RunGC(ctx, options)
 			Expect(err).To(Succeed())
+
+			Expect(report.Deleted).To(Equal([]cleanup_report.Item{
+				{Type: cleanup_report.ItemTypeLocalImage, ID: "one", Reason: "least recently used"},
+			}))
+			Expect(lo.Map(report.SpaceReclaimed, func(item cleanup_report.SpaceReclaimed, _ int) string {
+				return item.Step
+			})).To(Equal([]string{"volumes", "danglingImages", "werfContainers", "werfImages"}))
 		})
 	})
 })
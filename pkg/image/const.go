@@ -20,6 +20,11 @@ const (
 	WerfCustomTagMetadataStageIDLabel = "stage-id"
 	WerfCustomTagMetadataTag          = "tag"
 
+	WerfVulnerabilityScanMetadataImageDigestLabel = "image-digest"
+	WerfVulnerabilityScanMetadataScannerLabel     = "scanner"
+	WerfVulnerabilityScanMetadataSummaryLabel     = "summary"
+	WerfVulnerabilityScanMetadataScannedAtLabel   = "scanned-at"
+
 	WerfMountTmpDirLabel          = "werf-mount-type-tmp-dir"
 	WerfMountBuildDirLabel        = "werf-mount-type-build-dir"
 	WerfMountCustomDirLabelPrefix = "werf-mount-type-custom-dir-"
@@ -29,4 +34,5 @@ const (
 	StageContainerNamePrefix        = "werf.build."
 	ImportServerContainerNamePrefix = "import-server-"
 	AssemblingContainerNamePrefix   = "werf.stapel."
+	BuildahContainerNamePrefix      = "werf-"
 )
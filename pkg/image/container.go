@@ -1,9 +1,12 @@
 package image
 
+import "time"
+
 type Container struct {
 	ID      string
 	ImageID string
 	Names   []string
+	Created time.Time
 }
 
 func (container Container) LogName() string {
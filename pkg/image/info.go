@@ -23,6 +23,7 @@ type Info struct {
 
 	OnBuild           []string            `json:"onBuild"`
 	Env               []string            `json:"env"`
+	User              *string             `json:"user,omitempty"`
 	ID                string              `json:"ID"`
 	ParentID          string              `json:"parentID"`
 	Labels            map[string]string   `json:"labels"`
@@ -67,6 +68,7 @@ func (info *Info) GetCopy() *Info {
 		RepoDigest:        info.RepoDigest,
 		OnBuild:           util.CopyArr(info.OnBuild),
 		Env:               util.CopyArr(info.Env),
+		User:              copyStringPtr(info.User),
 		ID:                info.ID,
 		ParentID:          info.ParentID,
 		Labels:            util.CopyMap(info.Labels),
@@ -128,3 +130,11 @@ func ExtractRepoDigest(inspectRepoDigests []string, repository string) string {
 	}
 	return ""
 }
+
+func copyStringPtr(s *string) *string {
+	if s == nil {
+		return nil
+	}
+	res := *s
+	return &res
+}
@@ -16,10 +16,15 @@ import (
 	"github.com/werf/werf/v2/pkg/werf"
 )
 
+// Before changing: read the local_cache contract in the package doc of pkg/git_repo/gitdata.
 const (
 	ManifestCacheVersion = "5"
 )
 
+func GetManifestCacheDir() string {
+	return filepath.Join(werf.GetLocalCacheDir(), "manifests", ManifestCacheVersion)
+}
+
 type ManifestCache struct {
 	CacheDir string
 }
@@ -156,7 +161,7 @@ func (cache *ManifestCache) constructFilePathForImage(storageName, imageName str
 }
 
 func (cache *ManifestCache) lock(ctx context.Context, storageName, imageName string) (lockgate.LockHandle, error) {
-	lockName := fmt.Sprintf("manifest_cache.%s.%s", slug.Slug(storageName), imageName)
+	lockName := ManifestCacheLockName(slug.Slug(storageName), imageName)
 	if _, lock, err := werf.HostLocker().AcquireLock(ctx, lockName, lockgate.AcquireOptions{}); err != nil {
 		return lockgate.LockHandle{}, fmt.Errorf("cannot acquire %s host lock: %w", lockName, err)
 	} else {
@@ -170,3 +175,9 @@ func (cache *ManifestCache) unlock(lock lockgate.LockHandle) error {
 	}
 	return nil
 }
+
+// ManifestCacheLockName returns the name of the lock the record of the image is accessed under, storageDirName is
+// the name of the record dir of the storage.
+func ManifestCacheLockName(storageDirName, imageName string) string {
+	return fmt.Sprintf("manifest_cache.%s.%s", storageDirName, imageName)
+}
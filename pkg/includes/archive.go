@@ -0,0 +1,405 @@
+package includes
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/google/go-containerregistry/pkg/authn"
+	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+
+	"github.com/werf/common-go/pkg/util"
+	"github.com/werf/logboek"
+	"github.com/werf/werf/v2/pkg/werf"
+)
+
+const (
+	archivesCacheVersion = "1"
+	archiveFilesDirName  = "files"
+
+	ociImageTitleAnnotation = "org.opencontainers.image.title"
+
+	// httpArchiveDownloadTimeout limits the whole download of the HTTP archive including the body.
+	httpArchiveDownloadTimeout = 10 * time.Minute
+)
+
+var httpArchiveClient = &http.Client{Timeout: httpArchiveDownloadTimeout}
+
+type archiveType string
+
+const (
+	archiveTypeOCI  archiveType = "oci"
+	archiveTypeHTTP archiveType = "http"
+)
+
+func getArchivesCacheDir() string {
+	return filepath.Join(werf.GetLocalCacheDir(), "includes_archives", archivesCacheVersion)
+}
+
+// archiveRepository provides access to the files of the include distributed as an OCI artifact or an HTTP archive.
+// The archive is pinned by the digest, which is used in place of the commit of the git include.
+type archiveRepository struct {
+	archiveType archiveType
+	source      string
+	checksum    string
+}
+
+func newArchiveRepository(i includeConf) *archiveRepository {
+	switch {
+	case i.OCI != "":
+		return &archiveRepository{archiveType: archiveTypeOCI, source: i.OCI}
+	case i.HTTP != "":
+		return &archiveRepository{archiveType: archiveTypeHTTP, source: i.HTTP, checksum: i.Checksum}
+	default:
+		panic(fmt.Sprintf("unexpected include source %q", i.Source()))
+	}
+}
+
+func (a *archiveRepository) GetName() string {
+	return a.source
+}
+
+// resolveDigest returns the digest of the latest archive version: the manifest digest for OCI artifact and the checksum from the config for HTTP archive.
+func (a *archiveRepository) resolveDigest(ctx context.Context) (string, error) {
+	switch a.archiveType {
+	case archiveTypeOCI:
+		ref, err := name.ParseReference(a.source)
+		if err != nil {
+			return "", fmt.Errorf("unable to parse reference %q: %w", a.source, err)
+		}
+
+		if digestRef, ok := ref.(name.Digest); ok {
+			return digestRef.DigestStr(), nil
+		}
+
+		desc, err := remote.Head(ref, ociRemoteOptions(ctx)...)
+		if err != nil {
+			return "", fmt.Errorf("unable to get manifest of %q: %w", a.source, err)
+		}
+
+		return desc.Digest.String(), nil
+	case archiveTypeHTTP:
+		return a.checksum, nil
+	default:
+		panic(fmt.Sprintf("unexpected archive type %q", a.archiveType))
+	}
+}
+
+// fetch downloads and extracts the archive with the digest into the cache if it has not been done before.
+func (a *archiveRepository) fetch(ctx context.Context, digest string) error {
+	dir, err := a.dir(digest)
+	if err != nil {
+		return err
+	}
+
+	exist, err := util.DirExists(dir)
+	if err != nil {
+		return fmt.Errorf("unable to check existence of %q: %w", dir, err)
+	}
+	if exist {
+		return nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(dir), os.ModePerm); err != nil {
+		return fmt.Errorf("unable to create dir %q: %w", filepath.Dir(dir), err)
+	}
+
+	tmpDir, err := os.MkdirTemp(filepath.Dir(dir), "tmp-")
+	if err != nil {
+		return fmt.Errorf("unable to create tmp dir: %w", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	if err := os.MkdirAll(filepath.Join(tmpDir, archiveFilesDirName), os.ModePerm); err != nil {
+		return fmt.Errorf("unable to create dir: %w", err)
+	}
+
+	if err := logboek.Context(ctx).Default().LogProcess("Downloading %s", a.source).DoError(func() error {
+		switch a.archiveType {
+		case archiveTypeOCI:
+			return a.fetchOCI(ctx, digest, tmpDir)
+		case archiveTypeHTTP:
+			return a.fetchHTTP(ctx, digest, tmpDir)
+		default:
+			panic(fmt.Sprintf("unexpected archive type %q", a.archiveType))
+		}
+	}); err != nil {
+		return err
+	}
+
+	if err := os.Rename(tmpDir, dir); err != nil {
+		if exist, _ := util.DirExists(dir); exist {
+			// Extracted concurrently by another process.
+			return nil
+		}
+		return fmt.Errorf("unable to rename %q to %q: %w", tmpDir, dir, err)
+	}
+
+	return nil
+}
+
+func (a *archiveRepository) fetchOCI(ctx context.Context, digest, dstDir string) error {
+	ref, err := name.ParseReference(a.source)
+	if err != nil {
+		return fmt.Errorf("unable to parse reference %q: %w", a.source, err)
+	}
+	digestRef := ref.Context().Digest(digest)
+
+	desc, err := remote.Get(digestRef, ociRemoteOptions(ctx)...)
+	if err != nil {
+		return fmt.Errorf("unable to get manifest %q: %w", digestRef, err)
+	}
+
+	manifest, err := v1.ParseManifest(bytes.NewReader(desc.Manifest))
+	if err != nil {
+		return fmt.Errorf("unable to parse manifest %q: %w", digestRef, err)
+	}
+
+	for _, layerDesc := range manifest.Layers {
+		if err := a.fetchOCILayer(ctx, ref.Context(), layerDesc, filepath.Join(dstDir, archiveFilesDirName)); err != nil {
+			return fmt.Errorf("unable to fetch layer %s of %q: %w", layerDesc.Digest, digestRef, err)
+		}
+	}
+
+	return nil
+}
+
+func (a *archiveRepository) fetchOCILayer(ctx context.Context, repo name.Repository, layerDesc v1.Descriptor, dstDir string) error {
+	layer, err := remote.Layer(repo.Digest(layerDesc.Digest.String()), ociRemoteOptions(ctx)...)
+	if err != nil {
+		return err
+	}
+
+	rc, err := layer.Compressed()
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+
+	if strings.Contains(string(layerDesc.MediaType), "tar") {
+		return extractArchive(rc, dstDir)
+	}
+
+	// Single file layer, e.g. pushed with `oras push registry/repo:tag file.yaml`.
+	title := layerDesc.Annotations[ociImageTitleAnnotation]
+	if title == "" {
+		return fmt.Errorf("unsupported layer media type %q without %s annotation", layerDesc.MediaType, ociImageTitleAnnotation)
+	}
+
+	return writeArchiveFile(rc, dstDir, title, 0o644)
+}
+
+func (a *archiveRepository) fetchHTTP(ctx context.Context, digest, dstDir string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, a.source, nil)
+	if err != nil {
+		return fmt.Errorf("unable to create request: %w", err)
+	}
+	req.Header.Set("User-Agent", werf.UserAgent)
+
+	resp, err := httpArchiveClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("unable to download %q: %w", a.source, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unable to download %q: unexpected status %s", a.source, resp.Status)
+	}
+
+	archiveFile, err := os.CreateTemp(dstDir, "archive-")
+	if err != nil {
+		return fmt.Errorf("unable to create tmp file: %w", err)
+	}
+	defer os.Remove(archiveFile.Name())
+	defer archiveFile.Close()
+
+	hash := sha256.New()
+	if _, err := io.Copy(io.MultiWriter(archiveFile, hash), resp.Body); err != nil {
+		return fmt.Errorf("unable to download %q: %w", a.source, err)
+	}
+
+	if gotDigest := "sha256:" + hex.EncodeToString(hash.Sum(nil)); gotDigest != digest {
+		return fmt.Errorf("checksum mismatch for %q: expected %s, got %s", a.source, digest, gotDigest)
+	}
+
+	if _, err := archiveFile.Seek(0, io.SeekStart); err != nil {
+		return fmt.Errorf("unable to seek %q: %w", archiveFile.Name(), err)
+	}
+
+	if err := extractArchive(archiveFile, filepath.Join(dstDir, archiveFilesDirName)); err != nil {
+		return fmt.Errorf("unable to extract %q: %w", a.source, err)
+	}
+
+	return nil
+}
+
+func (a *archiveRepository) dir(digest string) (string, error) {
+	algo, hexDigest, ok := strings.Cut(digest, ":")
+	if _, err := hex.DecodeString(hexDigest); !ok || algo != "sha256" || len(hexDigest) != sha256.Size*2 || err != nil {
+		return "", fmt.Errorf("unexpected digest %q of %q: expected sha256:<hex>", digest, a.source)
+	}
+
+	return filepath.Join(getArchivesCacheDir(), string(a.archiveType), hexDigest), nil
+}
+
+func (a *archiveRepository) filesDir(digest string) (string, error) {
+	dir, err := a.dir(digest)
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(dir, archiveFilesDirName), nil
+}
+
+// listFiles returns the slash-separated paths of the archive files relative to the archive root.
+func (a *archiveRepository) listFiles(digest string) ([]string, error) {
+	dir, err := a.filesDir(digest)
+	if err != nil {
+		return nil, err
+	}
+
+	var files []string
+	if err := filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if d.Type().IsRegular() {
+			files = append(files, filepath.ToSlash(util.GetRelativeToBaseFilepath(dir, p)))
+		}
+
+		return nil
+	}); err != nil {
+		return nil, fmt.Errorf("unable to walk %q: %w", dir, err)
+	}
+
+	return files, nil
+}
+
+func (a *archiveRepository) ReadCommitFile(_ context.Context, digest, relPath string) ([]byte, error) {
+	p, err := a.filePath(digest, relPath)
+	if err != nil {
+		return nil, err
+	}
+
+	return os.ReadFile(p)
+}
+
+func (a *archiveRepository) IsCommitFileExist(_ context.Context, digest, relPath string) (bool, error) {
+	p, err := a.filePath(digest, relPath)
+	if err != nil {
+		return false, err
+	}
+
+	return util.RegularFileExists(p)
+}
+
+func (a *archiveRepository) IsCommitDirectoryExist(_ context.Context, digest, relPath string) (bool, error) {
+	p, err := a.filePath(digest, relPath)
+	if err != nil {
+		return false, err
+	}
+
+	return util.DirExists(p)
+}
+
+func (a *archiveRepository) filePath(digest, relPath string) (string, error) {
+	dir, err := a.filesDir(digest)
+	if err != nil {
+		return "", err
+	}
+
+	cleanRelPath := path.Clean("/" + filepath.ToSlash(relPath))
+
+	return filepath.Join(dir, filepath.FromSlash(cleanRelPath)), nil
+}
+
+func ociRemoteOptions(ctx context.Context) []remote.Option {
+	return []remote.Option{
+		remote.WithContext(ctx),
+		remote.WithAuthFromKeychain(authn.DefaultKeychain),
+		remote.WithUserAgent(werf.UserAgent),
+	}
+}
+
+// extractArchive extracts regular files and directories of the tar or tar.gz archive.
+// PAX headers (git archive starts the archive with the global one) and symlinks are skipped, hard links and entries
+// pointing outside the destination directory are rejected, because the archive comes from the remote source.
+func extractArchive(r io.Reader, dstDir string) error {
+	br := bufio.NewReader(r)
+
+	var archiveReader io.Reader = br
+	if magic, err := br.Peek(2); err == nil && magic[0] == 0x1f && magic[1] == 0x8b {
+		gzipReader, err := gzip.NewReader(br)
+		if err != nil {
+			return fmt.Errorf("unable to read gzip: %w", err)
+		}
+		defer gzipReader.Close()
+
+		archiveReader = gzipReader
+	}
+
+	tarReader := tar.NewReader(archiveReader)
+	for {
+		header, err := tarReader.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("unable to read tar: %w", err)
+		}
+
+		switch header.Typeflag {
+		case tar.TypeDir, tar.TypeXGlobalHeader, tar.TypeXHeader, tar.TypeSymlink:
+			continue
+		case tar.TypeReg:
+			if err := writeArchiveFile(tarReader, dstDir, header.Name, header.FileInfo().Mode().Perm()); err != nil {
+				return err
+			}
+		default:
+			return fmt.Errorf("tar entry %q of unsupported type %q: only regular files, directories and symlinks are allowed", header.Name, header.Typeflag)
+		}
+	}
+
+	return nil
+}
+
+func writeArchiveFile(r io.Reader, dstDir, name string, perm os.FileMode) error {
+	cleanName := path.Clean(filepath.ToSlash(name))
+	if path.IsAbs(cleanName) || cleanName == ".." || strings.HasPrefix(cleanName, "../") || cleanName == "." {
+		return fmt.Errorf("archive entry %q is outside of the archive root", name)
+	}
+
+	p := filepath.Join(dstDir, filepath.FromSlash(cleanName))
+	if err := os.MkdirAll(filepath.Dir(p), os.ModePerm); err != nil {
+		return fmt.Errorf("unable to create dir %q: %w", filepath.Dir(p), err)
+	}
+
+	f, err := os.OpenFile(p, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, perm)
+	if err != nil {
+		return fmt.Errorf("unable to create file %q: %w", p, err)
+	}
+	defer f.Close()
+
+	if _, err := io.Copy(f, r); err != nil {
+		return fmt.Errorf("unable to write file %q: %w", p, err)
+	}
+
+	return nil
+}
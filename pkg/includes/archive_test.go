@@ -0,0 +1,218 @@
+package includes
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func makeTarGz(t *testing.T, entries []tar.Header, contents []string) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+	gw := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gw)
+	for i, header := range entries {
+		header.Size = int64(len(contents[i]))
+		if err := tw.WriteHeader(&header); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tw.Write([]byte(contents[i])); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := gw.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	return buf.Bytes()
+}
+
+func TestExtractArchive(t *testing.T) {
+	dstDir := t.TempDir()
+	data := makeTarGz(t,
+		[]tar.Header{
+			{Name: "templates/", Typeflag: tar.TypeDir, Mode: 0o755},
+			{Name: "templates/werf.yaml", Typeflag: tar.TypeReg, Mode: 0o644},
+		},
+		[]string{"", "project: app\n"},
+	)
+
+	if err := extractArchive(bytes.NewReader(data), dstDir); err != nil {
+		t.Fatalf("extractArchive() error = %v", err)
+	}
+
+	got, err := os.ReadFile(filepath.Join(dstDir, "templates", "werf.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != "project: app\n" {
+		t.Errorf("extracted file content = %q, want %q", got, "project: app\n")
+	}
+}
+
+func TestExtractArchiveRejectsUnsafeEntries(t *testing.T) {
+	tests := []struct {
+		name    string
+		header  tar.Header
+		wantErr string
+	}{
+		{
+			name:    "parent dir",
+			header:  tar.Header{Name: "../evil", Typeflag: tar.TypeReg, Mode: 0o644},
+			wantErr: "outside of the archive root",
+		},
+		{
+			name:    "absolute path",
+			header:  tar.Header{Name: "/etc/evil", Typeflag: tar.TypeReg, Mode: 0o644},
+			wantErr: "outside of the archive root",
+		},
+		{
+			name:    "hard link",
+			header:  tar.Header{Name: "link", Typeflag: tar.TypeLink, Linkname: "/etc/passwd"},
+			wantErr: "unsupported type",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := makeTarGz(t, []tar.Header{tt.header}, []string{""})
+
+			err := extractArchive(bytes.NewReader(data), t.TempDir())
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("extractArchive() error = %v, want error containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestExtractArchiveSkipsSymlinks(t *testing.T) {
+	dstDir := t.TempDir()
+	data := makeTarGz(t,
+		[]tar.Header{{Name: "link", Typeflag: tar.TypeSymlink, Linkname: "/etc/passwd"}},
+		[]string{""},
+	)
+
+	if err := extractArchive(bytes.NewReader(data), dstDir); err != nil {
+		t.Fatalf("extractArchive() error = %v", err)
+	}
+
+	if _, err := os.Lstat(filepath.Join(dstDir, "link")); !os.IsNotExist(err) {
+		t.Errorf("symlink is extracted: Lstat() error = %v", err)
+	}
+}
+
+func TestExtractArchiveOfGitArchive(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not available")
+	}
+
+	repoDir := t.TempDir()
+	git := func(args ...string) []byte {
+		t.Helper()
+
+		cmd := exec.Command("git", append([]string{"-C", repoDir, "-c", "user.name=werf", "-c", "user.email=werf@example.com"}, args...)...)
+		out, err := cmd.Output()
+		if err != nil {
+			t.Fatalf("git %v: %v", args, err)
+		}
+
+		return out
+	}
+
+	if err := os.MkdirAll(filepath.Join(repoDir, "templates"), os.ModePerm); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(repoDir, "templates", "werf.yaml"), []byte("project: app\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink("templates/werf.yaml", filepath.Join(repoDir, "werf.yaml")); err != nil {
+		t.Fatal(err)
+	}
+
+	git("init", "-q")
+	git("add", "-A")
+	git("commit", "-q", "-m", "init")
+
+	// The archive starts with the pax_global_header entry holding the commit id.
+	data := git("archive", "--format=tar.gz", "--prefix=app/", "HEAD")
+
+	dstDir := t.TempDir()
+	if err := extractArchive(bytes.NewReader(data), dstDir); err != nil {
+		t.Fatalf("extractArchive() error = %v", err)
+	}
+
+	got, err := os.ReadFile(filepath.Join(dstDir, "app", "templates", "werf.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != "project: app\n" {
+		t.Errorf("extracted file content = %q, want %q", got, "project: app\n")
+	}
+
+	if _, err := os.Lstat(filepath.Join(dstDir, "app", "werf.yaml")); !os.IsNotExist(err) {
+		t.Errorf("symlink is extracted: Lstat() error = %v", err)
+	}
+}
+
+func TestValidateArchiveInclude(t *testing.T) {
+	const checksum = "sha256:0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"
+
+	tests := []struct {
+		name    string
+		include includeConf
+		wantErr string
+	}{
+		{
+			name:    "oci",
+			include: includeConf{OCI: "registry.example.com/shared-templates:1.4", Add: "/", To: "/"},
+		},
+		{
+			name:    "http",
+			include: includeConf{HTTP: "https://example.com/templates.tar.gz", Checksum: checksum, Add: "/", To: "/"},
+		},
+		{
+			name:    "several sources",
+			include: includeConf{Git: "https://example.com/repo.git", OCI: "registry.example.com/shared-templates:1.4", Add: "/", To: "/"},
+			wantErr: "specify only one of",
+		},
+		{
+			name:    "http without checksum",
+			include: includeConf{HTTP: "https://example.com/templates.tar.gz", Add: "/", To: "/"},
+			wantErr: "`checksum` field is required",
+		},
+		{
+			name:    "http with invalid checksum",
+			include: includeConf{HTTP: "https://example.com/templates.tar.gz", Checksum: "md5:123", Add: "/", To: "/"},
+			wantErr: "sha256:<hex>",
+		},
+		{
+			name:    "oci with tag",
+			include: includeConf{OCI: "registry.example.com/shared-templates:1.4", Tag: "v1", Add: "/", To: "/"},
+			wantErr: "can be used only with `git` include",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validate(Config{Includes: []includeConf{tt.include}})
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("validate() error = %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("validate() error = %v, want error containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
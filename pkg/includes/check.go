@@ -0,0 +1,181 @@
+package includes
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+
+	"github.com/werf/logboek"
+	"github.com/werf/werf/v2/pkg/path_matcher"
+)
+
+// LockDrift describes the include which latest version differs from the version in the lock file.
+type LockDrift struct {
+	Source string
+	// Ref is the branch or tag of the git include, empty for archives.
+	Ref string
+	// Locked and Latest are the commits of the git include or the digests of the archive include.
+	// Locked is empty if the include is missing in the lock file.
+	Locked string
+	Latest string
+	// ChangedFiles are the upstream paths matched by the include rules that changed between the locked and the latest commits.
+	// It is always empty for archives.
+	ChangedFiles []string
+	// ChangedFilesUnknown is set when the changed files cannot be calculated, e.g. when the locked commit is missing upstream after force push.
+	ChangedFilesUnknown bool
+}
+
+type CheckLockOptions struct {
+	FileReader GiterminismManagerFileReader
+}
+
+// CheckLock compares the lock file with the latest versions of the includes without changing the lock file.
+func CheckLock(ctx context.Context, opts CheckLockOptions) ([]*LockDrift, error) {
+	config, err := NewConfig(ctx, opts.FileReader, GetWerfIncludesConfigRelPath(), true)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read includes config: %w", err)
+	}
+
+	lockConf, err := parseLockConfig(ctx, opts.FileReader, GetWerfIncludesLockConfigRelPath())
+	if err != nil {
+		return nil, err
+	}
+
+	lockInfo, err := readLockInfo(lockConf)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read include lock info: %w", err)
+	}
+
+	remoteRepos, err := initRemoteRepos(ctx, config)
+	if err != nil {
+		return nil, fmt.Errorf("unable to initialize remote repositories: %w", err)
+	}
+
+	latestLockConf, err := createLockConfig(ctx, createLockConfigOptions{
+		includesConfig: config,
+		remoteRepos:    remoteRepos,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("create lock config: %w", err)
+	}
+
+	var drifts []*LockDrift
+	for _, latest := range latestLockConf.IncludeLock {
+		ref, err := latest.Ref()
+		if err != nil {
+			return nil, fmt.Errorf("unable to get ref for include %s: %w", latest.Source(), err)
+		}
+
+		locked := lockInfo.includeToCommitMapper[lockId(latest.Source(), ref)]
+		if locked == latest.pin() {
+			continue
+		}
+
+		drift := &LockDrift{
+			Source: latest.Source(),
+			Ref:    ref,
+			Locked: locked,
+			Latest: latest.pin(),
+		}
+
+		if !latest.isArchive() && locked != "" {
+			changedFiles, err := changedIncludeFiles(remoteRepos, config, latest.Git, ref, locked, latest.Commit)
+			if err != nil {
+				logboek.Context(ctx).Warn().LogF("WARNING: unable to get changed files of include %s: %s\n", latest.Source(), err)
+				drift.ChangedFilesUnknown = true
+			} else {
+				drift.ChangedFiles = changedFiles
+			}
+		}
+
+		drifts = append(drifts, drift)
+	}
+
+	return drifts, nil
+}
+
+// changedIncludeFiles returns the paths changed between the commits, which are matched by any include with the same repository and ref.
+func changedIncludeFiles(remoteRepos *gitRepositoriesWithCache, config Config, git, ref, fromCommit, toCommit string) ([]string, error) {
+	var matchers []path_matcher.PathMatcher
+	for _, inc := range config.Includes {
+		if inc.isArchive() || inc.Git != git {
+			continue
+		}
+
+		if incRef, err := inc.Ref(); err != nil || incRef != ref {
+			continue
+		}
+
+		matchers = append(matchers, path_matcher.NewPathMatcher(path_matcher.PathMatcherOptions{
+			BasePath:     inc.Add,
+			IncludeGlobs: inc.IncludePaths,
+			ExcludeGlobs: inc.ExcludePaths,
+		}))
+	}
+
+	r, err := remoteRepos.getRepository(git)
+	if err != nil {
+		return nil, err
+	}
+
+	repo, err := r.repo.PlainOpen()
+	if err != nil {
+		return nil, fmt.Errorf("plain open: %w", err)
+	}
+
+	fromTree, err := commitTree(repo.CommitObject, fromCommit)
+	if err != nil {
+		return nil, err
+	}
+
+	toTree, err := commitTree(repo.CommitObject, toCommit)
+	if err != nil {
+		return nil, err
+	}
+
+	changes, err := object.DiffTree(fromTree, toTree)
+	if err != nil {
+		return nil, fmt.Errorf("unable to diff commits %s and %s: %w", fromCommit, toCommit, err)
+	}
+
+	changedFilesSet := map[string]bool{}
+	for _, change := range changes {
+		for _, name := range []string{change.From.Name, change.To.Name} {
+			if name == "" {
+				continue
+			}
+
+			for _, matcher := range matchers {
+				if matcher.IsPathMatched(name) {
+					changedFilesSet[name] = true
+					break
+				}
+			}
+		}
+	}
+
+	changedFiles := make([]string, 0, len(changedFilesSet))
+	for name := range changedFilesSet {
+		changedFiles = append(changedFiles, name)
+	}
+	sort.Strings(changedFiles)
+
+	return changedFiles, nil
+}
+
+func commitTree(commitObject func(plumbing.Hash) (*object.Commit, error), commit string) (*object.Tree, error) {
+	c, err := commitObject(plumbing.NewHash(commit))
+	if err != nil {
+		return nil, fmt.Errorf("unable to get commit %s: %w", commit, err)
+	}
+
+	tree, err := c.Tree()
+	if err != nil {
+		return nil, fmt.Errorf("unable to get tree of commit %s: %w", commit, err)
+	}
+
+	return tree, nil
+}
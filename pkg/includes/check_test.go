@@ -0,0 +1,92 @@
+package includes
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+type gitRepositoryStub struct {
+	GitRepository
+
+	repo *git.Repository
+}
+
+func (r *gitRepositoryStub) PlainOpen() (*git.Repository, error) {
+	return r.repo, nil
+}
+
+func commitFiles(t *testing.T, repo *git.Repository, dir string, files map[string]string) string {
+	t.Helper()
+
+	wt, err := repo.Worktree()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for path, content := range files {
+		absPath := filepath.Join(dir, path)
+		if err := os.MkdirAll(filepath.Dir(absPath), os.ModePerm); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(absPath, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := wt.Add(path); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	hash, err := wt.Commit("commit", &git.CommitOptions{
+		Author: &object.Signature{Name: "test", Email: "test@werf.io", When: time.Now()},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return hash.String()
+}
+
+func TestChangedIncludeFiles(t *testing.T) {
+	dir := t.TempDir()
+	repo, err := git.PlainInit(dir, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	lockedCommit := commitFiles(t, repo, dir, map[string]string{
+		"common/.werf/cleanup.tpl": "v1",
+		"common/.helm/values.yaml": "v1",
+		"common/README.md":         "v1",
+		"other/file.txt":           "v1",
+	})
+	latestCommit := commitFiles(t, repo, dir, map[string]string{
+		"common/.werf/cleanup.tpl": "v2",
+		"common/README.md":         "v2",
+		"other/file.txt":           "v2",
+	})
+
+	remoteRepos := newGitRepositoriesWithCache()
+	remoteRepos.repositories["https://example.com/repo.git"] = &gitRepository{repo: &gitRepositoryStub{repo: repo}}
+
+	config := Config{Includes: []includeConf{
+		{Git: "https://example.com/repo.git", Branch: "main", Add: "/common", To: "/", IncludePaths: []string{".werf", ".helm"}},
+		{Git: "https://example.com/repo.git", Branch: "feature", Add: "/other", To: "/"},
+	}}
+
+	got, err := changedIncludeFiles(remoteRepos, config, "https://example.com/repo.git", "main", lockedCommit, latestCommit)
+	if err != nil {
+		t.Fatalf("changedIncludeFiles() error = %v", err)
+	}
+
+	want := []string{"common/.werf/cleanup.tpl"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("changedIncludeFiles() = %v, want %v", got, want)
+	}
+
+}
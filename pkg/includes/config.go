@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"regexp"
 	"strings"
 
 	"github.com/go-git/go-git/v5"
@@ -21,6 +22,9 @@ type Config struct {
 
 type includeConf struct {
 	Git          string                         `yaml:"git"`
+	OCI          string                         `yaml:"oci,omitempty"`
+	HTTP         string                         `yaml:"http,omitempty"`
+	Checksum     string                         `yaml:"checksum,omitempty"`
 	BasicAuth    *git_repo.BasicAuthCredentials `yaml:"basicAuth,omitempty"`
 	Branch       string                         `yaml:"branch"`
 	Tag          string                         `yaml:"tag"`
@@ -31,7 +35,20 @@ type includeConf struct {
 	ExcludePaths []string                       `yaml:"excludePaths"`
 }
 
+// Source returns the git repository, the OCI artifact reference or the HTTP archive url of the include.
+func (i *includeConf) Source() string {
+	return source(i.Git, i.OCI, i.HTTP)
+}
+
+func (i *includeConf) isArchive() bool {
+	return i.OCI != "" || i.HTTP != ""
+}
+
+// Ref returns the git ref of the include. Archives are pinned by the digest and have no ref.
 func (i *includeConf) Ref() (string, error) {
+	if i.isArchive() {
+		return "", nil
+	}
 	return ref(i.Git, i.Commit, i.Tag, i.Branch)
 }
 
@@ -44,10 +61,13 @@ type lockConfig struct {
 }
 
 type includeLockConf struct {
-	Git    string `yaml:"git"`
+	Git    string `yaml:"git,omitempty"`
+	OCI    string `yaml:"oci,omitempty"`
+	HTTP   string `yaml:"http,omitempty"`
 	Branch string `yaml:"branch,omitempty"`
 	Tag    string `yaml:"tag,omitempty"`
-	Commit string `yaml:"commit"`
+	Commit string `yaml:"commit,omitempty"`
+	Digest string `yaml:"digest,omitempty"`
 }
 
 func NewConfig(ctx context.Context, fileReader GiterminismManagerFileReader, configRelPath string, createLockConfig bool) (Config, error) {
@@ -91,8 +111,14 @@ func parseConfig(ctx context.Context, fileReader GiterminismManagerFileReader, c
 
 func validate(config Config) error {
 	for _, include := range config.Includes {
-		if include.Git == "" {
-			return fmt.Errorf("`git` field is required")
+		if !exactlyOne([]bool{include.Git != "", include.OCI != "", include.HTTP != ""}) {
+			return fmt.Errorf("specify only one of `git`, `oci` or `http` fields")
+		}
+
+		if include.isArchive() {
+			if err := validateArchive(include); err != nil {
+				return err
+			}
 		}
 
 		if include.BasicAuth != nil {
@@ -107,30 +133,34 @@ func validate(config Config) error {
 		}
 
 		if include.Add == "" {
-			return fmt.Errorf("include %s: `add` field is required", include.Git)
+			return fmt.Errorf("include %s: `add` field is required", include.Source())
 		}
 		if !strings.HasPrefix(include.Add, "/") {
-			return fmt.Errorf("include %s: `add` must be an absolute path relative to the repository root", include.Git)
+			return fmt.Errorf("include %s: `add` must be an absolute path relative to the repository root", include.Source())
 		}
 		if include.To == "" {
-			return fmt.Errorf("include %s: `to` field is required", include.Git)
+			return fmt.Errorf("include %s: `to` field is required", include.Source())
 		}
 		if !strings.HasPrefix(include.To, "/") {
-			return fmt.Errorf("include %s: `to` must be an absolute path relative to the repository root", include.Git)
+			return fmt.Errorf("include %s: `to` must be an absolute path relative to the repository root", include.Source())
 		}
 
 		for _, path := range include.IncludePaths {
 			if strings.HasPrefix(path, "/") {
-				return fmt.Errorf("include %s: `includePaths` must be relative paths to the repository root", include.Git)
+				return fmt.Errorf("include %s: `includePaths` must be relative paths to the repository root", include.Source())
 			}
 		}
 
 		for _, path := range include.ExcludePaths {
 			if strings.HasPrefix(path, "/") {
-				return fmt.Errorf("include %s: `excludePaths` must be relative paths to the repository root", include.Git)
+				return fmt.Errorf("include %s: `excludePaths` must be relative paths to the repository root", include.Source())
 			}
 		}
 
+		if include.isArchive() {
+			continue
+		}
+
 		if !exactlyOne([]bool{include.Branch != "", include.Commit != "", include.Tag != ""}) {
 			err := fmt.Errorf("include %s: specify only `branch` or `tag` or `commit`", include.Git)
 			return err
@@ -140,6 +170,38 @@ func validate(config Config) error {
 	return nil
 }
 
+func validateArchive(include includeConf) error {
+	if include.BasicAuth != nil {
+		return fmt.Errorf("include %s: `basicAuth` can be used only with `git` include, the registry credentials are taken from the docker config", include.Source())
+	}
+
+	if include.Branch != "" || include.Tag != "" || include.Commit != "" {
+		return fmt.Errorf("include %s: `branch`, `tag` and `commit` can be used only with `git` include", include.Source())
+	}
+
+	if include.OCI != "" && include.Checksum != "" {
+		return fmt.Errorf("include %s: `checksum` can be used only with `http` include, the OCI artifact is pinned by the manifest digest", include.Source())
+	}
+
+	if include.HTTP != "" {
+		if !strings.HasPrefix(include.HTTP, "https://") && !strings.HasPrefix(include.HTTP, "http://") {
+			return fmt.Errorf("include %s: `http` must be an http or https url", include.Source())
+		}
+
+		if include.Checksum == "" {
+			return fmt.Errorf("include %s: `checksum` field is required for `http` include", include.Source())
+		}
+
+		if !checksumRegexp.MatchString(include.Checksum) {
+			return fmt.Errorf("include %s: `checksum` must be in the sha256:<hex> format", include.Source())
+		}
+	}
+
+	return nil
+}
+
+var checksumRegexp = regexp.MustCompile(`^sha256:[a-f0-9]{64}$`)
+
 type getLockInfoOptions struct {
 	includesConfig         Config
 	createOrUpdateLockFile bool
@@ -148,11 +210,11 @@ type getLockInfoOptions struct {
 	lockConfig             *lockConfig
 }
 
-func getLockInfo(opts getLockInfoOptions) (*LockInfo, error) {
+func getLockInfo(ctx context.Context, opts getLockInfoOptions) (*LockInfo, error) {
 	var lockConf *lockConfig
 
 	if opts.useLatestVersion {
-		cfg, err := createLockConfig(createLockConfigOptions{
+		cfg, err := createLockConfig(ctx, createLockConfigOptions{
 			includesConfig: opts.includesConfig,
 			remoteRepos:    opts.remoteRepos,
 		})
@@ -184,9 +246,9 @@ func readLockInfo(lockConf *lockConfig) (*LockInfo, error) {
 	for _, l := range lockConf.IncludeLock {
 		ref, err := l.Ref()
 		if err != nil {
-			return nil, fmt.Errorf("unable to get ref for include %s: %w", l.Git, err)
+			return nil, fmt.Errorf("unable to get ref for include %s: %w", l.Source(), err)
 		}
-		lockInfo.includeToCommitMapper[lockId(l.Git, ref)] = l.Commit
+		lockInfo.includeToCommitMapper[lockId(l.Source(), ref)] = l.pin()
 	}
 
 	return lockInfo, nil
@@ -221,8 +283,8 @@ func CreateOrUpdateLockConfig(ctx context.Context, opts createLockConfigOptions)
 	return nil
 }
 
-func CreateLockConfig(_ context.Context, opts createLockConfigOptions) error {
-	locksConf, err := createLockConfig(opts)
+func CreateLockConfig(ctx context.Context, opts createLockConfigOptions) error {
+	locksConf, err := createLockConfig(ctx, opts)
 	if err != nil {
 		return fmt.Errorf("create lock config: %w", err)
 	}
@@ -231,18 +293,20 @@ func CreateLockConfig(_ context.Context, opts createLockConfigOptions) error {
 	return writeLockConfig(locksConf, includesLockPathAbs)
 }
 
-func createLockConfig(opts createLockConfigOptions) (lockConfig, error) {
+func createLockConfig(ctx context.Context, opts createLockConfigOptions) (lockConfig, error) {
 	includesMap := make(map[string]bool)
 	var lockConfs []includeLockConf
 	for _, c := range opts.includesConfig.Includes {
 		ref, err := c.Ref()
 		if err != nil {
-			return lockConfig{}, fmt.Errorf("get ref for include %s: %w", c.Git, err)
+			return lockConfig{}, fmt.Errorf("get ref for include %s: %w", c.Source(), err)
 		}
-		lockId := lockId(c.Git, ref)
+		lockId := lockId(c.Source(), ref)
 		if !includesMap[lockId] {
 			lockConfs = append(lockConfs, includeLockConf{
 				Git:    c.Git,
+				OCI:    c.OCI,
+				HTTP:   c.HTTP,
 				Branch: c.Branch,
 				Tag:    c.Tag,
 				Commit: c.Commit,
@@ -251,7 +315,7 @@ func createLockConfig(opts createLockConfigOptions) (lockConfig, error) {
 		}
 	}
 
-	newLockConfig, err := newLockConfig(lockConfs, opts.remoteRepos)
+	newLockConfig, err := newLockConfig(ctx, lockConfs, opts.remoteRepos)
 	if err != nil {
 		return lockConfig{}, fmt.Errorf("unable to update lock config: %w", err)
 	}
@@ -259,13 +323,19 @@ func createLockConfig(opts createLockConfigOptions) (lockConfig, error) {
 	return newLockConfig, nil
 }
 
-func newLockConfig(cfg []includeLockConf, remoteRepos *gitRepositoriesWithCache) (lockConfig, error) {
+func newLockConfig(ctx context.Context, cfg []includeLockConf, remoteRepos *gitRepositoriesWithCache) (lockConfig, error) {
 	newLockConfig := lockConfig{
 		IncludeLock: make([]includeLockConf, 0, len(cfg)),
 	}
 
 	for _, c := range cfg {
-		updated, err := c.updateCommit(remoteRepos)
+		var updated *includeLockConf
+		var err error
+		if c.isArchive() {
+			updated, err = c.updateDigest(ctx, remoteRepos)
+		} else {
+			updated, err = c.updateCommit(remoteRepos)
+		}
 		if err != nil {
 			return newLockConfig, err
 		}
@@ -283,10 +353,29 @@ func (l *LockInfo) GetCommit(git, ref string) (string, error) {
 	return commit, nil
 }
 
+func (i *includeLockConf) Source() string {
+	return source(i.Git, i.OCI, i.HTTP)
+}
+
+func (i *includeLockConf) isArchive() bool {
+	return i.OCI != "" || i.HTTP != ""
+}
+
 func (i *includeLockConf) Ref() (string, error) {
+	if i.isArchive() {
+		return "", nil
+	}
 	return ref(i.Git, i.Tag, i.Branch, i.Commit)
 }
 
+// pin returns the commit of the git include or the digest of the archive include.
+func (i *includeLockConf) pin() string {
+	if i.isArchive() {
+		return i.Digest
+	}
+	return i.Commit
+}
+
 func (i *includeLockConf) getCommit(r *git.Repository) (*object.Commit, error) {
 	return getCommit(r, i.Git, i.Tag, i.Branch, i.Commit)
 }
@@ -328,6 +417,24 @@ func (c *includeLockConf) updateCommit(remoteRepos *gitRepositoriesWithCache) (*
 	}, nil
 }
 
+func (c *includeLockConf) updateDigest(ctx context.Context, remoteRepos *gitRepositoriesWithCache) (*includeLockConf, error) {
+	archive, err := remoteRepos.getArchive(c.Source())
+	if err != nil {
+		return nil, err
+	}
+
+	digest, err := archive.resolveDigest(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("resolve digest: %w", err)
+	}
+
+	return &includeLockConf{
+		OCI:    c.OCI,
+		HTTP:   c.HTTP,
+		Digest: digest,
+	}, nil
+}
+
 func exactlyOne(conditions []bool) bool {
 	count := 0
 	for _, c := range conditions {
@@ -23,7 +23,7 @@ type GiterminismManagerFileReader interface {
 }
 
 type Include struct {
-	repo       GitRepository
+	repo       includeSource
 	commitHash string
 	// `objects` is a map of destination path to original path
 	// where the file was found in the remote repository
@@ -79,7 +79,7 @@ func Init(ctx context.Context, opts InitIncludesOptions) ([]*Include, error) {
 			return nil, nil
 		}
 
-		lockInfo, err := getLockInfo(getLockInfoOptions{
+		lockInfo, err := getLockInfo(ctx, getLockInfoOptions{
 			includesConfig:         config,
 			createOrUpdateLockFile: opts.CreateOrUpdateLockFile,
 			useLatestVersion:       opts.UseLatestVersion,
@@ -106,6 +106,15 @@ func GetIncludes(ctx context.Context, cfg Config, lockInfo *LockInfo, remoteRepo
 		for i := len(cfg.Includes) - 1; i >= 0; i-- {
 			// Reverse order to prioritize the last include in the list
 			inc := cfg.Includes[i]
+			if inc.isArchive() {
+				include, err := getArchiveInclude(ctx, inc, lockInfo, remoteRepos)
+				if err != nil {
+					return err
+				}
+				includes = append(includes, include)
+				continue
+			}
+
 			r, err := remoteRepos.getRepository(inc.Git)
 			if err != nil {
 				return fmt.Errorf("unable to find remote repository %s: %w", inc.Git, err)
@@ -185,6 +194,61 @@ func GetIncludes(ctx context.Context, cfg Config, lockInfo *LockInfo, remoteRepo
 	return includes, nil
 }
 
+func getArchiveInclude(ctx context.Context, inc includeConf, lockInfo *LockInfo, remoteRepos *gitRepositoriesWithCache) (*Include, error) {
+	archive, err := remoteRepos.getArchive(inc.Source())
+	if err != nil {
+		return nil, err
+	}
+
+	var include *Include
+	err = logboek.Context(ctx).Default().LogProcess("Processing include %s", inc.Source()).DoError(func() error {
+		digest, err := lockInfo.GetCommit(inc.Source(), "")
+		if err != nil {
+			return fmt.Errorf("unable to get digest from lock info: %w", err)
+		}
+
+		if err := archive.fetch(ctx, digest); err != nil {
+			return fmt.Errorf("unable to fetch %s: %w", inc.Source(), err)
+		}
+
+		files, err := archive.listFiles(digest)
+		if err != nil {
+			return err
+		}
+
+		pm := path_matcher.NewPathMatcher(path_matcher.PathMatcherOptions{
+			BasePath:     inc.Add,
+			IncludeGlobs: inc.IncludePaths,
+			ExcludeGlobs: inc.ExcludePaths,
+		})
+
+		matchedMap := map[string]string{}
+		for _, f := range files {
+			if pm.IsPathMatched(f) {
+				matchedMap[prepareRelPath(f, inc.Add, inc.To)] = f
+			}
+		}
+
+		if len(matchedMap) == 0 {
+			return fmt.Errorf("no files matched for include %s with digest %s", inc.Source(), digest)
+		}
+
+		include = &Include{
+			repo:       archive,
+			commitHash: digest,
+			objects:    matchedMap,
+		}
+
+		logboek.Context(ctx).Debug().LogF("Include initialized: archive: %s digest: %s\n", inc.Source(), digest)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return include, nil
+}
+
 func (i *Include) GetName() string {
 	if i.repo == nil {
 		return ""
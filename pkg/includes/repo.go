@@ -15,6 +15,11 @@ import (
 
 type GitRepository interface {
 	PlainOpen() (*git.Repository, error)
+	includeSource
+}
+
+// includeSource provides access to the include files at the commit (git) or at the digest (archives).
+type includeSource interface {
 	GetName() string
 	ReadCommitFile(ctx context.Context, commit, path string) (data []byte, err error)
 	IsCommitDirectoryExist(ctx context.Context, commit, path string) (exist bool, err error)
@@ -27,15 +32,24 @@ type gitRepository struct {
 
 type gitRepositoriesWithCache struct {
 	repositories map[string]*gitRepository
+	archives     map[string]*archiveRepository
 }
 
 func newGitRepositoriesWithCache() *gitRepositoriesWithCache {
 	return &gitRepositoriesWithCache{
 		repositories: make(map[string]*gitRepository),
+		archives:     make(map[string]*archiveRepository),
 	}
 }
 
 func (g *gitRepositoriesWithCache) add(ctx context.Context, i includeConf) error {
+	if i.isArchive() {
+		if _, ok := g.archives[i.Source()]; !ok {
+			g.archives[i.Source()] = newArchiveRepository(i)
+		}
+		return nil
+	}
+
 	if _, ok := g.repositories[i.Git]; !ok {
 		r, err := newRepo(ctx, i)
 		if err != nil {
@@ -54,6 +68,14 @@ func (g *gitRepositoriesWithCache) getRepository(git string) (*gitRepository, er
 	return repo, nil
 }
 
+func (g *gitRepositoriesWithCache) getArchive(source string) (*archiveRepository, error) {
+	archive, ok := g.archives[source]
+	if !ok {
+		return nil, fmt.Errorf("archive %s not found", source)
+	}
+	return archive, nil
+}
+
 func initRemoteRepos(ctx context.Context, cfg Config) (*gitRepositoriesWithCache, error) {
 	repoCache := newGitRepositoriesWithCache()
 	err := logboek.Context(ctx).Default().LogBlock("Initializing remote repositories").DoError(func() error {
@@ -156,6 +178,17 @@ func branchRef(r *git.Repository, branch string) (*object.Commit, error) {
 	return commitRef(r, branchRef.Hash().String())
 }
 
+func source(git, oci, http string) string {
+	switch {
+	case oci != "":
+		return oci
+	case http != "":
+		return http
+	default:
+		return git
+	}
+}
+
 func ref(git, tag, branch, commit string) (string, error) {
 	switch {
 	case tag != "":
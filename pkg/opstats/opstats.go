@@ -29,6 +29,7 @@ const (
 	OperationStageLockWait           Operation = "stage lock wait (storage)"
 	OperationStageDigestLockWait     Operation = "stage lock wait (parallel tasks)"
 	OperationContextAddFiles         Operation = "context add files"
+	OperationRegistryRateLimitWait   Operation = "registry rate limit wait"
 )
 
 type Event string
@@ -85,6 +86,20 @@ func CountEvent(ctx context.Context, event Event) {
 	collector.events[event]++
 }
 
+// CountPullEndpoint increments the counter of pulls served by the endpoint,
+// which is the mirror host or the origin registry host.
+// When no collector is bound, it is a no-op.
+func CountPullEndpoint(ctx context.Context, endpoint string) {
+	collector := FromContext(ctx)
+	if collector == nil {
+		return
+	}
+
+	collector.mu.Lock()
+	defer collector.mu.Unlock()
+	collector.pullEndpoints[endpoint]++
+}
+
 var _ io.ReadCloser = (*observedReadCloser)(nil)
 
 type observedReadCloser struct {
@@ -113,9 +128,10 @@ func NewObservedReadCloser(rc io.ReadCloser, done func()) io.ReadCloser {
 }
 
 type Collector struct {
-	mu        sync.Mutex
-	intervals map[Operation][]interval
-	events    map[Event]int
+	mu            sync.Mutex
+	intervals     map[Operation][]interval
+	events        map[Event]int
+	pullEndpoints map[string]int
 }
 
 type interval struct {
@@ -125,8 +141,9 @@ type interval struct {
 
 func NewCollector() *Collector {
 	return &Collector{
-		intervals: make(map[Operation][]interval),
-		events:    make(map[Event]int),
+		intervals:     make(map[Operation][]interval),
+		events:        make(map[Event]int),
+		pullEndpoints: make(map[string]int),
 	}
 }
 
@@ -209,6 +226,31 @@ func (c *Collector) EventSummary() []EventSummary {
 	return res
 }
 
+type PullEndpointSummary struct {
+	Endpoint string
+	Count    int
+}
+
+// PullEndpointSummary returns per-endpoint pull counters sorted by count in descending order.
+func (c *Collector) PullEndpointSummary() []PullEndpointSummary {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	res := make([]PullEndpointSummary, 0, len(c.pullEndpoints))
+	for endpoint, count := range c.pullEndpoints {
+		res = append(res, PullEndpointSummary{Endpoint: endpoint, Count: count})
+	}
+
+	sort.Slice(res, func(i, j int) bool {
+		if res[i].Count == res[j].Count {
+			return res[i].Endpoint < res[j].Endpoint
+		}
+		return res[i].Count > res[j].Count
+	})
+
+	return res
+}
+
 func unionDuration(intervals []interval) time.Duration {
 	if len(intervals) == 0 {
 		return 0
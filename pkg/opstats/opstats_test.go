@@ -165,11 +165,26 @@ var _ = Describe("Collector", func() {
 		}))
 	})
 
+	It("counts pull endpoints and sorts by count", func() {
+		collector := NewCollector()
+		ctx := NewContext(context.Background(), collector)
+
+		CountPullEndpoint(ctx, "docker.io")
+		CountPullEndpoint(ctx, "mirror.example.com")
+		CountPullEndpoint(ctx, "mirror.example.com")
+
+		Expect(collector.PullEndpointSummary()).To(Equal([]PullEndpointSummary{
+			{Endpoint: "mirror.example.com", Count: 2},
+			{Endpoint: "docker.io", Count: 1},
+		}))
+	})
+
 	It("is a no-op without collector in context", func() {
 		done := Observe(context.Background(), OperationImagePull)
 		Expect(done).NotTo(BeNil())
 		done()
 
 		CountEvent(context.Background(), EventStageBuilt)
+		CountPullEndpoint(context.Background(), "docker.io")
 	})
 })
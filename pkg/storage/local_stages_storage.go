@@ -5,15 +5,14 @@ import (
 	"fmt"
 	"strings"
 
-	v1 "github.com/google/go-containerregistry/pkg/v1"
 	"sigs.k8s.io/yaml"
 
 	"github.com/werf/common-go/pkg/util"
 	"github.com/werf/logboek"
 	"github.com/werf/werf/v2/pkg/container_backend"
 	"github.com/werf/werf/v2/pkg/docker_registry"
-	"github.com/werf/werf/v2/pkg/docker_registry/api"
 	"github.com/werf/werf/v2/pkg/image"
+	"github.com/werf/werf/v2/pkg/vulnerability_scanner"
 )
 
 const (
@@ -142,7 +141,7 @@ func (storage *LocalStagesStorage) GetStageDesc(ctx context.Context, projectName
 	}, nil
 }
 
-func (storage *LocalStagesStorage) ExportStage(ctx context.Context, stageDesc *image.StageDesc, destinationReference string, mutateConfigFunc func(config v1.Config) (v1.Config, error)) error {
+func (storage *LocalStagesStorage) ExportStage(ctx context.Context, stageDesc *image.StageDesc, destinationReference string, opts ExportStageOptions) error {
 	if err := storage.ContainerBackend.Tag(ctx, stageDesc.Info.Name, destinationReference, container_backend.TagOpts{}); err != nil {
 		return fmt.Errorf("unable to tag %q as %q: %w", stageDesc.Info.Name, destinationReference, err)
 	}
@@ -153,7 +152,21 @@ func (storage *LocalStagesStorage) ExportStage(ctx context.Context, stageDesc *i
 	if err := storage.ContainerBackend.Push(ctx, destinationReference, container_backend.PushOpts{}); err != nil {
 		return fmt.Errorf("unable to push %q: %w", destinationReference, err)
 	}
-	return docker_registry.API().MutateAndPushImage(ctx, destinationReference, destinationReference, api.WithConfigMutation(mutateExportStageConfig(mutateConfigFunc)))
+
+	mutateOpts, err := exportStageMutateOptions(opts)
+	if err != nil {
+		return err
+	}
+
+	return docker_registry.API().MutateAndPushImage(ctx, destinationReference, destinationReference, mutateOpts...)
+}
+
+func (storage *LocalStagesStorage) ExportStageToOCIArchive(_ context.Context, _ *image.StageDesc, _, _ string, _ ExportStageOptions) error {
+	return fmt.Errorf("export to OCI archive is not supported with local stages storage: use --repo")
+}
+
+func (storage *LocalStagesStorage) WriteStageToOCILayout(_ context.Context, _ *image.StageDesc, _, _ string) error {
+	return fmt.Errorf("writing OCI image layout is not supported with local stages storage: use --repo")
 }
 
 func (storage *LocalStagesStorage) DeleteStage(ctx context.Context, stageDesc *image.StageDesc, options DeleteImageOptions) error {
@@ -346,6 +359,24 @@ func (storage *LocalStagesStorage) GetImportMetadataIDs(ctx context.Context, pro
 	return tags, nil
 }
 
+func (storage *LocalStagesStorage) GetVulnerabilityScanMetadata(_ context.Context, _ string, _ vulnerability_scanner.ScannerType, _ string) (*VulnerabilityScanMetadata, error) {
+	return nil, fmt.Errorf("vulnerability scan is not supported with local stages storage: use --repo")
+}
+
+func (storage *LocalStagesStorage) PutVulnerabilityScanMetadata(_ context.Context, _ string, _ *VulnerabilityScanMetadata) error {
+	return fmt.Errorf("vulnerability scan is not supported with local stages storage: use --repo")
+}
+
+func (storage *LocalStagesStorage) RmVulnerabilityScanMetadata(_ context.Context, _, _ string) error {
+	return fmt.Errorf("vulnerability scan is not supported with local stages storage: use --repo")
+}
+
+// GetVulnerabilityScanMetadataIDs returns nothing, the vulnerability scan metadata is never stored in the local stages
+// storage.
+func (storage *LocalStagesStorage) GetVulnerabilityScanMetadataIDs(_ context.Context, _ string, _ ...Option) ([]string, error) {
+	return nil, nil
+}
+
 func (storage *LocalStagesStorage) GetClientIDRecords(_ context.Context, _ string, _ ...Option) ([]*ClientIDRecord, error) {
 	panic("not implemented")
 }
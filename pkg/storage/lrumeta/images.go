@@ -15,15 +15,20 @@ import (
 	"github.com/werf/werf/v2/pkg/werf"
 )
 
+// Before changing: read the local_cache contract in the package doc of pkg/git_repo/gitdata.
 const LRUImagesCacheVersion = "1"
 
 var CommonLRUImagesCache *LRUImagesCache
 
 func Init() error {
-	CommonLRUImagesCache = NewLRUImagesCache(filepath.Join(werf.GetLocalCacheDir(), "lru_images", LRUImagesCacheVersion))
+	CommonLRUImagesCache = NewLRUImagesCache(GetLRUImagesCacheDir())
 	return nil
 }
 
+func GetLRUImagesCacheDir() string {
+	return filepath.Join(werf.GetLocalCacheDir(), "lru_images", LRUImagesCacheVersion)
+}
+
 type LRUImagesCache struct {
 	CacheDir string
 }
@@ -123,7 +128,7 @@ func (cache *LRUImagesCache) constructFilePathForImage(imageRef string) string {
 }
 
 func (cache *LRUImagesCache) lock(ctx context.Context, imageRef string) (lockgate.LockHandle, error) {
-	lockName := fmt.Sprintf("lru_images_cache.%s", imageRef)
+	lockName := LRUImagesCacheLockName(imageRef)
 	if _, lock, err := werf.HostLocker().AcquireLock(ctx, lockName, lockgate.AcquireOptions{}); err != nil {
 		return lockgate.LockHandle{}, fmt.Errorf("cannot acquire %s host lock: %w", lockName, err)
 	} else {
@@ -137,3 +142,8 @@ func (cache *LRUImagesCache) unlock(lock lockgate.LockHandle) error {
 	}
 	return nil
 }
+
+// LRUImagesCacheLockName returns the name of the lock the record of the image is accessed under.
+func LRUImagesCacheLockName(imageRef string) string {
+	return fmt.Sprintf("lru_images_cache.%s", imageRef)
+}
@@ -0,0 +1,240 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"sort"
+)
+
+// ManagedImagesSyncPlan describes the changes to reconcile the managed images of the project with the images declared in werf.yaml.
+type ManagedImagesSyncPlan struct {
+	// Add is the images declared in werf.yaml, which are missing in the managed images list.
+	Add []string
+	// Migrations move the image metadata of the renamed images.
+	Migrations []*ManagedImageMigration
+	// Orphans is the managed images and the image metadata, which do not belong to the images declared in werf.yaml.
+	Orphans []*OrphanedManagedImage
+}
+
+// ManagedImageMigration moves the managed image record and the image metadata from the old image name to the new one.
+type ManagedImageMigration struct {
+	From    string
+	To      string
+	Managed bool
+	// Metadata is the commits of the image metadata records grouped by the stage ID.
+	Metadata map[string][]string
+}
+
+// OrphanedManagedImage is the managed image or the image metadata, which is not declared in werf.yaml anymore.
+type OrphanedManagedImage struct {
+	// ImageName is the managed image name or the image metadata ID if there is no managed image record for the metadata.
+	ImageName    string
+	IsMetadataID bool
+	Managed      bool
+	// Metadata is the commits of the image metadata records grouped by the stage ID.
+	Metadata map[string][]string
+}
+
+func (o *OrphanedManagedImage) MetadataRecordsNumber() int {
+	return countMetadataRecords(o.Metadata)
+}
+
+func (o *OrphanedManagedImage) StagesNumber() int {
+	return len(o.Metadata)
+}
+
+func (m *ManagedImageMigration) MetadataRecordsNumber() int {
+	return countMetadataRecords(m.Metadata)
+}
+
+type PlanManagedImagesSyncOptions struct {
+	// ImageNames is the images declared in werf.yaml.
+	ImageNames []string
+	// Migrations maps the old image names to the new ones.
+	Migrations map[string]string
+}
+
+func PlanManagedImagesSync(ctx context.Context, stagesStorage StagesStorage, projectName string, opts PlanManagedImagesSyncOptions) (*ManagedImagesSyncPlan, error) {
+	declared := map[string]bool{}
+	for _, imageName := range opts.ImageNames {
+		declared[getManagedImageNameByImageNameOrManagedImage(imageName)] = true
+	}
+
+	managedImages, err := stagesStorage.GetManagedImages(ctx, projectName)
+	if err != nil {
+		return nil, fmt.Errorf("unable to get managed images for project %q: %w", projectName, err)
+	}
+
+	managed := map[string]bool{}
+	for _, managedImage := range managedImages {
+		managed[getManagedImageNameByImageNameOrManagedImage(managedImage)] = true
+	}
+
+	migrateFrom := map[string]string{}
+	for from, to := range opts.Migrations {
+		from, to = getManagedImageNameByImageNameOrManagedImage(from), getManagedImageNameByImageNameOrManagedImage(to)
+		if declared[from] {
+			return nil, fmt.Errorf("unable to migrate image %q: image is declared in werf.yaml", from)
+		}
+		if !declared[to] {
+			return nil, fmt.Errorf("unable to migrate image %q to %q: image %q is not declared in werf.yaml", from, to, to)
+		}
+		migrateFrom[from] = to
+	}
+
+	var knownNames []string
+	for name := range declared {
+		knownNames = append(knownNames, name)
+	}
+	for name := range managed {
+		knownNames = append(knownNames, name)
+	}
+	for name := range migrateFrom {
+		knownNames = append(knownNames, name)
+	}
+
+	metadataByImageName, metadataByID, err := stagesStorage.GetAllAndGroupImageMetadataByImageName(ctx, projectName, knownNames)
+	if err != nil {
+		return nil, fmt.Errorf("unable to get image metadata for project %q: %w", projectName, err)
+	}
+
+	plan := &ManagedImagesSyncPlan{}
+
+	for name := range declared {
+		if !managed[name] {
+			plan.Add = append(plan.Add, name)
+		}
+	}
+	sort.Strings(plan.Add)
+
+	for from, to := range migrateFrom {
+		plan.Migrations = append(plan.Migrations, &ManagedImageMigration{
+			From:     from,
+			To:       to,
+			Managed:  managed[from],
+			Metadata: metadataByImageName[from],
+		})
+	}
+	sort.Slice(plan.Migrations, func(i, j int) bool { return plan.Migrations[i].From < plan.Migrations[j].From })
+
+	orphans := map[string]*OrphanedManagedImage{}
+	for name := range managed {
+		orphans[name] = &OrphanedManagedImage{ImageName: name, Managed: true}
+	}
+	for name, metadata := range metadataByImageName {
+		if _, ok := orphans[name]; !ok {
+			orphans[name] = &OrphanedManagedImage{ImageName: name}
+		}
+		orphans[name].Metadata = metadata
+	}
+	for id, metadata := range metadataByID {
+		orphans[id] = &OrphanedManagedImage{ImageName: id, IsMetadataID: true, Metadata: metadata}
+	}
+
+	for name, orphan := range orphans {
+		if orphan.IsMetadataID || (!declared[name] && migrateFrom[name] == "") {
+			plan.Orphans = append(plan.Orphans, orphan)
+		}
+	}
+	sort.Slice(plan.Orphans, func(i, j int) bool {
+		if plan.Orphans[i].IsMetadataID != plan.Orphans[j].IsMetadataID {
+			return !plan.Orphans[i].IsMetadataID
+		}
+		return plan.Orphans[i].ImageName < plan.Orphans[j].ImageName
+	})
+
+	return plan, nil
+}
+
+type ApplyManagedImagesSyncOptions struct {
+	// RemoveOrphans removes the orphaned managed images and their image metadata.
+	RemoveOrphans bool
+}
+
+// ApplyManagedImagesSync migrates the image metadata, adds the missing managed images and optionally removes the orphans.
+// The new image metadata records are added before the old ones are removed, so the interrupted migration can be repeated.
+func ApplyManagedImagesSync(ctx context.Context, stagesStorage StagesStorage, projectName string, plan *ManagedImagesSyncPlan, opts ApplyManagedImagesSyncOptions) error {
+	for _, migration := range plan.Migrations {
+		if err := stagesStorage.AddManagedImage(ctx, projectName, migration.To); err != nil {
+			return fmt.Errorf("unable to add managed image %q: %w", migration.To, err)
+		}
+
+		if err := forEachMetadataRecord(migration.Metadata, func(stageID, commit string) error {
+			if err := stagesStorage.PutImageMetadata(ctx, projectName, migration.To, commit, stageID); err != nil {
+				return fmt.Errorf("unable to put image %q metadata for commit %s and stage %s: %w", migration.To, commit, stageID, err)
+			}
+			return nil
+		}); err != nil {
+			return err
+		}
+
+		if err := rmImageMetadata(ctx, stagesStorage, projectName, migration.From, migration.Metadata); err != nil {
+			return err
+		}
+
+		if migration.Managed {
+			if err := stagesStorage.RmManagedImage(ctx, projectName, migration.From); err != nil {
+				return fmt.Errorf("unable to remove managed image %q: %w", migration.From, err)
+			}
+		}
+	}
+
+	for _, imageName := range plan.Add {
+		if err := stagesStorage.AddManagedImage(ctx, projectName, imageName); err != nil {
+			return fmt.Errorf("unable to add managed image %q: %w", imageName, err)
+		}
+	}
+
+	if !opts.RemoveOrphans {
+		return nil
+	}
+
+	for _, orphan := range plan.Orphans {
+		if err := rmImageMetadata(ctx, stagesStorage, projectName, orphan.ImageName, orphan.Metadata); err != nil {
+			return err
+		}
+
+		if orphan.Managed {
+			if err := stagesStorage.RmManagedImage(ctx, projectName, orphan.ImageName); err != nil {
+				return fmt.Errorf("unable to remove managed image %q: %w", orphan.ImageName, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+func rmImageMetadata(ctx context.Context, stagesStorage StagesStorage, projectName, imageNameOrImageMetadataID string, metadata map[string][]string) error {
+	return forEachMetadataRecord(metadata, func(stageID, commit string) error {
+		if err := stagesStorage.RmImageMetadata(ctx, projectName, imageNameOrImageMetadataID, commit, stageID); err != nil {
+			return fmt.Errorf("unable to remove image %q metadata for commit %s and stage %s: %w", imageNameOrImageMetadataID, commit, stageID, err)
+		}
+		return nil
+	})
+}
+
+func forEachMetadataRecord(metadata map[string][]string, f func(stageID, commit string) error) error {
+	stageIDs := make([]string, 0, len(metadata))
+	for stageID := range metadata {
+		stageIDs = append(stageIDs, stageID)
+	}
+	sort.Strings(stageIDs)
+
+	for _, stageID := range stageIDs {
+		for _, commit := range metadata[stageID] {
+			if err := f(stageID, commit); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func countMetadataRecords(metadata map[string][]string) int {
+	var number int
+	for _, commits := range metadata {
+		number += len(commits)
+	}
+	return number
+}
@@ -0,0 +1,167 @@
+package storage
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+// managedImagesStorageStub keeps the managed images and the image metadata in memory.
+type managedImagesStorageStub struct {
+	StagesStorage
+
+	managedImages map[string]bool
+	// metadata maps the image metadata ID to the commits grouped by the stage ID.
+	metadata map[string]map[string][]string
+}
+
+func (s *managedImagesStorageStub) AddManagedImage(_ context.Context, _, imageName string) error {
+	s.managedImages[getManagedImageNameByImageNameOrManagedImage(imageName)] = true
+	return nil
+}
+
+func (s *managedImagesStorageStub) RmManagedImage(_ context.Context, _, imageName string) error {
+	delete(s.managedImages, getManagedImageNameByImageNameOrManagedImage(imageName))
+	return nil
+}
+
+func (s *managedImagesStorageStub) GetManagedImages(_ context.Context, _ string, _ ...Option) ([]string, error) {
+	var res []string
+	for imageName := range s.managedImages {
+		res = append(res, imageName)
+	}
+	return res, nil
+}
+
+func (s *managedImagesStorageStub) PutImageMetadata(_ context.Context, _, imageName, commit, stageID string) error {
+	id := getImageMetadataID(imageName)
+	if s.metadata[id] == nil {
+		s.metadata[id] = map[string][]string{}
+	}
+	s.metadata[id][stageID] = append(s.metadata[id][stageID], commit)
+	return nil
+}
+
+func (s *managedImagesStorageStub) RmImageMetadata(_ context.Context, _, imageNameOrID, commit, stageID string) error {
+	id := imageNameOrID
+	if _, ok := s.metadata[id]; !ok {
+		id = getImageMetadataID(imageNameOrID)
+	}
+
+	var commits []string
+	for _, c := range s.metadata[id][stageID] {
+		if c != commit {
+			commits = append(commits, c)
+		}
+	}
+
+	if len(commits) == 0 {
+		delete(s.metadata[id], stageID)
+	} else {
+		s.metadata[id][stageID] = commits
+	}
+
+	if len(s.metadata[id]) == 0 {
+		delete(s.metadata, id)
+	}
+
+	return nil
+}
+
+func (s *managedImagesStorageStub) GetAllAndGroupImageMetadataByImageName(_ context.Context, _ string, imageNames []string, _ ...Option) (map[string]map[string][]string, map[string]map[string][]string, error) {
+	known, unknown := map[string]map[string][]string{}, map[string]map[string][]string{}
+
+idLoop:
+	for id, metadata := range s.metadata {
+		for _, imageName := range imageNames {
+			if getImageMetadataID(imageName) == id {
+				known[imageName] = metadata
+				continue idLoop
+			}
+		}
+		unknown[id] = metadata
+	}
+
+	return known, unknown, nil
+}
+
+var _ = Describe("managed images sync", func() {
+	ctx := context.Background()
+	var stub *managedImagesStorageStub
+
+	BeforeEach(func() {
+		stub = &managedImagesStorageStub{
+			managedImages: map[string]bool{"backend": true, "old-frontend": true, "removed": true},
+			metadata: map[string]map[string][]string{
+				getImageMetadataID("old-frontend"): {"stage-1": {"commit-1", "commit-2"}, "stage-2": {"commit-3"}},
+				getImageMetadataID("removed"):      {"stage-3": {"commit-4"}},
+				"lost-metadata-id":                 {"stage-4": {"commit-5"}},
+			},
+		}
+	})
+
+	It("plans to add the missing images and lists the orphans with their metadata", func() {
+		plan, err := PlanManagedImagesSync(ctx, stub, "project", PlanManagedImagesSyncOptions{
+			ImageNames: []string{"backend", "frontend", ""},
+		})
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(plan.Add).To(Equal([]string{"", "frontend"}))
+		Expect(plan.Migrations).To(BeEmpty())
+		Expect(plan.Orphans).To(HaveLen(3))
+
+		Expect(plan.Orphans[0].ImageName).To(Equal("old-frontend"))
+		Expect(plan.Orphans[0].Managed).To(BeTrue())
+		Expect(plan.Orphans[0].MetadataRecordsNumber()).To(Equal(3))
+		Expect(plan.Orphans[0].StagesNumber()).To(Equal(2))
+
+		Expect(plan.Orphans[1].ImageName).To(Equal("removed"))
+
+		Expect(plan.Orphans[2].ImageName).To(Equal("lost-metadata-id"))
+		Expect(plan.Orphans[2].IsMetadataID).To(BeTrue())
+		Expect(plan.Orphans[2].Managed).To(BeFalse())
+	})
+
+	It("migrates the metadata to the new name and keeps the orphans without the option", func() {
+		plan, err := PlanManagedImagesSync(ctx, stub, "project", PlanManagedImagesSyncOptions{
+			ImageNames: []string{"backend", "frontend"},
+			Migrations: map[string]string{"old-frontend": "frontend"},
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(plan.Migrations).To(HaveLen(1))
+		Expect(plan.Migrations[0].MetadataRecordsNumber()).To(Equal(3))
+		Expect(plan.Orphans).To(HaveLen(2))
+
+		Expect(ApplyManagedImagesSync(ctx, stub, "project", plan, ApplyManagedImagesSyncOptions{})).To(Succeed())
+
+		Expect(stub.managedImages).To(Equal(map[string]bool{"backend": true, "frontend": true, "removed": true}))
+		Expect(stub.metadata).To(HaveKey(getImageMetadataID("frontend")))
+		Expect(stub.metadata).NotTo(HaveKey(getImageMetadataID("old-frontend")))
+		Expect(stub.metadata).To(HaveKey("lost-metadata-id"))
+	})
+
+	It("removes the orphans and their metadata", func() {
+		plan, err := PlanManagedImagesSync(ctx, stub, "project", PlanManagedImagesSyncOptions{
+			ImageNames: []string{"backend"},
+		})
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(ApplyManagedImagesSync(ctx, stub, "project", plan, ApplyManagedImagesSyncOptions{RemoveOrphans: true})).To(Succeed())
+
+		Expect(stub.managedImages).To(Equal(map[string]bool{"backend": true}))
+		Expect(stub.metadata).To(BeEmpty())
+	})
+
+	DescribeTable("rejects the bad migration",
+		func(from, to string) {
+			_, err := PlanManagedImagesSync(ctx, stub, "project", PlanManagedImagesSyncOptions{
+				ImageNames: []string{"backend", "frontend"},
+				Migrations: map[string]string{from: to},
+			})
+			Expect(err).To(HaveOccurred())
+		},
+		Entry("to the image not declared in werf.yaml", "old-frontend", "unknown"),
+		Entry("from the image declared in werf.yaml", "backend", "frontend"),
+	)
+})
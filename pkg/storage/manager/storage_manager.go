@@ -85,6 +85,7 @@ type StorageManagerInterface interface {
 	ForEachRmManagedImage(ctx context.Context, projectName string, managedImages []string, f func(ctx context.Context, managedImage string, err error) error) error
 	ForEachGetImportMetadata(ctx context.Context, projectName string, ids []string, f func(ctx context.Context, metadataID string, metadata *storage.ImportMetadata, err error) error) error
 	ForEachRmImportMetadata(ctx context.Context, projectName string, ids []string, f func(ctx context.Context, id string, err error) error) error
+	ForEachRmVulnerabilityScanMetadata(ctx context.Context, projectName string, ids []string, f func(ctx context.Context, id string, err error) error) error
 	ForEachGetStageCustomTagMetadata(ctx context.Context, ids []string, f func(ctx context.Context, metadataID string, metadata *storage.CustomTagMetadata, err error) error) error
 	ForEachDeleteStageCustomTag(ctx context.Context, ids []string, f func(ctx context.Context, tag string, err error) error) error
 }
@@ -926,6 +927,7 @@ func ConvertStageDescForStagesStorage(stageDesc *image.StageDesc, stagesStorage
 			CreatedAtUnixNano: stageDesc.Info.CreatedAtUnixNano,
 			OnBuild:           stageDesc.Info.OnBuild,
 			Env:               stageDesc.Info.Env,
+			User:              stageDesc.Info.User,
 			Volumes:           stageDesc.Info.Volumes,
 		},
 	}
@@ -1073,6 +1075,16 @@ func (m *StorageManager) ForEachRmImportMetadata(ctx context.Context, projectNam
 	})
 }
 
+func (m *StorageManager) ForEachRmVulnerabilityScanMetadata(ctx context.Context, projectName string, ids []string, f func(ctx context.Context, id string, err error) error) error {
+	return parallel.DoTasks(ctx, len(ids), parallel.DoTasksOptions{
+		MaxNumberOfWorkers: m.MaxNumberOfWorkers(),
+	}, func(ctx context.Context, taskId int) error {
+		id := ids[taskId]
+		err := m.StagesStorage.RmVulnerabilityScanMetadata(ctx, projectName, id)
+		return f(ctx, id, err)
+	})
+}
+
 func (m *StorageManager) ForEachRejectedStage(ctx context.Context, stageIDs []image.StageID, f func(ctx context.Context, stageID image.StageID) error) error {
 	ids := append([]image.StageID(nil), stageIDs...)
 	return parallel.DoTasks(ctx, len(ids), parallel.DoTasksOptions{
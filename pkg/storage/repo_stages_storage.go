@@ -11,6 +11,7 @@ import (
 
 	"github.com/containerd/containerd/platforms"
 	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/opencontainers/go-digest"
 
 	"github.com/werf/common-go/pkg/util"
 	"github.com/werf/logboek"
@@ -19,6 +20,7 @@ import (
 	"github.com/werf/werf/v2/pkg/docker_registry/api"
 	"github.com/werf/werf/v2/pkg/image"
 	"github.com/werf/werf/v2/pkg/slug"
+	"github.com/werf/werf/v2/pkg/vulnerability_scanner"
 )
 
 const (
@@ -40,6 +42,9 @@ const (
 	RepoImportMetadata_ImageTagPrefix  = "import-metadata-"
 	RepoImportMetadata_ImageNameFormat = "%s:import-metadata-%s"
 
+	RepoVulnerabilityScanMetadata_ImageTagPrefix  = "vulnerability-scan-"
+	RepoVulnerabilityScanMetadata_ImageNameFormat = "%s:vulnerability-scan-%s"
+
 	RepoClientIDRecord_ImageTagPrefix  = "client-id-"
 	RepoClientIDRecord_ImageNameFormat = "%s:client-id-%s-%d"
 
@@ -158,8 +163,40 @@ func (storage *RepoStagesStorage) GetStagesIDs(ctx context.Context, _ string, op
 	return res, nil
 }
 
-func (storage *RepoStagesStorage) ExportStage(ctx context.Context, stageDesc *image.StageDesc, destinationReference string, mutateConfigFunc func(config v1.Config) (v1.Config, error)) error {
-	return storage.DockerRegistry.MutateAndPushImage(ctx, stageDesc.Info.Name, destinationReference, api.WithConfigMutation(mutateExportStageConfig(mutateConfigFunc)))
+func (storage *RepoStagesStorage) ExportStage(ctx context.Context, stageDesc *image.StageDesc, destinationReference string, opts ExportStageOptions) error {
+	mutateOpts, err := exportStageMutateOptions(opts)
+	if err != nil {
+		return err
+	}
+
+	return storage.DockerRegistry.MutateAndPushImage(ctx, stageDesc.Info.Name, destinationReference, mutateOpts...)
+}
+
+func (storage *RepoStagesStorage) ExportStageToOCIArchive(ctx context.Context, stageDesc *image.StageDesc, archivePath, refName string, opts ExportStageOptions) error {
+	mutateOpts, err := exportStageMutateOptions(opts)
+	if err != nil {
+		return err
+	}
+
+	return storage.DockerRegistry.MutateAndWriteOCIArchive(ctx, stageDesc.Info.Name, archivePath, refName, mutateOpts...)
+}
+
+func (storage *RepoStagesStorage) WriteStageToOCILayout(ctx context.Context, stageDesc *image.StageDesc, layoutDir, refName string) error {
+	return storage.DockerRegistry.WriteOCILayout(ctx, stageDesc.Info.Name, layoutDir, refName)
+}
+
+func exportStageMutateOptions(opts ExportStageOptions) ([]api.MutateOption, error) {
+	mutateOpts := []api.MutateOption{api.WithConfigMutation(mutateExportStageConfig(opts.MutateConfigFunc))}
+
+	if len(opts.Platforms) > 0 {
+		platformsOpt, err := api.WithPlatforms(opts.Platforms)
+		if err != nil {
+			return nil, err
+		}
+		mutateOpts = append(mutateOpts, platformsOpt)
+	}
+
+	return mutateOpts, nil
 }
 
 func mutateExportStageConfig(mutateConfigFunc func(config v1.Config) (v1.Config, error)) func(ctx context.Context, config v1.Config) (v1.Config, error) {
@@ -879,6 +916,114 @@ func (storage *RepoStagesStorage) GetImportMetadataIDs(ctx context.Context, _ st
 	return ids, nil
 }
 
+func (storage *RepoStagesStorage) GetVulnerabilityScanMetadata(ctx context.Context, _ string, scanner vulnerability_scanner.ScannerType, imageDigest string) (*VulnerabilityScanMetadata, error) {
+	if debugStagesStorage() {
+		logboek.Context(ctx).Debug().LogF("-- RepoStagesStorage.GetVulnerabilityScanMetadata %s %s\n", scanner, imageDigest)
+	}
+
+	fullImageName, err := makeRepoVulnerabilityScanMetadataName(storage.RepoAddress, scanner, imageDigest)
+	if err != nil {
+		return nil, err
+	}
+
+	img, err := storage.DockerRegistry.GetRepoImage(ctx, fullImageName)
+	if docker_registry.IsImageNotFoundError(err) {
+		return nil, ErrVulnerabilityScanMetadataNotFound
+	}
+	if docker_registry.IsBrokenImageError(err) {
+		return nil, ErrBrokenImage
+	}
+	if err != nil {
+		return nil, fmt.Errorf("unable to get repo image %s: %w", fullImageName, err)
+	}
+
+	return newVulnerabilityScanMetadataFromLabels(img.Labels)
+}
+
+func (storage *RepoStagesStorage) PutVulnerabilityScanMetadata(ctx context.Context, projectName string, metadata *VulnerabilityScanMetadata) error {
+	if debugStagesStorage() {
+		logboek.Context(ctx).Debug().LogF("-- RepoStagesStorage.PutVulnerabilityScanMetadata %v\n", metadata)
+	}
+
+	fullImageName, err := makeRepoVulnerabilityScanMetadataName(storage.RepoAddress, metadata.Scanner, metadata.ImageDigest)
+	if err != nil {
+		return err
+	}
+
+	labels, err := metadata.ToLabelsMap()
+	if err != nil {
+		return err
+	}
+	labels[image.WerfLabel] = projectName
+
+	if err := storage.DockerRegistry.PushImage(ctx, fullImageName, &docker_registry.PushImageOptions{Labels: labels}); err != nil {
+		if docker_registry.IsStatusForbiddenErr(err) {
+			logboek.Context(ctx).Warn().LogF("WARNING: Failed to push vulnerability scan meta tag image %s\n", fullImageName)
+
+			return nil
+		}
+
+		return fmt.Errorf("unable to push image %s: %w", fullImageName, err)
+	}
+
+	return nil
+}
+
+func (storage *RepoStagesStorage) RmVulnerabilityScanMetadata(ctx context.Context, _, id string) error {
+	if debugStagesStorage() {
+		logboek.Context(ctx).Debug().LogF("-- RepoStagesStorage.RmVulnerabilityScanMetadata %s\n", id)
+	}
+
+	fullImageName := fmt.Sprintf(RepoVulnerabilityScanMetadata_ImageNameFormat, storage.RepoAddress, id)
+
+	img, err := storage.DockerRegistry.TryGetRepoImage(ctx, fullImageName)
+	if err != nil {
+		return fmt.Errorf("unable to get repo image %s: %w", fullImageName, err)
+	} else if img == nil {
+		return nil
+	}
+
+	if err := storage.DockerRegistry.DeleteRepoImage(ctx, img); err != nil {
+		return fmt.Errorf("unable to remove repo image %s: %w", img.Tag, err)
+	}
+
+	return nil
+}
+
+func (storage *RepoStagesStorage) GetVulnerabilityScanMetadataIDs(ctx context.Context, _ string, opts ...Option) ([]string, error) {
+	if debugStagesStorage() {
+		logboek.Context(ctx).Debug().LogF("-- RepoStagesStorage.GetVulnerabilityScanMetadataIDs\n")
+	}
+
+	o := makeOptions(opts...)
+	tags, err := storage.Tags(ctx, storage.RepoAddress, o.dockerRegistryOptions...)
+	if err != nil {
+		return nil, fmt.Errorf("unable to get repo %s tags: %w", storage.RepoAddress, err)
+	}
+
+	var ids []string
+	for _, tag := range tags {
+		if !strings.HasPrefix(tag, RepoVulnerabilityScanMetadata_ImageTagPrefix) {
+			continue
+		}
+
+		ids = append(ids, strings.TrimPrefix(tag, RepoVulnerabilityScanMetadata_ImageTagPrefix))
+	}
+
+	return ids, nil
+}
+
+// makeRepoVulnerabilityScanMetadataName returns the image name with the tag made of the scanner and the hex part of
+// the image digest, the tag does not match the stage tag format.
+func makeRepoVulnerabilityScanMetadataName(repoAddress string, scanner vulnerability_scanner.ScannerType, imageDigest string) (string, error) {
+	d, err := digest.Parse(imageDigest)
+	if err != nil {
+		return "", fmt.Errorf("invalid image digest %q: %w", imageDigest, err)
+	}
+
+	return fmt.Sprintf(RepoVulnerabilityScanMetadata_ImageNameFormat, repoAddress, makeVulnerabilityScanMetadataID(scanner, d)), nil
+}
+
 func getImportMetadataIDFromRepoTag(tag string) string {
 	return strings.TrimPrefix(tag, RepoImportMetadata_ImageTagPrefix)
 }
@@ -10,6 +10,7 @@ import (
 
 	"github.com/werf/werf/v2/pkg/container_backend"
 	"github.com/werf/werf/v2/pkg/image"
+	"github.com/werf/werf/v2/pkg/vulnerability_scanner"
 )
 
 func debugStagesStorage() bool {
@@ -27,11 +28,12 @@ const (
 )
 
 var (
-	ErrBrokenImage               = errors.New("broken image")
-	ErrStageNotFound             = errors.New("stage not found")
-	ErrStageRejected             = errors.New("stage rejected")
-	ErrImportMetadataNotFound    = errors.New("import metadata not found")
-	ErrCustomTagMetadataNotFound = errors.New("custom tag metadata not found")
+	ErrBrokenImage                       = errors.New("broken image")
+	ErrStageNotFound                     = errors.New("stage not found")
+	ErrStageRejected                     = errors.New("stage rejected")
+	ErrImportMetadataNotFound            = errors.New("import metadata not found")
+	ErrCustomTagMetadataNotFound         = errors.New("custom tag metadata not found")
+	ErrVulnerabilityScanMetadataNotFound = errors.New("vulnerability scan metadata not found")
 )
 
 func IsErrBrokenImage(err error) bool {
@@ -54,6 +56,10 @@ func IsErrCustomTagMetadataNotFound(err error) bool {
 	return errors.Is(err, ErrCustomTagMetadataNotFound)
 }
 
+func IsErrVulnerabilityScanMetadataNotFound(err error) bool {
+	return errors.Is(err, ErrVulnerabilityScanMetadataNotFound)
+}
+
 type FilterStagesAndProcessRelatedDataOptions struct {
 	SkipUsedImage            bool
 	RmForce                  bool
@@ -64,7 +70,9 @@ type StagesStorage interface {
 	GetStagesIDs(ctx context.Context, projectName string, opts ...Option) ([]image.StageID, error)
 	GetStagesIDsByDigest(ctx context.Context, projectName, digest string, parentStageCreationTs int64, opts ...Option) ([]image.StageID, error)
 	GetStageDesc(ctx context.Context, projectName string, stageID image.StageID) (*image.StageDesc, error)
-	ExportStage(ctx context.Context, stageDesc *image.StageDesc, destinationReference string, mutateConfigFunc func(config v1.Config) (v1.Config, error)) error
+	ExportStage(ctx context.Context, stageDesc *image.StageDesc, destinationReference string, opts ExportStageOptions) error
+	ExportStageToOCIArchive(ctx context.Context, stageDesc *image.StageDesc, archivePath, refName string, opts ExportStageOptions) error
+	WriteStageToOCILayout(ctx context.Context, stageDesc *image.StageDesc, layoutDir, refName string) error
 	DeleteStage(ctx context.Context, stageDesc *image.StageDesc, options DeleteImageOptions) error
 
 	AddStageCustomTag(ctx context.Context, stageDesc *image.StageDesc, tag string) error
@@ -111,6 +119,11 @@ type StagesStorage interface {
 	RmImportMetadata(ctx context.Context, projectName, id string) error
 	GetImportMetadataIDs(ctx context.Context, projectName string, opts ...Option) ([]string, error)
 
+	GetVulnerabilityScanMetadata(ctx context.Context, projectName string, scanner vulnerability_scanner.ScannerType, imageDigest string) (*VulnerabilityScanMetadata, error)
+	PutVulnerabilityScanMetadata(ctx context.Context, projectName string, metadata *VulnerabilityScanMetadata) error
+	RmVulnerabilityScanMetadata(ctx context.Context, projectName, id string) error
+	GetVulnerabilityScanMetadataIDs(ctx context.Context, projectName string, opts ...Option) ([]string, error)
+
 	GetClientIDRecords(ctx context.Context, projectName string, opts ...Option) ([]*ClientIDRecord, error)
 	PostClientIDRecord(ctx context.Context, projectName string, rec *ClientIDRecord) error
 	GetSyncServerRecords(ctx context.Context, projectName string, opts ...Option) ([]*SyncServerRecord, error)
@@ -141,6 +154,12 @@ type CopyFromStorageOptions struct {
 	IsMultiplatformImage bool
 }
 
+type ExportStageOptions struct {
+	MutateConfigFunc func(config v1.Config) (v1.Config, error)
+	// Platforms are the platforms of the multiplatform stage to export, all platforms are exported if empty.
+	Platforms []string
+}
+
 type SyncServerRecord struct {
 	Server            string
 	TimestampMillisec int64
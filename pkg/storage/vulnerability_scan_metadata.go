@@ -0,0 +1,69 @@
+package storage
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/opencontainers/go-digest"
+
+	"github.com/werf/werf/v2/pkg/image"
+	"github.com/werf/werf/v2/pkg/vulnerability_scanner"
+)
+
+// VulnerabilityScanMetadata is the cached result of the vulnerability scan of the image with the digest. ScannedAt is
+// zero for the metadata stored without the scan time.
+type VulnerabilityScanMetadata struct {
+	ImageDigest string
+	Scanner     vulnerability_scanner.ScannerType
+	Summary     *vulnerability_scanner.Summary
+	ScannedAt   time.Time
+}
+
+func (m *VulnerabilityScanMetadata) ToLabelsMap() (map[string]string, error) {
+	summary, err := json.Marshal(m.Summary)
+	if err != nil {
+		return nil, fmt.Errorf("unable to marshal vulnerability scan summary: %w", err)
+	}
+
+	return map[string]string{
+		image.WerfVulnerabilityScanMetadataImageDigestLabel: m.ImageDigest,
+		image.WerfVulnerabilityScanMetadataScannerLabel:     string(m.Scanner),
+		image.WerfVulnerabilityScanMetadataSummaryLabel:     string(summary),
+		image.WerfVulnerabilityScanMetadataScannedAtLabel:   m.ScannedAt.UTC().Format(time.RFC3339),
+	}, nil
+}
+
+// GetImageDigestEncodedFromVulnerabilityScanMetadataID returns the hex part of the digest of the image the metadata
+// with the id is stored for.
+func GetImageDigestEncodedFromVulnerabilityScanMetadataID(id string) string {
+	return id[strings.LastIndex(id, "-")+1:]
+}
+
+func makeVulnerabilityScanMetadataID(scanner vulnerability_scanner.ScannerType, imageDigest digest.Digest) string {
+	return fmt.Sprintf("%s-%s", scanner, imageDigest.Encoded())
+}
+
+func newVulnerabilityScanMetadataFromLabels(labels map[string]string) (*VulnerabilityScanMetadata, error) {
+	var summary *vulnerability_scanner.Summary
+	if err := json.Unmarshal([]byte(labels[image.WerfVulnerabilityScanMetadataSummaryLabel]), &summary); err != nil {
+		return nil, fmt.Errorf("unable to unmarshal vulnerability scan summary: %w", err)
+	}
+
+	var scannedAt time.Time
+	if value := labels[image.WerfVulnerabilityScanMetadataScannedAtLabel]; value != "" {
+		var err error
+		scannedAt, err = time.Parse(time.RFC3339, value)
+		if err != nil {
+			return nil, fmt.Errorf("unable to parse vulnerability scan time %q: %w", value, err)
+		}
+	}
+
+	return &VulnerabilityScanMetadata{
+		ImageDigest: labels[image.WerfVulnerabilityScanMetadataImageDigestLabel],
+		Scanner:     vulnerability_scanner.ScannerType(labels[image.WerfVulnerabilityScanMetadataScannerLabel]),
+		Summary:     summary,
+		ScannedAt:   scannedAt,
+	}, nil
+}
@@ -0,0 +1,40 @@
+package storage
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/werf/werf/v2/pkg/image"
+	"github.com/werf/werf/v2/pkg/vulnerability_scanner"
+)
+
+var _ = Describe("VulnerabilityScanMetadata", func() {
+	It("keeps the scan time in the labels", func() {
+		metadata := &VulnerabilityScanMetadata{
+			ImageDigest: "sha256:24454f830cdb571e2c4ad15481119c43b3cafd48dd869a9b2945d1036d1dc68d",
+			Scanner:     vulnerability_scanner.ScannerTypeTrivy,
+			Summary: &vulnerability_scanner.Summary{
+				Scanner:         vulnerability_scanner.ScannerTypeTrivy,
+				Vulnerabilities: map[vulnerability_scanner.Severity]int{vulnerability_scanner.SeverityHigh: 2},
+			},
+			ScannedAt: time.Date(2026, 10, 19, 12, 30, 0, 0, time.UTC),
+		}
+
+		labels, err := metadata.ToLabelsMap()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(labels).To(HaveKeyWithValue(image.WerfVulnerabilityScanMetadataScannedAtLabel, "2026-10-19T12:30:00Z"))
+
+		Expect(newVulnerabilityScanMetadataFromLabels(labels)).To(Equal(metadata))
+	})
+
+	It("returns the zero scan time for the metadata stored without it", func() {
+		metadata, err := newVulnerabilityScanMetadataFromLabels(map[string]string{
+			image.WerfVulnerabilityScanMetadataScannerLabel: string(vulnerability_scanner.ScannerTypeGrype),
+			image.WerfVulnerabilityScanMetadataSummaryLabel: `{"scanner":"grype","vulnerabilities":{}}`,
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(metadata.ScannedAt.IsZero()).To(BeTrue())
+	})
+})
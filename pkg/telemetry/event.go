@@ -1,6 +1,10 @@
 package telemetry
 
-import "os"
+import (
+	"os"
+
+	"github.com/werf/werf/v2/pkg/opstats"
+)
 
 type EventType string
 
@@ -12,6 +16,7 @@ const (
 	BuildFinishedEvent      EventType = "BuildFinished"
 	ImageBuildFinishedEvent EventType = "ImageBuildFinished"
 	StageBuildFinishedEvent EventType = "StageBuildFinished"
+	OperationsSummaryEvent  EventType = "OperationsSummary"
 )
 
 type Event interface {
@@ -130,3 +135,43 @@ func NewStageBuildFinished(image, stage string, durationMs int64, fromCache bool
 }
 
 func (*StageBuildFinished) GetType() EventType { return StageBuildFinishedEvent }
+
+type OperationSummary struct {
+	Operation   string `json:"operation"`
+	Count       int    `json:"count"`
+	TotalTimeMs int64  `json:"totalTimeMs"`
+	WallTimeMs  int64  `json:"wallTimeMs"`
+	AvgTimeMs   int64  `json:"avgTimeMs"`
+	MaxTimeMs   int64  `json:"maxTimeMs"`
+}
+
+type OperationsSummary struct {
+	Operations []OperationSummary `json:"operations"`
+	StageCache map[string]int     `json:"stageCache"`
+}
+
+func NewOperationsSummary(operations []opstats.OperationSummary, events []opstats.EventSummary) *OperationsSummary {
+	summary := &OperationsSummary{
+		Operations: make([]OperationSummary, 0, len(operations)),
+		StageCache: make(map[string]int, len(events)),
+	}
+
+	for _, s := range operations {
+		summary.Operations = append(summary.Operations, OperationSummary{
+			Operation:   string(s.Operation),
+			Count:       s.Count,
+			TotalTimeMs: s.TotalTime.Milliseconds(),
+			WallTimeMs:  s.WallTime.Milliseconds(),
+			AvgTimeMs:   s.AvgTime.Milliseconds(),
+			MaxTimeMs:   s.MaxTime.Milliseconds(),
+		})
+	}
+
+	for _, e := range events {
+		summary.StageCache[string(e.Event)] = e.Count
+	}
+
+	return summary
+}
+
+func (*OperationsSummary) GetType() EventType { return OperationsSummaryEvent }
@@ -3,13 +3,16 @@ package telemetry
 import (
 	"fmt"
 	neturl "net/url"
+	"strings"
 	"time"
 
 	"go.opentelemetry.io/otel/exporters/otlp/otlptrace"
 	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
 )
 
-func NewTraceExporter(url string) (*otlptrace.Exporter, error) {
+const endpointTracesPath = "/v1/traces"
+
+func NewTraceExporter(url string, headers map[string]string) (*otlptrace.Exporter, error) {
 	urlObj, err := neturl.Parse(url)
 	if err != nil {
 		return nil, fmt.Errorf("bad url: %w", err)
@@ -28,7 +31,61 @@ func NewTraceExporter(url string) (*otlptrace.Exporter, error) {
 		otlptracehttp.WithTimeout(1300*time.Millisecond),
 	)
 
+	if len(headers) > 0 {
+		opts = append(opts, otlptracehttp.WithHeaders(headers))
+	}
+
 	client := otlptracehttp.NewClient(opts...)
 
 	return otlptrace.NewUnstarted(client), nil
 }
+
+// GetEndpointTraceUrl returns the traces url of the OTLP/HTTP collector endpoint.
+// The default /v1/traces path is used when the endpoint has no path, e.g. http://collector:4318.
+func GetEndpointTraceUrl(endpoint string) (string, error) {
+	urlObj, err := neturl.Parse(endpoint)
+	if err != nil {
+		return "", fmt.Errorf("bad url: %w", err)
+	}
+
+	if urlObj.Scheme != "http" && urlObj.Scheme != "https" {
+		return "", fmt.Errorf("bad url %q: http or https scheme expected", endpoint)
+	}
+
+	if urlObj.Host == "" {
+		return "", fmt.Errorf("bad url %q: host expected", endpoint)
+	}
+
+	if urlObj.Path == "" || urlObj.Path == "/" {
+		urlObj.Path = endpointTracesPath
+	}
+
+	return urlObj.String(), nil
+}
+
+// ParseEndpointHeaders parses the comma-separated list of key=value pairs,
+// the same format as OTEL_EXPORTER_OTLP_HEADERS uses.
+func ParseEndpointHeaders(s string) (map[string]string, error) {
+	headers := map[string]string{}
+
+	for _, pair := range strings.Split(s, ",") {
+		if strings.TrimSpace(pair) == "" {
+			continue
+		}
+
+		key, value, ok := strings.Cut(pair, "=")
+		key = strings.TrimSpace(key)
+		if !ok || key == "" {
+			return nil, fmt.Errorf("bad header %q: key=value expected", pair)
+		}
+
+		unescapedValue, err := neturl.PathUnescape(strings.TrimSpace(value))
+		if err != nil {
+			return nil, fmt.Errorf("bad header %q value: %w", key, err)
+		}
+
+		headers[key] = unescapedValue
+	}
+
+	return headers, nil
+}
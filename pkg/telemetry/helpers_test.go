@@ -0,0 +1,57 @@
+package telemetry
+
+import (
+	"reflect"
+	"testing"
+)
+
+func Test_GetEndpointTraceUrl(t *testing.T) {
+	tests := []struct {
+		endpoint string
+		want     string
+		wantErr  bool
+	}{
+		{"http://otel-collector:4318", "http://otel-collector:4318/v1/traces", false},
+		{"https://otel.example.com/", "https://otel.example.com/v1/traces", false},
+		{"https://otel.example.com/custom/traces", "https://otel.example.com/custom/traces", false},
+		{"otel-collector:4318", "", true},
+		{"grpc://otel-collector:4317", "", true},
+		{"http://", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.endpoint, func(t *testing.T) {
+			got, err := GetEndpointTraceUrl(tt.endpoint)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("GetEndpointTraceUrl() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("GetEndpointTraceUrl() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_ParseEndpointHeaders(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    map[string]string
+		wantErr bool
+	}{
+		{"", map[string]string{}, false},
+		{"Authorization=Bearer%20token", map[string]string{"Authorization": "Bearer token"}, false},
+		{" X-Scope-OrgID = team , X-Key=a=b,", map[string]string{"X-Scope-OrgID": "team", "X-Key": "a=b"}, false},
+		{"Authorization", nil, true},
+		{"=value", nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, err := ParseEndpointHeaders(tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseEndpointHeaders() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseEndpointHeaders() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
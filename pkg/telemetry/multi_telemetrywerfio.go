@@ -0,0 +1,89 @@
+package telemetry
+
+import (
+	"context"
+
+	"github.com/werf/werf/v2/pkg/opstats"
+)
+
+// MultiTelemetryWerfIO sends each event to all underlying exporters,
+// e.g. to telemetry.werf.io and to the user-provided endpoint.
+type MultiTelemetryWerfIO []TelemetryWerfIOInterface
+
+func (m MultiTelemetryWerfIO) SetUserID(ctx context.Context, userID string) {
+	for _, t := range m {
+		t.SetUserID(ctx, userID)
+	}
+}
+
+func (m MultiTelemetryWerfIO) SetProjectID(ctx context.Context, projectID string) {
+	for _, t := range m {
+		t.SetProjectID(ctx, projectID)
+	}
+}
+
+func (m MultiTelemetryWerfIO) SetProjectName(ctx context.Context, projectName string) {
+	for _, t := range m {
+		t.SetProjectName(ctx, projectName)
+	}
+}
+
+func (m MultiTelemetryWerfIO) SetCommand(ctx context.Context, command string) {
+	for _, t := range m {
+		t.SetCommand(ctx, command)
+	}
+}
+
+func (m MultiTelemetryWerfIO) SetCommandOptions(ctx context.Context, options []CommandOption) {
+	for _, t := range m {
+		t.SetCommandOptions(ctx, options)
+	}
+}
+
+func (m MultiTelemetryWerfIO) CommandStarted(ctx context.Context) {
+	for _, t := range m {
+		t.CommandStarted(ctx)
+	}
+}
+
+func (m MultiTelemetryWerfIO) CommandExited(ctx context.Context, exitCode int) {
+	for _, t := range m {
+		t.CommandExited(ctx, exitCode)
+	}
+}
+
+func (m MultiTelemetryWerfIO) UnshallowFailed(ctx context.Context, err error) {
+	for _, t := range m {
+		t.UnshallowFailed(ctx, err)
+	}
+}
+
+func (m MultiTelemetryWerfIO) BuildStarted(ctx context.Context, imagesCount int, containerBackend string, inContainer bool) {
+	for _, t := range m {
+		t.BuildStarted(ctx, imagesCount, containerBackend, inContainer)
+	}
+}
+
+func (m MultiTelemetryWerfIO) BuildFinished(ctx context.Context, success bool) {
+	for _, t := range m {
+		t.BuildFinished(ctx, success)
+	}
+}
+
+func (m MultiTelemetryWerfIO) ImageBuildFinished(ctx context.Context, image string, durationMs int64, rebuilt bool, configType string) {
+	for _, t := range m {
+		t.ImageBuildFinished(ctx, image, durationMs, rebuilt, configType)
+	}
+}
+
+func (m MultiTelemetryWerfIO) StageBuildFinished(ctx context.Context, image, stage string, durationMs int64, fromCache bool, baseImageSource string, baseImagePulled bool) {
+	for _, t := range m {
+		t.StageBuildFinished(ctx, image, stage, durationMs, fromCache, baseImageSource, baseImagePulled)
+	}
+}
+
+func (m MultiTelemetryWerfIO) OperationsSummary(ctx context.Context, operations []opstats.OperationSummary, events []opstats.EventSummary) {
+	for _, t := range m {
+		t.OperationsSummary(ctx, operations, events)
+	}
+}
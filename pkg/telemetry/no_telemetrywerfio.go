@@ -1,12 +1,17 @@
 package telemetry
 
-import "context"
+import (
+	"context"
+
+	"github.com/werf/werf/v2/pkg/opstats"
+)
 
 type NoTelemetryWerfIO struct{}
 
 func (t *NoTelemetryWerfIO) CommandStarted(context.Context)                                  {}
 func (t *NoTelemetryWerfIO) SetUserID(context.Context, string)                               {}
 func (t *NoTelemetryWerfIO) SetProjectID(context.Context, string)                            {}
+func (t *NoTelemetryWerfIO) SetProjectName(context.Context, string)                          {}
 func (t *NoTelemetryWerfIO) SetCommand(context.Context, string)                              {}
 func (t *NoTelemetryWerfIO) CommandExited(context.Context, int)                              {}
 func (t *NoTelemetryWerfIO) SetCommandOptions(context.Context, []CommandOption)              {}
@@ -16,3 +21,6 @@ func (t *NoTelemetryWerfIO) BuildFinished(context.Context, bool)
 func (t *NoTelemetryWerfIO) ImageBuildFinished(context.Context, string, int64, bool, string) {}
 func (t *NoTelemetryWerfIO) StageBuildFinished(context.Context, string, string, int64, bool, string, bool) {
 }
+
+func (t *NoTelemetryWerfIO) OperationsSummary(context.Context, []opstats.OperationSummary, []opstats.EventSummary) {
+}
@@ -2,6 +2,7 @@ package telemetry
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
 	"time"
@@ -15,26 +16,40 @@ import (
 const (
 	TracesURL    = "https://telemetry.werf.io/v1/traces"
 	TelemetryEnv = "WERF_TELEMETRY"
+
+	EndpointEnv         = "WERF_TELEMETRY_ENDPOINT"
+	EndpointHeadersEnv  = "WERF_TELEMETRY_ENDPOINT_HEADERS"
+	EndpointDetailedEnv = "WERF_TELEMETRY_ENDPOINT_DETAILED"
 )
 
 var (
 	telemetrywerfio *TelemetryWerfIO
-	logFile         *os.File
+	// telemetryEndpoint sends the same events to the user-provided OTLP/HTTP collector.
+	telemetryEndpoint *TelemetryWerfIO
+	logFile           *os.File
 )
 
 func GetTelemetryWerfIO() TelemetryWerfIOInterface {
-	if telemetrywerfio == nil {
+	switch {
+	case telemetrywerfio != nil && telemetryEndpoint != nil:
+		return MultiTelemetryWerfIO{telemetrywerfio, telemetryEndpoint}
+	case telemetrywerfio != nil:
+		return telemetrywerfio
+	case telemetryEndpoint != nil:
+		return telemetryEndpoint
+	default:
 		return &NoTelemetryWerfIO{}
 	}
-	return telemetrywerfio
 }
 
 type TelemetryOptions struct {
 	ErrorHandlerFunc func(err error)
+	// Endpoint is the OTLP/HTTP collector endpoint, which receives the events independently of telemetry.werf.io.
+	Endpoint string
 }
 
 func Init(ctx context.Context, opts TelemetryOptions) error {
-	if !IsEnabled() {
+	if !IsEnabled() && opts.Endpoint == "" {
 		return nil
 	}
 
@@ -46,20 +61,56 @@ func Init(ctx context.Context, opts TelemetryOptions) error {
 		logFile = f
 	}
 
-	if t, err := NewTelemetryWerfIO(GetTraceUrl(), TelemetryWerfIOOptions{
-		HandleErrorFunc: opts.ErrorHandlerFunc,
-	}); err != nil {
-		return fmt.Errorf("unable to setup telemetry.werf.io exporter: %w", err)
-	} else {
-		telemetrywerfio = t
+	otel.SetErrorHandler(&callFuncErrorHandler{f: opts.ErrorHandlerFunc})
+
+	if IsEnabled() {
+		if t, err := NewTelemetryWerfIO(GetTraceUrl(), TelemetryWerfIOOptions{
+			HandleErrorFunc: opts.ErrorHandlerFunc,
+		}); err != nil {
+			return fmt.Errorf("unable to setup telemetry.werf.io exporter: %w", err)
+		} else {
+			telemetrywerfio = t
+		}
+
+		if err := telemetrywerfio.Start(ctx); err != nil {
+			return fmt.Errorf("unable to start telemetry.werf.io exporter: %w", err)
+		}
 	}
 
-	otel.SetErrorHandler(&callFuncErrorHandler{f: opts.ErrorHandlerFunc})
+	if opts.Endpoint != "" {
+		if err := initEndpoint(ctx, opts); err != nil {
+			return fmt.Errorf("unable to setup telemetry endpoint %q exporter: %w", opts.Endpoint, err)
+		}
+	}
+
+	return nil
+}
+
+func initEndpoint(ctx context.Context, opts TelemetryOptions) error {
+	url, err := GetEndpointTraceUrl(opts.Endpoint)
+	if err != nil {
+		return err
+	}
 
-	if err := telemetrywerfio.Start(ctx); err != nil {
-		return fmt.Errorf("unable to start telemetry.werf.io exporter: %w", err)
+	headers, err := ParseEndpointHeaders(os.Getenv(EndpointHeadersEnv))
+	if err != nil {
+		return fmt.Errorf("unable to parse $%s: %w", EndpointHeadersEnv, err)
 	}
 
+	t, err := NewTelemetryWerfIO(url, TelemetryWerfIOOptions{
+		HandleErrorFunc: opts.ErrorHandlerFunc,
+		Headers:         headers,
+		Detailed:        util.GetBoolEnvironmentDefaultFalse(EndpointDetailedEnv),
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := t.Start(ctx); err != nil {
+		return err
+	}
+	telemetryEndpoint = t
+
 	return nil
 }
 
@@ -72,10 +123,7 @@ func (h *callFuncErrorHandler) Handle(err error) {
 }
 
 func Shutdown(ctx context.Context) error {
-	if !IsEnabled() {
-		return nil
-	}
-	if telemetrywerfio == nil {
+	if telemetrywerfio == nil && telemetryEndpoint == nil {
 		return nil
 	}
 
@@ -83,7 +131,20 @@ func Shutdown(ctx context.Context) error {
 		defer logFile.Close()
 	}
 
-	return telemetrywerfio.Shutdown(ctx)
+	var errs []error
+	if telemetrywerfio != nil {
+		if err := telemetrywerfio.Shutdown(ctx); err != nil {
+			errs = append(errs, fmt.Errorf("telemetry.werf.io exporter: %w", err))
+		}
+	}
+
+	if telemetryEndpoint != nil {
+		if err := telemetryEndpoint.Shutdown(ctx); err != nil {
+			errs = append(errs, fmt.Errorf("telemetry endpoint exporter: %w", err))
+		}
+	}
+
+	return errors.Join(errs...)
 }
 
 func GetTraceUrl() string {
@@ -106,6 +167,12 @@ func IsEnabled() bool {
 	return true
 }
 
+// IsDetailed returns true if the events sent to the telemetry endpoint should be enriched
+// with the project name and the operations summary.
+func IsDetailed() bool {
+	return telemetryEndpoint != nil && telemetryEndpoint.detailed
+}
+
 func LogF(f string, args ...interface{}) {
 	if logFile == nil {
 		return
@@ -17,6 +17,7 @@ import (
 	"go.opentelemetry.io/otel/trace"
 
 	"github.com/werf/common-go/pkg/util"
+	"github.com/werf/werf/v2/pkg/opstats"
 	"github.com/werf/werf/v2/pkg/werf"
 )
 
@@ -28,6 +29,7 @@ const (
 type TelemetryWerfIOInterface interface {
 	SetUserID(ctx context.Context, projectID string)
 	SetProjectID(ctx context.Context, projectID string)
+	SetProjectName(ctx context.Context, projectName string)
 	SetCommand(ctx context.Context, command string)
 	SetCommandOptions(ctx context.Context, options []CommandOption)
 
@@ -39,17 +41,21 @@ type TelemetryWerfIOInterface interface {
 	BuildFinished(ctx context.Context, success bool)
 	ImageBuildFinished(ctx context.Context, image string, durationMs int64, rebuilt bool, configType string)
 	StageBuildFinished(ctx context.Context, image, stage string, durationMs int64, fromCache bool, baseImageSource string, baseImagePulled bool)
+	OperationsSummary(ctx context.Context, operations []opstats.OperationSummary, events []opstats.EventSummary)
 }
 
 type TelemetryWerfIO struct {
 	handleErrorFunc func(err error)
 	tracerProvider  *sdktrace.TracerProvider
 	traceExporter   *otlptrace.Exporter
+	// detailed enables the project name attribute and the OperationsSummary event.
+	detailed bool
 
 	startedAt      time.Time
 	executionID    string
 	userID         string
 	projectID      string
+	projectName    string
 	command        string
 	commandOptions []CommandOption
 
@@ -59,10 +65,12 @@ type TelemetryWerfIO struct {
 
 type TelemetryWerfIOOptions struct {
 	HandleErrorFunc func(err error)
+	Headers         map[string]string
+	Detailed        bool
 }
 
 func NewTelemetryWerfIO(url string, opts TelemetryWerfIOOptions) (*TelemetryWerfIO, error) {
-	e, err := NewTraceExporter(url)
+	e, err := NewTraceExporter(url, opts.Headers)
 	if err != nil {
 		return nil, fmt.Errorf("unable to create telemetry trace exporter: %w", err)
 	}
@@ -76,6 +84,7 @@ func NewTelemetryWerfIO(url string, opts TelemetryWerfIOOptions) (*TelemetryWerf
 			),
 		),
 		traceExporter: e,
+		detailed:      opts.Detailed,
 		executionID:   uuid.New().String(),
 		startedAt:     time.Now(),
 	}, nil
@@ -132,6 +141,10 @@ func (t *TelemetryWerfIO) SetProjectID(ctx context.Context, projectID string) {
 	t.projectID = projectID
 }
 
+func (t *TelemetryWerfIO) SetProjectName(_ context.Context, projectName string) {
+	t.projectName = projectName
+}
+
 func (t *TelemetryWerfIO) CommandStarted(ctx context.Context) {
 	t.sendEvent(ctx, NewCommandStarted(t.commandOptions))
 }
@@ -169,6 +182,13 @@ func (t *TelemetryWerfIO) StageBuildFinished(ctx context.Context, image, stage s
 	t.sendEvent(ctx, event)
 }
 
+func (t *TelemetryWerfIO) OperationsSummary(ctx context.Context, operations []opstats.OperationSummary, events []opstats.EventSummary) {
+	if !t.detailed {
+		return
+	}
+	t.sendEvent(ctx, NewOperationsSummary(operations, events))
+}
+
 func (t *TelemetryWerfIO) getAttributes() map[string]interface{} {
 	attributes := map[string]interface{}{
 		"version": werf.Version,
@@ -267,6 +287,10 @@ func (t *TelemetryWerfIO) sendEvent(ctx context.Context, event Event) error {
 			attribute.Key("attributes").String(string(rawAttributes)),
 			attribute.Key("schemaVersion").Int64(schemaVersion),
 		}
+
+		if t.detailed && t.projectName != "" {
+			attributes = append(attributes, attribute.Key("projectName").String(t.projectName))
+		}
 	}
 
 	span.SetAttributes(attributes...)
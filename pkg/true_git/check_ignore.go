@@ -0,0 +1,39 @@
+package true_git
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// CheckIgnore returns the paths ignored by .gitignore and other git exclude rules.
+// The paths should be relative to the work tree dir.
+func CheckIgnore(ctx context.Context, workTreeDir string, paths []string) ([]string, error) {
+	if len(paths) == 0 {
+		return nil, nil
+	}
+
+	checkIgnoreCmd := NewGitCmd(ctx, &GitCmdOptions{RepoDir: workTreeDir}, "check-ignore", "-z", "--stdin")
+	checkIgnoreCmd.Stdin = strings.NewReader(strings.Join(paths, "\x00") + "\x00")
+
+	if err := checkIgnoreCmd.Run(ctx); err != nil {
+		// Exit code 1 means that none of the paths are ignored.
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && exitErr.ExitCode() == 1 {
+			return nil, nil
+		}
+
+		return nil, fmt.Errorf("git check-ignore command failed: %w", err)
+	}
+
+	var ignoredPaths []string
+	for _, p := range strings.Split(checkIgnoreCmd.OutBuf.String(), "\x00") {
+		if p != "" {
+			ignoredPaths = append(ignoredPaths, p)
+		}
+	}
+
+	return ignoredPaths, nil
+}
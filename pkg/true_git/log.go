@@ -0,0 +1,96 @@
+package true_git
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type PathCommit struct {
+	Hash string
+	Time time.Time
+}
+
+// GetLastPathCommit returns the last commit reachable from the specified commit that changes the path or nil if there is no such commit.
+// In a shallow clone the history ends at the shallow boundary, so if the found commit is a boundary commit the path might have been
+// changed earlier and an error is returned instead of the possibly wrong commit.
+func GetLastPathCommit(ctx context.Context, gitDir, commit, path string) (*PathCommit, error) {
+	logCmd := NewGitCmd(ctx, &GitCmdOptions{RepoDir: gitDir}, "log", "-1", "--format=%H %ct", commit, "--", path)
+	if err := logCmd.Run(ctx); err != nil {
+		return nil, fmt.Errorf("git log command failed: %w", err)
+	}
+
+	pathCommit, err := parseLastPathCommitOutput(logCmd.OutBuf.String())
+	if err != nil || pathCommit == nil {
+		return pathCommit, err
+	}
+
+	shallowCommits, err := getShallowCommits(ctx, gitDir)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, shallowCommit := range shallowCommits {
+		if shallowCommit == pathCommit.Hash {
+			return nil, fmt.Errorf("the last commit %s changing the path %q is the shallow clone boundary and the path might have been changed earlier: fetch more history or unshallow the repository", pathCommit.Hash, path)
+		}
+	}
+
+	return pathCommit, nil
+}
+
+func getShallowCommits(ctx context.Context, gitDir string) ([]string, error) {
+	commonDirCmd := NewGitCmd(ctx, &GitCmdOptions{RepoDir: gitDir}, "rev-parse", "--git-common-dir")
+	if err := commonDirCmd.Run(ctx); err != nil {
+		return nil, fmt.Errorf("git rev-parse command failed: %w", err)
+	}
+
+	commonDir := strings.TrimSpace(commonDirCmd.OutBuf.String())
+	if !filepath.IsAbs(commonDir) {
+		commonDir = filepath.Join(gitDir, commonDir)
+	}
+
+	data, err := os.ReadFile(filepath.Join(commonDir, "shallow"))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("unable to read shallow file: %w", err)
+	}
+
+	return parseShallowFile(string(data)), nil
+}
+
+func parseShallowFile(data string) []string {
+	var commits []string
+	for _, line := range strings.Split(data, "\n") {
+		if commit := strings.TrimSpace(line); commit != "" {
+			commits = append(commits, commit)
+		}
+	}
+
+	return commits
+}
+
+func parseLastPathCommitOutput(output string) (*PathCommit, error) {
+	output = strings.TrimSpace(output)
+	if output == "" {
+		return nil, nil
+	}
+
+	hash, unixTime, ok := strings.Cut(output, " ")
+	if !ok {
+		return nil, fmt.Errorf("malformed git log output %q", output)
+	}
+
+	sec, err := strconv.ParseInt(unixTime, 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("malformed commit time in git log output %q: %w", output, err)
+	}
+
+	return &PathCommit{Hash: hash, Time: time.Unix(sec, 0)}, nil
+}
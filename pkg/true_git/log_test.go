@@ -0,0 +1,41 @@
+package true_git
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("parseLastPathCommitOutput", func() {
+	It("returns nil if path has no commits", func() {
+		commit, err := parseLastPathCommitOutput("\n")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(commit).To(BeNil())
+	})
+
+	It("parses hash and commit time", func() {
+		commit, err := parseLastPathCommitOutput("07ff53c8ac03054a1a6aec38fcc0d690bcf508c7 1700000000\n")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(commit).To(Equal(&PathCommit{Hash: "07ff53c8ac03054a1a6aec38fcc0d690bcf508c7", Time: time.Unix(1700000000, 0)}))
+	})
+
+	It("returns error on malformed output", func() {
+		_, err := parseLastPathCommitOutput("garbage")
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("malformed"))
+	})
+})
+
+var _ = Describe("parseShallowFile", func() {
+	It("returns boundary commits", func() {
+		Expect(parseShallowFile("07ff53c8ac03054a1a6aec38fcc0d690bcf508c7\n1b4fa2a6d8e1e0f7c3d0a4e9b2c8f5a7d6e3b1c0\n")).To(Equal([]string{
+			"07ff53c8ac03054a1a6aec38fcc0d690bcf508c7",
+			"1b4fa2a6d8e1e0f7c3d0a4e9b2c8f5a7d6e3b1c0",
+		}))
+	})
+
+	It("returns nothing for empty file", func() {
+		Expect(parseShallowFile("")).To(BeEmpty())
+	})
+})
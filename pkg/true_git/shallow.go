@@ -98,3 +98,103 @@ func parseLsRemoteTagsOutput(out string) (map[string]RemoteTagRef, error) {
 
 	return res, nil
 }
+
+type LsRemoteRefOptions struct {
+	Env []string
+}
+
+// LsRemoteRef resolves the commit SHA of the ref of the remote repository the same way as git resolves the ref name:
+// the full ref name takes precedence over the tag, and the tag takes precedence over the branch. HEAD is resolved if
+// the ref is empty.
+func LsRemoteRef(ctx context.Context, url, ref string, opts LsRemoteRefOptions) (string, error) {
+	lsRemoteCmd := NewGitCmd(ctx, &GitCmdOptions{Env: opts.Env}, "ls-remote", url)
+	if err := lsRemoteCmd.Run(ctx); err != nil {
+		return "", fmt.Errorf("git ls-remote command failed: %w", err)
+	}
+
+	return parseLsRemoteRefOutput(lsRemoteCmd.OutBuf.String(), ref)
+}
+
+func parseLsRemoteRefOutput(out, ref string) (string, error) {
+	refs := map[string]string{}
+
+	for _, line := range strings.Split(out, "\n") {
+		if line == "" {
+			continue
+		}
+
+		parts := strings.SplitN(line, "\t", 2)
+		if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+			return "", fmt.Errorf("malformed git ls-remote output line %q", line)
+		}
+
+		refs[parts[1]] = parts[0]
+	}
+
+	candidates := []string{"HEAD"}
+	if ref != "" {
+		candidates = []string{ref, "refs/" + ref, "refs/tags/" + ref, "refs/heads/" + ref}
+	}
+
+	for _, candidate := range candidates {
+		// The peeled commit SHA of the annotated tag.
+		if sha, ok := refs[candidate+"^{}"]; ok {
+			return sha, nil
+		}
+
+		if sha, ok := refs[candidate]; ok {
+			return sha, nil
+		}
+	}
+
+	return "", fmt.Errorf("ref %q not found in remote", ref)
+}
+
+type ShallowCheckoutOptions struct {
+	Env []string
+}
+
+// ShallowCheckout fetches the commit of the remote repository with depth=1 and checks it out into the new work tree
+// dir along with the submodules. The ref is fetched instead of the commit if specified, because not every server
+// allows to fetch the unadvertised commit, and the fetched commit is verified to be the expected one.
+func ShallowCheckout(ctx context.Context, workTreeDir, url, ref, commit string, opts ShallowCheckoutOptions) error {
+	initCmd := NewGitCmd(ctx, nil, "init", "--quiet", workTreeDir)
+	if err := initCmd.Run(ctx); err != nil {
+		return fmt.Errorf("git init command failed: %w", err)
+	}
+
+	remoteAddCmd := NewGitCmd(ctx, &GitCmdOptions{RepoDir: workTreeDir}, "remote", "add", "origin", url)
+	if err := remoteAddCmd.Run(ctx); err != nil {
+		return fmt.Errorf("git remote add command failed: %w", err)
+	}
+
+	fetchRef := commit
+	if ref != "" {
+		fetchRef = ref
+	}
+
+	if err := ShallowFetch(ctx, workTreeDir, []string{fetchRef}, ShallowFetchOptions{Env: opts.Env}); err != nil {
+		return err
+	}
+
+	revParseCmd := NewGitCmd(ctx, &GitCmdOptions{RepoDir: workTreeDir}, "rev-parse", "FETCH_HEAD^{commit}")
+	if err := revParseCmd.Run(ctx); err != nil {
+		return fmt.Errorf("git rev-parse command failed: %w", err)
+	}
+
+	if fetchedCommit := strings.TrimSpace(revParseCmd.OutBuf.String()); fetchedCommit != commit {
+		return fmt.Errorf("fetched commit %s does not match expected commit %s", fetchedCommit, commit)
+	}
+
+	checkoutCmd := NewGitCmd(ctx, &GitCmdOptions{RepoDir: workTreeDir}, "-c", "advice.detachedHead=false", "checkout", "--quiet", commit)
+	if err := checkoutCmd.Run(ctx); err != nil {
+		return fmt.Errorf("git checkout command failed: %w", err)
+	}
+
+	submUpdateCmd := NewGitCmd(ctx, &GitCmdOptions{RepoDir: workTreeDir, Env: opts.Env}, "submodule", "update", "--init", "--recursive", "--depth=1")
+	if err := submUpdateCmd.Run(ctx); err != nil {
+		return fmt.Errorf("git submodule update command failed: %w", err)
+	}
+
+	return nil
+}
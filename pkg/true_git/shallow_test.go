@@ -57,6 +57,44 @@ var _ = Describe("parseLsRemoteTagsOutput", func() {
 	})
 })
 
+var _ = Describe("parseLsRemoteRefOutput", func() {
+	const (
+		shaHead      = "1111111111111111111111111111111111111111"
+		shaBranch    = "2222222222222222222222222222222222222222"
+		shaAnnotObj  = "3333333333333333333333333333333333333333"
+		shaAnnotPeel = "4444444444444444444444444444444444444444"
+	)
+
+	out := shaHead + "\tHEAD\n" +
+		shaHead + "\trefs/heads/main\n" +
+		shaBranch + "\trefs/heads/v1\n" +
+		shaAnnotObj + "\trefs/tags/v1\n" +
+		shaAnnotPeel + "\trefs/tags/v1^{}\n"
+
+	DescribeTable("resolves the ref",
+		func(ref, expected string) {
+			got, err := parseLsRemoteRefOutput(out, ref)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(got).To(Equal(expected))
+		},
+		Entry("empty ref is HEAD", "", shaHead),
+		Entry("branch", "main", shaHead),
+		Entry("tag takes precedence over branch", "v1", shaAnnotPeel),
+		Entry("full branch ref name", "refs/heads/v1", shaBranch),
+		Entry("short ref name with refs/ prefix omitted", "heads/v1", shaBranch),
+	)
+
+	It("returns error if the ref is not found", func() {
+		_, err := parseLsRemoteRefOutput(out, "does-not-exist")
+		Expect(err).To(MatchError(ContainSubstring(`ref "does-not-exist" not found in remote`)))
+	})
+
+	It("returns error on malformed line", func() {
+		_, err := parseLsRemoteRefOutput("garbage\n", "")
+		Expect(err).To(MatchError(ContainSubstring("malformed")))
+	})
+})
+
 var _ = Describe("shallow shell git helpers", func() {
 	var (
 		sourcePath string
@@ -163,4 +201,26 @@ var _ = Describe("shallow shell git helpers", func() {
 			Expect(revParse(ctx, mirrorPath, "refs/tags/movable")).To(Equal(headSHA))
 		})
 	})
+
+	Describe("LsRemoteRef", func() {
+		It("resolves the branch and the peeled annotated tag", func(ctx SpecContext) {
+			Expect(LsRemoteRef(ctx, sourcePath, "main", LsRemoteRefOptions{})).To(Equal(headSHA))
+			Expect(LsRemoteRef(ctx, sourcePath, "annot", LsRemoteRefOptions{})).To(Equal(headSHA))
+			Expect(LsRemoteRef(ctx, sourcePath, "", LsRemoteRefOptions{})).To(Equal(headSHA))
+		})
+	})
+
+	Describe("ShallowCheckout", func() {
+		It("checks out the commit of the ref into the work tree", func(ctx SpecContext) {
+			Expect(ShallowCheckout(ctx, mirrorPath, sourcePath, "annot", headSHA, ShallowCheckoutOptions{})).To(Succeed())
+
+			Expect(revParse(ctx, mirrorPath, "HEAD")).To(Equal(headSHA))
+			Expect(revParse(ctx, mirrorPath, "--is-shallow-repository")).To(Equal("true"))
+		})
+
+		It("returns error if the ref points to another commit", func(ctx SpecContext) {
+			err := ShallowCheckout(ctx, mirrorPath, sourcePath, "main", strings.Repeat("0", 40), ShallowCheckoutOptions{})
+			Expect(err).To(MatchError(ContainSubstring("does not match expected commit")))
+		})
+	})
 })
@@ -0,0 +1,141 @@
+package vulnerability_scanner
+
+import (
+	"encoding/json"
+	"strconv"
+)
+
+type trivyReport struct {
+	Results []struct {
+		Vulnerabilities []struct {
+			VulnerabilityID string `json:"VulnerabilityID"`
+			Severity        string `json:"Severity"`
+		} `json:"Vulnerabilities"`
+	} `json:"Results"`
+}
+
+// parseTrivyReport parses the report of `trivy image --format json`.
+func parseTrivyReport(data []byte) (*Summary, error) {
+	var report trivyReport
+	if err := json.Unmarshal(data, &report); err != nil {
+		return nil, err
+	}
+
+	summary := newSummary(ScannerTypeTrivy)
+	for _, result := range report.Results {
+		for _, vulnerability := range result.Vulnerabilities {
+			summary.add(normalizeSeverity(vulnerability.Severity))
+		}
+	}
+
+	return summary, nil
+}
+
+type grypeReport struct {
+	Matches []struct {
+		Vulnerability struct {
+			ID       string `json:"id"`
+			Severity string `json:"severity"`
+		} `json:"vulnerability"`
+	} `json:"matches"`
+}
+
+// parseGrypeReport parses the report of `grype --output json`.
+func parseGrypeReport(data []byte) (*Summary, error) {
+	var report grypeReport
+	if err := json.Unmarshal(data, &report); err != nil {
+		return nil, err
+	}
+
+	summary := newSummary(ScannerTypeGrype)
+	for _, match := range report.Matches {
+		summary.add(normalizeSeverity(match.Vulnerability.Severity))
+	}
+
+	return summary, nil
+}
+
+type sarifReport struct {
+	Runs []struct {
+		Tool struct {
+			Driver struct {
+				Rules []sarifRule `json:"rules"`
+			} `json:"driver"`
+		} `json:"tool"`
+		Results []struct {
+			RuleID    string `json:"ruleId"`
+			RuleIndex *int   `json:"ruleIndex"`
+		} `json:"results"`
+	} `json:"runs"`
+}
+
+type sarifRule struct {
+	ID         string `json:"id"`
+	Properties struct {
+		Tags             []string `json:"tags"`
+		SecuritySeverity string   `json:"security-severity"`
+	} `json:"properties"`
+}
+
+// parseSARIFReport parses the report of `trivy image --format sarif` or `grype --output sarif`. Each result is a
+// vulnerability with the severity of its rule.
+func parseSARIFReport(scannerType ScannerType, data []byte) (*Summary, error) {
+	var report sarifReport
+	if err := json.Unmarshal(data, &report); err != nil {
+		return nil, err
+	}
+
+	summary := newSummary(scannerType)
+	for _, run := range report.Runs {
+		rules := run.Tool.Driver.Rules
+
+		rulesByID := map[string]sarifRule{}
+		for _, rule := range rules {
+			rulesByID[rule.ID] = rule
+		}
+
+		for _, result := range run.Results {
+			rule, ok := rulesByID[result.RuleID]
+			if !ok && result.RuleIndex != nil && *result.RuleIndex >= 0 && *result.RuleIndex < len(rules) {
+				rule, ok = rules[*result.RuleIndex], true
+			}
+
+			severity := SeverityUnknown
+			if ok {
+				severity = rule.severity()
+			}
+
+			summary.add(severity)
+		}
+	}
+
+	return summary, nil
+}
+
+// severity returns the severity from the rule tags (trivy) or converts the CVSS score of the rule (grype) the same way
+// as the CVSS v3 qualitative severity rating scale.
+func (r sarifRule) severity() Severity {
+	for _, tag := range r.Properties.Tags {
+		if severity, err := ParseSeverity(tag); err == nil && severity != SeverityUnknown {
+			return severity
+		}
+	}
+
+	score, err := strconv.ParseFloat(r.Properties.SecuritySeverity, 64)
+	if err != nil {
+		return SeverityUnknown
+	}
+
+	switch {
+	case score >= 9:
+		return SeverityCritical
+	case score >= 7:
+		return SeverityHigh
+	case score >= 4:
+		return SeverityMedium
+	case score > 0:
+		return SeverityLow
+	default:
+		return SeverityUnknown
+	}
+}
@@ -0,0 +1,90 @@
+package vulnerability_scanner
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Report", func() {
+	It("should count trivy vulnerabilities by severity", func() {
+		summary, err := parseTrivyReport([]byte(`{
+			"Results": [
+				{"Target": "alpine", "Vulnerabilities": [
+					{"VulnerabilityID": "CVE-2024-0001", "Severity": "CRITICAL"},
+					{"VulnerabilityID": "CVE-2024-0002", "Severity": "HIGH"},
+					{"VulnerabilityID": "CVE-2024-0003", "Severity": "HIGH"}
+				]},
+				{"Target": "app/go.mod"},
+				{"Target": "app/package-lock.json", "Vulnerabilities": [
+					{"VulnerabilityID": "CVE-2024-0004", "Severity": "LOW"}
+				]}
+			]
+		}`))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(summary.Scanner).To(Equal(ScannerTypeTrivy))
+		Expect(summary.Vulnerabilities).To(Equal(map[Severity]int{
+			SeverityCritical: 1,
+			SeverityHigh:     2,
+			SeverityLow:      1,
+		}))
+		Expect(summary.Total()).To(Equal(4))
+	})
+
+	It("should count grype vulnerabilities by severity", func() {
+		summary, err := parseGrypeReport([]byte(`{
+			"matches": [
+				{"vulnerability": {"id": "CVE-2024-0001", "severity": "Medium"}},
+				{"vulnerability": {"id": "CVE-2024-0002", "severity": "Negligible"}},
+				{"vulnerability": {"id": "CVE-2024-0003", "severity": "Unknown"}},
+				{"vulnerability": {"id": "CVE-2024-0004", "severity": "Moderate"}}
+			]
+		}`))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(summary.Scanner).To(Equal(ScannerTypeGrype))
+		Expect(summary.Vulnerabilities).To(Equal(map[Severity]int{
+			SeverityMedium:  1,
+			SeverityLow:     1,
+			SeverityUnknown: 2,
+		}))
+	})
+
+	It("should count SARIF vulnerabilities by the severity of the rules", func() {
+		summary, err := parseSARIFReport(ScannerTypeTrivy, []byte(`{
+			"version": "2.1.0",
+			"runs": [{
+				"tool": {"driver": {"rules": [
+					{"id": "CVE-2024-0001", "properties": {"security-severity": "9.8", "tags": ["vulnerability", "security", "CRITICAL"]}},
+					{"id": "CVE-2024-0002", "properties": {"security-severity": "7.5", "tags": ["vulnerability", "security", "MEDIUM"]}},
+					{"id": "CVE-2024-0003-openssl", "properties": {"security-severity": "4.3"}},
+					{"id": "CVE-2024-0004-zlib", "properties": {}}
+				]}},
+				"results": [
+					{"ruleId": "CVE-2024-0001", "ruleIndex": 0},
+					{"ruleId": "CVE-2024-0002", "ruleIndex": 1},
+					{"ruleId": "CVE-2024-0002", "ruleIndex": 1},
+					{"ruleIndex": 2},
+					{"ruleId": "CVE-2024-0004-zlib"},
+					{"ruleId": "CVE-2024-0005"}
+				]
+			}]
+		}`))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(summary.Scanner).To(Equal(ScannerTypeTrivy))
+		Expect(summary.Vulnerabilities).To(Equal(map[Severity]int{
+			SeverityCritical: 1,
+			SeverityMedium:   3,
+			SeverityUnknown:  2,
+		}))
+	})
+
+	It("should return an empty summary for the image without vulnerabilities", func() {
+		summary, err := parseGrypeReport([]byte(`{"matches": []}`))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(summary.Total()).To(Equal(0))
+	})
+
+	It("should fail on the malformed report", func() {
+		_, err := parseTrivyReport([]byte(`not a json`))
+		Expect(err).To(HaveOccurred())
+	})
+})
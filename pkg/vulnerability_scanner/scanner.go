@@ -0,0 +1,120 @@
+package vulnerability_scanner
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+
+	"github.com/werf/logboek"
+	werfExec "github.com/werf/werf/v2/pkg/werf/exec"
+)
+
+type ScannerType string
+
+const (
+	ScannerTypeTrivy ScannerType = "trivy"
+	ScannerTypeGrype ScannerType = "grype"
+)
+
+var ScannerTypes = []ScannerType{ScannerTypeTrivy, ScannerTypeGrype}
+
+func ParseScannerType(s string) (ScannerType, error) {
+	for _, scannerType := range ScannerTypes {
+		if s == string(scannerType) {
+			return scannerType, nil
+		}
+	}
+
+	return "", fmt.Errorf("unsupported vulnerability scanner %q: expected one of %v", s, ScannerTypes)
+}
+
+// ReportFormat is the format the scanner writes the report in.
+type ReportFormat string
+
+const (
+	ReportFormatJSON  ReportFormat = "json"
+	ReportFormatSARIF ReportFormat = "sarif"
+)
+
+var ReportFormats = []ReportFormat{ReportFormatJSON, ReportFormatSARIF}
+
+func ParseReportFormat(s string) (ReportFormat, error) {
+	for _, reportFormat := range ReportFormats {
+		if s == string(reportFormat) {
+			return reportFormat, nil
+		}
+	}
+
+	return "", fmt.Errorf("unsupported vulnerability report format %q: expected one of %v", s, ReportFormats)
+}
+
+type ScanOptions struct {
+	// ImageRef is the reference of the scanned image in the registry.
+	ImageRef string
+	// OCILayoutDir is the OCI image layout with the scanned image, the scanner reads the image from it and does not
+	// pull the image on its own.
+	OCILayoutDir string
+	// ReportFormat is the format the scanner report is requested and parsed in, JSON if empty.
+	ReportFormat ReportFormat
+}
+
+// Scan runs the external scanner against the image and returns the summary of the found vulnerabilities. The scanner
+// binary is looked up in PATH.
+func Scan(ctx context.Context, scannerType ScannerType, opts ScanOptions) (*Summary, error) {
+	reportFormat := opts.ReportFormat
+	if reportFormat == "" {
+		reportFormat = ReportFormatJSON
+	}
+
+	var args []string
+	var parseReport func(data []byte) (*Summary, error)
+	switch scannerType {
+	case ScannerTypeTrivy:
+		args = []string{"image", "--quiet", "--scanners", "vuln", "--format", string(reportFormat), "--input", opts.OCILayoutDir}
+		parseReport = parseTrivyReport
+	case ScannerTypeGrype:
+		args = []string{fmt.Sprintf("oci-dir:%s", opts.OCILayoutDir), "--output", string(reportFormat), "--quiet"}
+		parseReport = parseGrypeReport
+	default:
+		panic(fmt.Sprintf("unexpected scanner type %q", scannerType))
+	}
+
+	if reportFormat == ReportFormatSARIF {
+		parseReport = func(data []byte) (*Summary, error) {
+			return parseSARIFReport(scannerType, data)
+		}
+	}
+
+	bin, err := exec.LookPath(string(scannerType))
+	if err != nil {
+		return nil, fmt.Errorf("unable to find %s binary: %w", scannerType, err)
+	}
+
+	cmd := werfExec.CommandContextCancellation(ctx, bin, args...)
+	stdout, stderr := &bytes.Buffer{}, &bytes.Buffer{}
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+
+	logboek.Context(ctx).Debug().LogF("Scanning image %s: %s\n", opts.ImageRef, cmd)
+
+	if err := cmd.Run(); err != nil {
+		werfExec.TerminateIfCanceled(ctx)
+
+		var errExit *exec.ExitError
+		if errors.As(err, &errExit) {
+			return nil, fmt.Errorf("error running %s for image %s: %w\nStderr:\n%s", scannerType, opts.ImageRef, err, strings.TrimSpace(stderr.String()))
+		}
+
+		return nil, fmt.Errorf("error running %s for image %s: %w", scannerType, opts.ImageRef, err)
+	}
+
+	summary, err := parseReport(stdout.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse %s report for image %s: %w", scannerType, opts.ImageRef, err)
+	}
+
+	return summary, nil
+}
@@ -0,0 +1,55 @@
+package vulnerability_scanner
+
+import (
+	"fmt"
+	"strings"
+)
+
+type Severity string
+
+const (
+	SeverityUnknown  Severity = "UNKNOWN"
+	SeverityLow      Severity = "LOW"
+	SeverityMedium   Severity = "MEDIUM"
+	SeverityHigh     Severity = "HIGH"
+	SeverityCritical Severity = "CRITICAL"
+)
+
+// Severities is the list of supported severities in ascending order.
+var Severities = []Severity{SeverityUnknown, SeverityLow, SeverityMedium, SeverityHigh, SeverityCritical}
+
+// ParseSeverity parses the severity case-insensitively.
+func ParseSeverity(s string) (Severity, error) {
+	for _, severity := range Severities {
+		if strings.EqualFold(s, string(severity)) {
+			return severity, nil
+		}
+	}
+
+	return "", fmt.Errorf("unsupported severity %q: expected one of %v", s, Severities)
+}
+
+// normalizeSeverity converts the severity reported by the scanner to the supported one, the unexpected severity is
+// considered unknown.
+func normalizeSeverity(s string) Severity {
+	if strings.EqualFold(s, "negligible") {
+		return SeverityLow
+	}
+
+	severity, err := ParseSeverity(s)
+	if err != nil {
+		return SeverityUnknown
+	}
+
+	return severity
+}
+
+func (s Severity) rank() int {
+	for i, severity := range Severities {
+		if s == severity {
+			return i
+		}
+	}
+
+	return 0
+}
@@ -0,0 +1,39 @@
+package vulnerability_scanner
+
+// Summary is the number of the found vulnerabilities by severity.
+type Summary struct {
+	Scanner         ScannerType      `json:"scanner"`
+	Vulnerabilities map[Severity]int `json:"vulnerabilities"`
+}
+
+func newSummary(scanner ScannerType) *Summary {
+	return &Summary{
+		Scanner:         scanner,
+		Vulnerabilities: map[Severity]int{},
+	}
+}
+
+func (s *Summary) add(severity Severity) {
+	s.Vulnerabilities[severity]++
+}
+
+func (s *Summary) Total() int {
+	var total int
+	for _, count := range s.Vulnerabilities {
+		total += count
+	}
+
+	return total
+}
+
+// CountAtLeast returns the number of the vulnerabilities with the severity equal to or higher than the threshold.
+func (s *Summary) CountAtLeast(threshold Severity) int {
+	var count int
+	for severity, n := range s.Vulnerabilities {
+		if severity.rank() >= threshold.rank() {
+			count += n
+		}
+	}
+
+	return count
+}
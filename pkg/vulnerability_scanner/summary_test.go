@@ -0,0 +1,39 @@
+package vulnerability_scanner
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Summary", func() {
+	DescribeTable("counting vulnerabilities at or above the threshold",
+		func(threshold Severity, expected int) {
+			summary := &Summary{Vulnerabilities: map[Severity]int{
+				SeverityUnknown:  1,
+				SeverityLow:      2,
+				SeverityMedium:   3,
+				SeverityHigh:     4,
+				SeverityCritical: 5,
+			}}
+			Expect(summary.CountAtLeast(threshold)).To(Equal(expected))
+		},
+		Entry("unknown", SeverityUnknown, 15),
+		Entry("medium", SeverityMedium, 12),
+		Entry("critical", SeverityCritical, 5),
+	)
+
+	DescribeTable("parsing severity",
+		func(s string, expected Severity, expectErr bool) {
+			severity, err := ParseSeverity(s)
+			if expectErr {
+				Expect(err).To(HaveOccurred())
+				return
+			}
+			Expect(err).NotTo(HaveOccurred())
+			Expect(severity).To(Equal(expected))
+		},
+		Entry("upper case", "HIGH", SeverityHigh, false),
+		Entry("lower case", "critical", SeverityCritical, false),
+		Entry("unsupported", "severe", Severity(""), true),
+	)
+})
@@ -44,7 +44,9 @@ func ExitCode(err error) int {
 
 	var exitErr *exec.ExitError
 
-	if errors.As(err, &exitErr) {
+	if code, ok := IsExitCodeError(err); ok {
+		return code
+	} else if errors.As(err, &exitErr) {
 		return exitErr.ExitCode()
 	} else {
 		return 1
@@ -0,0 +1,35 @@
+package exec
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ExitCodeError is returned when a command executed outside of the werf process (e.g. in a Kubernetes Pod) exits
+// with a non-zero code which should become the exit code of werf itself.
+type ExitCodeError struct {
+	Code int
+	Err  error
+}
+
+func NewExitCodeError(code int, err error) *ExitCodeError {
+	return &ExitCodeError{Code: code, Err: err}
+}
+
+func (e *ExitCodeError) Error() string {
+	return fmt.Sprintf("command exited with code %d: %s", e.Code, e.Err)
+}
+
+func (e *ExitCodeError) Unwrap() error {
+	return e.Err
+}
+
+// IsExitCodeError checks whether the error chain contains ExitCodeError and returns its code.
+func IsExitCodeError(err error) (int, bool) {
+	var exitCodeErr *ExitCodeError
+	if errors.As(err, &exitCodeErr) {
+		return exitCodeErr.Code, true
+	}
+
+	return 0, false
+}
@@ -0,0 +1,30 @@
+package exec_test
+
+import (
+	"errors"
+	"fmt"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	werfExec "github.com/werf/werf/v2/pkg/werf/exec"
+)
+
+var _ = Describe("ExitCodeError", func() {
+	It("should be found in a wrapped error chain", func() {
+		origErr := errors.New("command terminated with exit code 3")
+		err := fmt.Errorf("error running command: %w", werfExec.NewExitCodeError(3, origErr))
+
+		code, ok := werfExec.IsExitCodeError(err)
+		Expect(ok).To(BeTrue())
+		Expect(code).To(Equal(3))
+		Expect(errors.Is(err, origErr)).To(BeTrue())
+		Expect(werfExec.ExitCode(err)).To(Equal(3))
+	})
+
+	It("should not be found in an unrelated error", func() {
+		_, ok := werfExec.IsExitCodeError(errors.New("some error"))
+		Expect(ok).To(BeFalse())
+		Expect(werfExec.ExitCode(errors.New("some error"))).To(Equal(1))
+	})
+})
@@ -65,7 +65,7 @@ var _ = Describe("Images dependencies", Label("e2e", "build", "extra"), func() {
 
 	When("werf.yaml contains stapel and dockerfile images which used as dependencies in another stapel and dockerfile images", func() {
 		It("should successfully build images using specified dependencies", func(ctx SpecContext) {
-			containerBackend := container_backend.NewDockerServerBackend(werf.HostLocker().Locker())
+			containerBackend := container_backend.NewDockerServerBackend(werf.HostLocker().Locker(), container_backend.DockerServerBackendOptions{})
 
 			SuiteData.CommitProjectWorktree(ctx, SuiteData.ProjectName, "_fixtures/images_dependencies/state0", "initial commit")
 			Expect(werfBuild(ctx, SuiteData.GetProjectWorktree(SuiteData.ProjectName), liveexec.ExecCommandOptions{})).To(Succeed())
@@ -102,5 +102,9 @@ var _ = Describe("Simple ci-env", Label("e2e", "ci-env", "simple"), func() {
 		},
 		Entry("gitlab --as-env-file", []string{"gitlab", "--as-env-file"}),
 		Entry("github --as-env-file", []string{"github", "--as-env-file"}),
+		Entry("bitbucket --as-env-file", []string{"bitbucket", "--as-env-file"}),
+		Entry("jenkins --as-env-file", []string{"jenkins", "--as-env-file"}),
+		Entry("azure-devops --as-env-file", []string{"azure-devops", "--as-env-file"}),
+		Entry("woodpecker --as-env-file", []string{"woodpecker", "--as-env-file"}),
 	)
 })
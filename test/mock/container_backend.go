@@ -224,6 +224,20 @@ func (mr *MockContainerBackendMockRecorder) Info(ctx any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Info", reflect.TypeOf((*MockContainerBackend)(nil).Info), ctx)
 }
 
+// Introspect mocks base method.
+func (m *MockContainerBackend) Introspect(ctx context.Context, ref string, opts container_backend.IntrospectOpts) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Introspect", ctx, ref, opts)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Introspect indicates an expected call of Introspect.
+func (mr *MockContainerBackendMockRecorder) Introspect(ctx, ref, opts any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Introspect", reflect.TypeOf((*MockContainerBackend)(nil).Introspect), ctx, ref, opts)
+}
+
 // LoadImageFromStream mocks base method.
 func (m *MockContainerBackend) LoadImageFromStream(ctx context.Context, input io.Reader) (string, error) {
 	m.ctrl.T.Helper()
@@ -409,6 +423,20 @@ func (mr *MockContainerBackendMockRecorder) Rmi(ctx, ref, opts any) *gomock.Call
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Rmi", reflect.TypeOf((*MockContainerBackend)(nil).Rmi), ctx, ref, opts)
 }
 
+// Run mocks base method.
+func (m *MockContainerBackend) Run(ctx context.Context, ref string, opts container_backend.RunOpts) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Run", ctx, ref, opts)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Run indicates an expected call of Run.
+func (mr *MockContainerBackendMockRecorder) Run(ctx, ref, opts any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Run", reflect.TypeOf((*MockContainerBackend)(nil).Run), ctx, ref, opts)
+}
+
 // SaveImageToStream mocks base method.
 func (m *MockContainerBackend) SaveImageToStream(ctx context.Context, imageName string) (io.ReadCloser, error) {
 	m.ctrl.T.Helper()
@@ -15,8 +15,11 @@ type BuildahStub struct {
 	callsMutex        sync.Mutex
 	FromCommandFunc   func(ctx context.Context, container, image string, opts buildah.FromCommandOpts) (string, error)
 	PullFunc          func(ctx context.Context, ref string, opts buildah.PullOpts) (string, error)
+	RunCommandFunc    func(ctx context.Context, container string, command []string, opts buildah.RunCommandOpts) error
+	InspectFunc       func(ctx context.Context, ref string) (*thirdparty.BuilderInfo, error)
 	FromCommandImages []string
 	PullRefs          []string
+	RmContainers      []string
 }
 
 var _ buildah.Buildah = (*BuildahStub)(nil)
@@ -45,7 +48,10 @@ func (b *BuildahStub) BuildFromDockerfile(context.Context, string, buildah.Build
 	return "", nil
 }
 
-func (b *BuildahStub) RunCommand(context.Context, string, []string, buildah.RunCommandOpts) error {
+func (b *BuildahStub) RunCommand(ctx context.Context, container string, command []string, opts buildah.RunCommandOpts) error {
+	if b.RunCommandFunc != nil {
+		return b.RunCommandFunc(ctx, container, command, opts)
+	}
 	return nil
 }
 
@@ -71,11 +77,18 @@ func (b *BuildahStub) Pull(ctx context.Context, ref string, opts buildah.PullOpt
 	return "", nil
 }
 
-func (b *BuildahStub) Inspect(context.Context, string) (*thirdparty.BuilderInfo, error) {
+func (b *BuildahStub) Inspect(ctx context.Context, ref string) (*thirdparty.BuilderInfo, error) {
+	if b.InspectFunc != nil {
+		return b.InspectFunc(ctx, ref)
+	}
 	return nil, nil
 }
 
-func (b *BuildahStub) Rm(context.Context, string, buildah.RmOpts) error {
+func (b *BuildahStub) Rm(_ context.Context, ref string, _ buildah.RmOpts) error {
+	b.callsMutex.Lock()
+	b.RmContainers = append(b.RmContainers, ref)
+	b.callsMutex.Unlock()
+
 	return nil
 }
 
@@ -119,6 +132,10 @@ func (b *BuildahStub) PruneImages(context.Context, buildah.PruneImagesOptions) (
 	return buildah.PruneImagesReport{}, nil
 }
 
+func (b *BuildahStub) PruneLayers(context.Context, buildah.PruneLayersOptions) (buildah.PruneLayersReport, error) {
+	return buildah.PruneLayersReport{}, nil
+}
+
 func (b *BuildahStub) SaveImageToStream(context.Context, string) (io.ReadCloser, error) {
 	return nil, nil
 }